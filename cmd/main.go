@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"github.com/misshanya/mitter/internal/app"
 	"github.com/misshanya/mitter/internal/config"
+	"github.com/misshanya/mitter/internal/models"
 	"log/slog"
 	"os"
 	"os/signal"
@@ -20,6 +21,12 @@ func main() {
 	cfg := config.NewConfig()
 	server := app.NewApp(cfg)
 
+	// One-off commands, e.g. `mitter reconcile-counters`
+	if len(os.Args) > 1 {
+		runCommand(server, os.Args[1], os.Args[2:])
+		return
+	}
+
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
 	defer stop()
 	// Start server
@@ -34,3 +41,40 @@ func main() {
 		slog.Error("failed to stop server", slog.Any("err", err))
 	}
 }
+
+func runCommand(server *app.App, command string, args []string) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	switch command {
+	case "reconcile-counters":
+		if err := server.ReconcileCounters(ctx); err != nil {
+			slog.Error("failed to reconcile counters", slog.Any("err", err))
+			os.Exit(1)
+		}
+		fmt.Println("counters reconciled")
+	case "import-mitts":
+		if len(args) != 2 {
+			slog.Error("usage: import-mitts <login> <archive.json|archive.zip>")
+			os.Exit(1)
+		}
+
+		archive, err := os.ReadFile(args[1])
+		if err != nil {
+			slog.Error("failed to read archive", slog.Any("err", err))
+			os.Exit(1)
+		}
+
+		imp, err := server.ImportMitts(ctx, args[0], archive, func(imp *models.Import) {
+			fmt.Printf("processed %d/%d\n", imp.Processed, imp.Total)
+		})
+		if err != nil {
+			slog.Error("failed to import mitts", slog.Any("err", err))
+			os.Exit(1)
+		}
+		fmt.Printf("imported %d, skipped %d, failed %d (see GET /mitt/import/%s/errors)\n", imp.Imported, imp.Skipped, imp.Failed, imp.ID)
+	default:
+		slog.Error("unknown command", slog.String("command", command))
+		os.Exit(1)
+	}
+}
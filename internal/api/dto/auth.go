@@ -3,7 +3,7 @@ package dto
 import "github.com/google/uuid"
 
 type SignUpRequest struct {
-	Login    string `json:"login" validate:"required,min=2,max=50"`
+	Login    string `json:"login" validate:"required,min=2,max=50,login"`
 	Name     string `json:"name" validate:"required,min=2,max=50"`
 	Password string `json:"password" validate:"required,min=8,max=100"`
 }
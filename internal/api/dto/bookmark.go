@@ -0,0 +1,28 @@
+package dto
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+type BookmarkCreateRequest struct {
+	// Folder to put bookmark in, none if omitted
+	FolderID *uuid.UUID `json:"folder_id"`
+}
+
+type BookmarkMoveRequest struct {
+	// null takes bookmark out of its folder
+	FolderID *uuid.UUID `json:"folder_id"`
+}
+
+type BookmarkFolderRequest struct {
+	Name string `json:"name" validate:"required"`
+}
+
+type BookmarkFolderResponse struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+	// Bookmarks of mitts which aren't deleted
+	BookmarksCount int64     `json:"bookmarks_count"`
+	CreatedAt      time.Time `json:"created_at"`
+}
@@ -0,0 +1,25 @@
+package dto
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+type DraftRequest struct {
+	// Normalized and checked by content.Check, max length is set in config.
+	// Max here is the db limit, config can't exceed it
+	Content string `json:"content" validate:"required,max=10000"`
+	// Draft is published automatically at this time, it must be in the future. Omitted or null means never
+	PublishAt *time.Time `json:"publish_at"`
+}
+
+type DraftResponse struct {
+	ID      uuid.UUID `json:"id"`
+	Content string    `json:"content"`
+	// Only set for scheduled drafts
+	PublishAt *time.Time `json:"publish_at,omitempty"`
+	Scheduled bool       `json:"scheduled"`
+	Version   int32      `json:"version"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
@@ -3,3 +3,13 @@ package dto
 type HTTPError struct {
 	Message string `json:"message"`
 }
+
+type ValidationError struct {
+	Message string       `json:"message"`
+	Fields  []FieldError `json:"fields"`
+}
+
+type FieldError struct {
+	Field string `json:"field"`
+	Error string `json:"error"`
+}
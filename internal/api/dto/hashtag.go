@@ -0,0 +1,7 @@
+package dto
+
+type TrendingHashtagResponse struct {
+	Name string `json:"name"`
+	// Uses in the last window minus uses in the window before it
+	Score int64 `json:"score"`
+}
@@ -0,0 +1,28 @@
+package dto
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+type ImportResponse struct {
+	ID     uuid.UUID `json:"id"`
+	Status string    `json:"status" enums:"pending,running,done,failed"`
+	Error  string    `json:"error,omitempty"`
+	// Count of mitts in archive
+	Total     int32 `json:"total"`
+	Processed int32 `json:"processed"`
+	Imported  int32 `json:"imported"`
+	// Already imported before
+	Skipped    int32      `json:"skipped"`
+	Failed     int32      `json:"failed"`
+	CreatedAt  time.Time  `json:"created_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+type ImportItemErrorResponse struct {
+	// Index of mitt in archive
+	Index      int32  `json:"index"`
+	ExternalID string `json:"id"`
+	Error      string `json:"error"`
+}
@@ -0,0 +1,35 @@
+package dto
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+type ConversationCreateRequest struct {
+	// Other members, conversation is one-to-one if there's only one
+	UserIDs []uuid.UUID `json:"user_ids"`
+}
+
+type ConversationResponse struct {
+	ID      uuid.UUID `json:"id"`
+	IsGroup bool      `json:"is_group"`
+	// Members including me
+	Members []UserResponse `json:"members"`
+	// Messages of others I haven't read yet
+	UnreadCount int64            `json:"unread_count"`
+	LastMessage *MessageResponse `json:"last_message,omitempty"`
+	CreatedAt   time.Time        `json:"created_at"`
+}
+
+type MessageCreateRequest struct {
+	// Normalized and checked by content.Check, max length is set in config
+	Content string `json:"content"`
+}
+
+type MessageResponse struct {
+	ID             uuid.UUID `json:"id"`
+	ConversationID uuid.UUID `json:"conversation_id"`
+	SenderID       uuid.UUID `json:"sender_id"`
+	Content        string    `json:"content"`
+	CreatedAt      time.Time `json:"created_at"`
+}
@@ -6,11 +6,19 @@ import (
 )
 
 type MittCreateRequest struct {
-	Content string `json:"content"`
+	// Normalized and checked by content.Check, max length is set in config.
+	// Max here is the db limit, config can't exceed it
+	Content string `json:"content" validate:"required,max=10000"`
+	// Optional poll attached to mitt
+	Poll *PollCreateRequest `json:"poll"`
+	// Mitt is hidden once it expires and deleted later, at least 5 minutes from now. Omitted or null means never
+	ExpiresAt *time.Time `json:"expires_at"`
 }
 
 type MittUpdateRequest struct {
-	Content string `json:"content"`
+	// Normalized and checked by content.Check, max length is set in config.
+	// Max here is the db limit, config can't exceed it
+	Content string `json:"content" validate:"required,max=10000"`
 }
 
 type MittResponse struct {
@@ -21,6 +29,35 @@ type MittResponse struct {
 	CreatedAt  time.Time `json:"created_at"`
 	UpdatedAt  time.Time `json:"updated_at"`
 	Likes      int64     `json:"likes"`
+	Edited     bool      `json:"edited"`
+	Revisions  int32     `json:"revisions"`
+	// Only set for mitts in trash
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	// Only set for mitts which expire
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	// Mentions of existing users in content
+	Mentions []MentionResponse `json:"mentions"`
+	// I bookmarked mitt, always false for anonymous requests
+	Bookmarked bool `json:"bookmarked"`
+	// Only set for mitts with poll
+	Poll *PollResponse `json:"poll,omitempty"`
+	// Author pinned mitt to profile, only set in mitts of user
+	Pinned bool `json:"pinned"`
+}
+
+// MentionResponse is "@login" in mitt content.
+// Offsets are in Unicode code points including '@', end is exclusive
+type MentionResponse struct {
+	UserID uuid.UUID `json:"user_id"`
+	Login  string    `json:"login"`
+	Start  int       `json:"start"`
+	End    int       `json:"end"`
+}
+
+type MittRevisionResponse struct {
+	ID        uuid.UUID `json:"id"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 type MittLikeResponse struct {
@@ -0,0 +1,38 @@
+package dto
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+type NotificationResponse struct {
+	// ID of the latest notification in group, marking it read marks the whole group
+	ID     uuid.UUID  `json:"id"`
+	Type   string     `json:"type" enums:"like,follow,mention,poll_closed"`
+	MittID *uuid.UUID `json:"mitt_id,omitempty"`
+	// A few latest actors
+	Actors []UserResponse `json:"actors"`
+	// Count of all actors in group, "X and 5 others" is actors_count - 1 others
+	ActorsCount int64     `json:"actors_count"`
+	Unread      bool      `json:"unread"`
+	LatestAt    time.Time `json:"latest_at"`
+}
+
+type UnreadCountResponse struct {
+	Count int64 `json:"count"`
+}
+
+// NotificationPreferencesRequest changes only given types
+type NotificationPreferencesRequest struct {
+	Likes       *bool `json:"likes"`
+	Follows     *bool `json:"follows"`
+	Mentions    *bool `json:"mentions"`
+	ClosedPolls *bool `json:"closed_polls"`
+}
+
+type NotificationPreferencesResponse struct {
+	Likes       bool `json:"likes"`
+	Follows     bool `json:"follows"`
+	Mentions    bool `json:"mentions"`
+	ClosedPolls bool `json:"closed_polls"`
+}
@@ -0,0 +1,36 @@
+package dto
+
+import "time"
+
+type PollCreateRequest struct {
+	// 2 to 4 different options up to 25 characters each
+	Options        []string `json:"options"`
+	MultipleChoice bool     `json:"multiple_choice"`
+	// From 5 minutes from now to max duration set in config
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type PollVoteRequest struct {
+	// Positions of chosen options starting from 0, only one for single choice poll
+	Choices []int `json:"choices" validate:"required"`
+}
+
+type PollResponse struct {
+	Options        []PollOptionResponse `json:"options"`
+	MultipleChoice bool                 `json:"multiple_choice"`
+	ExpiresAt      time.Time            `json:"expires_at"`
+	Expired        bool                 `json:"expired"`
+	VotersCount    int64                `json:"voters_count"`
+	// Votes are hidden until I vote or poll expires
+	ResultsVisible bool `json:"results_visible"`
+	// I voted, always false for anonymous requests
+	Voted bool `json:"voted"`
+	// Positions of options I chose
+	Choices []int `json:"choices,omitempty"`
+}
+
+type PollOptionResponse struct {
+	Text string `json:"text"`
+	// Omitted while results are hidden
+	Votes *int64 `json:"votes,omitempty"`
+}
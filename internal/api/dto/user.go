@@ -3,11 +3,33 @@ package dto
 import "github.com/google/uuid"
 
 type UserResponse struct {
-	ID    uuid.UUID `json:"id"`
-	Login string    `json:"login"`
-	Name  string    `json:"name"`
+	ID             uuid.UUID `json:"id"`
+	Login          string    `json:"login"`
+	Name           string    `json:"name"`
+	IsPrivate      bool      `json:"is_private"`
+	FollowersCount int64     `json:"followers_count"`
+	FollowingCount int64     `json:"following_count"`
+	MittsCount     int64     `json:"mitts_count"`
+}
+
+// MeResponse is my profile with my settings, which aren't shown to others
+type MeResponse struct {
+	UserResponse
+	// Only users I follow can message me
+	MessagesFromFollowingOnly bool `json:"messages_from_following_only"`
 }
 
 type UserUpdateRequest struct {
-	Name *string `json:"name" validate:"omitempty,min=2,max=50"`
+	Name                      *string `json:"name" validate:"omitempty,min=2,max=50"`
+	IsPrivate                 *bool   `json:"is_private"`
+	MessagesFromFollowingOnly *bool   `json:"messages_from_following_only"`
+}
+
+type RelationshipResponse struct {
+	ID         uuid.UUID `json:"id"`
+	Following  bool      `json:"following"`
+	FollowedBy bool      `json:"followed_by"`
+	Blocking   bool      `json:"blocking"`
+	BlockedBy  bool      `json:"blocked_by"`
+	Muting     bool      `json:"muting"`
 }
@@ -0,0 +1,37 @@
+package dto
+
+import (
+	"encoding/json"
+	"github.com/google/uuid"
+	"time"
+)
+
+type WebhookCreateRequest struct {
+	// http or https URL that receives POST requests
+	URL    string   `json:"url"`
+	Events []string `json:"events" enums:"like,follow,mention,poll_closed"`
+}
+
+type WebhookResponse struct {
+	ID  uuid.UUID `json:"id"`
+	URL string    `json:"url"`
+	// Key of HMAC-SHA256 signature in X-Mitter-Signature header, only returned on creation
+	Secret    string    `json:"secret,omitempty"`
+	Events    []string  `json:"events"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type WebhookDeliveryResponse struct {
+	ID       uuid.UUID       `json:"id"`
+	Event    string          `json:"event"`
+	Payload  json.RawMessage `json:"payload" swaggertype:"object"`
+	Status   string          `json:"status" enums:"pending,delivered,failed"`
+	Attempts int32           `json:"attempts"`
+	// Response code of the last attempt, absent if there was no response
+	ResponseCode *int32 `json:"response_code,omitempty"`
+	// Error of the last attempt, like timeout or refused connection
+	Error         string     `json:"error,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	LastAttemptAt *time.Time `json:"last_attempt_at,omitempty"`
+	DeliveredAt   *time.Time `json:"delivered_at,omitempty"`
+}
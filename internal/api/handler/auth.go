@@ -13,6 +13,7 @@ import (
 
 type authService interface {
 	SignIn(ctx context.Context, creds models.SignIn) (string, *models.HTTPError)
+	Reactivate(ctx context.Context, creds models.SignIn) (string, *models.HTTPError)
 	SignUp(ctx context.Context, user *models.UserCreate) (uuid.UUID, *models.HTTPError)
 
 	ChangePassword(ctx context.Context, id uuid.UUID, changePassword *models.ChangePassword) *models.HTTPError
@@ -27,7 +28,7 @@ type AuthHandler struct {
 func NewAuthHandler(as authService, reqAuthMdl echo.MiddlewareFunc) *AuthHandler {
 	return &AuthHandler{
 		as:                as,
-		validate:          validator.New(),
+		validate:          newValidator(),
 		reqAuthMiddleware: reqAuthMdl,
 	}
 }
@@ -35,6 +36,7 @@ func NewAuthHandler(as authService, reqAuthMdl echo.MiddlewareFunc) *AuthHandler
 func (h *AuthHandler) Routes(group *echo.Group) {
 	group.POST("/sign-in", h.signIn)
 	group.POST("/sign-up", h.signUp)
+	group.POST("/reactivate", h.reactivate)
 
 	// Protect /change-password with auth middleware
 	group.POST("/change-password", h.changePassword, h.reqAuthMiddleware)
@@ -49,11 +51,36 @@ func (h *AuthHandler) Routes(group *echo.Group) {
 //	@Param			SignInRequest	body	dto.SignInRequest	true	"Sign In Request"
 //	@Produce		json
 //	@Success		200	{object}	dto.SignInResponse
-//	@Failure		400	{object}	dto.HTTPError
+//	@Failure		400	{object}	dto.ValidationError
 //	@Failure		401	{object}	dto.HTTPError
+//	@Failure		403	{object}	dto.HTTPError
 //	@Failure		500	{object}	dto.HTTPError
 //	@Router			/auth/sign-in [post]
 func (h *AuthHandler) signIn(c echo.Context) error {
+	return h.authenticate(c, h.as.SignIn)
+}
+
+// reactivate godoc
+//
+//	@Summary		Reactivate account
+//	@Description	Restore deleted account during grace period and sign in
+//	@Tags			Auth
+//	@Accept			json
+//	@Param			SignInRequest	body	dto.SignInRequest	true	"Sign In Request"
+//	@Produce		json
+//	@Success		200	{object}	dto.SignInResponse
+//	@Failure		400	{object}	dto.ValidationError
+//	@Failure		401	{object}	dto.HTTPError
+//	@Failure		409	{object}	dto.HTTPError
+//	@Failure		410	{object}	dto.HTTPError
+//	@Failure		500	{object}	dto.HTTPError
+//	@Router			/auth/reactivate [post]
+func (h *AuthHandler) reactivate(c echo.Context) error {
+	return h.authenticate(c, h.as.Reactivate)
+}
+
+// authenticate checks credentials with given service method and sets token cookie
+func (h *AuthHandler) authenticate(c echo.Context, signIn func(ctx context.Context, creds models.SignIn) (string, *models.HTTPError)) error {
 	ctx := c.Request().Context()
 
 	var req dto.SignInRequest
@@ -63,7 +90,7 @@ func (h *AuthHandler) signIn(c echo.Context) error {
 
 	// Validate
 	if err := h.validate.StructCtx(ctx, &req); err != nil {
-		return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+		return validationFailed(c, fieldErrors(err))
 	}
 
 	creds := models.SignIn{
@@ -71,7 +98,7 @@ func (h *AuthHandler) signIn(c echo.Context) error {
 		Password: req.Password,
 	}
 
-	token, err := h.as.SignIn(ctx, creds)
+	token, err := signIn(ctx, creds)
 	if err != nil {
 		return c.JSON(err.Code, dto.HTTPError{Message: err.Message})
 	}
@@ -99,7 +126,7 @@ func (h *AuthHandler) signIn(c echo.Context) error {
 //	@Param			SignUpRequest	body	dto.SignUpRequest	true	"Sign Up Request"
 //	@Produce		json
 //	@Success		201	{object}	dto.SignUpResponse
-//	@Failure		400	{object}	dto.HTTPError
+//	@Failure		400	{object}	dto.ValidationError
 //	@Failure		401	{object}	dto.HTTPError
 //	@Failure		409	{object}	dto.HTTPError
 //	@Failure		500	{object}	dto.HTTPError
@@ -114,7 +141,7 @@ func (h *AuthHandler) signUp(c echo.Context) error {
 
 	// Validate
 	if err := h.validate.StructCtx(ctx, &req); err != nil {
-		return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+		return validationFailed(c, fieldErrors(err))
 	}
 
 	user := &models.UserCreate{
@@ -143,7 +170,7 @@ func (h *AuthHandler) signUp(c echo.Context) error {
 //	@Accept			json
 //	@Param			ChangePasswordRequest	body	dto.ChangePasswordRequest	true	"Change Password Request"
 //	@Success		200
-//	@Failure		400	{object}	dto.HTTPError
+//	@Failure		400	{object}	dto.ValidationError
 //	@Failure		401	{object}	dto.HTTPError
 //	@Failure		500	{object}	dto.HTTPError
 //	@Router			/auth/change-password [post]
@@ -159,7 +186,7 @@ func (h *AuthHandler) changePassword(c echo.Context) error {
 
 	// Validate
 	if err := h.validate.StructCtx(ctx, &req); err != nil {
-		return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+		return validationFailed(c, fieldErrors(err))
 	}
 
 	changePwd := &models.ChangePassword{
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"context"
+	"fmt"
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"github.com/misshanya/mitter/internal/models"
@@ -22,6 +23,13 @@ func (s *mockAuthService) SignIn(ctx context.Context, creds models.SignIn) (stri
 	return "8a67006c-692f-4e75-b547-84a46707a5cb", nil
 }
 
+func (s *mockAuthService) Reactivate(ctx context.Context, creds models.SignIn) (string, *models.HTTPError) {
+	_ = ctx
+	_ = creds
+
+	return "8a67006c-692f-4e75-b547-84a46707a5cb", nil
+}
+
 func (s *mockAuthService) SignUp(ctx context.Context, user *models.UserCreate) (uuid.UUID, *models.HTTPError) {
 	_ = ctx
 	_ = user
@@ -70,6 +78,29 @@ func TestAuthHandler_SignIn(t *testing.T) {
 	}
 }
 
+func TestAuthHandler_Reactivate(t *testing.T) {
+	e := echo.New()
+	handler := NewAuthHandler(&mockAuthService{}, mockRequireAuth)
+
+	g := e.Group("/api/v1/auth")
+	handler.Routes(g)
+
+	// Create request
+	reqBody := `{"login":"testuser","password":"qwerty123456"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/reactivate", strings.NewReader(reqBody))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, rec)
+
+	if assert.NoError(t, handler.reactivate(ctx)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		expectedResp := `{"token": "8a67006c-692f-4e75-b547-84a46707a5cb"}`
+		assert.JSONEq(t, expectedResp, rec.Body.String())
+	}
+}
+
 func TestAuthHandler_SignUp(t *testing.T) {
 	e := echo.New()
 	handler := NewAuthHandler(&mockAuthService{}, mockRequireAuth)
@@ -93,6 +124,29 @@ func TestAuthHandler_SignUp(t *testing.T) {
 	}
 }
 
+func TestAuthHandler_SignUpLogin(t *testing.T) {
+	e := echo.New()
+	handler := NewAuthHandler(&mockAuthService{}, mockRequireAuth)
+
+	g := e.Group("/api/v1/auth")
+	handler.Routes(g)
+
+	// Logins which can't be mentioned are rejected
+	for _, login := range []string{"test user", "test.", "@test"} {
+		reqBody := fmt.Sprintf(`{"login":%q,"name":"Test User","password":"qwerty123456"}`, login)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/sign-up", strings.NewReader(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+
+		ctx := e.NewContext(req, rec)
+
+		if assert.NoError(t, handler.signUp(ctx)) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code, login)
+			assert.Contains(t, rec.Body.String(), `"field":"login"`, login)
+		}
+	}
+}
+
 func TestAuthHandler_ChangePassword(t *testing.T) {
 	e := echo.New()
 	handler := NewAuthHandler(&mockAuthService{}, mockRequireAuth)
@@ -0,0 +1,349 @@
+package handler
+
+import (
+	"context"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/misshanya/mitter/internal/api/dto"
+	"github.com/misshanya/mitter/internal/models"
+	"github.com/misshanya/mitter/pkg/pagination"
+	"net/http"
+)
+
+type bookmarkService interface {
+	mittMarker
+
+	AddBookmark(ctx context.Context, userID uuid.UUID, mittID uuid.UUID, folderID *uuid.UUID) *models.HTTPError
+	DeleteBookmark(ctx context.Context, userID uuid.UUID, mittID uuid.UUID) *models.HTTPError
+	MoveBookmark(ctx context.Context, userID uuid.UUID, mittID uuid.UUID, folderID *uuid.UUID) *models.HTTPError
+	GetBookmarks(ctx context.Context, userID uuid.UUID, folderID *uuid.UUID, limit, offset int32) ([]*models.Mitt, *models.HTTPError)
+
+	// Folders
+
+	CreateFolder(ctx context.Context, userID uuid.UUID, name string) (*models.BookmarkFolder, *models.HTTPError)
+	GetFolders(ctx context.Context, userID uuid.UUID) ([]*models.BookmarkFolder, *models.HTTPError)
+	RenameFolder(ctx context.Context, userID uuid.UUID, id uuid.UUID, name string) (*models.BookmarkFolder, *models.HTTPError)
+	DeleteFolder(ctx context.Context, userID uuid.UUID, id uuid.UUID) *models.HTTPError
+}
+
+type BookmarkHandler struct {
+	bs                bookmarkService
+	validate          *validator.Validate
+	reqAuthMiddleware echo.MiddlewareFunc
+}
+
+func NewBookmarkHandler(bs bookmarkService, reqAuthMdl echo.MiddlewareFunc) *BookmarkHandler {
+	return &BookmarkHandler{
+		bs:                bs,
+		validate:          newValidator(),
+		reqAuthMiddleware: reqAuthMdl,
+	}
+}
+
+func (h *BookmarkHandler) Routes(group *echo.Group) {
+	group.GET("", h.getBookmarks, h.reqAuthMiddleware)
+	group.POST("/:mitt_id", h.addBookmark, h.reqAuthMiddleware)
+	group.DELETE("/:mitt_id", h.deleteBookmark, h.reqAuthMiddleware)
+	group.PUT("/:mitt_id/folder", h.moveBookmark, h.reqAuthMiddleware)
+
+	group.POST("/folders", h.createFolder, h.reqAuthMiddleware)
+	group.GET("/folders", h.getFolders, h.reqAuthMiddleware)
+	group.PUT("/folders/:id", h.renameFolder, h.reqAuthMiddleware)
+	group.DELETE("/folders/:id", h.deleteFolder, h.reqAuthMiddleware)
+}
+
+func bookmarkFolderToResponse(folder *models.BookmarkFolder) dto.BookmarkFolderResponse {
+	return dto.BookmarkFolderResponse{
+		ID:             folder.ID,
+		Name:           folder.Name,
+		BookmarksCount: folder.BookmarksCount,
+		CreatedAt:      folder.CreatedAt,
+	}
+}
+
+// getBookmarks godoc
+//
+//	@Summary		Get my bookmarks
+//	@Description	Bookmarked mitts, recently bookmarked first. Bookmarks of deleted mitts aren't shown
+//	@Tags			Bookmarks
+//	@Security		Bearer
+//	@Param			Authorization	header	string	true	"access token 'Bearer {token}'"
+//	@Param			folder_id		query	string	false	"Only bookmarks in this folder"
+//	@Param			offset			query	int		false	"Offset"
+//	@Param			limit			query	int		false	"Limit"
+//	@Produce		json
+//	@Success		200	{object}	[]dto.MittResponse
+//	@Failure		400	{object}	dto.HTTPError
+//	@Failure		401	{object}	dto.HTTPError
+//	@Failure		404	{object}	dto.HTTPError
+//	@Failure		500	{object}	dto.HTTPError
+//	@Router			/bookmark [get]
+func (h *BookmarkHandler) getBookmarks(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID := c.Get("userID").(uuid.UUID)
+
+	var folderID *uuid.UUID
+	if s := c.QueryParam("folder_id"); s != "" {
+		id, err := uuid.Parse(s)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+		}
+		folderID = &id
+	}
+
+	limit, offset, err := pagination.GetLimitAndOffset(c, 30)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+	}
+
+	mitts, httpErr := h.bs.GetBookmarks(ctx, userID, folderID, limit, offset)
+	if httpErr != nil {
+		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
+	}
+
+	return c.JSON(http.StatusOK, mittsToResponse(mitts))
+}
+
+// addBookmark godoc
+//
+//	@Summary		Bookmark mitt
+//	@Description	Bookmarks are private. Bookmarking already bookmarked mitt changes nothing, use PUT /bookmark/{mitt_id}/folder to move it
+//	@Tags			Bookmarks
+//	@Security		Bearer
+//	@Param			Authorization	header	string						true	"access token 'Bearer {token}'"
+//	@Param			mitt_id			path	string						true	"ID of mitt"
+//	@Param			bookmark		body	dto.BookmarkCreateRequest	false	"Folder"
+//	@Accept			json
+//	@Success		204
+//	@Failure		400	{object}	dto.HTTPError
+//	@Failure		401	{object}	dto.HTTPError
+//	@Failure		404	{object}	dto.HTTPError
+//	@Failure		500	{object}	dto.HTTPError
+//	@Router			/bookmark/{mitt_id} [post]
+func (h *BookmarkHandler) addBookmark(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID := c.Get("userID").(uuid.UUID)
+
+	mittID, err := uuid.Parse(c.Param("mitt_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+	}
+
+	var req dto.BookmarkCreateRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+	}
+
+	if httpErr := h.bs.AddBookmark(ctx, userID, mittID, req.FolderID); httpErr != nil {
+		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// deleteBookmark godoc
+//
+//	@Summary		Remove bookmark
+//	@Description	Removing absent bookmark changes nothing
+//	@Tags			Bookmarks
+//	@Security		Bearer
+//	@Param			Authorization	header	string	true	"access token 'Bearer {token}'"
+//	@Param			mitt_id			path	string	true	"ID of mitt"
+//	@Success		204
+//	@Failure		400	{object}	dto.HTTPError
+//	@Failure		401	{object}	dto.HTTPError
+//	@Failure		500	{object}	dto.HTTPError
+//	@Router			/bookmark/{mitt_id} [delete]
+func (h *BookmarkHandler) deleteBookmark(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID := c.Get("userID").(uuid.UUID)
+
+	mittID, err := uuid.Parse(c.Param("mitt_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+	}
+
+	if httpErr := h.bs.DeleteBookmark(ctx, userID, mittID); httpErr != nil {
+		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// moveBookmark godoc
+//
+//	@Summary	Move bookmark to another folder
+//	@Tags		Bookmarks
+//	@Security	Bearer
+//	@Param		Authorization	header	string					true	"access token 'Bearer {token}'"
+//	@Param		mitt_id			path	string					true	"ID of mitt"
+//	@Param		folder			body	dto.BookmarkMoveRequest	true	"Folder, null takes bookmark out of its folder"
+//	@Accept		json
+//	@Success	204
+//	@Failure	400	{object}	dto.HTTPError
+//	@Failure	401	{object}	dto.HTTPError
+//	@Failure	404	{object}	dto.HTTPError
+//	@Failure	500	{object}	dto.HTTPError
+//	@Router		/bookmark/{mitt_id}/folder [put]
+func (h *BookmarkHandler) moveBookmark(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID := c.Get("userID").(uuid.UUID)
+
+	mittID, err := uuid.Parse(c.Param("mitt_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+	}
+
+	var req dto.BookmarkMoveRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+	}
+
+	if httpErr := h.bs.MoveBookmark(ctx, userID, mittID, req.FolderID); httpErr != nil {
+		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// Folders
+
+// createFolder godoc
+//
+//	@Summary		Create bookmark folder
+//	@Description	Names are unique regardless of case
+//	@Tags			Bookmarks
+//	@Security		Bearer
+//	@Param			Authorization	header	string						true	"access token 'Bearer {token}'"
+//	@Param			folder			body	dto.BookmarkFolderRequest	true	"Folder"
+//	@Accept			json
+//	@Produce		json
+//	@Success		201	{object}	dto.BookmarkFolderResponse
+//	@Failure		400	{object}	dto.ValidationError
+//	@Failure		401	{object}	dto.HTTPError
+//	@Failure		409	{object}	dto.HTTPError
+//	@Failure		500	{object}	dto.HTTPError
+//	@Router			/bookmark/folders [post]
+func (h *BookmarkHandler) createFolder(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID := c.Get("userID").(uuid.UUID)
+
+	var req dto.BookmarkFolderRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return validationFailed(c, fieldErrors(err))
+	}
+
+	folder, httpErr := h.bs.CreateFolder(ctx, userID, req.Name)
+	if httpErr != nil {
+		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
+	}
+
+	return c.JSON(http.StatusCreated, bookmarkFolderToResponse(folder))
+}
+
+// getFolders godoc
+//
+//	@Summary	Get my bookmark folders
+//	@Tags		Bookmarks
+//	@Security	Bearer
+//	@Param		Authorization	header	string	true	"access token 'Bearer {token}'"
+//	@Produce	json
+//	@Success	200	{object}	[]dto.BookmarkFolderResponse
+//	@Failure	401	{object}	dto.HTTPError
+//	@Failure	500	{object}	dto.HTTPError
+//	@Router		/bookmark/folders [get]
+func (h *BookmarkHandler) getFolders(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID := c.Get("userID").(uuid.UUID)
+
+	folders, httpErr := h.bs.GetFolders(ctx, userID)
+	if httpErr != nil {
+		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
+	}
+
+	resp := make([]dto.BookmarkFolderResponse, len(folders))
+	for i, folder := range folders {
+		resp[i] = bookmarkFolderToResponse(folder)
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// renameFolder godoc
+//
+//	@Summary	Rename bookmark folder
+//	@Tags		Bookmarks
+//	@Security	Bearer
+//	@Param		Authorization	header	string						true	"access token 'Bearer {token}'"
+//	@Param		id				path	string						true	"ID of folder"
+//	@Param		folder			body	dto.BookmarkFolderRequest	true	"Folder"
+//	@Accept		json
+//	@Produce	json
+//	@Success	200	{object}	dto.BookmarkFolderResponse
+//	@Failure	400	{object}	dto.ValidationError
+//	@Failure	401	{object}	dto.HTTPError
+//	@Failure	404	{object}	dto.HTTPError
+//	@Failure	409	{object}	dto.HTTPError
+//	@Failure	500	{object}	dto.HTTPError
+//	@Router		/bookmark/folders/{id} [put]
+func (h *BookmarkHandler) renameFolder(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID := c.Get("userID").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+	}
+
+	var req dto.BookmarkFolderRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return validationFailed(c, fieldErrors(err))
+	}
+
+	folder, httpErr := h.bs.RenameFolder(ctx, userID, id, req.Name)
+	if httpErr != nil {
+		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
+	}
+
+	return c.JSON(http.StatusOK, bookmarkFolderToResponse(folder))
+}
+
+// deleteFolder godoc
+//
+//	@Summary		Delete bookmark folder
+//	@Description	Bookmarks in folder are kept without folder
+//	@Tags			Bookmarks
+//	@Security		Bearer
+//	@Param			Authorization	header	string	true	"access token 'Bearer {token}'"
+//	@Param			id				path	string	true	"ID of folder"
+//	@Success		204
+//	@Failure		400	{object}	dto.HTTPError
+//	@Failure		401	{object}	dto.HTTPError
+//	@Failure		404	{object}	dto.HTTPError
+//	@Failure		500	{object}	dto.HTTPError
+//	@Router			/bookmark/folders/{id} [delete]
+func (h *BookmarkHandler) deleteFolder(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID := c.Get("userID").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+	}
+
+	if httpErr := h.bs.DeleteFolder(ctx, userID, id); httpErr != nil {
+		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
+	}
+	return c.NoContent(http.StatusNoContent)
+}
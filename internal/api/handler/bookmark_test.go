@@ -0,0 +1,343 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/misshanya/mitter/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+var mockBookmarkFolder = &models.BookmarkFolder{
+	ID:             uuid.MustParse("2e7a0f9c-3d4e-4f50-9b62-7c8d9e0f1a2b"),
+	Name:           "Read later",
+	CreatedAt:      time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	BookmarksCount: 1,
+}
+
+// Mock service
+type mockBookmarkService struct {
+	bookmarked map[uuid.UUID]bool
+}
+
+func newMockBookmarkService() *mockBookmarkService {
+	return &mockBookmarkService{bookmarked: map[uuid.UUID]bool{}}
+}
+
+func (s *mockBookmarkService) MarkMitts(ctx context.Context, userID uuid.UUID, mitts []*models.Mitt) *models.HTTPError {
+	_ = ctx
+	_ = userID
+
+	for _, m := range mitts {
+		m.Bookmarked = s.bookmarked[m.ID]
+	}
+	return nil
+}
+
+func (s *mockBookmarkService) AddBookmark(ctx context.Context, userID uuid.UUID, mittID uuid.UUID, folderID *uuid.UUID) *models.HTTPError {
+	_ = ctx
+	_ = userID
+
+	if folderID != nil && *folderID != mockBookmarkFolder.ID {
+		return &models.HTTPError{Code: http.StatusNotFound, Message: "Folder not found"}
+	}
+	if mittID != mockMittModel.ID {
+		return &models.HTTPError{Code: http.StatusNotFound, Message: "Mitt not found"}
+	}
+	s.bookmarked[mittID] = true
+	return nil
+}
+
+func (s *mockBookmarkService) DeleteBookmark(ctx context.Context, userID uuid.UUID, mittID uuid.UUID) *models.HTTPError {
+	_ = ctx
+	_ = userID
+
+	delete(s.bookmarked, mittID)
+	return nil
+}
+
+func (s *mockBookmarkService) MoveBookmark(ctx context.Context, userID uuid.UUID, mittID uuid.UUID, folderID *uuid.UUID) *models.HTTPError {
+	_ = ctx
+	_ = userID
+
+	if folderID != nil && *folderID != mockBookmarkFolder.ID {
+		return &models.HTTPError{Code: http.StatusNotFound, Message: "Folder not found"}
+	}
+	if !s.bookmarked[mittID] {
+		return &models.HTTPError{Code: http.StatusNotFound, Message: "Bookmark not found"}
+	}
+	return nil
+}
+
+func (s *mockBookmarkService) GetBookmarks(ctx context.Context, userID uuid.UUID, folderID *uuid.UUID, limit, offset int32) ([]*models.Mitt, *models.HTTPError) {
+	_ = ctx
+	_ = userID
+	_ = limit
+	_ = offset
+
+	if folderID != nil && *folderID != mockBookmarkFolder.ID {
+		return nil, &models.HTTPError{Code: http.StatusNotFound, Message: "Folder not found"}
+	}
+	return []*models.Mitt{{ID: mockMittModel.ID, Content: mockMittModel.Content, Bookmarked: true}}, nil
+}
+
+func (s *mockBookmarkService) CreateFolder(ctx context.Context, userID uuid.UUID, name string) (*models.BookmarkFolder, *models.HTTPError) {
+	_ = ctx
+	_ = userID
+
+	if name == mockBookmarkFolder.Name {
+		return nil, &models.HTTPError{Code: http.StatusConflict, Message: "Folder with this name already exists"}
+	}
+	return &models.BookmarkFolder{ID: uuid.New(), Name: name, CreatedAt: time.Now()}, nil
+}
+
+func (s *mockBookmarkService) GetFolders(ctx context.Context, userID uuid.UUID) ([]*models.BookmarkFolder, *models.HTTPError) {
+	_ = ctx
+	_ = userID
+
+	return []*models.BookmarkFolder{mockBookmarkFolder}, nil
+}
+
+func (s *mockBookmarkService) RenameFolder(ctx context.Context, userID uuid.UUID, id uuid.UUID, name string) (*models.BookmarkFolder, *models.HTTPError) {
+	_ = ctx
+	_ = userID
+
+	if id != mockBookmarkFolder.ID {
+		return nil, &models.HTTPError{Code: http.StatusNotFound, Message: "Folder not found"}
+	}
+	return &models.BookmarkFolder{ID: id, Name: name, CreatedAt: mockBookmarkFolder.CreatedAt}, nil
+}
+
+func (s *mockBookmarkService) DeleteFolder(ctx context.Context, userID uuid.UUID, id uuid.UUID) *models.HTTPError {
+	_ = ctx
+	_ = userID
+
+	if id != mockBookmarkFolder.ID {
+		return &models.HTTPError{Code: http.StatusNotFound, Message: "Folder not found"}
+	}
+	return nil
+}
+
+// Tests
+func TestBookmarkHandler_AddBookmark(t *testing.T) {
+	e := echo.New()
+	handler := NewBookmarkHandler(newMockBookmarkService(), mockRequireAuth)
+
+	g := e.Group("/api/v1/bookmark")
+	handler.Routes(g)
+
+	for _, tc := range []struct {
+		id   string
+		body string
+		code int
+	}{
+		{mockMittModel.ID.String(), "", http.StatusNoContent},
+		{mockMittModel.ID.String(), `{"folder_id":"` + mockBookmarkFolder.ID.String() + `"}`, http.StatusNoContent},
+		{mockMittModel.ID.String(), `{"folder_id":"` + uuid.NewString() + `"}`, http.StatusNotFound},
+		{uuid.NewString(), "", http.StatusNotFound},
+		{"not-uuid", "", http.StatusBadRequest},
+	} {
+		// Create request
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/bookmark/"+tc.id, strings.NewReader(tc.body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+
+		ctx := e.NewContext(req, rec)
+
+		// Set path param (mitt_id)
+		ctx.SetPath("/api/v1/bookmark/:mitt_id")
+		ctx.SetParamNames("mitt_id")
+		ctx.SetParamValues(tc.id)
+
+		if assert.NoError(t, mockRequireAuth(handler.addBookmark)(ctx)) {
+			assert.Equal(t, tc.code, rec.Code, tc.body)
+		}
+	}
+}
+
+func TestBookmarkHandler_MoveBookmark(t *testing.T) {
+	e := echo.New()
+	bs := newMockBookmarkService()
+	bs.bookmarked[mockMittModel.ID] = true
+	handler := NewBookmarkHandler(bs, mockRequireAuth)
+
+	g := e.Group("/api/v1/bookmark")
+	handler.Routes(g)
+
+	for _, tc := range []struct {
+		id   string
+		body string
+		code int
+	}{
+		{mockMittModel.ID.String(), `{"folder_id":"` + mockBookmarkFolder.ID.String() + `"}`, http.StatusNoContent},
+		{mockMittModel.ID.String(), `{"folder_id":null}`, http.StatusNoContent},
+		{mockMittModel.ID.String(), `{"folder_id":"` + uuid.NewString() + `"}`, http.StatusNotFound},
+		{uuid.NewString(), `{"folder_id":null}`, http.StatusNotFound},
+		{mockMittModel.ID.String(), `{"folder_id":"not-uuid"}`, http.StatusBadRequest},
+	} {
+		// Create request
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/bookmark/"+tc.id+"/folder", strings.NewReader(tc.body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+
+		ctx := e.NewContext(req, rec)
+
+		// Set path param (mitt_id)
+		ctx.SetPath("/api/v1/bookmark/:mitt_id/folder")
+		ctx.SetParamNames("mitt_id")
+		ctx.SetParamValues(tc.id)
+
+		if assert.NoError(t, mockRequireAuth(handler.moveBookmark)(ctx)) {
+			assert.Equal(t, tc.code, rec.Code, tc.body)
+		}
+	}
+}
+
+func TestBookmarkHandler_GetBookmarks(t *testing.T) {
+	e := echo.New()
+	handler := NewBookmarkHandler(newMockBookmarkService(), mockRequireAuth)
+
+	g := e.Group("/api/v1/bookmark")
+	handler.Routes(g)
+
+	for _, tc := range []struct {
+		query string
+		code  int
+	}{
+		{"", http.StatusOK},
+		{"?folder_id=" + mockBookmarkFolder.ID.String(), http.StatusOK},
+		{"?folder_id=" + uuid.NewString(), http.StatusNotFound},
+		{"?folder_id=not-uuid", http.StatusBadRequest},
+	} {
+		// Create request
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/bookmark"+tc.query, nil)
+		rec := httptest.NewRecorder()
+
+		ctx := e.NewContext(req, rec)
+
+		if assert.NoError(t, mockRequireAuth(handler.getBookmarks)(ctx)) {
+			assert.Equal(t, tc.code, rec.Code, tc.query)
+		}
+
+		if tc.code == http.StatusOK {
+			assert.Contains(t, rec.Body.String(), `"bookmarked":true`)
+		}
+	}
+}
+
+func TestBookmarkHandler_Folders(t *testing.T) {
+	e := echo.New()
+	handler := NewBookmarkHandler(newMockBookmarkService(), mockRequireAuth)
+
+	g := e.Group("/api/v1/bookmark")
+	handler.Routes(g)
+
+	// Create
+	for _, tc := range []struct {
+		body string
+		code int
+	}{
+		{`{"name":"Recipes"}`, http.StatusCreated},
+		{`{"name":"Read later"}`, http.StatusConflict},
+		{`{}`, http.StatusBadRequest},
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/bookmark/folders", strings.NewReader(tc.body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+
+		ctx := e.NewContext(req, rec)
+
+		if assert.NoError(t, mockRequireAuth(handler.createFolder)(ctx)) {
+			assert.Equal(t, tc.code, rec.Code, tc.body)
+		}
+	}
+
+	// List
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/bookmark/folders", nil)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	if assert.NoError(t, mockRequireAuth(handler.getFolders)(ctx)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"name":"Read later"`)
+		assert.Contains(t, rec.Body.String(), `"bookmarks_count":1`)
+	}
+
+	// Rename
+	req = httptest.NewRequest(http.MethodPut, "/api/v1/bookmark/folders/"+mockBookmarkFolder.ID.String(), strings.NewReader(`{"name":"Later"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec = httptest.NewRecorder()
+	ctx = e.NewContext(req, rec)
+	ctx.SetPath("/api/v1/bookmark/folders/:id")
+	ctx.SetParamNames("id")
+	ctx.SetParamValues(mockBookmarkFolder.ID.String())
+	if assert.NoError(t, mockRequireAuth(handler.renameFolder)(ctx)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"name":"Later"`)
+	}
+
+	// Delete
+	for _, tc := range []struct {
+		id   string
+		code int
+	}{
+		{mockBookmarkFolder.ID.String(), http.StatusNoContent},
+		{uuid.NewString(), http.StatusNotFound},
+	} {
+		req := httptest.NewRequest(http.MethodDelete, "/api/v1/bookmark/folders/"+tc.id, nil)
+		rec := httptest.NewRecorder()
+
+		ctx := e.NewContext(req, rec)
+		ctx.SetPath("/api/v1/bookmark/folders/:id")
+		ctx.SetParamNames("id")
+		ctx.SetParamValues(tc.id)
+
+		if assert.NoError(t, mockRequireAuth(handler.deleteFolder)(ctx)) {
+			assert.Equal(t, tc.code, rec.Code)
+		}
+	}
+}
+
+func TestMittHandler_Bookmarked(t *testing.T) {
+	e := echo.New()
+	bs := newMockBookmarkService()
+	bs.bookmarked[mockMittModel.ID] = true
+	handler := NewMittHandler(&mockMittService{}, bs, mockRequireAuth, mockRequireAuth, 280)
+	// Mock mitt is shared with other tests
+	defer func() { mockMittModel.Bookmarked = false }()
+
+	g := e.Group("/api/v1/mitt")
+	handler.Routes(g)
+
+	newCtx := func() (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/mitt/"+mockMittModel.ID.String(), nil)
+		rec := httptest.NewRecorder()
+
+		ctx := e.NewContext(req, rec)
+		ctx.SetPath("/api/v1/mitt/:id")
+		ctx.SetParamNames("id")
+		ctx.SetParamValues(mockMittModel.ID.String())
+		return ctx, rec
+	}
+
+	// Anonymous request
+	ctx, rec := newCtx()
+	if assert.NoError(t, handler.getMitt(ctx)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"bookmarked":false`)
+		assert.Equal(t, countersETag(mockMittModel.Version, mockMittModel.Likes), rec.Header().Get("ETag"))
+	}
+
+	// Signed in user who bookmarked mitt, cached response without the flag is stale
+	ctx, rec = newCtx()
+	if assert.NoError(t, mockRequireAuth(handler.getMitt)(ctx)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"bookmarked":true`)
+		assert.NotEqual(t, countersETag(mockMittModel.Version, mockMittModel.Likes), rec.Header().Get("ETag"))
+	}
+}
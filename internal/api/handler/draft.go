@@ -0,0 +1,348 @@
+package handler
+
+import (
+	"context"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/misshanya/mitter/internal/api/dto"
+	"github.com/misshanya/mitter/internal/models"
+	"github.com/misshanya/mitter/pkg/content"
+	"github.com/misshanya/mitter/pkg/pagination"
+	"net/http"
+	"strconv"
+)
+
+type draftService interface {
+	CreateDraft(ctx context.Context, userID uuid.UUID, draft *models.DraftCreate) (*models.Draft, *models.HTTPError)
+	GetDraft(ctx context.Context, userID uuid.UUID, id uuid.UUID) (*models.Draft, *models.HTTPError)
+	GetDrafts(ctx context.Context, userID uuid.UUID, scheduled *bool, limit, offset int32) ([]*models.Draft, *models.HTTPError)
+	UpdateDraft(ctx context.Context, userID uuid.UUID, id uuid.UUID, draft *models.DraftUpdate) (*models.Draft, *models.HTTPError)
+	CancelSchedule(ctx context.Context, userID uuid.UUID, id uuid.UUID) (*models.Draft, *models.HTTPError)
+	DeleteDraft(ctx context.Context, userID uuid.UUID, id uuid.UUID) *models.HTTPError
+	PublishDraft(ctx context.Context, userID uuid.UUID, id uuid.UUID) (*models.Mitt, *models.HTTPError)
+}
+
+type DraftHandler struct {
+	ds                draftService
+	reqAuthMiddleware echo.MiddlewareFunc
+	validate          *validator.Validate
+
+	maxContentLength int
+}
+
+// NewDraftHandler creates draft handler which accepts content up to maxContentLength characters, like mitts
+func NewDraftHandler(ds draftService, reqAuthMdl echo.MiddlewareFunc, maxContentLength int) *DraftHandler {
+	return &DraftHandler{
+		ds:                ds,
+		reqAuthMiddleware: reqAuthMdl,
+		validate:          newValidator(),
+		maxContentLength:  maxContentLength,
+	}
+}
+
+func draftToResponse(draft *models.Draft) dto.DraftResponse {
+	return dto.DraftResponse{
+		ID:        draft.ID,
+		Content:   draft.Content,
+		PublishAt: draft.PublishAt,
+		Scheduled: draft.PublishAt != nil,
+		Version:   draft.Version,
+		CreatedAt: draft.CreatedAt,
+		UpdatedAt: draft.UpdatedAt,
+	}
+}
+
+func (h *DraftHandler) Routes(group *echo.Group) {
+	group.POST("", h.createDraft, h.reqAuthMiddleware)
+	group.GET("", h.getDrafts, h.reqAuthMiddleware)
+	group.GET("/:id", h.getDraft, h.reqAuthMiddleware)
+	group.PUT("/:id", h.updateDraft, h.reqAuthMiddleware)
+	group.DELETE("/:id", h.deleteDraft, h.reqAuthMiddleware)
+	group.DELETE("/:id/schedule", h.cancelSchedule, h.reqAuthMiddleware)
+	group.POST("/:id/publish", h.publishDraft, h.reqAuthMiddleware)
+}
+
+// bindDraft binds draft request and normalizes its content
+func (h *DraftHandler) bindDraft(c echo.Context) (*dto.DraftRequest, error) {
+	var req dto.DraftRequest
+	if err := c.Bind(&req); err != nil {
+		return nil, c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return nil, validationFailed(c, fieldErrors(err))
+	}
+
+	req.Content = content.Normalize(req.Content)
+	if err := content.Check(req.Content, h.maxContentLength); err != nil {
+		return nil, validationFailed(c, []dto.FieldError{{Field: "content", Error: err.Error()}})
+	}
+	return &req, nil
+}
+
+// createDraft godoc
+//
+//	@Summary		Create draft
+//	@Description	Drafts are private and aren't in feeds. Draft with publish_at is published automatically at that time
+//	@Tags			Drafts
+//	@Security		Bearer
+//	@Param			Authorization	header	string				true	"access token 'Bearer {token}'"
+//	@Param			draft			body	dto.DraftRequest	true	"Draft"
+//	@Accept			json
+//	@Produce		json
+//	@Success		201	{object}	dto.DraftResponse
+//	@Header			201	{string}	ETag	"Version of draft"
+//	@Failure		400	{object}	dto.ValidationError
+//	@Failure		401	{object}	dto.HTTPError
+//	@Failure		500	{object}	dto.HTTPError
+//	@Router			/draft [post]
+func (h *DraftHandler) createDraft(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID := c.Get("userID").(uuid.UUID)
+
+	req, err := h.bindDraft(c)
+	if req == nil {
+		return err
+	}
+
+	draft, httpErr := h.ds.CreateDraft(ctx, userID, &models.DraftCreate{
+		Content:   req.Content,
+		PublishAt: req.PublishAt,
+	})
+	if httpErr != nil {
+		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
+	}
+
+	setETag(c, versionETag(draft.Version))
+	return c.JSON(http.StatusCreated, draftToResponse(draft))
+}
+
+// getDrafts godoc
+//
+//	@Summary		Get my drafts
+//	@Description	Scheduled drafts first, the soonest first, then the rest, recently updated first
+//	@Tags			Drafts
+//	@Security		Bearer
+//	@Param			Authorization	header	string	true	"access token 'Bearer {token}'"
+//	@Param			scheduled		query	bool	false	"Only scheduled (true) or not scheduled (false) drafts"
+//	@Param			offset			query	int		false	"Offset"
+//	@Param			limit			query	int		false	"Limit"
+//	@Produce		json
+//	@Success		200	{object}	[]dto.DraftResponse
+//	@Failure		400	{object}	dto.HTTPError
+//	@Failure		401	{object}	dto.HTTPError
+//	@Failure		500	{object}	dto.HTTPError
+//	@Router			/draft [get]
+func (h *DraftHandler) getDrafts(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID := c.Get("userID").(uuid.UUID)
+
+	var scheduled *bool
+	if s := c.QueryParam("scheduled"); s != "" {
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+		}
+		scheduled = &b
+	}
+
+	limit, offset, err := pagination.GetLimitAndOffset(c, 30)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+	}
+
+	drafts, httpErr := h.ds.GetDrafts(ctx, userID, scheduled, limit, offset)
+	if httpErr != nil {
+		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
+	}
+
+	resp := make([]dto.DraftResponse, len(drafts))
+	for i, d := range drafts {
+		resp[i] = draftToResponse(d)
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// getDraft godoc
+//
+//	@Summary	Get my draft
+//	@Tags		Drafts
+//	@Security	Bearer
+//	@Param		Authorization	header	string	true	"access token 'Bearer {token}'"
+//	@Param		id				path	string	true	"ID of draft"
+//	@Produce	json
+//	@Success	200	{object}	dto.DraftResponse
+//	@Header		200	{string}	ETag	"Version of draft"
+//	@Failure	400	{object}	dto.HTTPError
+//	@Failure	401	{object}	dto.HTTPError
+//	@Failure	404	{object}	dto.HTTPError
+//	@Failure	500	{object}	dto.HTTPError
+//	@Router		/draft/{id} [get]
+func (h *DraftHandler) getDraft(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID := c.Get("userID").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+	}
+
+	draft, httpErr := h.ds.GetDraft(ctx, userID, id)
+	if httpErr != nil {
+		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
+	}
+
+	setETag(c, versionETag(draft.Version))
+	return c.JSON(http.StatusOK, draftToResponse(draft))
+}
+
+// updateDraft godoc
+//
+//	@Summary		Update draft
+//	@Description	Replaces content and publish time, omitted or null publish_at cancels scheduled publishing
+//	@Tags			Drafts
+//	@Security		Bearer
+//	@Param			Authorization	header	string				true	"access token 'Bearer {token}'"
+//	@Param			id				path	string				true	"ID of draft"
+//	@Param			If-Match		header	string				false	"Update only if draft still has this ETag"
+//	@Param			draft			body	dto.DraftRequest	true	"Draft"
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	dto.DraftResponse
+//	@Header			200	{string}	ETag	"New version of draft"
+//	@Failure		400	{object}	dto.ValidationError
+//	@Failure		401	{object}	dto.HTTPError
+//	@Failure		404	{object}	dto.HTTPError
+//	@Failure		412	{object}	dto.HTTPError
+//	@Failure		500	{object}	dto.HTTPError
+//	@Router			/draft/{id} [put]
+func (h *DraftHandler) updateDraft(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID := c.Get("userID").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+	}
+
+	req, err := h.bindDraft(c)
+	if req == nil {
+		return err
+	}
+
+	draft, httpErr := h.ds.UpdateDraft(ctx, userID, id, &models.DraftUpdate{
+		Content:         req.Content,
+		PublishAt:       req.PublishAt,
+		ExpectedVersion: ifMatchVersion(c),
+	})
+	if httpErr != nil {
+		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
+	}
+
+	setETag(c, versionETag(draft.Version))
+	return c.JSON(http.StatusOK, draftToResponse(draft))
+}
+
+// deleteDraft godoc
+//
+//	@Summary	Delete draft
+//	@Tags		Drafts
+//	@Security	Bearer
+//	@Param		Authorization	header	string	true	"access token 'Bearer {token}'"
+//	@Param		id				path	string	true	"ID of draft"
+//	@Success	204
+//	@Failure	400	{object}	dto.HTTPError
+//	@Failure	401	{object}	dto.HTTPError
+//	@Failure	404	{object}	dto.HTTPError
+//	@Failure	500	{object}	dto.HTTPError
+//	@Router		/draft/{id} [delete]
+func (h *DraftHandler) deleteDraft(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID := c.Get("userID").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+	}
+
+	if httpErr := h.ds.DeleteDraft(ctx, userID, id); httpErr != nil {
+		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// cancelSchedule godoc
+//
+//	@Summary		Cancel scheduled publishing
+//	@Description	Scheduled draft stays as a plain draft
+//	@Tags			Drafts
+//	@Security		Bearer
+//	@Param			Authorization	header	string	true	"access token 'Bearer {token}'"
+//	@Param			id				path	string	true	"ID of draft"
+//	@Produce		json
+//	@Success		200	{object}	dto.DraftResponse
+//	@Header			200	{string}	ETag	"New version of draft"
+//	@Failure		400	{object}	dto.HTTPError
+//	@Failure		401	{object}	dto.HTTPError
+//	@Failure		404	{object}	dto.HTTPError
+//	@Failure		409	{object}	dto.HTTPError
+//	@Failure		500	{object}	dto.HTTPError
+//	@Router			/draft/{id}/schedule [delete]
+func (h *DraftHandler) cancelSchedule(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID := c.Get("userID").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+	}
+
+	draft, httpErr := h.ds.CancelSchedule(ctx, userID, id)
+	if httpErr != nil {
+		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
+	}
+
+	setETag(c, versionETag(draft.Version))
+	return c.JSON(http.StatusOK, draftToResponse(draft))
+}
+
+// publishDraft godoc
+//
+//	@Summary		Publish draft now
+//	@Description	Creates mitt from draft and deletes the draft, scheduled drafts can be published early
+//	@Tags			Drafts
+//	@Security		Bearer
+//	@Param			Authorization	header	string	true	"access token 'Bearer {token}'"
+//	@Param			id				path	string	true	"ID of draft"
+//	@Produce		json
+//	@Success		201	{object}	dto.MittResponse
+//	@Failure		400	{object}	dto.HTTPError
+//	@Failure		401	{object}	dto.HTTPError
+//	@Failure		404	{object}	dto.HTTPError
+//	@Failure		409	{object}	dto.HTTPError
+//	@Failure		500	{object}	dto.HTTPError
+//	@Router			/draft/{id}/publish [post]
+func (h *DraftHandler) publishDraft(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID := c.Get("userID").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+	}
+
+	mitt, httpErr := h.ds.PublishDraft(ctx, userID, id)
+	if httpErr != nil {
+		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
+	}
+
+	return c.JSON(http.StatusCreated, mittToResponse(mitt))
+}
@@ -0,0 +1,241 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/misshanya/mitter/internal/api/dto"
+	"github.com/misshanya/mitter/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var mockDraftModel = &models.Draft{
+	ID:        uuid.MustParse("5d1f0c7e-3a6b-4c1e-9f2a-8b7d6e5c4a3b"),
+	AuthorID:  uuid.MustParse("b096376a-5fa9-4130-907a-709c67008a65"),
+	Content:   "hello",
+	Version:   2,
+	CreatedAt: time.Now(),
+	UpdatedAt: time.Now(),
+}
+
+// Mock service
+type mockDraftService struct{}
+
+func (s *mockDraftService) CreateDraft(ctx context.Context, userID uuid.UUID, draft *models.DraftCreate) (*models.Draft, *models.HTTPError) {
+	_ = ctx
+	return &models.Draft{
+		ID:        mockDraftModel.ID,
+		AuthorID:  userID,
+		Content:   draft.Content,
+		PublishAt: draft.PublishAt,
+		Version:   1,
+	}, nil
+}
+
+func (s *mockDraftService) GetDraft(ctx context.Context, userID uuid.UUID, id uuid.UUID) (*models.Draft, *models.HTTPError) {
+	_ = ctx
+	if id != mockDraftModel.ID || userID != mockDraftModel.AuthorID {
+		return nil, &models.HTTPError{Code: http.StatusNotFound, Message: "Draft not found"}
+	}
+	return mockDraftModel, nil
+}
+
+func (s *mockDraftService) GetDrafts(ctx context.Context, userID uuid.UUID, scheduled *bool, limit, offset int32) ([]*models.Draft, *models.HTTPError) {
+	_ = ctx
+	_ = userID
+	_ = limit
+	_ = offset
+	if scheduled != nil && *scheduled {
+		return []*models.Draft{}, nil
+	}
+	return []*models.Draft{mockDraftModel}, nil
+}
+
+func (s *mockDraftService) UpdateDraft(ctx context.Context, userID uuid.UUID, id uuid.UUID, draft *models.DraftUpdate) (*models.Draft, *models.HTTPError) {
+	existing, httpErr := s.GetDraft(ctx, userID, id)
+	if httpErr != nil {
+		return nil, httpErr
+	}
+	if draft.ExpectedVersion != nil && *draft.ExpectedVersion != existing.Version {
+		return nil, &models.HTTPError{Code: http.StatusPreconditionFailed, Message: "Draft was modified, fetch it again"}
+	}
+	return &models.Draft{
+		ID:        id,
+		AuthorID:  userID,
+		Content:   draft.Content,
+		PublishAt: draft.PublishAt,
+		Version:   existing.Version + 1,
+	}, nil
+}
+
+func (s *mockDraftService) CancelSchedule(ctx context.Context, userID uuid.UUID, id uuid.UUID) (*models.Draft, *models.HTTPError) {
+	if _, httpErr := s.GetDraft(ctx, userID, id); httpErr != nil {
+		return nil, httpErr
+	}
+	return nil, &models.HTTPError{Code: http.StatusConflict, Message: "Draft is not scheduled"}
+}
+
+func (s *mockDraftService) DeleteDraft(ctx context.Context, userID uuid.UUID, id uuid.UUID) *models.HTTPError {
+	_, httpErr := s.GetDraft(ctx, userID, id)
+	return httpErr
+}
+
+func (s *mockDraftService) PublishDraft(ctx context.Context, userID uuid.UUID, id uuid.UUID) (*models.Mitt, *models.HTTPError) {
+	draft, httpErr := s.GetDraft(ctx, userID, id)
+	if httpErr != nil {
+		return nil, httpErr
+	}
+	return &models.Mitt{ID: uuid.New(), AuthorID: userID, Content: draft.Content, Version: 1}, nil
+}
+
+// Tests
+func TestDraftHandler_CreateDraft(t *testing.T) {
+	e := echo.New()
+	handler := NewDraftHandler(&mockDraftService{}, mockRequireAuth, 10)
+
+	g := e.Group("/api/v1/draft")
+	handler.Routes(g)
+
+	for _, tc := range []struct {
+		body string
+		code int
+	}{
+		{`{"content":"  hello  "}`, http.StatusCreated},
+		{`{"content":"hello","publish_at":"2030-01-01T00:00:00Z"}`, http.StatusCreated},
+		{`{"content":""}`, http.StatusBadRequest},
+		{`{"content":"hello world!"}`, http.StatusBadRequest},
+	} {
+		// Create request
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/draft", strings.NewReader(tc.body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+
+		ctx := e.NewContext(req, rec)
+
+		if assert.NoError(t, mockRequireAuth(handler.createDraft)(ctx)) {
+			assert.Equal(t, tc.code, rec.Code, tc.body)
+		}
+		if tc.code != http.StatusCreated {
+			continue
+		}
+
+		var resp dto.DraftResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, "hello", resp.Content)
+		assert.Equal(t, resp.PublishAt != nil, resp.Scheduled)
+		assert.Equal(t, versionETag(1), rec.Header().Get("ETag"))
+	}
+}
+
+func TestDraftHandler_UpdateDraft(t *testing.T) {
+	e := echo.New()
+	handler := NewDraftHandler(&mockDraftService{}, mockRequireAuth, 280)
+
+	g := e.Group("/api/v1/draft")
+	handler.Routes(g)
+
+	for _, tc := range []struct {
+		id      string
+		ifMatch string
+		code    int
+	}{
+		{mockDraftModel.ID.String(), "", http.StatusOK},
+		{mockDraftModel.ID.String(), versionETag(mockDraftModel.Version), http.StatusOK},
+		{mockDraftModel.ID.String(), versionETag(mockDraftModel.Version + 1), http.StatusPreconditionFailed},
+		{uuid.NewString(), "", http.StatusNotFound},
+		{"not-uuid", "", http.StatusBadRequest},
+	} {
+		// Create request
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/draft/"+tc.id, strings.NewReader(`{"content":"edited"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		if tc.ifMatch != "" {
+			req.Header.Set("If-Match", tc.ifMatch)
+		}
+		rec := httptest.NewRecorder()
+
+		ctx := e.NewContext(req, rec)
+
+		// Set path param (id)
+		ctx.SetPath("/api/v1/draft/:id")
+		ctx.SetParamNames("id")
+		ctx.SetParamValues(tc.id)
+
+		if assert.NoError(t, mockRequireAuth(handler.updateDraft)(ctx)) {
+			assert.Equal(t, tc.code, rec.Code, tc.ifMatch)
+		}
+		if tc.code == http.StatusOK {
+			assert.Equal(t, versionETag(mockDraftModel.Version+1), rec.Header().Get("ETag"))
+		}
+	}
+}
+
+func TestDraftHandler_GetDrafts(t *testing.T) {
+	e := echo.New()
+	handler := NewDraftHandler(&mockDraftService{}, mockRequireAuth, 280)
+
+	g := e.Group("/api/v1/draft")
+	handler.Routes(g)
+
+	for _, tc := range []struct {
+		query string
+		code  int
+		count int
+	}{
+		{"", http.StatusOK, 1},
+		{"?scheduled=true", http.StatusOK, 0},
+		{"?scheduled=false", http.StatusOK, 1},
+		{"?scheduled=maybe", http.StatusBadRequest, 0},
+	} {
+		// Create request
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/draft"+tc.query, nil)
+		rec := httptest.NewRecorder()
+
+		ctx := e.NewContext(req, rec)
+
+		if assert.NoError(t, mockRequireAuth(handler.getDrafts)(ctx)) {
+			assert.Equal(t, tc.code, rec.Code, tc.query)
+		}
+		if tc.code != http.StatusOK {
+			continue
+		}
+
+		var resp []dto.DraftResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Len(t, resp, tc.count)
+	}
+}
+
+func TestDraftHandler_PublishDraft(t *testing.T) {
+	e := echo.New()
+	handler := NewDraftHandler(&mockDraftService{}, mockRequireAuth, 280)
+
+	g := e.Group("/api/v1/draft")
+	handler.Routes(g)
+
+	// Create request
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/draft/"+mockDraftModel.ID.String()+"/publish", nil)
+	rec := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, rec)
+
+	// Set path param (id)
+	ctx.SetPath("/api/v1/draft/:id/publish")
+	ctx.SetParamNames("id")
+	ctx.SetParamValues(mockDraftModel.ID.String())
+
+	if assert.NoError(t, mockRequireAuth(handler.publishDraft)(ctx)) {
+		assert.Equal(t, http.StatusCreated, rec.Code)
+
+		var resp dto.MittResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, mockDraftModel.Content, resp.Content)
+	}
+}
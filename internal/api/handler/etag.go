@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/misshanya/mitter/internal/models"
+)
+
+// Echo has no constants for these headers
+const (
+	headerETag        = "ETag"
+	headerIfMatch     = "If-Match"
+	headerIfNoneMatch = "If-None-Match"
+)
+
+// setETag sets ETag of response. Responses depend on the signed in user, so shared caches must not reuse them for others
+func setETag(c echo.Context, etag string) {
+	header := c.Response().Header()
+	header.Set(headerETag, etag)
+	header.Set(echo.HeaderCacheControl, "private")
+	header.Add(echo.HeaderVary, echo.HeaderAuthorization)
+	header.Add(echo.HeaderVary, echo.HeaderCookie)
+}
+
+// versionETag makes ETag from entity version, for responses which change only on edits
+func versionETag(version int32) string {
+	return fmt.Sprintf(`"%d"`, version)
+}
+
+// countersETag makes ETag from entity version and counters, as counters change without version bump.
+// Version goes first, so the ETag can still be sent in If-Match
+func countersETag(version int32, counters ...int64) string {
+	tag := strconv.FormatInt(int64(version), 10)
+	for _, counter := range counters {
+		tag += "-" + strconv.FormatInt(counter, 10)
+	}
+	return `"` + tag + `"`
+}
+
+// mittETag makes ETag of mitt for current user. Likes, bookmarking, votes of poll, its closing
+// and seeing its results change it too, as response has them, but the version can still be sent in If-Match
+func mittETag(mitt *models.Mitt) string {
+	counters := []int64{mitt.Likes}
+
+	var flags string
+	if mitt.Bookmarked {
+		flags += "b"
+	}
+	if poll := mitt.Poll; poll != nil {
+		now := time.Now()
+
+		var votes int64
+		for _, option := range poll.Options {
+			votes += option.Votes
+		}
+		counters = append(counters, votes, poll.VotersCount)
+
+		if poll.ResultsVisible(now) {
+			flags += "r"
+		}
+		if poll.ClosedAt != nil || poll.Expired(now) {
+			flags += "c"
+		}
+	}
+
+	etag := countersETag(mitt.Version, counters...)
+	if flags != "" {
+		return strings.TrimSuffix(etag, `"`) + "-" + flags + `"`
+	}
+	return etag
+}
+
+// userETag makes ETag of user, follow and mitts counters change it too
+func userETag(user *models.User) string {
+	return countersETag(user.Version, user.FollowersCount, user.FollowingCount, user.MittsCount)
+}
+
+// ifMatchVersion returns version from If-Match header.
+// Returns nil if header is absent or matches any version ("*"),
+// and -1 if it can't be a version, so it never matches
+func ifMatchVersion(c echo.Context) *int32 {
+	header := strings.TrimSpace(c.Request().Header.Get(headerIfMatch))
+	if header == "" || header == "*" {
+		return nil
+	}
+
+	version := int32(-1)
+	tag, _, _ := strings.Cut(strings.Trim(header, `"`), "-")
+	if v, err := strconv.ParseInt(tag, 10, 32); err == nil {
+		version = int32(v)
+	}
+	return &version
+}
+
+// notModified reports if If-None-Match header contains current ETag
+func notModified(c echo.Context, etag string) bool {
+	header := c.Request().Header.Get(headerIfNoneMatch)
+	if header == "" {
+		return false
+	}
+
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == etag || tag == "*" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/misshanya/mitter/internal/api/dto"
+	"github.com/misshanya/mitter/internal/models"
+	"io"
+	"net/http"
+)
+
+// Download route is mounted next to export routes
+const exportDownloadPath = "/api/v1/export/download/"
+
+type exportService interface {
+	RequestExport(ctx context.Context, userID uuid.UUID) (*models.Export, *models.HTTPError)
+	GetExport(ctx context.Context, userID uuid.UUID, id uuid.UUID) (*models.Export, *models.HTTPError)
+	GetArchive(ctx context.Context, downloadToken uuid.UUID) (io.Reader, *models.HTTPError)
+}
+
+type ExportHandler struct {
+	es                exportService
+	reqAuthMiddleware echo.MiddlewareFunc
+}
+
+func NewExportHandler(es exportService, reqAuthMdl echo.MiddlewareFunc) *ExportHandler {
+	return &ExportHandler{
+		es:                es,
+		reqAuthMiddleware: reqAuthMdl,
+	}
+}
+
+func (h *ExportHandler) Routes(group *echo.Group) {
+	group.POST("", h.requestExport, h.reqAuthMiddleware)
+	group.GET("/:id", h.getExport, h.reqAuthMiddleware)
+
+	// Download link is authorized by its token
+	group.GET("/download/:token", h.downloadExport)
+}
+
+func exportToResponse(export *models.Export) dto.ExportResponse {
+	resp := dto.ExportResponse{
+		ID:         export.ID,
+		Status:     string(export.Status),
+		Error:      export.Error,
+		CreatedAt:  export.CreatedAt,
+		FinishedAt: export.FinishedAt,
+	}
+	if export.Status == models.ExportDone && export.DownloadToken != nil {
+		resp.DownloadURL = exportDownloadPath + export.DownloadToken.String()
+		resp.ExpiresAt = export.ExpiresAt
+	}
+	return resp
+}
+
+// requestExport godoc
+//
+//	@Summary		Request data export
+//	@Description	Queue export of profile, mitts with revisions, likes, follows and followers as ZIP archive
+//	@Tags			Export
+//	@Security		Bearer
+//	@Param			Authorization	header	string	true	"access token 'Bearer {token}'"
+//	@Produce		json
+//	@Success		202	{object}	dto.ExportResponse
+//	@Failure		401	{object}	dto.HTTPError
+//	@Failure		409	{object}	dto.HTTPError
+//	@Failure		500	{object}	dto.HTTPError
+//	@Router			/export [post]
+func (h *ExportHandler) requestExport(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID := c.Get("userID").(uuid.UUID)
+
+	export, err := h.es.RequestExport(ctx, userID)
+	if err != nil {
+		return c.JSON(err.Code, dto.HTTPError{Message: err.Message})
+	}
+
+	return c.JSON(http.StatusAccepted, exportToResponse(export))
+}
+
+// getExport godoc
+//
+//	@Summary		Get data export
+//	@Description	Get export status, download_url is set when archive is ready
+//	@Tags			Export
+//	@Security		Bearer
+//	@Param			Authorization	header	string	true	"access token 'Bearer {token}'"
+//	@Param			id				path	string	true	"ID of export"
+//	@Produce		json
+//	@Success		200	{object}	dto.ExportResponse
+//	@Failure		400	{object}	dto.HTTPError
+//	@Failure		401	{object}	dto.HTTPError
+//	@Failure		404	{object}	dto.HTTPError
+//	@Failure		500	{object}	dto.HTTPError
+//	@Router			/export/{id} [get]
+func (h *ExportHandler) getExport(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID := c.Get("userID").(uuid.UUID)
+
+	exportID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+	}
+
+	export, httpErr := h.es.GetExport(ctx, userID, exportID)
+	if httpErr != nil {
+		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
+	}
+
+	return c.JSON(http.StatusOK, exportToResponse(export))
+}
+
+// downloadExport godoc
+//
+//	@Summary		Download data export
+//	@Description	Download ZIP archive by link from export status
+//	@Tags			Export
+//	@Param			token	path	string	true	"Download token"
+//	@Produce		application/zip
+//	@Success		200	{file}		binary
+//	@Failure		400	{object}	dto.HTTPError
+//	@Failure		404	{object}	dto.HTTPError
+//	@Failure		500	{object}	dto.HTTPError
+//	@Router			/export/download/{token} [get]
+func (h *ExportHandler) downloadExport(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	token, err := uuid.Parse(c.Param("token"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+	}
+
+	archive, httpErr := h.es.GetArchive(ctx, token)
+	if httpErr != nil {
+		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
+	}
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="mitter-export.zip"`)
+	return c.Stream(http.StatusOK, "application/zip", archive)
+}
@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/misshanya/mitter/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	mockDownloadToken = uuid.MustParse("8a67006c-692f-4e75-b547-84a46707a5cb")
+	mockExportModel   = &models.Export{
+		ID:            uuid.MustParse("5c0a1f5b-3a44-4bb5-9e55-0b0c1b1a4a11"),
+		UserID:        mockUserID,
+		Status:        models.ExportDone,
+		CreatedAt:     time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		DownloadToken: &mockDownloadToken,
+	}
+)
+
+// Mock service
+type mockExportService struct{}
+
+func (s *mockExportService) RequestExport(ctx context.Context, userID uuid.UUID) (*models.Export, *models.HTTPError) {
+	_ = ctx
+	_ = userID
+
+	return &models.Export{ID: mockExportModel.ID, UserID: userID, Status: models.ExportPending}, nil
+}
+
+func (s *mockExportService) GetExport(ctx context.Context, userID uuid.UUID, id uuid.UUID) (*models.Export, *models.HTTPError) {
+	_ = ctx
+	_ = userID
+
+	if id != mockExportModel.ID {
+		return nil, &models.HTTPError{Code: http.StatusNotFound, Message: "Export not found"}
+	}
+	return mockExportModel, nil
+}
+
+func (s *mockExportService) GetArchive(ctx context.Context, downloadToken uuid.UUID) (io.Reader, *models.HTTPError) {
+	_ = ctx
+
+	if downloadToken != mockDownloadToken {
+		return nil, &models.HTTPError{Code: http.StatusNotFound, Message: "Download link is invalid or expired"}
+	}
+	return strings.NewReader("PK"), nil
+}
+
+// Tests
+func TestExportHandler_RequestExport(t *testing.T) {
+	e := echo.New()
+	handler := NewExportHandler(&mockExportService{}, mockRequireAuth)
+
+	g := e.Group("/api/v1/export")
+	handler.Routes(g)
+
+	// Create request
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/export", nil)
+	rec := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, rec)
+
+	if assert.NoError(t, mockRequireAuth(handler.requestExport)(ctx)) {
+		assert.Equal(t, http.StatusAccepted, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"status":"pending"`)
+		assert.NotContains(t, rec.Body.String(), "download_url")
+	}
+}
+
+func TestExportHandler_GetExport(t *testing.T) {
+	e := echo.New()
+	handler := NewExportHandler(&mockExportService{}, mockRequireAuth)
+
+	g := e.Group("/api/v1/export")
+	handler.Routes(g)
+
+	// Create request
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/export/"+mockExportModel.ID.String(), nil)
+	rec := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, rec)
+
+	// Set path param (id)
+	ctx.SetPath("/api/v1/export/:id")
+	ctx.SetParamNames("id")
+	ctx.SetParamValues(mockExportModel.ID.String())
+
+	if assert.NoError(t, mockRequireAuth(handler.getExport)(ctx)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"download_url":"/api/v1/export/download/`+mockDownloadToken.String()+`"`)
+	}
+}
+
+func TestExportHandler_DownloadExport(t *testing.T) {
+	e := echo.New()
+	handler := NewExportHandler(&mockExportService{}, mockRequireAuth)
+
+	g := e.Group("/api/v1/export")
+	handler.Routes(g)
+
+	for _, tc := range []struct {
+		token string
+		code  int
+	}{
+		{mockDownloadToken.String(), http.StatusOK},
+		{uuid.NewString(), http.StatusNotFound},
+		{"not-a-token", http.StatusBadRequest},
+	} {
+		// Create request
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/export/download/"+tc.token, nil)
+		rec := httptest.NewRecorder()
+
+		ctx := e.NewContext(req, rec)
+
+		// Set path param (token)
+		ctx.SetPath("/api/v1/export/download/:token")
+		ctx.SetParamNames("token")
+		ctx.SetParamValues(tc.token)
+
+		if assert.NoError(t, handler.downloadExport(ctx)) {
+			assert.Equal(t, tc.code, rec.Code)
+		}
+		if tc.code == http.StatusOK {
+			assert.Equal(t, "application/zip", rec.Header().Get(echo.HeaderContentType))
+			assert.Equal(t, "PK", rec.Body.String())
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"context"
+	"github.com/labstack/echo/v4"
+	"github.com/misshanya/mitter/internal/api/dto"
+	"github.com/misshanya/mitter/internal/models"
+	"github.com/misshanya/mitter/pkg/pagination"
+	"net/http"
+)
+
+type hashtagService interface {
+	GetHashtagMitts(ctx context.Context, name string, limit, offset int32) ([]*models.Mitt, *models.HTTPError)
+	GetTrending(ctx context.Context, limit int32) ([]*models.TrendingHashtag, *models.HTTPError)
+}
+
+type HashtagHandler struct {
+	hs                hashtagService
+	mm                mittMarker
+	optAuthMiddleware echo.MiddlewareFunc
+}
+
+func NewHashtagHandler(hs hashtagService, mm mittMarker, optAuthMdl echo.MiddlewareFunc) *HashtagHandler {
+	return &HashtagHandler{
+		hs:                hs,
+		mm:                mm,
+		optAuthMiddleware: optAuthMdl,
+	}
+}
+
+func (h *HashtagHandler) Routes(group *echo.Group) {
+	group.GET("/tag/:name", h.getHashtagMitts, h.optAuthMiddleware)
+	group.GET("/trending/tags", h.getTrendingHashtags)
+}
+
+// getHashtagMitts godoc
+//
+//	@Summary		Get hashtag timeline
+//	@Description	Get mitts with hashtag, newest first
+//	@Tags			Hashtags
+//	@Param			Authorization	header	string	false	"access token 'Bearer {token}', marks my bookmarks and poll votes"
+//	@Param			name			path	string	true	"Hashtag with or without '#', case doesn't matter"
+//	@Param			offset			query	int		false	"Offset"
+//	@Param			limit			query	int		false	"Limit"
+//	@Produce		json
+//	@Success		200	{object}	[]dto.MittResponse
+//	@Failure		400	{object}	dto.HTTPError
+//	@Failure		401	{object}	dto.HTTPError
+//	@Failure		500	{object}	dto.HTTPError
+//	@Router			/tag/{name} [get]
+func (h *HashtagHandler) getHashtagMitts(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	limit, offset, err := pagination.GetLimitAndOffset(c, 30)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+	}
+
+	mitts, httpErr := h.hs.GetHashtagMitts(ctx, c.Param("name"), limit, offset)
+	if httpErr != nil {
+		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
+	}
+	if httpErr := markMitts(c, h.mm, mitts...); httpErr != nil {
+		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
+	}
+
+	return c.JSON(http.StatusOK, mittsToResponse(mitts))
+}
+
+// getTrendingHashtags godoc
+//
+//	@Summary		Get trending hashtags
+//	@Description	Get hashtags which usage grows the most: uses in the last window minus uses in the window before it
+//	@Tags			Hashtags
+//	@Param			limit	query	int	false	"Limit"
+//	@Produce		json
+//	@Success		200	{object}	[]dto.TrendingHashtagResponse
+//	@Failure		400	{object}	dto.HTTPError
+//	@Failure		500	{object}	dto.HTTPError
+//	@Router			/trending/tags [get]
+func (h *HashtagHandler) getTrendingHashtags(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	limit, _, err := pagination.GetLimitAndOffset(c, 10)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+	}
+
+	tags, httpErr := h.hs.GetTrending(ctx, limit)
+	if httpErr != nil {
+		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
+	}
+
+	resp := make([]dto.TrendingHashtagResponse, len(tags))
+	for i, tag := range tags {
+		resp[i] = dto.TrendingHashtagResponse{
+			Name:  tag.Name,
+			Score: tag.Score,
+		}
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
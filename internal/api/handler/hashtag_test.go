@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/misshanya/mitter/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// Mock service
+type mockHashtagService struct{}
+
+func (s *mockHashtagService) GetHashtagMitts(ctx context.Context, name string, limit, offset int32) ([]*models.Mitt, *models.HTTPError) {
+	_ = ctx
+	_ = limit
+	_ = offset
+
+	if strings.Contains(name, " ") {
+		return nil, &models.HTTPError{Code: http.StatusBadRequest, Message: "Invalid hashtag"}
+	}
+	return []*models.Mitt{mockMittModel}, nil
+}
+
+func (s *mockHashtagService) GetTrending(ctx context.Context, limit int32) ([]*models.TrendingHashtag, *models.HTTPError) {
+	_ = ctx
+	_ = limit
+
+	return []*models.TrendingHashtag{{Name: "go", Score: 10}}, nil
+}
+
+// Tests
+func TestHashtagHandler_GetHashtagMitts(t *testing.T) {
+	e := echo.New()
+	handler := NewHashtagHandler(&mockHashtagService{}, newMockBookmarkService(), mockRequireAuth)
+
+	g := e.Group("/api/v1")
+	handler.Routes(g)
+
+	for _, tc := range []struct {
+		name string
+		code int
+	}{
+		{"go", http.StatusOK},
+		{"not a tag", http.StatusBadRequest},
+	} {
+		// Create request
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/tag/x", nil)
+		rec := httptest.NewRecorder()
+
+		ctx := e.NewContext(req, rec)
+
+		// Set path param (name)
+		ctx.SetPath("/api/v1/tag/:name")
+		ctx.SetParamNames("name")
+		ctx.SetParamValues(tc.name)
+
+		if assert.NoError(t, handler.getHashtagMitts(ctx)) {
+			assert.Equal(t, tc.code, rec.Code)
+		}
+	}
+}
+
+func TestHashtagHandler_GetTrendingHashtags(t *testing.T) {
+	e := echo.New()
+	handler := NewHashtagHandler(&mockHashtagService{}, newMockBookmarkService(), mockRequireAuth)
+
+	g := e.Group("/api/v1")
+	handler.Routes(g)
+
+	// Create request
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/trending/tags", nil)
+	rec := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, rec)
+
+	if assert.NoError(t, handler.getTrendingHashtags(ctx)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `[{"name":"go","score":10}]`, rec.Body.String())
+	}
+}
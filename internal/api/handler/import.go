@@ -0,0 +1,175 @@
+package handler
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/misshanya/mitter/internal/api/dto"
+	"github.com/misshanya/mitter/internal/models"
+	"github.com/misshanya/mitter/pkg/pagination"
+	"io"
+	"net/http"
+)
+
+type importService interface {
+	RequestImport(ctx context.Context, userID uuid.UUID, archive []byte) (*models.Import, *models.HTTPError)
+	GetImport(ctx context.Context, userID uuid.UUID, id uuid.UUID) (*models.Import, *models.HTTPError)
+	GetImportErrors(ctx context.Context, userID uuid.UUID, id uuid.UUID, limit, offset int32) ([]*models.ImportItemError, *models.HTTPError)
+}
+
+type ImportHandler struct {
+	is                importService
+	reqAuthMiddleware echo.MiddlewareFunc
+	maxSize           int64
+}
+
+// NewImportHandler creates import handler which accepts archives up to maxSize bytes
+func NewImportHandler(is importService, reqAuthMdl echo.MiddlewareFunc, maxSize int64) *ImportHandler {
+	return &ImportHandler{
+		is:                is,
+		reqAuthMiddleware: reqAuthMdl,
+		maxSize:           maxSize,
+	}
+}
+
+func (h *ImportHandler) Routes(group *echo.Group) {
+	group.POST("/import", h.requestImport, h.reqAuthMiddleware)
+	group.GET("/import/:id", h.getImport, h.reqAuthMiddleware)
+	group.GET("/import/:id/errors", h.getImportErrors, h.reqAuthMiddleware)
+}
+
+func importToResponse(imp *models.Import) dto.ImportResponse {
+	return dto.ImportResponse{
+		ID:         imp.ID,
+		Status:     string(imp.Status),
+		Error:      imp.Error,
+		Total:      imp.Total,
+		Processed:  imp.Processed,
+		Imported:   imp.Imported,
+		Skipped:    imp.Skipped,
+		Failed:     imp.Failed,
+		CreatedAt:  imp.CreatedAt,
+		FinishedAt: imp.FinishedAt,
+	}
+}
+
+// requestImport godoc
+//
+//	@Summary		Import mitts
+//	@Description	Import mitts from JSON array of {"id", "content", "created_at"} or ZIP with such mitts.json (data export archive works too).
+//	@Description	Original created_at is kept, mitts with already imported id are skipped.
+//	@Tags			Mitts
+//	@Security		Bearer
+//	@Param			Authorization	header	string	true	"access token 'Bearer {token}'"
+//	@Accept			json
+//	@Accept			application/zip
+//	@Param			archive	body	string	true	"Archive"
+//	@Produce		json
+//	@Success		202	{object}	dto.ImportResponse
+//	@Failure		400	{object}	dto.HTTPError
+//	@Failure		401	{object}	dto.HTTPError
+//	@Failure		413	{object}	dto.HTTPError
+//	@Failure		500	{object}	dto.HTTPError
+//	@Router			/mitt/import [post]
+func (h *ImportHandler) requestImport(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID := c.Get("userID").(uuid.UUID)
+
+	archive, err := io.ReadAll(io.LimitReader(c.Request().Body, h.maxSize+1))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+	}
+	if int64(len(archive)) > h.maxSize {
+		return c.JSON(http.StatusRequestEntityTooLarge, dto.HTTPError{Message: "Archive is too big"})
+	}
+
+	imp, httpErr := h.is.RequestImport(ctx, userID, archive)
+	if httpErr != nil {
+		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
+	}
+
+	return c.JSON(http.StatusAccepted, importToResponse(imp))
+}
+
+// getImport godoc
+//
+//	@Summary		Get import
+//	@Description	Get import status and progress
+//	@Tags			Mitts
+//	@Security		Bearer
+//	@Param			Authorization	header	string	true	"access token 'Bearer {token}'"
+//	@Param			id				path	string	true	"ID of import"
+//	@Produce		json
+//	@Success		200	{object}	dto.ImportResponse
+//	@Failure		400	{object}	dto.HTTPError
+//	@Failure		401	{object}	dto.HTTPError
+//	@Failure		404	{object}	dto.HTTPError
+//	@Failure		500	{object}	dto.HTTPError
+//	@Router			/mitt/import/{id} [get]
+func (h *ImportHandler) getImport(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID := c.Get("userID").(uuid.UUID)
+
+	importID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+	}
+
+	imp, httpErr := h.is.GetImport(ctx, userID, importID)
+	if httpErr != nil {
+		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
+	}
+
+	return c.JSON(http.StatusOK, importToResponse(imp))
+}
+
+// getImportErrors godoc
+//
+//	@Summary		Get import errors
+//	@Description	Get mitts which weren't imported and why
+//	@Tags			Mitts
+//	@Security		Bearer
+//	@Param			Authorization	header	string	true	"access token 'Bearer {token}'"
+//	@Param			id				path	string	true	"ID of import"
+//	@Param			offset			query	int		false	"Offset"
+//	@Param			limit			query	int		false	"Limit"
+//	@Produce		json
+//	@Success		200	{object}	[]dto.ImportItemErrorResponse
+//	@Failure		400	{object}	dto.HTTPError
+//	@Failure		401	{object}	dto.HTTPError
+//	@Failure		404	{object}	dto.HTTPError
+//	@Failure		500	{object}	dto.HTTPError
+//	@Router			/mitt/import/{id}/errors [get]
+func (h *ImportHandler) getImportErrors(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID := c.Get("userID").(uuid.UUID)
+
+	importID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+	}
+
+	limit, offset, err := pagination.GetLimitAndOffset(c, 30)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+	}
+
+	itemErrors, httpErr := h.is.GetImportErrors(ctx, userID, importID, limit, offset)
+	if httpErr != nil {
+		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
+	}
+
+	resp := make([]dto.ImportItemErrorResponse, len(itemErrors))
+	for i, itemErr := range itemErrors {
+		resp[i] = dto.ImportItemErrorResponse{
+			Index:      itemErr.Index,
+			ExternalID: itemErr.ExternalID,
+			Error:      itemErr.Error,
+		}
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/misshanya/mitter/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+var mockImportModel = &models.Import{
+	ID:        uuid.MustParse("0f3b8f35-2c1e-4a8e-8a53-6d1f1c9e2b77"),
+	UserID:    mockUserID,
+	Status:    models.ImportRunning,
+	Total:     10,
+	Processed: 4,
+	Imported:  3,
+	Failed:    1,
+	CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+}
+
+// Mock service
+type mockImportService struct{}
+
+func (s *mockImportService) RequestImport(ctx context.Context, userID uuid.UUID, archive []byte) (*models.Import, *models.HTTPError) {
+	_ = ctx
+
+	if len(archive) == 0 {
+		return nil, &models.HTTPError{Code: http.StatusBadRequest, Message: "Archive has no mitts"}
+	}
+	return &models.Import{ID: mockImportModel.ID, UserID: userID, Status: models.ImportPending, Total: 1}, nil
+}
+
+func (s *mockImportService) GetImport(ctx context.Context, userID uuid.UUID, id uuid.UUID) (*models.Import, *models.HTTPError) {
+	_ = ctx
+	_ = userID
+
+	if id != mockImportModel.ID {
+		return nil, &models.HTTPError{Code: http.StatusNotFound, Message: "Import not found"}
+	}
+	return mockImportModel, nil
+}
+
+func (s *mockImportService) GetImportErrors(ctx context.Context, userID uuid.UUID, id uuid.UUID, limit, offset int32) ([]*models.ImportItemError, *models.HTTPError) {
+	_ = ctx
+	_ = userID
+	_ = limit
+	_ = offset
+
+	if id != mockImportModel.ID {
+		return nil, &models.HTTPError{Code: http.StatusNotFound, Message: "Import not found"}
+	}
+	return []*models.ImportItemError{{Index: 2, ExternalID: "42", Error: "content must not be blank"}}, nil
+}
+
+// Tests
+func TestImportHandler_RequestImport(t *testing.T) {
+	e := echo.New()
+	handler := NewImportHandler(&mockImportService{}, mockRequireAuth, 100)
+
+	g := e.Group("/api/v1/mitt")
+	handler.Routes(g)
+
+	for _, tc := range []struct {
+		body string
+		code int
+	}{
+		{`[{"id": "1", "content": "hi", "created_at": "2020-01-01T00:00:00Z"}]`, http.StatusAccepted},
+		{"", http.StatusBadRequest},
+		{strings.Repeat("a", 101), http.StatusRequestEntityTooLarge},
+	} {
+		// Create request
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/mitt/import", strings.NewReader(tc.body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+
+		ctx := e.NewContext(req, rec)
+
+		if assert.NoError(t, mockRequireAuth(handler.requestImport)(ctx)) {
+			assert.Equal(t, tc.code, rec.Code)
+		}
+	}
+}
+
+func TestImportHandler_GetImport(t *testing.T) {
+	e := echo.New()
+	handler := NewImportHandler(&mockImportService{}, mockRequireAuth, 100)
+
+	g := e.Group("/api/v1/mitt")
+	handler.Routes(g)
+
+	// Create request
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/mitt/import/"+mockImportModel.ID.String(), nil)
+	rec := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, rec)
+
+	// Set path param (id)
+	ctx.SetPath("/api/v1/mitt/import/:id")
+	ctx.SetParamNames("id")
+	ctx.SetParamValues(mockImportModel.ID.String())
+
+	if assert.NoError(t, mockRequireAuth(handler.getImport)(ctx)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"status":"running"`)
+		assert.Contains(t, rec.Body.String(), `"processed":4`)
+	}
+}
+
+func TestImportHandler_GetImportErrors(t *testing.T) {
+	e := echo.New()
+	handler := NewImportHandler(&mockImportService{}, mockRequireAuth, 100)
+
+	g := e.Group("/api/v1/mitt")
+	handler.Routes(g)
+
+	// Create request
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/mitt/import/"+mockImportModel.ID.String()+"/errors", nil)
+	rec := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, rec)
+
+	// Set path param (id)
+	ctx.SetPath("/api/v1/mitt/import/:id/errors")
+	ctx.SetParamNames("id")
+	ctx.SetParamValues(mockImportModel.ID.String())
+
+	if assert.NoError(t, mockRequireAuth(handler.getImportErrors)(ctx)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"id":"42"`)
+	}
+}
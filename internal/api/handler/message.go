@@ -0,0 +1,340 @@
+package handler
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/misshanya/mitter/internal/api/dto"
+	"github.com/misshanya/mitter/internal/models"
+	"github.com/misshanya/mitter/pkg/content"
+	"github.com/misshanya/mitter/pkg/pagination"
+	"net/http"
+)
+
+type messageService interface {
+	CreateConversation(ctx context.Context, userID uuid.UUID, memberIDs []uuid.UUID) (*models.Conversation, bool, *models.HTTPError)
+	GetConversation(ctx context.Context, userID uuid.UUID, id uuid.UUID) (*models.Conversation, *models.HTTPError)
+	GetConversations(ctx context.Context, userID uuid.UUID, limit, offset int32) ([]*models.Conversation, *models.HTTPError)
+	SendMessage(ctx context.Context, userID uuid.UUID, conversationID uuid.UUID, content string) (*models.Message, *models.HTTPError)
+	GetMessages(ctx context.Context, userID uuid.UUID, conversationID uuid.UUID, before *uuid.UUID, limit int32) ([]*models.Message, *models.HTTPError)
+	MarkRead(ctx context.Context, userID uuid.UUID, conversationID uuid.UUID) *models.HTTPError
+	GetUnreadCount(ctx context.Context, userID uuid.UUID) (int64, *models.HTTPError)
+}
+
+type MessageHandler struct {
+	ms                messageService
+	reqAuthMiddleware echo.MiddlewareFunc
+	maxContentLength  int
+}
+
+func NewMessageHandler(ms messageService, reqAuthMdl echo.MiddlewareFunc, maxContentLength int) *MessageHandler {
+	return &MessageHandler{
+		ms:                ms,
+		reqAuthMiddleware: reqAuthMdl,
+		maxContentLength:  maxContentLength,
+	}
+}
+
+func (h *MessageHandler) Routes(group *echo.Group) {
+	group.POST("", h.createConversation, h.reqAuthMiddleware)
+	group.GET("", h.getConversations, h.reqAuthMiddleware)
+	group.GET("/unread-count", h.getUnreadCount, h.reqAuthMiddleware)
+	group.GET("/:id", h.getConversation, h.reqAuthMiddleware)
+	group.GET("/:id/messages", h.getMessages, h.reqAuthMiddleware)
+	group.POST("/:id/messages", h.sendMessage, h.reqAuthMiddleware)
+	group.POST("/:id/read", h.markRead, h.reqAuthMiddleware)
+}
+
+func messageToResponse(message *models.Message) dto.MessageResponse {
+	return dto.MessageResponse{
+		ID:             message.ID,
+		ConversationID: message.ConversationID,
+		SenderID:       message.SenderID,
+		Content:        message.Content,
+		CreatedAt:      message.CreatedAt,
+	}
+}
+
+func conversationToResponse(conversation *models.Conversation) dto.ConversationResponse {
+	resp := dto.ConversationResponse{
+		ID:          conversation.ID,
+		IsGroup:     conversation.IsGroup,
+		Members:     usersToResponse(conversation.Members),
+		UnreadCount: conversation.UnreadCount,
+		CreatedAt:   conversation.CreatedAt,
+	}
+	if conversation.LastMessage != nil {
+		lastMessage := messageToResponse(conversation.LastMessage)
+		resp.LastMessage = &lastMessage
+	}
+	return resp
+}
+
+// createConversation godoc
+//
+//	@Summary		Start conversation
+//	@Description	Start one-to-one conversation with one user or group conversation with several users.
+//	@Description	If we already have one-to-one conversation, it's returned with 200.
+//	@Description	Users who blocked me, whom I blocked or who accept messages only from people they follow can't be added
+//	@Tags			Messages
+//	@Security		Bearer
+//	@Param			Authorization	header	string							true	"access token 'Bearer {token}'"
+//	@Param			conversation	body	dto.ConversationCreateRequest	true	"Members"
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	dto.ConversationResponse
+//	@Success		201	{object}	dto.ConversationResponse
+//	@Failure		400	{object}	dto.HTTPError
+//	@Failure		401	{object}	dto.HTTPError
+//	@Failure		403	{object}	dto.HTTPError
+//	@Failure		404	{object}	dto.HTTPError
+//	@Failure		500	{object}	dto.HTTPError
+//	@Router			/conversation [post]
+func (h *MessageHandler) createConversation(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID := c.Get("userID").(uuid.UUID)
+
+	var req dto.ConversationCreateRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+	}
+
+	conversation, created, httpErr := h.ms.CreateConversation(ctx, userID, req.UserIDs)
+	if httpErr != nil {
+		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
+	}
+
+	code := http.StatusOK
+	if created {
+		code = http.StatusCreated
+	}
+
+	return c.JSON(code, conversationToResponse(conversation))
+}
+
+// getConversations godoc
+//
+//	@Summary		Get my conversations
+//	@Description	Conversations with the latest message first
+//	@Tags			Messages
+//	@Security		Bearer
+//	@Param			Authorization	header	string	true	"access token 'Bearer {token}'"
+//	@Param			offset			query	int		false	"Offset"
+//	@Param			limit			query	int		false	"Limit"
+//	@Produce		json
+//	@Success		200	{object}	[]dto.ConversationResponse
+//	@Failure		400	{object}	dto.HTTPError
+//	@Failure		401	{object}	dto.HTTPError
+//	@Failure		500	{object}	dto.HTTPError
+//	@Router			/conversation [get]
+func (h *MessageHandler) getConversations(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID := c.Get("userID").(uuid.UUID)
+
+	limit, offset, err := pagination.GetLimitAndOffset(c, 30)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+	}
+
+	conversations, httpErr := h.ms.GetConversations(ctx, userID, limit, offset)
+	if httpErr != nil {
+		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
+	}
+
+	resp := make([]dto.ConversationResponse, len(conversations))
+	for i, conversation := range conversations {
+		resp[i] = conversationToResponse(conversation)
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// getUnreadCount godoc
+//
+//	@Summary		Get unread messages count
+//	@Description	Count of unread messages in all my conversations
+//	@Tags			Messages
+//	@Security		Bearer
+//	@Param			Authorization	header	string	true	"access token 'Bearer {token}'"
+//	@Produce		json
+//	@Success		200	{object}	dto.UnreadCountResponse
+//	@Failure		401	{object}	dto.HTTPError
+//	@Failure		500	{object}	dto.HTTPError
+//	@Router			/conversation/unread-count [get]
+func (h *MessageHandler) getUnreadCount(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID := c.Get("userID").(uuid.UUID)
+
+	count, httpErr := h.ms.GetUnreadCount(ctx, userID)
+	if httpErr != nil {
+		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
+	}
+
+	return c.JSON(http.StatusOK, dto.UnreadCountResponse{Count: count})
+}
+
+// getConversation godoc
+//
+//	@Summary	Get conversation
+//	@Tags		Messages
+//	@Security	Bearer
+//	@Param		Authorization	header	string	true	"access token 'Bearer {token}'"
+//	@Param		id				path	string	true	"ID of conversation"
+//	@Produce	json
+//	@Success	200	{object}	dto.ConversationResponse
+//	@Failure	400	{object}	dto.HTTPError
+//	@Failure	401	{object}	dto.HTTPError
+//	@Failure	404	{object}	dto.HTTPError
+//	@Failure	500	{object}	dto.HTTPError
+//	@Router		/conversation/{id} [get]
+func (h *MessageHandler) getConversation(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID := c.Get("userID").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+	}
+
+	conversation, httpErr := h.ms.GetConversation(ctx, userID, id)
+	if httpErr != nil {
+		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
+	}
+
+	return c.JSON(http.StatusOK, conversationToResponse(conversation))
+}
+
+// getMessages godoc
+//
+//	@Summary		Get messages
+//	@Description	Messages of conversation, newest first. To get the next page pass ID of the last message as before
+//	@Tags			Messages
+//	@Security		Bearer
+//	@Param			Authorization	header	string	true	"access token 'Bearer {token}'"
+//	@Param			id				path	string	true	"ID of conversation"
+//	@Param			before			query	string	false	"ID of message, only older messages are returned"
+//	@Param			limit			query	int		false	"Limit"
+//	@Produce		json
+//	@Success		200	{object}	[]dto.MessageResponse
+//	@Failure		400	{object}	dto.HTTPError
+//	@Failure		401	{object}	dto.HTTPError
+//	@Failure		404	{object}	dto.HTTPError
+//	@Failure		500	{object}	dto.HTTPError
+//	@Router			/conversation/{id}/messages [get]
+func (h *MessageHandler) getMessages(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID := c.Get("userID").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+	}
+
+	var before *uuid.UUID
+	if beforeStr := c.QueryParam("before"); beforeStr != "" {
+		beforeID, err := uuid.Parse(beforeStr)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: "invalid before"})
+		}
+		before = &beforeID
+	}
+
+	limit, _, err := pagination.GetLimitAndOffset(c, 50)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+	}
+
+	messages, httpErr := h.ms.GetMessages(ctx, userID, id, before, limit)
+	if httpErr != nil {
+		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
+	}
+
+	resp := make([]dto.MessageResponse, len(messages))
+	for i, message := range messages {
+		resp[i] = messageToResponse(message)
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// sendMessage godoc
+//
+//	@Summary		Send message
+//	@Description	In one-to-one conversation the other member must still accept messages from me
+//	@Tags			Messages
+//	@Security		Bearer
+//	@Param			Authorization	header	string						true	"access token 'Bearer {token}'"
+//	@Param			id				path	string						true	"ID of conversation"
+//	@Param			message			body	dto.MessageCreateRequest	true	"Message"
+//	@Accept			json
+//	@Produce		json
+//	@Success		201	{object}	dto.MessageResponse
+//	@Failure		400	{object}	dto.ValidationError
+//	@Failure		401	{object}	dto.HTTPError
+//	@Failure		403	{object}	dto.HTTPError
+//	@Failure		404	{object}	dto.HTTPError
+//	@Failure		500	{object}	dto.HTTPError
+//	@Router			/conversation/{id}/messages [post]
+func (h *MessageHandler) sendMessage(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID := c.Get("userID").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+	}
+
+	var req dto.MessageCreateRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+	}
+
+	// Validate
+	req.Content = content.Normalize(req.Content)
+	if err := content.Check(req.Content, h.maxContentLength); err != nil {
+		return validationFailed(c, []dto.FieldError{{Field: "content", Error: err.Error()}})
+	}
+
+	message, httpErr := h.ms.SendMessage(ctx, userID, id, req.Content)
+	if httpErr != nil {
+		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
+	}
+
+	return c.JSON(http.StatusCreated, messageToResponse(message))
+}
+
+// markRead godoc
+//
+//	@Summary	Mark conversation read
+//	@Tags		Messages
+//	@Security	Bearer
+//	@Param		Authorization	header	string	true	"access token 'Bearer {token}'"
+//	@Param		id				path	string	true	"ID of conversation"
+//	@Success	204
+//	@Failure	400	{object}	dto.HTTPError
+//	@Failure	401	{object}	dto.HTTPError
+//	@Failure	404	{object}	dto.HTTPError
+//	@Failure	500	{object}	dto.HTTPError
+//	@Router		/conversation/{id}/read [post]
+func (h *MessageHandler) markRead(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID := c.Get("userID").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+	}
+
+	if httpErr := h.ms.MarkRead(ctx, userID, id); httpErr != nil {
+		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,264 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/misshanya/mitter/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+var mockMessage = &models.Message{
+	ID:             uuid.MustParse("2e7a0f9c-3d4e-4f5a-9b62-7c8d9e0f1a2b"),
+	ConversationID: mockConversation.ID,
+	SenderID:       mockUserID,
+	Content:        "hi",
+	CreatedAt:      time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+}
+
+var mockConversation = &models.Conversation{
+	ID:          uuid.MustParse("3f8b1a0d-4e5f-4a6b-8c73-8d9e0f1a2b3c"),
+	MemberIDs:   []uuid.UUID{mockUserID},
+	Members:     []*models.User{{ID: mockUserID, Login: "member", Name: "Member"}},
+	UnreadCount: 2,
+	CreatedAt:   time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+}
+
+// Mock service
+type mockMessageService struct{}
+
+func (s *mockMessageService) CreateConversation(ctx context.Context, userID uuid.UUID, memberIDs []uuid.UUID) (*models.Conversation, bool, *models.HTTPError) {
+	_ = ctx
+	_ = userID
+
+	if len(memberIDs) == 0 {
+		return nil, false, &models.HTTPError{Code: http.StatusBadRequest, Message: "At least one other member is required"}
+	}
+	return mockConversation, true, nil
+}
+
+func (s *mockMessageService) GetConversation(ctx context.Context, userID uuid.UUID, id uuid.UUID) (*models.Conversation, *models.HTTPError) {
+	_ = ctx
+	_ = userID
+
+	if id != mockConversation.ID {
+		return nil, &models.HTTPError{Code: http.StatusNotFound, Message: "Conversation not found"}
+	}
+	return mockConversation, nil
+}
+
+func (s *mockMessageService) GetConversations(ctx context.Context, userID uuid.UUID, limit, offset int32) ([]*models.Conversation, *models.HTTPError) {
+	_ = ctx
+	_ = userID
+	_ = limit
+	_ = offset
+
+	return []*models.Conversation{mockConversation}, nil
+}
+
+func (s *mockMessageService) SendMessage(ctx context.Context, userID uuid.UUID, conversationID uuid.UUID, content string) (*models.Message, *models.HTTPError) {
+	_ = ctx
+
+	if conversationID != mockConversation.ID {
+		return nil, &models.HTTPError{Code: http.StatusNotFound, Message: "Conversation not found"}
+	}
+	return &models.Message{
+		ID:             mockMessage.ID,
+		ConversationID: conversationID,
+		SenderID:       userID,
+		Content:        content,
+		CreatedAt:      mockMessage.CreatedAt,
+	}, nil
+}
+
+func (s *mockMessageService) GetMessages(ctx context.Context, userID uuid.UUID, conversationID uuid.UUID, before *uuid.UUID, limit int32) ([]*models.Message, *models.HTTPError) {
+	_ = ctx
+	_ = userID
+	_ = limit
+
+	if conversationID != mockConversation.ID {
+		return nil, &models.HTTPError{Code: http.StatusNotFound, Message: "Conversation not found"}
+	}
+	if before != nil {
+		return []*models.Message{}, nil
+	}
+	return []*models.Message{mockMessage}, nil
+}
+
+func (s *mockMessageService) MarkRead(ctx context.Context, userID uuid.UUID, conversationID uuid.UUID) *models.HTTPError {
+	_ = ctx
+	_ = userID
+
+	if conversationID != mockConversation.ID {
+		return &models.HTTPError{Code: http.StatusNotFound, Message: "Conversation not found"}
+	}
+	return nil
+}
+
+func (s *mockMessageService) GetUnreadCount(ctx context.Context, userID uuid.UUID) (int64, *models.HTTPError) {
+	_ = ctx
+	_ = userID
+
+	return 2, nil
+}
+
+// Tests
+func TestMessageHandler_CreateConversation(t *testing.T) {
+	e := echo.New()
+	handler := NewMessageHandler(&mockMessageService{}, mockRequireAuth, 1000)
+
+	g := e.Group("/api/v1/conversation")
+	handler.Routes(g)
+
+	for _, tc := range []struct {
+		body string
+		code int
+	}{
+		{`{"user_ids":["` + mockUserID.String() + `"]}`, http.StatusCreated},
+		{`{"user_ids":[]}`, http.StatusBadRequest},
+		{`{"user_ids":["not-uuid"]}`, http.StatusBadRequest},
+	} {
+		// Create request
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/conversation", strings.NewReader(tc.body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+
+		ctx := e.NewContext(req, rec)
+
+		if assert.NoError(t, mockRequireAuth(handler.createConversation)(ctx)) {
+			assert.Equal(t, tc.code, rec.Code, tc.body)
+		}
+	}
+}
+
+func TestMessageHandler_GetConversations(t *testing.T) {
+	e := echo.New()
+	handler := NewMessageHandler(&mockMessageService{}, mockRequireAuth, 1000)
+
+	g := e.Group("/api/v1/conversation")
+	handler.Routes(g)
+
+	// Create request
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/conversation", nil)
+	rec := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, rec)
+
+	if assert.NoError(t, mockRequireAuth(handler.getConversations)(ctx)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"unread_count":2`)
+		assert.Contains(t, rec.Body.String(), `"login":"member"`)
+	}
+}
+
+func TestMessageHandler_SendMessage(t *testing.T) {
+	e := echo.New()
+	handler := NewMessageHandler(&mockMessageService{}, mockRequireAuth, 5)
+
+	g := e.Group("/api/v1/conversation")
+	handler.Routes(g)
+
+	for _, tc := range []struct {
+		id   string
+		body string
+		code int
+	}{
+		{mockConversation.ID.String(), `{"content":"  hi  "}`, http.StatusCreated},
+		{mockConversation.ID.String(), `{"content":"   "}`, http.StatusBadRequest},
+		{mockConversation.ID.String(), `{"content":"too long"}`, http.StatusBadRequest},
+		{uuid.NewString(), `{"content":"hi"}`, http.StatusNotFound},
+		{"not-uuid", `{"content":"hi"}`, http.StatusBadRequest},
+	} {
+		// Create request
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/conversation/"+tc.id+"/messages", strings.NewReader(tc.body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+
+		ctx := e.NewContext(req, rec)
+
+		// Set path param (id)
+		ctx.SetPath("/api/v1/conversation/:id/messages")
+		ctx.SetParamNames("id")
+		ctx.SetParamValues(tc.id)
+
+		if assert.NoError(t, mockRequireAuth(handler.sendMessage)(ctx)) {
+			assert.Equal(t, tc.code, rec.Code, tc.body)
+		}
+
+		// Content is normalized
+		if tc.code == http.StatusCreated {
+			assert.Contains(t, rec.Body.String(), `"content":"hi"`)
+		}
+	}
+}
+
+func TestMessageHandler_GetMessages(t *testing.T) {
+	e := echo.New()
+	handler := NewMessageHandler(&mockMessageService{}, mockRequireAuth, 1000)
+
+	g := e.Group("/api/v1/conversation")
+	handler.Routes(g)
+
+	for _, tc := range []struct {
+		query string
+		code  int
+		body  string
+	}{
+		{"", http.StatusOK, `"content":"hi"`},
+		{"?before=" + mockMessage.ID.String(), http.StatusOK, `[]`},
+		{"?before=not-uuid", http.StatusBadRequest, `invalid before`},
+	} {
+		// Create request
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/conversation/"+mockConversation.ID.String()+"/messages"+tc.query, nil)
+		rec := httptest.NewRecorder()
+
+		ctx := e.NewContext(req, rec)
+
+		// Set path param (id)
+		ctx.SetPath("/api/v1/conversation/:id/messages")
+		ctx.SetParamNames("id")
+		ctx.SetParamValues(mockConversation.ID.String())
+
+		if assert.NoError(t, mockRequireAuth(handler.getMessages)(ctx)) {
+			assert.Equal(t, tc.code, rec.Code, tc.query)
+			assert.Contains(t, rec.Body.String(), tc.body)
+		}
+	}
+}
+
+func TestMessageHandler_MarkRead(t *testing.T) {
+	e := echo.New()
+	handler := NewMessageHandler(&mockMessageService{}, mockRequireAuth, 1000)
+
+	g := e.Group("/api/v1/conversation")
+	handler.Routes(g)
+
+	for _, tc := range []struct {
+		id   string
+		code int
+	}{
+		{mockConversation.ID.String(), http.StatusNoContent},
+		{uuid.NewString(), http.StatusNotFound},
+	} {
+		// Create request
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/conversation/"+tc.id+"/read", nil)
+		rec := httptest.NewRecorder()
+
+		ctx := e.NewContext(req, rec)
+
+		// Set path param (id)
+		ctx.SetPath("/api/v1/conversation/:id/read")
+		ctx.SetParamNames("id")
+		ctx.SetParamValues(tc.id)
+
+		if assert.NoError(t, mockRequireAuth(handler.markRead)(ctx)) {
+			assert.Equal(t, tc.code, rec.Code)
+		}
+	}
+}
@@ -7,8 +7,10 @@ import (
 	"github.com/labstack/echo/v4"
 	"github.com/misshanya/mitter/internal/api/dto"
 	"github.com/misshanya/mitter/internal/models"
+	"github.com/misshanya/mitter/pkg/content"
 	"github.com/misshanya/mitter/pkg/pagination"
 	"net/http"
+	"time"
 )
 
 type mittService interface {
@@ -21,37 +23,161 @@ type mittService interface {
 
 	DeleteMitt(ctx context.Context, userID uuid.UUID, mittID uuid.UUID) *models.HTTPError
 
+	GetMittHistory(ctx context.Context, mittID uuid.UUID, limit, offset int32) ([]*models.MittRevision, *models.HTTPError)
+
+	// Pins
+
+	PinMitt(ctx context.Context, userID uuid.UUID, mittID uuid.UUID) *models.HTTPError
+	UnpinMitt(ctx context.Context, userID uuid.UUID, mittID uuid.UUID) *models.HTTPError
+
+	// Trash
+
+	RestoreMitt(ctx context.Context, userID uuid.UUID, mittID uuid.UUID) *models.HTTPError
+	GetTrash(ctx context.Context, userID uuid.UUID, limit, offset int32) ([]*models.Mitt, *models.HTTPError)
+
 	// Likes
 
+	LikeMitt(ctx context.Context, userID uuid.UUID, mittID uuid.UUID) *models.HTTPError
+	UnlikeMitt(ctx context.Context, userID uuid.UUID, mittID uuid.UUID) *models.HTTPError
 	SwitchLike(ctx context.Context, userID uuid.UUID, mittID uuid.UUID) (bool, *models.HTTPError)
 
 	Feed(ctx context.Context, limit, offset int32) ([]*models.Mitt, *models.HTTPError)
+	GetMentions(ctx context.Context, userID uuid.UUID, limit, offset int32) ([]*models.Mitt, *models.HTTPError)
+}
+
+// mittMarker sets fields of mitts which depend on current user, it's used by every handler that returns mitts
+type mittMarker interface {
+	MarkMitts(ctx context.Context, userID uuid.UUID, mitts []*models.Mitt) *models.HTTPError
+}
+
+// markMitts marks mitts for current user, anonymous requests get them unmarked
+func markMitts(c echo.Context, mm mittMarker, mitts ...*models.Mitt) *models.HTTPError {
+	userID, ok := c.Get("userID").(uuid.UUID)
+	if !ok {
+		return nil
+	}
+	return mm.MarkMitts(c.Request().Context(), userID, mitts)
 }
 
 type MittHandler struct {
 	ms                mittService
+	mm                mittMarker
 	validate          *validator.Validate
 	reqAuthMiddleware echo.MiddlewareFunc
+	optAuthMiddleware echo.MiddlewareFunc
+
+	maxContentLength int
 }
 
-func NewMittHandler(ms mittService, reqAuthMdl echo.MiddlewareFunc) *MittHandler {
+// NewMittHandler creates mitt handler which accepts content up to maxContentLength characters.
+// Public routes use optAuthMdl, so mitts are marked for signed in user
+func NewMittHandler(ms mittService, mm mittMarker, reqAuthMdl, optAuthMdl echo.MiddlewareFunc, maxContentLength int) *MittHandler {
 	return &MittHandler{
 		ms:                ms,
-		validate:          validator.New(),
+		mm:                mm,
+		validate:          newValidator(),
 		reqAuthMiddleware: reqAuthMdl,
+		optAuthMiddleware: optAuthMdl,
+		maxContentLength:  maxContentLength,
 	}
 }
 
+func mittToResponse(mitt *models.Mitt) dto.MittResponse {
+	mentions := make([]dto.MentionResponse, len(mitt.Mentions))
+	for i, m := range mitt.Mentions {
+		mentions[i] = dto.MentionResponse{
+			UserID: m.UserID,
+			Login:  m.Login,
+			Start:  m.Start,
+			End:    m.End,
+		}
+	}
+
+	return dto.MittResponse{
+		ID:         mitt.ID,
+		Author:     mitt.AuthorID,
+		AuthorName: mitt.AuthorName,
+		Content:    mitt.Content,
+		CreatedAt:  mitt.CreatedAt,
+		UpdatedAt:  mitt.UpdatedAt,
+		Likes:      mitt.Likes,
+		Edited:     mitt.Revisions > 0,
+		Revisions:  mitt.Revisions,
+		DeletedAt:  mitt.DeletedAt,
+		ExpiresAt:  mitt.ExpiresAt,
+		Mentions:   mentions,
+		Bookmarked: mitt.Bookmarked,
+		Poll:       pollToResponse(mitt.Poll),
+		Pinned:     mitt.Pinned,
+	}
+}
+
+// pollToResponse hides votes from users who can't see results yet
+func pollToResponse(poll *models.Poll) *dto.PollResponse {
+	if poll == nil {
+		return nil
+	}
+
+	now := time.Now()
+	visible := poll.ResultsVisible(now)
+
+	options := make([]dto.PollOptionResponse, len(poll.Options))
+	for i, o := range poll.Options {
+		options[i] = dto.PollOptionResponse{Text: o.Text}
+		if visible {
+			options[i].Votes = &o.Votes
+		}
+	}
+
+	return &dto.PollResponse{
+		Options:        options,
+		MultipleChoice: poll.MultipleChoice,
+		ExpiresAt:      poll.ExpiresAt,
+		Expired:        poll.Expired(now),
+		VotersCount:    poll.VotersCount,
+		ResultsVisible: visible,
+		Voted:          poll.Voted,
+		Choices:        poll.Choices,
+	}
+}
+
+func mittsToResponse(mitts []*models.Mitt) []dto.MittResponse {
+	resp := make([]dto.MittResponse, len(mitts))
+	for i, mitt := range mitts {
+		resp[i] = mittToResponse(mitt)
+	}
+	return resp
+}
+
+// validateContent normalizes mitt content and returns offending fields if it's invalid
+func (h *MittHandler) validateContent(text *string) []dto.FieldError {
+	*text = content.Normalize(*text)
+	if err := content.Check(*text, h.maxContentLength); err != nil {
+		return []dto.FieldError{{Field: "content", Error: err.Error()}}
+	}
+	return nil
+}
+
 func (h *MittHandler) Routes(group *echo.Group) {
 	group.POST("", h.createMitt, h.reqAuthMiddleware)
-	group.GET("/:id", h.getMitt)
-	group.GET("/user/:id", h.getAllUserMitts)
+	group.GET("/:id", h.getMitt, h.optAuthMiddleware)
+	group.GET("/user/:id", h.getAllUserMitts, h.optAuthMiddleware)
 	group.PUT("/:id", h.updateMitt, h.reqAuthMiddleware)
 	group.DELETE("/:id", h.deleteMitt, h.reqAuthMiddleware)
+	group.GET("/:id/history", h.getMittHistory)
+
+	group.GET("/trash", h.getTrash, h.reqAuthMiddleware)
+	group.POST("/:id/restore", h.restoreMitt, h.reqAuthMiddleware)
+
+	group.POST("/:id/pin", h.pinMitt, h.reqAuthMiddleware)
+	group.DELETE("/:id/pin", h.unpinMitt, h.reqAuthMiddleware)
 
 	group.POST("/:id/like", h.likeMitt, h.reqAuthMiddleware)
+	group.PUT("/:id/like", h.putLike, h.reqAuthMiddleware)
+	group.DELETE("/:id/like", h.deleteLike, h.reqAuthMiddleware)
 
-	group.GET("/feed", h.feed)
+	group.GET("/feed", h.feed, h.optAuthMiddleware)
+	group.GET("/mentions", h.getMentions, h.reqAuthMiddleware)
 }
 
 // createMitt godoc
@@ -64,7 +190,7 @@ func (h *MittHandler) Routes(group *echo.Group) {
 //	@Param		CreateMittRequest	body	dto.MittCreateRequest	true	"Create Mitt Request"
 //	@Produce	json
 //	@Success	201	{object}	dto.MittResponse
-//	@Failure	400	{object}	dto.HTTPError
+//	@Failure	400	{object}	dto.ValidationError
 //	@Failure	401	{object}	dto.HTTPError
 //	@Failure	500	{object}	dto.HTTPError
 //	@Router		/mitt [post]
@@ -80,36 +206,44 @@ func (h *MittHandler) createMitt(c echo.Context) error {
 
 	// Validate
 	if err := h.validate.Struct(req); err != nil {
-		return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+		return validationFailed(c, fieldErrors(err))
+	}
+	if fields := h.validateContent(&req.Content); fields != nil {
+		return validationFailed(c, fields)
 	}
 
 	mittCreate := &models.MittCreate{
-		Content: req.Content,
+		Content:   req.Content,
+		ExpiresAt: req.ExpiresAt,
+	}
+	if req.Poll != nil {
+		mittCreate.Poll = &models.PollCreate{
+			Options:        req.Poll.Options,
+			MultipleChoice: req.Poll.MultipleChoice,
+			ExpiresAt:      req.Poll.ExpiresAt,
+		}
 	}
 	mitt, err := h.ms.CreateMitt(ctx, userID, mittCreate)
 	if err != nil {
 		return c.JSON(err.Code, dto.HTTPError{Message: err.Message})
 	}
 
-	resp := dto.MittResponse{
-		ID:         mitt.ID,
-		Author:     mitt.AuthorID,
-		AuthorName: mitt.AuthorName,
-		Content:    mitt.Content,
-		CreatedAt:  mitt.CreatedAt,
-		UpdatedAt:  mitt.UpdatedAt,
-	}
-	return c.JSON(http.StatusCreated, resp)
+	return c.JSON(http.StatusCreated, mittToResponse(mitt))
 }
 
 // getMitt godoc
 //
 //	@Summary	Get Mitt
 //	@Tags		Mitts
-//	@Param		id	path	string	true	"ID of mitt"
+//	@Param		Authorization	header	string	false	"access token 'Bearer {token}', marks my bookmark and poll vote"
+//	@Param		id				path	string	true	"ID of mitt"
+//	@Param		If-None-Match	header	string	false	"ETag of cached mitt"
 //	@Produce	json
 //	@Success	200	{object}	dto.MittResponse
+//	@Header		200	{string}	ETag	"Version and likes of mitt"
+//	@Success	304
 //	@Failure	400	{object}	dto.HTTPError
+//	@Failure	401	{object}	dto.HTTPError
 //	@Failure	404	{object}	dto.HTTPError
 //	@Failure	500	{object}	dto.HTTPError
 //	@Router		/mitt/{id} [get]
@@ -126,29 +260,32 @@ func (h *MittHandler) getMitt(c echo.Context) error {
 	if httpErr != nil {
 		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
 	}
+	if httpErr := markMitts(c, h.mm, mitt); httpErr != nil {
+		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
+	}
 
-	resp := dto.MittResponse{
-		ID:         mitt.ID,
-		Author:     mitt.AuthorID,
-		AuthorName: mitt.AuthorName,
-		Content:    mitt.Content,
-		CreatedAt:  mitt.CreatedAt,
-		UpdatedAt:  mitt.UpdatedAt,
-		Likes:      mitt.Likes,
+	etag := mittETag(mitt)
+	setETag(c, etag)
+	if notModified(c, etag) {
+		return c.NoContent(http.StatusNotModified)
 	}
-	return c.JSON(http.StatusOK, resp)
+
+	return c.JSON(http.StatusOK, mittToResponse(mitt))
 }
 
 // getAllUserMitts godoc
 //
-//	@Summary	Get User Mitts
-//	@Tags		Mitts
-//	@Param		id		path	string	true	"ID of user"
-//	@Param		offset	query	int		false	"Offset"
-//	@Param		limit	query	int		false	"Limit"
+//	@Summary		Get User Mitts
+//	@Description	Pinned mitts go first, recently pinned first, then the rest, oldest first
+//	@Tags			Mitts
+//	@Param		Authorization	header	string	false	"access token 'Bearer {token}', marks my bookmarks and poll votes"
+//	@Param		id				path	string	true	"ID of user"
+//	@Param		offset			query	int		false	"Offset"
+//	@Param		limit			query	int		false	"Limit"
 //	@Produce	json
 //	@Success	200	{object}	[]dto.MittResponse
 //	@Failure	400	{object}	dto.HTTPError
+//	@Failure	401	{object}	dto.HTTPError
 //	@Failure	404	{object}	dto.HTTPError
 //	@Failure	500	{object}	dto.HTTPError
 //	@Router		/mitt/user/{id} [get]
@@ -170,20 +307,11 @@ func (h *MittHandler) getAllUserMitts(c echo.Context) error {
 	if httpErr != nil {
 		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
 	}
-
-	resp := make([]dto.MittResponse, len(mitts))
-	for i, m := range mitts {
-		resp[i] = dto.MittResponse{
-			ID:         m.ID,
-			Author:     m.AuthorID,
-			AuthorName: m.AuthorName,
-			Content:    m.Content,
-			CreatedAt:  m.CreatedAt,
-			UpdatedAt:  m.UpdatedAt,
-			Likes:      m.Likes,
-		}
+	if httpErr := markMitts(c, h.mm, mitts...); httpErr != nil {
+		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
 	}
-	return c.JSON(http.StatusOK, resp)
+
+	return c.JSON(http.StatusOK, mittsToResponse(mitts))
 }
 
 // updateMitt godoc
@@ -193,14 +321,17 @@ func (h *MittHandler) getAllUserMitts(c echo.Context) error {
 //	@Security	Bearer
 //	@Param		Authorization	header	string	true	"access token 'Bearer {token}'"
 //	@Param		id				path	string	true	"ID of mitt"
+//	@Param		If-Match		header	string	false	"Update only if mitt still has this ETag"
 //	@Accept		json
 //	@Param		UpdateMittRequest	body	dto.MittUpdateRequest	true	"Update Mitt Request"
 //	@Produce	json
 //	@Success	200	{object}	dto.MittResponse
-//	@Failure	400	{object}	dto.HTTPError
+//	@Header		200	{string}	ETag	"New version of mitt"
+//	@Failure	400	{object}	dto.ValidationError
 //	@Failure	401	{object}	dto.HTTPError
 //	@Failure	403	{object}	dto.HTTPError
 //	@Failure	404	{object}	dto.HTTPError
+//	@Failure	412	{object}	dto.HTTPError
 //	@Failure	500	{object}	dto.HTTPError
 //	@Router		/mitt/{id} [put]
 func (h *MittHandler) updateMitt(c echo.Context) error {
@@ -221,44 +352,153 @@ func (h *MittHandler) updateMitt(c echo.Context) error {
 
 	// Validate
 	if err := h.validate.Struct(req); err != nil {
-		return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+		return validationFailed(c, fieldErrors(err))
+	}
+	if fields := h.validateContent(&req.Content); fields != nil {
+		return validationFailed(c, fields)
 	}
 
 	updateMitt := &models.MittUpdate{
-		Content: req.Content,
+		Content:         req.Content,
+		ExpectedVersion: ifMatchVersion(c),
 	}
 	newMitt, httpErr := h.ms.UpdateMitt(ctx, userID, mittID, updateMitt)
 	if httpErr != nil {
 		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
 	}
+	if httpErr := markMitts(c, h.mm, newMitt); httpErr != nil {
+		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
+	}
+
+	setETag(c, mittETag(newMitt))
+	return c.JSON(http.StatusOK, mittToResponse(newMitt))
+}
+
+// deleteMitt godoc
+//
+//	@Tags			Mitts
+//	@Summary		Delete mitt
+//	@Description	Moves mitt to trash, it can be restored during retention period
+//	@Security		Bearer
+//	@Param			Authorization	header	string	true	"access token 'Bearer {token}'"
+//	@Param			id				path	string	true	"ID of mitt"
+//	@Produce		json
+//	@Success		204
+//	@Failure		400	{object}	dto.HTTPError
+//	@Failure		401	{object}	dto.HTTPError
+//	@Failure		403	{object}	dto.HTTPError
+//	@Failure		500	{object}	dto.HTTPError
+//	@Router			/mitt/{id} [delete]
+func (h *MittHandler) deleteMitt(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID := c.Get("userID").(uuid.UUID)
+
+	mittIDStr := c.Param("id")
+	mittID, err := uuid.Parse(mittIDStr)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+	}
+
+	if httpErr := h.ms.DeleteMitt(ctx, userID, mittID); httpErr != nil {
+		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// getMittHistory godoc
+//
+//	@Summary		Get Mitt History
+//	@Description	Previous versions of edited mitt, newest first
+//	@Tags			Mitts
+//	@Param			id		path	string	true	"ID of mitt"
+//	@Param			offset	query	int		false	"Offset"
+//	@Param			limit	query	int		false	"Limit"
+//	@Produce		json
+//	@Success		200	{object}	[]dto.MittRevisionResponse
+//	@Failure		400	{object}	dto.HTTPError
+//	@Failure		404	{object}	dto.HTTPError
+//	@Failure		500	{object}	dto.HTTPError
+//	@Router			/mitt/{id}/history [get]
+func (h *MittHandler) getMittHistory(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	mittIDStr := c.Param("id")
+	mittID, err := uuid.Parse(mittIDStr)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+	}
+
+	limit, offset, err := pagination.GetLimitAndOffset(c, 30)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+	}
+
+	revisions, httpErr := h.ms.GetMittHistory(ctx, mittID, limit, offset)
+	if httpErr != nil {
+		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
+	}
 
-	resp := dto.MittResponse{
-		ID:         newMitt.ID,
-		Author:     newMitt.AuthorID,
-		AuthorName: newMitt.AuthorName,
-		Content:    newMitt.Content,
-		CreatedAt:  newMitt.CreatedAt,
-		UpdatedAt:  newMitt.UpdatedAt,
-		Likes:      newMitt.Likes,
+	resp := make([]dto.MittRevisionResponse, len(revisions))
+	for i, rev := range revisions {
+		resp[i] = dto.MittRevisionResponse{
+			ID:        rev.ID,
+			Content:   rev.Content,
+			CreatedAt: rev.CreatedAt,
+		}
 	}
 	return c.JSON(http.StatusOK, resp)
 }
 
-// deleteMitt godoc
+// Trash
+
+// getTrash godoc
+//
+//	@Summary		Get Trash
+//	@Description	My deleted mitts which can still be restored, recently deleted first
+//	@Tags			Mitts
+//	@Security		Bearer
+//	@Param			Authorization	header	string	true	"access token 'Bearer {token}'"
+//	@Param			offset			query	int		false	"Offset"
+//	@Param			limit			query	int		false	"Limit"
+//	@Produce		json
+//	@Success		200	{object}	[]dto.MittResponse
+//	@Failure		400	{object}	dto.HTTPError
+//	@Failure		401	{object}	dto.HTTPError
+//	@Failure		500	{object}	dto.HTTPError
+//	@Router			/mitt/trash [get]
+func (h *MittHandler) getTrash(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID := c.Get("userID").(uuid.UUID)
+
+	limit, offset, err := pagination.GetLimitAndOffset(c, 30)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+	}
+
+	mitts, httpErr := h.ms.GetTrash(ctx, userID, limit, offset)
+	if httpErr != nil {
+		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
+	}
+
+	return c.JSON(http.StatusOK, mittsToResponse(mitts))
+}
+
+// restoreMitt godoc
 //
+//	@Summary	Restore Mitt
 //	@Tags		Mitts
-//	@Summary	Delete mitt
 //	@Security	Bearer
 //	@Param		Authorization	header	string	true	"access token 'Bearer {token}'"
 //	@Param		id				path	string	true	"ID of mitt"
-//	@Produce	json
 //	@Success	204
 //	@Failure	400	{object}	dto.HTTPError
 //	@Failure	401	{object}	dto.HTTPError
-//	@Failure	403	{object}	dto.HTTPError
+//	@Failure	404	{object}	dto.HTTPError
 //	@Failure	500	{object}	dto.HTTPError
-//	@Router		/mitt/{id} [delete]
-func (h *MittHandler) deleteMitt(c echo.Context) error {
+//	@Router		/mitt/{id}/restore [post]
+func (h *MittHandler) restoreMitt(c echo.Context) error {
 	ctx := c.Request().Context()
 
 	userID := c.Get("userID").(uuid.UUID)
@@ -269,7 +509,72 @@ func (h *MittHandler) deleteMitt(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
 	}
 
-	if httpErr := h.ms.DeleteMitt(ctx, userID, mittID); httpErr != nil {
+	if httpErr := h.ms.RestoreMitt(ctx, userID, mittID); httpErr != nil {
+		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// Pins
+
+// pinMitt godoc
+//
+//	@Summary		Pin Mitt
+//	@Description	Pins my mitt to my profile, number of pinned mitts is limited. Mitt is unpinned once it's deleted. Private accounts can't pin mitts
+//	@Tags			Mitts
+//	@Security		Bearer
+//	@Param			Authorization	header	string	true	"access token 'Bearer {token}'"
+//	@Param			id				path	string	true	"ID of mitt"
+//	@Success		204
+//	@Failure		400	{object}	dto.HTTPError
+//	@Failure		401	{object}	dto.HTTPError
+//	@Failure		403	{object}	dto.HTTPError
+//	@Failure		404	{object}	dto.HTTPError
+//	@Failure		409	{object}	dto.HTTPError
+//	@Failure		500	{object}	dto.HTTPError
+//	@Router			/mitt/{id}/pin [post]
+func (h *MittHandler) pinMitt(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID := c.Get("userID").(uuid.UUID)
+
+	mittIDStr := c.Param("id")
+	mittID, err := uuid.Parse(mittIDStr)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+	}
+
+	if httpErr := h.ms.PinMitt(ctx, userID, mittID); httpErr != nil {
+		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// unpinMitt godoc
+//
+//	@Summary	Unpin Mitt
+//	@Tags		Mitts
+//	@Security	Bearer
+//	@Param		Authorization	header	string	true	"access token 'Bearer {token}'"
+//	@Param		id				path	string	true	"ID of mitt"
+//	@Success	204
+//	@Failure	400	{object}	dto.HTTPError
+//	@Failure	401	{object}	dto.HTTPError
+//	@Failure	404	{object}	dto.HTTPError
+//	@Failure	500	{object}	dto.HTTPError
+//	@Router		/mitt/{id}/pin [delete]
+func (h *MittHandler) unpinMitt(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID := c.Get("userID").(uuid.UUID)
+
+	mittIDStr := c.Param("id")
+	mittID, err := uuid.Parse(mittIDStr)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+	}
+
+	if httpErr := h.ms.UnpinMitt(ctx, userID, mittID); httpErr != nil {
 		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
 	}
 	return c.NoContent(http.StatusNoContent)
@@ -279,7 +584,8 @@ func (h *MittHandler) deleteMitt(c echo.Context) error {
 
 // likeMitt godoc
 //
-//	@Summary	Like Mitt
+//	@Summary		Switch Mitt Like
+//	@Description	Likes mitt if it's not liked yet and removes like otherwise
 //	@Tags		Mitts
 //	@Security	Bearer
 //	@Param		Authorization	header	string	true	"access token 'Bearer {token}'"
@@ -288,6 +594,7 @@ func (h *MittHandler) deleteMitt(c echo.Context) error {
 //	@Success	200	{object}	dto.MittLikeResponse
 //	@Failure	400	{object}	dto.HTTPError
 //	@Failure	401	{object}	dto.HTTPError
+//	@Failure	404	{object}	dto.HTTPError
 //	@Failure	500	{object}	dto.HTTPError
 //	@Router		/mitt/{id}/like [post]
 func (h *MittHandler) likeMitt(c echo.Context) error {
@@ -312,15 +619,83 @@ func (h *MittHandler) likeMitt(c echo.Context) error {
 	return c.JSON(http.StatusOK, resp)
 }
 
+// putLike godoc
+//
+//	@Summary		Like Mitt
+//	@Description	Idempotent, liking already liked mitt changes nothing
+//	@Tags			Mitts
+//	@Security		Bearer
+//	@Param			Authorization	header	string	true	"access token 'Bearer {token}'"
+//	@Param			id				path	string	true	"ID of mitt"
+//	@Produce		json
+//	@Success		200	{object}	dto.MittLikeResponse
+//	@Failure		400	{object}	dto.HTTPError
+//	@Failure		401	{object}	dto.HTTPError
+//	@Failure		404	{object}	dto.HTTPError
+//	@Failure		500	{object}	dto.HTTPError
+//	@Router			/mitt/{id}/like [put]
+func (h *MittHandler) putLike(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID := c.Get("userID").(uuid.UUID)
+
+	mittIDStr := c.Param("id")
+	mittID, err := uuid.Parse(mittIDStr)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+	}
+
+	if httpErr := h.ms.LikeMitt(ctx, userID, mittID); httpErr != nil {
+		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
+	}
+
+	return c.JSON(http.StatusOK, dto.MittLikeResponse{Like: true})
+}
+
+// deleteLike godoc
+//
+//	@Summary		Unlike Mitt
+//	@Description	Idempotent, removing absent like changes nothing
+//	@Tags			Mitts
+//	@Security		Bearer
+//	@Param			Authorization	header	string	true	"access token 'Bearer {token}'"
+//	@Param			id				path	string	true	"ID of mitt"
+//	@Produce		json
+//	@Success		200	{object}	dto.MittLikeResponse
+//	@Failure		400	{object}	dto.HTTPError
+//	@Failure		401	{object}	dto.HTTPError
+//	@Failure		404	{object}	dto.HTTPError
+//	@Failure		500	{object}	dto.HTTPError
+//	@Router			/mitt/{id}/like [delete]
+func (h *MittHandler) deleteLike(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID := c.Get("userID").(uuid.UUID)
+
+	mittIDStr := c.Param("id")
+	mittID, err := uuid.Parse(mittIDStr)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+	}
+
+	if httpErr := h.ms.UnlikeMitt(ctx, userID, mittID); httpErr != nil {
+		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
+	}
+
+	return c.JSON(http.StatusOK, dto.MittLikeResponse{Like: false})
+}
+
 // feed godoc
 //
 //	@Summary	Get Feed Mitts
 //	@Tags		Mitts
-//	@Param		offset	query	int	false	"Offset"
-//	@Param		limit	query	int	false	"Limit"
+//	@Param		Authorization	header	string	false	"access token 'Bearer {token}', marks my bookmarks and poll votes"
+//	@Param		offset			query	int		false	"Offset"
+//	@Param		limit			query	int		false	"Limit"
 //	@Produce	json
 //	@Success	200	{object}	[]dto.MittResponse
 //	@Failure	400	{object}	dto.HTTPError
+//	@Failure	401	{object}	dto.HTTPError
 //	@Failure	500	{object}	dto.HTTPError
 //	@Router		/mitt/feed [get]
 func (h *MittHandler) feed(c echo.Context) error {
@@ -335,18 +710,45 @@ func (h *MittHandler) feed(c echo.Context) error {
 	if httpErr != nil {
 		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
 	}
+	if httpErr := markMitts(c, h.mm, mitts...); httpErr != nil {
+		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
+	}
 
-	resp := make([]dto.MittResponse, len(mitts))
-	for i, m := range mitts {
-		resp[i] = dto.MittResponse{
-			ID:         m.ID,
-			Author:     m.AuthorID,
-			AuthorName: m.AuthorName,
-			Content:    m.Content,
-			CreatedAt:  m.CreatedAt,
-			UpdatedAt:  m.UpdatedAt,
-			Likes:      m.Likes,
-		}
+	return c.JSON(http.StatusOK, mittsToResponse(mitts))
+}
+
+// getMentions godoc
+//
+//	@Summary		Get Mentions
+//	@Description	Mitts mentioning me, newest first. Mitts by users I blocked or muted are hidden
+//	@Tags			Mitts
+//	@Security		Bearer
+//	@Param			Authorization	header	string	true	"access token 'Bearer {token}'"
+//	@Param			offset			query	int		false	"Offset"
+//	@Param			limit			query	int		false	"Limit"
+//	@Produce		json
+//	@Success		200	{object}	[]dto.MittResponse
+//	@Failure		400	{object}	dto.HTTPError
+//	@Failure		401	{object}	dto.HTTPError
+//	@Failure		500	{object}	dto.HTTPError
+//	@Router			/mitt/mentions [get]
+func (h *MittHandler) getMentions(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID := c.Get("userID").(uuid.UUID)
+
+	limit, offset, err := pagination.GetLimitAndOffset(c, 30)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
 	}
-	return c.JSON(http.StatusOK, resp)
+
+	mitts, httpErr := h.ms.GetMentions(ctx, userID, limit, offset)
+	if httpErr != nil {
+		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
+	}
+	if httpErr := markMitts(c, h.mm, mitts...); httpErr != nil {
+		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
+	}
+
+	return c.JSON(http.StatusOK, mittsToResponse(mitts))
 }
@@ -25,6 +25,7 @@ var mockMittModel = &models.Mitt{
 	CreatedAt: time.Now(),
 	UpdatedAt: time.Now(),
 	Likes:     0,
+	Version:   1,
 }
 
 var mockUserID = uuid.MustParse("b096376a-5fa9-4130-907a-709c67008a65")
@@ -35,8 +36,12 @@ type mockMittService struct{}
 func (s *mockMittService) CreateMitt(ctx context.Context, userID uuid.UUID, mitt *models.MittCreate) (*models.Mitt, *models.HTTPError) {
 	_ = ctx
 	_ = userID
-	_ = mitt
 
+	if mitt.ExpiresAt != nil {
+		expiring := *mockMittModel
+		expiring.ExpiresAt = mitt.ExpiresAt
+		return &expiring, nil
+	}
 	return mockMittModel, nil
 }
 
@@ -60,6 +65,10 @@ func (m *mockMittService) UpdateMitt(ctx context.Context, userID uuid.UUID, mitt
 	_ = ctx
 	_ = mittID
 
+	if mitt.ExpectedVersion != nil && *mitt.ExpectedVersion != mockMittModel.Version {
+		return nil, &models.HTTPError{Code: http.StatusPreconditionFailed, Message: "Mitt was modified, fetch it again"}
+	}
+
 	// Update mock mitt content
 	mockMittModel.Content = mitt.Content
 
@@ -75,6 +84,89 @@ func (m *mockMittService) DeleteMitt(ctx context.Context, userID uuid.UUID, mitt
 	return nil
 }
 
+func (m *mockMittService) GetMittHistory(ctx context.Context, mittID uuid.UUID, limit, offset int32) ([]*models.MittRevision, *models.HTTPError) {
+	_ = ctx
+	_ = limit
+	_ = offset
+
+	return []*models.MittRevision{{
+		ID:        uuid.New(),
+		MittID:    mittID,
+		Content:   "first version",
+		CreatedAt: mockMittModel.CreatedAt,
+	}}, nil
+}
+
+func (m *mockMittService) PinMitt(ctx context.Context, userID uuid.UUID, mittID uuid.UUID) *models.HTTPError {
+	_ = ctx
+
+	if mittID != mockMittModel.ID {
+		return &models.HTTPError{Code: http.StatusNotFound, Message: "Mitt not found"}
+	}
+	if userID != mockMittModel.AuthorID {
+		return &models.HTTPError{Code: http.StatusForbidden, Message: "You are not allowed to do this"}
+	}
+	return nil
+}
+
+func (m *mockMittService) UnpinMitt(ctx context.Context, userID uuid.UUID, mittID uuid.UUID) *models.HTTPError {
+	_ = ctx
+	_ = userID
+
+	if mittID != mockMittModel.ID {
+		return &models.HTTPError{Code: http.StatusNotFound, Message: "Mitt is not pinned"}
+	}
+	return nil
+}
+
+func (m *mockMittService) RestoreMitt(ctx context.Context, userID uuid.UUID, mittID uuid.UUID) *models.HTTPError {
+	_ = ctx
+	_ = userID
+
+	if mittID != mockMittModel.ID {
+		return &models.HTTPError{Code: http.StatusNotFound, Message: "Mitt not found in trash"}
+	}
+	return nil
+}
+
+func (m *mockMittService) GetTrash(ctx context.Context, userID uuid.UUID, limit, offset int32) ([]*models.Mitt, *models.HTTPError) {
+	_ = ctx
+	_ = limit
+	_ = offset
+
+	deletedAt := time.Now()
+	return []*models.Mitt{{
+		ID:        uuid.New(),
+		AuthorID:  userID,
+		Content:   "deleted mitt",
+		DeletedAt: &deletedAt,
+	}}, nil
+}
+
+func (m *mockMittService) LikeMitt(ctx context.Context, userID uuid.UUID, mittID uuid.UUID) *models.HTTPError {
+	_ = ctx
+	_ = userID
+
+	if mittID != mockMittModel.ID {
+		return &models.HTTPError{Code: http.StatusNotFound, Message: "Mitt not found"}
+	}
+
+	mockMittModel.Likes = 1
+	return nil
+}
+
+func (m *mockMittService) UnlikeMitt(ctx context.Context, userID uuid.UUID, mittID uuid.UUID) *models.HTTPError {
+	_ = ctx
+	_ = userID
+
+	if mittID != mockMittModel.ID {
+		return &models.HTTPError{Code: http.StatusNotFound, Message: "Mitt not found"}
+	}
+
+	mockMittModel.Likes = 0
+	return nil
+}
+
 func (m *mockMittService) SwitchLike(ctx context.Context, userID uuid.UUID, mittID uuid.UUID) (bool, *models.HTTPError) {
 	// If likes > 0, lets suppose user already liked mitt
 	isAlreadyLiked := mockMittModel.Likes > 0
@@ -97,10 +189,23 @@ func (m *mockMittService) Feed(ctx context.Context, limit, offset int32) ([]*mod
 	return []*models.Mitt{mockMittModel}, nil
 }
 
+func (m *mockMittService) GetMentions(ctx context.Context, userID uuid.UUID, limit, offset int32) ([]*models.Mitt, *models.HTTPError) {
+	_ = ctx
+	_ = limit
+	_ = offset
+
+	return []*models.Mitt{{
+		ID:       uuid.New(),
+		AuthorID: uuid.New(),
+		Content:  "hi @me",
+		Mentions: []*models.MittMention{{UserID: userID, Login: "me", Start: 3, End: 6}},
+	}}, nil
+}
+
 // Tests
 func TestMittHandler_CreateMitt(t *testing.T) {
 	e := echo.New()
-	handler := NewMittHandler(&mockMittService{}, mockRequireAuth)
+	handler := NewMittHandler(&mockMittService{}, newMockBookmarkService(), mockRequireAuth, mockRequireAuth, 280)
 
 	g := e.Group("/api/v1/mitt")
 	handler.Routes(g)
@@ -123,6 +228,7 @@ func TestMittHandler_CreateMitt(t *testing.T) {
 			CreatedAt: mockMittModel.CreatedAt,
 			UpdatedAt: mockMittModel.UpdatedAt,
 			Likes:     mockMittModel.Likes,
+			Mentions:  []dto.MentionResponse{},
 		}
 
 		b, err := json.Marshal(resp)
@@ -134,9 +240,65 @@ func TestMittHandler_CreateMitt(t *testing.T) {
 	}
 }
 
+func TestMittHandler_CreateMittExpiry(t *testing.T) {
+	e := echo.New()
+	handler := NewMittHandler(&mockMittService{}, newMockBookmarkService(), mockRequireAuth, mockRequireAuth, 280)
+
+	g := e.Group("/api/v1/mitt")
+	handler.Routes(g)
+
+	// Create request
+	reqBody := `{"content":"today only","expires_at":"2030-01-01T00:00:00Z"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/mitt", strings.NewReader(reqBody))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, rec)
+
+	if assert.NoError(t, mockRequireAuth(handler.createMitt)(ctx)) {
+		assert.Equal(t, http.StatusCreated, rec.Code)
+
+		var resp dto.MittResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		require.NotNil(t, resp.ExpiresAt)
+		assert.True(t, resp.ExpiresAt.Equal(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)))
+	}
+}
+
+func TestMittHandler_CreateMittValidation(t *testing.T) {
+	e := echo.New()
+	handler := NewMittHandler(&mockMittService{}, newMockBookmarkService(), mockRequireAuth, mockRequireAuth, 5)
+
+	g := e.Group("/api/v1/mitt")
+	handler.Routes(g)
+
+	for _, reqBody := range []string{
+		`{"content":"   \n "}`,
+		`{"content":"hello world"}`,
+		`{"content":"bell\u0007"}`,
+		`{}`,
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/mitt", strings.NewReader(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+
+		ctx := e.NewContext(req, rec)
+
+		if assert.NoError(t, mockRequireAuth(handler.createMitt)(ctx)) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+			var resp dto.ValidationError
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+			if assert.Len(t, resp.Fields, 1) {
+				assert.Equal(t, "content", resp.Fields[0].Field)
+			}
+		}
+	}
+}
+
 func TestMittHandler_GetMitt(t *testing.T) {
 	e := echo.New()
-	handler := NewMittHandler(&mockMittService{}, mockRequireAuth)
+	handler := NewMittHandler(&mockMittService{}, newMockBookmarkService(), mockRequireAuth, mockRequireAuth, 280)
 
 	g := e.Group("/api/v1/mitt")
 	handler.Routes(g)
@@ -162,6 +324,7 @@ func TestMittHandler_GetMitt(t *testing.T) {
 			CreatedAt: mockMittModel.CreatedAt,
 			UpdatedAt: mockMittModel.UpdatedAt,
 			Likes:     mockMittModel.Likes,
+			Mentions:  []dto.MentionResponse{},
 		}
 
 		b, err := json.Marshal(resp)
@@ -175,7 +338,7 @@ func TestMittHandler_GetMitt(t *testing.T) {
 
 func TestMittHandler_GetAllUserMitts(t *testing.T) {
 	e := echo.New()
-	handler := NewMittHandler(&mockMittService{}, mockRequireAuth)
+	handler := NewMittHandler(&mockMittService{}, newMockBookmarkService(), mockRequireAuth, mockRequireAuth, 280)
 
 	g := e.Group("/api/v1/mitt")
 	handler.Routes(g)
@@ -201,6 +364,7 @@ func TestMittHandler_GetAllUserMitts(t *testing.T) {
 			CreatedAt: mockMittModel.CreatedAt,
 			UpdatedAt: mockMittModel.UpdatedAt,
 			Likes:     mockMittModel.Likes,
+			Mentions:  []dto.MentionResponse{},
 		}
 
 		resp := []dto.MittResponse{respMitt}
@@ -216,13 +380,15 @@ func TestMittHandler_GetAllUserMitts(t *testing.T) {
 
 func TestMittHandler_UpdateMitt(t *testing.T) {
 	e := echo.New()
-	handler := NewMittHandler(&mockMittService{}, mockRequireAuth)
+	handler := NewMittHandler(&mockMittService{}, newMockBookmarkService(), mockRequireAuth, mockRequireAuth, 280)
 
 	g := e.Group("/api/v1/mitt")
 	handler.Routes(g)
 
 	// Create request
-	req := httptest.NewRequest(http.MethodPut, fmt.Sprintf("/api/v1/mitt/%s", mockMittModel.ID.String()), nil)
+	reqBody := fmt.Sprintf(`{"content":"%s"}`, mockMittModel.Content)
+	req := httptest.NewRequest(http.MethodPut, fmt.Sprintf("/api/v1/mitt/%s", mockMittModel.ID.String()), strings.NewReader(reqBody))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
 
 	ctx := e.NewContext(req, rec)
@@ -242,6 +408,7 @@ func TestMittHandler_UpdateMitt(t *testing.T) {
 			CreatedAt: mockMittModel.CreatedAt,
 			UpdatedAt: mockMittModel.UpdatedAt,
 			Likes:     mockMittModel.Likes,
+			Mentions:  []dto.MentionResponse{},
 		}
 
 		b, err := json.Marshal(resp)
@@ -255,7 +422,7 @@ func TestMittHandler_UpdateMitt(t *testing.T) {
 
 func TestMittHandler_DeleteMitt(t *testing.T) {
 	e := echo.New()
-	handler := NewMittHandler(&mockMittService{}, mockRequireAuth)
+	handler := NewMittHandler(&mockMittService{}, newMockBookmarkService(), mockRequireAuth, mockRequireAuth, 280)
 
 	g := e.Group("/api/v1/mitt")
 	handler.Routes(g)
@@ -278,7 +445,7 @@ func TestMittHandler_DeleteMitt(t *testing.T) {
 
 func TestMittHandler_LikeMitt(t *testing.T) {
 	e := echo.New()
-	handler := NewMittHandler(&mockMittService{}, mockRequireAuth)
+	handler := NewMittHandler(&mockMittService{}, newMockBookmarkService(), mockRequireAuth, mockRequireAuth, 280)
 
 	g := e.Group("/api/v1/mitt")
 	handler.Routes(g)
@@ -338,3 +505,248 @@ func TestMittHandler_LikeMitt(t *testing.T) {
 		require.JSONEq(t, expectedResp, rec.Body.String())
 	}
 }
+
+func TestMittHandler_PutDeleteLike(t *testing.T) {
+	e := echo.New()
+	handler := NewMittHandler(&mockMittService{}, newMockBookmarkService(), mockRequireAuth, mockRequireAuth, 280)
+
+	g := e.Group("/api/v1/mitt")
+	handler.Routes(g)
+
+	newCtx := func(method, mittID string) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(method, fmt.Sprintf("/api/v1/mitt/%s/like", mittID), nil)
+		rec := httptest.NewRecorder()
+
+		ctx := e.NewContext(req, rec)
+
+		// Set path param (id)
+		ctx.SetPath("/api/v1/mitt/:id/like")
+		ctx.SetParamNames("id")
+		ctx.SetParamValues(mittID)
+		return ctx, rec
+	}
+
+	// Liking twice gives the same result
+	for range 2 {
+		ctx, rec := newCtx(http.MethodPut, mockMittModel.ID.String())
+		if assert.NoError(t, mockRequireAuth(handler.putLike)(ctx)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.JSONEq(t, `{"like":true}`, rec.Body.String())
+		}
+	}
+
+	// Unliking too
+	for range 2 {
+		ctx, rec := newCtx(http.MethodDelete, mockMittModel.ID.String())
+		if assert.NoError(t, mockRequireAuth(handler.deleteLike)(ctx)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.JSONEq(t, `{"like":false}`, rec.Body.String())
+		}
+	}
+
+	// Nonexistent mitt
+	ctx, rec := newCtx(http.MethodPut, uuid.New().String())
+	if assert.NoError(t, mockRequireAuth(handler.putLike)(ctx)) {
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	}
+}
+
+func TestMittHandler_GetMittHistory(t *testing.T) {
+	e := echo.New()
+	handler := NewMittHandler(&mockMittService{}, newMockBookmarkService(), mockRequireAuth, mockRequireAuth, 280)
+
+	g := e.Group("/api/v1/mitt")
+	handler.Routes(g)
+
+	// Create request
+	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/mitt/%s/history", mockMittModel.ID.String()), nil)
+	rec := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, rec)
+
+	// Set path param (id)
+	ctx.SetPath("/api/v1/mitt/:id/history")
+	ctx.SetParamNames("id")
+	ctx.SetParamValues(mockMittModel.ID.String())
+
+	if assert.NoError(t, handler.getMittHistory(ctx)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "first version")
+	}
+}
+
+func TestMittHandler_ETag(t *testing.T) {
+	e := echo.New()
+	handler := NewMittHandler(&mockMittService{}, newMockBookmarkService(), mockRequireAuth, mockRequireAuth, 280)
+
+	g := e.Group("/api/v1/mitt")
+	handler.Routes(g)
+
+	newCtx := func(method, body string, header, value string) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(method, fmt.Sprintf("/api/v1/mitt/%s", mockMittModel.ID.String()), strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+		rec := httptest.NewRecorder()
+
+		ctx := e.NewContext(req, rec)
+
+		// Set path param (id)
+		ctx.SetPath("/api/v1/mitt/:id")
+		ctx.SetParamNames("id")
+		ctx.SetParamValues(mockMittModel.ID.String())
+		return ctx, rec
+	}
+
+	etag := countersETag(mockMittModel.Version, mockMittModel.Likes)
+
+	// ETag is returned
+	ctx, rec := newCtx(http.MethodGet, "", "", "")
+	if assert.NoError(t, handler.getMitt(ctx)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, etag, rec.Header().Get("ETag"))
+		// Response depends on the signed in user
+		assert.Equal(t, "private", rec.Header().Get(echo.HeaderCacheControl))
+		assert.Equal(t, []string{echo.HeaderAuthorization, echo.HeaderCookie}, rec.Header().Values(echo.HeaderVary))
+	}
+
+	// Not modified
+	ctx, rec = newCtx(http.MethodGet, "", "If-None-Match", etag)
+	if assert.NoError(t, handler.getMitt(ctx)) {
+		assert.Equal(t, http.StatusNotModified, rec.Code)
+		assert.Empty(t, rec.Body.String())
+	}
+
+	// Likes changed since, but version is the same
+	ctx, rec = newCtx(http.MethodGet, "", "If-None-Match", countersETag(mockMittModel.Version, mockMittModel.Likes+1))
+	if assert.NoError(t, handler.getMitt(ctx)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+	}
+
+	// Stale If-Match
+	ctx, rec = newCtx(http.MethodPut, `{"content":"hello world"}`, "If-Match", versionETag(mockMittModel.Version+1))
+	if assert.NoError(t, mockRequireAuth(handler.updateMitt)(ctx)) {
+		assert.Equal(t, http.StatusPreconditionFailed, rec.Code)
+	}
+
+	// Matching If-Match
+	ctx, rec = newCtx(http.MethodPut, `{"content":"hello world"}`, "If-Match", etag)
+	if assert.NoError(t, mockRequireAuth(handler.updateMitt)(ctx)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+	}
+}
+
+func TestMittETag_Poll(t *testing.T) {
+	mitt := &models.Mitt{
+		Version: 1,
+		Poll: &models.Poll{
+			Options:   []*models.PollOption{{Text: "a"}, {Text: "b"}},
+			ExpiresAt: time.Now().Add(time.Hour),
+		},
+	}
+	etag := mittETag(mitt)
+	assert.Equal(t, `"1-0-0-0"`, etag)
+
+	// Someone else voted
+	mitt.Poll.Options[1].Votes++
+	mitt.Poll.VotersCount++
+	voted := mittETag(mitt)
+	assert.Equal(t, `"1-0-1-1"`, voted)
+
+	// Poll is closed
+	mitt.Poll.ExpiresAt = time.Now().Add(-time.Minute)
+	assert.Equal(t, `"1-0-1-1-rc"`, mittETag(mitt))
+}
+
+func TestMittHandler_Trash(t *testing.T) {
+	e := echo.New()
+	handler := NewMittHandler(&mockMittService{}, newMockBookmarkService(), mockRequireAuth, mockRequireAuth, 280)
+
+	g := e.Group("/api/v1/mitt")
+	handler.Routes(g)
+
+	// Trash listing
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/mitt/trash", nil)
+	rec := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, rec)
+
+	if assert.NoError(t, mockRequireAuth(handler.getTrash)(ctx)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "deleted_at")
+	}
+
+	// Restore
+	req = httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/mitt/%s/restore", mockMittModel.ID.String()), nil)
+	rec = httptest.NewRecorder()
+
+	ctx = e.NewContext(req, rec)
+
+	// Set path param (id)
+	ctx.SetPath("/api/v1/mitt/:id/restore")
+	ctx.SetParamNames("id")
+	ctx.SetParamValues(mockMittModel.ID.String())
+
+	if assert.NoError(t, mockRequireAuth(handler.restoreMitt)(ctx)) {
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+	}
+}
+
+func TestMittHandler_Pin(t *testing.T) {
+	e := echo.New()
+	handler := NewMittHandler(&mockMittService{}, newMockBookmarkService(), mockRequireAuth, mockRequireAuth, 280)
+
+	g := e.Group("/api/v1/mitt")
+	handler.Routes(g)
+
+	for _, tc := range []struct {
+		method string
+		id     string
+		code   int
+	}{
+		{http.MethodPost, mockMittModel.ID.String(), http.StatusNoContent},
+		{http.MethodPost, uuid.NewString(), http.StatusNotFound},
+		{http.MethodPost, "not-uuid", http.StatusBadRequest},
+		{http.MethodDelete, mockMittModel.ID.String(), http.StatusNoContent},
+		{http.MethodDelete, uuid.NewString(), http.StatusNotFound},
+	} {
+		// Create request
+		req := httptest.NewRequest(tc.method, fmt.Sprintf("/api/v1/mitt/%s/pin", tc.id), nil)
+		rec := httptest.NewRecorder()
+
+		ctx := e.NewContext(req, rec)
+
+		// Set path param (id)
+		ctx.SetPath("/api/v1/mitt/:id/pin")
+		ctx.SetParamNames("id")
+		ctx.SetParamValues(tc.id)
+
+		h := handler.pinMitt
+		if tc.method == http.MethodDelete {
+			h = handler.unpinMitt
+		}
+		if assert.NoError(t, mockRequireAuth(h)(ctx)) {
+			assert.Equal(t, tc.code, rec.Code, tc.method+" "+tc.id)
+		}
+	}
+}
+
+func TestMittHandler_GetMentions(t *testing.T) {
+	e := echo.New()
+	handler := NewMittHandler(&mockMittService{}, newMockBookmarkService(), mockRequireAuth, mockRequireAuth, 280)
+
+	g := e.Group("/api/v1/mitt")
+	handler.Routes(g)
+
+	// Create request
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/mitt/mentions", nil)
+	rec := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, rec)
+
+	if assert.NoError(t, mockRequireAuth(handler.getMentions)(ctx)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), fmt.Sprintf(`"mentions":[{"user_id":"%s","login":"me","start":3,"end":6}]`, mockUserID))
+	}
+}
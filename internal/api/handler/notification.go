@@ -0,0 +1,245 @@
+package handler
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/misshanya/mitter/internal/api/dto"
+	"github.com/misshanya/mitter/internal/models"
+	"github.com/misshanya/mitter/pkg/pagination"
+	"net/http"
+)
+
+type notificationService interface {
+	GetNotifications(ctx context.Context, userID uuid.UUID, limit, offset int32) ([]*models.NotificationGroup, *models.HTTPError)
+	GetUnreadCount(ctx context.Context, userID uuid.UUID) (int64, *models.HTTPError)
+	MarkRead(ctx context.Context, userID uuid.UUID, id uuid.UUID) *models.HTTPError
+	MarkAllRead(ctx context.Context, userID uuid.UUID) *models.HTTPError
+	GetPreferences(ctx context.Context, userID uuid.UUID) (models.NotificationPreferences, *models.HTTPError)
+	UpdatePreferences(ctx context.Context, userID uuid.UUID, prefs models.NotificationPreferences) (models.NotificationPreferences, *models.HTTPError)
+}
+
+type NotificationHandler struct {
+	ns                notificationService
+	reqAuthMiddleware echo.MiddlewareFunc
+}
+
+func NewNotificationHandler(ns notificationService, reqAuthMdl echo.MiddlewareFunc) *NotificationHandler {
+	return &NotificationHandler{
+		ns:                ns,
+		reqAuthMiddleware: reqAuthMdl,
+	}
+}
+
+func (h *NotificationHandler) Routes(group *echo.Group) {
+	group.GET("", h.getNotifications, h.reqAuthMiddleware)
+	group.GET("/unread-count", h.getUnreadCount, h.reqAuthMiddleware)
+	group.POST("/:id/read", h.markRead, h.reqAuthMiddleware)
+	group.POST("/read-all", h.markAllRead, h.reqAuthMiddleware)
+	group.GET("/preferences", h.getPreferences, h.reqAuthMiddleware)
+	group.PATCH("/preferences", h.updatePreferences, h.reqAuthMiddleware)
+}
+
+func preferencesToResponse(prefs models.NotificationPreferences) dto.NotificationPreferencesResponse {
+	return dto.NotificationPreferencesResponse{
+		Likes:       prefs[models.NotificationLike],
+		Follows:     prefs[models.NotificationFollow],
+		Mentions:    prefs[models.NotificationMention],
+		ClosedPolls: prefs[models.NotificationPollClosed],
+	}
+}
+
+// getNotifications godoc
+//
+//	@Summary		Get notifications
+//	@Description	Get my notifications grouped by type, mitt and day ("X and 5 others liked your mitt"), latest first
+//	@Tags			Notifications
+//	@Security		Bearer
+//	@Param			Authorization	header	string	true	"access token 'Bearer {token}'"
+//	@Param			offset			query	int		false	"Offset"
+//	@Param			limit			query	int		false	"Limit"
+//	@Produce		json
+//	@Success		200	{object}	[]dto.NotificationResponse
+//	@Failure		400	{object}	dto.HTTPError
+//	@Failure		401	{object}	dto.HTTPError
+//	@Failure		500	{object}	dto.HTTPError
+//	@Router			/notification [get]
+func (h *NotificationHandler) getNotifications(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID := c.Get("userID").(uuid.UUID)
+
+	limit, offset, err := pagination.GetLimitAndOffset(c, 30)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+	}
+
+	groups, httpErr := h.ns.GetNotifications(ctx, userID, limit, offset)
+	if httpErr != nil {
+		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
+	}
+
+	resp := make([]dto.NotificationResponse, len(groups))
+	for i, g := range groups {
+		resp[i] = dto.NotificationResponse{
+			ID:          g.ID,
+			Type:        string(g.Type),
+			MittID:      g.MittID,
+			Actors:      usersToResponse(g.Actors),
+			ActorsCount: g.ActorsCount,
+			Unread:      g.Unread,
+			LatestAt:    g.LatestAt,
+		}
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// getUnreadCount godoc
+//
+//	@Summary	Get unread notifications count
+//	@Tags		Notifications
+//	@Security	Bearer
+//	@Param		Authorization	header	string	true	"access token 'Bearer {token}'"
+//	@Produce	json
+//	@Success	200	{object}	dto.UnreadCountResponse
+//	@Failure	401	{object}	dto.HTTPError
+//	@Failure	500	{object}	dto.HTTPError
+//	@Router		/notification/unread-count [get]
+func (h *NotificationHandler) getUnreadCount(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID := c.Get("userID").(uuid.UUID)
+
+	count, httpErr := h.ns.GetUnreadCount(ctx, userID)
+	if httpErr != nil {
+		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
+	}
+
+	return c.JSON(http.StatusOK, dto.UnreadCountResponse{Count: count})
+}
+
+// markRead godoc
+//
+//	@Summary		Mark notification read
+//	@Description	Mark notification read with the rest of its group
+//	@Tags			Notifications
+//	@Security		Bearer
+//	@Param			Authorization	header	string	true	"access token 'Bearer {token}'"
+//	@Param			id				path	string	true	"ID of notification"
+//	@Success		204
+//	@Failure		400	{object}	dto.HTTPError
+//	@Failure		401	{object}	dto.HTTPError
+//	@Failure		404	{object}	dto.HTTPError
+//	@Failure		500	{object}	dto.HTTPError
+//	@Router			/notification/{id}/read [post]
+func (h *NotificationHandler) markRead(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID := c.Get("userID").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+	}
+
+	if httpErr := h.ns.MarkRead(ctx, userID, id); httpErr != nil {
+		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// markAllRead godoc
+//
+//	@Summary	Mark all notifications read
+//	@Tags		Notifications
+//	@Security	Bearer
+//	@Param		Authorization	header	string	true	"access token 'Bearer {token}'"
+//	@Success	204
+//	@Failure	401	{object}	dto.HTTPError
+//	@Failure	500	{object}	dto.HTTPError
+//	@Router		/notification/read-all [post]
+func (h *NotificationHandler) markAllRead(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID := c.Get("userID").(uuid.UUID)
+
+	if httpErr := h.ns.MarkAllRead(ctx, userID); httpErr != nil {
+		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// getPreferences godoc
+//
+//	@Summary		Get notification preferences
+//	@Description	Which types of notifications are enabled
+//	@Tags			Notifications
+//	@Security		Bearer
+//	@Param			Authorization	header	string	true	"access token 'Bearer {token}'"
+//	@Produce		json
+//	@Success		200	{object}	dto.NotificationPreferencesResponse
+//	@Failure		401	{object}	dto.HTTPError
+//	@Failure		500	{object}	dto.HTTPError
+//	@Router			/notification/preferences [get]
+func (h *NotificationHandler) getPreferences(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID := c.Get("userID").(uuid.UUID)
+
+	prefs, httpErr := h.ns.GetPreferences(ctx, userID)
+	if httpErr != nil {
+		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
+	}
+
+	return c.JSON(http.StatusOK, preferencesToResponse(prefs))
+}
+
+// updatePreferences godoc
+//
+//	@Summary		Update notification preferences
+//	@Description	Enable or disable types of notifications, omitted types are left as is
+//	@Tags			Notifications
+//	@Security		Bearer
+//	@Param			Authorization	header	string								true	"access token 'Bearer {token}'"
+//	@Param			preferences		body	dto.NotificationPreferencesRequest	true	"Preferences"
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	dto.NotificationPreferencesResponse
+//	@Failure		400	{object}	dto.HTTPError
+//	@Failure		401	{object}	dto.HTTPError
+//	@Failure		500	{object}	dto.HTTPError
+//	@Router			/notification/preferences [patch]
+func (h *NotificationHandler) updatePreferences(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID := c.Get("userID").(uuid.UUID)
+
+	var req dto.NotificationPreferencesRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+	}
+
+	prefs := models.NotificationPreferences{}
+	if req.Likes != nil {
+		prefs[models.NotificationLike] = *req.Likes
+	}
+	if req.Follows != nil {
+		prefs[models.NotificationFollow] = *req.Follows
+	}
+	if req.Mentions != nil {
+		prefs[models.NotificationMention] = *req.Mentions
+	}
+	if req.ClosedPolls != nil {
+		prefs[models.NotificationPollClosed] = *req.ClosedPolls
+	}
+
+	updated, httpErr := h.ns.UpdatePreferences(ctx, userID, prefs)
+	if httpErr != nil {
+		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
+	}
+
+	return c.JSON(http.StatusOK, preferencesToResponse(updated))
+}
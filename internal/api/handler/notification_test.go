@@ -0,0 +1,175 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/misshanya/mitter/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+var mockNotificationGroup = &models.NotificationGroup{
+	ID:          uuid.MustParse("6a1f2b3c-4d5e-4f60-8a7b-9c0d1e2f3a4b"),
+	Type:        models.NotificationLike,
+	MittID:      &mockMittModel.ID,
+	ActorIDs:    []uuid.UUID{mockUserID},
+	Actors:      []*models.User{{ID: mockUserID, Login: "actor", Name: "Actor"}},
+	ActorsCount: 6,
+	Unread:      true,
+	LatestAt:    time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+}
+
+// Mock service
+type mockNotificationService struct {
+	prefs models.NotificationPreferences
+}
+
+func (s *mockNotificationService) GetNotifications(ctx context.Context, userID uuid.UUID, limit, offset int32) ([]*models.NotificationGroup, *models.HTTPError) {
+	_ = ctx
+	_ = userID
+	_ = limit
+	_ = offset
+
+	return []*models.NotificationGroup{mockNotificationGroup}, nil
+}
+
+func (s *mockNotificationService) GetUnreadCount(ctx context.Context, userID uuid.UUID) (int64, *models.HTTPError) {
+	_ = ctx
+	_ = userID
+
+	return 1, nil
+}
+
+func (s *mockNotificationService) MarkRead(ctx context.Context, userID uuid.UUID, id uuid.UUID) *models.HTTPError {
+	_ = ctx
+	_ = userID
+
+	if id != mockNotificationGroup.ID {
+		return &models.HTTPError{Code: http.StatusNotFound, Message: "Notification not found"}
+	}
+	return nil
+}
+
+func (s *mockNotificationService) MarkAllRead(ctx context.Context, userID uuid.UUID) *models.HTTPError {
+	_ = ctx
+	_ = userID
+
+	return nil
+}
+
+func (s *mockNotificationService) GetPreferences(ctx context.Context, userID uuid.UUID) (models.NotificationPreferences, *models.HTTPError) {
+	_ = ctx
+	_ = userID
+
+	prefs := models.NotificationPreferences{}
+	for _, t := range models.NotificationTypes {
+		enabled, ok := s.prefs[t]
+		prefs[t] = !ok || enabled
+	}
+	return prefs, nil
+}
+
+func (s *mockNotificationService) UpdatePreferences(ctx context.Context, userID uuid.UUID, prefs models.NotificationPreferences) (models.NotificationPreferences, *models.HTTPError) {
+	s.prefs = prefs
+	return s.GetPreferences(ctx, userID)
+}
+
+// Tests
+func TestNotificationHandler_GetNotifications(t *testing.T) {
+	e := echo.New()
+	handler := NewNotificationHandler(&mockNotificationService{}, mockRequireAuth)
+
+	g := e.Group("/api/v1/notification")
+	handler.Routes(g)
+
+	// Create request
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/notification", nil)
+	rec := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, rec)
+
+	if assert.NoError(t, mockRequireAuth(handler.getNotifications)(ctx)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"type":"like"`)
+		assert.Contains(t, rec.Body.String(), `"actors_count":6`)
+		assert.Contains(t, rec.Body.String(), `"login":"actor"`)
+	}
+}
+
+func TestNotificationHandler_MarkRead(t *testing.T) {
+	e := echo.New()
+	handler := NewNotificationHandler(&mockNotificationService{}, mockRequireAuth)
+
+	g := e.Group("/api/v1/notification")
+	handler.Routes(g)
+
+	for _, tc := range []struct {
+		id   string
+		code int
+	}{
+		{mockNotificationGroup.ID.String(), http.StatusNoContent},
+		{uuid.NewString(), http.StatusNotFound},
+		{"not-uuid", http.StatusBadRequest},
+	} {
+		// Create request
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/notification/"+tc.id+"/read", nil)
+		rec := httptest.NewRecorder()
+
+		ctx := e.NewContext(req, rec)
+
+		// Set path param (id)
+		ctx.SetPath("/api/v1/notification/:id/read")
+		ctx.SetParamNames("id")
+		ctx.SetParamValues(tc.id)
+
+		if assert.NoError(t, mockRequireAuth(handler.markRead)(ctx)) {
+			assert.Equal(t, tc.code, rec.Code)
+		}
+	}
+}
+
+func TestNotificationHandler_GetUnreadCount(t *testing.T) {
+	e := echo.New()
+	handler := NewNotificationHandler(&mockNotificationService{}, mockRequireAuth)
+
+	g := e.Group("/api/v1/notification")
+	handler.Routes(g)
+
+	// Create request
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/notification/unread-count", nil)
+	rec := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, rec)
+
+	if assert.NoError(t, mockRequireAuth(handler.getUnreadCount)(ctx)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"count":1}`, rec.Body.String())
+	}
+}
+
+func TestNotificationHandler_UpdatePreferences(t *testing.T) {
+	e := echo.New()
+	handler := NewNotificationHandler(&mockNotificationService{}, mockRequireAuth)
+
+	g := e.Group("/api/v1/notification")
+	handler.Routes(g)
+
+	// Create request
+	reqBody := `{"likes":false}`
+	req := httptest.NewRequest(http.MethodPatch, "/api/v1/notification/preferences", strings.NewReader(reqBody))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, rec)
+
+	if assert.NoError(t, mockRequireAuth(handler.updatePreferences)(ctx)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"likes":false,"follows":true,"mentions":true,"closed_polls":true}`, rec.Body.String())
+	}
+}
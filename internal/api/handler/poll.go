@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"context"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/misshanya/mitter/internal/api/dto"
+	"github.com/misshanya/mitter/internal/models"
+	"net/http"
+)
+
+type pollService interface {
+	Vote(ctx context.Context, userID uuid.UUID, mittID uuid.UUID, choices []int) (*models.Mitt, *models.HTTPError)
+}
+
+type PollHandler struct {
+	ps                pollService
+	mm                mittMarker
+	validate          *validator.Validate
+	reqAuthMiddleware echo.MiddlewareFunc
+}
+
+func NewPollHandler(ps pollService, mm mittMarker, reqAuthMdl echo.MiddlewareFunc) *PollHandler {
+	return &PollHandler{
+		ps:                ps,
+		mm:                mm,
+		validate:          newValidator(),
+		reqAuthMiddleware: reqAuthMdl,
+	}
+}
+
+func (h *PollHandler) Routes(group *echo.Group) {
+	group.POST("/:id/vote", h.vote, h.reqAuthMiddleware)
+}
+
+// vote godoc
+//
+//	@Summary		Vote in poll
+//	@Description	Votes in poll of mitt, vote can't be changed. Results become visible after voting
+//	@Tags			Mitts
+//	@Security		Bearer
+//	@Param			Authorization	header	string				true	"access token 'Bearer {token}'"
+//	@Param			id				path	string				true	"ID of mitt"
+//	@Param			vote			body	dto.PollVoteRequest	true	"Choices"
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	dto.MittResponse
+//	@Failure		400	{object}	dto.ValidationError
+//	@Failure		401	{object}	dto.HTTPError
+//	@Failure		404	{object}	dto.HTTPError
+//	@Failure		409	{object}	dto.HTTPError
+//	@Failure		500	{object}	dto.HTTPError
+//	@Router			/mitt/{id}/vote [post]
+func (h *PollHandler) vote(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID := c.Get("userID").(uuid.UUID)
+
+	mittID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+	}
+
+	var req dto.PollVoteRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return validationFailed(c, fieldErrors(err))
+	}
+
+	mitt, httpErr := h.ps.Vote(ctx, userID, mittID, req.Choices)
+	if httpErr != nil {
+		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
+	}
+	if httpErr := markMitts(c, h.mm, mitt); httpErr != nil {
+		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
+	}
+
+	setETag(c, mittETag(mitt))
+	return c.JSON(http.StatusOK, mittToResponse(mitt))
+}
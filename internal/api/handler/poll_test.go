@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/misshanya/mitter/internal/api/dto"
+	"github.com/misshanya/mitter/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Mock service
+type mockPollService struct{}
+
+func (s *mockPollService) Vote(ctx context.Context, userID uuid.UUID, mittID uuid.UUID, choices []int) (*models.Mitt, *models.HTTPError) {
+	_ = ctx
+	_ = userID
+
+	if mittID != mockMittModel.ID {
+		return nil, &models.HTTPError{Code: http.StatusNotFound, Message: "Poll not found"}
+	}
+	if len(choices) != 1 || choices[0] < 0 || choices[0] > 1 {
+		return nil, &models.HTTPError{Code: http.StatusBadRequest, Message: "Invalid poll choices"}
+	}
+
+	poll := &models.Poll{
+		Options:     []*models.PollOption{{Text: "yes"}, {Text: "no"}},
+		ExpiresAt:   time.Now().Add(time.Hour),
+		VotersCount: 1,
+		Voted:       true,
+		Choices:     choices,
+	}
+	poll.Options[choices[0]].Votes = 1
+	return &models.Mitt{ID: mittID, Version: 1, Poll: poll}, nil
+}
+
+// Tests
+func TestPollHandler_Vote(t *testing.T) {
+	e := echo.New()
+	handler := NewPollHandler(&mockPollService{}, newMockBookmarkService(), mockRequireAuth)
+
+	g := e.Group("/api/v1/mitt")
+	handler.Routes(g)
+
+	for _, tc := range []struct {
+		id   string
+		body string
+		code int
+	}{
+		{mockMittModel.ID.String(), `{"choices":[1]}`, http.StatusOK},
+		{mockMittModel.ID.String(), `{"choices":[2]}`, http.StatusBadRequest},
+		{mockMittModel.ID.String(), `{}`, http.StatusBadRequest},
+		{uuid.NewString(), `{"choices":[0]}`, http.StatusNotFound},
+		{"not-uuid", `{"choices":[0]}`, http.StatusBadRequest},
+	} {
+		// Create request
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/mitt/"+tc.id+"/vote", strings.NewReader(tc.body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+
+		ctx := e.NewContext(req, rec)
+
+		// Set path param (id)
+		ctx.SetPath("/api/v1/mitt/:id/vote")
+		ctx.SetParamNames("id")
+		ctx.SetParamValues(tc.id)
+
+		if assert.NoError(t, mockRequireAuth(handler.vote)(ctx)) {
+			assert.Equal(t, tc.code, rec.Code, tc.body)
+		}
+		if tc.code != http.StatusOK {
+			continue
+		}
+
+		var resp dto.MittResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		if assert.NotNil(t, resp.Poll) {
+			assert.True(t, resp.Poll.Voted)
+			assert.True(t, resp.Poll.ResultsVisible)
+			assert.Equal(t, []int{1}, resp.Poll.Choices)
+			if assert.NotNil(t, resp.Poll.Options[1].Votes) {
+				assert.Equal(t, int64(1), *resp.Poll.Options[1].Votes)
+			}
+		}
+		assert.Equal(t, `"1-0-1-1-r"`, rec.Header().Get(headerETag))
+	}
+}
+
+func TestPollToResponse_HiddenResults(t *testing.T) {
+	poll := &models.Poll{
+		Options:     []*models.PollOption{{Text: "yes", Votes: 3}, {Text: "no", Votes: 1}},
+		ExpiresAt:   time.Now().Add(time.Hour),
+		VotersCount: 4,
+	}
+
+	resp := pollToResponse(poll)
+	assert.False(t, resp.ResultsVisible)
+	assert.False(t, resp.Expired)
+	assert.Equal(t, int64(4), resp.VotersCount)
+	for _, o := range resp.Options {
+		assert.Nil(t, o.Votes)
+	}
+
+	// Results of expired poll are visible to everyone
+	poll.ExpiresAt = time.Now().Add(-time.Minute)
+	resp = pollToResponse(poll)
+	assert.True(t, resp.ResultsVisible)
+	assert.True(t, resp.Expired)
+	if assert.NotNil(t, resp.Options[0].Votes) {
+		assert.Equal(t, int64(3), *resp.Options[0].Votes)
+	}
+}
@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/misshanya/mitter/internal/api/dto"
+	"github.com/misshanya/mitter/internal/models"
+	"github.com/misshanya/mitter/pkg/pagination"
+	"net/http"
+)
+
+type searchService interface {
+	SearchMitts(ctx context.Context, viewerID uuid.UUID, query string, order models.SearchOrder, limit, offset int32) ([]*models.Mitt, *models.HTTPError)
+	SearchUsers(ctx context.Context, viewerID uuid.UUID, query string, limit, offset int32) ([]*models.User, *models.HTTPError)
+	Autocomplete(ctx context.Context, viewerID uuid.UUID, prefix string) ([]*models.User, *models.HTTPError)
+}
+
+type SearchHandler struct {
+	ss                searchService
+	mm                mittMarker
+	reqAuthMiddleware echo.MiddlewareFunc
+}
+
+func NewSearchHandler(ss searchService, mm mittMarker, reqAuthMdl echo.MiddlewareFunc) *SearchHandler {
+	return &SearchHandler{
+		ss:                ss,
+		mm:                mm,
+		reqAuthMiddleware: reqAuthMdl,
+	}
+}
+
+func (h *SearchHandler) Routes(group *echo.Group) {
+	group.GET("/mitts", h.searchMitts, h.reqAuthMiddleware)
+	group.GET("/users", h.searchUsers, h.reqAuthMiddleware)
+	group.GET("/users/autocomplete", h.autocomplete, h.reqAuthMiddleware)
+}
+
+// searchMitts godoc
+//
+//	@Summary		Search mitts
+//	@Description	Full-text search. Query supports "quoted phrases", OR, -excluded words and filters:
+//	@Description	from:login (any of several), #hashtag (all of several), since:2006-01-02 and until:2006-01-02 (inclusive).
+//	@Description	Deleted mitts, mitts of private accounts I don't follow and of users I blocked, muted or was blocked by are not found
+//	@Tags			Search
+//	@Security		Bearer
+//	@Param			Authorization	header	string	true	"access token 'Bearer {token}'"
+//	@Param			q				query	string	true	"Search query"
+//	@Param			sort			query	string	false	"Order, relevance by default"	Enums(relevance, recent)
+//	@Param			offset			query	int		false	"Offset"
+//	@Param			limit			query	int		false	"Limit"
+//	@Produce		json
+//	@Success		200	{object}	[]dto.MittResponse
+//	@Failure		400	{object}	dto.HTTPError
+//	@Failure		401	{object}	dto.HTTPError
+//	@Failure		500	{object}	dto.HTTPError
+//	@Router			/search/mitts [get]
+func (h *SearchHandler) searchMitts(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID := c.Get("userID").(uuid.UUID)
+
+	limit, offset, err := pagination.GetLimitAndOffset(c, 30)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+	}
+
+	order := models.SearchOrder(c.QueryParam("sort"))
+	if order == "" {
+		order = models.SearchByRelevance
+	}
+
+	mitts, httpErr := h.ss.SearchMitts(ctx, userID, c.QueryParam("q"), order, limit, offset)
+	if httpErr != nil {
+		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
+	}
+	if httpErr := markMitts(c, h.mm, mitts...); httpErr != nil {
+		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
+	}
+
+	return c.JSON(http.StatusOK, mittsToResponse(mitts))
+}
+
+// searchUsers godoc
+//
+//	@Summary		Search users
+//	@Description	Users with login or name similar to query, typos are tolerated.
+//	@Description	Accounts I follow and accounts followed by my friends go first, users I blocked or was blocked by are not found
+//	@Tags			Search
+//	@Security		Bearer
+//	@Param			Authorization	header	string	true	"access token 'Bearer {token}'"
+//	@Param			q				query	string	true	"Login or name"
+//	@Param			offset			query	int		false	"Offset"
+//	@Param			limit			query	int		false	"Limit"
+//	@Produce		json
+//	@Success		200	{object}	[]dto.UserResponse
+//	@Failure		400	{object}	dto.HTTPError
+//	@Failure		401	{object}	dto.HTTPError
+//	@Failure		500	{object}	dto.HTTPError
+//	@Router			/search/users [get]
+func (h *SearchHandler) searchUsers(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID := c.Get("userID").(uuid.UUID)
+
+	limit, offset, err := pagination.GetLimitAndOffset(c, 30)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+	}
+
+	users, httpErr := h.ss.SearchUsers(ctx, userID, c.QueryParam("q"), limit, offset)
+	if httpErr != nil {
+		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
+	}
+
+	return c.JSON(http.StatusOK, usersToResponse(users))
+}
+
+// autocomplete godoc
+//
+//	@Summary		Autocomplete login
+//	@Description	Users whose login starts with prefix, for @mentions. The most followed first, but accounts I follow go before them
+//	@Tags			Search
+//	@Security		Bearer
+//	@Param			Authorization	header	string	true	"access token 'Bearer {token}'"
+//	@Param			q				query	string	true	"Prefix of login, with or without @"
+//	@Produce		json
+//	@Success		200	{object}	[]dto.UserResponse
+//	@Failure		400	{object}	dto.HTTPError
+//	@Failure		401	{object}	dto.HTTPError
+//	@Failure		500	{object}	dto.HTTPError
+//	@Router			/search/users/autocomplete [get]
+func (h *SearchHandler) autocomplete(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID := c.Get("userID").(uuid.UUID)
+
+	users, httpErr := h.ss.Autocomplete(ctx, userID, c.QueryParam("q"))
+	if httpErr != nil {
+		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
+	}
+
+	return c.JSON(http.StatusOK, usersToResponse(users))
+}
@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/misshanya/mitter/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// Mock service
+type mockSearchService struct{}
+
+func (s *mockSearchService) SearchMitts(ctx context.Context, viewerID uuid.UUID, query string, order models.SearchOrder, limit, offset int32) ([]*models.Mitt, *models.HTTPError) {
+	_ = ctx
+	_ = viewerID
+	_ = limit
+	_ = offset
+
+	if query == "" {
+		return nil, &models.HTTPError{Code: http.StatusBadRequest, Message: "Search query is required"}
+	}
+	if order != models.SearchByRelevance && order != models.SearchByRecency {
+		return nil, &models.HTTPError{Code: http.StatusBadRequest, Message: "Unknown sort order"}
+	}
+	return []*models.Mitt{mockMittModel}, nil
+}
+
+func (s *mockSearchService) SearchUsers(ctx context.Context, viewerID uuid.UUID, query string, limit, offset int32) ([]*models.User, *models.HTTPError) {
+	_ = ctx
+	_ = viewerID
+	_ = limit
+	_ = offset
+
+	if query == "" {
+		return nil, &models.HTTPError{Code: http.StatusBadRequest, Message: "Search query is required"}
+	}
+	return []*models.User{{ID: mockUserID, Login: "alice", Name: "Alice"}}, nil
+}
+
+func (s *mockSearchService) Autocomplete(ctx context.Context, viewerID uuid.UUID, prefix string) ([]*models.User, *models.HTTPError) {
+	_ = ctx
+	_ = viewerID
+
+	if prefix == "" {
+		return nil, &models.HTTPError{Code: http.StatusBadRequest, Message: "Search query is required"}
+	}
+	return []*models.User{{ID: mockUserID, Login: "alice", Name: "Alice"}}, nil
+}
+
+// Tests
+func TestSearchHandler_SearchMitts(t *testing.T) {
+	e := echo.New()
+	handler := NewSearchHandler(&mockSearchService{}, newMockBookmarkService(), mockRequireAuth)
+
+	g := e.Group("/api/v1/search")
+	handler.Routes(g)
+
+	for _, tc := range []struct {
+		query url.Values
+		code  int
+	}{
+		{url.Values{"q": {`"hello world" from:bob`}}, http.StatusOK},
+		{url.Values{"q": {"cats"}, "sort": {"recent"}}, http.StatusOK},
+		{url.Values{"q": {"cats"}, "sort": {"popular"}}, http.StatusBadRequest},
+		{url.Values{}, http.StatusBadRequest},
+		{url.Values{"q": {"cats"}, "limit": {"-1"}}, http.StatusBadRequest},
+	} {
+		// Create request
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/search/mitts?"+tc.query.Encode(), nil)
+		rec := httptest.NewRecorder()
+
+		ctx := e.NewContext(req, rec)
+
+		if assert.NoError(t, mockRequireAuth(handler.searchMitts)(ctx)) {
+			assert.Equal(t, tc.code, rec.Code, tc.query.Encode())
+		}
+	}
+}
+
+func TestSearchHandler_SearchUsers(t *testing.T) {
+	e := echo.New()
+	handler := NewSearchHandler(&mockSearchService{}, newMockBookmarkService(), mockRequireAuth)
+
+	g := e.Group("/api/v1/search")
+	handler.Routes(g)
+
+	for _, tc := range []struct {
+		query url.Values
+		code  int
+	}{
+		{url.Values{"q": {"alce"}}, http.StatusOK},
+		{url.Values{}, http.StatusBadRequest},
+	} {
+		// Create request
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/search/users?"+tc.query.Encode(), nil)
+		rec := httptest.NewRecorder()
+
+		ctx := e.NewContext(req, rec)
+
+		if assert.NoError(t, mockRequireAuth(handler.searchUsers)(ctx)) {
+			assert.Equal(t, tc.code, rec.Code, tc.query.Encode())
+		}
+
+		if tc.code == http.StatusOK {
+			assert.Contains(t, rec.Body.String(), `"login":"alice"`)
+		}
+	}
+}
+
+func TestSearchHandler_Autocomplete(t *testing.T) {
+	e := echo.New()
+	handler := NewSearchHandler(&mockSearchService{}, newMockBookmarkService(), mockRequireAuth)
+
+	g := e.Group("/api/v1/search")
+	handler.Routes(g)
+
+	// Create request
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/search/users/autocomplete?q=%40al", nil)
+	rec := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, rec)
+
+	if assert.NoError(t, mockRequireAuth(handler.autocomplete)(ctx)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"login":"alice"`)
+	}
+}
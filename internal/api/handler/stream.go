@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/misshanya/mitter/internal/api/dto"
+	"github.com/misshanya/mitter/internal/models"
+	"net/http"
+	"time"
+)
+
+type streamService interface {
+	Subscribe(ctx context.Context, userID uuid.UUID, lastEventID string) (<-chan *models.Event, *models.HTTPError)
+}
+
+type StreamHandler struct {
+	ss                streamService
+	reqAuthMiddleware echo.MiddlewareFunc
+	heartbeat         time.Duration
+}
+
+// NewStreamHandler creates stream handler which sends heartbeat comments every heartbeat interval,
+// so proxies don't close idle connections
+func NewStreamHandler(ss streamService, reqAuthMdl echo.MiddlewareFunc, heartbeat time.Duration) *StreamHandler {
+	return &StreamHandler{
+		ss:                ss,
+		reqAuthMiddleware: reqAuthMdl,
+		heartbeat:         heartbeat,
+	}
+}
+
+func (h *StreamHandler) Routes(group *echo.Group) {
+	group.GET("", h.stream, h.reqAuthMiddleware)
+}
+
+// stream godoc
+//
+//	@Summary		Stream events
+//	@Description	Server-Sent Events stream of new mitts ("mitt"), likes counts ("likes") and my new followers ("follower").
+//	@Description	Data of event is JSON. Send ID of the last received event in Last-Event-ID to get missed events after reconnect.
+//	@Description	Comments are sent as heartbeat
+//	@Tags			Stream
+//	@Security		Bearer
+//	@Param			Authorization	header	string	true	"access token 'Bearer {token}'"
+//	@Param			Last-Event-ID	header	string	false	"ID of the last received event"
+//	@Produce		text/event-stream
+//	@Success		200
+//	@Failure		400	{object}	dto.HTTPError
+//	@Failure		401	{object}	dto.HTTPError
+//	@Failure		500	{object}	dto.HTTPError
+//	@Router			/stream [get]
+func (h *StreamHandler) stream(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID := c.Get("userID").(uuid.UUID)
+
+	events, httpErr := h.ss.Subscribe(ctx, userID, c.Request().Header.Get("Last-Event-ID"))
+	if httpErr != nil {
+		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
+	}
+
+	w := c.Response()
+	w.Header().Set(echo.HeaderContentType, "text/event-stream")
+	w.Header().Set(echo.HeaderCacheControl, "no-cache")
+	w.Header().Set(echo.HeaderConnection, "keep-alive")
+	// Disable buffering in nginx
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	w.Flush()
+
+	ticker := time.NewTicker(h.heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return nil
+			}
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+			if _, err := fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Type, event.Data); err != nil {
+				return nil
+			}
+		}
+		w.Flush()
+	}
+}
@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/misshanya/mitter/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// Mock service
+type mockStreamService struct{}
+
+func (s *mockStreamService) Subscribe(ctx context.Context, userID uuid.UUID, lastEventID string) (<-chan *models.Event, *models.HTTPError) {
+	_ = ctx
+	_ = userID
+
+	if lastEventID == "invalid" {
+		return nil, &models.HTTPError{Code: http.StatusBadRequest, Message: "Invalid Last-Event-ID"}
+	}
+
+	// One event, then stream ends
+	events := make(chan *models.Event, 1)
+	events <- &models.Event{ID: "1-0,0-0", Type: models.EventLikes, Data: []byte(`{"likes":1}`)}
+	close(events)
+	return events, nil
+}
+
+// Tests
+func TestStreamHandler_Stream(t *testing.T) {
+	e := echo.New()
+	handler := NewStreamHandler(&mockStreamService{}, mockRequireAuth, time.Minute)
+
+	g := e.Group("/api/v1/stream")
+	handler.Routes(g)
+
+	// Create request
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/stream", nil)
+	rec := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, rec)
+
+	if assert.NoError(t, mockRequireAuth(handler.stream)(ctx)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "text/event-stream", rec.Header().Get(echo.HeaderContentType))
+		assert.Equal(t, "id: 1-0,0-0\nevent: likes\ndata: {\"likes\":1}\n\n", rec.Body.String())
+	}
+}
+
+func TestStreamHandler_InvalidLastEventID(t *testing.T) {
+	e := echo.New()
+	handler := NewStreamHandler(&mockStreamService{}, mockRequireAuth, time.Minute)
+
+	g := e.Group("/api/v1/stream")
+	handler.Routes(g)
+
+	// Create request
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/stream", nil)
+	req.Header.Set("Last-Event-ID", "invalid")
+	rec := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, rec)
+
+	if assert.NoError(t, mockRequireAuth(handler.stream)(ctx)) {
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	}
+}
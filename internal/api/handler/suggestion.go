@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/misshanya/mitter/internal/api/dto"
+	"github.com/misshanya/mitter/internal/models"
+	"github.com/misshanya/mitter/pkg/pagination"
+	"net/http"
+)
+
+type suggestionService interface {
+	GetSuggestions(ctx context.Context, userID uuid.UUID, limit int32) ([]*models.User, *models.HTTPError)
+}
+
+type SuggestionHandler struct {
+	service suggestionService
+}
+
+func NewSuggestionHandler(service suggestionService) *SuggestionHandler {
+	return &SuggestionHandler{service: service}
+}
+
+func (h *SuggestionHandler) Routes(group *echo.Group) {
+	group.GET("/suggestions", h.getSuggestions)
+}
+
+// getSuggestions godoc
+//
+//	@Tags			User
+//	@Summary		Who to follow
+//	@Description	Get users suggested to follow (friends of friends, users followed by my follows and popular users)
+//	@Security		Bearer
+//	@Param			Authorization	header		string	true	"access token 'Bearer {token}'"
+//	@Param			limit			query		int		false	"Limit"
+//	@Success		200				{object}	[]dto.UserResponse
+//	@Failure		400				{object}	dto.HTTPError
+//	@Failure		401				{object}	dto.HTTPError
+//	@Failure		500				{object}	dto.HTTPError
+//	@Router			/user/suggestions [get]
+func (h *SuggestionHandler) getSuggestions(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID := c.Get("userID").(uuid.UUID)
+
+	limit, _, err := pagination.GetLimitAndOffset(c, 10)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+	}
+
+	users, httpErr := h.service.GetSuggestions(ctx, userID, limit)
+	if httpErr != nil {
+		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
+	}
+
+	return c.JSON(http.StatusOK, usersToResponse(users))
+}
@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/misshanya/mitter/internal/models"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// Mock service
+type mockSuggestionService struct{}
+
+func (s *mockSuggestionService) GetSuggestions(ctx context.Context, userID uuid.UUID, limit int32) ([]*models.User, *models.HTTPError) {
+	_ = ctx
+	_ = userID
+	_ = limit
+
+	return []*models.User{{
+		ID:             uuid.New(),
+		Login:          "testsuggestion",
+		Name:           "Test Suggestion",
+		HashedPassword: "abracadabra",
+	}}, nil
+}
+
+// Tests
+func TestSuggestionHandler_GetSuggestions(t *testing.T) {
+	e := echo.New()
+
+	handler := NewSuggestionHandler(&mockSuggestionService{})
+
+	g := e.Group("/api/v1/user")
+	handler.Routes(g)
+
+	// Create request
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/user/suggestions", nil)
+	rec := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, rec)
+	ctx.Set("userID", uuid.MustParse("b096376a-5fa9-4130-907a-709c67008a65"))
+
+	if assert.NoError(t, handler.getSuggestions(ctx)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "testsuggestion")
+		assert.NotContains(t, rec.Body.String(), "abracadabra")
+	}
+}
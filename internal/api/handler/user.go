@@ -9,6 +9,7 @@ import (
 	"github.com/misshanya/mitter/internal/models"
 	"github.com/misshanya/mitter/pkg/pagination"
 	"net/http"
+	"strings"
 )
 
 type userService interface {
@@ -22,6 +23,17 @@ type userService interface {
 	GetUserFollows(ctx context.Context, followerID uuid.UUID, limit, offset int32) ([]*models.User, *models.HTTPError)
 	GetUserFollowers(ctx context.Context, followeeID uuid.UUID, limit, offset int32) ([]*models.User, *models.HTTPError)
 	GetUserFriends(ctx context.Context, userID uuid.UUID, limit, offset int32) ([]*models.User, *models.HTTPError)
+
+	BlockUser(ctx context.Context, blockerID uuid.UUID, blockedID uuid.UUID) *models.HTTPError
+	UnblockUser(ctx context.Context, blockerID uuid.UUID, blockedID uuid.UUID) *models.HTTPError
+	MuteUser(ctx context.Context, muterID uuid.UUID, mutedID uuid.UUID) *models.HTTPError
+	UnmuteUser(ctx context.Context, muterID uuid.UUID, mutedID uuid.UUID) *models.HTTPError
+
+	GetFollowsOf(ctx context.Context, viewerID uuid.UUID, userID uuid.UUID, limit, offset int32) ([]*models.User, *models.HTTPError)
+	GetFollowersOf(ctx context.Context, viewerID uuid.UUID, userID uuid.UUID, limit, offset int32) ([]*models.User, *models.HTTPError)
+	GetFriendsOf(ctx context.Context, viewerID uuid.UUID, userID uuid.UUID, limit, offset int32) ([]*models.User, *models.HTTPError)
+	GetKnownFollowers(ctx context.Context, viewerID uuid.UUID, userID uuid.UUID, limit, offset int32) ([]*models.User, *models.HTTPError)
+	GetRelationships(ctx context.Context, userID uuid.UUID, ids []uuid.UUID) ([]*models.Relationship, *models.HTTPError)
 }
 
 type UserHandler struct {
@@ -32,7 +44,7 @@ type UserHandler struct {
 func NewUserHandler(service userService) *UserHandler {
 	return &UserHandler{
 		service:  service,
-		validate: validator.New(),
+		validate: newValidator(),
 	}
 }
 
@@ -46,6 +58,44 @@ func (h *UserHandler) Routes(group *echo.Group) {
 	group.GET("/follows", h.getMyFollows)
 	group.GET("/followers", h.getMyFollowers)
 	group.GET("/friends", h.getMyFriends)
+
+	group.POST("/:id/block", h.blockUser)
+	group.DELETE("/:id/block", h.unblockUser)
+	group.POST("/:id/mute", h.muteUser)
+	group.DELETE("/:id/mute", h.unmuteUser)
+
+	group.GET("/:id/follows", h.getUserFollows)
+	group.GET("/:id/followers", h.getUserFollowers)
+	group.GET("/:id/friends", h.getUserFriends)
+	group.GET("/:id/followers/known", h.getKnownFollowers)
+	group.GET("/relationships", h.getRelationships)
+}
+
+func userToResponse(user *models.User) dto.UserResponse {
+	return dto.UserResponse{
+		ID:             user.ID,
+		Login:          user.Login,
+		Name:           user.Name,
+		IsPrivate:      user.IsPrivate,
+		FollowersCount: user.FollowersCount,
+		FollowingCount: user.FollowingCount,
+		MittsCount:     user.MittsCount,
+	}
+}
+
+func meToResponse(user *models.User) dto.MeResponse {
+	return dto.MeResponse{
+		UserResponse:              userToResponse(user),
+		MessagesFromFollowingOnly: user.MessagesFromFollowingOnly,
+	}
+}
+
+func usersToResponse(users []*models.User) []dto.UserResponse {
+	resp := make([]dto.UserResponse, len(users))
+	for i, user := range users {
+		resp[i] = userToResponse(user)
+	}
+	return resp
 }
 
 // getMe godoc
@@ -55,8 +105,11 @@ func (h *UserHandler) Routes(group *echo.Group) {
 //	@Description	Get info about me
 //	@Security		Bearer
 //	@Param			Authorization	header	string	true	"access token 'Bearer {token}'"
+//	@Param			If-None-Match	header	string	false	"ETag of cached profile"
 //	@Produce		json
-//	@Success		200	{object}	dto.UserResponse
+//	@Success		200	{object}	dto.MeResponse
+//	@Header			200	{string}	ETag	"Version and counters of profile"
+//	@Success		304
 //	@Failure		400	{object}	dto.HTTPError
 //	@Failure		401	{object}	dto.HTTPError
 //	@Failure		404	{object}	dto.HTTPError
@@ -72,25 +125,27 @@ func (h *UserHandler) getMe(c echo.Context) error {
 		return c.JSON(err.Code, dto.HTTPError{Message: err.Message})
 	}
 
-	resp := dto.UserResponse{
-		ID:    user.ID,
-		Login: user.Login,
-		Name:  user.Name,
+	etag := userETag(user)
+	setETag(c, etag)
+	if notModified(c, etag) {
+		return c.NoContent(http.StatusNotModified)
 	}
-	return c.JSON(http.StatusOK, resp)
+
+	return c.JSON(http.StatusOK, meToResponse(user))
 }
 
 // deleteUser godoc
 //
 //	@Tags			User
 //	@Summary		Delete account
-//	@Description	Delete user account
+//	@Description	Deactivate account and sign out everywhere, account can be reactivated during grace period, then it's deleted with all mitts, likes and follows
 //	@Security		Bearer
 //	@Param			Authorization	header	string	true	"access token 'Bearer {token}'"
 //	@Produce		json
 //	@Success		204
 //	@Failure		400	{object}	dto.HTTPError
 //	@Failure		401	{object}	dto.HTTPError
+//	@Failure		404	{object}	dto.HTTPError
 //	@Failure		500	{object}	dto.HTTPError
 //	@Router			/user [delete]
 func (h *UserHandler) deleteUser(c echo.Context) error {
@@ -113,11 +168,13 @@ func (h *UserHandler) deleteUser(c echo.Context) error {
 //	@Description	Update user info
 //	@Security		Bearer
 //	@Param			Authorization		header	string					true	"access token 'Bearer {token}'"
+//	@Param			If-Match			header	string					false	"Update only if profile still has this ETag"
 //	@Param			UpdateUserRequest	body	dto.UserUpdateRequest	true	"Update User Request"
 //	@Accept			json
 //	@Success		200
-//	@Failure		400	{object}	dto.HTTPError
+//	@Failure		400	{object}	dto.ValidationError
 //	@Failure		401	{object}	dto.HTTPError
+//	@Failure		412	{object}	dto.HTTPError
 //	@Failure		500	{object}	dto.HTTPError
 //	@Router			/user [patch]
 func (h *UserHandler) updateUser(c echo.Context) error {
@@ -132,11 +189,14 @@ func (h *UserHandler) updateUser(c echo.Context) error {
 
 	// Validate
 	if err := h.validate.StructCtx(ctx, &req); err != nil {
-		return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+		return validationFailed(c, fieldErrors(err))
 	}
 
 	user := &models.UserUpdate{
-		Name: req.Name,
+		Name:                      req.Name,
+		IsPrivate:                 req.IsPrivate,
+		MessagesFromFollowingOnly: req.MessagesFromFollowingOnly,
+		ExpectedVersion:           ifMatchVersion(c),
 	}
 	err := h.service.UpdateUser(ctx, userID, user)
 	if err != nil {
@@ -238,16 +298,7 @@ func (h *UserHandler) getMyFollows(c echo.Context) error {
 		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
 	}
 
-	resp := make([]dto.UserResponse, len(users))
-	for i, user := range users {
-		resp[i] = dto.UserResponse{
-			ID:    user.ID,
-			Login: user.Login,
-			Name:  user.Name,
-		}
-	}
-
-	return c.JSON(http.StatusOK, resp)
+	return c.JSON(http.StatusOK, usersToResponse(users))
 }
 
 // getMyFollowers godoc
@@ -278,16 +329,7 @@ func (h *UserHandler) getMyFollowers(c echo.Context) error {
 		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
 	}
 
-	resp := make([]dto.UserResponse, len(users))
-	for i, user := range users {
-		resp[i] = dto.UserResponse{
-			ID:    user.ID,
-			Login: user.Login,
-			Name:  user.Name,
-		}
-	}
-
-	return c.JSON(http.StatusOK, resp)
+	return c.JSON(http.StatusOK, usersToResponse(users))
 }
 
 // getMyFriends godoc
@@ -318,12 +360,346 @@ func (h *UserHandler) getMyFriends(c echo.Context) error {
 		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
 	}
 
-	resp := make([]dto.UserResponse, len(users))
-	for i, user := range users {
-		resp[i] = dto.UserResponse{
-			ID:    user.ID,
-			Login: user.Login,
-			Name:  user.Name,
+	return c.JSON(http.StatusOK, usersToResponse(users))
+}
+
+// blockUser godoc
+//
+//	@Tags		User
+//	@Summary	Block user
+//	@Security	Bearer
+//	@Param		Authorization	header	string	true	"access token 'Bearer {token}'"
+//	@Param		id				path	string	true	"ID of user to block"
+//	@Success	204
+//	@Failure	400	{object}	dto.HTTPError
+//	@Failure	401	{object}	dto.HTTPError
+//	@Failure	404	{object}	dto.HTTPError
+//	@Failure	500	{object}	dto.HTTPError
+//	@Router		/user/{id}/block [post]
+func (h *UserHandler) blockUser(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID := c.Get("userID").(uuid.UUID)
+
+	targetIDStr := c.Param("id")
+	targetID, err := uuid.Parse(targetIDStr)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+	}
+
+	httpErr := h.service.BlockUser(ctx, userID, targetID)
+	if httpErr != nil {
+		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// unblockUser godoc
+//
+//	@Tags		User
+//	@Summary	Unblock user
+//	@Security	Bearer
+//	@Param		Authorization	header	string	true	"access token 'Bearer {token}'"
+//	@Param		id				path	string	true	"ID of user to unblock"
+//	@Success	204
+//	@Failure	400	{object}	dto.HTTPError
+//	@Failure	401	{object}	dto.HTTPError
+//	@Failure	500	{object}	dto.HTTPError
+//	@Router		/user/{id}/block [delete]
+func (h *UserHandler) unblockUser(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID := c.Get("userID").(uuid.UUID)
+
+	targetIDStr := c.Param("id")
+	targetID, err := uuid.Parse(targetIDStr)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+	}
+
+	httpErr := h.service.UnblockUser(ctx, userID, targetID)
+	if httpErr != nil {
+		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// muteUser godoc
+//
+//	@Tags		User
+//	@Summary	Mute user
+//	@Security	Bearer
+//	@Param		Authorization	header	string	true	"access token 'Bearer {token}'"
+//	@Param		id				path	string	true	"ID of user to mute"
+//	@Success	204
+//	@Failure	400	{object}	dto.HTTPError
+//	@Failure	401	{object}	dto.HTTPError
+//	@Failure	404	{object}	dto.HTTPError
+//	@Failure	500	{object}	dto.HTTPError
+//	@Router		/user/{id}/mute [post]
+func (h *UserHandler) muteUser(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID := c.Get("userID").(uuid.UUID)
+
+	targetIDStr := c.Param("id")
+	targetID, err := uuid.Parse(targetIDStr)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+	}
+
+	httpErr := h.service.MuteUser(ctx, userID, targetID)
+	if httpErr != nil {
+		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// unmuteUser godoc
+//
+//	@Tags		User
+//	@Summary	Unmute user
+//	@Security	Bearer
+//	@Param		Authorization	header	string	true	"access token 'Bearer {token}'"
+//	@Param		id				path	string	true	"ID of user to unmute"
+//	@Success	204
+//	@Failure	400	{object}	dto.HTTPError
+//	@Failure	401	{object}	dto.HTTPError
+//	@Failure	500	{object}	dto.HTTPError
+//	@Router		/user/{id}/mute [delete]
+func (h *UserHandler) unmuteUser(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID := c.Get("userID").(uuid.UUID)
+
+	targetIDStr := c.Param("id")
+	targetID, err := uuid.Parse(targetIDStr)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+	}
+
+	httpErr := h.service.UnmuteUser(ctx, userID, targetID)
+	if httpErr != nil {
+		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// getUserFollows godoc
+//
+//	@Tags			User
+//	@Summary		Get User Follows
+//	@Description	Get users followed by the user (private accounts are visible only to their followers)
+//	@Security		Bearer
+//	@Param			Authorization	header		string	true	"access token 'Bearer {token}'"
+//	@Param			id				path		string	true	"ID of user"
+//	@Param			offset			query		int		false	"Offset"
+//	@Param			limit			query		int		false	"Limit"
+//	@Success		200				{object}	[]dto.UserResponse
+//	@Failure		400				{object}	dto.HTTPError
+//	@Failure		401				{object}	dto.HTTPError
+//	@Failure		403				{object}	dto.HTTPError
+//	@Failure		404				{object}	dto.HTTPError
+//	@Failure		500				{object}	dto.HTTPError
+//	@Router			/user/{id}/follows [get]
+func (h *UserHandler) getUserFollows(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	viewerID := c.Get("userID").(uuid.UUID)
+
+	userIDStr := c.Param("id")
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+	}
+
+	limit, offset, err := pagination.GetLimitAndOffset(c, 30)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+	}
+
+	users, httpErr := h.service.GetFollowsOf(ctx, viewerID, userID, limit, offset)
+	if httpErr != nil {
+		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
+	}
+
+	return c.JSON(http.StatusOK, usersToResponse(users))
+}
+
+// getUserFollowers godoc
+//
+//	@Tags			User
+//	@Summary		Get User Followers
+//	@Description	Get followers of the user (private accounts are visible only to their followers)
+//	@Security		Bearer
+//	@Param			Authorization	header		string	true	"access token 'Bearer {token}'"
+//	@Param			id				path		string	true	"ID of user"
+//	@Param			offset			query		int		false	"Offset"
+//	@Param			limit			query		int		false	"Limit"
+//	@Success		200				{object}	[]dto.UserResponse
+//	@Failure		400				{object}	dto.HTTPError
+//	@Failure		401				{object}	dto.HTTPError
+//	@Failure		403				{object}	dto.HTTPError
+//	@Failure		404				{object}	dto.HTTPError
+//	@Failure		500				{object}	dto.HTTPError
+//	@Router			/user/{id}/followers [get]
+func (h *UserHandler) getUserFollowers(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	viewerID := c.Get("userID").(uuid.UUID)
+
+	userIDStr := c.Param("id")
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+	}
+
+	limit, offset, err := pagination.GetLimitAndOffset(c, 30)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+	}
+
+	users, httpErr := h.service.GetFollowersOf(ctx, viewerID, userID, limit, offset)
+	if httpErr != nil {
+		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
+	}
+
+	return c.JSON(http.StatusOK, usersToResponse(users))
+}
+
+// getUserFriends godoc
+//
+//	@Tags			User
+//	@Summary		Get User Friends
+//	@Description	Get friends of the user (private accounts are visible only to their followers)
+//	@Security		Bearer
+//	@Param			Authorization	header		string	true	"access token 'Bearer {token}'"
+//	@Param			id				path		string	true	"ID of user"
+//	@Param			offset			query		int		false	"Offset"
+//	@Param			limit			query		int		false	"Limit"
+//	@Success		200				{object}	[]dto.UserResponse
+//	@Failure		400				{object}	dto.HTTPError
+//	@Failure		401				{object}	dto.HTTPError
+//	@Failure		403				{object}	dto.HTTPError
+//	@Failure		404				{object}	dto.HTTPError
+//	@Failure		500				{object}	dto.HTTPError
+//	@Router			/user/{id}/friends [get]
+func (h *UserHandler) getUserFriends(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	viewerID := c.Get("userID").(uuid.UUID)
+
+	userIDStr := c.Param("id")
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+	}
+
+	limit, offset, err := pagination.GetLimitAndOffset(c, 30)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+	}
+
+	users, httpErr := h.service.GetFriendsOf(ctx, viewerID, userID, limit, offset)
+	if httpErr != nil {
+		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
+	}
+
+	return c.JSON(http.StatusOK, usersToResponse(users))
+}
+
+// getKnownFollowers godoc
+//
+//	@Tags			User
+//	@Summary		Followers You Know
+//	@Description	Get followers of the user that are followed by me
+//	@Security		Bearer
+//	@Param			Authorization	header		string	true	"access token 'Bearer {token}'"
+//	@Param			id				path		string	true	"ID of user"
+//	@Param			offset			query		int		false	"Offset"
+//	@Param			limit			query		int		false	"Limit"
+//	@Success		200				{object}	[]dto.UserResponse
+//	@Failure		400				{object}	dto.HTTPError
+//	@Failure		401				{object}	dto.HTTPError
+//	@Failure		403				{object}	dto.HTTPError
+//	@Failure		404				{object}	dto.HTTPError
+//	@Failure		500				{object}	dto.HTTPError
+//	@Router			/user/{id}/followers/known [get]
+func (h *UserHandler) getKnownFollowers(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	viewerID := c.Get("userID").(uuid.UUID)
+
+	userIDStr := c.Param("id")
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+	}
+
+	limit, offset, err := pagination.GetLimitAndOffset(c, 30)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+	}
+
+	users, httpErr := h.service.GetKnownFollowers(ctx, viewerID, userID, limit, offset)
+	if httpErr != nil {
+		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
+	}
+
+	return c.JSON(http.StatusOK, usersToResponse(users))
+}
+
+// getRelationships godoc
+//
+//	@Tags			User
+//	@Summary		Get Relationships
+//	@Description	Get my relationships (follow, block, mute) with up to 100 users
+//	@Security		Bearer
+//	@Param			Authorization	header		string	true	"access token 'Bearer {token}'"
+//	@Param			ids				query		string	true	"Comma separated IDs of users"
+//	@Success		200				{object}	[]dto.RelationshipResponse
+//	@Failure		400				{object}	dto.HTTPError
+//	@Failure		401				{object}	dto.HTTPError
+//	@Failure		500				{object}	dto.HTTPError
+//	@Router			/user/relationships [get]
+func (h *UserHandler) getRelationships(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID := c.Get("userID").(uuid.UUID)
+
+	idsStr := c.QueryParam("ids")
+	if idsStr == "" {
+		return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: "ids are required"})
+	}
+
+	parts := strings.Split(idsStr, ",")
+	ids := make([]uuid.UUID, len(parts))
+	for i, part := range parts {
+		id, err := uuid.Parse(strings.TrimSpace(part))
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+		}
+		ids[i] = id
+	}
+
+	relationships, httpErr := h.service.GetRelationships(ctx, userID, ids)
+	if httpErr != nil {
+		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
+	}
+
+	resp := make([]dto.RelationshipResponse, len(relationships))
+	for i, rel := range relationships {
+		resp[i] = dto.RelationshipResponse{
+			ID:         rel.UserID,
+			Following:  rel.Following,
+			FollowedBy: rel.FollowedBy,
+			Blocking:   rel.Blocking,
+			BlockedBy:  rel.BlockedBy,
+			Muting:     rel.Muting,
 		}
 	}
 
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"context"
+	"encoding/json"
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"github.com/misshanya/mitter/internal/models"
@@ -96,6 +97,90 @@ func (s *mockUserService) GetUserFriends(ctx context.Context, userID uuid.UUID,
 	}}, nil
 }
 
+func (s *mockUserService) BlockUser(ctx context.Context, blockerID uuid.UUID, blockedID uuid.UUID) *models.HTTPError {
+	_ = ctx
+	_ = blockerID
+	_ = blockedID
+
+	return nil
+}
+
+func (s *mockUserService) UnblockUser(ctx context.Context, blockerID uuid.UUID, blockedID uuid.UUID) *models.HTTPError {
+	_ = ctx
+	_ = blockerID
+	_ = blockedID
+
+	return nil
+}
+
+func (s *mockUserService) MuteUser(ctx context.Context, muterID uuid.UUID, mutedID uuid.UUID) *models.HTTPError {
+	_ = ctx
+	_ = muterID
+	_ = mutedID
+
+	return nil
+}
+
+func (s *mockUserService) UnmuteUser(ctx context.Context, muterID uuid.UUID, mutedID uuid.UUID) *models.HTTPError {
+	_ = ctx
+	_ = muterID
+	_ = mutedID
+
+	return nil
+}
+
+func (s *mockUserService) GetFollowsOf(ctx context.Context, viewerID uuid.UUID, userID uuid.UUID, limit, offset int32) ([]*models.User, *models.HTTPError) {
+	_ = ctx
+	_ = viewerID
+	_ = userID
+	_ = limit
+	_ = offset
+
+	return []*models.User{{ID: uuid.New(), Login: "testfollow", Name: "Test Follow"}}, nil
+}
+
+func (s *mockUserService) GetFollowersOf(ctx context.Context, viewerID uuid.UUID, userID uuid.UUID, limit, offset int32) ([]*models.User, *models.HTTPError) {
+	_ = ctx
+	_ = viewerID
+	_ = userID
+	_ = limit
+	_ = offset
+
+	return []*models.User{{ID: uuid.New(), Login: "testfollower", Name: "Test Follower"}}, nil
+}
+
+func (s *mockUserService) GetFriendsOf(ctx context.Context, viewerID uuid.UUID, userID uuid.UUID, limit, offset int32) ([]*models.User, *models.HTTPError) {
+	_ = ctx
+	_ = viewerID
+	_ = userID
+	_ = limit
+	_ = offset
+
+	return []*models.User{{ID: uuid.New(), Login: "testfriend", Name: "Test Friend"}}, nil
+}
+
+func (s *mockUserService) GetKnownFollowers(ctx context.Context, viewerID uuid.UUID, userID uuid.UUID, limit, offset int32) ([]*models.User, *models.HTTPError) {
+	_ = ctx
+	_ = viewerID
+	_ = userID
+	_ = limit
+	_ = offset
+
+	return []*models.User{{ID: uuid.New(), Login: "testknown", Name: "Test Known"}}, nil
+}
+
+func (s *mockUserService) GetRelationships(ctx context.Context, userID uuid.UUID, ids []uuid.UUID) ([]*models.Relationship, *models.HTTPError) {
+	_ = ctx
+	_ = userID
+	_ = ids
+
+	relationships := make([]*models.Relationship, len(ids))
+	for i, id := range ids {
+		relationships[i] = &models.Relationship{UserID: id, FollowedBy: true}
+	}
+	return relationships, nil
+}
+
 // Tests
 func TestUserHandler_GetMe(t *testing.T) {
 	e := echo.New()
@@ -116,6 +201,38 @@ func TestUserHandler_GetMe(t *testing.T) {
 		assert.Equal(t, http.StatusOK, rec.Code)
 		assert.Contains(t, rec.Body.String(), "testuser")
 		assert.Contains(t, rec.Body.String(), "Test User")
+		// Settings are shown only to me
+		assert.Contains(t, rec.Body.String(), `"messages_from_following_only":`)
+	}
+}
+
+func TestUserHandler_UserResponseHidesSettings(t *testing.T) {
+	body, err := json.Marshal(userToResponse(&models.User{Login: "testuser", MessagesFromFollowingOnly: true}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NotContains(t, string(body), "messages_from_following_only")
+}
+
+func TestUserHandler_GetMeNotModified(t *testing.T) {
+	e := echo.New()
+
+	handler := NewUserHandler(&mockUserService{})
+
+	g := e.Group("/api/v1/user")
+	handler.Routes(g)
+
+	// Create request, mock user always has zero version
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/user/", nil)
+	req.Header.Set("If-None-Match", `"0-0-0-0"`)
+	rec := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, rec)
+	ctx.Set("userID", uuid.MustParse("b096376a-5fa9-4130-907a-709c67008a65"))
+
+	if assert.NoError(t, handler.getMe(ctx)) {
+		assert.Equal(t, http.StatusNotModified, rec.Code)
+		assert.Equal(t, `"0-0-0-0"`, rec.Header().Get("ETag"))
 	}
 }
 
@@ -158,3 +275,111 @@ func TestUserHandler_UpdateUser(t *testing.T) {
 		assert.Equal(t, http.StatusOK, rec.Code)
 	}
 }
+
+func TestUserHandler_BlockUser(t *testing.T) {
+	e := echo.New()
+
+	handler := NewUserHandler(&mockUserService{})
+
+	g := e.Group("/api/v1/user")
+	handler.Routes(g)
+
+	// Create request
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/user/38386ffe-54ac-48be-9244-a5144b41a014/block", nil)
+	rec := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, rec)
+	ctx.Set("userID", uuid.MustParse("b096376a-5fa9-4130-907a-709c67008a65"))
+
+	// Set path param (id)
+	ctx.SetPath("/api/v1/user/:id/block")
+	ctx.SetParamNames("id")
+	ctx.SetParamValues("38386ffe-54ac-48be-9244-a5144b41a014")
+
+	if assert.NoError(t, handler.blockUser(ctx)) {
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+	}
+}
+
+func TestUserHandler_MuteUser(t *testing.T) {
+	e := echo.New()
+
+	handler := NewUserHandler(&mockUserService{})
+
+	g := e.Group("/api/v1/user")
+	handler.Routes(g)
+
+	// Create request
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/user/38386ffe-54ac-48be-9244-a5144b41a014/mute", nil)
+	rec := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, rec)
+	ctx.Set("userID", uuid.MustParse("b096376a-5fa9-4130-907a-709c67008a65"))
+
+	// Set path param (id)
+	ctx.SetPath("/api/v1/user/:id/mute")
+	ctx.SetParamNames("id")
+	ctx.SetParamValues("38386ffe-54ac-48be-9244-a5144b41a014")
+
+	if assert.NoError(t, handler.muteUser(ctx)) {
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+	}
+}
+
+func TestUserHandler_GetUserFollowers(t *testing.T) {
+	e := echo.New()
+
+	handler := NewUserHandler(&mockUserService{})
+
+	g := e.Group("/api/v1/user")
+	handler.Routes(g)
+
+	// Create request
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/user/38386ffe-54ac-48be-9244-a5144b41a014/followers", nil)
+	rec := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, rec)
+	ctx.Set("userID", uuid.MustParse("b096376a-5fa9-4130-907a-709c67008a65"))
+
+	// Set path param (id)
+	ctx.SetPath("/api/v1/user/:id/followers")
+	ctx.SetParamNames("id")
+	ctx.SetParamValues("38386ffe-54ac-48be-9244-a5144b41a014")
+
+	if assert.NoError(t, handler.getUserFollowers(ctx)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "testfollower")
+	}
+}
+
+func TestUserHandler_GetRelationships(t *testing.T) {
+	e := echo.New()
+
+	handler := NewUserHandler(&mockUserService{})
+
+	g := e.Group("/api/v1/user")
+	handler.Routes(g)
+
+	// Create request
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/user/relationships?ids=38386ffe-54ac-48be-9244-a5144b41a014", nil)
+	rec := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, rec)
+	ctx.Set("userID", uuid.MustParse("b096376a-5fa9-4130-907a-709c67008a65"))
+
+	if assert.NoError(t, handler.getRelationships(ctx)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `[{"id":"38386ffe-54ac-48be-9244-a5144b41a014","following":false,"followed_by":true,"blocking":false,"blocked_by":false,"muting":false}]`, rec.Body.String())
+	}
+
+	// Invalid id
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/user/relationships?ids=abc", nil)
+	rec = httptest.NewRecorder()
+
+	ctx = e.NewContext(req, rec)
+	ctx.Set("userID", uuid.MustParse("b096376a-5fa9-4130-907a-709c67008a65"))
+
+	if assert.NoError(t, handler.getRelationships(ctx)) {
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	}
+}
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/misshanya/mitter/internal/api/dto"
+	"github.com/misshanya/mitter/pkg/content"
+)
+
+// newValidator creates validator which reports fields by their json names
+func newValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(func(fld reflect.StructField) string {
+		name := strings.SplitN(fld.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	// Logins must be mentionable as "@login"
+	_ = v.RegisterValidation("login", func(fl validator.FieldLevel) bool {
+		return content.ValidLogin(fl.Field().String())
+	})
+	return v
+}
+
+// fieldErrors converts validator errors into list of offending fields
+func fieldErrors(err error) []dto.FieldError {
+	var errs validator.ValidationErrors
+	if !errors.As(err, &errs) {
+		return []dto.FieldError{{Error: err.Error()}}
+	}
+
+	fields := make([]dto.FieldError, len(errs))
+	for i, fe := range errs {
+		fields[i] = dto.FieldError{
+			Field: fe.Field(),
+			Error: fieldErrorMessage(fe),
+		}
+	}
+	return fields
+}
+
+func fieldErrorMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "min":
+		return fmt.Sprintf("must be at least %s characters", fe.Param())
+	case "max":
+		return fmt.Sprintf("must be at most %s characters", fe.Param())
+	case "login":
+		return "may contain only letters, digits, '_', '.' and '-', and must start and end with a letter, digit or '_'"
+	default:
+		return fmt.Sprintf("failed on '%s' rule", fe.Tag())
+	}
+}
+
+// validationFailed responds with 400 and list of offending fields
+func validationFailed(c echo.Context, fields []dto.FieldError) error {
+	return c.JSON(http.StatusBadRequest, dto.ValidationError{
+		Message: "Validation failed",
+		Fields:  fields,
+	})
+}
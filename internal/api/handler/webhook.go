@@ -0,0 +1,251 @@
+package handler
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/misshanya/mitter/internal/api/dto"
+	"github.com/misshanya/mitter/internal/models"
+	"github.com/misshanya/mitter/pkg/pagination"
+	"net/http"
+)
+
+type webhookService interface {
+	CreateWebhook(ctx context.Context, userID uuid.UUID, webhook *models.WebhookCreate) (*models.Webhook, *models.HTTPError)
+	GetWebhooks(ctx context.Context, userID uuid.UUID) ([]*models.Webhook, *models.HTTPError)
+	DeleteWebhook(ctx context.Context, userID uuid.UUID, id uuid.UUID) *models.HTTPError
+	GetDeliveries(ctx context.Context, userID uuid.UUID, webhookID uuid.UUID, limit, offset int32) ([]*models.WebhookDelivery, *models.HTTPError)
+	Redeliver(ctx context.Context, userID uuid.UUID, webhookID uuid.UUID, id uuid.UUID) *models.HTTPError
+}
+
+type WebhookHandler struct {
+	ws                webhookService
+	reqAuthMiddleware echo.MiddlewareFunc
+}
+
+func NewWebhookHandler(ws webhookService, reqAuthMdl echo.MiddlewareFunc) *WebhookHandler {
+	return &WebhookHandler{
+		ws:                ws,
+		reqAuthMiddleware: reqAuthMdl,
+	}
+}
+
+func (h *WebhookHandler) Routes(group *echo.Group) {
+	group.POST("", h.createWebhook, h.reqAuthMiddleware)
+	group.GET("", h.getWebhooks, h.reqAuthMiddleware)
+	group.DELETE("/:id", h.deleteWebhook, h.reqAuthMiddleware)
+	group.GET("/:id/deliveries", h.getDeliveries, h.reqAuthMiddleware)
+	group.POST("/:id/deliveries/:delivery_id/redeliver", h.redeliver, h.reqAuthMiddleware)
+}
+
+func webhookToResponse(webhook *models.Webhook) dto.WebhookResponse {
+	events := make([]string, len(webhook.Events))
+	for i, t := range webhook.Events {
+		events[i] = string(t)
+	}
+
+	return dto.WebhookResponse{
+		ID:        webhook.ID,
+		URL:       webhook.URL,
+		Events:    events,
+		CreatedAt: webhook.CreatedAt,
+	}
+}
+
+// createWebhook godoc
+//
+//	@Summary		Create webhook
+//	@Description	Register URL that receives JSON payloads about likes of my mitts, new followers and mentions.
+//	@Description	Requests have X-Mitter-Event, X-Mitter-Delivery and X-Mitter-Signature ("sha256=" and hex of HMAC-SHA256 of body with secret) headers.
+//	@Description	Deliveries without 2xx response are retried with exponential backoff
+//	@Tags			Webhooks
+//	@Security		Bearer
+//	@Param			Authorization	header	string						true	"access token 'Bearer {token}'"
+//	@Param			webhook			body	dto.WebhookCreateRequest	true	"Webhook"
+//	@Accept			json
+//	@Produce		json
+//	@Success		201	{object}	dto.WebhookResponse
+//	@Failure		400	{object}	dto.HTTPError
+//	@Failure		401	{object}	dto.HTTPError
+//	@Failure		500	{object}	dto.HTTPError
+//	@Router			/webhook [post]
+func (h *WebhookHandler) createWebhook(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID := c.Get("userID").(uuid.UUID)
+
+	var req dto.WebhookCreateRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+	}
+
+	events := make([]models.NotificationType, len(req.Events))
+	for i, t := range req.Events {
+		events[i] = models.NotificationType(t)
+	}
+
+	webhook, httpErr := h.ws.CreateWebhook(ctx, userID, &models.WebhookCreate{
+		URL:    req.URL,
+		Events: events,
+	})
+	if httpErr != nil {
+		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
+	}
+
+	resp := webhookToResponse(webhook)
+	resp.Secret = webhook.Secret
+
+	return c.JSON(http.StatusCreated, resp)
+}
+
+// getWebhooks godoc
+//
+//	@Summary	Get my webhooks
+//	@Tags		Webhooks
+//	@Security	Bearer
+//	@Param		Authorization	header	string	true	"access token 'Bearer {token}'"
+//	@Produce	json
+//	@Success	200	{object}	[]dto.WebhookResponse
+//	@Failure	401	{object}	dto.HTTPError
+//	@Failure	500	{object}	dto.HTTPError
+//	@Router		/webhook [get]
+func (h *WebhookHandler) getWebhooks(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID := c.Get("userID").(uuid.UUID)
+
+	webhooks, httpErr := h.ws.GetWebhooks(ctx, userID)
+	if httpErr != nil {
+		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
+	}
+
+	resp := make([]dto.WebhookResponse, len(webhooks))
+	for i, webhook := range webhooks {
+		resp[i] = webhookToResponse(webhook)
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// deleteWebhook godoc
+//
+//	@Summary	Delete webhook
+//	@Tags		Webhooks
+//	@Security	Bearer
+//	@Param		Authorization	header	string	true	"access token 'Bearer {token}'"
+//	@Param		id				path	string	true	"ID of webhook"
+//	@Success	204
+//	@Failure	400	{object}	dto.HTTPError
+//	@Failure	401	{object}	dto.HTTPError
+//	@Failure	404	{object}	dto.HTTPError
+//	@Failure	500	{object}	dto.HTTPError
+//	@Router		/webhook/{id} [delete]
+func (h *WebhookHandler) deleteWebhook(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID := c.Get("userID").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+	}
+
+	if httpErr := h.ws.DeleteWebhook(ctx, userID, id); httpErr != nil {
+		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// getDeliveries godoc
+//
+//	@Summary		Get webhook deliveries
+//	@Description	Delivery log with status and response code of the last attempt, newest first
+//	@Tags			Webhooks
+//	@Security		Bearer
+//	@Param			Authorization	header	string	true	"access token 'Bearer {token}'"
+//	@Param			id				path	string	true	"ID of webhook"
+//	@Param			offset			query	int		false	"Offset"
+//	@Param			limit			query	int		false	"Limit"
+//	@Produce		json
+//	@Success		200	{object}	[]dto.WebhookDeliveryResponse
+//	@Failure		400	{object}	dto.HTTPError
+//	@Failure		401	{object}	dto.HTTPError
+//	@Failure		404	{object}	dto.HTTPError
+//	@Failure		500	{object}	dto.HTTPError
+//	@Router			/webhook/{id}/deliveries [get]
+func (h *WebhookHandler) getDeliveries(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID := c.Get("userID").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+	}
+
+	limit, offset, err := pagination.GetLimitAndOffset(c, 30)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+	}
+
+	deliveries, httpErr := h.ws.GetDeliveries(ctx, userID, id, limit, offset)
+	if httpErr != nil {
+		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
+	}
+
+	resp := make([]dto.WebhookDeliveryResponse, len(deliveries))
+	for i, d := range deliveries {
+		resp[i] = dto.WebhookDeliveryResponse{
+			ID:            d.ID,
+			Event:         string(d.Event),
+			Payload:       d.Payload,
+			Status:        string(d.Status),
+			Attempts:      d.Attempts,
+			ResponseCode:  d.ResponseCode,
+			Error:         d.Error,
+			CreatedAt:     d.CreatedAt,
+			LastAttemptAt: d.LastAttemptAt,
+			DeliveredAt:   d.DeliveredAt,
+		}
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// redeliver godoc
+//
+//	@Summary		Redeliver
+//	@Description	Queue delivery again with a fresh set of attempts
+//	@Tags			Webhooks
+//	@Security		Bearer
+//	@Param			Authorization	header	string	true	"access token 'Bearer {token}'"
+//	@Param			id				path	string	true	"ID of webhook"
+//	@Param			delivery_id		path	string	true	"ID of delivery"
+//	@Success		202
+//	@Failure		400	{object}	dto.HTTPError
+//	@Failure		401	{object}	dto.HTTPError
+//	@Failure		404	{object}	dto.HTTPError
+//	@Failure		409	{object}	dto.HTTPError
+//	@Failure		500	{object}	dto.HTTPError
+//	@Router			/webhook/{id}/deliveries/{delivery_id}/redeliver [post]
+func (h *WebhookHandler) redeliver(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID := c.Get("userID").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+	}
+	deliveryID, err := uuid.Parse(c.Param("delivery_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.HTTPError{Message: err.Error()})
+	}
+
+	if httpErr := h.ws.Redeliver(ctx, userID, id, deliveryID); httpErr != nil {
+		return c.JSON(httpErr.Code, dto.HTTPError{Message: httpErr.Message})
+	}
+
+	return c.NoContent(http.StatusAccepted)
+}
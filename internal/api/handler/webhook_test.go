@@ -0,0 +1,241 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/misshanya/mitter/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+var mockWebhook = &models.Webhook{
+	ID:        uuid.MustParse("0c5e8d7a-1b2c-4d3e-9f40-5a6b7c8d9e0f"),
+	UserID:    mockUserID,
+	URL:       "https://example.com/hook",
+	Secret:    "secret",
+	Events:    []models.NotificationType{models.NotificationLike},
+	CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+}
+
+var mockWebhookDelivery = &models.WebhookDelivery{
+	ID:        uuid.MustParse("1d6f9e8b-2c3d-4e4f-8a51-6b7c8d9e0f1a"),
+	WebhookID: mockWebhook.ID,
+	Event:     models.NotificationLike,
+	Payload:   json.RawMessage(`{"event":"like"}`),
+	Status:    models.WebhookDeliveryFailed,
+	Attempts:  8,
+	Error:     "timeout",
+	CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+}
+
+// Mock service
+type mockWebhookService struct{}
+
+func (s *mockWebhookService) CreateWebhook(ctx context.Context, userID uuid.UUID, webhook *models.WebhookCreate) (*models.Webhook, *models.HTTPError) {
+	_ = ctx
+
+	if webhook.URL == "" {
+		return nil, &models.HTTPError{Code: http.StatusBadRequest, Message: "Invalid webhook URL"}
+	}
+
+	return &models.Webhook{
+		ID:        mockWebhook.ID,
+		UserID:    userID,
+		URL:       webhook.URL,
+		Secret:    mockWebhook.Secret,
+		Events:    webhook.Events,
+		CreatedAt: mockWebhook.CreatedAt,
+	}, nil
+}
+
+func (s *mockWebhookService) GetWebhooks(ctx context.Context, userID uuid.UUID) ([]*models.Webhook, *models.HTTPError) {
+	_ = ctx
+	_ = userID
+
+	return []*models.Webhook{mockWebhook}, nil
+}
+
+func (s *mockWebhookService) DeleteWebhook(ctx context.Context, userID uuid.UUID, id uuid.UUID) *models.HTTPError {
+	_ = ctx
+	_ = userID
+
+	if id != mockWebhook.ID {
+		return &models.HTTPError{Code: http.StatusNotFound, Message: "Webhook not found"}
+	}
+	return nil
+}
+
+func (s *mockWebhookService) GetDeliveries(ctx context.Context, userID uuid.UUID, webhookID uuid.UUID, limit, offset int32) ([]*models.WebhookDelivery, *models.HTTPError) {
+	_ = ctx
+	_ = userID
+	_ = limit
+	_ = offset
+
+	if webhookID != mockWebhook.ID {
+		return nil, &models.HTTPError{Code: http.StatusNotFound, Message: "Webhook not found"}
+	}
+	return []*models.WebhookDelivery{mockWebhookDelivery}, nil
+}
+
+func (s *mockWebhookService) Redeliver(ctx context.Context, userID uuid.UUID, webhookID uuid.UUID, id uuid.UUID) *models.HTTPError {
+	_ = ctx
+	_ = userID
+	_ = webhookID
+
+	if id != mockWebhookDelivery.ID {
+		return &models.HTTPError{Code: http.StatusNotFound, Message: "Delivery not found"}
+	}
+	return nil
+}
+
+// Tests
+func TestWebhookHandler_CreateWebhook(t *testing.T) {
+	e := echo.New()
+	handler := NewWebhookHandler(&mockWebhookService{}, mockRequireAuth)
+
+	g := e.Group("/api/v1/webhook")
+	handler.Routes(g)
+
+	for _, tc := range []struct {
+		body string
+		code int
+	}{
+		{`{"url":"https://example.com/hook","events":["like","follow"]}`, http.StatusCreated},
+		{`{"events":["like"]}`, http.StatusBadRequest},
+	} {
+		// Create request
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/webhook", strings.NewReader(tc.body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+
+		ctx := e.NewContext(req, rec)
+
+		if assert.NoError(t, mockRequireAuth(handler.createWebhook)(ctx)) {
+			assert.Equal(t, tc.code, rec.Code)
+		}
+
+		if tc.code == http.StatusCreated {
+			assert.Contains(t, rec.Body.String(), `"secret":"secret"`)
+			assert.Contains(t, rec.Body.String(), `"events":["like","follow"]`)
+		}
+	}
+}
+
+func TestWebhookHandler_GetWebhooks(t *testing.T) {
+	e := echo.New()
+	handler := NewWebhookHandler(&mockWebhookService{}, mockRequireAuth)
+
+	g := e.Group("/api/v1/webhook")
+	handler.Routes(g)
+
+	// Create request
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/webhook", nil)
+	rec := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, rec)
+
+	if assert.NoError(t, mockRequireAuth(handler.getWebhooks)(ctx)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"url":"https://example.com/hook"`)
+		// Secret is shown only on creation
+		assert.NotContains(t, rec.Body.String(), `"secret"`)
+	}
+}
+
+func TestWebhookHandler_DeleteWebhook(t *testing.T) {
+	e := echo.New()
+	handler := NewWebhookHandler(&mockWebhookService{}, mockRequireAuth)
+
+	g := e.Group("/api/v1/webhook")
+	handler.Routes(g)
+
+	for _, tc := range []struct {
+		id   string
+		code int
+	}{
+		{mockWebhook.ID.String(), http.StatusNoContent},
+		{uuid.NewString(), http.StatusNotFound},
+		{"not-uuid", http.StatusBadRequest},
+	} {
+		// Create request
+		req := httptest.NewRequest(http.MethodDelete, "/api/v1/webhook/"+tc.id, nil)
+		rec := httptest.NewRecorder()
+
+		ctx := e.NewContext(req, rec)
+
+		// Set path param (id)
+		ctx.SetPath("/api/v1/webhook/:id")
+		ctx.SetParamNames("id")
+		ctx.SetParamValues(tc.id)
+
+		if assert.NoError(t, mockRequireAuth(handler.deleteWebhook)(ctx)) {
+			assert.Equal(t, tc.code, rec.Code)
+		}
+	}
+}
+
+func TestWebhookHandler_GetDeliveries(t *testing.T) {
+	e := echo.New()
+	handler := NewWebhookHandler(&mockWebhookService{}, mockRequireAuth)
+
+	g := e.Group("/api/v1/webhook")
+	handler.Routes(g)
+
+	// Create request
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/webhook/"+mockWebhook.ID.String()+"/deliveries", nil)
+	rec := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, rec)
+
+	// Set path param (id)
+	ctx.SetPath("/api/v1/webhook/:id/deliveries")
+	ctx.SetParamNames("id")
+	ctx.SetParamValues(mockWebhook.ID.String())
+
+	if assert.NoError(t, mockRequireAuth(handler.getDeliveries)(ctx)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"status":"failed"`)
+		assert.Contains(t, rec.Body.String(), `"payload":{"event":"like"}`)
+		assert.Contains(t, rec.Body.String(), `"error":"timeout"`)
+	}
+}
+
+func TestWebhookHandler_Redeliver(t *testing.T) {
+	e := echo.New()
+	handler := NewWebhookHandler(&mockWebhookService{}, mockRequireAuth)
+
+	g := e.Group("/api/v1/webhook")
+	handler.Routes(g)
+
+	for _, tc := range []struct {
+		id   string
+		code int
+	}{
+		{mockWebhookDelivery.ID.String(), http.StatusAccepted},
+		{uuid.NewString(), http.StatusNotFound},
+		{"not-uuid", http.StatusBadRequest},
+	} {
+		// Create request
+		path := "/api/v1/webhook/" + mockWebhook.ID.String() + "/deliveries/" + tc.id + "/redeliver"
+		req := httptest.NewRequest(http.MethodPost, path, nil)
+		rec := httptest.NewRecorder()
+
+		ctx := e.NewContext(req, rec)
+
+		// Set path params (id, delivery_id)
+		ctx.SetPath("/api/v1/webhook/:id/deliveries/:delivery_id/redeliver")
+		ctx.SetParamNames("id", "delivery_id")
+		ctx.SetParamValues(mockWebhook.ID.String(), tc.id)
+
+		if assert.NoError(t, mockRequireAuth(handler.redeliver)(ctx)) {
+			assert.Equal(t, tc.code, rec.Code)
+		}
+	}
+}
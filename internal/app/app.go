@@ -3,6 +3,7 @@ package app
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"log/slog"
 	"os"
 
@@ -16,12 +17,26 @@ import (
 	"github.com/misshanya/mitter/internal/config"
 	"github.com/misshanya/mitter/internal/db"
 	"github.com/misshanya/mitter/internal/db/sqlc/storage"
+	"github.com/misshanya/mitter/internal/jobs"
 	"github.com/misshanya/mitter/internal/metrics"
 	myMiddleware "github.com/misshanya/mitter/internal/middleware"
+	"github.com/misshanya/mitter/internal/models"
 	"github.com/misshanya/mitter/internal/repository"
 	"github.com/misshanya/mitter/internal/service/auth"
+	"github.com/misshanya/mitter/internal/service/bookmark"
+	"github.com/misshanya/mitter/internal/service/counter"
+	"github.com/misshanya/mitter/internal/service/export"
+	"github.com/misshanya/mitter/internal/service/hashtag"
+	"github.com/misshanya/mitter/internal/service/importer"
+	"github.com/misshanya/mitter/internal/service/message"
 	"github.com/misshanya/mitter/internal/service/mitt"
+	"github.com/misshanya/mitter/internal/service/notification"
+	"github.com/misshanya/mitter/internal/service/poll"
+	"github.com/misshanya/mitter/internal/service/search"
+	"github.com/misshanya/mitter/internal/service/stream"
+	"github.com/misshanya/mitter/internal/service/suggestion"
 	"github.com/misshanya/mitter/internal/service/user"
+	"github.com/misshanya/mitter/internal/service/webhook"
 	"github.com/redis/go-redis/v9"
 	echoSwagger "github.com/swaggo/echo-swagger"
 )
@@ -49,16 +64,7 @@ func (a *App) Start(ctx context.Context) {
 	}
 
 	// Init db connection
-	conn, err := initDB(ctx, a.cfg.Postgres.URL)
-	if err != nil {
-		slog.Error("failed to connect to database")
-		os.Exit(1)
-	}
-
-	if err := db.Migrate(sql.OpenDB(stdlib.GetConnector(*conn.Config().ConnConfig))); err != nil {
-		slog.Error("failed to migrate database", slog.Any("err", err))
-		os.Exit(1)
-	}
+	conn := a.connectDB(ctx)
 
 	// Init SQL queries
 	queries := storage.New(conn)
@@ -70,8 +76,9 @@ func (a *App) Start(ctx context.Context) {
 	if a.cfg.Mode == "DEV" {
 		slog.Info("[!] Running in DEV mode")
 		a.e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
-			AllowOrigins: []string{"*"},
-			AllowHeaders: []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept},
+			AllowOrigins:  []string{"*"},
+			AllowHeaders:  []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, "If-Match", "If-None-Match"},
+			ExposeHeaders: []string{"ETag"},
 		}))
 	}
 
@@ -85,40 +92,128 @@ func (a *App) Start(ctx context.Context) {
 	// Custom metrics
 	userMetrics := metrics.NewUserMetrics()
 	mittMetrics := metrics.NewMittMetrics()
+	streamMetrics := metrics.NewStreamMetrics()
 
 	apiGroup := a.e.Group("/api")
 	v1Group := apiGroup.Group("/v1")
 
 	// Repos
-	userRepo := repository.NewUserRepository(queries)
+	userRepo := repository.NewUserRepository(conn, queries)
 	authRepo := repository.NewAuthRepository(rdb)
-	mittRepo := repository.NewMittRepository(queries)
+	mittRepo := repository.NewMittRepository(conn, queries)
+	suggestionRepo := repository.NewSuggestionRepository(rdb)
+	counterRepo := repository.NewCounterRepository(conn, queries)
+	exportRepo := repository.NewExportRepository(conn, queries)
+	importRepo := repository.NewImportRepository(conn, queries)
+	hashtagRepo := repository.NewHashtagRepository(queries)
+	trendingRepo := repository.NewTrendingRepository(rdb, a.cfg.Hashtags.TrendingWindow, a.cfg.Hashtags.TrendingBucket)
+	notificationRepo := repository.NewNotificationRepository(conn, queries)
+	eventRepo := repository.NewEventRepository(rdb, a.cfg.Stream.ReplayLimit, a.cfg.Stream.ReplayTTL)
+	webhookRepo := repository.NewWebhookRepository(queries)
+	messageRepo := repository.NewMessageRepository(conn, queries)
+	searchRepo := repository.NewSearchRepository(queries)
+	autocompleteRepo := repository.NewAutocompleteRepository(rdb)
+	bookmarkRepo := repository.NewBookmarkRepository(conn, queries)
+	pollRepo := repository.NewPollRepository(queries)
+	draftRepo := repository.NewDraftRepository(conn, queries)
 
 	// Services
-	userService := user.NewUserService(userRepo, userMetrics)
-	authService := auth.NewAuthService(userRepo, authRepo, userMetrics)
-	mittService := mitt.NewService(mittRepo, mittMetrics, userRepo)
+	notificationService := notification.NewService(notificationRepo, userRepo)
+	streamService := stream.NewService(eventRepo, userRepo, streamMetrics, a.cfg.Stream.ReplayLimit)
+	webhookService := webhook.NewService(webhookRepo, a.cfg.Webhooks.Timeout, a.cfg.Webhooks.MaxAttempts, a.cfg.Webhooks.RetryBackoff, a.cfg.Webhooks.MaxRetryBackoff, a.cfg.Webhooks.AllowPrivateAddresses)
+	// Webhooks get the same events as notifications
+	notifier := models.Notifiers{notificationService, webhookService}
+	userService := user.NewUserService(userRepo, authRepo, userMetrics, notifier, streamService, a.cfg.Users.DeletionGracePeriod)
+	authService := auth.NewAuthService(userRepo, authRepo, userMetrics, a.cfg.Users.DeletionGracePeriod)
+	mittService := mitt.NewService(mittRepo, draftRepo, mittMetrics, userRepo, trendingRepo, notifier, streamService, mitt.Options{
+		EditWindow:      a.cfg.Mitts.EditWindow,
+		TrashRetention:  a.cfg.Mitts.TrashRetention,
+		MaxPollDuration: a.cfg.Polls.MaxDuration,
+		MaxPinned:       a.cfg.Mitts.MaxPinned,
+	})
+	// Suggestions live in cache for two refresh intervals, so they don't expire before the next refresh
+	suggestionService := suggestion.NewService(userRepo, suggestionRepo, a.cfg.Suggestions.Count, 2*a.cfg.Suggestions.RefreshInterval)
+	counterService := counter.NewService(counterRepo)
+	exportService := export.NewService(exportRepo, userRepo, mittRepo, a.cfg.Exports.LinkTTL)
+	importService := importer.NewService(importRepo, a.cfg.Mitts.MaxLength)
+	hashtagService := hashtag.NewService(hashtagRepo, trendingRepo)
+	messageService := message.NewService(messageRepo, userRepo, a.cfg.Messages.MaxMembers)
+	searchService := search.NewService(searchRepo, autocompleteRepo, userRepo, a.cfg.Search.AutocompleteCount, a.cfg.Search.AutocompleteTTL)
+	bookmarkService := bookmark.NewService(bookmarkRepo, mittRepo, a.cfg.Bookmarks.MaxFolders)
+	pollService := poll.NewService(pollRepo, mittRepo, notifier)
+	// Mitts returned by handlers are marked for current user
+	marker := models.MittMarkers{bookmarkService, pollService}
+
+	// Background jobs
+	go jobs.Every(ctx, "suggestions", a.cfg.Suggestions.RefreshInterval, suggestionService.Refresh)
+	go jobs.Every(ctx, "counters reconciliation", a.cfg.Counters.ReconcileInterval, counterService.Reconcile)
+	go jobs.Every(ctx, "trash purge", a.cfg.Mitts.PurgeInterval, mittService.PurgeTrash)
+	go jobs.Every(ctx, "deactivated users purge", a.cfg.Users.PurgeInterval, userService.PurgeDeactivated)
+	go jobs.Every(ctx, "exports", a.cfg.Exports.PollInterval, exportService.ProcessPending)
+	go jobs.Every(ctx, "expired exports purge", a.cfg.Exports.PurgeInterval, exportService.PurgeExpired)
+	go jobs.Every(ctx, "imports", a.cfg.Imports.PollInterval, importService.ProcessPending)
+	go jobs.Every(ctx, "trending hashtags", a.cfg.Hashtags.TrendingRefreshInterval, hashtagService.RefreshTrending)
+	go jobs.Every(ctx, "webhook deliveries", a.cfg.Webhooks.PollInterval, webhookService.ProcessPending)
+	go jobs.Every(ctx, "expired polls", a.cfg.Polls.CloseInterval, pollService.CloseExpired)
+	go jobs.Every(ctx, "scheduled mitts", a.cfg.Mitts.ScheduleInterval, mittService.PublishScheduled)
+	go jobs.Every(ctx, "expired mitts", a.cfg.Mitts.ExpireInterval, mittService.DeleteExpired)
 
 	// Middlewares
 	authMiddleware := myMiddleware.NewAuthMiddleware(authRepo)
+	dataLoaderMiddleware := myMiddleware.NewDataLoaderMiddleware(userRepo)
+
+	// Must be applied before creating subgroups, they copy middlewares on creation
+	v1Group.Use(dataLoaderMiddleware.AttachLoaders)
 
 	// Handlers
 	userHandler := handler.NewUserHandler(userService)
 	authHandler := handler.NewAuthHandler(authService, authMiddleware.RequireAuth)
-	mittHandler := handler.NewMittHandler(mittService, authMiddleware.RequireAuth)
+	mittHandler := handler.NewMittHandler(mittService, marker, authMiddleware.RequireAuth, authMiddleware.OptionalAuth, a.cfg.Mitts.MaxLength)
+	suggestionHandler := handler.NewSuggestionHandler(suggestionService)
+	exportHandler := handler.NewExportHandler(exportService, authMiddleware.RequireAuth)
+	importHandler := handler.NewImportHandler(importService, authMiddleware.RequireAuth, a.cfg.Imports.MaxSize)
+	hashtagHandler := handler.NewHashtagHandler(hashtagService, marker, authMiddleware.OptionalAuth)
+	notificationHandler := handler.NewNotificationHandler(notificationService, authMiddleware.RequireAuth)
+	streamHandler := handler.NewStreamHandler(streamService, authMiddleware.RequireAuth, a.cfg.Stream.HeartbeatInterval)
+	webhookHandler := handler.NewWebhookHandler(webhookService, authMiddleware.RequireAuth)
+	messageHandler := handler.NewMessageHandler(messageService, authMiddleware.RequireAuth, a.cfg.Messages.MaxLength)
+	searchHandler := handler.NewSearchHandler(searchService, marker, authMiddleware.RequireAuth)
+	bookmarkHandler := handler.NewBookmarkHandler(bookmarkService, authMiddleware.RequireAuth)
+	pollHandler := handler.NewPollHandler(pollService, marker, authMiddleware.RequireAuth)
+	draftHandler := handler.NewDraftHandler(mittService, authMiddleware.RequireAuth, a.cfg.Mitts.MaxLength)
 
 	// Groups
 	userGroup := v1Group.Group("/user")
 	authGroup := v1Group.Group("/auth")
 	mittGroup := v1Group.Group("/mitt")
+	exportGroup := v1Group.Group("/export")
+	notificationGroup := v1Group.Group("/notification")
+	streamGroup := v1Group.Group("/stream")
+	webhookGroup := v1Group.Group("/webhook")
+	conversationGroup := v1Group.Group("/conversation")
+	searchGroup := v1Group.Group("/search")
+	bookmarkGroup := v1Group.Group("/bookmark")
+	draftGroup := v1Group.Group("/draft")
 
 	// Apply middlewares
 	userGroup.Use(authMiddleware.RequireAuth)
 
 	// Connect handlers
 	userHandler.Routes(userGroup)
+	suggestionHandler.Routes(userGroup)
 	authHandler.Routes(authGroup)
 	mittHandler.Routes(mittGroup)
+	pollHandler.Routes(mittGroup)
+	importHandler.Routes(mittGroup)
+	exportHandler.Routes(exportGroup)
+	hashtagHandler.Routes(v1Group)
+	notificationHandler.Routes(notificationGroup)
+	streamHandler.Routes(streamGroup)
+	webhookHandler.Routes(webhookGroup)
+	messageHandler.Routes(conversationGroup)
+	searchHandler.Routes(searchGroup)
+	bookmarkHandler.Routes(bookmarkGroup)
+	draftHandler.Routes(draftGroup)
 
 	a.e.Logger.Fatal(a.e.Start(a.cfg.Server.Addr))
 }
@@ -127,6 +222,47 @@ func (a *App) Stop(ctx context.Context) error {
 	return a.e.Shutdown(ctx)
 }
 
+// ReconcileCounters recomputes denormalized counters once, without starting the server
+func (a *App) ReconcileCounters(ctx context.Context) error {
+	conn := a.connectDB(ctx)
+	defer conn.Close()
+
+	counterService := counter.NewService(repository.NewCounterRepository(conn, storage.New(conn)))
+	return counterService.Reconcile(ctx)
+}
+
+// ImportMitts imports archive of mitts for user with given login right away, without starting the server
+func (a *App) ImportMitts(ctx context.Context, login string, archive []byte, progress func(imp *models.Import)) (*models.Import, error) {
+	conn := a.connectDB(ctx)
+	defer conn.Close()
+
+	queries := storage.New(conn)
+
+	user, err := repository.NewUserRepository(conn, queries).GetUserByLogin(ctx, login)
+	if err != nil {
+		return nil, fmt.Errorf("getting user %q: %w", login, err)
+	}
+
+	importService := importer.NewService(repository.NewImportRepository(conn, queries), a.cfg.Mitts.MaxLength)
+	return importService.Import(ctx, user.ID, archive, progress)
+}
+
+// connectDB connects to database and applies migrations, exits on failure
+func (a *App) connectDB(ctx context.Context) *pgxpool.Pool {
+	conn, err := initDB(ctx, a.cfg.Postgres.URL)
+	if err != nil {
+		slog.Error("failed to connect to database")
+		os.Exit(1)
+	}
+
+	if err := db.Migrate(sql.OpenDB(stdlib.GetConnector(*conn.Config().ConnConfig))); err != nil {
+		slog.Error("failed to migrate database", slog.Any("err", err))
+		os.Exit(1)
+	}
+
+	return conn
+}
+
 func initDB(ctx context.Context, dbURL string) (*pgxpool.Pool, error) {
 	pool, err := pgxpool.New(ctx, dbURL)
 	if err != nil {
@@ -1,16 +1,35 @@
 package config
 
 import (
+	"fmt"
 	"github.com/ilyakaznacheev/cleanenv"
 	"log/slog"
 	"os"
+	"time"
 )
 
+// Max mitt and draft length in characters allowed by db check constraint (mitt_content_valid)
+const MaxStoredContentLength = 10000
+
 type Config struct {
 	Server   server   `env:"SERVER" env-required:"true"`
 	Redis    redis    `env:"REDIS" env-required:"true"`
 	Postgres postgres `env:"POSTGRES" env-required:"true"`
 	Mode     string   `env:"MODE" env-default:"PROD"`
+
+	Suggestions suggestions `env:"SUGGESTIONS"`
+	Counters    counters    `env:"COUNTERS"`
+	Mitts       mitts       `env:"MITTS"`
+	Users       users       `env:"USERS"`
+	Exports     exports     `env:"EXPORTS"`
+	Imports     imports     `env:"IMPORTS"`
+	Hashtags    hashtags    `env:"HASHTAGS"`
+	Stream      stream      `env:"STREAM"`
+	Webhooks    webhooks    `env:"WEBHOOKS"`
+	Messages    messages    `env:"MESSAGES"`
+	Search      search      `env:"SEARCH"`
+	Bookmarks   bookmarks   `env:"BOOKMARKS"`
+	Polls       polls       `env:"POLLS"`
 }
 
 type server struct {
@@ -27,6 +46,108 @@ type postgres struct {
 	URL string `env:"PG_URL" env-required:"true"`
 }
 
+type suggestions struct {
+	Count           int32         `env:"SUGGESTIONS_COUNT" env-default:"30"`
+	RefreshInterval time.Duration `env:"SUGGESTIONS_REFRESH_INTERVAL" env-default:"1h"`
+}
+
+type counters struct {
+	ReconcileInterval time.Duration `env:"COUNTERS_RECONCILE_INTERVAL" env-default:"24h"`
+}
+
+type mitts struct {
+	// Max mitt length in user-perceived characters (grapheme clusters)
+	MaxLength int `env:"MITTS_MAX_LENGTH" env-default:"280"`
+	// How long after creation mitt can be edited, zero means forever
+	EditWindow time.Duration `env:"MITTS_EDIT_WINDOW" env-default:"0"`
+	// How long deleted mitts can be restored before they are purged
+	TrashRetention time.Duration `env:"MITTS_TRASH_RETENTION" env-default:"720h"`
+	PurgeInterval  time.Duration `env:"MITTS_PURGE_INTERVAL" env-default:"1h"`
+	// How often due scheduled drafts are published
+	ScheduleInterval time.Duration `env:"MITTS_SCHEDULE_INTERVAL" env-default:"30s"`
+	// How often expired mitts are deleted, they are hidden right away
+	ExpireInterval time.Duration `env:"MITTS_EXPIRE_INTERVAL" env-default:"1m"`
+	// How many mitts user can pin to profile
+	MaxPinned int `env:"MITTS_MAX_PINNED" env-default:"3"`
+}
+
+type users struct {
+	// How long deleted account can be reactivated before its data is purged
+	DeletionGracePeriod time.Duration `env:"USERS_DELETION_GRACE_PERIOD" env-default:"720h"`
+	PurgeInterval       time.Duration `env:"USERS_PURGE_INTERVAL" env-default:"1h"`
+}
+
+type exports struct {
+	// How often queued exports are picked up
+	PollInterval time.Duration `env:"EXPORTS_POLL_INTERVAL" env-default:"10s"`
+	// How long download link works, expired exports are deleted
+	LinkTTL       time.Duration `env:"EXPORTS_LINK_TTL" env-default:"24h"`
+	PurgeInterval time.Duration `env:"EXPORTS_PURGE_INTERVAL" env-default:"1h"`
+}
+
+type imports struct {
+	// Max archive size in bytes
+	MaxSize int64 `env:"IMPORTS_MAX_SIZE" env-default:"10485760"`
+	// How often queued imports are picked up
+	PollInterval time.Duration `env:"IMPORTS_POLL_INTERVAL" env-default:"10s"`
+}
+
+type hashtags struct {
+	// Trending hashtags compare uses in the last window with the window before it
+	TrendingWindow time.Duration `env:"HASHTAGS_TRENDING_WINDOW" env-default:"1h"`
+	// Uses are counted per bucket, so window slides by this step
+	TrendingBucket          time.Duration `env:"HASHTAGS_TRENDING_BUCKET" env-default:"5m"`
+	TrendingRefreshInterval time.Duration `env:"HASHTAGS_TRENDING_REFRESH_INTERVAL" env-default:"1m"`
+}
+
+type stream struct {
+	// How often comments are sent to idle connections, so proxies don't close them
+	HeartbeatInterval time.Duration `env:"STREAM_HEARTBEAT_INTERVAL" env-default:"15s"`
+	// How many latest events per channel are kept for clients that reconnect with Last-Event-ID
+	ReplayLimit int64 `env:"STREAM_REPLAY_LIMIT" env-default:"100"`
+	// Events of channel are dropped when it has no new events for this long
+	ReplayTTL time.Duration `env:"STREAM_REPLAY_TTL" env-default:"1h"`
+}
+
+type webhooks struct {
+	// How often due deliveries are picked up
+	PollInterval time.Duration `env:"WEBHOOKS_POLL_INTERVAL" env-default:"5s"`
+	// How long to wait for response of receiver
+	Timeout     time.Duration `env:"WEBHOOKS_TIMEOUT" env-default:"10s"`
+	MaxAttempts int32         `env:"WEBHOOKS_MAX_ATTEMPTS" env-default:"8"`
+	// Delay before the first retry, it doubles after every failed attempt up to MaxRetryBackoff
+	RetryBackoff    time.Duration `env:"WEBHOOKS_RETRY_BACKOFF" env-default:"30s"`
+	MaxRetryBackoff time.Duration `env:"WEBHOOKS_MAX_RETRY_BACKOFF" env-default:"6h"`
+	// Lets webhooks reach loopback, private and link-local addresses, only for local development
+	AllowPrivateAddresses bool `env:"WEBHOOKS_ALLOW_PRIVATE_ADDRESSES" env-default:"false"`
+}
+
+type messages struct {
+	// Max message length in user-perceived characters (grapheme clusters)
+	MaxLength int `env:"MESSAGES_MAX_LENGTH" env-default:"1000"`
+	// Max members of group conversation including its creator
+	MaxMembers int `env:"MESSAGES_MAX_MEMBERS" env-default:"10"`
+}
+
+type search struct {
+	// How many users are suggested for login prefix
+	AutocompleteCount int32 `env:"SEARCH_AUTOCOMPLETE_COUNT" env-default:"10"`
+	// How long suggestions for login prefix are cached
+	AutocompleteTTL time.Duration `env:"SEARCH_AUTOCOMPLETE_TTL" env-default:"1m"`
+}
+
+type bookmarks struct {
+	// Max bookmark folders of user
+	MaxFolders int64 `env:"BOOKMARKS_MAX_FOLDERS" env-default:"100"`
+}
+
+type polls struct {
+	// Max time between creation and expiry of poll
+	MaxDuration time.Duration `env:"POLLS_MAX_DURATION" env-default:"168h"`
+	// How often expired polls are closed and their authors notified
+	CloseInterval time.Duration `env:"POLLS_CLOSE_INTERVAL" env-default:"1m"`
+}
+
 func NewConfig() *Config {
 	var cfg Config
 
@@ -36,5 +157,18 @@ func NewConfig() *Config {
 		os.Exit(1)
 	}
 
+	if err := cfg.validate(); err != nil {
+		slog.Error("invalid config", slog.Any("err", err))
+		os.Exit(1)
+	}
+
 	return &cfg
 }
+
+// validate checks settings which must agree with db schema
+func (cfg *Config) validate() error {
+	if cfg.Mitts.MaxLength < 1 || cfg.Mitts.MaxLength > MaxStoredContentLength {
+		return fmt.Errorf("MITTS_MAX_LENGTH must be between 1 and %d, got %d", MaxStoredContentLength, cfg.Mitts.MaxLength)
+	}
+	return nil
+}
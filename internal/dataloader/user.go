@@ -0,0 +1,94 @@
+package dataloader
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/misshanya/mitter/internal/models"
+)
+
+type userRepo interface {
+	GetUsersByIDs(ctx context.Context, ids []uuid.UUID) ([]*models.User, error)
+}
+
+// UserLoader loads users in batches and remembers them for the rest of the request
+type UserLoader struct {
+	repo userRepo
+
+	mu    sync.Mutex
+	cache map[uuid.UUID]*models.User
+}
+
+func NewUserLoader(repo userRepo) *UserLoader {
+	return &UserLoader{
+		repo:  repo,
+		cache: make(map[uuid.UUID]*models.User),
+	}
+}
+
+// LoadMany returns users in the order of ids, skipping users that don't exist.
+// Only users that weren't loaded before are queried, with a single query
+func (l *UserLoader) LoadMany(ctx context.Context, ids []uuid.UUID) ([]*models.User, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	missing := make([]uuid.UUID, 0, len(ids))
+	seen := make(map[uuid.UUID]struct{}, len(ids))
+	for _, id := range ids {
+		if _, ok := l.cache[id]; ok {
+			continue
+		}
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		missing = append(missing, id)
+	}
+
+	if len(missing) > 0 {
+		users, err := l.repo.GetUsersByIDs(ctx, missing)
+		if err != nil {
+			return nil, err
+		}
+		for _, user := range users {
+			l.cache[user.ID] = user
+		}
+	}
+
+	users := make([]*models.User, 0, len(ids))
+	for _, id := range ids {
+		if user, ok := l.cache[id]; ok {
+			users = append(users, user)
+		}
+	}
+
+	return users, nil
+}
+
+// Load returns one user or nil if user doesn't exist
+func (l *UserLoader) Load(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	users, err := l.LoadMany(ctx, []uuid.UUID{id})
+	if err != nil {
+		return nil, err
+	}
+	if len(users) == 0 {
+		return nil, nil
+	}
+	return users[0], nil
+}
+
+type userLoaderKey struct{}
+
+// WithUserLoader attaches loader to ctx
+func WithUserLoader(ctx context.Context, l *UserLoader) context.Context {
+	return context.WithValue(ctx, userLoaderKey{}, l)
+}
+
+// Users returns loader attached to ctx or a new one (that lives only while it is used) if there is none
+func Users(ctx context.Context, repo userRepo) *UserLoader {
+	if l, ok := ctx.Value(userLoaderKey{}).(*UserLoader); ok {
+		return l
+	}
+	return NewUserLoader(repo)
+}
@@ -0,0 +1,73 @@
+package dataloader
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/misshanya/mitter/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	testUserID  = uuid.MustParse("b096376a-5fa9-4130-907a-709c67008a65")
+	testUser2ID = uuid.MustParse("38386ffe-54ac-48be-9244-a5144b41a014")
+)
+
+// Mock repo that counts queries
+type mockUserRepo struct {
+	queries int
+}
+
+func (r *mockUserRepo) GetUsersByIDs(ctx context.Context, ids []uuid.UUID) ([]*models.User, error) {
+	_ = ctx
+	r.queries++
+
+	users := make([]*models.User, 0, len(ids))
+	for _, id := range ids {
+		// testUser2 doesn't exist
+		if id == testUser2ID {
+			continue
+		}
+		users = append(users, &models.User{ID: id})
+	}
+	return users, nil
+}
+
+// Tests
+func TestUserLoader_LoadMany(t *testing.T) {
+	repo := &mockUserRepo{}
+	loader := NewUserLoader(repo)
+	ctx := context.Background()
+
+	users, err := loader.LoadMany(ctx, []uuid.UUID{testUserID, testUser2ID, testUserID})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Missing users are skipped, duplicates are kept in order
+	if assert.Len(t, users, 2) {
+		assert.Equal(t, testUserID, users[0].ID)
+		assert.Equal(t, testUserID, users[1].ID)
+	}
+	assert.Equal(t, 1, repo.queries)
+
+	// Cached user is not queried again
+	user, err := loader.Load(ctx, testUserID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, testUserID, user.ID)
+	assert.Equal(t, 1, repo.queries)
+}
+
+func TestUsers(t *testing.T) {
+	repo := &mockUserRepo{}
+	loader := NewUserLoader(repo)
+
+	ctx := WithUserLoader(context.Background(), loader)
+	assert.Same(t, loader, Users(ctx, repo))
+
+	// Without loader in ctx a new one is created
+	assert.NotSame(t, loader, Users(context.Background(), repo))
+}
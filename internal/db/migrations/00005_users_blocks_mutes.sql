@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS users_blocks (
+    id UUID NOT NULL DEFAULT gen_random_uuid() PRIMARY KEY,
+    blocker_id UUID NOT NULL REFERENCES users(id),
+    blocked_id UUID NOT NULL REFERENCES users(id),
+    UNIQUE(blocker_id, blocked_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_users_blocks_blocked_id ON users_blocks(blocked_id);
+
+CREATE TABLE IF NOT EXISTS users_mutes (
+    id UUID NOT NULL DEFAULT gen_random_uuid() PRIMARY KEY,
+    muter_id UUID NOT NULL REFERENCES users(id),
+    muted_id UUID NOT NULL REFERENCES users(id),
+    UNIQUE(muter_id, muted_id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS users_mutes;
+
+DROP INDEX IF EXISTS idx_users_blocks_blocked_id;
+
+DROP TABLE IF EXISTS users_blocks;
+-- +goose StatementEnd
@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE mitts ADD COLUMN IF NOT EXISTS likes_count BIGINT NOT NULL DEFAULT 0;
+
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS followers_count BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS following_count BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS mitts_count BIGINT NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_mitts_likes_mitt_id ON mitts_likes(mitt_id);
+CREATE INDEX IF NOT EXISTS idx_mitts_author ON mitts(author);
+
+-- Fill counters for existing data
+UPDATE mitts m
+SET likes_count = (SELECT COUNT(*) FROM mitts_likes ml WHERE ml.mitt_id = m.id);
+
+UPDATE users u
+SET
+    followers_count = (SELECT COUNT(*) FROM users_follows uf WHERE uf.followee_id = u.id),
+    following_count = (SELECT COUNT(*) FROM users_follows uf WHERE uf.follower_id = u.id),
+    mitts_count = (SELECT COUNT(*) FROM mitts m WHERE m.author = u.id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_mitts_author;
+DROP INDEX IF EXISTS idx_mitts_likes_mitt_id;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS followers_count,
+    DROP COLUMN IF EXISTS following_count,
+    DROP COLUMN IF EXISTS mitts_count;
+
+ALTER TABLE mitts DROP COLUMN IF EXISTS likes_count;
+-- +goose StatementEnd
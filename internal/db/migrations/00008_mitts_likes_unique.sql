@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Keep only the earliest like of every user for every mitt
+DELETE FROM mitts_likes
+WHERE id IN (
+    SELECT id
+    FROM (
+        SELECT id, ROW_NUMBER() OVER (
+            PARTITION BY user_id, mitt_id
+            ORDER BY liked_at NULLS LAST, id
+        ) AS rn
+        FROM mitts_likes
+    ) d
+    WHERE d.rn > 1
+);
+
+ALTER TABLE mitts_likes ADD CONSTRAINT mitts_likes_user_id_mitt_id_key UNIQUE (user_id, mitt_id);
+
+-- Duplicates were counted too
+UPDATE mitts m
+SET likes_count = (SELECT COUNT(*) FROM mitts_likes ml WHERE ml.mitt_id = m.id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE mitts_likes DROP CONSTRAINT IF EXISTS mitts_likes_user_id_mitt_id_key;
+-- +goose StatementEnd
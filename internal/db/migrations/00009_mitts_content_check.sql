@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Backstop for application validation, real limit is configurable and counted in graphemes.
+-- NOT VALID skips checking existing rows
+ALTER TABLE mitts ADD CONSTRAINT mitts_content_check CHECK (
+    char_length(content) <= 10000 AND
+    btrim(content, E' \t\n\r') <> ''
+) NOT VALID;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE mitts DROP CONSTRAINT IF EXISTS mitts_content_check;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS mitt_revisions (
+    id UUID NOT NULL DEFAULT gen_random_uuid() PRIMARY KEY,
+    mitt_id UUID NOT NULL REFERENCES mitts(id) ON DELETE CASCADE,
+    content TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_mitt_revisions_mitt_id ON mitt_revisions(mitt_id, created_at);
+
+ALTER TABLE mitts ADD COLUMN IF NOT EXISTS revisions_count INT NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE mitts DROP COLUMN IF EXISTS revisions_count;
+
+DROP TABLE IF EXISTS mitt_revisions;
+-- +goose StatementEnd
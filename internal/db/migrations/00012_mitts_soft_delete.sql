@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE mitts ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_mitts_deleted_at ON mitts(deleted_at) WHERE deleted_at IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_mitts_deleted_at;
+
+-- Soft deleted mitts would become visible again
+DELETE FROM mitts WHERE deleted_at IS NOT NULL;
+
+ALTER TABLE mitts DROP COLUMN IF EXISTS deleted_at;
+-- +goose StatementEnd
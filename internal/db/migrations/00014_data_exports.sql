@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS data_exports (
+    id UUID NOT NULL DEFAULT gen_random_uuid() PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'running', 'done', 'failed')),
+    error TEXT,
+    download_token UUID UNIQUE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    started_at TIMESTAMP,
+    finished_at TIMESTAMP,
+    expires_at TIMESTAMP
+);
+
+-- Only one unfinished export per user
+CREATE UNIQUE INDEX IF NOT EXISTS idx_data_exports_user_id_active ON data_exports(user_id) WHERE status IN ('pending', 'running');
+CREATE INDEX IF NOT EXISTS idx_data_exports_created_at_pending ON data_exports(created_at) WHERE status IN ('pending', 'running');
+CREATE INDEX IF NOT EXISTS idx_data_exports_expires_at ON data_exports(expires_at);
+
+-- Archives are kept apart, so exports can be listed without loading them
+CREATE TABLE IF NOT EXISTS data_export_archives (
+    export_id UUID NOT NULL PRIMARY KEY REFERENCES data_exports(id) ON DELETE CASCADE,
+    archive BYTEA NOT NULL
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS data_export_archives;
+DROP TABLE IF EXISTS data_exports;
+-- +goose StatementEnd
@@ -0,0 +1,50 @@
+-- +goose Up
+-- +goose StatementBegin
+-- ID of post in the source it was imported from
+ALTER TABLE mitts ADD COLUMN IF NOT EXISTS external_id TEXT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_mitts_author_external_id ON mitts(author, external_id) WHERE external_id IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS mitt_imports (
+    id UUID NOT NULL DEFAULT gen_random_uuid() PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'running', 'done', 'failed')),
+    error TEXT,
+    total INT NOT NULL,
+    processed INT NOT NULL DEFAULT 0,
+    imported INT NOT NULL DEFAULT 0,
+    skipped INT NOT NULL DEFAULT 0,
+    failed INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_mitt_imports_created_at_pending ON mitt_imports(created_at) WHERE status IN ('pending', 'running');
+
+-- Parsed archive, deleted when import is finished
+CREATE TABLE IF NOT EXISTS mitt_import_items (
+    import_id UUID NOT NULL PRIMARY KEY REFERENCES mitt_imports(id) ON DELETE CASCADE,
+    items JSONB NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS mitt_import_errors (
+    id UUID NOT NULL DEFAULT gen_random_uuid() PRIMARY KEY,
+    import_id UUID NOT NULL REFERENCES mitt_imports(id) ON DELETE CASCADE,
+    item_index INT NOT NULL,
+    external_id TEXT NOT NULL DEFAULT '',
+    error TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_mitt_import_errors_import_id ON mitt_import_errors(import_id, item_index);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS mitt_import_errors;
+DROP TABLE IF EXISTS mitt_import_items;
+DROP TABLE IF EXISTS mitt_imports;
+
+DROP INDEX IF EXISTS idx_mitts_author_external_id;
+ALTER TABLE mitts DROP COLUMN IF EXISTS external_id;
+-- +goose StatementEnd
@@ -0,0 +1,39 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Names are stored lowercase without '#'
+CREATE TABLE IF NOT EXISTS hashtags (
+    id UUID NOT NULL DEFAULT gen_random_uuid() PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE CHECK (char_length(name) BETWEEN 1 AND 100),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS mitt_hashtags (
+    mitt_id UUID NOT NULL REFERENCES mitts(id) ON DELETE CASCADE,
+    hashtag_id UUID NOT NULL REFERENCES hashtags(id) ON DELETE CASCADE,
+    PRIMARY KEY (mitt_id, hashtag_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_mitt_hashtags_hashtag_id ON mitt_hashtags(hashtag_id);
+
+-- Tag existing mitts. The pattern is close to pkg/content, new mitts are tagged by the app
+CREATE TEMPORARY TABLE existing_mitt_hashtags ON COMMIT DROP AS
+SELECT DISTINCT m.id AS mitt_id, lower(t[1]) AS name
+FROM mitts m, regexp_matches(m.content, '(?:^|[^[:alnum:]_&#])#([[:alnum:]_]{1,100})(?![[:alnum:]_])', 'g') AS t
+WHERE t[1] ~ '[[:alpha:]]';
+
+INSERT INTO hashtags (name)
+SELECT DISTINCT name FROM existing_mitt_hashtags
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO mitt_hashtags (mitt_id, hashtag_id)
+SELECT e.mitt_id, h.id
+FROM existing_mitt_hashtags e
+JOIN hashtags h ON h.name = e.name
+ON CONFLICT DO NOTHING;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS mitt_hashtags;
+DROP TABLE IF EXISTS hashtags;
+-- +goose StatementEnd
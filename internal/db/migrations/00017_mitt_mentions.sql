@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS mitt_mentions (
+    mitt_id UUID NOT NULL REFERENCES mitts(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    PRIMARY KEY (mitt_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_mitt_mentions_user_id ON mitt_mentions(user_id);
+
+-- Resolve mentions in existing mitts. The pattern is close to pkg/content, new mitts are resolved by the app
+INSERT INTO mitt_mentions (mitt_id, user_id)
+SELECT DISTINCT m.id, u.id
+FROM mitts m
+CROSS JOIN LATERAL regexp_matches(m.content, '(?:^|[^[:alnum:]_&@])@([[:alnum:]_]+)', 'g') AS t
+JOIN users u ON u.login = t[1]
+ON CONFLICT DO NOTHING;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS mitt_mentions;
+-- +goose StatementEnd
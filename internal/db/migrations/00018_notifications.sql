@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS notifications (
+    id UUID NOT NULL DEFAULT gen_random_uuid() PRIMARY KEY,
+    -- Recipient
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    -- Who did it
+    actor_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type TEXT NOT NULL CHECK (type IN ('like', 'follow', 'mention')),
+    mitt_id UUID REFERENCES mitts(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    read_at TIMESTAMP
+);
+
+-- Liking again or following again doesn't notify twice
+CREATE UNIQUE INDEX IF NOT EXISTS idx_notifications_unique ON notifications(user_id, type, actor_id, mitt_id) NULLS NOT DISTINCT;
+CREATE INDEX IF NOT EXISTS idx_notifications_user_id_created_at ON notifications(user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_notifications_user_id_unread ON notifications(user_id) WHERE read_at IS NULL;
+
+-- Types without a row are enabled
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type TEXT NOT NULL CHECK (type IN ('like', 'follow', 'mention')),
+    enabled BOOLEAN NOT NULL,
+    PRIMARY KEY (user_id, type)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS notification_preferences;
+DROP TABLE IF EXISTS notifications;
+-- +goose StatementEnd
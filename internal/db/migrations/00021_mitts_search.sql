@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Mitts are in different languages, so words are indexed as is, without stemming.
+-- Search queries must use the same expression to hit the index
+CREATE INDEX IF NOT EXISTS idx_mitts_search ON mitts USING GIN (to_tsvector('simple'::regconfig, content)) WHERE deleted_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_mitts_search;
+-- +goose StatementEnd
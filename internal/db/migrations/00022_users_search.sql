@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Similarity search, pg_trgm ignores case
+CREATE INDEX IF NOT EXISTS idx_users_login_trgm ON users USING GIN (login gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_users_name_trgm ON users USING GIN (name gin_trgm_ops);
+-- Prefix autocomplete
+CREATE INDEX IF NOT EXISTS idx_users_login_prefix ON users (lower(login) text_pattern_ops);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_users_login_prefix;
+DROP INDEX IF EXISTS idx_users_name_trgm;
+DROP INDEX IF EXISTS idx_users_login_trgm;
+-- +goose StatementEnd
@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS bookmark_folders (
+    id UUID NOT NULL DEFAULT gen_random_uuid() PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL CHECK (length(btrim(name)) > 0),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Folder names are unique per user regardless of case
+CREATE UNIQUE INDEX IF NOT EXISTS idx_bookmark_folders_user_id_name ON bookmark_folders(user_id, lower(name));
+
+CREATE TABLE IF NOT EXISTS bookmarks (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    mitt_id UUID NOT NULL REFERENCES mitts(id) ON DELETE CASCADE,
+    -- NULL if bookmark isn't in any folder, deleting folder keeps its bookmarks
+    folder_id UUID REFERENCES bookmark_folders(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT clock_timestamp(),
+    PRIMARY KEY (user_id, mitt_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_bookmarks_user_id_created_at ON bookmarks(user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_bookmarks_folder_id ON bookmarks(folder_id) WHERE folder_id IS NOT NULL;
+-- Purging mitts deletes their bookmarks
+CREATE INDEX IF NOT EXISTS idx_bookmarks_mitt_id ON bookmarks(mitt_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS bookmarks;
+DROP TABLE IF EXISTS bookmark_folders;
+-- +goose StatementEnd
@@ -0,0 +1,67 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS polls (
+    mitt_id UUID NOT NULL PRIMARY KEY REFERENCES mitts(id) ON DELETE CASCADE,
+    -- Options are numbered by their position starting from 0
+    options TEXT[] NOT NULL CHECK (cardinality(options) BETWEEN 2 AND 4),
+    multiple_choice BOOLEAN NOT NULL DEFAULT FALSE,
+    expires_at TIMESTAMP NOT NULL,
+    -- Set by job once poll expires and its author is notified
+    closed_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_polls_expires_at ON polls(expires_at) WHERE closed_at IS NULL;
+
+-- One vote per user, it can't be changed
+CREATE TABLE IF NOT EXISTS poll_votes (
+    mitt_id UUID NOT NULL REFERENCES polls(mitt_id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    -- Positions of chosen options
+    choices SMALLINT[] NOT NULL CHECK (cardinality(choices) > 0),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (mitt_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_poll_votes_user_id ON poll_votes(user_id);
+
+ALTER TABLE notifications DROP CONSTRAINT IF EXISTS notifications_type_check;
+ALTER TABLE notifications ADD CONSTRAINT notifications_type_check CHECK (
+    type IN ('like', 'follow', 'mention', 'poll_closed')
+);
+
+ALTER TABLE notification_preferences DROP CONSTRAINT IF EXISTS notification_preferences_type_check;
+ALTER TABLE notification_preferences ADD CONSTRAINT notification_preferences_type_check CHECK (
+    type IN ('like', 'follow', 'mention', 'poll_closed')
+);
+
+ALTER TABLE webhooks DROP CONSTRAINT IF EXISTS webhooks_event_types_check;
+ALTER TABLE webhooks ADD CONSTRAINT webhooks_event_types_check CHECK (
+    cardinality(event_types) > 0 AND event_types <@ ARRAY['like', 'follow', 'mention', 'poll_closed']
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM notifications WHERE type = 'poll_closed';
+DELETE FROM notification_preferences WHERE type = 'poll_closed';
+UPDATE webhooks SET event_types = array_remove(event_types, 'poll_closed');
+DELETE FROM webhooks WHERE cardinality(event_types) = 0;
+
+ALTER TABLE notifications DROP CONSTRAINT IF EXISTS notifications_type_check;
+ALTER TABLE notifications ADD CONSTRAINT notifications_type_check CHECK (
+    type IN ('like', 'follow', 'mention')
+);
+
+ALTER TABLE notification_preferences DROP CONSTRAINT IF EXISTS notification_preferences_type_check;
+ALTER TABLE notification_preferences ADD CONSTRAINT notification_preferences_type_check CHECK (
+    type IN ('like', 'follow', 'mention')
+);
+
+ALTER TABLE webhooks DROP CONSTRAINT IF EXISTS webhooks_event_types_check;
+ALTER TABLE webhooks ADD CONSTRAINT webhooks_event_types_check CHECK (
+    cardinality(event_types) > 0 AND event_types <@ ARRAY['like', 'follow', 'mention']
+);
+
+DROP TABLE IF EXISTS poll_votes;
+DROP TABLE IF EXISTS polls;
+-- +goose StatementEnd
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Drafts are private and become mitts once published, scheduled drafts have publish_at
+CREATE TABLE IF NOT EXISTS mitt_drafts (
+    id UUID NOT NULL DEFAULT gen_random_uuid() PRIMARY KEY,
+    author UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    content TEXT NOT NULL CHECK (
+        char_length(content) <= 10000 AND
+        btrim(content, E' \t\n\r') <> ''
+    ),
+    publish_at TIMESTAMP,
+    -- Scheduler publishing the draft holds it until then, so other instances skip it
+    claimed_until TIMESTAMP,
+    version INT NOT NULL DEFAULT 1,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_mitt_drafts_author ON mitt_drafts(author, updated_at DESC);
+CREATE INDEX IF NOT EXISTS idx_mitt_drafts_publish_at ON mitt_drafts(publish_at) WHERE publish_at IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS mitt_drafts;
+-- +goose StatementEnd
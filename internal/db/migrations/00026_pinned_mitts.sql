@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Users pin their own mitts to profile, pin is removed once mitt goes to trash
+CREATE TABLE IF NOT EXISTS pinned_mitts (
+    mitt_id UUID NOT NULL PRIMARY KEY REFERENCES mitts(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    pinned_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_pinned_mitts_user_id ON pinned_mitts(user_id, pinned_at DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS pinned_mitts;
+-- +goose StatementEnd
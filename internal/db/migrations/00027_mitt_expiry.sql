@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Expired mitts are hidden right away and deleted by job later
+ALTER TABLE mitts ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_mitts_expires_at ON mitts(expires_at) WHERE expires_at IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_mitts_expires_at;
+ALTER TABLE mitts DROP COLUMN IF EXISTS expires_at;
+-- +goose StatementEnd
@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Mitts and drafts share content limits, so they are defined once.
+-- Max length must stay in sync with config.MaxStoredContentLength
+CREATE OR REPLACE FUNCTION mitt_content_valid(content TEXT) RETURNS BOOLEAN
+LANGUAGE SQL IMMUTABLE AS $$
+    SELECT char_length(content) <= 10000 AND btrim(content, E' \t\n\r') <> ''
+$$;
+
+ALTER TABLE mitts DROP CONSTRAINT IF EXISTS mitts_content_check;
+ALTER TABLE mitts ADD CONSTRAINT mitts_content_check CHECK (mitt_content_valid(content)) NOT VALID;
+
+ALTER TABLE mitt_drafts DROP CONSTRAINT IF EXISTS mitt_drafts_content_check;
+ALTER TABLE mitt_drafts ADD CONSTRAINT mitt_drafts_content_check CHECK (mitt_content_valid(content));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE mitt_drafts DROP CONSTRAINT IF EXISTS mitt_drafts_content_check;
+ALTER TABLE mitt_drafts ADD CONSTRAINT mitt_drafts_content_check CHECK (
+    char_length(content) <= 10000 AND
+    btrim(content, E' \t\n\r') <> ''
+);
+
+ALTER TABLE mitts DROP CONSTRAINT IF EXISTS mitts_content_check;
+ALTER TABLE mitts ADD CONSTRAINT mitts_content_check CHECK (
+    char_length(content) <= 10000 AND
+    btrim(content, E' \t\n\r') <> ''
+) NOT VALID;
+
+DROP FUNCTION IF EXISTS mitt_content_valid(TEXT);
+-- +goose StatementEnd
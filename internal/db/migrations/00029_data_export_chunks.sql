@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Archives are written and read in chunks, so neither side holds the whole archive in memory
+CREATE TABLE IF NOT EXISTS data_export_chunks (
+    export_id UUID NOT NULL REFERENCES data_exports(id) ON DELETE CASCADE,
+    seq INT NOT NULL,
+    data BYTEA NOT NULL,
+    PRIMARY KEY (export_id, seq)
+);
+
+INSERT INTO data_export_chunks (export_id, seq, data)
+SELECT export_id, 0, archive FROM data_export_archives;
+
+DROP TABLE IF EXISTS data_export_archives;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS data_export_archives (
+    export_id UUID NOT NULL PRIMARY KEY REFERENCES data_exports(id) ON DELETE CASCADE,
+    archive BYTEA NOT NULL
+);
+
+INSERT INTO data_export_archives (export_id, archive)
+SELECT export_id, string_agg(data, ''::bytea ORDER BY seq)
+FROM data_export_chunks
+GROUP BY export_id;
+
+DROP TABLE IF EXISTS data_export_chunks;
+-- +goose StatementEnd
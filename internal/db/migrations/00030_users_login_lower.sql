@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Mentions are matched case-insensitively, so logins differing only in case can't coexist.
+-- Of such accounts the first one (active before deactivated, then by login and id) keeps its login,
+-- others get a suffix from their id: "Alice" becomes "Alice-1f0e9c2a". The result is still a valid login of at most 50 characters
+UPDATE users u
+SET login = left(u.login, 41) || '-' || left(replace(u.id::text, '-', ''), 8)
+FROM (
+    SELECT id, row_number() OVER (
+        PARTITION BY lower(login)
+        ORDER BY deactivated_at IS NOT NULL, login COLLATE "C", id
+    ) AS n
+    FROM users
+) d
+WHERE d.id = u.id AND d.n > 1;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_login_lower ON users (lower(login));
+
+-- Resolve mentions again like pkg/content does now: logins may have '.' and '-' inside and are matched case-insensitively
+DELETE FROM mitt_mentions;
+
+INSERT INTO mitt_mentions (mitt_id, user_id)
+SELECT DISTINCT m.id, u.id
+FROM mitts m
+CROSS JOIN LATERAL regexp_matches(m.content, '(?:^|[^[:alnum:]_&@])@([[:alnum:]_](?:[[:alnum:]_.-]*[[:alnum:]_])?)', 'g') AS t
+JOIN users u ON lower(u.login) = lower(t[1])
+ON CONFLICT DO NOTHING;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_users_login_lower;
+-- +goose StatementEnd
@@ -0,0 +1,74 @@
+-- name: AddBookmark :execrows
+INSERT INTO bookmarks (
+    user_id, mitt_id, folder_id
+)
+SELECT @user_id, m.id, sqlc.narg('folder_id')::uuid
+FROM mitts m
+JOIN users u ON u.id = m.author
+WHERE m.id = @mitt_id AND m.deleted_at IS NULL AND (m.expires_at IS NULL OR m.expires_at > NOW()) AND u.deactivated_at IS NULL
+ON CONFLICT (user_id, mitt_id) DO NOTHING;
+
+-- name: DeleteBookmark :execrows
+DELETE FROM bookmarks
+WHERE user_id = @user_id AND mitt_id = @mitt_id;
+
+-- name: MoveBookmark :execrows
+UPDATE bookmarks
+SET folder_id = sqlc.narg('folder_id')::uuid
+WHERE user_id = @user_id AND mitt_id = @mitt_id;
+
+-- name: GetBookmarks :many
+SELECT m.id, m.author, m.content, m.created_at, m.updated_at, m.likes_count, m.revisions_count, m.version, m.expires_at, u.name AS author_name
+FROM bookmarks b
+JOIN mitts m ON m.id = b.mitt_id
+JOIN users u ON u.id = m.author
+WHERE b.user_id = @user_id AND m.deleted_at IS NULL AND (m.expires_at IS NULL OR m.expires_at > NOW()) AND u.deactivated_at IS NULL
+  AND (sqlc.narg('folder_id')::uuid IS NULL OR b.folder_id = sqlc.narg('folder_id')::uuid)
+ORDER BY b.created_at DESC
+LIMIT $1 OFFSET $2;
+
+-- name: GetBookmarkedMittIDs :many
+SELECT mitt_id
+FROM bookmarks
+WHERE user_id = @user_id AND mitt_id = ANY(@mitt_ids::uuid[]);
+
+-- name: LockUserBookmarkFolders :exec
+SELECT id FROM users
+WHERE id = @user_id
+-- Folders of user are created one at a time, so limit can't be exceeded by concurrent requests
+FOR UPDATE;
+
+-- name: CreateBookmarkFolder :one
+INSERT INTO bookmark_folders (
+    user_id, name
+) VALUES (
+    @user_id, @name
+)
+RETURNING *;
+
+-- name: GetBookmarkFolder :one
+SELECT * FROM bookmark_folders
+WHERE id = @id AND user_id = @user_id;
+
+-- name: GetBookmarkFolders :many
+SELECT f.id, f.name, f.created_at, COUNT(m.id) AS bookmarks_count
+FROM bookmark_folders f
+LEFT JOIN bookmarks b ON b.folder_id = f.id
+LEFT JOIN mitts m ON m.id = b.mitt_id AND m.deleted_at IS NULL AND (m.expires_at IS NULL OR m.expires_at > NOW())
+WHERE f.user_id = @user_id
+GROUP BY f.id
+ORDER BY f.name;
+
+-- name: CountBookmarkFolders :one
+SELECT COUNT(*) FROM bookmark_folders
+WHERE user_id = @user_id;
+
+-- name: RenameBookmarkFolder :one
+UPDATE bookmark_folders
+SET name = @name
+WHERE id = @id AND user_id = @user_id
+RETURNING *;
+
+-- name: DeleteBookmarkFolder :execrows
+DELETE FROM bookmark_folders
+WHERE id = @id AND user_id = @user_id;
@@ -0,0 +1,46 @@
+-- name: LockMittsLikesCounts :many
+SELECT id, likes_count FROM mitts
+WHERE id > @after
+ORDER BY id
+LIMIT $1
+FOR UPDATE;
+
+-- name: SetMittsLikesCounts :execrows
+UPDATE mitts m
+SET likes_count = t.likes_count
+FROM unnest(@ids::uuid[], @likes_counts::bigint[]) AS t(id, likes_count)
+WHERE m.id = t.id;
+
+-- name: LockUsersCounts :many
+SELECT id FROM users
+WHERE id > @after
+ORDER BY id
+LIMIT $1
+FOR UPDATE;
+
+-- name: LockUsers :exec
+SELECT id FROM users
+WHERE id = ANY(@ids::uuid[])
+ORDER BY id
+FOR UPDATE;
+
+-- name: ReconcileUsersCounts :execrows
+UPDATE users u
+SET
+    followers_count = c.followers,
+    following_count = c.following,
+    mitts_count = c.mitts
+FROM (
+    SELECT
+        u2.id,
+        (SELECT COUNT(*) FROM users_follows uf WHERE uf.followee_id = u2.id) AS followers,
+        (SELECT COUNT(*) FROM users_follows uf WHERE uf.follower_id = u2.id) AS following,
+        (SELECT COUNT(*) FROM mitts m WHERE m.author = u2.id AND m.deleted_at IS NULL AND (m.expires_at IS NULL OR m.expires_at > NOW())) AS mitts
+    FROM users u2
+    WHERE u2.id = ANY(@ids::uuid[])
+) c
+WHERE u.id = c.id AND (
+    u.followers_count <> c.followers OR
+    u.following_count <> c.following OR
+    u.mitts_count <> c.mitts
+);
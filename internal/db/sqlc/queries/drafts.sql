@@ -0,0 +1,54 @@
+-- name: CreateDraft :one
+INSERT INTO mitt_drafts (
+    author, content, publish_at
+) VALUES (
+    @author, @content, sqlc.narg('publish_at')::timestamp
+)
+RETURNING *;
+
+-- name: GetDraft :one
+SELECT *
+FROM mitt_drafts
+WHERE id = @id AND author = @author;
+
+-- name: GetUserDrafts :many
+SELECT *
+FROM mitt_drafts
+WHERE author = @author
+  AND (sqlc.narg('scheduled')::bool IS NULL OR (publish_at IS NOT NULL) = sqlc.narg('scheduled')::bool)
+ORDER BY publish_at NULLS LAST, updated_at DESC
+LIMIT $1 OFFSET $2;
+
+-- name: UpdateDraft :one
+UPDATE mitt_drafts
+SET
+    content = @content,
+    publish_at = sqlc.narg('publish_at')::timestamp,
+    claimed_until = NULL,
+    updated_at = NOW(),
+    version = version + 1
+WHERE id = @id AND author = @author AND version = COALESCE(sqlc.narg('expected_version')::int, version)
+RETURNING *;
+
+-- name: DeleteDraft :execrows
+DELETE FROM mitt_drafts
+WHERE id = @id AND author = @author;
+
+-- name: ClaimScheduledDrafts :many
+UPDATE mitt_drafts
+SET claimed_until = NOW() + (@lease_seconds::bigint * INTERVAL '1 second')
+WHERE id IN (
+    SELECT d.id
+    FROM mitt_drafts d
+    JOIN users u ON u.id = d.author
+    WHERE d.publish_at <= NOW() AND (d.claimed_until IS NULL OR d.claimed_until <= NOW())
+      AND u.deactivated_at IS NULL
+    ORDER BY d.publish_at
+    LIMIT sqlc.arg(batch_size)::int
+    FOR UPDATE OF d SKIP LOCKED
+)
+RETURNING *;
+
+-- name: DeletePublishedDraft :execrows
+DELETE FROM mitt_drafts
+WHERE id = @id AND version = @version;
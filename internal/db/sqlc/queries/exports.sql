@@ -0,0 +1,58 @@
+-- name: CreateExport :one
+INSERT INTO data_exports (user_id) VALUES (@user_id)
+RETURNING *;
+
+-- name: GetExport :one
+SELECT * FROM data_exports
+WHERE id = @id AND user_id = @user_id;
+
+-- name: ClaimExport :one
+UPDATE data_exports
+SET status = 'running', started_at = NOW()
+WHERE id = (
+    SELECT id
+    FROM data_exports
+    WHERE status = 'pending' OR
+          (status = 'running' AND started_at < NOW() - (@stale_seconds::bigint * INTERVAL '1 second'))
+    ORDER BY created_at
+    LIMIT 1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: SaveExportChunk :exec
+INSERT INTO data_export_chunks (export_id, seq, data) VALUES (@export_id, @seq, @data);
+
+-- name: DeleteExportChunks :exec
+DELETE FROM data_export_chunks
+WHERE export_id = @export_id;
+
+-- name: CompleteExport :exec
+UPDATE data_exports
+SET
+    status = 'done',
+    finished_at = NOW(),
+    download_token = gen_random_uuid(),
+    expires_at = NOW() + (@ttl_seconds::bigint * INTERVAL '1 second')
+WHERE id = @id;
+
+-- name: FailExport :exec
+UPDATE data_exports
+SET
+    status = 'failed',
+    error = @error,
+    finished_at = NOW(),
+    expires_at = NOW() + (@ttl_seconds::bigint * INTERVAL '1 second')
+WHERE id = @id;
+
+-- name: GetExportByToken :one
+SELECT * FROM data_exports
+WHERE download_token = @download_token AND expires_at > NOW();
+
+-- name: GetExportChunk :one
+SELECT data FROM data_export_chunks
+WHERE export_id = @export_id AND seq = @seq;
+
+-- name: DeleteExpiredExports :execrows
+DELETE FROM data_exports
+WHERE expires_at < NOW();
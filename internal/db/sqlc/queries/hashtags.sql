@@ -0,0 +1,26 @@
+-- name: CreateHashtags :exec
+INSERT INTO hashtags (name)
+SELECT unnest(@names::text[])
+ON CONFLICT (name) DO NOTHING;
+
+-- name: LinkMittHashtags :exec
+INSERT INTO mitt_hashtags (mitt_id, hashtag_id)
+SELECT @mitt_id, id
+FROM hashtags
+WHERE name = ANY(@names::text[])
+ON CONFLICT DO NOTHING;
+
+-- name: UnlinkMittHashtags :exec
+DELETE FROM mitt_hashtags mh
+USING hashtags h
+WHERE h.id = mh.hashtag_id AND mh.mitt_id = @mitt_id AND NOT (h.name = ANY(@keep_names::text[]));
+
+-- name: GetHashtagMitts :many
+SELECT m.id, m.author, m.content, m.created_at, m.updated_at, m.likes_count, m.revisions_count, m.version, m.expires_at, u.name AS author_name
+FROM hashtags h
+JOIN mitt_hashtags mh ON mh.hashtag_id = h.id
+JOIN mitts m ON m.id = mh.mitt_id
+JOIN users u ON u.id = m.author
+WHERE h.name = @name AND m.deleted_at IS NULL AND (m.expires_at IS NULL OR m.expires_at > NOW()) AND u.deactivated_at IS NULL
+ORDER BY m.created_at DESC
+LIMIT $1 OFFSET $2;
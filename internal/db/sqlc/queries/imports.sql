@@ -0,0 +1,75 @@
+-- name: CreateImport :one
+INSERT INTO mitt_imports (user_id, status, total) VALUES (@user_id, @status, @total)
+RETURNING *;
+
+-- name: SaveImportItems :exec
+INSERT INTO mitt_import_items (import_id, items) VALUES (@import_id, @items);
+
+-- name: GetImport :one
+SELECT * FROM mitt_imports
+WHERE id = @id AND user_id = @user_id;
+
+-- name: GetImportErrors :many
+SELECT * FROM mitt_import_errors
+WHERE import_id = @import_id
+ORDER BY item_index
+LIMIT $1 OFFSET $2;
+
+-- name: ClaimImport :one
+UPDATE mitt_imports
+SET status = 'running', updated_at = NOW()
+WHERE id = (
+    SELECT id
+    FROM mitt_imports
+    WHERE status = 'pending' OR
+          (status = 'running' AND updated_at < NOW() - (@stale_seconds::bigint * INTERVAL '1 second'))
+    ORDER BY created_at
+    LIMIT 1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: GetImportItems :one
+SELECT items FROM mitt_import_items
+WHERE import_id = @import_id;
+
+-- name: ImportMitt :one
+INSERT INTO mitts (
+    author, content, created_at, updated_at, external_id
+) VALUES (
+    @author, @content, @created_at, @created_at, @external_id
+)
+ON CONFLICT (author, external_id) WHERE external_id IS NOT NULL DO NOTHING
+RETURNING id;
+
+-- name: SaveImportError :exec
+INSERT INTO mitt_import_errors (
+    import_id, item_index, external_id, error
+) VALUES (
+    @import_id, @item_index, @external_id, @error
+);
+
+-- name: UpdateImportProgress :one
+UPDATE mitt_imports
+SET
+    processed = @processed,
+    imported = imported + @imported::int,
+    skipped = skipped + @skipped::int,
+    failed = failed + @failed::int,
+    updated_at = NOW()
+WHERE id = @id
+RETURNING *;
+
+-- name: FinishImport :exec
+UPDATE mitt_imports
+SET status = 'done', updated_at = NOW(), finished_at = NOW()
+WHERE id = @id;
+
+-- name: FailImport :exec
+UPDATE mitt_imports
+SET status = 'failed', error = @error, updated_at = NOW(), finished_at = NOW()
+WHERE id = @id;
+
+-- name: DeleteImportItems :exec
+DELETE FROM mitt_import_items
+WHERE import_id = @import_id;
@@ -0,0 +1,34 @@
+-- name: LinkMittMentions :exec
+INSERT INTO mitt_mentions (mitt_id, user_id)
+SELECT @mitt_id, id
+FROM users
+WHERE lower(login) = ANY(@logins::text[]) AND deactivated_at IS NULL
+ON CONFLICT DO NOTHING;
+
+-- name: UnlinkMittMentions :exec
+DELETE FROM mitt_mentions mm
+USING users u
+WHERE u.id = mm.user_id AND mm.mitt_id = @mitt_id AND NOT (lower(u.login) = ANY(@keep_logins::text[]));
+
+-- name: GetMittsMentions :many
+SELECT mm.mitt_id, mm.user_id, u.login
+FROM mitt_mentions mm
+JOIN users u ON u.id = mm.user_id
+WHERE mm.mitt_id = ANY(@mitt_ids::uuid[]) AND u.deactivated_at IS NULL;
+
+-- name: GetMentionedMitts :many
+SELECT m.id, m.author, m.content, m.created_at, m.updated_at, m.likes_count, m.revisions_count, m.version, m.expires_at, u.name AS author_name
+FROM mitt_mentions mm
+JOIN mitts m ON m.id = mm.mitt_id
+JOIN users u ON u.id = m.author
+WHERE mm.user_id = @user_id AND m.deleted_at IS NULL AND (m.expires_at IS NULL OR m.expires_at > NOW()) AND u.deactivated_at IS NULL
+  AND NOT EXISTS (
+      SELECT 1 FROM users_blocks b
+      WHERE b.blocker_id = @user_id AND b.blocked_id = m.author
+  )
+  AND NOT EXISTS (
+      SELECT 1 FROM users_mutes mu
+      WHERE mu.muter_id = @user_id AND mu.muted_id = m.author
+  )
+ORDER BY m.created_at DESC
+LIMIT $1 OFFSET $2;
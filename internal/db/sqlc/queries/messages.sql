@@ -0,0 +1,164 @@
+-- name: GetMessageRecipients :many
+SELECT u.id, (
+    NOT EXISTS (
+        SELECT 1 FROM users_blocks b
+        WHERE (b.blocker_id = u.id AND b.blocked_id = @sender_id)
+           OR (b.blocker_id = @sender_id AND b.blocked_id = u.id)
+    )
+    AND (
+        NOT u.messages_from_following_only
+        OR EXISTS (
+            SELECT 1 FROM users_follows f
+            WHERE f.follower_id = u.id AND f.followee_id = @sender_id
+        )
+    )
+)::boolean AS allowed
+FROM users u
+WHERE u.id = ANY(@user_ids::uuid[]) AND u.deactivated_at IS NULL;
+
+-- name: HasBlocksInConversation :one
+SELECT EXISTS (
+    SELECT 1 FROM conversation_members cm
+    JOIN users_blocks b ON (b.blocker_id = cm.user_id AND b.blocked_id = @user_id)
+                        OR (b.blocker_id = @user_id AND b.blocked_id = cm.user_id)
+    WHERE cm.conversation_id = @conversation_id
+);
+
+-- name: CreateDirectConversation :one
+INSERT INTO conversations (
+    direct_key
+) VALUES (
+    @direct_key
+)
+ON CONFLICT (direct_key) DO UPDATE SET direct_key = EXCLUDED.direct_key
+RETURNING id, (xmax = 0)::boolean AS created;
+
+-- name: CreateGroupConversation :one
+INSERT INTO conversations DEFAULT VALUES
+RETURNING id;
+
+-- name: AddConversationMembers :exec
+INSERT INTO conversation_members (
+    conversation_id, user_id
+)
+SELECT @conversation_id, unnest(@user_ids::uuid[])
+ON CONFLICT DO NOTHING;
+
+-- name: GetConversation :one
+SELECT
+    c.id,
+    (c.direct_key IS NULL)::boolean AS is_group,
+    c.created_at,
+    ARRAY(
+        SELECT cm.user_id FROM conversation_members cm
+        WHERE cm.conversation_id = c.id
+        ORDER BY cm.joined_at, cm.user_id
+    )::uuid[] AS member_ids,
+    (
+        SELECT COUNT(*) FROM messages ms
+        WHERE ms.conversation_id = c.id AND ms.created_at > me.last_read_at AND ms.sender_id <> me.user_id
+          AND NOT EXISTS (
+              SELECT 1 FROM users_blocks b
+              WHERE (b.blocker_id = me.user_id AND b.blocked_id = ms.sender_id)
+                 OR (b.blocker_id = ms.sender_id AND b.blocked_id = me.user_id)
+          )
+    ) AS unread_count,
+    lm.id AS last_message_id,
+    lm.sender_id AS last_message_sender_id,
+    lm.content AS last_message_content,
+    lm.created_at AS last_message_created_at
+FROM conversations c
+JOIN conversation_members me ON me.conversation_id = c.id AND me.user_id = @user_id
+LEFT JOIN LATERAL (
+    SELECT ms.id, ms.sender_id, ms.content, ms.created_at FROM messages ms
+    WHERE ms.conversation_id = c.id
+    ORDER BY ms.created_at DESC, ms.id DESC
+    LIMIT 1
+) lm ON TRUE
+WHERE c.id = @id;
+
+-- name: GetUserConversations :many
+SELECT
+    c.id,
+    (c.direct_key IS NULL)::boolean AS is_group,
+    c.created_at,
+    ARRAY(
+        SELECT cm.user_id FROM conversation_members cm
+        WHERE cm.conversation_id = c.id
+        ORDER BY cm.joined_at, cm.user_id
+    )::uuid[] AS member_ids,
+    (
+        SELECT COUNT(*) FROM messages ms
+        WHERE ms.conversation_id = c.id AND ms.created_at > me.last_read_at AND ms.sender_id <> me.user_id
+          AND NOT EXISTS (
+              SELECT 1 FROM users_blocks b
+              WHERE (b.blocker_id = me.user_id AND b.blocked_id = ms.sender_id)
+                 OR (b.blocker_id = ms.sender_id AND b.blocked_id = me.user_id)
+          )
+    ) AS unread_count,
+    lm.id AS last_message_id,
+    lm.sender_id AS last_message_sender_id,
+    lm.content AS last_message_content,
+    lm.created_at AS last_message_created_at
+FROM conversations c
+JOIN conversation_members me ON me.conversation_id = c.id AND me.user_id = @user_id
+LEFT JOIN LATERAL (
+    SELECT ms.id, ms.sender_id, ms.content, ms.created_at FROM messages ms
+    WHERE ms.conversation_id = c.id
+    ORDER BY ms.created_at DESC, ms.id DESC
+    LIMIT 1
+) lm ON TRUE
+ORDER BY COALESCE(c.last_message_at, c.created_at) DESC, c.id
+LIMIT $1 OFFSET $2;
+
+-- name: CreateMessage :one
+WITH m AS (
+    INSERT INTO messages (
+        conversation_id, sender_id, content
+    ) VALUES (
+        @conversation_id, @sender_id, @content
+    )
+    RETURNING *
+), c AS (
+    UPDATE conversations
+    SET last_message_at = (SELECT created_at FROM m)
+    WHERE id = @conversation_id
+), r AS (
+    -- Sender has read everything up to own message
+    UPDATE conversation_members
+    SET last_read_at = (SELECT created_at FROM m)
+    WHERE conversation_id = @conversation_id AND user_id = @sender_id
+)
+SELECT * FROM m;
+
+-- name: GetMessages :many
+SELECT * FROM messages
+WHERE conversation_id = @conversation_id
+  AND (
+      sqlc.narg('before')::uuid IS NULL
+      OR (created_at, id) < (
+          SELECT b.created_at, b.id FROM messages b
+          WHERE b.id = sqlc.narg('before')::uuid AND b.conversation_id = @conversation_id
+      )
+  )
+ORDER BY created_at DESC, id DESC
+LIMIT $1;
+
+-- name: MarkConversationRead :execrows
+UPDATE conversation_members
+SET last_read_at = GREATEST(
+    last_read_at,
+    COALESCE((SELECT MAX(created_at) FROM messages WHERE conversation_id = @conversation_id), last_read_at)
+)
+WHERE conversation_id = @conversation_id AND user_id = @user_id;
+
+-- name: GetUnreadMessagesCount :one
+SELECT COUNT(*)
+FROM conversation_members me
+JOIN messages ms ON ms.conversation_id = me.conversation_id
+WHERE me.user_id = @user_id AND ms.created_at > me.last_read_at AND ms.sender_id <> me.user_id
+  AND NOT EXISTS (
+      SELECT 1 FROM users_blocks b
+      WHERE (b.blocker_id = me.user_id AND b.blocked_id = ms.sender_id)
+         OR (b.blocker_id = ms.sender_id AND b.blocked_id = me.user_id)
+  );
@@ -1,56 +1,167 @@
 -- name: CreateMitt :one
 INSERT INTO mitts (
-    author, content
+    author, content, expires_at
 ) VALUES (
-    @author, @content
+    @author, @content, sqlc.narg('expires_at')
 )
 RETURNING *;
 
 -- name: GetMitt :one
-SELECT * FROM mitts
-WHERE id = @id
+SELECT m.id, m.author, m.content, m.created_at, m.updated_at, m.likes_count, m.revisions_count, m.version, m.expires_at, u.name AS author_name
+FROM mitts m
+JOIN users u ON u.id = m.author
+WHERE m.id = @id AND m.deleted_at IS NULL AND (m.expires_at IS NULL OR m.expires_at > NOW()) AND u.deactivated_at IS NULL
 LIMIT 1;
 
 -- name: GetAllUserMitts :many
-SELECT * FROM mitts
-WHERE author = @author
-ORDER BY created_at
+SELECT m.id, m.author, m.content, m.created_at, m.updated_at, m.likes_count, m.revisions_count, m.version, m.expires_at, u.name AS author_name
+FROM mitts m
+JOIN users u ON u.id = m.author
+LEFT JOIN pinned_mitts p ON p.mitt_id = m.id
+WHERE m.author = @author AND m.deleted_at IS NULL AND (m.expires_at IS NULL OR m.expires_at > NOW()) AND u.deactivated_at IS NULL
+-- Pinned mitts first, recently pinned first
+ORDER BY p.pinned_at DESC NULLS LAST, m.created_at, m.id
 LIMIT $1 OFFSET $2;
 
 -- name: UpdateMitt :one
 UPDATE mitts
 SET
     content = @content,
-    updated_at = NOW()
-WHERE id = @id
+    updated_at = NOW(),
+    revisions_count = revisions_count + 1,
+    version = version + 1
+WHERE id = @id AND deleted_at IS NULL AND (expires_at IS NULL OR expires_at > NOW()) AND version = COALESCE(sqlc.narg('expected_version')::int, version)
 RETURNING *;
 
--- name: DeleteMitt :exec
+-- name: SaveMittRevision :exec
+INSERT INTO mitt_revisions (
+    mitt_id, content, created_at
+)
+SELECT id, content, updated_at
+FROM mitts
+WHERE id = @mitt_id
+-- Row stays locked until update in the same transaction, so concurrent edit can't slip in between
+FOR UPDATE;
+
+-- name: GetMittRevisions :many
+SELECT * FROM mitt_revisions
+WHERE mitt_id = @mitt_id
+ORDER BY created_at DESC
+LIMIT $1 OFFSET $2;
+
+-- name: GetMittsRevisions :many
+SELECT * FROM mitt_revisions
+WHERE mitt_id = ANY(@mitt_ids::uuid[])
+ORDER BY mitt_id, created_at DESC;
+
+-- name: DeleteMitt :one
+UPDATE mitts
+SET deleted_at = NOW()
+WHERE id = @id AND deleted_at IS NULL AND (expires_at IS NULL OR expires_at > NOW())
+RETURNING author;
+
+-- name: RestoreMitt :one
+UPDATE mitts
+SET deleted_at = NULL
+WHERE id = @id AND author = @author
+  AND deleted_at > NOW() - (@retention_seconds::bigint * INTERVAL '1 second')
+  AND (expires_at IS NULL OR expires_at > NOW())
+RETURNING author;
+
+-- name: GetDeletedUserMitts :many
+SELECT *
+FROM mitts
+WHERE author = @author AND deleted_at IS NOT NULL AND (expires_at IS NULL OR expires_at > NOW())
+ORDER BY deleted_at DESC, id
+LIMIT $1 OFFSET $2;
+
+-- name: PurgeDeletedMitts :execrows
 DELETE FROM mitts
-WHERE id = @id;
+WHERE id IN (
+    SELECT id
+    FROM mitts
+    WHERE deleted_at < NOW() - (@retention_seconds::bigint * INTERVAL '1 second')
+    LIMIT @batch_size
+);
+
+-- name: DeleteExpiredMitts :many
+DELETE FROM mitts
+WHERE id IN (
+    SELECT id
+    FROM mitts
+    WHERE expires_at <= NOW()
+    ORDER BY expires_at
+    LIMIT @batch_size
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, author, content, created_at, (deleted_at IS NULL)::bool AS active;
+
+-- name: DeleteUserMitts :execrows
+DELETE FROM mitts
+WHERE id IN (
+    SELECT id
+    FROM mitts
+    WHERE author = @author
+    LIMIT @batch_size
+);
 
+-- name: DeleteUserLikes :one
+WITH deleted AS (
+    DELETE FROM mitts_likes
+    WHERE id IN (
+        SELECT id
+        FROM mitts_likes
+        WHERE user_id = @user_id
+        LIMIT @batch_size
+    )
+    RETURNING mitt_id
+), updated AS (
+    UPDATE mitts m
+    SET likes_count = m.likes_count - d.likes
+    FROM (SELECT mitt_id, COUNT(*) AS likes FROM deleted GROUP BY mitt_id) d
+    WHERE m.id = d.mitt_id
+)
+SELECT COUNT(*) FROM deleted;
 
--- name: LikeMitt :exec
+-- name: LikeMitt :execrows
 INSERT INTO mitts_likes (
     user_id, mitt_id
-) VALUES (
-    @user_id, @mitt_id
-);
+)
+SELECT @user_id, id
+FROM mitts
+WHERE id = @mitt_id AND deleted_at IS NULL AND (expires_at IS NULL OR expires_at > NOW())
+ON CONFLICT (user_id, mitt_id) DO NOTHING;
 
 -- name: IsMittLikedByUser :one
 SELECT 1 FROM mitts_likes
 WHERE user_id = @user_id AND mitt_id = @mitt_id;
 
--- name: DeleteMittLike :exec
+-- name: DeleteMittLike :execrows
 DELETE FROM mitts_likes
 WHERE user_id = @user_id AND mitt_id = @mitt_id;
 
--- name: GetMittLikesCount :one
-SELECT COUNT(*) FROM mitts_likes
-WHERE mitt_id = @mitt_id;
+-- name: GetMittsLikesCounts :many
+SELECT mitt_id, COUNT(*) AS likes
+FROM mitts_likes
+WHERE mitt_id = ANY(@mitt_ids::uuid[])
+GROUP BY mitt_id;
+
+-- name: AddMittLikesCount :exec
+UPDATE mitts
+SET likes_count = likes_count + @delta::bigint
+WHERE id = @id;
 
 
 -- name: Feed :many
-SELECT * FROM mitts
-ORDER BY created_at DESC
+SELECT m.id, m.author, m.content, m.created_at, m.updated_at, m.likes_count, m.revisions_count, m.version, m.expires_at, u.name AS author_name
+FROM mitts m
+JOIN users u ON u.id = m.author
+WHERE m.deleted_at IS NULL AND (m.expires_at IS NULL OR m.expires_at > NOW()) AND u.deactivated_at IS NULL
+ORDER BY m.created_at DESC
+LIMIT $1 OFFSET $2;
+
+-- name: GetUserLikes :many
+SELECT mitt_id, liked_at FROM mitts_likes
+WHERE user_id = @user_id
+ORDER BY liked_at, id
 LIMIT $1 OFFSET $2;
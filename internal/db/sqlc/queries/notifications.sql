@@ -0,0 +1,78 @@
+-- name: CreateNotification :execrows
+INSERT INTO notifications (
+    user_id, actor_id, type, mitt_id
+)
+SELECT r.id, @actor_id, @type, sqlc.narg('mitt_id')
+FROM users r
+WHERE r.id = COALESCE(sqlc.narg('user_id')::uuid, (SELECT author FROM mitts WHERE id = sqlc.narg('mitt_id')::uuid))
+  -- Closed poll is the only thing users are notified about their own mitts
+  AND (r.id <> @actor_id OR @type = 'poll_closed')
+  AND r.deactivated_at IS NULL
+  AND NOT EXISTS (
+      SELECT 1 FROM users_blocks b
+      WHERE b.blocker_id = r.id AND b.blocked_id = @actor_id
+  )
+  AND NOT EXISTS (
+      SELECT 1 FROM users_mutes mu
+      WHERE mu.muter_id = r.id AND mu.muted_id = @actor_id
+  )
+  AND NOT EXISTS (
+      SELECT 1 FROM notification_preferences p
+      WHERE p.user_id = r.id AND p.type = @type AND NOT p.enabled
+  )
+ON CONFLICT DO NOTHING;
+
+-- name: GetNotificationGroups :many
+SELECT
+    (array_agg(n.id ORDER BY n.created_at DESC))[1]::uuid AS id,
+    n.type,
+    n.mitt_id,
+    (array_agg(n.actor_id ORDER BY n.created_at DESC))[1:sqlc.arg(actors_limit)::int]::uuid[] AS actor_ids,
+    COUNT(*) AS actors_count,
+    bool_or(n.read_at IS NULL) AS unread,
+    MAX(n.created_at)::timestamp AS latest_at
+FROM notifications n
+JOIN users a ON a.id = n.actor_id
+LEFT JOIN mitts m ON m.id = n.mitt_id
+WHERE n.user_id = @user_id AND a.deactivated_at IS NULL AND m.deleted_at IS NULL AND (m.expires_at IS NULL OR m.expires_at > NOW())
+GROUP BY n.type, n.mitt_id, date_trunc('day', n.created_at)
+ORDER BY latest_at DESC
+LIMIT $1 OFFSET $2;
+
+-- name: MarkNotificationGroupRead :one
+WITH g AS (
+    SELECT * FROM notifications
+    WHERE id = @id AND user_id = @user_id
+), updated AS (
+    UPDATE notifications n
+    SET read_at = NOW()
+    FROM g
+    WHERE n.user_id = g.user_id AND n.type = g.type AND n.mitt_id IS NOT DISTINCT FROM g.mitt_id
+      AND date_trunc('day', n.created_at) = date_trunc('day', g.created_at)
+      AND n.read_at IS NULL
+)
+SELECT COUNT(*) FROM g;
+
+-- name: MarkAllNotificationsRead :exec
+UPDATE notifications
+SET read_at = NOW()
+WHERE user_id = @user_id AND read_at IS NULL;
+
+-- name: GetUnreadNotificationsCount :one
+SELECT COUNT(*)
+FROM notifications n
+JOIN users a ON a.id = n.actor_id
+LEFT JOIN mitts m ON m.id = n.mitt_id
+WHERE n.user_id = @user_id AND n.read_at IS NULL AND a.deactivated_at IS NULL AND m.deleted_at IS NULL AND (m.expires_at IS NULL OR m.expires_at > NOW());
+
+-- name: GetNotificationPreferences :many
+SELECT type, enabled FROM notification_preferences
+WHERE user_id = @user_id;
+
+-- name: SetNotificationPreference :exec
+INSERT INTO notification_preferences (
+    user_id, type, enabled
+) VALUES (
+    @user_id, @type, @enabled
+)
+ON CONFLICT (user_id, type) DO UPDATE SET enabled = EXCLUDED.enabled;
@@ -0,0 +1,37 @@
+-- name: LockUserPins :one
+SELECT is_private FROM users
+WHERE id = @user_id
+-- Pins of user are changed one at a time, so limit can't be exceeded by concurrent pins
+-- and account can't become private in the meantime
+FOR UPDATE;
+
+-- name: PinMitt :execrows
+INSERT INTO pinned_mitts (
+    mitt_id, user_id
+)
+SELECT id, author
+FROM mitts
+WHERE id = @mitt_id AND author = @user_id AND deleted_at IS NULL AND (expires_at IS NULL OR expires_at > NOW())
+-- Mitt can't go to trash until pin is saved, trash removes it then
+FOR SHARE
+ON CONFLICT (mitt_id) DO NOTHING;
+
+-- name: CountPinnedMitts :one
+SELECT COUNT(*) FROM pinned_mitts
+WHERE user_id = @user_id;
+
+-- name: UnpinMitt :execrows
+DELETE FROM pinned_mitts
+WHERE mitt_id = @mitt_id AND user_id = @user_id;
+
+-- name: DeleteMittPin :exec
+DELETE FROM pinned_mitts
+WHERE mitt_id = @mitt_id;
+
+-- name: GetPinnedMittIDs :many
+SELECT mitt_id FROM pinned_mitts
+WHERE user_id = @user_id AND mitt_id = ANY(@mitt_ids::uuid[]);
+
+-- name: DeleteUserPins :exec
+DELETE FROM pinned_mitts
+WHERE user_id = @user_id;
@@ -0,0 +1,51 @@
+-- name: CreatePoll :exec
+INSERT INTO polls (
+    mitt_id, options, multiple_choice, expires_at
+) VALUES (
+    @mitt_id, @options::text[], @multiple_choice, @expires_at
+);
+
+-- name: GetPolls :many
+SELECT
+    p.mitt_id, p.options, p.multiple_choice, p.expires_at, p.closed_at,
+    ARRAY(
+        SELECT COUNT(v.user_id)
+        FROM generate_subscripts(p.options, 1) AS i
+        LEFT JOIN poll_votes v ON v.mitt_id = p.mitt_id AND (i - 1)::smallint = ANY(v.choices)
+        GROUP BY i
+        ORDER BY i
+    )::bigint[] AS votes,
+    (SELECT COUNT(*) FROM poll_votes v WHERE v.mitt_id = p.mitt_id) AS voters_count
+FROM polls p
+WHERE p.mitt_id = ANY(@mitt_ids::uuid[]);
+
+-- name: GetPollChoices :many
+SELECT mitt_id, choices
+FROM poll_votes
+WHERE user_id = @user_id AND mitt_id = ANY(@mitt_ids::uuid[]);
+
+-- name: VotePoll :execrows
+INSERT INTO poll_votes (
+    mitt_id, user_id, choices
+)
+SELECT p.mitt_id, @user_id, @choices::smallint[]
+FROM polls p
+JOIN mitts m ON m.id = p.mitt_id
+JOIN users u ON u.id = m.author
+WHERE p.mitt_id = @mitt_id AND p.closed_at IS NULL AND p.expires_at > NOW()
+  AND m.deleted_at IS NULL AND (m.expires_at IS NULL OR m.expires_at > NOW()) AND u.deactivated_at IS NULL
+ON CONFLICT (mitt_id, user_id) DO NOTHING;
+
+-- name: ClosePolls :many
+UPDATE polls p
+SET closed_at = NOW()
+FROM mitts m
+WHERE m.id = p.mitt_id AND p.mitt_id IN (
+    SELECT mitt_id
+    FROM polls
+    WHERE closed_at IS NULL AND expires_at <= NOW()
+    ORDER BY expires_at
+    LIMIT sqlc.arg(batch_size)::int
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING p.mitt_id, m.author, (m.deleted_at IS NULL AND (m.expires_at IS NULL OR m.expires_at > NOW()))::bool AS active;
@@ -0,0 +1,86 @@
+-- name: SearchMitts :many
+SELECT m.id, m.author, m.content, m.created_at, m.updated_at, m.likes_count, m.revisions_count, m.version, m.expires_at, u.name AS author_name
+FROM mitts m
+JOIN users u ON u.id = m.author
+WHERE m.deleted_at IS NULL AND (m.expires_at IS NULL OR m.expires_at > NOW()) AND u.deactivated_at IS NULL
+  AND (
+      sqlc.narg('query')::text IS NULL
+      OR to_tsvector('simple'::regconfig, m.content) @@ websearch_to_tsquery('simple'::regconfig, sqlc.narg('query')::text)
+  )
+  AND (sqlc.narg('from_logins')::text[] IS NULL OR lower(u.login) = ANY(sqlc.narg('from_logins')::text[]))
+  -- Mitt must have all hashtags
+  AND (
+      sqlc.narg('hashtags')::text[] IS NULL
+      OR (
+          SELECT COUNT(*) FROM mitt_hashtags mh
+          JOIN hashtags h ON h.id = mh.hashtag_id
+          WHERE mh.mitt_id = m.id AND h.name = ANY(sqlc.narg('hashtags')::text[])
+      ) = cardinality(sqlc.narg('hashtags')::text[])
+  )
+  AND (sqlc.narg('since')::timestamp IS NULL OR m.created_at >= sqlc.narg('since')::timestamp)
+  AND (sqlc.narg('until')::timestamp IS NULL OR m.created_at < sqlc.narg('until')::timestamp)
+  -- Mitts of private accounts are found only by their followers, elsewhere they are visible to everyone
+  AND (
+      NOT u.is_private
+      OR u.id = @viewer_id
+      OR EXISTS (
+          SELECT 1 FROM users_follows f
+          WHERE f.follower_id = @viewer_id AND f.followee_id = u.id
+      )
+  )
+  AND NOT EXISTS (
+      SELECT 1 FROM users_blocks b
+      WHERE (b.blocker_id = @viewer_id AND b.blocked_id = u.id)
+         OR (b.blocker_id = u.id AND b.blocked_id = @viewer_id)
+  )
+  AND NOT EXISTS (
+      SELECT 1 FROM users_mutes mu
+      WHERE mu.muter_id = @viewer_id AND mu.muted_id = u.id
+  )
+ORDER BY
+    CASE WHEN @by_relevance::boolean THEN
+        ts_rank_cd(to_tsvector('simple'::regconfig, m.content), websearch_to_tsquery('simple'::regconfig, sqlc.narg('query')::text))
+    END DESC NULLS LAST,
+    m.created_at DESC,
+    m.id DESC
+LIMIT $1 OFFSET $2;
+
+-- name: SearchUsers :many
+WITH friends AS (
+    -- Users I follow who follow me back
+    SELECT f1.followee_id AS id
+    FROM users_follows f1
+    JOIN users_follows f2 ON f2.follower_id = f1.followee_id AND f2.followee_id = f1.follower_id
+    WHERE f1.follower_id = @viewer_id
+)
+SELECT u.id
+FROM users u
+WHERE u.deactivated_at IS NULL
+  AND (u.login % @query::text OR u.name % @query::text OR lower(u.login) LIKE @login_prefix::text || '%')
+  AND NOT EXISTS (
+      SELECT 1 FROM users_blocks b
+      WHERE (b.blocker_id = @viewer_id AND b.blocked_id = u.id)
+         OR (b.blocker_id = u.id AND b.blocked_id = @viewer_id)
+  )
+ORDER BY
+    GREATEST(similarity(u.login, @query::text), similarity(u.name, @query::text))
+    -- Accounts I follow and accounts followed by my friends (up to 5 of them) go up
+    + CASE WHEN EXISTS (
+        SELECT 1 FROM users_follows f
+        WHERE f.follower_id = @viewer_id AND f.followee_id = u.id
+    ) THEN 0.5 ELSE 0 END
+    + 0.1 * LEAST((
+        SELECT COUNT(*) FROM users_follows f
+        JOIN friends fr ON fr.id = f.follower_id
+        WHERE f.followee_id = u.id
+    ), 5) DESC,
+    u.followers_count DESC,
+    u.id
+LIMIT $1 OFFSET $2;
+
+-- name: AutocompleteLogins :many
+SELECT id
+FROM users
+WHERE lower(login) LIKE @login_prefix::text || '%' AND deactivated_at IS NULL
+ORDER BY followers_count DESC, login
+LIMIT $1;
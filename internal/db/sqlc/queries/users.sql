@@ -7,17 +7,67 @@ SELECT * FROM users WHERE login = @login
 LIMIT 1;
 
 -- name: GetUserByID :one
-SELECT * FROM users WHERE id = @id
+SELECT * FROM users WHERE id = @id AND deactivated_at IS NULL
 LIMIT 1;
 
+-- name: GetUsersByIDs :many
+SELECT * FROM users WHERE id = ANY(@ids::uuid[]) AND deactivated_at IS NULL;
+
+-- name: DeactivateUser :execrows
+UPDATE users
+SET deactivated_at = NOW()
+WHERE id = @id AND deactivated_at IS NULL;
+
+-- name: ReactivateUser :execrows
+UPDATE users
+SET deactivated_at = NULL
+WHERE id = @id
+  AND deactivated_at > NOW() - (@grace_period_seconds::bigint * INTERVAL '1 second');
+
+-- name: GetUsersToPurge :many
+SELECT id FROM users
+WHERE deactivated_at < NOW() - (@grace_period_seconds::bigint * INTERVAL '1 second')
+ORDER BY deactivated_at
+LIMIT $1;
+
+-- name: DeleteUserFollows :one
+WITH deleted AS (
+    DELETE FROM users_follows
+    WHERE id IN (
+        SELECT id
+        FROM users_follows
+        WHERE follower_id = @user_id OR followee_id = @user_id
+        LIMIT @batch_size
+    )
+    RETURNING follower_id, followee_id
+), updated AS (
+    UPDATE users u
+    SET
+        following_count = u.following_count - (SELECT COUNT(*) FROM deleted d WHERE d.follower_id = u.id),
+        followers_count = u.followers_count - (SELECT COUNT(*) FROM deleted d WHERE d.followee_id = u.id)
+    WHERE u.id IN (SELECT follower_id FROM deleted UNION SELECT followee_id FROM deleted)
+)
+SELECT COUNT(*) FROM deleted;
+
+-- name: DeleteUserBlocks :exec
+DELETE FROM users_blocks
+WHERE blocker_id = @user_id OR blocked_id = @user_id;
+
+-- name: DeleteUserMutes :exec
+DELETE FROM users_mutes
+WHERE muter_id = @user_id OR muted_id = @user_id;
+
 -- name: DeleteUser :exec
-DELETE FROM users WHERE id = @id;
+DELETE FROM users WHERE id = @id AND deactivated_at IS NOT NULL;
 
--- name: UpdateUser :exec
+-- name: UpdateUser :execrows
 UPDATE users
 SET
-    name = COALESCE(sqlc.narg('name'), name)
-WHERE id = @id;
+    name = COALESCE(sqlc.narg('name'), name),
+    is_private = COALESCE(sqlc.narg('is_private'), is_private),
+    messages_from_following_only = COALESCE(sqlc.narg('messages_from_following_only'), messages_from_following_only),
+    version = version + 1
+WHERE id = @id AND version = COALESCE(sqlc.narg('expected_version')::int, version);
 
 -- name: UpdatePassword :exec
 UPDATE users
@@ -35,7 +85,7 @@ INSERT INTO users_follows (
     @follower_id, @followee_id
 );
 
--- name: UnfollowUser :exec
+-- name: UnfollowUser :execrows
 DELETE FROM users_follows
 WHERE follower_id = @follower_id AND
       followee_id = @followee_id;
@@ -43,11 +93,13 @@ WHERE follower_id = @follower_id AND
 -- name: GetUserFollows :many
 SELECT followee_id FROM users_follows
 WHERE follower_id = @follower_id
+ORDER BY id
 LIMIT $1 OFFSET $2;
 
 -- name: GetUserFollowers :many
 SELECT follower_id FROM users_follows
 WHERE followee_id = @followee_id
+ORDER BY id
 LIMIT $1 OFFSET $2;
 
 -- name: GetUserFriends :many
@@ -55,4 +107,194 @@ SELECT uf1.followee_id
 FROM users_follows uf1
 JOIN users_follows uf2 ON uf1.follower_id = uf2.followee_id AND uf1.followee_id = uf2.follower_id
 WHERE uf1.follower_id = @id
+ORDER BY uf1.id
+LIMIT $1 OFFSET $2;
+
+-- name: ListUserIDs :many
+SELECT id FROM users
+WHERE id > @after
+ORDER BY id
+LIMIT $1;
+
+-- name: BlockUser :exec
+INSERT INTO users_blocks (
+    blocker_id, blocked_id
+) VALUES (
+    @blocker_id, @blocked_id
+)
+ON CONFLICT (blocker_id, blocked_id) DO NOTHING;
+
+-- name: UnblockUser :exec
+DELETE FROM users_blocks
+WHERE blocker_id = @blocker_id AND
+      blocked_id = @blocked_id;
+
+-- name: IsBlockedBetween :one
+SELECT EXISTS (
+    SELECT 1 FROM users_blocks
+    WHERE (blocker_id = @first_id AND blocked_id = @second_id) OR
+          (blocker_id = @second_id AND blocked_id = @first_id)
+);
+
+-- name: DeleteFollowsBetween :many
+DELETE FROM users_follows
+WHERE (follower_id = @first_id AND followee_id = @second_id) OR
+      (follower_id = @second_id AND followee_id = @first_id)
+RETURNING follower_id, followee_id;
+
+-- name: MuteUser :exec
+INSERT INTO users_mutes (
+    muter_id, muted_id
+) VALUES (
+    @muter_id, @muted_id
+)
+ON CONFLICT (muter_id, muted_id) DO NOTHING;
+
+-- name: UnmuteUser :exec
+DELETE FROM users_mutes
+WHERE muter_id = @muter_id AND
+      muted_id = @muted_id;
+
+-- name: GetHiddenUserIDs :many
+SELECT blocked_id AS id FROM users_blocks
+WHERE blocker_id = @user_id
+UNION
+SELECT blocker_id FROM users_blocks
+WHERE blocked_id = @user_id
+UNION
+SELECT muted_id FROM users_mutes
+WHERE muter_id = @user_id;
+
+-- name: SuggestFollows :many
+WITH my_follows AS (
+    SELECT followee_id FROM users_follows
+    WHERE follower_id = @user_id
+),
+my_friends AS (
+    SELECT uf1.followee_id AS friend_id
+    FROM users_follows uf1
+    JOIN users_follows uf2 ON uf1.follower_id = uf2.followee_id AND uf1.followee_id = uf2.follower_id
+    WHERE uf1.follower_id = @user_id
+),
+-- Deactivated users are left out here, so they don't take places in the top
+candidates AS (
+    SELECT uf.followee_id AS candidate_id, 1 AS weight
+    FROM users_follows uf
+    JOIN users u ON u.id = uf.followee_id
+    WHERE uf.follower_id IN (SELECT followee_id FROM my_follows) AND u.deactivated_at IS NULL
+    UNION ALL
+    SELECT uf1.followee_id AS candidate_id, 2 AS weight
+    FROM users_follows uf1
+    JOIN users_follows uf2 ON uf1.follower_id = uf2.followee_id AND uf1.followee_id = uf2.follower_id
+    JOIN users u ON u.id = uf1.followee_id
+    WHERE uf1.follower_id IN (SELECT friend_id FROM my_friends) AND u.deactivated_at IS NULL
+)
+SELECT c.candidate_id
+FROM candidates c
+WHERE c.candidate_id <> @user_id
+  AND c.candidate_id NOT IN (SELECT followee_id FROM my_follows)
+  AND NOT EXISTS (
+      SELECT 1 FROM users_blocks ub
+      WHERE (ub.blocker_id = @user_id AND ub.blocked_id = c.candidate_id) OR
+            (ub.blocker_id = c.candidate_id AND ub.blocked_id = @user_id)
+  )
+  AND NOT EXISTS (
+      SELECT 1 FROM users_mutes um
+      WHERE um.muter_id = @user_id AND um.muted_id = c.candidate_id
+  )
+GROUP BY c.candidate_id
+ORDER BY SUM(c.weight) DESC, c.candidate_id
+LIMIT $1;
+
+-- name: GetPopularUsers :many
+SELECT u.id
+FROM users u
+WHERE u.id <> @user_id
+  AND NOT EXISTS (
+      SELECT 1 FROM users_follows f
+      WHERE f.follower_id = @user_id AND f.followee_id = u.id
+  )
+  AND NOT EXISTS (
+      SELECT 1 FROM users_blocks ub
+      WHERE (ub.blocker_id = @user_id AND ub.blocked_id = u.id) OR
+            (ub.blocker_id = u.id AND ub.blocked_id = @user_id)
+  )
+  AND NOT EXISTS (
+      SELECT 1 FROM users_mutes um
+      WHERE um.muter_id = @user_id AND um.muted_id = u.id
+  )
+  AND u.deactivated_at IS NULL
+ORDER BY u.followers_count DESC, u.id
+LIMIT $1;
+
+-- name: FilterSuggestable :many
+SELECT t.id
+FROM unnest(@candidate_ids::uuid[]) WITH ORDINALITY AS t(id, ord)
+JOIN users u ON u.id = t.id
+WHERE u.deactivated_at IS NULL
+  AND NOT EXISTS (
+      SELECT 1 FROM users_follows f
+      WHERE f.follower_id = @user_id AND f.followee_id = t.id
+  )
+  AND NOT EXISTS (
+      SELECT 1 FROM users_blocks ub
+      WHERE (ub.blocker_id = @user_id AND ub.blocked_id = t.id) OR
+            (ub.blocker_id = t.id AND ub.blocked_id = @user_id)
+  )
+  AND NOT EXISTS (
+      SELECT 1 FROM users_mutes um
+      WHERE um.muter_id = @user_id AND um.muted_id = t.id
+  )
+ORDER BY t.ord;
+
+-- name: IsFollowing :one
+SELECT EXISTS (
+    SELECT 1 FROM users_follows
+    WHERE follower_id = @follower_id AND followee_id = @followee_id
+);
+
+-- name: GetKnownFollowers :many
+SELECT f.follower_id
+FROM users_follows f
+JOIN users_follows mine ON mine.followee_id = f.follower_id AND mine.follower_id = @viewer_id
+WHERE f.followee_id = @user_id
 LIMIT $1 OFFSET $2;
+
+-- name: GetRelationships :many
+SELECT
+    t.id,
+    EXISTS (
+        SELECT 1 FROM users_follows f
+        WHERE f.follower_id = @user_id AND f.followee_id = t.id
+    ) AS following,
+    EXISTS (
+        SELECT 1 FROM users_follows f
+        WHERE f.follower_id = t.id AND f.followee_id = @user_id
+    ) AS followed_by,
+    EXISTS (
+        SELECT 1 FROM users_blocks ub
+        WHERE ub.blocker_id = @user_id AND ub.blocked_id = t.id
+    ) AS blocking,
+    EXISTS (
+        SELECT 1 FROM users_blocks ub
+        WHERE ub.blocker_id = t.id AND ub.blocked_id = @user_id
+    ) AS blocked_by,
+    EXISTS (
+        SELECT 1 FROM users_mutes um
+        WHERE um.muter_id = @user_id AND um.muted_id = t.id
+    ) AS muting
+FROM unnest(@ids::uuid[]) AS t(id)
+JOIN users u ON u.id = t.id
+WHERE u.deactivated_at IS NULL;
+
+-- name: AddFollowCounts :exec
+UPDATE users
+SET
+    following_count = following_count + CASE WHEN id = @follower_id THEN @delta::bigint ELSE 0 END,
+    followers_count = followers_count + CASE WHEN id = @followee_id THEN @delta::bigint ELSE 0 END
+WHERE id IN (@follower_id, @followee_id);
+
+-- name: AddUserMittsCount :exec
+UPDATE users
+SET mitts_count = mitts_count + @delta::bigint
+WHERE id = @id;
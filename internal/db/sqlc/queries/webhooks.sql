@@ -0,0 +1,92 @@
+-- name: CreateWebhook :one
+INSERT INTO webhooks (
+    user_id, url, secret, event_types
+) VALUES (
+    @user_id, @url, @secret, @event_types
+)
+RETURNING *;
+
+-- name: GetWebhook :one
+SELECT * FROM webhooks
+WHERE id = @id AND user_id = @user_id;
+
+-- name: GetUserWebhooks :many
+SELECT * FROM webhooks
+WHERE user_id = @user_id
+ORDER BY created_at;
+
+-- name: DeleteWebhook :execrows
+DELETE FROM webhooks
+WHERE id = @id AND user_id = @user_id;
+
+-- name: CreateWebhookDeliveries :execrows
+INSERT INTO webhook_deliveries (
+    webhook_id, event_type, payload
+)
+SELECT w.id, @event_type, json_build_object(
+    'event', @event_type::text,
+    'actor', json_build_object('id', a.id, 'login', a.login, 'name', a.name),
+    'mitt_id', sqlc.narg('mitt_id')::uuid,
+    'created_at', NOW()
+)
+FROM webhooks w
+JOIN users r ON r.id = w.user_id
+JOIN users a ON a.id = @actor_id
+WHERE w.user_id = COALESCE(sqlc.narg('user_id')::uuid, (SELECT author FROM mitts WHERE id = sqlc.narg('mitt_id')::uuid))
+  AND (w.user_id <> a.id OR @event_type = 'poll_closed')
+  AND @event_type = ANY(w.event_types)
+  AND r.deactivated_at IS NULL
+  AND NOT EXISTS (
+      SELECT 1 FROM users_blocks b
+      WHERE b.blocker_id = w.user_id AND b.blocked_id = a.id
+  )
+  AND NOT EXISTS (
+      SELECT 1 FROM users_mutes mu
+      WHERE mu.muter_id = w.user_id AND mu.muted_id = a.id
+  );
+
+-- name: GetWebhookDeliveries :many
+SELECT id, webhook_id, event_type, payload, status, attempts, response_code, error, created_at, last_attempt_at, delivered_at
+FROM webhook_deliveries
+WHERE webhook_id = @webhook_id
+ORDER BY created_at DESC
+LIMIT $1 OFFSET $2;
+
+-- name: ClaimWebhookDeliveries :many
+UPDATE webhook_deliveries d
+SET next_attempt_at = NOW() + (@lease_seconds::bigint * INTERVAL '1 second')
+FROM webhooks w
+WHERE w.id = d.webhook_id AND d.id IN (
+    SELECT id
+    FROM webhook_deliveries
+    WHERE status = 'pending' AND next_attempt_at <= NOW()
+    ORDER BY next_attempt_at
+    LIMIT sqlc.arg(batch_size)::int
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING d.id, d.event_type, d.payload, d.attempts, w.url, w.secret;
+
+-- name: RecordWebhookAttempt :exec
+UPDATE webhook_deliveries
+SET
+    attempts = attempts + 1,
+    status = @status,
+    response_code = sqlc.narg('response_code'),
+    error = sqlc.narg('error'),
+    last_attempt_at = NOW(),
+    next_attempt_at = NOW() + (@retry_after_ms::bigint * INTERVAL '1 millisecond'),
+    delivered_at = CASE WHEN @status = 'delivered' THEN NOW() END
+WHERE id = @id;
+
+-- name: RedeliverWebhookDelivery :one
+WITH d AS (
+    SELECT wd.id, wd.status
+    FROM webhook_deliveries wd
+    JOIN webhooks w ON w.id = wd.webhook_id
+    WHERE wd.id = @id AND wd.webhook_id = @webhook_id AND w.user_id = @user_id
+), updated AS (
+    UPDATE webhook_deliveries
+    SET status = 'pending', attempts = 0, next_attempt_at = NOW()
+    WHERE id IN (SELECT id FROM d WHERE status <> 'pending')
+)
+SELECT status FROM d;
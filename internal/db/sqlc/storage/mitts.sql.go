@@ -9,24 +9,42 @@ import (
 	"context"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const addMittLikesCount = `-- name: AddMittLikesCount :exec
+UPDATE mitts
+SET likes_count = likes_count + $1::bigint
+WHERE id = $2
+`
+
+type AddMittLikesCountParams struct {
+	Delta int64
+	ID    uuid.UUID
+}
+
+func (q *Queries) AddMittLikesCount(ctx context.Context, arg AddMittLikesCountParams) error {
+	_, err := q.db.Exec(ctx, addMittLikesCount, arg.Delta, arg.ID)
+	return err
+}
+
 const createMitt = `-- name: CreateMitt :one
 INSERT INTO mitts (
-    author, content
+    author, content, expires_at
 ) VALUES (
-    $1, $2
+    $1, $2, $3
 )
-RETURNING id, author, content, created_at, updated_at
+RETURNING id, author, content, created_at, updated_at, likes_count, revisions_count, version, deleted_at, external_id, expires_at
 `
 
 type CreateMittParams struct {
-	Author  uuid.UUID
-	Content string
+	Author    uuid.UUID
+	Content   string
+	ExpiresAt pgtype.Timestamp
 }
 
 func (q *Queries) CreateMitt(ctx context.Context, arg CreateMittParams) (Mitt, error) {
-	row := q.db.QueryRow(ctx, createMitt, arg.Author, arg.Content)
+	row := q.db.QueryRow(ctx, createMitt, arg.Author, arg.Content, arg.ExpiresAt)
 	var i Mitt
 	err := row.Scan(
 		&i.ID,
@@ -34,21 +52,78 @@ func (q *Queries) CreateMitt(ctx context.Context, arg CreateMittParams) (Mitt, e
 		&i.Content,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.LikesCount,
+		&i.RevisionsCount,
+		&i.Version,
+		&i.DeletedAt,
+		&i.ExternalID,
+		&i.ExpiresAt,
 	)
 	return i, err
 }
 
-const deleteMitt = `-- name: DeleteMitt :exec
+const deleteExpiredMitts = `-- name: DeleteExpiredMitts :many
 DELETE FROM mitts
-WHERE id = $1
+WHERE id IN (
+    SELECT id
+    FROM mitts
+    WHERE expires_at <= NOW()
+    ORDER BY expires_at
+    LIMIT $1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, author, content, created_at, (deleted_at IS NULL)::bool AS active
 `
 
-func (q *Queries) DeleteMitt(ctx context.Context, id uuid.UUID) error {
-	_, err := q.db.Exec(ctx, deleteMitt, id)
-	return err
+type DeleteExpiredMittsRow struct {
+	ID        uuid.UUID
+	Author    uuid.UUID
+	Content   string
+	CreatedAt pgtype.Timestamp
+	Active    bool
 }
 
-const deleteMittLike = `-- name: DeleteMittLike :exec
+func (q *Queries) DeleteExpiredMitts(ctx context.Context, batchSize int32) ([]DeleteExpiredMittsRow, error) {
+	rows, err := q.db.Query(ctx, deleteExpiredMitts, batchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []DeleteExpiredMittsRow
+	for rows.Next() {
+		var i DeleteExpiredMittsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Author,
+			&i.Content,
+			&i.CreatedAt,
+			&i.Active,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const deleteMitt = `-- name: DeleteMitt :one
+UPDATE mitts
+SET deleted_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL AND (expires_at IS NULL OR expires_at > NOW())
+RETURNING author
+`
+
+func (q *Queries) DeleteMitt(ctx context.Context, id uuid.UUID) (uuid.UUID, error) {
+	row := q.db.QueryRow(ctx, deleteMitt, id)
+	var author uuid.UUID
+	err := row.Scan(&author)
+	return author, err
+}
+
+const deleteMittLike = `-- name: DeleteMittLike :execrows
 DELETE FROM mitts_likes
 WHERE user_id = $1 AND mitt_id = $2
 `
@@ -58,14 +133,74 @@ type DeleteMittLikeParams struct {
 	MittID uuid.UUID
 }
 
-func (q *Queries) DeleteMittLike(ctx context.Context, arg DeleteMittLikeParams) error {
-	_, err := q.db.Exec(ctx, deleteMittLike, arg.UserID, arg.MittID)
-	return err
+func (q *Queries) DeleteMittLike(ctx context.Context, arg DeleteMittLikeParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteMittLike, arg.UserID, arg.MittID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteUserLikes = `-- name: DeleteUserLikes :one
+WITH deleted AS (
+    DELETE FROM mitts_likes
+    WHERE id IN (
+        SELECT id
+        FROM mitts_likes
+        WHERE user_id = $1
+        LIMIT $2
+    )
+    RETURNING mitt_id
+), updated AS (
+    UPDATE mitts m
+    SET likes_count = m.likes_count - d.likes
+    FROM (SELECT mitt_id, COUNT(*) AS likes FROM deleted GROUP BY mitt_id) d
+    WHERE m.id = d.mitt_id
+)
+SELECT COUNT(*) FROM deleted
+`
+
+type DeleteUserLikesParams struct {
+	UserID    uuid.UUID
+	BatchSize int32
+}
+
+func (q *Queries) DeleteUserLikes(ctx context.Context, arg DeleteUserLikesParams) (int64, error) {
+	row := q.db.QueryRow(ctx, deleteUserLikes, arg.UserID, arg.BatchSize)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const deleteUserMitts = `-- name: DeleteUserMitts :execrows
+DELETE FROM mitts
+WHERE id IN (
+    SELECT id
+    FROM mitts
+    WHERE author = $1
+    LIMIT $2
+)
+`
+
+type DeleteUserMittsParams struct {
+	Author    uuid.UUID
+	BatchSize int32
+}
+
+func (q *Queries) DeleteUserMitts(ctx context.Context, arg DeleteUserMittsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteUserMitts, arg.Author, arg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
 
 const feed = `-- name: Feed :many
-SELECT id, author, content, created_at, updated_at FROM mitts
-ORDER BY created_at DESC
+SELECT m.id, m.author, m.content, m.created_at, m.updated_at, m.likes_count, m.revisions_count, m.version, m.expires_at, u.name AS author_name
+FROM mitts m
+JOIN users u ON u.id = m.author
+WHERE m.deleted_at IS NULL AND (m.expires_at IS NULL OR m.expires_at > NOW()) AND u.deactivated_at IS NULL
+ORDER BY m.created_at DESC
 LIMIT $1 OFFSET $2
 `
 
@@ -74,21 +209,39 @@ type FeedParams struct {
 	Offset int32
 }
 
-func (q *Queries) Feed(ctx context.Context, arg FeedParams) ([]Mitt, error) {
+type FeedRow struct {
+	ID             uuid.UUID
+	Author         uuid.UUID
+	Content        string
+	CreatedAt      pgtype.Timestamp
+	UpdatedAt      pgtype.Timestamp
+	LikesCount     int64
+	RevisionsCount int32
+	Version        int32
+	ExpiresAt      pgtype.Timestamp
+	AuthorName     string
+}
+
+func (q *Queries) Feed(ctx context.Context, arg FeedParams) ([]FeedRow, error) {
 	rows, err := q.db.Query(ctx, feed, arg.Limit, arg.Offset)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Mitt
+	var items []FeedRow
 	for rows.Next() {
-		var i Mitt
+		var i FeedRow
 		if err := rows.Scan(
 			&i.ID,
 			&i.Author,
 			&i.Content,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.LikesCount,
+			&i.RevisionsCount,
+			&i.Version,
+			&i.ExpiresAt,
+			&i.AuthorName,
 		); err != nil {
 			return nil, err
 		}
@@ -101,9 +254,13 @@ func (q *Queries) Feed(ctx context.Context, arg FeedParams) ([]Mitt, error) {
 }
 
 const getAllUserMitts = `-- name: GetAllUserMitts :many
-SELECT id, author, content, created_at, updated_at FROM mitts
-WHERE author = $3
-ORDER BY created_at
+SELECT m.id, m.author, m.content, m.created_at, m.updated_at, m.likes_count, m.revisions_count, m.version, m.expires_at, u.name AS author_name
+FROM mitts m
+JOIN users u ON u.id = m.author
+LEFT JOIN pinned_mitts p ON p.mitt_id = m.id
+WHERE m.author = $3 AND m.deleted_at IS NULL AND (m.expires_at IS NULL OR m.expires_at > NOW()) AND u.deactivated_at IS NULL
+-- Pinned mitts first, recently pinned first
+ORDER BY p.pinned_at DESC NULLS LAST, m.created_at, m.id
 LIMIT $1 OFFSET $2
 `
 
@@ -113,12 +270,70 @@ type GetAllUserMittsParams struct {
 	Author uuid.UUID
 }
 
-func (q *Queries) GetAllUserMitts(ctx context.Context, arg GetAllUserMittsParams) ([]Mitt, error) {
+type GetAllUserMittsRow struct {
+	ID             uuid.UUID
+	Author         uuid.UUID
+	Content        string
+	CreatedAt      pgtype.Timestamp
+	UpdatedAt      pgtype.Timestamp
+	LikesCount     int64
+	RevisionsCount int32
+	Version        int32
+	ExpiresAt      pgtype.Timestamp
+	AuthorName     string
+}
+
+func (q *Queries) GetAllUserMitts(ctx context.Context, arg GetAllUserMittsParams) ([]GetAllUserMittsRow, error) {
 	rows, err := q.db.Query(ctx, getAllUserMitts, arg.Limit, arg.Offset, arg.Author)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
+	var items []GetAllUserMittsRow
+	for rows.Next() {
+		var i GetAllUserMittsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Author,
+			&i.Content,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.LikesCount,
+			&i.RevisionsCount,
+			&i.Version,
+			&i.ExpiresAt,
+			&i.AuthorName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getDeletedUserMitts = `-- name: GetDeletedUserMitts :many
+SELECT id, author, content, created_at, updated_at, likes_count, revisions_count, version, deleted_at, external_id, expires_at
+FROM mitts
+WHERE author = $3 AND deleted_at IS NOT NULL AND (expires_at IS NULL OR expires_at > NOW())
+ORDER BY deleted_at DESC, id
+LIMIT $1 OFFSET $2
+`
+
+type GetDeletedUserMittsParams struct {
+	Limit  int32
+	Offset int32
+	Author uuid.UUID
+}
+
+func (q *Queries) GetDeletedUserMitts(ctx context.Context, arg GetDeletedUserMittsParams) ([]Mitt, error) {
+	rows, err := q.db.Query(ctx, getDeletedUserMitts, arg.Limit, arg.Offset, arg.Author)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
 	var items []Mitt
 	for rows.Next() {
 		var i Mitt
@@ -128,6 +343,12 @@ func (q *Queries) GetAllUserMitts(ctx context.Context, arg GetAllUserMittsParams
 			&i.Content,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.LikesCount,
+			&i.RevisionsCount,
+			&i.Version,
+			&i.DeletedAt,
+			&i.ExternalID,
+			&i.ExpiresAt,
 		); err != nil {
 			return nil, err
 		}
@@ -140,34 +361,187 @@ func (q *Queries) GetAllUserMitts(ctx context.Context, arg GetAllUserMittsParams
 }
 
 const getMitt = `-- name: GetMitt :one
-SELECT id, author, content, created_at, updated_at FROM mitts
-WHERE id = $1
+SELECT m.id, m.author, m.content, m.created_at, m.updated_at, m.likes_count, m.revisions_count, m.version, m.expires_at, u.name AS author_name
+FROM mitts m
+JOIN users u ON u.id = m.author
+WHERE m.id = $1 AND m.deleted_at IS NULL AND (m.expires_at IS NULL OR m.expires_at > NOW()) AND u.deactivated_at IS NULL
 LIMIT 1
 `
 
-func (q *Queries) GetMitt(ctx context.Context, id uuid.UUID) (Mitt, error) {
+type GetMittRow struct {
+	ID             uuid.UUID
+	Author         uuid.UUID
+	Content        string
+	CreatedAt      pgtype.Timestamp
+	UpdatedAt      pgtype.Timestamp
+	LikesCount     int64
+	RevisionsCount int32
+	Version        int32
+	ExpiresAt      pgtype.Timestamp
+	AuthorName     string
+}
+
+func (q *Queries) GetMitt(ctx context.Context, id uuid.UUID) (GetMittRow, error) {
 	row := q.db.QueryRow(ctx, getMitt, id)
-	var i Mitt
+	var i GetMittRow
 	err := row.Scan(
 		&i.ID,
 		&i.Author,
 		&i.Content,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.LikesCount,
+		&i.RevisionsCount,
+		&i.Version,
+		&i.ExpiresAt,
+		&i.AuthorName,
 	)
 	return i, err
 }
 
-const getMittLikesCount = `-- name: GetMittLikesCount :one
-SELECT COUNT(*) FROM mitts_likes
-WHERE mitt_id = $1
+const getMittRevisions = `-- name: GetMittRevisions :many
+SELECT id, mitt_id, content, created_at FROM mitt_revisions
+WHERE mitt_id = $3
+ORDER BY created_at DESC
+LIMIT $1 OFFSET $2
 `
 
-func (q *Queries) GetMittLikesCount(ctx context.Context, mittID uuid.UUID) (int64, error) {
-	row := q.db.QueryRow(ctx, getMittLikesCount, mittID)
-	var count int64
-	err := row.Scan(&count)
-	return count, err
+type GetMittRevisionsParams struct {
+	Limit  int32
+	Offset int32
+	MittID uuid.UUID
+}
+
+func (q *Queries) GetMittRevisions(ctx context.Context, arg GetMittRevisionsParams) ([]MittRevision, error) {
+	rows, err := q.db.Query(ctx, getMittRevisions, arg.Limit, arg.Offset, arg.MittID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []MittRevision
+	for rows.Next() {
+		var i MittRevision
+		if err := rows.Scan(
+			&i.ID,
+			&i.MittID,
+			&i.Content,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getMittsLikesCounts = `-- name: GetMittsLikesCounts :many
+SELECT mitt_id, COUNT(*) AS likes
+FROM mitts_likes
+WHERE mitt_id = ANY($1::uuid[])
+GROUP BY mitt_id
+`
+
+type GetMittsLikesCountsRow struct {
+	MittID uuid.UUID
+	Likes  int64
+}
+
+func (q *Queries) GetMittsLikesCounts(ctx context.Context, mittIds []uuid.UUID) ([]GetMittsLikesCountsRow, error) {
+	rows, err := q.db.Query(ctx, getMittsLikesCounts, mittIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetMittsLikesCountsRow
+	for rows.Next() {
+		var i GetMittsLikesCountsRow
+		if err := rows.Scan(
+			&i.MittID,
+			&i.Likes,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getMittsRevisions = `-- name: GetMittsRevisions :many
+SELECT id, mitt_id, content, created_at FROM mitt_revisions
+WHERE mitt_id = ANY($1::uuid[])
+ORDER BY mitt_id, created_at DESC
+`
+
+func (q *Queries) GetMittsRevisions(ctx context.Context, mittIds []uuid.UUID) ([]MittRevision, error) {
+	rows, err := q.db.Query(ctx, getMittsRevisions, mittIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []MittRevision
+	for rows.Next() {
+		var i MittRevision
+		if err := rows.Scan(
+			&i.ID,
+			&i.MittID,
+			&i.Content,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserLikes = `-- name: GetUserLikes :many
+SELECT mitt_id, liked_at FROM mitts_likes
+WHERE user_id = $3
+ORDER BY liked_at, id
+LIMIT $1 OFFSET $2
+`
+
+type GetUserLikesParams struct {
+	Limit  int32
+	Offset int32
+	UserID uuid.UUID
+}
+
+type GetUserLikesRow struct {
+	MittID  uuid.UUID
+	LikedAt pgtype.Timestamp
+}
+
+func (q *Queries) GetUserLikes(ctx context.Context, arg GetUserLikesParams) ([]GetUserLikesRow, error) {
+	rows, err := q.db.Query(ctx, getUserLikes, arg.Limit, arg.Offset, arg.UserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetUserLikesRow
+	for rows.Next() {
+		var i GetUserLikesRow
+		if err := rows.Scan(
+			&i.MittID,
+			&i.LikedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
 const isMittLikedByUser = `-- name: IsMittLikedByUser :one
@@ -187,12 +561,14 @@ func (q *Queries) IsMittLikedByUser(ctx context.Context, arg IsMittLikedByUserPa
 	return column_1, err
 }
 
-const likeMitt = `-- name: LikeMitt :exec
+const likeMitt = `-- name: LikeMitt :execrows
 INSERT INTO mitts_likes (
     user_id, mitt_id
-) VALUES (
-    $1, $2
 )
+SELECT $1, id
+FROM mitts
+WHERE id = $2 AND deleted_at IS NULL AND (expires_at IS NULL OR expires_at > NOW())
+ON CONFLICT (user_id, mitt_id) DO NOTHING
 `
 
 type LikeMittParams struct {
@@ -200,8 +576,72 @@ type LikeMittParams struct {
 	MittID uuid.UUID
 }
 
-func (q *Queries) LikeMitt(ctx context.Context, arg LikeMittParams) error {
-	_, err := q.db.Exec(ctx, likeMitt, arg.UserID, arg.MittID)
+func (q *Queries) LikeMitt(ctx context.Context, arg LikeMittParams) (int64, error) {
+	result, err := q.db.Exec(ctx, likeMitt, arg.UserID, arg.MittID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const purgeDeletedMitts = `-- name: PurgeDeletedMitts :execrows
+DELETE FROM mitts
+WHERE id IN (
+    SELECT id
+    FROM mitts
+    WHERE deleted_at < NOW() - ($1::bigint * INTERVAL '1 second')
+    LIMIT $2
+)
+`
+
+type PurgeDeletedMittsParams struct {
+	RetentionSeconds int64
+	BatchSize        int32
+}
+
+func (q *Queries) PurgeDeletedMitts(ctx context.Context, arg PurgeDeletedMittsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, purgeDeletedMitts, arg.RetentionSeconds, arg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const restoreMitt = `-- name: RestoreMitt :one
+UPDATE mitts
+SET deleted_at = NULL
+WHERE id = $1 AND author = $2
+  AND deleted_at > NOW() - ($3::bigint * INTERVAL '1 second')
+  AND (expires_at IS NULL OR expires_at > NOW())
+RETURNING author
+`
+
+type RestoreMittParams struct {
+	ID               uuid.UUID
+	Author           uuid.UUID
+	RetentionSeconds int64
+}
+
+func (q *Queries) RestoreMitt(ctx context.Context, arg RestoreMittParams) (uuid.UUID, error) {
+	row := q.db.QueryRow(ctx, restoreMitt, arg.ID, arg.Author, arg.RetentionSeconds)
+	var author uuid.UUID
+	err := row.Scan(&author)
+	return author, err
+}
+
+const saveMittRevision = `-- name: SaveMittRevision :exec
+INSERT INTO mitt_revisions (
+    mitt_id, content, created_at
+)
+SELECT id, content, updated_at
+FROM mitts
+WHERE id = $1
+-- Row stays locked until update in the same transaction, so concurrent edit can't slip in between
+FOR UPDATE
+`
+
+func (q *Queries) SaveMittRevision(ctx context.Context, mittID uuid.UUID) error {
+	_, err := q.db.Exec(ctx, saveMittRevision, mittID)
 	return err
 }
 
@@ -209,18 +649,21 @@ const updateMitt = `-- name: UpdateMitt :one
 UPDATE mitts
 SET
     content = $1,
-    updated_at = NOW()
-WHERE id = $2
-RETURNING id, author, content, created_at, updated_at
+    updated_at = NOW(),
+    revisions_count = revisions_count + 1,
+    version = version + 1
+WHERE id = $2 AND deleted_at IS NULL AND (expires_at IS NULL OR expires_at > NOW()) AND version = COALESCE($3::int, version)
+RETURNING id, author, content, created_at, updated_at, likes_count, revisions_count, version, deleted_at, external_id, expires_at
 `
 
 type UpdateMittParams struct {
-	Content string
-	ID      uuid.UUID
+	Content         string
+	ID              uuid.UUID
+	ExpectedVersion pgtype.Int4
 }
 
 func (q *Queries) UpdateMitt(ctx context.Context, arg UpdateMittParams) (Mitt, error) {
-	row := q.db.QueryRow(ctx, updateMitt, arg.Content, arg.ID)
+	row := q.db.QueryRow(ctx, updateMitt, arg.Content, arg.ID, arg.ExpectedVersion)
 	var i Mitt
 	err := row.Scan(
 		&i.ID,
@@ -228,6 +671,12 @@ func (q *Queries) UpdateMitt(ctx context.Context, arg UpdateMittParams) (Mitt, e
 		&i.Content,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.LikesCount,
+		&i.RevisionsCount,
+		&i.Version,
+		&i.DeletedAt,
+		&i.ExternalID,
+		&i.ExpiresAt,
 	)
 	return i, err
 }
@@ -9,12 +9,134 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type Bookmark struct {
+	UserID    uuid.UUID
+	MittID    uuid.UUID
+	FolderID  pgtype.UUID
+	CreatedAt pgtype.Timestamp
+}
+
+type BookmarkFolder struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	Name      string
+	CreatedAt pgtype.Timestamp
+}
+
+type Conversation struct {
+	ID            uuid.UUID
+	DirectKey     pgtype.Text
+	CreatedAt     pgtype.Timestamp
+	LastMessageAt pgtype.Timestamp
+}
+
+type ConversationMember struct {
+	ConversationID uuid.UUID
+	UserID         uuid.UUID
+	JoinedAt       pgtype.Timestamp
+	LastReadAt     pgtype.Timestamp
+}
+
+type DataExport struct {
+	ID            uuid.UUID
+	UserID        uuid.UUID
+	Status        string
+	Error         pgtype.Text
+	DownloadToken pgtype.UUID
+	CreatedAt     pgtype.Timestamp
+	StartedAt     pgtype.Timestamp
+	FinishedAt    pgtype.Timestamp
+	ExpiresAt     pgtype.Timestamp
+}
+
+type DataExportChunk struct {
+	ExportID uuid.UUID
+	Seq      int32
+	Data     []byte
+}
+
+type Hashtag struct {
+	ID        uuid.UUID
+	Name      string
+	CreatedAt pgtype.Timestamp
+}
+
+type Message struct {
+	ID             uuid.UUID
+	ConversationID uuid.UUID
+	SenderID       uuid.UUID
+	Content        string
+	CreatedAt      pgtype.Timestamp
+}
+
 type Mitt struct {
+	ID             uuid.UUID
+	Author         uuid.UUID
+	Content        string
+	CreatedAt      pgtype.Timestamp
+	UpdatedAt      pgtype.Timestamp
+	LikesCount     int64
+	RevisionsCount int32
+	Version        int32
+	DeletedAt      pgtype.Timestamp
+	ExternalID     pgtype.Text
+	ExpiresAt      pgtype.Timestamp
+}
+
+type MittDraft struct {
+	ID           uuid.UUID
+	Author       uuid.UUID
+	Content      string
+	PublishAt    pgtype.Timestamp
+	ClaimedUntil pgtype.Timestamp
+	Version      int32
+	CreatedAt    pgtype.Timestamp
+	UpdatedAt    pgtype.Timestamp
+}
+
+type MittHashtag struct {
+	MittID    uuid.UUID
+	HashtagID uuid.UUID
+}
+
+type MittImport struct {
+	ID         uuid.UUID
+	UserID     uuid.UUID
+	Status     string
+	Error      pgtype.Text
+	Total      int32
+	Processed  int32
+	Imported   int32
+	Skipped    int32
+	Failed     int32
+	CreatedAt  pgtype.Timestamp
+	UpdatedAt  pgtype.Timestamp
+	FinishedAt pgtype.Timestamp
+}
+
+type MittImportError struct {
+	ID         uuid.UUID
+	ImportID   uuid.UUID
+	ItemIndex  int32
+	ExternalID string
+	Error      string
+}
+
+type MittImportItem struct {
+	ImportID uuid.UUID
+	Items    []byte
+}
+
+type MittMention struct {
+	MittID uuid.UUID
+	UserID uuid.UUID
+}
+
+type MittRevision struct {
 	ID        uuid.UUID
-	Author    uuid.UUID
+	MittID    uuid.UUID
 	Content   string
 	CreatedAt pgtype.Timestamp
-	UpdatedAt pgtype.Timestamp
 }
 
 type MittsLike struct {
@@ -24,11 +146,61 @@ type MittsLike struct {
 	LikedAt pgtype.Timestamp
 }
 
+type Notification struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	ActorID   uuid.UUID
+	Type      string
+	MittID    pgtype.UUID
+	CreatedAt pgtype.Timestamp
+	ReadAt    pgtype.Timestamp
+}
+
+type NotificationPreference struct {
+	UserID  uuid.UUID
+	Type    string
+	Enabled bool
+}
+
+type PinnedMitt struct {
+	MittID   uuid.UUID
+	UserID   uuid.UUID
+	PinnedAt pgtype.Timestamp
+}
+
+type Poll struct {
+	MittID         uuid.UUID
+	Options        []string
+	MultipleChoice bool
+	ExpiresAt      pgtype.Timestamp
+	ClosedAt       pgtype.Timestamp
+}
+
+type PollVote struct {
+	MittID    uuid.UUID
+	UserID    uuid.UUID
+	Choices   []int16
+	CreatedAt pgtype.Timestamp
+}
+
 type User struct {
-	ID       uuid.UUID
-	Login    string
-	Name     string
-	Password string
+	ID                        uuid.UUID
+	Login                     string
+	Name                      string
+	Password                  string
+	IsPrivate                 bool
+	FollowersCount            int64
+	FollowingCount            int64
+	MittsCount                int64
+	Version                   int32
+	DeactivatedAt             pgtype.Timestamp
+	MessagesFromFollowingOnly bool
+}
+
+type UsersBlock struct {
+	ID        uuid.UUID
+	BlockerID uuid.UUID
+	BlockedID uuid.UUID
 }
 
 type UsersFollow struct {
@@ -36,3 +208,33 @@ type UsersFollow struct {
 	FollowerID uuid.UUID
 	FolloweeID uuid.UUID
 }
+
+type UsersMute struct {
+	ID      uuid.UUID
+	MuterID uuid.UUID
+	MutedID uuid.UUID
+}
+
+type Webhook struct {
+	ID         uuid.UUID
+	UserID     uuid.UUID
+	Url        string
+	Secret     string
+	EventTypes []string
+	CreatedAt  pgtype.Timestamp
+}
+
+type WebhookDelivery struct {
+	ID            uuid.UUID
+	WebhookID     uuid.UUID
+	EventType     string
+	Payload       []byte
+	Status        string
+	Attempts      int32
+	ResponseCode  pgtype.Int4
+	Error         pgtype.Text
+	NextAttemptAt pgtype.Timestamp
+	CreatedAt     pgtype.Timestamp
+	LastAttemptAt pgtype.Timestamp
+	DeliveredAt   pgtype.Timestamp
+}
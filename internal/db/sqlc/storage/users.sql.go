@@ -12,6 +12,60 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const addFollowCounts = `-- name: AddFollowCounts :exec
+UPDATE users
+SET
+    following_count = following_count + CASE WHEN id = $1 THEN $2::bigint ELSE 0 END,
+    followers_count = followers_count + CASE WHEN id = $3 THEN $2::bigint ELSE 0 END
+WHERE id IN ($1, $3)
+`
+
+type AddFollowCountsParams struct {
+	FollowerID uuid.UUID
+	Delta      int64
+	FolloweeID uuid.UUID
+}
+
+func (q *Queries) AddFollowCounts(ctx context.Context, arg AddFollowCountsParams) error {
+	_, err := q.db.Exec(ctx, addFollowCounts, arg.FollowerID, arg.Delta, arg.FolloweeID)
+	return err
+}
+
+const addUserMittsCount = `-- name: AddUserMittsCount :exec
+UPDATE users
+SET mitts_count = mitts_count + $1::bigint
+WHERE id = $2
+`
+
+type AddUserMittsCountParams struct {
+	Delta int64
+	ID    uuid.UUID
+}
+
+func (q *Queries) AddUserMittsCount(ctx context.Context, arg AddUserMittsCountParams) error {
+	_, err := q.db.Exec(ctx, addUserMittsCount, arg.Delta, arg.ID)
+	return err
+}
+
+const blockUser = `-- name: BlockUser :exec
+INSERT INTO users_blocks (
+    blocker_id, blocked_id
+) VALUES (
+    $1, $2
+)
+ON CONFLICT (blocker_id, blocked_id) DO NOTHING
+`
+
+type BlockUserParams struct {
+	BlockerID uuid.UUID
+	BlockedID uuid.UUID
+}
+
+func (q *Queries) BlockUser(ctx context.Context, arg BlockUserParams) error {
+	_, err := q.db.Exec(ctx, blockUser, arg.BlockerID, arg.BlockedID)
+	return err
+}
+
 const createUser = `-- name: CreateUser :one
 INSERT INTO users (login, name, password) VALUES ($1, $2, $3)
 RETURNING id
@@ -30,8 +84,62 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (uuid.UU
 	return id, err
 }
 
+const deactivateUser = `-- name: DeactivateUser :execrows
+UPDATE users
+SET deactivated_at = NOW()
+WHERE id = $1 AND deactivated_at IS NULL
+`
+
+func (q *Queries) DeactivateUser(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, deactivateUser, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteFollowsBetween = `-- name: DeleteFollowsBetween :many
+DELETE FROM users_follows
+WHERE (follower_id = $1 AND followee_id = $2) OR
+      (follower_id = $2 AND followee_id = $1)
+RETURNING follower_id, followee_id
+`
+
+type DeleteFollowsBetweenParams struct {
+	FirstID  uuid.UUID
+	SecondID uuid.UUID
+}
+
+type DeleteFollowsBetweenRow struct {
+	FollowerID uuid.UUID
+	FolloweeID uuid.UUID
+}
+
+func (q *Queries) DeleteFollowsBetween(ctx context.Context, arg DeleteFollowsBetweenParams) ([]DeleteFollowsBetweenRow, error) {
+	rows, err := q.db.Query(ctx, deleteFollowsBetween, arg.FirstID, arg.SecondID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []DeleteFollowsBetweenRow
+	for rows.Next() {
+		var i DeleteFollowsBetweenRow
+		if err := rows.Scan(
+			&i.FollowerID,
+			&i.FolloweeID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const deleteUser = `-- name: DeleteUser :exec
-DELETE FROM users WHERE id = $1
+DELETE FROM users WHERE id = $1 AND deactivated_at IS NOT NULL
 `
 
 func (q *Queries) DeleteUser(ctx context.Context, id uuid.UUID) error {
@@ -39,6 +147,104 @@ func (q *Queries) DeleteUser(ctx context.Context, id uuid.UUID) error {
 	return err
 }
 
+const deleteUserBlocks = `-- name: DeleteUserBlocks :exec
+DELETE FROM users_blocks
+WHERE blocker_id = $1 OR blocked_id = $1
+`
+
+func (q *Queries) DeleteUserBlocks(ctx context.Context, userID uuid.UUID) error {
+	_, err := q.db.Exec(ctx, deleteUserBlocks, userID)
+	return err
+}
+
+const deleteUserFollows = `-- name: DeleteUserFollows :one
+WITH deleted AS (
+    DELETE FROM users_follows
+    WHERE id IN (
+        SELECT id
+        FROM users_follows
+        WHERE follower_id = $1 OR followee_id = $1
+        LIMIT $2
+    )
+    RETURNING follower_id, followee_id
+), updated AS (
+    UPDATE users u
+    SET
+        following_count = u.following_count - (SELECT COUNT(*) FROM deleted d WHERE d.follower_id = u.id),
+        followers_count = u.followers_count - (SELECT COUNT(*) FROM deleted d WHERE d.followee_id = u.id)
+    WHERE u.id IN (SELECT follower_id FROM deleted UNION SELECT followee_id FROM deleted)
+)
+SELECT COUNT(*) FROM deleted
+`
+
+type DeleteUserFollowsParams struct {
+	UserID    uuid.UUID
+	BatchSize int32
+}
+
+func (q *Queries) DeleteUserFollows(ctx context.Context, arg DeleteUserFollowsParams) (int64, error) {
+	row := q.db.QueryRow(ctx, deleteUserFollows, arg.UserID, arg.BatchSize)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const deleteUserMutes = `-- name: DeleteUserMutes :exec
+DELETE FROM users_mutes
+WHERE muter_id = $1 OR muted_id = $1
+`
+
+func (q *Queries) DeleteUserMutes(ctx context.Context, userID uuid.UUID) error {
+	_, err := q.db.Exec(ctx, deleteUserMutes, userID)
+	return err
+}
+
+const filterSuggestable = `-- name: FilterSuggestable :many
+SELECT t.id
+FROM unnest($1::uuid[]) WITH ORDINALITY AS t(id, ord)
+JOIN users u ON u.id = t.id
+WHERE u.deactivated_at IS NULL
+  AND NOT EXISTS (
+      SELECT 1 FROM users_follows f
+      WHERE f.follower_id = $2 AND f.followee_id = t.id
+  )
+  AND NOT EXISTS (
+      SELECT 1 FROM users_blocks ub
+      WHERE (ub.blocker_id = $2 AND ub.blocked_id = t.id) OR
+            (ub.blocker_id = t.id AND ub.blocked_id = $2)
+  )
+  AND NOT EXISTS (
+      SELECT 1 FROM users_mutes um
+      WHERE um.muter_id = $2 AND um.muted_id = t.id
+  )
+ORDER BY t.ord
+`
+
+type FilterSuggestableParams struct {
+	CandidateIds []uuid.UUID
+	UserID       uuid.UUID
+}
+
+func (q *Queries) FilterSuggestable(ctx context.Context, arg FilterSuggestableParams) ([]uuid.UUID, error) {
+	rows, err := q.db.Query(ctx, filterSuggestable, arg.CandidateIds, arg.UserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const followUser = `-- name: FollowUser :exec
 INSERT INTO users_follows (
     follower_id, followee_id
@@ -68,8 +274,190 @@ func (q *Queries) GetCurrentPasswordHash(ctx context.Context, id uuid.UUID) (str
 	return password, err
 }
 
+const getHiddenUserIDs = `-- name: GetHiddenUserIDs :many
+SELECT blocked_id AS id FROM users_blocks
+WHERE blocker_id = $1
+UNION
+SELECT blocker_id FROM users_blocks
+WHERE blocked_id = $1
+UNION
+SELECT muted_id FROM users_mutes
+WHERE muter_id = $1
+`
+
+func (q *Queries) GetHiddenUserIDs(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := q.db.Query(ctx, getHiddenUserIDs, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getKnownFollowers = `-- name: GetKnownFollowers :many
+SELECT f.follower_id
+FROM users_follows f
+JOIN users_follows mine ON mine.followee_id = f.follower_id AND mine.follower_id = $3
+WHERE f.followee_id = $4
+LIMIT $1 OFFSET $2
+`
+
+type GetKnownFollowersParams struct {
+	Limit    int32
+	Offset   int32
+	ViewerID uuid.UUID
+	UserID   uuid.UUID
+}
+
+func (q *Queries) GetKnownFollowers(ctx context.Context, arg GetKnownFollowersParams) ([]uuid.UUID, error) {
+	rows, err := q.db.Query(ctx, getKnownFollowers, arg.Limit, arg.Offset, arg.ViewerID, arg.UserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var follower_id uuid.UUID
+		if err := rows.Scan(&follower_id); err != nil {
+			return nil, err
+		}
+		items = append(items, follower_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPopularUsers = `-- name: GetPopularUsers :many
+SELECT u.id
+FROM users u
+WHERE u.id <> $2
+  AND NOT EXISTS (
+      SELECT 1 FROM users_follows f
+      WHERE f.follower_id = $2 AND f.followee_id = u.id
+  )
+  AND NOT EXISTS (
+      SELECT 1 FROM users_blocks ub
+      WHERE (ub.blocker_id = $2 AND ub.blocked_id = u.id) OR
+            (ub.blocker_id = u.id AND ub.blocked_id = $2)
+  )
+  AND NOT EXISTS (
+      SELECT 1 FROM users_mutes um
+      WHERE um.muter_id = $2 AND um.muted_id = u.id
+  )
+  AND u.deactivated_at IS NULL
+ORDER BY u.followers_count DESC, u.id
+LIMIT $1
+`
+
+type GetPopularUsersParams struct {
+	Limit  int32
+	UserID uuid.UUID
+}
+
+func (q *Queries) GetPopularUsers(ctx context.Context, arg GetPopularUsersParams) ([]uuid.UUID, error) {
+	rows, err := q.db.Query(ctx, getPopularUsers, arg.Limit, arg.UserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRelationships = `-- name: GetRelationships :many
+SELECT
+    t.id,
+    EXISTS (
+        SELECT 1 FROM users_follows f
+        WHERE f.follower_id = $1 AND f.followee_id = t.id
+    ) AS following,
+    EXISTS (
+        SELECT 1 FROM users_follows f
+        WHERE f.follower_id = t.id AND f.followee_id = $1
+    ) AS followed_by,
+    EXISTS (
+        SELECT 1 FROM users_blocks ub
+        WHERE ub.blocker_id = $1 AND ub.blocked_id = t.id
+    ) AS blocking,
+    EXISTS (
+        SELECT 1 FROM users_blocks ub
+        WHERE ub.blocker_id = t.id AND ub.blocked_id = $1
+    ) AS blocked_by,
+    EXISTS (
+        SELECT 1 FROM users_mutes um
+        WHERE um.muter_id = $1 AND um.muted_id = t.id
+    ) AS muting
+FROM unnest($2::uuid[]) AS t(id)
+JOIN users u ON u.id = t.id
+WHERE u.deactivated_at IS NULL
+`
+
+type GetRelationshipsParams struct {
+	UserID uuid.UUID
+	Ids    []uuid.UUID
+}
+
+type GetRelationshipsRow struct {
+	ID         uuid.UUID
+	Following  bool
+	FollowedBy bool
+	Blocking   bool
+	BlockedBy  bool
+	Muting     bool
+}
+
+func (q *Queries) GetRelationships(ctx context.Context, arg GetRelationshipsParams) ([]GetRelationshipsRow, error) {
+	rows, err := q.db.Query(ctx, getRelationships, arg.UserID, arg.Ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetRelationshipsRow
+	for rows.Next() {
+		var i GetRelationshipsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Following,
+			&i.FollowedBy,
+			&i.Blocking,
+			&i.BlockedBy,
+			&i.Muting,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getUserByID = `-- name: GetUserByID :one
-SELECT id, login, name, password FROM users WHERE id = $1
+SELECT id, login, name, password, is_private, followers_count, following_count, mitts_count, version, deactivated_at, messages_from_following_only FROM users WHERE id = $1 AND deactivated_at IS NULL
 LIMIT 1
 `
 
@@ -81,12 +469,19 @@ func (q *Queries) GetUserByID(ctx context.Context, id uuid.UUID) (User, error) {
 		&i.Login,
 		&i.Name,
 		&i.Password,
+		&i.IsPrivate,
+		&i.FollowersCount,
+		&i.FollowingCount,
+		&i.MittsCount,
+		&i.Version,
+		&i.DeactivatedAt,
+		&i.MessagesFromFollowingOnly,
 	)
 	return i, err
 }
 
 const getUserByLogin = `-- name: GetUserByLogin :one
-SELECT id, login, name, password FROM users WHERE login = $1
+SELECT id, login, name, password, is_private, followers_count, following_count, mitts_count, version, deactivated_at, messages_from_following_only FROM users WHERE login = $1
 LIMIT 1
 `
 
@@ -98,6 +493,13 @@ func (q *Queries) GetUserByLogin(ctx context.Context, login string) (User, error
 		&i.Login,
 		&i.Name,
 		&i.Password,
+		&i.IsPrivate,
+		&i.FollowersCount,
+		&i.FollowingCount,
+		&i.MittsCount,
+		&i.Version,
+		&i.DeactivatedAt,
+		&i.MessagesFromFollowingOnly,
 	)
 	return i, err
 }
@@ -105,6 +507,7 @@ func (q *Queries) GetUserByLogin(ctx context.Context, login string) (User, error
 const getUserFollowers = `-- name: GetUserFollowers :many
 SELECT follower_id FROM users_follows
 WHERE followee_id = $3
+ORDER BY id
 LIMIT $1 OFFSET $2
 `
 
@@ -137,6 +540,7 @@ func (q *Queries) GetUserFollowers(ctx context.Context, arg GetUserFollowersPara
 const getUserFollows = `-- name: GetUserFollows :many
 SELECT followee_id FROM users_follows
 WHERE follower_id = $3
+ORDER BY id
 LIMIT $1 OFFSET $2
 `
 
@@ -171,6 +575,7 @@ SELECT uf1.followee_id
 FROM users_follows uf1
 JOIN users_follows uf2 ON uf1.follower_id = uf2.followee_id AND uf1.followee_id = uf2.follower_id
 WHERE uf1.follower_id = $3
+ORDER BY uf1.id
 LIMIT $1 OFFSET $2
 `
 
@@ -200,7 +605,268 @@ func (q *Queries) GetUserFriends(ctx context.Context, arg GetUserFriendsParams)
 	return items, nil
 }
 
-const unfollowUser = `-- name: UnfollowUser :exec
+const getUsersByIDs = `-- name: GetUsersByIDs :many
+SELECT id, login, name, password, is_private, followers_count, following_count, mitts_count, version, deactivated_at, messages_from_following_only FROM users WHERE id = ANY($1::uuid[]) AND deactivated_at IS NULL
+`
+
+func (q *Queries) GetUsersByIDs(ctx context.Context, ids []uuid.UUID) ([]User, error) {
+	rows, err := q.db.Query(ctx, getUsersByIDs, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []User
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.Login,
+			&i.Name,
+			&i.Password,
+			&i.IsPrivate,
+			&i.FollowersCount,
+			&i.FollowingCount,
+			&i.MittsCount,
+			&i.Version,
+			&i.DeactivatedAt,
+			&i.MessagesFromFollowingOnly,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUsersToPurge = `-- name: GetUsersToPurge :many
+SELECT id FROM users
+WHERE deactivated_at < NOW() - ($2::bigint * INTERVAL '1 second')
+ORDER BY deactivated_at
+LIMIT $1
+`
+
+type GetUsersToPurgeParams struct {
+	Limit              int32
+	GracePeriodSeconds int64
+}
+
+func (q *Queries) GetUsersToPurge(ctx context.Context, arg GetUsersToPurgeParams) ([]uuid.UUID, error) {
+	rows, err := q.db.Query(ctx, getUsersToPurge, arg.Limit, arg.GracePeriodSeconds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const isBlockedBetween = `-- name: IsBlockedBetween :one
+SELECT EXISTS (
+    SELECT 1 FROM users_blocks
+    WHERE (blocker_id = $1 AND blocked_id = $2) OR
+          (blocker_id = $2 AND blocked_id = $1)
+)
+`
+
+type IsBlockedBetweenParams struct {
+	FirstID  uuid.UUID
+	SecondID uuid.UUID
+}
+
+func (q *Queries) IsBlockedBetween(ctx context.Context, arg IsBlockedBetweenParams) (bool, error) {
+	row := q.db.QueryRow(ctx, isBlockedBetween, arg.FirstID, arg.SecondID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const isFollowing = `-- name: IsFollowing :one
+SELECT EXISTS (
+    SELECT 1 FROM users_follows
+    WHERE follower_id = $1 AND followee_id = $2
+)
+`
+
+type IsFollowingParams struct {
+	FollowerID uuid.UUID
+	FolloweeID uuid.UUID
+}
+
+func (q *Queries) IsFollowing(ctx context.Context, arg IsFollowingParams) (bool, error) {
+	row := q.db.QueryRow(ctx, isFollowing, arg.FollowerID, arg.FolloweeID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const listUserIDs = `-- name: ListUserIDs :many
+SELECT id FROM users
+WHERE id > $2
+ORDER BY id
+LIMIT $1
+`
+
+type ListUserIDsParams struct {
+	Limit int32
+	After uuid.UUID
+}
+
+func (q *Queries) ListUserIDs(ctx context.Context, arg ListUserIDsParams) ([]uuid.UUID, error) {
+	rows, err := q.db.Query(ctx, listUserIDs, arg.Limit, arg.After)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const muteUser = `-- name: MuteUser :exec
+INSERT INTO users_mutes (
+    muter_id, muted_id
+) VALUES (
+    $1, $2
+)
+ON CONFLICT (muter_id, muted_id) DO NOTHING
+`
+
+type MuteUserParams struct {
+	MuterID uuid.UUID
+	MutedID uuid.UUID
+}
+
+func (q *Queries) MuteUser(ctx context.Context, arg MuteUserParams) error {
+	_, err := q.db.Exec(ctx, muteUser, arg.MuterID, arg.MutedID)
+	return err
+}
+
+const reactivateUser = `-- name: ReactivateUser :execrows
+UPDATE users
+SET deactivated_at = NULL
+WHERE id = $1
+  AND deactivated_at > NOW() - ($2::bigint * INTERVAL '1 second')
+`
+
+type ReactivateUserParams struct {
+	ID                 uuid.UUID
+	GracePeriodSeconds int64
+}
+
+func (q *Queries) ReactivateUser(ctx context.Context, arg ReactivateUserParams) (int64, error) {
+	result, err := q.db.Exec(ctx, reactivateUser, arg.ID, arg.GracePeriodSeconds)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const suggestFollows = `-- name: SuggestFollows :many
+WITH my_follows AS (
+    SELECT followee_id FROM users_follows
+    WHERE follower_id = $2
+),
+my_friends AS (
+    SELECT uf1.followee_id AS friend_id
+    FROM users_follows uf1
+    JOIN users_follows uf2 ON uf1.follower_id = uf2.followee_id AND uf1.followee_id = uf2.follower_id
+    WHERE uf1.follower_id = $2
+),
+-- Deactivated users are left out here, so they don't take places in the top
+candidates AS (
+    SELECT uf.followee_id AS candidate_id, 1 AS weight
+    FROM users_follows uf
+    JOIN users u ON u.id = uf.followee_id
+    WHERE uf.follower_id IN (SELECT followee_id FROM my_follows) AND u.deactivated_at IS NULL
+    UNION ALL
+    SELECT uf1.followee_id AS candidate_id, 2 AS weight
+    FROM users_follows uf1
+    JOIN users_follows uf2 ON uf1.follower_id = uf2.followee_id AND uf1.followee_id = uf2.follower_id
+    JOIN users u ON u.id = uf1.followee_id
+    WHERE uf1.follower_id IN (SELECT friend_id FROM my_friends) AND u.deactivated_at IS NULL
+)
+SELECT c.candidate_id
+FROM candidates c
+WHERE c.candidate_id <> $2
+  AND c.candidate_id NOT IN (SELECT followee_id FROM my_follows)
+  AND NOT EXISTS (
+      SELECT 1 FROM users_blocks ub
+      WHERE (ub.blocker_id = $2 AND ub.blocked_id = c.candidate_id) OR
+            (ub.blocker_id = c.candidate_id AND ub.blocked_id = $2)
+  )
+  AND NOT EXISTS (
+      SELECT 1 FROM users_mutes um
+      WHERE um.muter_id = $2 AND um.muted_id = c.candidate_id
+  )
+GROUP BY c.candidate_id
+ORDER BY SUM(c.weight) DESC, c.candidate_id
+LIMIT $1
+`
+
+type SuggestFollowsParams struct {
+	Limit  int32
+	UserID uuid.UUID
+}
+
+func (q *Queries) SuggestFollows(ctx context.Context, arg SuggestFollowsParams) ([]uuid.UUID, error) {
+	rows, err := q.db.Query(ctx, suggestFollows, arg.Limit, arg.UserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var candidate_id uuid.UUID
+		if err := rows.Scan(&candidate_id); err != nil {
+			return nil, err
+		}
+		items = append(items, candidate_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const unblockUser = `-- name: UnblockUser :exec
+DELETE FROM users_blocks
+WHERE blocker_id = $1 AND
+      blocked_id = $2
+`
+
+type UnblockUserParams struct {
+	BlockerID uuid.UUID
+	BlockedID uuid.UUID
+}
+
+func (q *Queries) UnblockUser(ctx context.Context, arg UnblockUserParams) error {
+	_, err := q.db.Exec(ctx, unblockUser, arg.BlockerID, arg.BlockedID)
+	return err
+}
+
+const unfollowUser = `-- name: UnfollowUser :execrows
 DELETE FROM users_follows
 WHERE follower_id = $1 AND
       followee_id = $2
@@ -211,8 +877,27 @@ type UnfollowUserParams struct {
 	FolloweeID uuid.UUID
 }
 
-func (q *Queries) UnfollowUser(ctx context.Context, arg UnfollowUserParams) error {
-	_, err := q.db.Exec(ctx, unfollowUser, arg.FollowerID, arg.FolloweeID)
+func (q *Queries) UnfollowUser(ctx context.Context, arg UnfollowUserParams) (int64, error) {
+	result, err := q.db.Exec(ctx, unfollowUser, arg.FollowerID, arg.FolloweeID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const unmuteUser = `-- name: UnmuteUser :exec
+DELETE FROM users_mutes
+WHERE muter_id = $1 AND
+      muted_id = $2
+`
+
+type UnmuteUserParams struct {
+	MuterID uuid.UUID
+	MutedID uuid.UUID
+}
+
+func (q *Queries) UnmuteUser(ctx context.Context, arg UnmuteUserParams) error {
+	_, err := q.db.Exec(ctx, unmuteUser, arg.MuterID, arg.MutedID)
 	return err
 }
 
@@ -233,19 +918,28 @@ func (q *Queries) UpdatePassword(ctx context.Context, arg UpdatePasswordParams)
 	return err
 }
 
-const updateUser = `-- name: UpdateUser :exec
+const updateUser = `-- name: UpdateUser :execrows
 UPDATE users
 SET
-    name = COALESCE($1, name)
-WHERE id = $2
+    name = COALESCE($1, name),
+    is_private = COALESCE($2, is_private),
+    messages_from_following_only = COALESCE($3, messages_from_following_only),
+    version = version + 1
+WHERE id = $4 AND version = COALESCE($5::int, version)
 `
 
 type UpdateUserParams struct {
-	Name pgtype.Text
-	ID   uuid.UUID
+	Name                      pgtype.Text
+	IsPrivate                 pgtype.Bool
+	MessagesFromFollowingOnly pgtype.Bool
+	ID                        uuid.UUID
+	ExpectedVersion           pgtype.Int4
 }
 
-func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) error {
-	_, err := q.db.Exec(ctx, updateUser, arg.Name, arg.ID)
-	return err
+func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updateUser, arg.Name, arg.IsPrivate, arg.MessagesFromFollowingOnly, arg.ID, arg.ExpectedVersion)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
@@ -1,6 +1,8 @@
 package metrics
 
 import (
+	"time"
+
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 )
@@ -9,6 +11,10 @@ type MittMetrics struct {
 	TotalMitts   prometheus.Gauge
 	TotalLikes   prometheus.Gauge
 	ViewedInFeed prometheus.Counter
+
+	ExtractedHashtags  prometheus.Counter
+	HashtagsPerMitt    prometheus.Histogram
+	HashtagsExtraction prometheus.Histogram
 }
 
 func NewMittMetrics() *MittMetrics {
@@ -16,6 +22,16 @@ func NewMittMetrics() *MittMetrics {
 		TotalMitts:   promauto.NewGauge(prometheus.GaugeOpts{Name: "mitter_mitts_total"}),
 		TotalLikes:   promauto.NewGauge(prometheus.GaugeOpts{Name: "mitter_mitts_likes_total"}),
 		ViewedInFeed: promauto.NewCounter(prometheus.CounterOpts{Name: "mitter_mitts_feed_viewed"}),
+
+		ExtractedHashtags: promauto.NewCounter(prometheus.CounterOpts{Name: "mitter_hashtags_extracted_total"}),
+		HashtagsPerMitt: promauto.NewHistogram(prometheus.HistogramOpts{
+			Name:    "mitter_hashtags_per_mitt",
+			Buckets: []float64{0, 1, 2, 3, 5, 10, 20},
+		}),
+		HashtagsExtraction: promauto.NewHistogram(prometheus.HistogramOpts{
+			Name:    "mitter_hashtags_extraction_seconds",
+			Buckets: prometheus.ExponentialBuckets(0.000001, 4, 8),
+		}),
 	}
 }
 
@@ -38,3 +54,9 @@ func (m *MittMetrics) DeleteLike() {
 func (m *MittMetrics) ViewInFeed(count float64) {
 	m.ViewedInFeed.Add(count)
 }
+
+func (m *MittMetrics) ExtractHashtags(count int, took time.Duration) {
+	m.ExtractedHashtags.Add(float64(count))
+	m.HashtagsPerMitt.Observe(float64(count))
+	m.HashtagsExtraction.Observe(took.Seconds())
+}
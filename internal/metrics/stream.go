@@ -0,0 +1,33 @@
+package metrics
+
+import (
+	"github.com/misshanya/mitter/internal/models"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+type StreamMetrics struct {
+	Connections prometheus.Gauge
+	SentEvents  *prometheus.CounterVec
+}
+
+func NewStreamMetrics() *StreamMetrics {
+	return &StreamMetrics{
+		Connections: promauto.NewGauge(prometheus.GaugeOpts{Name: "mitter_stream_connections"}),
+		SentEvents: promauto.NewCounterVec(prometheus.CounterOpts{
+			Name: "mitter_stream_events_sent_total",
+		}, []string{"type"}),
+	}
+}
+
+func (m *StreamMetrics) Connect() {
+	m.Connections.Inc()
+}
+
+func (m *StreamMetrics) Disconnect() {
+	m.Connections.Dec()
+}
+
+func (m *StreamMetrics) SendEvent(eventType models.EventType) {
+	m.SentEvents.WithLabelValues(string(eventType)).Inc()
+}
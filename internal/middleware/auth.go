@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"context"
+	"errors"
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"net/http"
@@ -20,22 +21,55 @@ func NewAuthMiddleware(authRepo authRepo) *AuthMiddleware {
 	return &AuthMiddleware{authRepo: authRepo}
 }
 
+var errNoToken = errors.New("no token")
+
+// token returns access token from Authorization header or cookie
+func token(c echo.Context) (string, error) {
+	authHeader := c.Request().Header.Get("Authorization")
+	if authHeader != "" {
+		parts := strings.SplitN(authHeader, " ", 2)
+		if len(parts) != 2 || parts[0] != "Bearer" {
+			return "", echo.NewHTTPError(http.StatusUnauthorized, "Invalid Authorization header")
+		}
+		return parts[1], nil
+	}
+	if cookie, err := c.Cookie("token"); err == nil {
+		return cookie.Value, nil
+	}
+	return "", errNoToken
+}
+
 func (a *AuthMiddleware) RequireAuth(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
-		var token string
-
-		authHeader := c.Request().Header.Get("Authorization")
-		if authHeader != "" {
-			parts := strings.SplitN(authHeader, " ", 2)
-			if len(parts) != 2 || parts[0] != "Bearer" {
-				return echo.NewHTTPError(http.StatusUnauthorized, "Invalid Authorization header")
-			}
-			token = parts[1]
-		} else if cookie, err := c.Cookie("token"); err == nil {
-			token = cookie.Value
-		} else {
+		token, err := token(c)
+		if errors.Is(err, errNoToken) {
 			return echo.NewHTTPError(http.StatusUnauthorized, "Authorization required")
 		}
+		if err != nil {
+			return err
+		}
+
+		userID, err := a.authRepo.GetUserIDByToken(c.Request().Context(), token)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
+		}
+
+		c.Set("userID", userID)
+		return next(c)
+	}
+}
+
+// OptionalAuth sets userID like RequireAuth if request has token and lets anonymous requests through.
+// Invalid token is still rejected, so client knows it has to sign in again
+func (a *AuthMiddleware) OptionalAuth(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		token, err := token(c)
+		if errors.Is(err, errNoToken) {
+			return next(c)
+		}
+		if err != nil {
+			return err
+		}
 
 		userID, err := a.authRepo.GetUserIDByToken(c.Request().Context(), token)
 		if err != nil {
@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/misshanya/mitter/internal/dataloader"
+	"github.com/misshanya/mitter/internal/models"
+)
+
+type usersRepo interface {
+	GetUsersByIDs(ctx context.Context, ids []uuid.UUID) ([]*models.User, error)
+}
+
+type DataLoaderMiddleware struct {
+	usersRepo usersRepo
+}
+
+func NewDataLoaderMiddleware(usersRepo usersRepo) *DataLoaderMiddleware {
+	return &DataLoaderMiddleware{usersRepo: usersRepo}
+}
+
+// AttachLoaders gives every request its own loaders, so data is batched and cached only within one request
+func (m *DataLoaderMiddleware) AttachLoaders(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		req := c.Request()
+		ctx := dataloader.WithUserLoader(req.Context(), dataloader.NewUserLoader(m.usersRepo))
+		c.SetRequest(req.WithContext(ctx))
+		return next(c)
+	}
+}
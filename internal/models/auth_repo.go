@@ -8,4 +8,7 @@ import (
 type AuthRepository interface {
 	SaveToken(ctx context.Context, token *Token) error
 	GetUserIDByToken(ctx context.Context, token string) (uuid.UUID, error)
+	RevokeUserTokens(ctx context.Context, userID uuid.UUID) error
+	DeleteToken(ctx context.Context, userID uuid.UUID, token string) error
+	SetUserDeactivated(ctx context.Context, userID uuid.UUID, deactivated bool) error
 }
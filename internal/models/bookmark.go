@@ -0,0 +1,16 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BookmarkFolder is a named folder for bookmarks of user
+type BookmarkFolder struct {
+	ID        uuid.UUID
+	Name      string
+	CreatedAt time.Time
+	// Bookmarks of mitts which aren't deleted
+	BookmarksCount int64
+}
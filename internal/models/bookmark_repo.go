@@ -0,0 +1,26 @@
+package models
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type BookmarkRepository interface {
+	// AddBookmark returns false if mitt is already bookmarked or doesn't exist
+	AddBookmark(ctx context.Context, userID uuid.UUID, mittID uuid.UUID, folderID *uuid.UUID) (bool, error)
+	DeleteBookmark(ctx context.Context, userID uuid.UUID, mittID uuid.UUID) (bool, error)
+	MoveBookmark(ctx context.Context, userID uuid.UUID, mittID uuid.UUID, folderID *uuid.UUID) (bool, error)
+	GetBookmarks(ctx context.Context, userID uuid.UUID, folderID *uuid.UUID, limit, offset int32) ([]*Mitt, error)
+	// GetBookmarkedMittIDs returns IDs of given mitts which user bookmarked
+	GetBookmarkedMittIDs(ctx context.Context, userID uuid.UUID, mittIDs []uuid.UUID) ([]uuid.UUID, error)
+
+	// Folders
+
+	// CreateFolder returns ErrFolderLimit if user already has max folders
+	CreateFolder(ctx context.Context, userID uuid.UUID, name string, max int64) (*BookmarkFolder, error)
+	GetFolder(ctx context.Context, userID uuid.UUID, id uuid.UUID) (*BookmarkFolder, error)
+	GetFolders(ctx context.Context, userID uuid.UUID) ([]*BookmarkFolder, error)
+	RenameFolder(ctx context.Context, userID uuid.UUID, id uuid.UUID, name string) (*BookmarkFolder, error)
+	DeleteFolder(ctx context.Context, userID uuid.UUID, id uuid.UUID) (bool, error)
+}
@@ -0,0 +1,8 @@
+package models
+
+import "context"
+
+type CounterRepository interface {
+	ReconcileMittsCounters(ctx context.Context) (int64, error)
+	ReconcileUsersCounters(ctx context.Context) (int64, error)
+}
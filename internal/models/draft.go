@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type DraftCreate struct {
+	Content string
+	// Draft is published automatically at this time, nil means never
+	PublishAt *time.Time
+}
+
+// Draft is a private mitt which isn't published yet
+type Draft struct {
+	ID        uuid.UUID
+	AuthorID  uuid.UUID
+	Content   string
+	PublishAt *time.Time
+	Version   int32
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type DraftUpdate struct {
+	Content string
+	// Replaces previous time, nil cancels scheduled publishing
+	PublishAt *time.Time
+	// Update only if draft has this version, nil means any
+	ExpectedVersion *int32
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type DraftRepository interface {
+	CreateDraft(ctx context.Context, userID uuid.UUID, draft *DraftCreate) (*Draft, error)
+	GetDraft(ctx context.Context, userID uuid.UUID, id uuid.UUID) (*Draft, error)
+	// GetUserDrafts returns only scheduled or not scheduled drafts, nil scheduled means all
+	GetUserDrafts(ctx context.Context, userID uuid.UUID, scheduled *bool, limit, offset int32) ([]*Draft, error)
+	// UpdateDraft returns ErrVersionConflict if draft doesn't have expected version
+	UpdateDraft(ctx context.Context, userID uuid.UUID, id uuid.UUID, draft *DraftUpdate) (*Draft, error)
+	DeleteDraft(ctx context.Context, userID uuid.UUID, id uuid.UUID) (bool, error)
+
+	// ClaimScheduled returns up to batchSize drafts due to publish and holds them for lease,
+	// concurrent calls get different drafts
+	ClaimScheduled(ctx context.Context, lease time.Duration, batchSize int32) ([]*Draft, error)
+	// PublishDraft deletes draft and creates mitt from it in one transaction.
+	// Returns nil if draft was changed, deleted or published since it was read
+	PublishDraft(ctx context.Context, draft *Draft, mitt *MittCreate) (*Mitt, error)
+}
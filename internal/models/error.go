@@ -1,6 +1,23 @@
 package models
 
+import "errors"
+
 type HTTPError struct {
 	Code    int
 	Message string
 }
+
+// ErrVersionConflict is returned by repositories when updated entity was changed by someone else
+var ErrVersionConflict = errors.New("version conflict")
+
+// ErrPinLimit is returned by repositories when user already has max pinned mitts
+var ErrPinLimit = errors.New("pinned mitts limit reached")
+
+// ErrFolderLimit is returned by repositories when user already has max bookmark folders
+var ErrFolderLimit = errors.New("bookmark folders limit reached")
+
+// ErrPrivateAccount is returned by repositories when private account tries to pin mitt
+var ErrPrivateAccount = errors.New("account is private")
+
+// ErrUserDeactivated is returned by auth repository for tokens of deactivated users
+var ErrUserDeactivated = errors.New("user is deactivated")
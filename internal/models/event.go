@@ -0,0 +1,66 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type EventType string
+
+const (
+	// New mitt in feed
+	EventMitt EventType = "mitt"
+	// Likes count of mitt changed
+	EventLikes EventType = "likes"
+	// New follower of user
+	EventFollower EventType = "follower"
+)
+
+// Event is sent to connected clients in real time
+type Event struct {
+	ID string `json:"id"`
+	// Channel event was published to, not sent to clients
+	Channel string          `json:"-"`
+	Type    EventType       `json:"type"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// Payloads of events, field names match API responses
+
+type MittEventData struct {
+	ID         uuid.UUID `json:"id"`
+	Author     uuid.UUID `json:"author"`
+	AuthorName string    `json:"author_name"`
+	Content    string    `json:"content"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type LikesEventData struct {
+	MittID uuid.UUID `json:"mitt_id"`
+	Author uuid.UUID `json:"author"`
+	Likes  int64     `json:"likes"`
+}
+
+type FollowerEventData struct {
+	ID    uuid.UUID `json:"id"`
+	Login string    `json:"login"`
+	Name  string    `json:"name"`
+}
+
+// EventPublisher streams events to connected clients. Events are secondary,
+// so failures are logged and don't fail the action that caused them
+type EventPublisher interface {
+	PublishMitt(ctx context.Context, mitt *Mitt)
+	PublishLikes(ctx context.Context, mittID uuid.UUID, authorID uuid.UUID, likes int64)
+	PublishFollower(ctx context.Context, followeeID uuid.UUID, follower *User)
+}
+
+type StreamMetrics interface {
+	Connect()
+	Disconnect()
+
+	SendEvent(eventType EventType)
+}
@@ -0,0 +1,15 @@
+package models
+
+import "context"
+
+type EventRepository interface {
+	// Publish sends event to subscribers of channel and keeps it for replay
+	Publish(ctx context.Context, channel string, eventType EventType, data []byte) error
+	// Subscribe returns events published to channels after subscription, until ctx is done
+	Subscribe(ctx context.Context, channels ...string) (<-chan *Event, error)
+
+	// GetLastEventID returns ID of the latest kept event of channel
+	GetLastEventID(ctx context.Context, channel string) (string, error)
+	// GetEventsAfter returns up to limit kept events of channel published after event with afterID, oldest first
+	GetEventsAfter(ctx context.Context, channel string, afterID string, limit int64) ([]*Event, error)
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+type ExportStatus string
+
+const (
+	ExportPending ExportStatus = "pending"
+	ExportRunning ExportStatus = "running"
+	ExportDone    ExportStatus = "done"
+	ExportFailed  ExportStatus = "failed"
+)
+
+// Export is a personal data export requested by user
+type Export struct {
+	ID         uuid.UUID
+	UserID     uuid.UUID
+	Status     ExportStatus
+	Error      string
+	CreatedAt  time.Time
+	FinishedAt *time.Time
+	// Set when archive is ready, download link works until ExpiresAt
+	DownloadToken *uuid.UUID
+	ExpiresAt     *time.Time
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"time"
+)
+
+type ExportRepository interface {
+	CreateExport(ctx context.Context, userID uuid.UUID) (*Export, error)
+	GetExport(ctx context.Context, userID uuid.UUID, id uuid.UUID) (*Export, error)
+
+	// ClaimExport takes the oldest pending export, or running one not finished within staleAfter,
+	// returns nil if there is nothing to do
+	ClaimExport(ctx context.Context, staleAfter time.Duration) (*Export, error)
+	// SaveExportChunk saves next part of archive, parts are numbered from 0
+	SaveExportChunk(ctx context.Context, id uuid.UUID, seq int32, data []byte) error
+	CompleteExport(ctx context.Context, id uuid.UUID, ttl time.Duration) error
+	FailExport(ctx context.Context, id uuid.UUID, reason string, ttl time.Duration) error
+
+	GetExportByToken(ctx context.Context, downloadToken uuid.UUID) (*Export, error)
+	GetExportChunk(ctx context.Context, id uuid.UUID, seq int32) ([]byte, error)
+	DeleteExpiredExports(ctx context.Context) (int64, error)
+}
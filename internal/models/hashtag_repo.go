@@ -0,0 +1,16 @@
+package models
+
+import (
+	"context"
+	"time"
+)
+
+type HashtagRepository interface {
+	GetHashtagMitts(ctx context.Context, name string, limit, offset int32) ([]*Mitt, error)
+}
+
+type TrendingRepository interface {
+	AddHashtagUses(ctx context.Context, tags []string, at time.Time, delta int64) error
+	RefreshTrendingHashtags(ctx context.Context, now time.Time) error
+	GetTrendingHashtags(ctx context.Context, limit int32) ([]*TrendingHashtag, error)
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+type ImportStatus string
+
+const (
+	ImportPending ImportStatus = "pending"
+	ImportRunning ImportStatus = "running"
+	ImportDone    ImportStatus = "done"
+	ImportFailed  ImportStatus = "failed"
+)
+
+// Import is a bulk import of mitts from archive, Processed grows as items are imported
+type Import struct {
+	ID         uuid.UUID
+	UserID     uuid.UUID
+	Status     ImportStatus
+	Error      string
+	Total      int32
+	Processed  int32
+	Imported   int32
+	Skipped    int32
+	Failed     int32
+	CreatedAt  time.Time
+	FinishedAt *time.Time
+}
+
+// ImportItem is a post from archive
+type ImportItem struct {
+	// ID of post in the source, used to skip already imported posts
+	ExternalID string    `json:"id"`
+	Content    string    `json:"content"`
+	CreatedAt  time.Time `json:"created_at"`
+	// Extracted from content while importing
+	Hashtags []string `json:"-"`
+	Mentions []string `json:"-"`
+}
+
+// ImportItemError describes why item with Index in archive wasn't imported
+type ImportItemError struct {
+	Index      int32
+	ExternalID string
+	Error      string
+}
+
+// ImportBatch is a result of processing consecutive items of import
+type ImportBatch struct {
+	// Valid items to insert
+	Items  []*ImportItem
+	Errors []*ImportItemError
+	// Processed count after this batch
+	Processed int32
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"time"
+)
+
+type ImportRepository interface {
+	// CreateImport saves import with its items, running import isn't picked up by workers until it's stale
+	CreateImport(ctx context.Context, userID uuid.UUID, items []*ImportItem, running bool) (*Import, error)
+	GetImport(ctx context.Context, userID uuid.UUID, id uuid.UUID) (*Import, error)
+	GetImportErrors(ctx context.Context, importID uuid.UUID, limit, offset int32) ([]*ImportItemError, error)
+
+	// ClaimImport takes the oldest pending import, or running one without progress within staleAfter,
+	// returns nil if there is nothing to do
+	ClaimImport(ctx context.Context, staleAfter time.Duration) (*Import, error)
+	GetImportItems(ctx context.Context, importID uuid.UUID) ([]*ImportItem, error)
+	// SaveImportBatch inserts mitts, skipping already imported ones, and updates progress in one transaction,
+	// returns updated import
+	SaveImportBatch(ctx context.Context, imp *Import, batch *ImportBatch) (*Import, error)
+	FinishImport(ctx context.Context, id uuid.UUID) error
+	FailImport(ctx context.Context, id uuid.UUID, reason string) error
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// MittMarker sets fields of mitts which depend on current user
+type MittMarker interface {
+	MarkMitts(ctx context.Context, userID uuid.UUID, mitts []*Mitt) *HTTPError
+}
+
+// MittMarkers passes mitts to every marker, stops on the first error
+type MittMarkers []MittMarker
+
+func (m MittMarkers) MarkMitts(ctx context.Context, userID uuid.UUID, mitts []*Mitt) *HTTPError {
+	for _, marker := range m {
+		if err := marker.MarkMitts(ctx, userID, mitts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Conversation is one-to-one or group conversation as seen by one of its members
+type Conversation struct {
+	ID      uuid.UUID
+	IsGroup bool
+	// Members including current user, in order of joining
+	MemberIDs []uuid.UUID
+	Members   []*User
+	// Messages of others created after current user last read conversation
+	UnreadCount int64
+	LastMessage *Message
+	CreatedAt   time.Time
+}
+
+type Message struct {
+	ID             uuid.UUID
+	ConversationID uuid.UUID
+	SenderID       uuid.UUID
+	Content        string
+	CreatedAt      time.Time
+}
+
+// MessageRecipient is existing user and whether sender can message them
+type MessageRecipient struct {
+	ID uuid.UUID
+	// False if one of them blocked another or recipient accepts messages only from users they follow
+	Allowed bool
+}
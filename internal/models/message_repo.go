@@ -0,0 +1,24 @@
+package models
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type MessageRepository interface {
+	// GetRecipients returns only existing users
+	GetRecipients(ctx context.Context, senderID uuid.UUID, userIDs []uuid.UUID) ([]*MessageRecipient, error)
+
+	CreateConversation(ctx context.Context, memberIDs []uuid.UUID, group bool) (uuid.UUID, bool, error)
+	GetConversation(ctx context.Context, userID uuid.UUID, id uuid.UUID) (*Conversation, error)
+	GetUserConversations(ctx context.Context, userID uuid.UUID, limit, offset int32) ([]*Conversation, error)
+	// HasBlocks tells if user blocked or is blocked by some member of conversation
+	HasBlocks(ctx context.Context, userID uuid.UUID, conversationID uuid.UUID) (bool, error)
+
+	CreateMessage(ctx context.Context, conversationID uuid.UUID, senderID uuid.UUID, content string) (*Message, error)
+	GetMessages(ctx context.Context, conversationID uuid.UUID, before *uuid.UUID, limit int32) ([]*Message, error)
+
+	MarkRead(ctx context.Context, userID uuid.UUID, conversationID uuid.UUID) (bool, error)
+	GetUnreadCount(ctx context.Context, userID uuid.UUID) (int64, error)
+}
@@ -7,6 +7,14 @@ import (
 
 type MittCreate struct {
 	Content string
+	// Extracted from content
+	Hashtags []string
+	// Logins mentioned in content
+	Mentions []string
+	// Optional poll
+	Poll *PollCreate
+	// Mitt is hidden once it expires and deleted later, nil means never
+	ExpiresAt *time.Time
 }
 
 type Mitt struct {
@@ -17,8 +25,66 @@ type Mitt struct {
 	CreatedAt  time.Time
 	UpdatedAt  time.Time
 	Likes      int64
+	Revisions  int32
+	Version    int32
+	// Set if mitt is in trash
+	DeletedAt *time.Time
+	// Set for mitts which expire
+	ExpiresAt *time.Time
+	// Mentions of existing users in content
+	Mentions []*MittMention
+	// Set if current user bookmarked mitt
+	Bookmarked bool
+	// Nil if mitt has no poll
+	Poll *Poll
+	// Set if author pinned mitt to profile, only in profile listing
+	Pinned bool
+}
+
+// MittMention is "@login" in mitt content resolved to user.
+// Start and End are offsets in code points including '@', End is exclusive
+type MittMention struct {
+	UserID uuid.UUID
+	Login  string
+	Start  int
+	End    int
+}
+
+// ExpiredMitt is a mitt deleted once it expired, Active is false if it was in trash
+type ExpiredMitt struct {
+	ID        uuid.UUID
+	AuthorID  uuid.UUID
+	Content   string
+	CreatedAt time.Time
+	Active    bool
+}
+
+// MittRevision is a previous version of edited mitt
+type MittRevision struct {
+	ID        uuid.UUID
+	MittID    uuid.UUID
+	Content   string
+	CreatedAt time.Time
+}
+
+// MittLike is a like of user
+type MittLike struct {
+	MittID  uuid.UUID
+	LikedAt time.Time
 }
 
 type MittUpdate struct {
 	Content string
+	// Extracted from content, replace previous ones
+	Hashtags []string
+	// Logins mentioned in content, replace previous ones
+	Mentions []string
+	// Update only if mitt has this version, nil means any
+	ExpectedVersion *int32
+}
+
+// TrendingHashtag is a hashtag with growth of uses in recent window
+type TrendingHashtag struct {
+	Name  string
+	Score int64
 }
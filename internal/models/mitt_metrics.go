@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 type MittMetrics interface {
 	AddMitt()
 	DeleteMitt()
@@ -8,4 +10,6 @@ type MittMetrics interface {
 	DeleteLike()
 
 	ViewInFeed(count float64)
+
+	ExtractHashtags(count int, took time.Duration)
 }
@@ -3,6 +3,7 @@ package models
 import (
 	"context"
 	"github.com/google/uuid"
+	"time"
 )
 
 type MittRepository interface {
@@ -14,13 +15,29 @@ type MittRepository interface {
 	UpdateMitt(ctx context.Context, mittID uuid.UUID, mitt *MittUpdate) (*Mitt, error)
 
 	DeleteMitt(ctx context.Context, mittID uuid.UUID) error
+	RestoreMitt(ctx context.Context, userID uuid.UUID, mittID uuid.UUID, retention time.Duration) (bool, error)
+	GetDeletedUserMitts(ctx context.Context, userID uuid.UUID, limit, offset int32) ([]*Mitt, error)
+	PurgeDeletedMitts(ctx context.Context, retention time.Duration, batchSize int32) (int64, error)
+	DeleteExpiredMitts(ctx context.Context, batchSize int32) ([]*ExpiredMitt, error)
+
+	GetMittRevisions(ctx context.Context, mittID uuid.UUID, limit, offset int32) ([]*MittRevision, error)
+	// GetMittsRevisions returns all revisions of several mitts, newest first
+	GetMittsRevisions(ctx context.Context, mittIDs []uuid.UUID) (map[uuid.UUID][]*MittRevision, error)
+
+	// Pins
+
+	// PinMitt returns ErrPinLimit if user would have more than max pinned mitts, pinning pinned mitt does nothing
+	PinMitt(ctx context.Context, userID uuid.UUID, mittID uuid.UUID, max int) error
+	UnpinMitt(ctx context.Context, userID uuid.UUID, mittID uuid.UUID) (bool, error)
 
 	// Likes
 
-	LikeMitt(ctx context.Context, userID uuid.UUID, mittID uuid.UUID) error
+	LikeMitt(ctx context.Context, userID uuid.UUID, mittID uuid.UUID) (bool, error)
 	IsMittLikedByUser(ctx context.Context, userID uuid.UUID, mittID uuid.UUID) (bool, error)
-	DeleteMittLike(ctx context.Context, userID uuid.UUID, mittID uuid.UUID) error
-	GetMittLikesCount(ctx context.Context, mittID uuid.UUID) (int64, error)
+	DeleteMittLike(ctx context.Context, userID uuid.UUID, mittID uuid.UUID) (bool, error)
+	GetUserLikes(ctx context.Context, userID uuid.UUID, limit, offset int32) ([]*MittLike, error)
 
 	Feed(ctx context.Context, limit, offset int32) ([]*Mitt, error)
+
+	GetMentionedMitts(ctx context.Context, userID uuid.UUID, limit, offset int32) ([]*Mitt, error)
 }
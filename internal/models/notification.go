@@ -0,0 +1,54 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type NotificationType string
+
+const (
+	NotificationLike    NotificationType = "like"
+	NotificationFollow  NotificationType = "follow"
+	NotificationMention NotificationType = "mention"
+	// Sent to author when their poll expires
+	NotificationPollClosed NotificationType = "poll_closed"
+)
+
+// NotificationTypes are all types of notifications, each can be disabled in preferences
+var NotificationTypes = []NotificationType{NotificationLike, NotificationFollow, NotificationMention, NotificationPollClosed}
+
+// NotificationEvent is something user should be notified about
+type NotificationEvent struct {
+	Type    NotificationType
+	ActorID uuid.UUID
+	// Recipient, author of mitt if nil
+	UserID *uuid.UUID
+	MittID *uuid.UUID
+}
+
+// NotificationGroup is notifications of the same type about the same mitt (or profile) on the same day,
+// like "X and 5 others liked your mitt"
+type NotificationGroup struct {
+	// ID of the latest notification, marking it read marks the whole group
+	ID     uuid.UUID
+	Type   NotificationType
+	MittID *uuid.UUID
+	// A few latest actors
+	ActorIDs    []uuid.UUID
+	Actors      []*User
+	ActorsCount int64
+	Unread      bool
+	LatestAt    time.Time
+}
+
+// NotificationPreferences tell which types of notifications are enabled
+type NotificationPreferences map[NotificationType]bool
+
+// Notifier records notifications about events. Notifications are secondary,
+// so failures are logged and don't fail the action that caused them
+type Notifier interface {
+	Notify(ctx context.Context, event *NotificationEvent)
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type NotificationRepository interface {
+	CreateNotification(ctx context.Context, event *NotificationEvent) (bool, error)
+
+	GetNotificationGroups(ctx context.Context, userID uuid.UUID, actorsLimit, limit, offset int32) ([]*NotificationGroup, error)
+	GetUnreadCount(ctx context.Context, userID uuid.UUID) (int64, error)
+
+	MarkGroupRead(ctx context.Context, userID uuid.UUID, id uuid.UUID) (bool, error)
+	MarkAllRead(ctx context.Context, userID uuid.UUID) error
+
+	GetPreferences(ctx context.Context, userID uuid.UUID) (NotificationPreferences, error)
+	SetPreferences(ctx context.Context, userID uuid.UUID, prefs NotificationPreferences) error
+}
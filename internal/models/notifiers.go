@@ -0,0 +1,12 @@
+package models
+
+import "context"
+
+// Notifiers passes events to every notifier
+type Notifiers []Notifier
+
+func (n Notifiers) Notify(ctx context.Context, event *NotificationEvent) {
+	for _, notifier := range n {
+		notifier.Notify(ctx, event)
+	}
+}
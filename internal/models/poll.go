@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PollCreate struct {
+	Options        []string
+	MultipleChoice bool
+	ExpiresAt      time.Time
+}
+
+// Poll is attached to mitt, its options are numbered by position starting from 0
+type Poll struct {
+	Options        []*PollOption
+	MultipleChoice bool
+	ExpiresAt      time.Time
+	// Set once poll is closed by job and its author is notified
+	ClosedAt    *time.Time
+	VotersCount int64
+	// Set if current user voted
+	Voted   bool
+	Choices []int
+}
+
+type PollOption struct {
+	Text  string
+	Votes int64
+}
+
+// PollClosed is a poll closed by job
+type PollClosed struct {
+	MittID   uuid.UUID
+	AuthorID uuid.UUID
+	// False if mitt is in trash
+	Active bool
+}
+
+// Expired tells if poll doesn't accept votes anymore
+func (p *Poll) Expired(now time.Time) bool {
+	return !now.Before(p.ExpiresAt)
+}
+
+// ResultsVisible tells if current user can see votes: results are hidden until they vote or poll expires
+func (p *Poll) ResultsVisible(now time.Time) bool {
+	return p.Voted || p.Expired(now)
+}
@@ -0,0 +1,16 @@
+package models
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type PollRepository interface {
+	// Vote returns false if user already voted or poll doesn't accept votes
+	Vote(ctx context.Context, userID uuid.UUID, mittID uuid.UUID, choices []int) (bool, error)
+	// GetChoices returns choices of user in polls of given mitts by mitt ID
+	GetChoices(ctx context.Context, userID uuid.UUID, mittIDs []uuid.UUID) (map[uuid.UUID][]int, error)
+	// ClosePolls closes up to batchSize expired polls, concurrent calls close different polls
+	ClosePolls(ctx context.Context, batchSize int32) ([]*PollClosed, error)
+}
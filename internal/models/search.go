@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type SearchOrder string
+
+const (
+	SearchByRelevance SearchOrder = "relevance"
+	SearchByRecency   SearchOrder = "recent"
+)
+
+// MittSearch is parsed search query, mitts must match all of its parts
+type MittSearch struct {
+	// Searcher, only mitts visible to them are returned
+	ViewerID uuid.UUID
+	// Full-text part in web search syntax: words, "quoted phrases", OR and -excluded words
+	Text string
+	// Authors' logins, any of them
+	From []string
+	// Normalized hashtags, all of them
+	Hashtags []string
+	Since    *time.Time
+	// Exclusive
+	Until *time.Time
+	Order SearchOrder
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type SearchRepository interface {
+	SearchMitts(ctx context.Context, search *MittSearch, limit, offset int32) ([]*Mitt, error)
+	SearchUsers(ctx context.Context, viewerID uuid.UUID, query string, limit, offset int32) ([]uuid.UUID, error)
+	AutocompleteLogins(ctx context.Context, prefix string, limit int32) ([]uuid.UUID, error)
+}
+
+// AutocompleteRepository caches autocomplete results, they are the same for everyone
+type AutocompleteRepository interface {
+	SaveAutocomplete(ctx context.Context, prefix string, ids []uuid.UUID, ttl time.Duration) error
+	GetAutocomplete(ctx context.Context, prefix string) ([]uuid.UUID, bool, error)
+}
@@ -0,0 +1,14 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type SuggestionRepository interface {
+	SaveSuggestions(ctx context.Context, userID uuid.UUID, ids []uuid.UUID, ttl time.Duration) error
+	GetSuggestions(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, bool, error)
+	AcquireRefreshLock(ctx context.Context, ttl time.Duration) (bool, error)
+}
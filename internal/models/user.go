@@ -1,6 +1,10 @@
 package models
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type UserCreate struct {
 	Login          string
@@ -14,8 +18,31 @@ type User struct {
 	Login          string
 	Name           string
 	HashedPassword string
+	IsPrivate      bool
+	// Only users this user follows can start conversations with them
+	MessagesFromFollowingOnly bool
+	FollowersCount            int64
+	FollowingCount            int64
+	MittsCount                int64
+	Version                   int32
+	// Set when user deleted account, data is purged after grace period
+	DeactivatedAt *time.Time
 }
 
 type UserUpdate struct {
-	Name *string
+	Name                      *string
+	IsPrivate                 *bool
+	MessagesFromFollowingOnly *bool
+	// Update only if user has this version, nil means any
+	ExpectedVersion *int32
+}
+
+// Relationship describes how current user and another user are connected
+type Relationship struct {
+	UserID     uuid.UUID
+	Following  bool
+	FollowedBy bool
+	Blocking   bool
+	BlockedBy  bool
+	Muting     bool
 }
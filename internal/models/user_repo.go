@@ -2,6 +2,8 @@ package models
 
 import (
 	"context"
+	"time"
+
 	"github.com/google/uuid"
 )
 
@@ -9,7 +11,14 @@ type UserRepository interface {
 	CreateUser(ctx context.Context, user *UserCreate) (uuid.UUID, error)
 	GetUserByLogin(ctx context.Context, login string) (*User, error)
 	GetUserByID(ctx context.Context, id uuid.UUID) (*User, error)
+	GetUsersByIDs(ctx context.Context, ids []uuid.UUID) ([]*User, error)
 
+	DeactivateUser(ctx context.Context, id uuid.UUID) (bool, error)
+	ReactivateUser(ctx context.Context, id uuid.UUID, gracePeriod time.Duration) (bool, error)
+	GetUsersToPurge(ctx context.Context, gracePeriod time.Duration, limit int32) ([]uuid.UUID, error)
+	DeleteUserLikes(ctx context.Context, id uuid.UUID, batchSize int32) (int64, error)
+	DeleteUserFollows(ctx context.Context, id uuid.UUID, batchSize int32) (int64, error)
+	DeleteUserMitts(ctx context.Context, id uuid.UUID, batchSize int32) (int64, error)
 	DeleteUser(ctx context.Context, id uuid.UUID) error
 
 	UpdateUser(ctx context.Context, id uuid.UUID, user *UserUpdate) error
@@ -22,4 +31,20 @@ type UserRepository interface {
 	GetUserFollows(ctx context.Context, followerID uuid.UUID, limit, offset int32) ([]uuid.UUID, error)
 	GetUserFollowers(ctx context.Context, followeeID uuid.UUID, limit, offset int32) ([]uuid.UUID, error)
 	GetUserFriends(ctx context.Context, userID uuid.UUID, limit, offset int32) ([]uuid.UUID, error)
+	IsFollowing(ctx context.Context, followerID uuid.UUID, followeeID uuid.UUID) (bool, error)
+	GetKnownFollowers(ctx context.Context, viewerID uuid.UUID, userID uuid.UUID, limit, offset int32) ([]uuid.UUID, error)
+	GetRelationships(ctx context.Context, userID uuid.UUID, ids []uuid.UUID) ([]*Relationship, error)
+
+	ListUserIDs(ctx context.Context, after uuid.UUID, limit int32) ([]uuid.UUID, error)
+
+	BlockUser(ctx context.Context, blockerID uuid.UUID, blockedID uuid.UUID) error
+	UnblockUser(ctx context.Context, blockerID uuid.UUID, blockedID uuid.UUID) error
+	IsBlockedBetween(ctx context.Context, firstID uuid.UUID, secondID uuid.UUID) (bool, error)
+	MuteUser(ctx context.Context, muterID uuid.UUID, mutedID uuid.UUID) error
+	UnmuteUser(ctx context.Context, muterID uuid.UUID, mutedID uuid.UUID) error
+	GetHiddenUserIDs(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error)
+
+	SuggestFollows(ctx context.Context, userID uuid.UUID, limit int32) ([]uuid.UUID, error)
+	GetPopularUsers(ctx context.Context, userID uuid.UUID, limit int32) ([]uuid.UUID, error)
+	FilterSuggestable(ctx context.Context, userID uuid.UUID, candidateIDs []uuid.UUID) ([]uuid.UUID, error)
 }
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Webhook receives signed JSON payloads about events of user, event types are the same as notification types
+type Webhook struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+	URL    string
+	// Key of HMAC-SHA256 signature of payloads
+	Secret    string
+	Events    []NotificationType
+	CreatedAt time.Time
+}
+
+type WebhookCreate struct {
+	URL    string
+	Events []NotificationType
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliveryDelivered WebhookDeliveryStatus = "delivered"
+	// All attempts failed
+	WebhookDeliveryFailed WebhookDeliveryStatus = "failed"
+)
+
+type WebhookDelivery struct {
+	ID        uuid.UUID
+	WebhookID uuid.UUID
+	Event     NotificationType
+	Payload   []byte
+	Status    WebhookDeliveryStatus
+	Attempts  int32
+	// Result of the last attempt, ResponseCode is nil if there was no response
+	ResponseCode  *int32
+	Error         string
+	CreatedAt     time.Time
+	LastAttemptAt *time.Time
+	DeliveredAt   *time.Time
+}
+
+// ClaimedWebhookDelivery is delivery to be attempted now
+type ClaimedWebhookDelivery struct {
+	ID       uuid.UUID
+	Event    NotificationType
+	Payload  []byte
+	Attempts int32
+	URL      string
+	Secret   string
+}
+
+// WebhookAttempt is result of delivery attempt
+type WebhookAttempt struct {
+	Status       WebhookDeliveryStatus
+	ResponseCode *int32
+	Error        string
+	// When to try again if delivery is still pending
+	RetryAfter time.Duration
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type WebhookRepository interface {
+	CreateWebhook(ctx context.Context, userID uuid.UUID, secret string, webhook *WebhookCreate) (*Webhook, error)
+	GetWebhook(ctx context.Context, userID uuid.UUID, id uuid.UUID) (*Webhook, error)
+	GetUserWebhooks(ctx context.Context, userID uuid.UUID) ([]*Webhook, error)
+	DeleteWebhook(ctx context.Context, userID uuid.UUID, id uuid.UUID) (bool, error)
+
+	// CreateDeliveries queues deliveries of event to webhooks of its recipient
+	CreateDeliveries(ctx context.Context, event *NotificationEvent) (int64, error)
+	GetDeliveries(ctx context.Context, webhookID uuid.UUID, limit, offset int32) ([]*WebhookDelivery, error)
+	// ClaimDeliveries returns up to batchSize deliveries due now, they aren't claimed again for lease
+	ClaimDeliveries(ctx context.Context, lease time.Duration, batchSize int32) ([]*ClaimedWebhookDelivery, error)
+	RecordAttempt(ctx context.Context, id uuid.UUID, attempt *WebhookAttempt) error
+	// Redeliver queues delivery again unless it's pending, returns status it had
+	Redeliver(ctx context.Context, userID uuid.UUID, webhookID uuid.UUID, id uuid.UUID) (WebhookDeliveryStatus, error)
+}
@@ -19,8 +19,30 @@ func NewAuthRepository(rdb *redis.Client) *AuthRepository {
 	}
 }
 
+const tokenTTL = 24 * time.Hour
+
+// userTokensKey is a set of user's tokens, so they can be revoked all at once
+func userTokensKey(userID uuid.UUID) string {
+	return "user_tokens:" + userID.String()
+}
+
+// userDeactivatedKey marks deactivated user, so tokens which weren't revoked are rejected too:
+// ones issued before tokens were tracked in userTokensKey or saved by sign-in racing with deactivation
+func userDeactivatedKey(userID uuid.UUID) string {
+	return "user_deactivated:" + userID.String()
+}
+
 func (r *AuthRepository) SaveToken(ctx context.Context, token *models.Token) error {
-	return r.rdb.Set(ctx, token.Token.String(), token.UserID.String(), 24*time.Hour).Err()
+	key := userTokensKey(token.UserID)
+
+	_, err := r.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, token.Token.String(), token.UserID.String(), tokenTTL)
+		pipe.SAdd(ctx, key, token.Token.String())
+		// Set lives as long as the newest token
+		pipe.Expire(ctx, key, tokenTTL)
+		return nil
+	})
+	return err
 }
 
 func (r *AuthRepository) GetUserIDByToken(ctx context.Context, token string) (uuid.UUID, error) {
@@ -37,5 +59,43 @@ func (r *AuthRepository) GetUserIDByToken(ctx context.Context, token string) (uu
 		slog.Error("error parsing user id from redis", slog.String("token", token), slog.String("userID", userIDString), slog.String("error", err.Error()))
 		return uuid.Nil, err
 	}
+
+	deactivated, err := r.rdb.Exists(ctx, userDeactivatedKey(id)).Result()
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if deactivated > 0 {
+		return uuid.Nil, models.ErrUserDeactivated
+	}
 	return id, nil
 }
+
+func (r *AuthRepository) RevokeUserTokens(ctx context.Context, userID uuid.UUID) error {
+	key := userTokensKey(userID)
+
+	tokens, err := r.rdb.SMembers(ctx, key).Result()
+	if err != nil {
+		return err
+	}
+
+	return r.rdb.Del(ctx, append(tokens, key)...).Err()
+}
+
+func (r *AuthRepository) DeleteToken(ctx context.Context, userID uuid.UUID, token string) error {
+	_, err := r.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, token)
+		pipe.SRem(ctx, userTokensKey(userID), token)
+		return nil
+	})
+	return err
+}
+
+// SetUserDeactivated marks user as deactivated or removes the mark.
+// Mark lives as long as a token, after that every token issued before deactivation is expired anyway
+func (r *AuthRepository) SetUserDeactivated(ctx context.Context, userID uuid.UUID, deactivated bool) error {
+	key := userDeactivatedKey(userID)
+	if !deactivated {
+		return r.rdb.Del(ctx, key).Err()
+	}
+	return r.rdb.Set(ctx, key, 1, tokenTTL).Err()
+}
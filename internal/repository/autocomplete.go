@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+type AutocompleteRepository struct {
+	rdb *redis.Client
+}
+
+func NewAutocompleteRepository(rdb *redis.Client) *AutocompleteRepository {
+	return &AutocompleteRepository{
+		rdb: rdb,
+	}
+}
+
+func autocompleteKey(prefix string) string {
+	return "autocomplete:" + prefix
+}
+
+func (r *AutocompleteRepository) SaveAutocomplete(ctx context.Context, prefix string, ids []uuid.UUID, ttl time.Duration) error {
+	idsStr := make([]string, len(ids))
+	for i, id := range ids {
+		idsStr[i] = id.String()
+	}
+
+	return r.rdb.Set(ctx, autocompleteKey(prefix), strings.Join(idsStr, ","), ttl).Err()
+}
+
+// GetAutocomplete returns cached users for prefix and false if there is nothing cached for it
+func (r *AutocompleteRepository) GetAutocomplete(ctx context.Context, prefix string) ([]uuid.UUID, bool, error) {
+	val, err := r.rdb.Get(ctx, autocompleteKey(prefix)).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	if val == "" {
+		return []uuid.UUID{}, true, nil
+	}
+
+	parts := strings.Split(val, ",")
+	ids := make([]uuid.UUID, len(parts))
+	for i, part := range parts {
+		id, err := uuid.Parse(part)
+		if err != nil {
+			return nil, false, err
+		}
+		ids[i] = id
+	}
+
+	return ids, true, nil
+}
@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/misshanya/mitter/internal/db/sqlc/storage"
+	"github.com/misshanya/mitter/internal/models"
+)
+
+type BookmarkRepository struct {
+	pool    *pgxpool.Pool
+	queries *storage.Queries
+}
+
+func NewBookmarkRepository(pool *pgxpool.Pool, q *storage.Queries) *BookmarkRepository {
+	return &BookmarkRepository{pool: pool, queries: q}
+}
+
+func bookmarkFolderDBToFolder(f storage.BookmarkFolder) *models.BookmarkFolder {
+	return &models.BookmarkFolder{
+		ID:        f.ID,
+		Name:      f.Name,
+		CreatedAt: f.CreatedAt.Time,
+	}
+}
+
+// AddBookmark returns false if mitt is already bookmarked, deleted or doesn't exist
+func (r *BookmarkRepository) AddBookmark(ctx context.Context, userID uuid.UUID, mittID uuid.UUID, folderID *uuid.UUID) (bool, error) {
+	created, err := r.queries.AddBookmark(ctx, storage.AddBookmarkParams{
+		UserID:   userID,
+		FolderID: uuidToPg(folderID),
+		MittID:   mittID,
+	})
+	return created > 0, err
+}
+
+func (r *BookmarkRepository) DeleteBookmark(ctx context.Context, userID uuid.UUID, mittID uuid.UUID) (bool, error) {
+	deleted, err := r.queries.DeleteBookmark(ctx, storage.DeleteBookmarkParams{
+		UserID: userID,
+		MittID: mittID,
+	})
+	return deleted > 0, err
+}
+
+// MoveBookmark puts bookmark in folder, nil folder takes it out of any folder.
+// Returns false if mitt isn't bookmarked
+func (r *BookmarkRepository) MoveBookmark(ctx context.Context, userID uuid.UUID, mittID uuid.UUID, folderID *uuid.UUID) (bool, error) {
+	moved, err := r.queries.MoveBookmark(ctx, storage.MoveBookmarkParams{
+		FolderID: uuidToPg(folderID),
+		UserID:   userID,
+		MittID:   mittID,
+	})
+	return moved > 0, err
+}
+
+// GetBookmarks returns bookmarked mitts which aren't deleted, recently bookmarked first.
+// Nil folder means bookmarks from all folders
+func (r *BookmarkRepository) GetBookmarks(ctx context.Context, userID uuid.UUID, folderID *uuid.UUID, limit, offset int32) ([]*models.Mitt, error) {
+	mittsDB, err := r.queries.GetBookmarks(ctx, storage.GetBookmarksParams{
+		Limit:    limit,
+		Offset:   offset,
+		UserID:   userID,
+		FolderID: uuidToPg(folderID),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	mitts := make([]*models.Mitt, len(mittsDB))
+	for i, mittDB := range mittsDB {
+		mitts[i] = mittRowToMitt(storage.GetMittRow(mittDB))
+		mitts[i].Bookmarked = true
+	}
+
+	if err := attachRelated(ctx, r.queries, mitts...); err != nil {
+		return nil, err
+	}
+
+	return mitts, nil
+}
+
+func (r *BookmarkRepository) GetBookmarkedMittIDs(ctx context.Context, userID uuid.UUID, mittIDs []uuid.UUID) ([]uuid.UUID, error) {
+	return r.queries.GetBookmarkedMittIDs(ctx, storage.GetBookmarkedMittIDsParams{
+		UserID:  userID,
+		MittIds: mittIDs,
+	})
+}
+
+// Folders
+
+// CreateFolder returns models.ErrFolderLimit if user already has max folders
+func (r *BookmarkRepository) CreateFolder(ctx context.Context, userID uuid.UUID, name string, max int64) (*models.BookmarkFolder, error) {
+	var folder storage.BookmarkFolder
+	err := inTx(ctx, r.pool, r.queries, func(q *storage.Queries) error {
+		if err := q.LockUserBookmarkFolders(ctx, userID); err != nil {
+			return err
+		}
+
+		count, err := q.CountBookmarkFolders(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if count >= max {
+			return models.ErrFolderLimit
+		}
+
+		folder, err = q.CreateBookmarkFolder(ctx, storage.CreateBookmarkFolderParams{
+			UserID: userID,
+			Name:   name,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return bookmarkFolderDBToFolder(folder), nil
+}
+
+// GetFolder returns folder only if it belongs to user, bookmarks aren't counted
+func (r *BookmarkRepository) GetFolder(ctx context.Context, userID uuid.UUID, id uuid.UUID) (*models.BookmarkFolder, error) {
+	folder, err := r.queries.GetBookmarkFolder(ctx, storage.GetBookmarkFolderParams{
+		ID:     id,
+		UserID: userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return bookmarkFolderDBToFolder(folder), nil
+}
+
+// GetFolders returns all folders of user sorted by name
+func (r *BookmarkRepository) GetFolders(ctx context.Context, userID uuid.UUID) ([]*models.BookmarkFolder, error) {
+	foldersDB, err := r.queries.GetBookmarkFolders(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	folders := make([]*models.BookmarkFolder, len(foldersDB))
+	for i, f := range foldersDB {
+		folders[i] = &models.BookmarkFolder{
+			ID:             f.ID,
+			Name:           f.Name,
+			CreatedAt:      f.CreatedAt.Time,
+			BookmarksCount: f.BookmarksCount,
+		}
+	}
+
+	return folders, nil
+}
+
+func (r *BookmarkRepository) RenameFolder(ctx context.Context, userID uuid.UUID, id uuid.UUID, name string) (*models.BookmarkFolder, error) {
+	folder, err := r.queries.RenameBookmarkFolder(ctx, storage.RenameBookmarkFolderParams{
+		Name:   name,
+		ID:     id,
+		UserID: userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return bookmarkFolderDBToFolder(folder), nil
+}
+
+// DeleteFolder deletes folder, its bookmarks stay without folder
+func (r *BookmarkRepository) DeleteFolder(ctx context.Context, userID uuid.UUID, id uuid.UUID) (bool, error) {
+	deleted, err := r.queries.DeleteBookmarkFolder(ctx, storage.DeleteBookmarkFolderParams{
+		ID:     id,
+		UserID: userID,
+	})
+	return deleted > 0, err
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/misshanya/mitter/internal/db/sqlc/storage"
+)
+
+// How many rows are locked and reconciled per one transaction
+const reconcileBatchSize = 500
+
+type CounterRepository struct {
+	pool    *pgxpool.Pool
+	queries *storage.Queries
+}
+
+func NewCounterRepository(pool *pgxpool.Pool, q *storage.Queries) *CounterRepository {
+	return &CounterRepository{pool: pool, queries: q}
+}
+
+// ReconcileMittsCounters recomputes likes counters of mitts, returns number of fixed mitts.
+// Every batch of counters is locked before likes are counted, so a concurrent like
+// is either already committed and counted or applies its delta after the batch
+func (r *CounterRepository) ReconcileMittsCounters(ctx context.Context) (int64, error) {
+	var fixed int64
+
+	after := uuid.Nil
+	for {
+		var last uuid.UUID
+		err := inTx(ctx, r.pool, r.queries, func(q *storage.Queries) error {
+			rows, err := q.LockMittsLikesCounts(ctx, storage.LockMittsLikesCountsParams{
+				Limit: reconcileBatchSize,
+				After: after,
+			})
+			if err != nil || len(rows) == 0 {
+				return err
+			}
+			last = rows[len(rows)-1].ID
+
+			ids := make([]uuid.UUID, len(rows))
+			for i, row := range rows {
+				ids[i] = row.ID
+			}
+
+			// Mitts without likes are not returned, so they are just missing in map (zero value)
+			countsDB, err := q.GetMittsLikesCounts(ctx, ids)
+			if err != nil {
+				return err
+			}
+			actual := make(map[uuid.UUID]int64, len(countsDB))
+			for _, c := range countsDB {
+				actual[c.MittID] = c.Likes
+			}
+
+			var driftIDs []uuid.UUID
+			var driftCounts []int64
+			for _, row := range rows {
+				if row.LikesCount != actual[row.ID] {
+					driftIDs = append(driftIDs, row.ID)
+					driftCounts = append(driftCounts, actual[row.ID])
+				}
+			}
+			if len(driftIDs) == 0 {
+				return nil
+			}
+
+			n, err := q.SetMittsLikesCounts(ctx, storage.SetMittsLikesCountsParams{
+				Ids:         driftIDs,
+				LikesCounts: driftCounts,
+			})
+			fixed += n
+			return err
+		})
+		if err != nil {
+			return fixed, err
+		}
+		if last == uuid.Nil {
+			return fixed, nil
+		}
+
+		after = last
+	}
+}
+
+// ReconcileUsersCounters recomputes followers, following and mitts counters of users, returns number of fixed users.
+// Users are locked in batches before counting, the same way as mitts
+func (r *CounterRepository) ReconcileUsersCounters(ctx context.Context) (int64, error) {
+	var fixed int64
+
+	after := uuid.Nil
+	for {
+		var last uuid.UUID
+		err := inTx(ctx, r.pool, r.queries, func(q *storage.Queries) error {
+			ids, err := q.LockUsersCounts(ctx, storage.LockUsersCountsParams{
+				Limit: reconcileBatchSize,
+				After: after,
+			})
+			if err != nil || len(ids) == 0 {
+				return err
+			}
+			last = ids[len(ids)-1]
+
+			n, err := q.ReconcileUsersCounts(ctx, ids)
+			fixed += n
+			return err
+		})
+		if err != nil {
+			return fixed, err
+		}
+		if last == uuid.Nil {
+			return fixed, nil
+		}
+
+		after = last
+	}
+}
@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/misshanya/mitter/internal/db/sqlc/storage"
+	"github.com/misshanya/mitter/internal/models"
+)
+
+type DraftRepository struct {
+	pool    *pgxpool.Pool
+	queries *storage.Queries
+}
+
+func NewDraftRepository(pool *pgxpool.Pool, q *storage.Queries) *DraftRepository {
+	return &DraftRepository{pool: pool, queries: q}
+}
+
+func draftDBToDraft(d storage.MittDraft) *models.Draft {
+	draft := &models.Draft{
+		ID:        d.ID,
+		AuthorID:  d.Author,
+		Content:   d.Content,
+		Version:   d.Version,
+		CreatedAt: d.CreatedAt.Time,
+		UpdatedAt: d.UpdatedAt.Time,
+	}
+	if d.PublishAt.Valid {
+		draft.PublishAt = &d.PublishAt.Time
+	}
+	return draft
+}
+
+func draftsDBToDrafts(drafts []storage.MittDraft) []*models.Draft {
+	result := make([]*models.Draft, len(drafts))
+	for i, d := range drafts {
+		result[i] = draftDBToDraft(d)
+	}
+	return result
+}
+
+// publishAtToPg converts publish time to UTC, timestamps are stored without time zone
+func publishAtToPg(t *time.Time) pgtype.Timestamp {
+	if t == nil {
+		return pgtype.Timestamp{}
+	}
+	utc := t.UTC()
+	return timeToPg(&utc)
+}
+
+func (r *DraftRepository) CreateDraft(ctx context.Context, userID uuid.UUID, draft *models.DraftCreate) (*models.Draft, error) {
+	draftDB, err := r.queries.CreateDraft(ctx, storage.CreateDraftParams{
+		Author:    userID,
+		Content:   draft.Content,
+		PublishAt: publishAtToPg(draft.PublishAt),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return draftDBToDraft(draftDB), nil
+}
+
+// GetDraft returns draft only if it belongs to user
+func (r *DraftRepository) GetDraft(ctx context.Context, userID uuid.UUID, id uuid.UUID) (*models.Draft, error) {
+	draftDB, err := r.queries.GetDraft(ctx, storage.GetDraftParams{
+		ID:     id,
+		Author: userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return draftDBToDraft(draftDB), nil
+}
+
+// GetUserDrafts returns scheduled drafts first, the soonest first, then the rest, recently updated first
+func (r *DraftRepository) GetUserDrafts(ctx context.Context, userID uuid.UUID, scheduled *bool, limit, offset int32) ([]*models.Draft, error) {
+	params := storage.GetUserDraftsParams{
+		Limit:  limit,
+		Offset: offset,
+		Author: userID,
+	}
+	if scheduled != nil {
+		params.Scheduled = pgtype.Bool{Bool: *scheduled, Valid: true}
+	}
+
+	draftsDB, err := r.queries.GetUserDrafts(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	return draftsDBToDrafts(draftsDB), nil
+}
+
+// UpdateDraft replaces content and publish time of draft, it's released if scheduler holds it
+func (r *DraftRepository) UpdateDraft(ctx context.Context, userID uuid.UUID, id uuid.UUID, draft *models.DraftUpdate) (*models.Draft, error) {
+	expectedVersion := pgtype.Int4{}
+	if draft.ExpectedVersion != nil {
+		expectedVersion = pgtype.Int4{Int32: *draft.ExpectedVersion, Valid: true}
+	}
+
+	draftDB, err := r.queries.UpdateDraft(ctx, storage.UpdateDraftParams{
+		Content:         draft.Content,
+		PublishAt:       publishAtToPg(draft.PublishAt),
+		ID:              id,
+		Author:          userID,
+		ExpectedVersion: expectedVersion,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) && draft.ExpectedVersion != nil {
+			return nil, models.ErrVersionConflict
+		}
+		return nil, err
+	}
+	return draftDBToDraft(draftDB), nil
+}
+
+func (r *DraftRepository) DeleteDraft(ctx context.Context, userID uuid.UUID, id uuid.UUID) (bool, error) {
+	deleted, err := r.queries.DeleteDraft(ctx, storage.DeleteDraftParams{
+		ID:     id,
+		Author: userID,
+	})
+	return deleted > 0, err
+}
+
+func (r *DraftRepository) ClaimScheduled(ctx context.Context, lease time.Duration, batchSize int32) ([]*models.Draft, error) {
+	draftsDB, err := r.queries.ClaimScheduledDrafts(ctx, storage.ClaimScheduledDraftsParams{
+		LeaseSeconds: int64(lease.Seconds()),
+		BatchSize:    batchSize,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return draftsDBToDrafts(draftsDB), nil
+}
+
+// PublishDraft deletes draft only if it still has the same version, so every version is published at most once
+func (r *DraftRepository) PublishDraft(ctx context.Context, draft *models.Draft, mitt *models.MittCreate) (*models.Mitt, error) {
+	var mittDB *storage.Mitt
+	err := inTx(ctx, r.pool, r.queries, func(q *storage.Queries) error {
+		deleted, err := q.DeletePublishedDraft(ctx, storage.DeletePublishedDraftParams{
+			ID:      draft.ID,
+			Version: draft.Version,
+		})
+		if err != nil || deleted == 0 {
+			return err
+		}
+
+		created, err := createMitt(ctx, q, draft.AuthorID, mitt)
+		if err != nil {
+			return err
+		}
+		mittDB = &created
+		return nil
+	})
+	if err != nil || mittDB == nil {
+		return nil, err
+	}
+
+	newMitt := mittDBToMitt(*mittDB)
+	if err := attachRelated(ctx, r.queries, newMitt); err != nil {
+		return nil, err
+	}
+
+	return newMitt, nil
+}
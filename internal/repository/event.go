@@ -0,0 +1,293 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/misshanya/mitter/internal/models"
+	"github.com/redis/go-redis/v9"
+)
+
+// Both Pub/Sub channel and stream of events kept for replay
+const eventsKeyPrefix = "events:"
+
+// Events buffered for every subscriber, subscriber that falls behind is dropped
+const subscriberBufferSize = 64
+
+// EventRepository publishes events with Redis Pub/Sub, so they reach clients connected to any instance.
+// Every event is also added to a capped stream, so clients can catch up after reconnect.
+// Subscribers of instance share one Redis connection, events are fanned out to them in process
+type EventRepository struct {
+	rdb    *redis.Client
+	maxLen int64
+	ttl    time.Duration
+
+	mu     sync.Mutex
+	pubsub *redis.PubSub
+	topics map[string]*eventTopic
+	// Numbers of SUBSCRIBE commands per key not confirmed by Redis yet
+	pending map[string]int
+}
+
+// eventTopic is Pub/Sub channel subscribed to by clients of instance
+type eventTopic struct {
+	subscribers map[*eventSubscriber]struct{}
+	// Closed when Redis confirms subscription
+	ready     chan struct{}
+	confirmed bool
+}
+
+type eventSubscriber struct {
+	events chan *models.Event
+	keys   []string
+	closed bool
+}
+
+// NewEventRepository creates event repository which keeps up to maxLen latest events per channel,
+// streams of channels without new events for ttl are deleted
+func NewEventRepository(rdb *redis.Client, maxLen int64, ttl time.Duration) *EventRepository {
+	return &EventRepository{
+		rdb:     rdb,
+		maxLen:  maxLen,
+		ttl:     ttl,
+		topics:  map[string]*eventTopic{},
+		pending: map[string]int{},
+	}
+}
+
+func eventsKey(channel string) string {
+	return eventsKeyPrefix + channel
+}
+
+// publishScript adds event to stream and publishes it with stream ID atomically,
+// so subscribers get events of channel in the order of their IDs
+var publishScript = redis.NewScript(`
+local id = redis.call('XADD', KEYS[1], 'MAXLEN', '~', ARGV[1], '*', 'type', ARGV[2], 'data', ARGV[3])
+redis.call('PEXPIRE', KEYS[1], ARGV[4])
+redis.call('PUBLISH', KEYS[1], '{"id":"' .. id .. '","type":"' .. ARGV[2] .. '","data":' .. ARGV[3] .. '}')
+return id
+`)
+
+// Publish adds event to stream of channel and publishes it with stream ID. Data must be JSON
+func (r *EventRepository) Publish(ctx context.Context, channel string, eventType models.EventType, data []byte) error {
+	return publishScript.Run(ctx, r.rdb, []string{eventsKey(channel)},
+		r.maxLen, string(eventType), data, r.ttl.Milliseconds(),
+	).Err()
+}
+
+// Subscribe returns channel of events which is closed when ctx is done.
+// It's also closed if subscriber falls behind or connection to Redis is restored,
+// as events may be missed then and client has to catch up
+func (r *EventRepository) Subscribe(ctx context.Context, channels ...string) (<-chan *models.Event, error) {
+	sub := &eventSubscriber{
+		events: make(chan *models.Event, subscriberBufferSize),
+		keys:   make([]string, len(channels)),
+	}
+	for i, channel := range channels {
+		sub.keys[i] = eventsKey(channel)
+	}
+
+	ready, err := r.addSubscriber(ctx, sub)
+	if err != nil {
+		return nil, err
+	}
+
+	// Wait for confirmation, so events published after return aren't missed
+	for _, ch := range ready {
+		select {
+		case <-ch:
+		case <-ctx.Done():
+			r.removeSubscriber(sub)
+			return nil, ctx.Err()
+		}
+	}
+
+	go func() {
+		<-ctx.Done()
+		r.removeSubscriber(sub)
+	}()
+
+	return sub.events, nil
+}
+
+// addSubscriber adds subscriber to topics of its keys, subscribing to new ones in Redis.
+// It returns channels closed when topics are confirmed
+func (r *EventRepository) addSubscriber(ctx context.Context, sub *eventSubscriber) ([]chan struct{}, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.pubsub == nil {
+		r.pubsub = r.rdb.Subscribe(context.Background())
+		go r.dispatch(r.pubsub.ChannelWithSubscriptions())
+	}
+
+	var newKeys []string
+	ready := make([]chan struct{}, len(sub.keys))
+	for i, key := range sub.keys {
+		topic, ok := r.topics[key]
+		if !ok {
+			topic = &eventTopic{
+				subscribers: map[*eventSubscriber]struct{}{},
+				ready:       make(chan struct{}),
+			}
+			r.topics[key] = topic
+			newKeys = append(newKeys, key)
+		}
+		topic.subscribers[sub] = struct{}{}
+		ready[i] = topic.ready
+	}
+
+	if len(newKeys) == 0 {
+		return ready, nil
+	}
+
+	// Commands are sent under lock, so they reach Redis in the same order topics change
+	if err := r.pubsub.Subscribe(ctx, newKeys...); err != nil {
+		r.dropLocked(sub)
+		return nil, err
+	}
+	for _, key := range newKeys {
+		r.pending[key]++
+	}
+
+	return ready, nil
+}
+
+func (r *EventRepository) removeSubscriber(sub *eventSubscriber) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.dropLocked(sub)
+}
+
+// dropLocked closes channel of subscriber and unsubscribes from keys nobody else listens to
+func (r *EventRepository) dropLocked(sub *eventSubscriber) {
+	if sub.closed {
+		return
+	}
+	sub.closed = true
+	close(sub.events)
+
+	var unused []string
+	for _, key := range sub.keys {
+		topic, ok := r.topics[key]
+		if !ok {
+			continue
+		}
+		delete(topic.subscribers, sub)
+		if len(topic.subscribers) == 0 {
+			delete(r.topics, key)
+			unused = append(unused, key)
+		}
+	}
+
+	if len(unused) > 0 {
+		if err := r.pubsub.Unsubscribe(context.Background(), unused...); err != nil {
+			slog.Error("error unsubscribing from events", slog.Any("err", err))
+		}
+	}
+}
+
+// dispatch fans out messages of shared Pub/Sub to subscribers until it's closed
+func (r *EventRepository) dispatch(messages <-chan any) {
+	for msg := range messages {
+		switch msg := msg.(type) {
+		case *redis.Subscription:
+			if msg.Kind == "subscribe" {
+				r.confirm(msg.Channel)
+			}
+		case *redis.Message:
+			r.deliver(msg)
+		}
+	}
+}
+
+// confirm marks topic subscribed once Redis confirms the last SUBSCRIBE sent for it
+func (r *EventRepository) confirm(key string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	topic := r.topics[key]
+	if r.pending[key] == 0 {
+		// Resubscribed after reconnect, events published in between are lost
+		if topic != nil {
+			for sub := range topic.subscribers {
+				r.dropLocked(sub)
+			}
+		}
+		return
+	}
+
+	r.pending[key]--
+	if r.pending[key] > 0 {
+		return
+	}
+	delete(r.pending, key)
+
+	if topic != nil && !topic.confirmed {
+		topic.confirmed = true
+		close(topic.ready)
+	}
+}
+
+func (r *EventRepository) deliver(msg *redis.Message) {
+	var event models.Event
+	if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+		slog.Error("error decoding event", slog.String("channel", msg.Channel), slog.Any("err", err))
+		return
+	}
+	event.Channel = strings.TrimPrefix(msg.Channel, eventsKeyPrefix)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	topic, ok := r.topics[msg.Channel]
+	if !ok {
+		return
+	}
+	for sub := range topic.subscribers {
+		select {
+		case sub.events <- &event:
+		default:
+			// Client catches up from stream after reconnect
+			r.dropLocked(sub)
+		}
+	}
+}
+
+// GetLastEventID returns "0-0" if channel has no kept events
+func (r *EventRepository) GetLastEventID(ctx context.Context, channel string) (string, error) {
+	msgs, err := r.rdb.XRevRangeN(ctx, eventsKey(channel), "+", "-", 1).Result()
+	if err != nil {
+		return "", err
+	}
+	if len(msgs) == 0 {
+		return "0-0", nil
+	}
+	return msgs[0].ID, nil
+}
+
+func (r *EventRepository) GetEventsAfter(ctx context.Context, channel string, afterID string, limit int64) ([]*models.Event, error) {
+	msgs, err := r.rdb.XRangeN(ctx, eventsKey(channel), "("+afterID, "+", limit).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]*models.Event, len(msgs))
+	for i, msg := range msgs {
+		eventType, _ := msg.Values["type"].(string)
+		data, _ := msg.Values["data"].(string)
+		events[i] = &models.Event{
+			ID:      msg.ID,
+			Channel: channel,
+			Type:    models.EventType(eventType),
+			Data:    json.RawMessage(data),
+		}
+	}
+
+	return events, nil
+}
@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/misshanya/mitter/internal/db/sqlc/storage"
+	"github.com/misshanya/mitter/internal/models"
+	"time"
+)
+
+type ExportRepository struct {
+	pool    *pgxpool.Pool
+	queries *storage.Queries
+}
+
+func NewExportRepository(pool *pgxpool.Pool, q *storage.Queries) *ExportRepository {
+	return &ExportRepository{pool: pool, queries: q}
+}
+
+func exportDBToExport(exportDB storage.DataExport) *models.Export {
+	export := &models.Export{
+		ID:        exportDB.ID,
+		UserID:    exportDB.UserID,
+		Status:    models.ExportStatus(exportDB.Status),
+		Error:     exportDB.Error.String,
+		CreatedAt: exportDB.CreatedAt.Time,
+	}
+	if exportDB.FinishedAt.Valid {
+		export.FinishedAt = &exportDB.FinishedAt.Time
+	}
+	if exportDB.DownloadToken.Valid {
+		token := uuid.UUID(exportDB.DownloadToken.Bytes)
+		export.DownloadToken = &token
+	}
+	if exportDB.ExpiresAt.Valid {
+		export.ExpiresAt = &exportDB.ExpiresAt.Time
+	}
+
+	return export
+}
+
+func (r *ExportRepository) CreateExport(ctx context.Context, userID uuid.UUID) (*models.Export, error) {
+	exportDB, err := r.queries.CreateExport(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return exportDBToExport(exportDB), nil
+}
+
+func (r *ExportRepository) GetExport(ctx context.Context, userID uuid.UUID, id uuid.UUID) (*models.Export, error) {
+	exportDB, err := r.queries.GetExport(ctx, storage.GetExportParams{
+		ID:     id,
+		UserID: userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return exportDBToExport(exportDB), nil
+}
+
+// ClaimExport drops chunks written by previous attempt in the same transaction, so archive is built from scratch
+func (r *ExportRepository) ClaimExport(ctx context.Context, staleAfter time.Duration) (*models.Export, error) {
+	var exportDB storage.DataExport
+	err := inTx(ctx, r.pool, r.queries, func(q *storage.Queries) error {
+		var err error
+		exportDB, err = q.ClaimExport(ctx, int64(staleAfter.Seconds()))
+		if err != nil {
+			return err
+		}
+
+		return q.DeleteExportChunks(ctx, exportDB.ID)
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return exportDBToExport(exportDB), nil
+}
+
+func (r *ExportRepository) SaveExportChunk(ctx context.Context, id uuid.UUID, seq int32, data []byte) error {
+	return r.queries.SaveExportChunk(ctx, storage.SaveExportChunkParams{
+		ExportID: id,
+		Seq:      seq,
+		Data:     data,
+	})
+}
+
+func (r *ExportRepository) CompleteExport(ctx context.Context, id uuid.UUID, ttl time.Duration) error {
+	return r.queries.CompleteExport(ctx, storage.CompleteExportParams{
+		TtlSeconds: int64(ttl.Seconds()),
+		ID:         id,
+	})
+}
+
+// FailExport drops chunks written so far in the same transaction
+func (r *ExportRepository) FailExport(ctx context.Context, id uuid.UUID, reason string, ttl time.Duration) error {
+	return inTx(ctx, r.pool, r.queries, func(q *storage.Queries) error {
+		if err := q.DeleteExportChunks(ctx, id); err != nil {
+			return err
+		}
+
+		return q.FailExport(ctx, storage.FailExportParams{
+			Error:      pgtype.Text{String: reason, Valid: true},
+			TtlSeconds: int64(ttl.Seconds()),
+			ID:         id,
+		})
+	})
+}
+
+// GetExportByToken returns pgx.ErrNoRows if token is unknown or expired
+func (r *ExportRepository) GetExportByToken(ctx context.Context, downloadToken uuid.UUID) (*models.Export, error) {
+	exportDB, err := r.queries.GetExportByToken(ctx, pgtype.UUID{Bytes: downloadToken, Valid: true})
+	if err != nil {
+		return nil, err
+	}
+
+	return exportDBToExport(exportDB), nil
+}
+
+// GetExportChunk returns pgx.ErrNoRows after the last chunk
+func (r *ExportRepository) GetExportChunk(ctx context.Context, id uuid.UUID, seq int32) ([]byte, error) {
+	return r.queries.GetExportChunk(ctx, storage.GetExportChunkParams{
+		ExportID: id,
+		Seq:      seq,
+	})
+}
+
+func (r *ExportRepository) DeleteExpiredExports(ctx context.Context) (int64, error) {
+	return r.queries.DeleteExpiredExports(ctx)
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/misshanya/mitter/internal/db/sqlc/storage"
+	"github.com/misshanya/mitter/internal/models"
+)
+
+type HashtagRepository struct {
+	queries *storage.Queries
+}
+
+func NewHashtagRepository(q *storage.Queries) *HashtagRepository {
+	return &HashtagRepository{queries: q}
+}
+
+// linkMittHashtags adds hashtags to mitt, creating missing ones
+func linkMittHashtags(ctx context.Context, q *storage.Queries, mittID uuid.UUID, tags []string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	if err := q.CreateHashtags(ctx, tags); err != nil {
+		return err
+	}
+
+	return q.LinkMittHashtags(ctx, storage.LinkMittHashtagsParams{
+		MittID: mittID,
+		Names:  tags,
+	})
+}
+
+// setMittHashtags makes tags the only hashtags of mitt
+func setMittHashtags(ctx context.Context, q *storage.Queries, mittID uuid.UUID, tags []string) error {
+	// nil would be NULL and nothing would be unlinked
+	keep := tags
+	if keep == nil {
+		keep = []string{}
+	}
+
+	if err := q.UnlinkMittHashtags(ctx, storage.UnlinkMittHashtagsParams{
+		MittID:    mittID,
+		KeepNames: keep,
+	}); err != nil {
+		return err
+	}
+
+	return linkMittHashtags(ctx, q, mittID, tags)
+}
+
+func (r *HashtagRepository) GetHashtagMitts(ctx context.Context, name string, limit, offset int32) ([]*models.Mitt, error) {
+	mittsDB, err := r.queries.GetHashtagMitts(ctx, storage.GetHashtagMittsParams{
+		Limit:  limit,
+		Offset: offset,
+		Name:   name,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	mitts := make([]*models.Mitt, len(mittsDB))
+	for i, mittDB := range mittsDB {
+		mitts[i] = mittRowToMitt(storage.GetMittRow(mittDB))
+	}
+
+	if err := attachRelated(ctx, r.queries, mitts...); err != nil {
+		return nil, err
+	}
+
+	return mitts, nil
+}
@@ -0,0 +1,225 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/misshanya/mitter/internal/db/sqlc/storage"
+	"github.com/misshanya/mitter/internal/models"
+	"time"
+)
+
+type ImportRepository struct {
+	pool    *pgxpool.Pool
+	queries *storage.Queries
+}
+
+func NewImportRepository(pool *pgxpool.Pool, q *storage.Queries) *ImportRepository {
+	return &ImportRepository{pool: pool, queries: q}
+}
+
+func importDBToImport(importDB storage.MittImport) *models.Import {
+	imp := &models.Import{
+		ID:        importDB.ID,
+		UserID:    importDB.UserID,
+		Status:    models.ImportStatus(importDB.Status),
+		Error:     importDB.Error.String,
+		Total:     importDB.Total,
+		Processed: importDB.Processed,
+		Imported:  importDB.Imported,
+		Skipped:   importDB.Skipped,
+		Failed:    importDB.Failed,
+		CreatedAt: importDB.CreatedAt.Time,
+	}
+	if importDB.FinishedAt.Valid {
+		imp.FinishedAt = &importDB.FinishedAt.Time
+	}
+
+	return imp
+}
+
+func (r *ImportRepository) CreateImport(ctx context.Context, userID uuid.UUID, items []*models.ImportItem, running bool) (*models.Import, error) {
+	itemsJSON, err := json.Marshal(items)
+	if err != nil {
+		return nil, err
+	}
+
+	status := models.ImportPending
+	if running {
+		status = models.ImportRunning
+	}
+
+	var imp *models.Import
+	err = inTx(ctx, r.pool, r.queries, func(q *storage.Queries) error {
+		importDB, err := q.CreateImport(ctx, storage.CreateImportParams{
+			UserID: userID,
+			Status: string(status),
+			Total:  int32(len(items)),
+		})
+		if err != nil {
+			return err
+		}
+		imp = importDBToImport(importDB)
+
+		return q.SaveImportItems(ctx, storage.SaveImportItemsParams{
+			ImportID: importDB.ID,
+			Items:    itemsJSON,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return imp, nil
+}
+
+func (r *ImportRepository) GetImport(ctx context.Context, userID uuid.UUID, id uuid.UUID) (*models.Import, error) {
+	importDB, err := r.queries.GetImport(ctx, storage.GetImportParams{
+		ID:     id,
+		UserID: userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return importDBToImport(importDB), nil
+}
+
+func (r *ImportRepository) GetImportErrors(ctx context.Context, importID uuid.UUID, limit, offset int32) ([]*models.ImportItemError, error) {
+	errorsDB, err := r.queries.GetImportErrors(ctx, storage.GetImportErrorsParams{
+		Limit:    limit,
+		Offset:   offset,
+		ImportID: importID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	itemErrors := make([]*models.ImportItemError, len(errorsDB))
+	for i, errorDB := range errorsDB {
+		itemErrors[i] = &models.ImportItemError{
+			Index:      errorDB.ItemIndex,
+			ExternalID: errorDB.ExternalID,
+			Error:      errorDB.Error,
+		}
+	}
+
+	return itemErrors, nil
+}
+
+func (r *ImportRepository) ClaimImport(ctx context.Context, staleAfter time.Duration) (*models.Import, error) {
+	importDB, err := r.queries.ClaimImport(ctx, int64(staleAfter.Seconds()))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return importDBToImport(importDB), nil
+}
+
+func (r *ImportRepository) GetImportItems(ctx context.Context, importID uuid.UUID) ([]*models.ImportItem, error) {
+	itemsJSON, err := r.queries.GetImportItems(ctx, importID)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []*models.ImportItem
+	if err := json.Unmarshal(itemsJSON, &items); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+func (r *ImportRepository) SaveImportBatch(ctx context.Context, imp *models.Import, batch *models.ImportBatch) (*models.Import, error) {
+	var updated *models.Import
+	err := inTx(ctx, r.pool, r.queries, func(q *storage.Queries) error {
+		var imported int64
+		for _, item := range batch.Items {
+			mittID, err := q.ImportMitt(ctx, storage.ImportMittParams{
+				Author:     imp.UserID,
+				Content:    item.Content,
+				CreatedAt:  pgtype.Timestamp{Time: item.CreatedAt, Valid: true},
+				ExternalID: pgtype.Text{String: item.ExternalID, Valid: true},
+			})
+			if err != nil {
+				if errors.Is(err, pgx.ErrNoRows) {
+					// Already imported
+					continue
+				}
+				return err
+			}
+			imported++
+
+			if err := linkMittHashtags(ctx, q, mittID, item.Hashtags); err != nil {
+				return err
+			}
+
+			if err := linkMittMentions(ctx, q, mittID, item.Mentions); err != nil {
+				return err
+			}
+		}
+
+		if imported > 0 {
+			if err := q.AddUserMittsCount(ctx, storage.AddUserMittsCountParams{
+				Delta: imported,
+				ID:    imp.UserID,
+			}); err != nil {
+				return err
+			}
+		}
+
+		for _, itemErr := range batch.Errors {
+			if err := q.SaveImportError(ctx, storage.SaveImportErrorParams{
+				ImportID:   imp.ID,
+				ItemIndex:  itemErr.Index,
+				ExternalID: itemErr.ExternalID,
+				Error:      itemErr.Error,
+			}); err != nil {
+				return err
+			}
+		}
+
+		importDB, err := q.UpdateImportProgress(ctx, storage.UpdateImportProgressParams{
+			Processed: batch.Processed,
+			Imported:  int32(imported),
+			Skipped:   int32(int64(len(batch.Items)) - imported),
+			Failed:    int32(len(batch.Errors)),
+			ID:        imp.ID,
+		})
+		if err != nil {
+			return err
+		}
+		updated = importDBToImport(importDB)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+// FinishImport marks import as done and deletes its items
+func (r *ImportRepository) FinishImport(ctx context.Context, id uuid.UUID) error {
+	return inTx(ctx, r.pool, r.queries, func(q *storage.Queries) error {
+		if err := q.FinishImport(ctx, id); err != nil {
+			return err
+		}
+		return q.DeleteImportItems(ctx, id)
+	})
+}
+
+func (r *ImportRepository) FailImport(ctx context.Context, id uuid.UUID, reason string) error {
+	return r.queries.FailImport(ctx, storage.FailImportParams{
+		Error: pgtype.Text{String: reason, Valid: true},
+		ID:    id,
+	})
+}
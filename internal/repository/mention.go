@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/misshanya/mitter/internal/db/sqlc/storage"
+	"github.com/misshanya/mitter/internal/models"
+	"github.com/misshanya/mitter/pkg/content"
+)
+
+// linkMittMentions resolves lowercase logins to users and links them to mitt, unknown logins are ignored
+func linkMittMentions(ctx context.Context, q *storage.Queries, mittID uuid.UUID, logins []string) error {
+	if len(logins) == 0 {
+		return nil
+	}
+
+	return q.LinkMittMentions(ctx, storage.LinkMittMentionsParams{
+		MittID: mittID,
+		Logins: logins,
+	})
+}
+
+// setMittMentions makes users with logins the only mentioned users of mitt
+func setMittMentions(ctx context.Context, q *storage.Queries, mittID uuid.UUID, logins []string) error {
+	// nil would be NULL and nothing would be unlinked
+	keep := logins
+	if keep == nil {
+		keep = []string{}
+	}
+
+	if err := q.UnlinkMittMentions(ctx, storage.UnlinkMittMentionsParams{
+		MittID:     mittID,
+		KeepLogins: keep,
+	}); err != nil {
+		return err
+	}
+
+	return linkMittMentions(ctx, q, mittID, logins)
+}
+
+// attachMentions sets mention entities of mitts using their resolved mentions, in one query
+func attachMentions(ctx context.Context, q *storage.Queries, mitts ...*models.Mitt) error {
+	if len(mitts) == 0 {
+		return nil
+	}
+
+	ids := make([]uuid.UUID, len(mitts))
+	for i, mitt := range mitts {
+		ids[i] = mitt.ID
+	}
+
+	rows, err := q.GetMittsMentions(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	// Mitt ID -> lowercase login -> user, logins are matched case-insensitively
+	resolved := make(map[uuid.UUID]map[string]storage.GetMittsMentionsRow)
+	for _, row := range rows {
+		if resolved[row.MittID] == nil {
+			resolved[row.MittID] = make(map[string]storage.GetMittsMentionsRow)
+		}
+		resolved[row.MittID][strings.ToLower(row.Login)] = row
+	}
+
+	for _, mitt := range mitts {
+		users := resolved[mitt.ID]
+		if len(users) == 0 {
+			continue
+		}
+
+		for _, m := range content.Mentions(mitt.Content) {
+			user, ok := users[strings.ToLower(m.Login)]
+			if !ok {
+				continue
+			}
+			mitt.Mentions = append(mitt.Mentions, &models.MittMention{
+				UserID: user.UserID,
+				Login:  user.Login,
+				Start:  m.Start,
+				End:    m.End,
+			})
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/misshanya/mitter/internal/db/sqlc/storage"
+	"github.com/misshanya/mitter/internal/models"
+)
+
+type MessageRepository struct {
+	pool    *pgxpool.Pool
+	queries *storage.Queries
+}
+
+func NewMessageRepository(pool *pgxpool.Pool, q *storage.Queries) *MessageRepository {
+	return &MessageRepository{pool: pool, queries: q}
+}
+
+func messageDBToMessage(messageDB storage.Message) *models.Message {
+	return &models.Message{
+		ID:             messageDB.ID,
+		ConversationID: messageDB.ConversationID,
+		SenderID:       messageDB.SenderID,
+		Content:        messageDB.Content,
+		CreatedAt:      messageDB.CreatedAt.Time,
+	}
+}
+
+func conversationDBToConversation(c storage.GetConversationRow) *models.Conversation {
+	conversation := &models.Conversation{
+		ID:          c.ID,
+		IsGroup:     c.IsGroup,
+		MemberIDs:   c.MemberIds,
+		UnreadCount: c.UnreadCount,
+		CreatedAt:   c.CreatedAt.Time,
+	}
+	if c.LastMessageID.Valid {
+		conversation.LastMessage = &models.Message{
+			ID:             c.LastMessageID.Bytes,
+			ConversationID: c.ID,
+			SenderID:       c.LastMessageSenderID.Bytes,
+			Content:        c.LastMessageContent.String,
+			CreatedAt:      c.LastMessageCreatedAt.Time,
+		}
+	}
+	return conversation
+}
+
+func (r *MessageRepository) GetRecipients(ctx context.Context, senderID uuid.UUID, userIDs []uuid.UUID) ([]*models.MessageRecipient, error) {
+	recipientsDB, err := r.queries.GetMessageRecipients(ctx, storage.GetMessageRecipientsParams{
+		SenderID: senderID,
+		UserIds:  userIDs,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	recipients := make([]*models.MessageRecipient, len(recipientsDB))
+	for i, rc := range recipientsDB {
+		recipients[i] = &models.MessageRecipient{
+			ID:      rc.ID,
+			Allowed: rc.Allowed,
+		}
+	}
+
+	return recipients, nil
+}
+
+// directKey identifies one-to-one conversation of two users regardless of who started it
+func directKey(memberIDs []uuid.UUID) string {
+	keys := make([]string, len(memberIDs))
+	for i, id := range memberIDs {
+		keys[i] = id.String()
+	}
+	slices.Sort(keys)
+	return strings.Join(keys, ":")
+}
+
+// CreateConversation creates conversation with given members. One-to-one conversation is created only once,
+// the existing one is returned for the same pair of users, created reports whether it's new
+func (r *MessageRepository) CreateConversation(ctx context.Context, memberIDs []uuid.UUID, group bool) (id uuid.UUID, created bool, err error) {
+	err = inTx(ctx, r.pool, r.queries, func(q *storage.Queries) error {
+		if group {
+			id, err = q.CreateGroupConversation(ctx)
+			created = true
+		} else {
+			var row storage.CreateDirectConversationRow
+			row, err = q.CreateDirectConversation(ctx, pgtype.Text{String: directKey(memberIDs), Valid: true})
+			id, created = row.ID, row.Created
+		}
+		if err != nil {
+			return err
+		}
+
+		return q.AddConversationMembers(ctx, storage.AddConversationMembersParams{
+			ConversationID: id,
+			UserIds:        memberIDs,
+		})
+	})
+	return id, created, err
+}
+
+// GetConversation returns pgx.ErrNoRows if there's no such conversation or user isn't its member
+func (r *MessageRepository) GetConversation(ctx context.Context, userID uuid.UUID, id uuid.UUID) (*models.Conversation, error) {
+	conversationDB, err := r.queries.GetConversation(ctx, storage.GetConversationParams{
+		UserID: userID,
+		ID:     id,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return conversationDBToConversation(conversationDB), nil
+}
+
+// GetUserConversations returns conversations of user with the latest activity first
+func (r *MessageRepository) GetUserConversations(ctx context.Context, userID uuid.UUID, limit, offset int32) ([]*models.Conversation, error) {
+	conversationsDB, err := r.queries.GetUserConversations(ctx, storage.GetUserConversationsParams{
+		Limit:  limit,
+		Offset: offset,
+		UserID: userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	conversations := make([]*models.Conversation, len(conversationsDB))
+	for i, c := range conversationsDB {
+		conversations[i] = conversationDBToConversation(storage.GetConversationRow(c))
+	}
+
+	return conversations, nil
+}
+
+func (r *MessageRepository) HasBlocks(ctx context.Context, userID uuid.UUID, conversationID uuid.UUID) (bool, error) {
+	return r.queries.HasBlocksInConversation(ctx, storage.HasBlocksInConversationParams{
+		UserID:         userID,
+		ConversationID: conversationID,
+	})
+}
+
+func (r *MessageRepository) CreateMessage(ctx context.Context, conversationID uuid.UUID, senderID uuid.UUID, content string) (*models.Message, error) {
+	messageDB, err := r.queries.CreateMessage(ctx, storage.CreateMessageParams{
+		ConversationID: conversationID,
+		SenderID:       senderID,
+		Content:        content,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return messageDBToMessage(messageDB), nil
+}
+
+// GetMessages returns messages older than message before (or the latest if it's nil), newest first
+func (r *MessageRepository) GetMessages(ctx context.Context, conversationID uuid.UUID, before *uuid.UUID, limit int32) ([]*models.Message, error) {
+	messagesDB, err := r.queries.GetMessages(ctx, storage.GetMessagesParams{
+		Limit:          limit,
+		ConversationID: conversationID,
+		Before:         uuidToPg(before),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make([]*models.Message, len(messagesDB))
+	for i, m := range messagesDB {
+		messages[i] = messageDBToMessage(m)
+	}
+
+	return messages, nil
+}
+
+// MarkRead marks all current messages of conversation read, returns false if user isn't its member
+func (r *MessageRepository) MarkRead(ctx context.Context, userID uuid.UUID, conversationID uuid.UUID) (bool, error) {
+	updated, err := r.queries.MarkConversationRead(ctx, storage.MarkConversationReadParams{
+		ConversationID: conversationID,
+		UserID:         userID,
+	})
+	return updated > 0, err
+}
+
+func (r *MessageRepository) GetUnreadCount(ctx context.Context, userID uuid.UUID) (int64, error) {
+	return r.queries.GetUnreadMessagesCount(ctx, userID)
+}
@@ -5,47 +5,137 @@ import (
 	"errors"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/misshanya/mitter/internal/db/sqlc/storage"
 	"github.com/misshanya/mitter/internal/models"
+	"slices"
+	"time"
 )
 
 type MittRepository struct {
+	pool    *pgxpool.Pool
 	queries *storage.Queries
 }
 
-func NewMittRepository(q *storage.Queries) *MittRepository {
-	return &MittRepository{queries: q}
+func NewMittRepository(pool *pgxpool.Pool, q *storage.Queries) *MittRepository {
+	return &MittRepository{pool: pool, queries: q}
 }
 
 func mittDBToMitt(mittDB storage.Mitt) *models.Mitt {
+	var deletedAt *time.Time
+	if mittDB.DeletedAt.Valid {
+		deletedAt = &mittDB.DeletedAt.Time
+	}
+
+	var expiresAt *time.Time
+	if mittDB.ExpiresAt.Valid {
+		expiresAt = &mittDB.ExpiresAt.Time
+	}
+
 	return &models.Mitt{
 		ID:        mittDB.ID,
 		AuthorID:  mittDB.Author,
 		Content:   mittDB.Content,
 		CreatedAt: mittDB.CreatedAt.Time,
 		UpdatedAt: mittDB.UpdatedAt.Time,
+		Likes:     mittDB.LikesCount,
+		Revisions: mittDB.RevisionsCount,
+		Version:   mittDB.Version,
+		DeletedAt: deletedAt,
+		ExpiresAt: expiresAt,
+	}
+}
+
+// mittRowToMitt converts mitt joined with its author. Rows of other queries
+// with the same columns can be converted to storage.GetMittRow
+func mittRowToMitt(row storage.GetMittRow) *models.Mitt {
+	var expiresAt *time.Time
+	if row.ExpiresAt.Valid {
+		expiresAt = &row.ExpiresAt.Time
+	}
+
+	return &models.Mitt{
+		ID:         row.ID,
+		AuthorID:   row.Author,
+		AuthorName: row.AuthorName,
+		Content:    row.Content,
+		CreatedAt:  row.CreatedAt.Time,
+		UpdatedAt:  row.UpdatedAt.Time,
+		Likes:      row.LikesCount,
+		Revisions:  row.RevisionsCount,
+		Version:    row.Version,
+		ExpiresAt:  expiresAt,
 	}
 }
 
+// createMitt creates mitt with its hashtags, mentions and poll and counts it for author
+func createMitt(ctx context.Context, q *storage.Queries, userID uuid.UUID, mitt *models.MittCreate) (storage.Mitt, error) {
+	var expiresAt *time.Time
+	if mitt.ExpiresAt != nil {
+		// Timestamps are stored without time zone in UTC
+		utc := mitt.ExpiresAt.UTC()
+		expiresAt = &utc
+	}
+
+	mittDB, err := q.CreateMitt(ctx, storage.CreateMittParams{
+		Author:    userID,
+		Content:   mitt.Content,
+		ExpiresAt: timeToPg(expiresAt),
+	})
+	if err != nil {
+		return mittDB, err
+	}
+
+	if err := linkMittHashtags(ctx, q, mittDB.ID, mitt.Hashtags); err != nil {
+		return mittDB, err
+	}
+
+	if err := linkMittMentions(ctx, q, mittDB.ID, mitt.Mentions); err != nil {
+		return mittDB, err
+	}
+
+	if err := createPoll(ctx, q, mittDB.ID, mitt.Poll); err != nil {
+		return mittDB, err
+	}
+
+	return mittDB, q.AddUserMittsCount(ctx, storage.AddUserMittsCountParams{
+		Delta: 1,
+		ID:    userID,
+	})
+}
+
 func (r *MittRepository) CreateMitt(ctx context.Context, userID uuid.UUID, mitt *models.MittCreate) (*models.Mitt, error) {
-	mittDB, err := r.queries.CreateMitt(ctx, storage.CreateMittParams{
-		Author:  userID,
-		Content: mitt.Content,
+	var mittDB storage.Mitt
+	err := inTx(ctx, r.pool, r.queries, func(q *storage.Queries) error {
+		var err error
+		mittDB, err = createMitt(ctx, q, userID, mitt)
+		return err
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	return mittDBToMitt(mittDB), nil
+	newMitt := mittDBToMitt(mittDB)
+	if err := attachRelated(ctx, r.queries, newMitt); err != nil {
+		return nil, err
+	}
+
+	return newMitt, nil
 }
 
 func (r *MittRepository) GetMitt(ctx context.Context, id uuid.UUID) (*models.Mitt, error) {
-	mittDB, err := r.queries.GetMitt(ctx, id)
+	mittRow, err := r.queries.GetMitt(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
-	return mittDBToMitt(mittDB), nil
+	mitt := mittRowToMitt(mittRow)
+	if err := attachRelated(ctx, r.queries, mitt); err != nil {
+		return nil, err
+	}
+
+	return mitt, nil
 }
 
 func (r *MittRepository) GetAllUserMitts(ctx context.Context, userID uuid.UUID, limit, offset int32) ([]*models.Mitt, error) {
@@ -59,36 +149,306 @@ func (r *MittRepository) GetAllUserMitts(ctx context.Context, userID uuid.UUID,
 	}
 
 	mitts := make([]*models.Mitt, len(mittsDB))
+	ids := make([]uuid.UUID, len(mittsDB))
 	for i, mittDB := range mittsDB {
-		mitts[i] = mittDBToMitt(mittDB)
+		mitts[i] = mittRowToMitt(storage.GetMittRow(mittDB))
+		ids[i] = mittDB.ID
+	}
+
+	if err := attachRelated(ctx, r.queries, mitts...); err != nil {
+		return nil, err
+	}
+
+	pinned, err := r.queries.GetPinnedMittIDs(ctx, storage.GetPinnedMittIDsParams{
+		UserID:  userID,
+		MittIds: ids,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range mitts {
+		m.Pinned = slices.Contains(pinned, m.ID)
 	}
 
 	return mitts, nil
 }
 
+// UpdateMitt saves current content of mitt as a revision and updates it with its hashtags.
+// Returns models.ErrVersionConflict if mitt doesn't have expected version
 func (r *MittRepository) UpdateMitt(ctx context.Context, mittID uuid.UUID, mitt *models.MittUpdate) (*models.Mitt, error) {
-	mittDB, err := r.queries.UpdateMitt(ctx, storage.UpdateMittParams{
-		ID:      mittID,
-		Content: mitt.Content,
+	expectedVersion := pgtype.Int4{}
+	if mitt.ExpectedVersion != nil {
+		expectedVersion = pgtype.Int4{Int32: *mitt.ExpectedVersion, Valid: true}
+	}
+
+	var mittDB storage.Mitt
+	err := inTx(ctx, r.pool, r.queries, func(q *storage.Queries) error {
+		// Saving revision locks the mitt, so it holds exactly the content being replaced
+		if err := q.SaveMittRevision(ctx, mittID); err != nil {
+			return err
+		}
+
+		var err error
+		mittDB, err = q.UpdateMitt(ctx, storage.UpdateMittParams{
+			ID:              mittID,
+			Content:         mitt.Content,
+			ExpectedVersion: expectedVersion,
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := setMittHashtags(ctx, q, mittID, mitt.Hashtags); err != nil {
+			return err
+		}
+
+		return setMittMentions(ctx, q, mittID, mitt.Mentions)
 	})
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) && mitt.ExpectedVersion != nil {
+			return nil, models.ErrVersionConflict
+		}
+		return nil, err
+	}
+
+	newMitt := mittDBToMitt(mittDB)
+	if err := attachRelated(ctx, r.queries, newMitt); err != nil {
 		return nil, err
 	}
 
-	return mittDBToMitt(mittDB), nil
+	return newMitt, nil
+}
+
+func (r *MittRepository) GetMittRevisions(ctx context.Context, mittID uuid.UUID, limit, offset int32) ([]*models.MittRevision, error) {
+	revisionsDB, err := r.queries.GetMittRevisions(ctx, storage.GetMittRevisionsParams{
+		Limit:  limit,
+		Offset: offset,
+		MittID: mittID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	revisions := make([]*models.MittRevision, len(revisionsDB))
+	for i, rev := range revisionsDB {
+		revisions[i] = revisionDBToRevision(rev)
+	}
+
+	return revisions, nil
+}
+
+func (r *MittRepository) GetMittsRevisions(ctx context.Context, mittIDs []uuid.UUID) (map[uuid.UUID][]*models.MittRevision, error) {
+	revisionsDB, err := r.queries.GetMittsRevisions(ctx, mittIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Mitts without revisions are just missing in map
+	revisions := make(map[uuid.UUID][]*models.MittRevision)
+	for _, rev := range revisionsDB {
+		revisions[rev.MittID] = append(revisions[rev.MittID], revisionDBToRevision(rev))
+	}
+
+	return revisions, nil
+}
+
+func revisionDBToRevision(rev storage.MittRevision) *models.MittRevision {
+	return &models.MittRevision{
+		ID:        rev.ID,
+		MittID:    rev.MittID,
+		Content:   rev.Content,
+		CreatedAt: rev.CreatedAt.Time,
+	}
+}
+
+// Pins
+
+func (r *MittRepository) PinMitt(ctx context.Context, userID uuid.UUID, mittID uuid.UUID, max int) error {
+	return inTx(ctx, r.pool, r.queries, func(q *storage.Queries) error {
+		isPrivate, err := q.LockUserPins(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if isPrivate {
+			return models.ErrPrivateAccount
+		}
+
+		pinned, err := q.PinMitt(ctx, storage.PinMittParams{
+			MittID: mittID,
+			UserID: userID,
+		})
+		if err != nil || pinned == 0 {
+			return err
+		}
+
+		count, err := q.CountPinnedMitts(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if count > int64(max) {
+			// Rolls back the pin
+			return models.ErrPinLimit
+		}
+		return nil
+	})
+}
+
+// UnpinMitt returns false if mitt wasn't pinned by user
+func (r *MittRepository) UnpinMitt(ctx context.Context, userID uuid.UUID, mittID uuid.UUID) (bool, error) {
+	unpinned, err := r.queries.UnpinMitt(ctx, storage.UnpinMittParams{
+		MittID: mittID,
+		UserID: userID,
+	})
+	return unpinned > 0, err
 }
 
+// DeleteMitt moves mitt to trash and unpins it, restoring doesn't pin it back
 func (r *MittRepository) DeleteMitt(ctx context.Context, mittID uuid.UUID) error {
-	return r.queries.DeleteMitt(ctx, mittID)
+	return inTx(ctx, r.pool, r.queries, func(q *storage.Queries) error {
+		authorID, err := q.DeleteMitt(ctx, mittID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				// Already deleted, nothing to count
+				return nil
+			}
+			return err
+		}
+
+		if err := q.DeleteMittPin(ctx, mittID); err != nil {
+			return err
+		}
+
+		return q.AddUserMittsCount(ctx, storage.AddUserMittsCountParams{
+			Delta: -1,
+			ID:    authorID,
+		})
+	})
+}
+
+// RestoreMitt restores mitt of user from trash if it was deleted less than retention ago.
+// Returns false if there is no such mitt in trash
+func (r *MittRepository) RestoreMitt(ctx context.Context, userID uuid.UUID, mittID uuid.UUID, retention time.Duration) (bool, error) {
+	restored := false
+	err := inTx(ctx, r.pool, r.queries, func(q *storage.Queries) error {
+		authorID, err := q.RestoreMitt(ctx, storage.RestoreMittParams{
+			ID:               mittID,
+			Author:           userID,
+			RetentionSeconds: int64(retention.Seconds()),
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil
+			}
+			return err
+		}
+		restored = true
+
+		return q.AddUserMittsCount(ctx, storage.AddUserMittsCountParams{
+			Delta: 1,
+			ID:    authorID,
+		})
+	})
+	return restored, err
+}
+
+func (r *MittRepository) GetDeletedUserMitts(ctx context.Context, userID uuid.UUID, limit, offset int32) ([]*models.Mitt, error) {
+	mittsDB, err := r.queries.GetDeletedUserMitts(ctx, storage.GetDeletedUserMittsParams{
+		Limit:  limit,
+		Offset: offset,
+		Author: userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	mitts := make([]*models.Mitt, len(mittsDB))
+	for i, mittDB := range mittsDB {
+		mitts[i] = mittDBToMitt(mittDB)
+	}
+
+	if err := attachRelated(ctx, r.queries, mitts...); err != nil {
+		return nil, err
+	}
+
+	return mitts, nil
+}
+
+// PurgeDeletedMitts hard deletes up to batchSize mitts which are in trash longer than retention
+func (r *MittRepository) PurgeDeletedMitts(ctx context.Context, retention time.Duration, batchSize int32) (int64, error) {
+	return r.queries.PurgeDeletedMitts(ctx, storage.PurgeDeletedMittsParams{
+		RetentionSeconds: int64(retention.Seconds()),
+		BatchSize:        batchSize,
+	})
+}
+
+// DeleteExpiredMitts hard deletes up to batchSize expired mitts with their likes and everything else linked to them.
+// Every mitt is deleted once even if several instances run it at the same time.
+// Mitts counts of authors are recounted rather than decremented, as reconciling them skips expired mitts already
+func (r *MittRepository) DeleteExpiredMitts(ctx context.Context, batchSize int32) ([]*models.ExpiredMitt, error) {
+	var rows []storage.DeleteExpiredMittsRow
+	err := inTx(ctx, r.pool, r.queries, func(q *storage.Queries) error {
+		var err error
+		rows, err = q.DeleteExpiredMitts(ctx, batchSize)
+		if err != nil {
+			return err
+		}
+
+		var authors []uuid.UUID
+		for _, row := range rows {
+			// Mitts in trash are already uncounted
+			if row.Active && !slices.Contains(authors, row.Author) {
+				authors = append(authors, row.Author)
+			}
+		}
+		if len(authors) == 0 {
+			return nil
+		}
+
+		// Authors are locked before counting, so concurrently created mitts are counted once
+		if err := q.LockUsers(ctx, authors); err != nil {
+			return err
+		}
+		_, err = q.ReconcileUsersCounts(ctx, authors)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	expired := make([]*models.ExpiredMitt, len(rows))
+	for i, row := range rows {
+		expired[i] = &models.ExpiredMitt{
+			ID:        row.ID,
+			AuthorID:  row.Author,
+			Content:   row.Content,
+			CreatedAt: row.CreatedAt.Time,
+			Active:    row.Active,
+		}
+	}
+	return expired, nil
 }
 
 // Likes
 
-func (r *MittRepository) LikeMitt(ctx context.Context, userID uuid.UUID, mittID uuid.UUID) error {
-	return r.queries.LikeMitt(ctx, storage.LikeMittParams{
-		UserID: userID,
-		MittID: mittID,
+// LikeMitt returns true if like was created and false if mitt was already liked
+func (r *MittRepository) LikeMitt(ctx context.Context, userID uuid.UUID, mittID uuid.UUID) (bool, error) {
+	var created bool
+	err := inTx(ctx, r.pool, r.queries, func(q *storage.Queries) error {
+		inserted, err := q.LikeMitt(ctx, storage.LikeMittParams{
+			UserID: userID,
+			MittID: mittID,
+		})
+		if err != nil || inserted == 0 {
+			return err
+		}
+		created = true
+
+		return q.AddMittLikesCount(ctx, storage.AddMittLikesCountParams{
+			Delta: inserted,
+			ID:    mittID,
+		})
 	})
+	return created, err
 }
 
 func (r *MittRepository) IsMittLikedByUser(ctx context.Context, userID uuid.UUID, mittID uuid.UUID) (bool, error) {
@@ -105,15 +465,46 @@ func (r *MittRepository) IsMittLikedByUser(ctx context.Context, userID uuid.UUID
 	return true, nil
 }
 
-func (r *MittRepository) DeleteMittLike(ctx context.Context, userID uuid.UUID, mittID uuid.UUID) error {
-	return r.queries.DeleteMittLike(ctx, storage.DeleteMittLikeParams{
-		UserID: userID,
-		MittID: mittID,
+// DeleteMittLike returns true if like was deleted and false if there was no like
+func (r *MittRepository) DeleteMittLike(ctx context.Context, userID uuid.UUID, mittID uuid.UUID) (bool, error) {
+	var removed bool
+	err := inTx(ctx, r.pool, r.queries, func(q *storage.Queries) error {
+		deleted, err := q.DeleteMittLike(ctx, storage.DeleteMittLikeParams{
+			UserID: userID,
+			MittID: mittID,
+		})
+		if err != nil || deleted == 0 {
+			return err
+		}
+		removed = true
+
+		return q.AddMittLikesCount(ctx, storage.AddMittLikesCountParams{
+			Delta: -deleted,
+			ID:    mittID,
+		})
 	})
+	return removed, err
 }
 
-func (r *MittRepository) GetMittLikesCount(ctx context.Context, mittID uuid.UUID) (int64, error) {
-	return r.queries.GetMittLikesCount(ctx, mittID)
+func (r *MittRepository) GetUserLikes(ctx context.Context, userID uuid.UUID, limit, offset int32) ([]*models.MittLike, error) {
+	likesDB, err := r.queries.GetUserLikes(ctx, storage.GetUserLikesParams{
+		Limit:  limit,
+		Offset: offset,
+		UserID: userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	likes := make([]*models.MittLike, len(likesDB))
+	for i, likeDB := range likesDB {
+		likes[i] = &models.MittLike{
+			MittID:  likeDB.MittID,
+			LikedAt: likeDB.LikedAt.Time,
+		}
+	}
+
+	return likes, nil
 }
 
 func (r *MittRepository) Feed(ctx context.Context, limit, offset int32) ([]*models.Mitt, error) {
@@ -127,7 +518,34 @@ func (r *MittRepository) Feed(ctx context.Context, limit, offset int32) ([]*mode
 
 	mitts := make([]*models.Mitt, len(mittsDB))
 	for i, mittDB := range mittsDB {
-		mitts[i] = mittDBToMitt(mittDB)
+		mitts[i] = mittRowToMitt(storage.GetMittRow(mittDB))
+	}
+
+	if err := attachRelated(ctx, r.queries, mitts...); err != nil {
+		return nil, err
+	}
+
+	return mitts, nil
+}
+
+// GetMentionedMitts returns mitts mentioning user, except ones by users blocked or muted by them
+func (r *MittRepository) GetMentionedMitts(ctx context.Context, userID uuid.UUID, limit, offset int32) ([]*models.Mitt, error) {
+	mittsDB, err := r.queries.GetMentionedMitts(ctx, storage.GetMentionedMittsParams{
+		Limit:  limit,
+		Offset: offset,
+		UserID: userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	mitts := make([]*models.Mitt, len(mittsDB))
+	for i, mittDB := range mittsDB {
+		mitts[i] = mittRowToMitt(storage.GetMittRow(mittDB))
+	}
+
+	if err := attachRelated(ctx, r.queries, mitts...); err != nil {
+		return nil, err
 	}
 
 	return mitts, nil
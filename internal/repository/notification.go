@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/misshanya/mitter/internal/db/sqlc/storage"
+	"github.com/misshanya/mitter/internal/models"
+)
+
+type NotificationRepository struct {
+	pool    *pgxpool.Pool
+	queries *storage.Queries
+}
+
+func NewNotificationRepository(pool *pgxpool.Pool, q *storage.Queries) *NotificationRepository {
+	return &NotificationRepository{pool: pool, queries: q}
+}
+
+func uuidToPg(id *uuid.UUID) pgtype.UUID {
+	if id == nil {
+		return pgtype.UUID{}
+	}
+	return pgtype.UUID{Bytes: *id, Valid: true}
+}
+
+func uuidFromPg(id pgtype.UUID) *uuid.UUID {
+	if !id.Valid {
+		return nil
+	}
+	u := uuid.UUID(id.Bytes)
+	return &u
+}
+
+// CreateNotification returns false if recipient doesn't want it: it's their own action,
+// actor is blocked or muted, type is disabled or they were already notified
+func (r *NotificationRepository) CreateNotification(ctx context.Context, event *models.NotificationEvent) (bool, error) {
+	created, err := r.queries.CreateNotification(ctx, storage.CreateNotificationParams{
+		ActorID: event.ActorID,
+		Type:    string(event.Type),
+		MittID:  uuidToPg(event.MittID),
+		UserID:  uuidToPg(event.UserID),
+	})
+	return created > 0, err
+}
+
+// GetNotificationGroups returns groups of notifications with up to actorsLimit latest actors, latest first
+func (r *NotificationRepository) GetNotificationGroups(ctx context.Context, userID uuid.UUID, actorsLimit, limit, offset int32) ([]*models.NotificationGroup, error) {
+	groupsDB, err := r.queries.GetNotificationGroups(ctx, storage.GetNotificationGroupsParams{
+		Limit:       limit,
+		Offset:      offset,
+		ActorsLimit: actorsLimit,
+		UserID:      userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make([]*models.NotificationGroup, len(groupsDB))
+	for i, g := range groupsDB {
+		groups[i] = &models.NotificationGroup{
+			ID:          g.ID,
+			Type:        models.NotificationType(g.Type),
+			MittID:      uuidFromPg(g.MittID),
+			ActorIDs:    g.ActorIds,
+			ActorsCount: g.ActorsCount,
+			Unread:      g.Unread,
+			LatestAt:    g.LatestAt.Time,
+		}
+	}
+
+	return groups, nil
+}
+
+func (r *NotificationRepository) GetUnreadCount(ctx context.Context, userID uuid.UUID) (int64, error) {
+	return r.queries.GetUnreadNotificationsCount(ctx, userID)
+}
+
+// MarkGroupRead marks notification and the rest of its group read, returns false if user has no such notification
+func (r *NotificationRepository) MarkGroupRead(ctx context.Context, userID uuid.UUID, id uuid.UUID) (bool, error) {
+	found, err := r.queries.MarkNotificationGroupRead(ctx, storage.MarkNotificationGroupReadParams{
+		ID:     id,
+		UserID: userID,
+	})
+	return found > 0, err
+}
+
+func (r *NotificationRepository) MarkAllRead(ctx context.Context, userID uuid.UUID) error {
+	return r.queries.MarkAllNotificationsRead(ctx, userID)
+}
+
+// GetPreferences returns only saved preferences, types without them are enabled
+func (r *NotificationRepository) GetPreferences(ctx context.Context, userID uuid.UUID) (models.NotificationPreferences, error) {
+	prefsDB, err := r.queries.GetNotificationPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	prefs := make(models.NotificationPreferences, len(prefsDB))
+	for _, p := range prefsDB {
+		prefs[models.NotificationType(p.Type)] = p.Enabled
+	}
+
+	return prefs, nil
+}
+
+func (r *NotificationRepository) SetPreferences(ctx context.Context, userID uuid.UUID, prefs models.NotificationPreferences) error {
+	return inTx(ctx, r.pool, r.queries, func(q *storage.Queries) error {
+		for t, enabled := range prefs {
+			if err := q.SetNotificationPreference(ctx, storage.SetNotificationPreferenceParams{
+				UserID:  userID,
+				Type:    string(t),
+				Enabled: enabled,
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/misshanya/mitter/internal/db/sqlc/storage"
+	"github.com/misshanya/mitter/internal/models"
+)
+
+type PollRepository struct {
+	queries *storage.Queries
+}
+
+func NewPollRepository(q *storage.Queries) *PollRepository {
+	return &PollRepository{queries: q}
+}
+
+func createPoll(ctx context.Context, q *storage.Queries, mittID uuid.UUID, poll *models.PollCreate) error {
+	if poll == nil {
+		return nil
+	}
+
+	// Timestamps are stored without time zone in UTC
+	expiresAt := poll.ExpiresAt.UTC()
+	return q.CreatePoll(ctx, storage.CreatePollParams{
+		MittID:         mittID,
+		Options:        poll.Options,
+		MultipleChoice: poll.MultipleChoice,
+		ExpiresAt:      timeToPg(&expiresAt),
+	})
+}
+
+// attachPolls sets polls of mitts which have them, in one query. Votes of current user aren't set
+func attachPolls(ctx context.Context, q *storage.Queries, mitts ...*models.Mitt) error {
+	if len(mitts) == 0 {
+		return nil
+	}
+
+	ids := make([]uuid.UUID, len(mitts))
+	for i, mitt := range mitts {
+		ids[i] = mitt.ID
+	}
+
+	rows, err := q.GetPolls(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	polls := make(map[uuid.UUID]*models.Poll, len(rows))
+	for _, row := range rows {
+		poll := &models.Poll{
+			Options:        make([]*models.PollOption, len(row.Options)),
+			MultipleChoice: row.MultipleChoice,
+			ExpiresAt:      row.ExpiresAt.Time,
+			VotersCount:    row.VotersCount,
+		}
+		if row.ClosedAt.Valid {
+			poll.ClosedAt = &row.ClosedAt.Time
+		}
+		for i, text := range row.Options {
+			poll.Options[i] = &models.PollOption{Text: text}
+			if i < len(row.Votes) {
+				poll.Options[i].Votes = row.Votes[i]
+			}
+		}
+		polls[row.MittID] = poll
+	}
+
+	for _, mitt := range mitts {
+		mitt.Poll = polls[mitt.ID]
+	}
+
+	return nil
+}
+
+// attachRelated sets everything stored apart from mitts: mentions and polls
+func attachRelated(ctx context.Context, q *storage.Queries, mitts ...*models.Mitt) error {
+	if err := attachMentions(ctx, q, mitts...); err != nil {
+		return err
+	}
+	return attachPolls(ctx, q, mitts...)
+}
+
+// Vote returns false if user already voted, poll is expired, closed or doesn't exist
+func (r *PollRepository) Vote(ctx context.Context, userID uuid.UUID, mittID uuid.UUID, choices []int) (bool, error) {
+	choicesDB := make([]int16, len(choices))
+	for i, c := range choices {
+		choicesDB[i] = int16(c)
+	}
+
+	created, err := r.queries.VotePoll(ctx, storage.VotePollParams{
+		UserID:  userID,
+		Choices: choicesDB,
+		MittID:  mittID,
+	})
+	return created > 0, err
+}
+
+func (r *PollRepository) GetChoices(ctx context.Context, userID uuid.UUID, mittIDs []uuid.UUID) (map[uuid.UUID][]int, error) {
+	rows, err := r.queries.GetPollChoices(ctx, storage.GetPollChoicesParams{
+		UserID:  userID,
+		MittIds: mittIDs,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	choices := make(map[uuid.UUID][]int, len(rows))
+	for _, row := range rows {
+		c := make([]int, len(row.Choices))
+		for i, choice := range row.Choices {
+			c[i] = int(choice)
+		}
+		choices[row.MittID] = c
+	}
+
+	return choices, nil
+}
+
+func (r *PollRepository) ClosePolls(ctx context.Context, batchSize int32) ([]*models.PollClosed, error) {
+	rows, err := r.queries.ClosePolls(ctx, batchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	closed := make([]*models.PollClosed, len(rows))
+	for i, row := range rows {
+		closed[i] = &models.PollClosed{
+			MittID:   row.MittID,
+			AuthorID: row.Author,
+			Active:   row.Active,
+		}
+	}
+
+	return closed, nil
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/misshanya/mitter/internal/db/sqlc/storage"
+	"github.com/misshanya/mitter/internal/models"
+)
+
+type SearchRepository struct {
+	queries *storage.Queries
+}
+
+func NewSearchRepository(q *storage.Queries) *SearchRepository {
+	return &SearchRepository{queries: q}
+}
+
+func timeToPg(t *time.Time) pgtype.Timestamp {
+	if t == nil {
+		return pgtype.Timestamp{}
+	}
+	return pgtype.Timestamp{Time: *t, Valid: true}
+}
+
+// SearchMitts returns mitts visible to viewer that match search, empty parts of search match everything
+func (r *SearchRepository) SearchMitts(ctx context.Context, search *models.MittSearch, limit, offset int32) ([]*models.Mitt, error) {
+	params := storage.SearchMittsParams{
+		Limit:       limit,
+		Offset:      offset,
+		Since:       timeToPg(search.Since),
+		Until:       timeToPg(search.Until),
+		ViewerID:    search.ViewerID,
+		ByRelevance: search.Order == models.SearchByRelevance,
+	}
+	if search.Text != "" {
+		params.Query = pgtype.Text{String: search.Text, Valid: true}
+	}
+	// NULL disables filter, empty array would match nothing
+	if len(search.From) > 0 {
+		params.FromLogins = search.From
+	}
+	if len(search.Hashtags) > 0 {
+		params.Hashtags = search.Hashtags
+	}
+
+	mittsDB, err := r.queries.SearchMitts(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	mitts := make([]*models.Mitt, len(mittsDB))
+	for i, mittDB := range mittsDB {
+		mitts[i] = mittRowToMitt(storage.GetMittRow(mittDB))
+	}
+
+	if err := attachRelated(ctx, r.queries, mitts...); err != nil {
+		return nil, err
+	}
+
+	return mitts, nil
+}
+
+// likeEscaper escapes wildcards of LIKE pattern, so they match literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// SearchUsers returns IDs of users whose login or name is similar to query or whose login starts with it,
+// users viewer follows or their friends follow go first. Users who blocked viewer or are blocked by them are skipped
+func (r *SearchRepository) SearchUsers(ctx context.Context, viewerID uuid.UUID, query string, limit, offset int32) ([]uuid.UUID, error) {
+	return r.queries.SearchUsers(ctx, storage.SearchUsersParams{
+		Limit:       limit,
+		Offset:      offset,
+		ViewerID:    viewerID,
+		Query:       query,
+		LoginPrefix: likeEscaper.Replace(strings.ToLower(query)),
+	})
+}
+
+// AutocompleteLogins returns IDs of users whose login starts with prefix, the most followed first
+func (r *SearchRepository) AutocompleteLogins(ctx context.Context, prefix string, limit int32) ([]uuid.UUID, error) {
+	return r.queries.AutocompleteLogins(ctx, storage.AutocompleteLoginsParams{
+		Limit:       limit,
+		LoginPrefix: likeEscaper.Replace(strings.ToLower(prefix)),
+	})
+}
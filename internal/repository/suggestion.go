@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+type SuggestionRepository struct {
+	rdb *redis.Client
+}
+
+func NewSuggestionRepository(rdb *redis.Client) *SuggestionRepository {
+	return &SuggestionRepository{
+		rdb: rdb,
+	}
+}
+
+func suggestionsKey(userID uuid.UUID) string {
+	return "suggestions:" + userID.String()
+}
+
+// Held by the instance which refreshes suggestions
+const suggestionsRefreshLockKey = "suggestions:refresh:lock"
+
+func (r *SuggestionRepository) SaveSuggestions(ctx context.Context, userID uuid.UUID, ids []uuid.UUID, ttl time.Duration) error {
+	idsStr := make([]string, len(ids))
+	for i, id := range ids {
+		idsStr[i] = id.String()
+	}
+
+	return r.rdb.Set(ctx, suggestionsKey(userID), strings.Join(idsStr, ","), ttl).Err()
+}
+
+// GetSuggestions returns cached suggestions and false if there is nothing cached for the user
+func (r *SuggestionRepository) GetSuggestions(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, bool, error) {
+	val, err := r.rdb.Get(ctx, suggestionsKey(userID)).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	if val == "" {
+		return []uuid.UUID{}, true, nil
+	}
+
+	parts := strings.Split(val, ",")
+	ids := make([]uuid.UUID, len(parts))
+	for i, part := range parts {
+		id, err := uuid.Parse(part)
+		if err != nil {
+			return nil, false, err
+		}
+		ids[i] = id
+	}
+
+	return ids, true, nil
+}
+
+// AcquireRefreshLock takes the refresh lock for ttl, returns false if another instance holds it
+func (r *SuggestionRepository) AcquireRefreshLock(ctx context.Context, ttl time.Duration) (bool, error) {
+	return r.rdb.SetNX(ctx, suggestionsRefreshLockKey, "1", ttl).Result()
+}
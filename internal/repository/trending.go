@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/misshanya/mitter/internal/models"
+	"github.com/redis/go-redis/v9"
+)
+
+// Sorted set of hashtags by score, rebuilt by RefreshTrendingHashtags
+const trendingHashtagsKey = "trending:hashtags"
+
+// TrendingRepository counts hashtag uses in sorted sets per time bucket,
+// trends compare the last window of buckets with the window before it
+type TrendingRepository struct {
+	rdb    *redis.Client
+	window time.Duration
+	bucket time.Duration
+}
+
+func NewTrendingRepository(rdb *redis.Client, window, bucket time.Duration) *TrendingRepository {
+	return &TrendingRepository{
+		rdb:    rdb,
+		window: window,
+		bucket: bucket,
+	}
+}
+
+func hashtagUsesKey(bucketStart time.Time) string {
+	return "trending:hashtags:" + strconv.FormatInt(bucketStart.Unix(), 10)
+}
+
+// AddHashtagUses adds delta uses of tags to the bucket of time at.
+// Buckets are kept for two windows, uses older than that are ignored
+func (r *TrendingRepository) AddHashtagUses(ctx context.Context, tags []string, at time.Time, delta int64) error {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	start := at.Truncate(r.bucket)
+	expireAt := start.Add(2*r.window + r.bucket)
+	if !expireAt.After(time.Now()) {
+		return nil
+	}
+
+	key := hashtagUsesKey(start)
+
+	pipe := r.rdb.TxPipeline()
+	for _, tag := range tags {
+		pipe.ZIncrBy(ctx, key, float64(delta), tag)
+	}
+	pipe.ExpireAt(ctx, key, expireAt)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// RefreshTrendingHashtags scores hashtags by uses in the last window minus uses in the window before it
+func (r *TrendingRepository) RefreshTrendingHashtags(ctx context.Context, now time.Time) error {
+	n := max(int(r.window/r.bucket), 1)
+	current := now.Truncate(r.bucket)
+
+	keys := make([]string, 2*n)
+	weights := make([]float64, 2*n)
+	for i := range keys {
+		keys[i] = hashtagUsesKey(current.Add(-time.Duration(i) * r.bucket))
+		weights[i] = 1
+		if i >= n {
+			weights[i] = -1
+		}
+	}
+
+	pipe := r.rdb.TxPipeline()
+	pipe.ZUnionStore(ctx, trendingHashtagsKey, &redis.ZStore{
+		Keys:      keys,
+		Weights:   weights,
+		Aggregate: "SUM",
+	})
+	// Trends get stale if nobody refreshes them
+	pipe.Expire(ctx, trendingHashtagsKey, r.window)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// GetTrendingHashtags returns up to limit hashtags with positive score, highest first
+func (r *TrendingRepository) GetTrendingHashtags(ctx context.Context, limit int32) ([]*models.TrendingHashtag, error) {
+	zs, err := r.rdb.ZRevRangeByScoreWithScores(ctx, trendingHashtagsKey, &redis.ZRangeBy{
+		Min:   "(0",
+		Max:   "+inf",
+		Count: int64(limit),
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	tags := make([]*models.TrendingHashtag, len(zs))
+	for i, z := range zs {
+		tags[i] = &models.TrendingHashtag{
+			Name:  z.Member.(string),
+			Score: int64(math.Round(z.Score)),
+		}
+	}
+
+	return tags, nil
+}
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/misshanya/mitter/internal/db/sqlc/storage"
+)
+
+// inTx runs fn with queries bound to a transaction.
+// The transaction is committed if fn succeeds and rolled back otherwise
+func inTx(ctx context.Context, pool *pgxpool.Pool, q *storage.Queries, fn func(q *storage.Queries) error) error {
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := fn(q.WithTx(tx)); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
@@ -4,16 +4,40 @@ import (
 	"context"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/misshanya/mitter/internal/db/sqlc/storage"
 	"github.com/misshanya/mitter/internal/models"
+	"time"
 )
 
 type UserRepository struct {
+	pool    *pgxpool.Pool
 	queries *storage.Queries
 }
 
-func NewUserRepository(q *storage.Queries) *UserRepository {
-	return &UserRepository{queries: q}
+func NewUserRepository(pool *pgxpool.Pool, q *storage.Queries) *UserRepository {
+	return &UserRepository{pool: pool, queries: q}
+}
+
+func userDBToUser(userDB storage.User) *models.User {
+	var deactivatedAt *time.Time
+	if userDB.DeactivatedAt.Valid {
+		deactivatedAt = &userDB.DeactivatedAt.Time
+	}
+
+	return &models.User{
+		ID:                        userDB.ID,
+		Login:                     userDB.Login,
+		Name:                      userDB.Name,
+		HashedPassword:            userDB.Password,
+		IsPrivate:                 userDB.IsPrivate,
+		MessagesFromFollowingOnly: userDB.MessagesFromFollowingOnly,
+		FollowersCount:            userDB.FollowersCount,
+		FollowingCount:            userDB.FollowingCount,
+		MittsCount:                userDB.MittsCount,
+		Version:                   userDB.Version,
+		DeactivatedAt:             deactivatedAt,
+	}
 }
 
 func (r *UserRepository) CreateUser(ctx context.Context, user *models.UserCreate) (uuid.UUID, error) {
@@ -30,15 +54,7 @@ func (r *UserRepository) GetUserByLogin(ctx context.Context, login string) (*mod
 		return nil, err
 	}
 
-	// Convert db user to my model
-	user := &models.User{
-		ID:             userDB.ID,
-		Login:          userDB.Login,
-		Name:           userDB.Name,
-		HashedPassword: userDB.Password,
-	}
-
-	return user, nil
+	return userDBToUser(userDB), nil
 }
 
 func (r *UserRepository) GetUserByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
@@ -47,30 +63,128 @@ func (r *UserRepository) GetUserByID(ctx context.Context, id uuid.UUID) (*models
 		return nil, err
 	}
 
-	// Convert db user to my model
-	user := &models.User{
-		ID:             userDB.ID,
-		Login:          userDB.Login,
-		Name:           userDB.Name,
-		HashedPassword: userDB.Password,
+	return userDBToUser(userDB), nil
+}
+
+func (r *UserRepository) GetUsersByIDs(ctx context.Context, ids []uuid.UUID) ([]*models.User, error) {
+	usersDB, err := r.queries.GetUsersByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	users := make([]*models.User, len(usersDB))
+	for i, userDB := range usersDB {
+		users[i] = userDBToUser(userDB)
 	}
 
-	return user, nil
+	return users, nil
+}
+
+// DeactivateUser returns false if user doesn't exist or is already deactivated
+func (r *UserRepository) DeactivateUser(ctx context.Context, id uuid.UUID) (bool, error) {
+	deactivated, err := r.queries.DeactivateUser(ctx, id)
+	return deactivated > 0, err
+}
+
+// ReactivateUser returns false if user isn't deactivated or grace period is over
+func (r *UserRepository) ReactivateUser(ctx context.Context, id uuid.UUID, gracePeriod time.Duration) (bool, error) {
+	reactivated, err := r.queries.ReactivateUser(ctx, storage.ReactivateUserParams{
+		ID:                 id,
+		GracePeriodSeconds: int64(gracePeriod.Seconds()),
+	})
+	return reactivated > 0, err
+}
+
+func (r *UserRepository) GetUsersToPurge(ctx context.Context, gracePeriod time.Duration, limit int32) ([]uuid.UUID, error) {
+	return r.queries.GetUsersToPurge(ctx, storage.GetUsersToPurgeParams{
+		Limit:              limit,
+		GracePeriodSeconds: int64(gracePeriod.Seconds()),
+	})
+}
+
+// DeleteUserLikes deletes a batch of user's likes and decrements likes counters of liked mitts
+func (r *UserRepository) DeleteUserLikes(ctx context.Context, id uuid.UUID, batchSize int32) (int64, error) {
+	return r.queries.DeleteUserLikes(ctx, storage.DeleteUserLikesParams{
+		UserID:    id,
+		BatchSize: batchSize,
+	})
+}
+
+// DeleteUserFollows deletes a batch of user's follows in both directions and updates follow counters
+func (r *UserRepository) DeleteUserFollows(ctx context.Context, id uuid.UUID, batchSize int32) (int64, error) {
+	return r.queries.DeleteUserFollows(ctx, storage.DeleteUserFollowsParams{
+		UserID:    id,
+		BatchSize: batchSize,
+	})
+}
+
+// DeleteUserMitts hard deletes a batch of user's mitts, including ones in trash
+func (r *UserRepository) DeleteUserMitts(ctx context.Context, id uuid.UUID, batchSize int32) (int64, error) {
+	return r.queries.DeleteUserMitts(ctx, storage.DeleteUserMittsParams{
+		Author:    id,
+		BatchSize: batchSize,
+	})
 }
 
+// DeleteUser deletes deactivated user with blocks and mutes,
+// likes, follows and mitts must be deleted before
 func (r *UserRepository) DeleteUser(ctx context.Context, id uuid.UUID) error {
-	return r.queries.DeleteUser(ctx, id)
+	return inTx(ctx, r.pool, r.queries, func(q *storage.Queries) error {
+		if err := q.DeleteUserBlocks(ctx, id); err != nil {
+			return err
+		}
+		if err := q.DeleteUserMutes(ctx, id); err != nil {
+			return err
+		}
+		return q.DeleteUser(ctx, id)
+	})
 }
 
+// UpdateUser returns models.ErrVersionConflict if user doesn't have expected version
 func (r *UserRepository) UpdateUser(ctx context.Context, id uuid.UUID, user *models.UserUpdate) error {
 	name := pgtype.Text{}
 	if user.Name != nil {
 		name = pgtype.Text{String: *user.Name, Valid: true}
 	}
 
-	return r.queries.UpdateUser(ctx, storage.UpdateUserParams{
-		Name: name,
-		ID:   id,
+	isPrivate := pgtype.Bool{}
+	if user.IsPrivate != nil {
+		isPrivate = pgtype.Bool{Bool: *user.IsPrivate, Valid: true}
+	}
+
+	messagesFromFollowingOnly := pgtype.Bool{}
+	if user.MessagesFromFollowingOnly != nil {
+		messagesFromFollowingOnly = pgtype.Bool{Bool: *user.MessagesFromFollowingOnly, Valid: true}
+	}
+
+	expectedVersion := pgtype.Int4{}
+	if user.ExpectedVersion != nil {
+		expectedVersion = pgtype.Int4{Int32: *user.ExpectedVersion, Valid: true}
+	}
+
+	return inTx(ctx, r.pool, r.queries, func(q *storage.Queries) error {
+		updated, err := q.UpdateUser(ctx, storage.UpdateUserParams{
+			Name:                      name,
+			IsPrivate:                 isPrivate,
+			MessagesFromFollowingOnly: messagesFromFollowingOnly,
+			ID:                        id,
+			ExpectedVersion:           expectedVersion,
+		})
+		if err != nil {
+			return err
+		}
+		if updated == 0 {
+			if user.ExpectedVersion != nil {
+				return models.ErrVersionConflict
+			}
+			return nil
+		}
+
+		// Private accounts can't have pins
+		if isPrivate.Valid && isPrivate.Bool {
+			return q.DeleteUserPins(ctx, id)
+		}
+		return nil
 	})
 }
 
@@ -86,16 +200,37 @@ func (r *UserRepository) ChangePassword(ctx context.Context, id uuid.UUID, newHa
 }
 
 func (r *UserRepository) FollowUser(ctx context.Context, followerID uuid.UUID, followeeID uuid.UUID) error {
-	return r.queries.FollowUser(ctx, storage.FollowUserParams{
-		FollowerID: followerID,
-		FolloweeID: followeeID,
+	return inTx(ctx, r.pool, r.queries, func(q *storage.Queries) error {
+		if err := q.FollowUser(ctx, storage.FollowUserParams{
+			FollowerID: followerID,
+			FolloweeID: followeeID,
+		}); err != nil {
+			return err
+		}
+
+		return q.AddFollowCounts(ctx, storage.AddFollowCountsParams{
+			FollowerID: followerID,
+			Delta:      1,
+			FolloweeID: followeeID,
+		})
 	})
 }
 
 func (r *UserRepository) UnfollowUser(ctx context.Context, followerID uuid.UUID, followeeID uuid.UUID) error {
-	return r.queries.UnfollowUser(ctx, storage.UnfollowUserParams{
-		FollowerID: followerID,
-		FolloweeID: followeeID,
+	return inTx(ctx, r.pool, r.queries, func(q *storage.Queries) error {
+		deleted, err := q.UnfollowUser(ctx, storage.UnfollowUserParams{
+			FollowerID: followerID,
+			FolloweeID: followeeID,
+		})
+		if err != nil || deleted == 0 {
+			return err
+		}
+
+		return q.AddFollowCounts(ctx, storage.AddFollowCountsParams{
+			FollowerID: followerID,
+			Delta:      -deleted,
+			FolloweeID: followeeID,
+		})
 	})
 }
 
@@ -122,3 +257,136 @@ func (r *UserRepository) GetUserFriends(ctx context.Context, userID uuid.UUID, l
 		Offset: offset,
 	})
 }
+
+func (r *UserRepository) IsFollowing(ctx context.Context, followerID uuid.UUID, followeeID uuid.UUID) (bool, error) {
+	return r.queries.IsFollowing(ctx, storage.IsFollowingParams{
+		FollowerID: followerID,
+		FolloweeID: followeeID,
+	})
+}
+
+func (r *UserRepository) GetKnownFollowers(ctx context.Context, viewerID uuid.UUID, userID uuid.UUID, limit, offset int32) ([]uuid.UUID, error) {
+	return r.queries.GetKnownFollowers(ctx, storage.GetKnownFollowersParams{
+		Limit:    limit,
+		Offset:   offset,
+		ViewerID: viewerID,
+		UserID:   userID,
+	})
+}
+
+func (r *UserRepository) GetRelationships(ctx context.Context, userID uuid.UUID, ids []uuid.UUID) ([]*models.Relationship, error) {
+	relationshipsDB, err := r.queries.GetRelationships(ctx, storage.GetRelationshipsParams{
+		UserID: userID,
+		Ids:    ids,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	relationships := make([]*models.Relationship, len(relationshipsDB))
+	for i, rel := range relationshipsDB {
+		relationships[i] = &models.Relationship{
+			UserID:     rel.ID,
+			Following:  rel.Following,
+			FollowedBy: rel.FollowedBy,
+			Blocking:   rel.Blocking,
+			BlockedBy:  rel.BlockedBy,
+			Muting:     rel.Muting,
+		}
+	}
+
+	return relationships, nil
+}
+
+func (r *UserRepository) ListUserIDs(ctx context.Context, after uuid.UUID, limit int32) ([]uuid.UUID, error) {
+	return r.queries.ListUserIDs(ctx, storage.ListUserIDsParams{
+		Limit: limit,
+		After: after,
+	})
+}
+
+// Blocks and mutes
+
+func (r *UserRepository) BlockUser(ctx context.Context, blockerID uuid.UUID, blockedID uuid.UUID) error {
+	return inTx(ctx, r.pool, r.queries, func(q *storage.Queries) error {
+		// Blocking breaks the follow relation in both directions
+		deleted, err := q.DeleteFollowsBetween(ctx, storage.DeleteFollowsBetweenParams{
+			FirstID:  blockerID,
+			SecondID: blockedID,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, follow := range deleted {
+			if err := q.AddFollowCounts(ctx, storage.AddFollowCountsParams{
+				FollowerID: follow.FollowerID,
+				Delta:      -1,
+				FolloweeID: follow.FolloweeID,
+			}); err != nil {
+				return err
+			}
+		}
+
+		return q.BlockUser(ctx, storage.BlockUserParams{
+			BlockerID: blockerID,
+			BlockedID: blockedID,
+		})
+	})
+}
+
+func (r *UserRepository) UnblockUser(ctx context.Context, blockerID uuid.UUID, blockedID uuid.UUID) error {
+	return r.queries.UnblockUser(ctx, storage.UnblockUserParams{
+		BlockerID: blockerID,
+		BlockedID: blockedID,
+	})
+}
+
+func (r *UserRepository) IsBlockedBetween(ctx context.Context, firstID uuid.UUID, secondID uuid.UUID) (bool, error) {
+	return r.queries.IsBlockedBetween(ctx, storage.IsBlockedBetweenParams{
+		FirstID:  firstID,
+		SecondID: secondID,
+	})
+}
+
+func (r *UserRepository) MuteUser(ctx context.Context, muterID uuid.UUID, mutedID uuid.UUID) error {
+	return r.queries.MuteUser(ctx, storage.MuteUserParams{
+		MuterID: muterID,
+		MutedID: mutedID,
+	})
+}
+
+func (r *UserRepository) UnmuteUser(ctx context.Context, muterID uuid.UUID, mutedID uuid.UUID) error {
+	return r.queries.UnmuteUser(ctx, storage.UnmuteUserParams{
+		MuterID: muterID,
+		MutedID: mutedID,
+	})
+}
+
+// GetHiddenUserIDs returns users whose content is hidden from user: blocked by or blocking user, or muted by user
+func (r *UserRepository) GetHiddenUserIDs(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	return r.queries.GetHiddenUserIDs(ctx, userID)
+}
+
+// Suggestions
+
+func (r *UserRepository) SuggestFollows(ctx context.Context, userID uuid.UUID, limit int32) ([]uuid.UUID, error) {
+	return r.queries.SuggestFollows(ctx, storage.SuggestFollowsParams{
+		Limit:  limit,
+		UserID: userID,
+	})
+}
+
+func (r *UserRepository) GetPopularUsers(ctx context.Context, userID uuid.UUID, limit int32) ([]uuid.UUID, error) {
+	return r.queries.GetPopularUsers(ctx, storage.GetPopularUsersParams{
+		Limit:  limit,
+		UserID: userID,
+	})
+}
+
+func (r *UserRepository) FilterSuggestable(ctx context.Context, userID uuid.UUID, candidateIDs []uuid.UUID) ([]uuid.UUID, error) {
+	return r.queries.FilterSuggestable(ctx, storage.FilterSuggestableParams{
+		CandidateIds: candidateIDs,
+		UserID:       userID,
+	})
+}
@@ -0,0 +1,184 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/misshanya/mitter/internal/db/sqlc/storage"
+	"github.com/misshanya/mitter/internal/models"
+)
+
+type WebhookRepository struct {
+	queries *storage.Queries
+}
+
+func NewWebhookRepository(q *storage.Queries) *WebhookRepository {
+	return &WebhookRepository{queries: q}
+}
+
+func webhookDBToWebhook(webhookDB storage.Webhook) *models.Webhook {
+	events := make([]models.NotificationType, len(webhookDB.EventTypes))
+	for i, t := range webhookDB.EventTypes {
+		events[i] = models.NotificationType(t)
+	}
+
+	return &models.Webhook{
+		ID:        webhookDB.ID,
+		UserID:    webhookDB.UserID,
+		URL:       webhookDB.Url,
+		Secret:    webhookDB.Secret,
+		Events:    events,
+		CreatedAt: webhookDB.CreatedAt.Time,
+	}
+}
+
+func (r *WebhookRepository) CreateWebhook(ctx context.Context, userID uuid.UUID, secret string, webhook *models.WebhookCreate) (*models.Webhook, error) {
+	events := make([]string, len(webhook.Events))
+	for i, t := range webhook.Events {
+		events[i] = string(t)
+	}
+
+	webhookDB, err := r.queries.CreateWebhook(ctx, storage.CreateWebhookParams{
+		UserID:     userID,
+		Url:        webhook.URL,
+		Secret:     secret,
+		EventTypes: events,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return webhookDBToWebhook(webhookDB), nil
+}
+
+func (r *WebhookRepository) GetWebhook(ctx context.Context, userID uuid.UUID, id uuid.UUID) (*models.Webhook, error) {
+	webhookDB, err := r.queries.GetWebhook(ctx, storage.GetWebhookParams{
+		ID:     id,
+		UserID: userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return webhookDBToWebhook(webhookDB), nil
+}
+
+func (r *WebhookRepository) GetUserWebhooks(ctx context.Context, userID uuid.UUID) ([]*models.Webhook, error) {
+	webhooksDB, err := r.queries.GetUserWebhooks(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	webhooks := make([]*models.Webhook, len(webhooksDB))
+	for i, w := range webhooksDB {
+		webhooks[i] = webhookDBToWebhook(w)
+	}
+
+	return webhooks, nil
+}
+
+func (r *WebhookRepository) DeleteWebhook(ctx context.Context, userID uuid.UUID, id uuid.UUID) (bool, error) {
+	deleted, err := r.queries.DeleteWebhook(ctx, storage.DeleteWebhookParams{
+		ID:     id,
+		UserID: userID,
+	})
+	return deleted > 0, err
+}
+
+// CreateDeliveries skips deactivated recipients and actors they blocked
+func (r *WebhookRepository) CreateDeliveries(ctx context.Context, event *models.NotificationEvent) (int64, error) {
+	return r.queries.CreateWebhookDeliveries(ctx, storage.CreateWebhookDeliveriesParams{
+		EventType: string(event.Type),
+		MittID:    uuidToPg(event.MittID),
+		ActorID:   event.ActorID,
+		UserID:    uuidToPg(event.UserID),
+	})
+}
+
+func (r *WebhookRepository) GetDeliveries(ctx context.Context, webhookID uuid.UUID, limit, offset int32) ([]*models.WebhookDelivery, error) {
+	deliveriesDB, err := r.queries.GetWebhookDeliveries(ctx, storage.GetWebhookDeliveriesParams{
+		Limit:     limit,
+		Offset:    offset,
+		WebhookID: webhookID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries := make([]*models.WebhookDelivery, len(deliveriesDB))
+	for i, d := range deliveriesDB {
+		delivery := &models.WebhookDelivery{
+			ID:        d.ID,
+			WebhookID: d.WebhookID,
+			Event:     models.NotificationType(d.EventType),
+			Payload:   d.Payload,
+			Status:    models.WebhookDeliveryStatus(d.Status),
+			Attempts:  d.Attempts,
+			Error:     d.Error.String,
+			CreatedAt: d.CreatedAt.Time,
+		}
+		if d.ResponseCode.Valid {
+			delivery.ResponseCode = &d.ResponseCode.Int32
+		}
+		if d.LastAttemptAt.Valid {
+			delivery.LastAttemptAt = &d.LastAttemptAt.Time
+		}
+		if d.DeliveredAt.Valid {
+			delivery.DeliveredAt = &d.DeliveredAt.Time
+		}
+		deliveries[i] = delivery
+	}
+
+	return deliveries, nil
+}
+
+func (r *WebhookRepository) ClaimDeliveries(ctx context.Context, lease time.Duration, batchSize int32) ([]*models.ClaimedWebhookDelivery, error) {
+	deliveriesDB, err := r.queries.ClaimWebhookDeliveries(ctx, storage.ClaimWebhookDeliveriesParams{
+		LeaseSeconds: int64(lease.Seconds()),
+		BatchSize:    batchSize,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries := make([]*models.ClaimedWebhookDelivery, len(deliveriesDB))
+	for i, d := range deliveriesDB {
+		deliveries[i] = &models.ClaimedWebhookDelivery{
+			ID:       d.ID,
+			Event:    models.NotificationType(d.EventType),
+			Payload:  d.Payload,
+			Attempts: d.Attempts,
+			URL:      d.Url,
+			Secret:   d.Secret,
+		}
+	}
+
+	return deliveries, nil
+}
+
+func (r *WebhookRepository) RecordAttempt(ctx context.Context, id uuid.UUID, attempt *models.WebhookAttempt) error {
+	var responseCode pgtype.Int4
+	if attempt.ResponseCode != nil {
+		responseCode = pgtype.Int4{Int32: *attempt.ResponseCode, Valid: true}
+	}
+
+	return r.queries.RecordWebhookAttempt(ctx, storage.RecordWebhookAttemptParams{
+		Status:       string(attempt.Status),
+		ResponseCode: responseCode,
+		Error:        pgtype.Text{String: attempt.Error, Valid: attempt.Error != ""},
+		RetryAfterMs: attempt.RetryAfter.Milliseconds(),
+		ID:           id,
+	})
+}
+
+// Redeliver returns pgx.ErrNoRows if user has no such delivery
+func (r *WebhookRepository) Redeliver(ctx context.Context, userID uuid.UUID, webhookID uuid.UUID, id uuid.UUID) (models.WebhookDeliveryStatus, error) {
+	status, err := r.queries.RedeliverWebhookDelivery(ctx, storage.RedeliverWebhookDeliveryParams{
+		ID:        id,
+		WebhookID: webhookID,
+		UserID:    userID,
+	})
+	return models.WebhookDeliveryStatus(status), err
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/misshanya/mitter/pkg/pgutil"
 	"log/slog"
 	"net/http"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -18,24 +19,86 @@ type Service struct {
 	ur models.UserRepository
 	ar models.AuthRepository
 	um models.UserMetrics
+
+	deletionGracePeriod time.Duration
 }
 
-func NewAuthService(ur models.UserRepository, ar models.AuthRepository, um models.UserMetrics) *Service {
-	return &Service{ur: ur, ar: ar, um: um}
+func NewAuthService(ur models.UserRepository, ar models.AuthRepository, um models.UserMetrics, deletionGracePeriod time.Duration) *Service {
+	return &Service{ur: ur, ar: ar, um: um, deletionGracePeriod: deletionGracePeriod}
 }
 
 func (s *Service) SignIn(ctx context.Context, creds models.SignIn) (string, *models.HTTPError) {
+	user, httpErr := s.checkCredentials(ctx, creds)
+	if httpErr != nil {
+		return "", httpErr
+	}
+
+	if user.DeactivatedAt != nil {
+		return "", &models.HTTPError{
+			Code:    http.StatusForbidden,
+			Message: "Account is deactivated",
+		}
+	}
+
+	return s.issueToken(ctx, user.ID)
+}
+
+// Reactivate restores deactivated account during grace period and signs in
+func (s *Service) Reactivate(ctx context.Context, creds models.SignIn) (string, *models.HTTPError) {
+	user, httpErr := s.checkCredentials(ctx, creds)
+	if httpErr != nil {
+		return "", httpErr
+	}
+
+	if user.DeactivatedAt == nil {
+		return "", &models.HTTPError{
+			Code:    http.StatusConflict,
+			Message: "Account is not deactivated",
+		}
+	}
+
+	// Remove the mark first, so failed request can be retried. Tokens issued before deactivation were revoked
+	if err := s.ar.SetUserDeactivated(ctx, user.ID, false); err != nil {
+		slog.Error("error unmarking user as deactivated", slog.Any("err", err))
+		return "", &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error",
+		}
+	}
+
+	reactivated, err := s.ur.ReactivateUser(ctx, user.ID, s.deletionGracePeriod)
+	if err != nil {
+		slog.Error("error reactivating user", slog.Any("err", err))
+		return "", &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error",
+		}
+	}
+	if !reactivated {
+		return "", &models.HTTPError{
+			Code:    http.StatusGone,
+			Message: "Account can't be reactivated anymore",
+		}
+	}
+
+	// Update metrics
+	go s.um.AddUser()
+
+	return s.issueToken(ctx, user.ID)
+}
+
+func (s *Service) checkCredentials(ctx context.Context, creds models.SignIn) (*models.User, *models.HTTPError) {
 	user, err := s.ur.GetUserByLogin(ctx, creds.Login)
 	if err != nil {
 		// If user not found
 		if errors.Is(err, pgx.ErrNoRows) {
-			return "", &models.HTTPError{
+			return nil, &models.HTTPError{
 				Code:    http.StatusUnauthorized,
 				Message: "Invalid login or password",
 			}
 		}
 
-		return "", &models.HTTPError{
+		return nil, &models.HTTPError{
 			Code:    http.StatusInternalServerError,
 			Message: "Internal Server Error",
 		}
@@ -45,25 +108,31 @@ func (s *Service) SignIn(ctx context.Context, creds models.SignIn) (string, *mod
 	ok, err := crypto.ComparePasswordAndHash(creds.Password, user.HashedPassword)
 	if err != nil {
 		slog.Error("error comparing password while signing in", slog.Any("err", err))
-		return "", &models.HTTPError{
+		return nil, &models.HTTPError{
 			Code:    http.StatusInternalServerError,
 			Message: "Internal server error",
 		}
 	}
 	if !ok {
-		return "", &models.HTTPError{
+		return nil, &models.HTTPError{
 			Code:    http.StatusBadRequest,
 			Message: "Invalid login or password",
 		}
 	}
 
+	return user, nil
+}
+
+// issueToken saves new token of active user. Account could be deactivated after credentials were checked,
+// and revoking its tokens could miss this one, so it's checked again after saving
+func (s *Service) issueToken(ctx context.Context, userID uuid.UUID) (string, *models.HTTPError) {
 	// Generate access token
 	token := uuid.New()
 
 	// Save token to Redis
 	if err := s.ar.SaveToken(ctx, &models.Token{
 		Token:  token,
-		UserID: user.ID,
+		UserID: userID,
 	}); err != nil {
 		slog.Error("error saving token to redis", slog.Any("err", err))
 		return "", &models.HTTPError{
@@ -72,9 +141,33 @@ func (s *Service) SignIn(ctx context.Context, creds models.SignIn) (string, *mod
 		}
 	}
 
+	user, err := s.ur.GetUserByID(ctx, userID)
+	if err != nil {
+		slog.Error("error checking user after saving token", slog.Any("err", err))
+		s.deleteToken(ctx, userID, token.String())
+		return "", &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error",
+		}
+	}
+	if user.DeactivatedAt != nil {
+		s.deleteToken(ctx, userID, token.String())
+		return "", &models.HTTPError{
+			Code:    http.StatusForbidden,
+			Message: "Account is deactivated",
+		}
+	}
+
 	return token.String(), nil
 }
 
+// deleteToken deletes token which must not be used. If it fails, token of deactivated user is still rejected by its mark
+func (s *Service) deleteToken(ctx context.Context, userID uuid.UUID, token string) {
+	if err := s.ar.DeleteToken(ctx, userID, token); err != nil {
+		slog.Error("error deleting token", slog.Any("err", err))
+	}
+}
+
 func (s *Service) SignUp(ctx context.Context, user *models.UserCreate) (uuid.UUID, *models.HTTPError) {
 	// Hash password and store it in user.HashedPassword
 	hashedPassword, err := crypto.GenerateHash(user.Password)
@@ -2,6 +2,7 @@ package auth
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/misshanya/mitter/internal/models"
@@ -15,10 +16,29 @@ var (
 		HashedPassword: "$argon2id$v=19$m=65536,t=3,p=2$VUNCT0J2RG9NV2xSd1d0eQ$7604v3WFNe5CL1Nt1hVUKXZnuZAuP0l3LSzxRUFtZl0",
 	}
 	testUserID = uuid.MustParse("b096376a-5fa9-4130-907a-709c67008a65")
+
+	deactivatedAt       = time.Now().Add(-time.Hour)
+	testDeactivatedUser = models.User{
+		ID:             uuid.MustParse("38386ffe-54ac-48be-9244-a5144b41a014"),
+		Login:          "deactivated",
+		Name:           "Deactivated User",
+		HashedPassword: testUser.HashedPassword,
+		DeactivatedAt:  &deactivatedAt,
+	}
+
+	// testDeactivatingUser is deactivated right after its credentials are checked
+	testDeactivatingUser = models.User{
+		ID:             uuid.MustParse("0f5c1b8e-3d7a-4a52-9c1e-6b2f8d4e7a10"),
+		Login:          "deactivating",
+		Name:           "Deactivating User",
+		HashedPassword: testUser.HashedPassword,
+	}
 )
 
 // Mock Auth repo
-type mockAuthRepo struct{}
+type mockAuthRepo struct {
+	deleted []string
+}
 
 func (r *mockAuthRepo) SaveToken(ctx context.Context, token *models.Token) error {
 	_ = ctx
@@ -34,6 +54,29 @@ func (r *mockAuthRepo) GetUserIDByToken(ctx context.Context, token string) (uuid
 	return testUserID, nil
 }
 
+func (r *mockAuthRepo) RevokeUserTokens(ctx context.Context, userID uuid.UUID) error {
+	_ = ctx
+	_ = userID
+
+	return nil
+}
+
+func (r *mockAuthRepo) DeleteToken(ctx context.Context, userID uuid.UUID, token string) error {
+	_ = ctx
+	_ = userID
+
+	r.deleted = append(r.deleted, token)
+	return nil
+}
+
+func (r *mockAuthRepo) SetUserDeactivated(ctx context.Context, userID uuid.UUID, deactivated bool) error {
+	_ = ctx
+	_ = userID
+	_ = deactivated
+
+	return nil
+}
+
 // Mock User repo
 type mockUserRepo struct{}
 
@@ -46,18 +89,81 @@ func (r *mockUserRepo) CreateUser(ctx context.Context, user *models.UserCreate)
 
 func (r *mockUserRepo) GetUserByLogin(ctx context.Context, login string) (*models.User, error) {
 	_ = ctx
-	_ = login
 
+	switch login {
+	case testDeactivatedUser.Login:
+		return &testDeactivatedUser, nil
+	case testDeactivatingUser.Login:
+		return &testDeactivatingUser, nil
+	}
 	return &testUser, nil
 }
 
 func (r *mockUserRepo) GetUserByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
 	_ = ctx
-	_ = id
 
+	if id == testDeactivatingUser.ID {
+		user := testDeactivatingUser
+		user.DeactivatedAt = &deactivatedAt
+		return &user, nil
+	}
 	return &testUser, nil
 }
 
+func (r *mockUserRepo) GetUsersByIDs(ctx context.Context, ids []uuid.UUID) ([]*models.User, error) {
+	_ = ctx
+	_ = ids
+
+	return []*models.User{&testUser}, nil
+}
+
+func (r *mockUserRepo) DeactivateUser(ctx context.Context, id uuid.UUID) (bool, error) {
+	_ = ctx
+	_ = id
+
+	return true, nil
+}
+
+func (r *mockUserRepo) ReactivateUser(ctx context.Context, id uuid.UUID, gracePeriod time.Duration) (bool, error) {
+	_ = ctx
+	_ = id
+	_ = gracePeriod
+
+	return true, nil
+}
+
+func (r *mockUserRepo) GetUsersToPurge(ctx context.Context, gracePeriod time.Duration, limit int32) ([]uuid.UUID, error) {
+	_ = ctx
+	_ = gracePeriod
+	_ = limit
+
+	return nil, nil
+}
+
+func (r *mockUserRepo) DeleteUserLikes(ctx context.Context, id uuid.UUID, batchSize int32) (int64, error) {
+	_ = ctx
+	_ = id
+	_ = batchSize
+
+	return 0, nil
+}
+
+func (r *mockUserRepo) DeleteUserFollows(ctx context.Context, id uuid.UUID, batchSize int32) (int64, error) {
+	_ = ctx
+	_ = id
+	_ = batchSize
+
+	return 0, nil
+}
+
+func (r *mockUserRepo) DeleteUserMitts(ctx context.Context, id uuid.UUID, batchSize int32) (int64, error) {
+	_ = ctx
+	_ = id
+	_ = batchSize
+
+	return 0, nil
+}
+
 func (r *mockUserRepo) DeleteUser(ctx context.Context, id uuid.UUID) error {
 	_ = ctx
 	_ = id
@@ -132,6 +238,111 @@ func (r *mockUserRepo) GetUserFriends(ctx context.Context, userID uuid.UUID, lim
 	return []uuid.UUID{testUserID}, nil
 }
 
+func (r *mockUserRepo) ListUserIDs(ctx context.Context, after uuid.UUID, limit int32) ([]uuid.UUID, error) {
+	_ = ctx
+	_ = after
+	_ = limit
+
+	return []uuid.UUID{}, nil
+}
+
+func (r *mockUserRepo) BlockUser(ctx context.Context, blockerID uuid.UUID, blockedID uuid.UUID) error {
+	_ = ctx
+	_ = blockerID
+	_ = blockedID
+
+	return nil
+}
+
+func (r *mockUserRepo) UnblockUser(ctx context.Context, blockerID uuid.UUID, blockedID uuid.UUID) error {
+	_ = ctx
+	_ = blockerID
+	_ = blockedID
+
+	return nil
+}
+
+func (r *mockUserRepo) IsBlockedBetween(ctx context.Context, firstID uuid.UUID, secondID uuid.UUID) (bool, error) {
+	_ = ctx
+	_ = firstID
+	_ = secondID
+
+	return false, nil
+}
+
+func (r *mockUserRepo) MuteUser(ctx context.Context, muterID uuid.UUID, mutedID uuid.UUID) error {
+	_ = ctx
+	_ = muterID
+	_ = mutedID
+
+	return nil
+}
+
+func (r *mockUserRepo) UnmuteUser(ctx context.Context, muterID uuid.UUID, mutedID uuid.UUID) error {
+	_ = ctx
+	_ = muterID
+	_ = mutedID
+
+	return nil
+}
+
+func (r *mockUserRepo) GetHiddenUserIDs(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	_ = ctx
+	_ = userID
+
+	return nil, nil
+}
+
+func (r *mockUserRepo) SuggestFollows(ctx context.Context, userID uuid.UUID, limit int32) ([]uuid.UUID, error) {
+	_ = ctx
+	_ = userID
+	_ = limit
+
+	return []uuid.UUID{}, nil
+}
+
+func (r *mockUserRepo) GetPopularUsers(ctx context.Context, userID uuid.UUID, limit int32) ([]uuid.UUID, error) {
+	_ = ctx
+	_ = userID
+	_ = limit
+
+	return []uuid.UUID{}, nil
+}
+
+func (r *mockUserRepo) FilterSuggestable(ctx context.Context, userID uuid.UUID, candidateIDs []uuid.UUID) ([]uuid.UUID, error) {
+	_ = ctx
+	_ = userID
+	_ = candidateIDs
+
+	return candidateIDs, nil
+}
+
+func (r *mockUserRepo) IsFollowing(ctx context.Context, followerID uuid.UUID, followeeID uuid.UUID) (bool, error) {
+	_ = ctx
+	_ = followerID
+	_ = followeeID
+
+	return false, nil
+}
+
+func (r *mockUserRepo) GetKnownFollowers(ctx context.Context, viewerID uuid.UUID, userID uuid.UUID, limit, offset int32) ([]uuid.UUID, error) {
+	_ = ctx
+	_ = viewerID
+	_ = userID
+	_ = limit
+	_ = offset
+
+	return []uuid.UUID{}, nil
+}
+
+func (r *mockUserRepo) GetRelationships(ctx context.Context, userID uuid.UUID, ids []uuid.UUID) ([]*models.Relationship, error) {
+	_ = ctx
+	_ = userID
+	_ = ids
+
+	return []*models.Relationship{}, nil
+}
+
 // Mock user metrics
 type mockUserMetrics struct {
 	FakeUsersCount int
@@ -2,7 +2,9 @@ package auth
 
 import (
 	"context"
+	"net/http"
 	"testing"
+	"time"
 
 	"github.com/misshanya/mitter/internal/models"
 	"github.com/stretchr/testify/assert"
@@ -10,7 +12,7 @@ import (
 
 // Tests
 func TestAuthService_SignIn(t *testing.T) {
-	service := NewAuthService(&mockUserRepo{}, &mockAuthRepo{}, &mockUserMetrics{})
+	service := NewAuthService(&mockUserRepo{}, &mockAuthRepo{}, &mockUserMetrics{}, time.Hour)
 
 	ctx := context.Background()
 
@@ -28,8 +30,65 @@ func TestAuthService_SignIn(t *testing.T) {
 	}
 }
 
+func TestAuthService_SignIn_Deactivated(t *testing.T) {
+	service := NewAuthService(&mockUserRepo{}, &mockAuthRepo{}, &mockUserMetrics{}, time.Hour)
+
+	ctx := context.Background()
+
+	creds := models.SignIn{
+		Login:    testDeactivatedUser.Login,
+		Password: "qwerty123456",
+	}
+	_, err := service.SignIn(ctx, creds)
+	if assert.NotNil(t, err) {
+		assert.Equal(t, http.StatusForbidden, err.Code)
+	}
+}
+
+func TestAuthService_SignIn_DeactivatedMeanwhile(t *testing.T) {
+	ar := &mockAuthRepo{}
+	service := NewAuthService(&mockUserRepo{}, ar, &mockUserMetrics{}, time.Hour)
+
+	ctx := context.Background()
+
+	creds := models.SignIn{
+		Login:    testDeactivatingUser.Login,
+		Password: "qwerty123456",
+	}
+	_, err := service.SignIn(ctx, creds)
+	if assert.NotNil(t, err) {
+		assert.Equal(t, http.StatusForbidden, err.Code)
+	}
+
+	// Saved token isn't left behind
+	assert.Len(t, ar.deleted, 1)
+}
+
+func TestAuthService_Reactivate(t *testing.T) {
+	service := NewAuthService(&mockUserRepo{}, &mockAuthRepo{}, &mockUserMetrics{}, time.Hour)
+
+	ctx := context.Background()
+
+	creds := models.SignIn{
+		Login:    testDeactivatedUser.Login,
+		Password: "qwerty123456",
+	}
+	token, err := service.Reactivate(ctx, creds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NotEmpty(t, token)
+
+	// Active account can't be reactivated
+	creds.Login = testUser.Login
+	_, err = service.Reactivate(ctx, creds)
+	if assert.NotNil(t, err) {
+		assert.Equal(t, http.StatusConflict, err.Code)
+	}
+}
+
 func TestAuthService_SignUp(t *testing.T) {
-	service := NewAuthService(&mockUserRepo{}, &mockAuthRepo{}, &mockUserMetrics{})
+	service := NewAuthService(&mockUserRepo{}, &mockAuthRepo{}, &mockUserMetrics{}, time.Hour)
 
 	ctx := context.Background()
 
@@ -49,7 +108,7 @@ func TestAuthService_SignUp(t *testing.T) {
 }
 
 func TestAuthService_ChangePassword(t *testing.T) {
-	service := NewAuthService(&mockUserRepo{}, &mockAuthRepo{}, &mockUserMetrics{})
+	service := NewAuthService(&mockUserRepo{}, &mockAuthRepo{}, &mockUserMetrics{}, time.Hour)
 
 	ctx := context.Background()
 
@@ -0,0 +1,301 @@
+package bookmark
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/misshanya/mitter/internal/models"
+	"github.com/misshanya/mitter/pkg/pgutil"
+)
+
+const maxFolderNameLength = 50
+
+type Service struct {
+	br models.BookmarkRepository
+	mr models.MittRepository
+
+	maxFolders int64
+}
+
+// NewService creates bookmark service which allows up to maxFolders folders per user
+func NewService(br models.BookmarkRepository, mr models.MittRepository, maxFolders int64) *Service {
+	return &Service{
+		br:         br,
+		mr:         mr,
+		maxFolders: maxFolders,
+	}
+}
+
+// checkFolder returns error if folder doesn't belong to user, nil folder is always fine
+func (s *Service) checkFolder(ctx context.Context, userID uuid.UUID, folderID *uuid.UUID) *models.HTTPError {
+	if folderID == nil {
+		return nil
+	}
+
+	if _, err := s.br.GetFolder(ctx, userID, *folderID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return &models.HTTPError{
+				Code:    http.StatusNotFound,
+				Message: "Folder not found",
+			}
+		}
+		slog.Error("error getting bookmark folder", slog.Any("err", err))
+		return &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		}
+	}
+	return nil
+}
+
+// checkMitt returns error if mitt doesn't exist or is deleted
+func (s *Service) checkMitt(ctx context.Context, mittID uuid.UUID) *models.HTTPError {
+	if _, err := s.mr.GetMitt(ctx, mittID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return &models.HTTPError{
+				Code:    http.StatusNotFound,
+				Message: "Mitt not found",
+			}
+		}
+		slog.Error("error getting mitt", slog.Any("err", err))
+		return &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		}
+	}
+	return nil
+}
+
+// AddBookmark bookmarks mitt, optionally in folder. Bookmarking already bookmarked mitt changes nothing
+func (s *Service) AddBookmark(ctx context.Context, userID uuid.UUID, mittID uuid.UUID, folderID *uuid.UUID) *models.HTTPError {
+	if httpErr := s.checkFolder(ctx, userID, folderID); httpErr != nil {
+		return httpErr
+	}
+
+	created, err := s.br.AddBookmark(ctx, userID, mittID, folderID)
+	if err != nil {
+		if pgutil.IsForeignKeyViolation(err) {
+			// Folder was deleted meanwhile
+			return &models.HTTPError{
+				Code:    http.StatusNotFound,
+				Message: "Folder not found",
+			}
+		}
+		slog.Error("error adding bookmark", slog.Any("err", err))
+		return &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		}
+	}
+
+	if !created {
+		// Mitt is already bookmarked or doesn't exist
+		return s.checkMitt(ctx, mittID)
+	}
+	return nil
+}
+
+// DeleteBookmark removes bookmark, removing absent bookmark changes nothing
+func (s *Service) DeleteBookmark(ctx context.Context, userID uuid.UUID, mittID uuid.UUID) *models.HTTPError {
+	if _, err := s.br.DeleteBookmark(ctx, userID, mittID); err != nil {
+		slog.Error("error deleting bookmark", slog.Any("err", err))
+		return &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		}
+	}
+	return nil
+}
+
+// MoveBookmark puts bookmark in folder, nil folder takes it out of any folder
+func (s *Service) MoveBookmark(ctx context.Context, userID uuid.UUID, mittID uuid.UUID, folderID *uuid.UUID) *models.HTTPError {
+	if httpErr := s.checkFolder(ctx, userID, folderID); httpErr != nil {
+		return httpErr
+	}
+
+	moved, err := s.br.MoveBookmark(ctx, userID, mittID, folderID)
+	if err != nil {
+		if pgutil.IsForeignKeyViolation(err) {
+			return &models.HTTPError{
+				Code:    http.StatusNotFound,
+				Message: "Folder not found",
+			}
+		}
+		slog.Error("error moving bookmark", slog.Any("err", err))
+		return &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		}
+	}
+
+	if !moved {
+		return &models.HTTPError{
+			Code:    http.StatusNotFound,
+			Message: "Bookmark not found",
+		}
+	}
+	return nil
+}
+
+// GetBookmarks returns bookmarked mitts, recently bookmarked first. Nil folder means all bookmarks
+func (s *Service) GetBookmarks(ctx context.Context, userID uuid.UUID, folderID *uuid.UUID, limit, offset int32) ([]*models.Mitt, *models.HTTPError) {
+	if httpErr := s.checkFolder(ctx, userID, folderID); httpErr != nil {
+		return nil, httpErr
+	}
+
+	mitts, err := s.br.GetBookmarks(ctx, userID, folderID, limit, offset)
+	if err != nil {
+		slog.Error("error getting bookmarks", slog.Any("err", err))
+		return nil, &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		}
+	}
+	return mitts, nil
+}
+
+// MarkMitts sets Bookmarked flag of mitts which user bookmarked
+func (s *Service) MarkMitts(ctx context.Context, userID uuid.UUID, mitts []*models.Mitt) *models.HTTPError {
+	if len(mitts) == 0 {
+		return nil
+	}
+
+	ids := make([]uuid.UUID, len(mitts))
+	for i, m := range mitts {
+		ids[i] = m.ID
+	}
+
+	bookmarked, err := s.br.GetBookmarkedMittIDs(ctx, userID, ids)
+	if err != nil {
+		slog.Error("error getting bookmarked mitts", slog.Any("err", err))
+		return &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		}
+	}
+
+	set := make(map[uuid.UUID]struct{}, len(bookmarked))
+	for _, id := range bookmarked {
+		set[id] = struct{}{}
+	}
+	for _, m := range mitts {
+		_, m.Bookmarked = set[m.ID]
+	}
+	return nil
+}
+
+// Folders
+
+// normalizeFolderName trims folder name and checks its length
+func normalizeFolderName(name string) (string, *models.HTTPError) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", &models.HTTPError{
+			Code:    http.StatusBadRequest,
+			Message: "Folder name is required",
+		}
+	}
+	if utf8.RuneCountInString(name) > maxFolderNameLength {
+		return "", &models.HTTPError{
+			Code:    http.StatusBadRequest,
+			Message: "Folder name is too long",
+		}
+	}
+	return name, nil
+}
+
+func folderExists() *models.HTTPError {
+	return &models.HTTPError{
+		Code:    http.StatusConflict,
+		Message: "Folder with this name already exists",
+	}
+}
+
+func (s *Service) CreateFolder(ctx context.Context, userID uuid.UUID, name string) (*models.BookmarkFolder, *models.HTTPError) {
+	name, httpErr := normalizeFolderName(name)
+	if httpErr != nil {
+		return nil, httpErr
+	}
+
+	folder, err := s.br.CreateFolder(ctx, userID, name, s.maxFolders)
+	if err != nil {
+		if errors.Is(err, models.ErrFolderLimit) {
+			return nil, &models.HTTPError{
+				Code:    http.StatusConflict,
+				Message: "Too many folders",
+			}
+		}
+		if pgutil.IsUniqueViolation(err) {
+			return nil, folderExists()
+		}
+		slog.Error("error creating bookmark folder", slog.Any("err", err))
+		return nil, &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		}
+	}
+	return folder, nil
+}
+
+func (s *Service) GetFolders(ctx context.Context, userID uuid.UUID) ([]*models.BookmarkFolder, *models.HTTPError) {
+	folders, err := s.br.GetFolders(ctx, userID)
+	if err != nil {
+		slog.Error("error getting bookmark folders", slog.Any("err", err))
+		return nil, &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		}
+	}
+	return folders, nil
+}
+
+func (s *Service) RenameFolder(ctx context.Context, userID uuid.UUID, id uuid.UUID, name string) (*models.BookmarkFolder, *models.HTTPError) {
+	name, httpErr := normalizeFolderName(name)
+	if httpErr != nil {
+		return nil, httpErr
+	}
+
+	folder, err := s.br.RenameFolder(ctx, userID, id, name)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, &models.HTTPError{
+				Code:    http.StatusNotFound,
+				Message: "Folder not found",
+			}
+		}
+		if pgutil.IsUniqueViolation(err) {
+			return nil, folderExists()
+		}
+		slog.Error("error renaming bookmark folder", slog.Any("err", err))
+		return nil, &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		}
+	}
+	return folder, nil
+}
+
+// DeleteFolder deletes folder, its bookmarks are kept without folder
+func (s *Service) DeleteFolder(ctx context.Context, userID uuid.UUID, id uuid.UUID) *models.HTTPError {
+	deleted, err := s.br.DeleteFolder(ctx, userID, id)
+	if err != nil {
+		slog.Error("error deleting bookmark folder", slog.Any("err", err))
+		return &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		}
+	}
+	if !deleted {
+		return &models.HTTPError{
+			Code:    http.StatusNotFound,
+			Message: "Folder not found",
+		}
+	}
+	return nil
+}
@@ -0,0 +1,347 @@
+package bookmark
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/misshanya/mitter/internal/models"
+)
+
+var (
+	testUserID  = uuid.MustParse("b096376a-5fa9-4130-907a-709c67008a65")
+	testOtherID = uuid.MustParse("38386ffe-54ac-48be-9244-a5144b41a014")
+	testMittID  = uuid.MustParse("5c0a1f5b-3a44-4bb5-9e55-0b0c1b1a4a11")
+)
+
+type mockBookmark struct {
+	userID   uuid.UUID
+	mittID   uuid.UUID
+	folderID *uuid.UUID
+}
+
+type mockFolder struct {
+	userID uuid.UUID
+	folder *models.BookmarkFolder
+}
+
+// Mock bookmark repo (in-memory)
+type mockBookmarkRepo struct {
+	bookmarks []*mockBookmark
+	folders   []*mockFolder
+}
+
+func newMockBookmarkRepo() *mockBookmarkRepo {
+	return &mockBookmarkRepo{}
+}
+
+func (r *mockBookmarkRepo) find(userID uuid.UUID, mittID uuid.UUID) *mockBookmark {
+	for _, b := range r.bookmarks {
+		if b.userID == userID && b.mittID == mittID {
+			return b
+		}
+	}
+	return nil
+}
+
+func (r *mockBookmarkRepo) findFolder(userID uuid.UUID, id uuid.UUID) *mockFolder {
+	for _, f := range r.folders {
+		if f.userID == userID && f.folder.ID == id {
+			return f
+		}
+	}
+	return nil
+}
+
+func (r *mockBookmarkRepo) AddBookmark(ctx context.Context, userID uuid.UUID, mittID uuid.UUID, folderID *uuid.UUID) (bool, error) {
+	_ = ctx
+
+	if mittID != testMittID || r.find(userID, mittID) != nil {
+		return false, nil
+	}
+	r.bookmarks = append(r.bookmarks, &mockBookmark{userID: userID, mittID: mittID, folderID: folderID})
+	return true, nil
+}
+
+func (r *mockBookmarkRepo) DeleteBookmark(ctx context.Context, userID uuid.UUID, mittID uuid.UUID) (bool, error) {
+	_ = ctx
+
+	n := len(r.bookmarks)
+	r.bookmarks = slices.DeleteFunc(r.bookmarks, func(b *mockBookmark) bool {
+		return b.userID == userID && b.mittID == mittID
+	})
+	return len(r.bookmarks) < n, nil
+}
+
+func (r *mockBookmarkRepo) MoveBookmark(ctx context.Context, userID uuid.UUID, mittID uuid.UUID, folderID *uuid.UUID) (bool, error) {
+	_ = ctx
+
+	b := r.find(userID, mittID)
+	if b == nil {
+		return false, nil
+	}
+	b.folderID = folderID
+	return true, nil
+}
+
+func (r *mockBookmarkRepo) GetBookmarks(ctx context.Context, userID uuid.UUID, folderID *uuid.UUID, limit, offset int32) ([]*models.Mitt, error) {
+	_ = ctx
+	_ = limit
+	_ = offset
+
+	var mitts []*models.Mitt
+	for _, b := range r.bookmarks {
+		if b.userID != userID {
+			continue
+		}
+		if folderID != nil && (b.folderID == nil || *b.folderID != *folderID) {
+			continue
+		}
+		mitts = append(mitts, &models.Mitt{ID: b.mittID, Bookmarked: true})
+	}
+	return mitts, nil
+}
+
+func (r *mockBookmarkRepo) GetBookmarkedMittIDs(ctx context.Context, userID uuid.UUID, mittIDs []uuid.UUID) ([]uuid.UUID, error) {
+	_ = ctx
+
+	var ids []uuid.UUID
+	for _, id := range mittIDs {
+		if r.find(userID, id) != nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+func (r *mockBookmarkRepo) CreateFolder(ctx context.Context, userID uuid.UUID, name string, max int64) (*models.BookmarkFolder, error) {
+	folders, _ := r.GetFolders(ctx, userID)
+	if int64(len(folders)) >= max {
+		return nil, models.ErrFolderLimit
+	}
+
+	for _, f := range r.folders {
+		if f.userID == userID && strings.EqualFold(f.folder.Name, name) {
+			return nil, &pgconn.PgError{Code: "23505"}
+		}
+	}
+
+	folder := &models.BookmarkFolder{ID: uuid.New(), Name: name, CreatedAt: time.Now()}
+	r.folders = append(r.folders, &mockFolder{userID: userID, folder: folder})
+	return folder, nil
+}
+
+func (r *mockBookmarkRepo) GetFolder(ctx context.Context, userID uuid.UUID, id uuid.UUID) (*models.BookmarkFolder, error) {
+	_ = ctx
+
+	f := r.findFolder(userID, id)
+	if f == nil {
+		return nil, pgx.ErrNoRows
+	}
+	return f.folder, nil
+}
+
+func (r *mockBookmarkRepo) GetFolders(ctx context.Context, userID uuid.UUID) ([]*models.BookmarkFolder, error) {
+	_ = ctx
+
+	var folders []*models.BookmarkFolder
+	for _, f := range r.folders {
+		if f.userID == userID {
+			folders = append(folders, f.folder)
+		}
+	}
+	return folders, nil
+}
+
+func (r *mockBookmarkRepo) RenameFolder(ctx context.Context, userID uuid.UUID, id uuid.UUID, name string) (*models.BookmarkFolder, error) {
+	_ = ctx
+
+	f := r.findFolder(userID, id)
+	if f == nil {
+		return nil, pgx.ErrNoRows
+	}
+	f.folder.Name = name
+	return f.folder, nil
+}
+
+func (r *mockBookmarkRepo) DeleteFolder(ctx context.Context, userID uuid.UUID, id uuid.UUID) (bool, error) {
+	_ = ctx
+
+	if r.findFolder(userID, id) == nil {
+		return false, nil
+	}
+	r.folders = slices.DeleteFunc(r.folders, func(f *mockFolder) bool {
+		return f.folder.ID == id
+	})
+	// Bookmarks stay without folder
+	for _, b := range r.bookmarks {
+		if b.folderID != nil && *b.folderID == id {
+			b.folderID = nil
+		}
+	}
+	return true, nil
+}
+
+// Mock Mitt repo
+type mockMittRepo struct{}
+
+func (r *mockMittRepo) CreateMitt(ctx context.Context, userID uuid.UUID, mitt *models.MittCreate) (*models.Mitt, error) {
+	_ = ctx
+	_ = userID
+	_ = mitt
+
+	return nil, nil
+}
+
+func (r *mockMittRepo) GetMitt(ctx context.Context, id uuid.UUID) (*models.Mitt, error) {
+	_ = ctx
+
+	if id != testMittID {
+		return nil, pgx.ErrNoRows
+	}
+	return &models.Mitt{ID: id}, nil
+}
+
+func (r *mockMittRepo) GetAllUserMitts(ctx context.Context, userID uuid.UUID, limit, offset int32) ([]*models.Mitt, error) {
+	_ = ctx
+	_ = userID
+	_ = limit
+	_ = offset
+
+	return nil, nil
+}
+
+func (r *mockMittRepo) UpdateMitt(ctx context.Context, mittID uuid.UUID, mitt *models.MittUpdate) (*models.Mitt, error) {
+	_ = ctx
+	_ = mittID
+	_ = mitt
+
+	return nil, nil
+}
+
+func (r *mockMittRepo) DeleteMitt(ctx context.Context, mittID uuid.UUID) error {
+	_ = ctx
+	_ = mittID
+
+	return nil
+}
+
+func (r *mockMittRepo) RestoreMitt(ctx context.Context, userID uuid.UUID, mittID uuid.UUID, retention time.Duration) (bool, error) {
+	_ = ctx
+	_ = userID
+	_ = mittID
+	_ = retention
+
+	return false, nil
+}
+
+func (r *mockMittRepo) GetDeletedUserMitts(ctx context.Context, userID uuid.UUID, limit, offset int32) ([]*models.Mitt, error) {
+	_ = ctx
+	_ = userID
+	_ = limit
+	_ = offset
+
+	return nil, nil
+}
+
+func (r *mockMittRepo) PurgeDeletedMitts(ctx context.Context, retention time.Duration, batchSize int32) (int64, error) {
+	_ = ctx
+	_ = retention
+	_ = batchSize
+
+	return 0, nil
+}
+
+func (r *mockMittRepo) GetMittRevisions(ctx context.Context, mittID uuid.UUID, limit, offset int32) ([]*models.MittRevision, error) {
+	_ = ctx
+	_ = mittID
+	_ = limit
+	_ = offset
+
+	return nil, nil
+}
+
+func (r *mockMittRepo) GetMittsRevisions(ctx context.Context, mittIDs []uuid.UUID) (map[uuid.UUID][]*models.MittRevision, error) {
+	_ = ctx
+	_ = mittIDs
+
+	return nil, nil
+}
+
+func (r *mockMittRepo) DeleteExpiredMitts(ctx context.Context, batchSize int32) ([]*models.ExpiredMitt, error) {
+	_ = ctx
+	_ = batchSize
+
+	return nil, nil
+}
+
+func (r *mockMittRepo) PinMitt(ctx context.Context, userID uuid.UUID, mittID uuid.UUID, max int) error {
+	_ = ctx
+	_ = userID
+	_ = mittID
+	_ = max
+
+	return nil
+}
+
+func (r *mockMittRepo) UnpinMitt(ctx context.Context, userID uuid.UUID, mittID uuid.UUID) (bool, error) {
+	_ = ctx
+	_ = userID
+	_ = mittID
+
+	return false, nil
+}
+
+func (r *mockMittRepo) LikeMitt(ctx context.Context, userID uuid.UUID, mittID uuid.UUID) (bool, error) {
+	_ = ctx
+	_ = userID
+	_ = mittID
+
+	return false, nil
+}
+
+func (r *mockMittRepo) IsMittLikedByUser(ctx context.Context, userID uuid.UUID, mittID uuid.UUID) (bool, error) {
+	_ = ctx
+	_ = userID
+	_ = mittID
+
+	return false, nil
+}
+
+func (r *mockMittRepo) DeleteMittLike(ctx context.Context, userID uuid.UUID, mittID uuid.UUID) (bool, error) {
+	_ = ctx
+	_ = userID
+	_ = mittID
+
+	return false, nil
+}
+
+func (r *mockMittRepo) GetUserLikes(ctx context.Context, userID uuid.UUID, limit, offset int32) ([]*models.MittLike, error) {
+	_ = ctx
+	_ = userID
+	_ = limit
+	_ = offset
+
+	return nil, nil
+}
+
+func (r *mockMittRepo) Feed(ctx context.Context, limit, offset int32) ([]*models.Mitt, error) {
+	_ = ctx
+	_ = limit
+	_ = offset
+
+	return nil, nil
+}
+
+func (r *mockMittRepo) GetMentionedMitts(ctx context.Context, userID uuid.UUID, limit, offset int32) ([]*models.Mitt, error) {
+	_ = ctx
+	_ = userID
+	_ = limit
+	_ = offset
+
+	return nil, nil
+}
@@ -0,0 +1,166 @@
+package bookmark
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/misshanya/mitter/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_AddBookmark(t *testing.T) {
+	br := newMockBookmarkRepo()
+	service := NewService(br, &mockMittRepo{}, 10)
+	ctx := context.Background()
+
+	if err := service.AddBookmark(ctx, testUserID, testMittID, nil); err != nil {
+		t.Fatal(err)
+	}
+	// Bookmarking again changes nothing
+	if err := service.AddBookmark(ctx, testUserID, testMittID, nil); err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, br.bookmarks, 1)
+
+	err := service.AddBookmark(ctx, testUserID, uuid.New(), nil)
+	if assert.NotNil(t, err) {
+		assert.Equal(t, http.StatusNotFound, err.Code)
+		assert.Equal(t, "Mitt not found", err.Message)
+	}
+
+	// Folder of another user
+	folder, err := service.CreateFolder(ctx, testOtherID, "Later")
+	require.Nil(t, err)
+	err = service.AddBookmark(ctx, testUserID, testMittID, &folder.ID)
+	if assert.NotNil(t, err) {
+		assert.Equal(t, http.StatusNotFound, err.Code)
+		assert.Equal(t, "Folder not found", err.Message)
+	}
+}
+
+func TestService_MoveBookmark(t *testing.T) {
+	br := newMockBookmarkRepo()
+	service := NewService(br, &mockMittRepo{}, 10)
+	ctx := context.Background()
+
+	folder, err := service.CreateFolder(ctx, testUserID, "Read later")
+	require.Nil(t, err)
+
+	// Not bookmarked yet
+	err = service.MoveBookmark(ctx, testUserID, testMittID, &folder.ID)
+	if assert.NotNil(t, err) {
+		assert.Equal(t, http.StatusNotFound, err.Code)
+		assert.Equal(t, "Bookmark not found", err.Message)
+	}
+
+	require.Nil(t, service.AddBookmark(ctx, testUserID, testMittID, nil))
+	require.Nil(t, service.MoveBookmark(ctx, testUserID, testMittID, &folder.ID))
+
+	mitts, err := service.GetBookmarks(ctx, testUserID, &folder.ID, 30, 0)
+	require.Nil(t, err)
+	assert.Len(t, mitts, 1)
+
+	// Out of folder
+	require.Nil(t, service.MoveBookmark(ctx, testUserID, testMittID, nil))
+	mitts, err = service.GetBookmarks(ctx, testUserID, &folder.ID, 30, 0)
+	require.Nil(t, err)
+	assert.Empty(t, mitts)
+
+	// Still bookmarked
+	mitts, err = service.GetBookmarks(ctx, testUserID, nil, 30, 0)
+	require.Nil(t, err)
+	assert.Len(t, mitts, 1)
+}
+
+func TestService_DeleteBookmark(t *testing.T) {
+	br := newMockBookmarkRepo()
+	service := NewService(br, &mockMittRepo{}, 10)
+	ctx := context.Background()
+
+	require.Nil(t, service.AddBookmark(ctx, testUserID, testMittID, nil))
+	require.Nil(t, service.DeleteBookmark(ctx, testUserID, testMittID))
+	assert.Empty(t, br.bookmarks)
+
+	// Removing absent bookmark changes nothing
+	assert.Nil(t, service.DeleteBookmark(ctx, testUserID, testMittID))
+}
+
+func TestService_MarkMitts(t *testing.T) {
+	service := NewService(newMockBookmarkRepo(), &mockMittRepo{}, 10)
+	ctx := context.Background()
+
+	require.Nil(t, service.AddBookmark(ctx, testUserID, testMittID, nil))
+
+	mitts := []*models.Mitt{{ID: testMittID}, {ID: uuid.New(), Bookmarked: true}}
+	require.Nil(t, service.MarkMitts(ctx, testUserID, mitts))
+	assert.True(t, mitts[0].Bookmarked)
+	assert.False(t, mitts[1].Bookmarked)
+
+	// Bookmarks are private
+	require.Nil(t, service.MarkMitts(ctx, testOtherID, mitts))
+	assert.False(t, mitts[0].Bookmarked)
+}
+
+func TestService_Folders(t *testing.T) {
+	br := newMockBookmarkRepo()
+	service := NewService(br, &mockMittRepo{}, 2)
+	ctx := context.Background()
+
+	folder, err := service.CreateFolder(ctx, testUserID, "  Recipes ")
+	require.Nil(t, err)
+	assert.Equal(t, "Recipes", folder.Name)
+
+	for _, tc := range []struct {
+		name    string
+		code    int
+		message string
+	}{
+		{"   ", http.StatusBadRequest, "Folder name is required"},
+		{strings.Repeat("a", maxFolderNameLength+1), http.StatusBadRequest, "Folder name is too long"},
+		{"recipes", http.StatusConflict, "Folder with this name already exists"},
+	} {
+		_, err := service.CreateFolder(ctx, testUserID, tc.name)
+		if assert.NotNil(t, err, tc.name) {
+			assert.Equal(t, tc.code, err.Code, tc.name)
+			assert.Equal(t, tc.message, err.Message, tc.name)
+		}
+	}
+
+	_, err = service.CreateFolder(ctx, testUserID, "Memes")
+	require.Nil(t, err)
+	_, err = service.CreateFolder(ctx, testUserID, "Travel")
+	if assert.NotNil(t, err) {
+		assert.Equal(t, http.StatusConflict, err.Code)
+		assert.Equal(t, "Too many folders", err.Message)
+	}
+
+	renamed, err := service.RenameFolder(ctx, testUserID, folder.ID, "Cooking")
+	require.Nil(t, err)
+	assert.Equal(t, "Cooking", renamed.Name)
+
+	_, err = service.RenameFolder(ctx, testOtherID, folder.ID, "Mine")
+	if assert.NotNil(t, err) {
+		assert.Equal(t, http.StatusNotFound, err.Code)
+	}
+
+	// Deleting folder keeps its bookmarks
+	require.Nil(t, service.AddBookmark(ctx, testUserID, testMittID, &folder.ID))
+	require.Nil(t, service.DeleteFolder(ctx, testUserID, folder.ID))
+	mitts, err := service.GetBookmarks(ctx, testUserID, nil, 30, 0)
+	require.Nil(t, err)
+	assert.Len(t, mitts, 1)
+
+	err = service.DeleteFolder(ctx, testUserID, folder.ID)
+	if assert.NotNil(t, err) {
+		assert.Equal(t, http.StatusNotFound, err.Code)
+	}
+
+	_, err = service.GetBookmarks(ctx, testUserID, &folder.ID, 30, 0)
+	if assert.NotNil(t, err) {
+		assert.Equal(t, http.StatusNotFound, err.Code)
+	}
+}
@@ -0,0 +1,43 @@
+package counter
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/misshanya/mitter/internal/models"
+)
+
+// Service keeps denormalized counters in sync with source tables.
+// Counters are updated in the same transaction as every write,
+// so reconciliation only repairs drift caused by manual changes or bugs
+type Service struct {
+	cr models.CounterRepository
+}
+
+func NewService(cr models.CounterRepository) *Service {
+	return &Service{cr: cr}
+}
+
+// Reconcile recomputes all counters from source tables
+func (s *Service) Reconcile(ctx context.Context) error {
+	fixedMitts, err := s.cr.ReconcileMittsCounters(ctx)
+	if err != nil {
+		slog.Error("error reconciling mitts counters", slog.Any("err", err))
+		return err
+	}
+
+	fixedUsers, err := s.cr.ReconcileUsersCounters(ctx)
+	if err != nil {
+		slog.Error("error reconciling users counters", slog.Any("err", err))
+		return err
+	}
+
+	if fixedMitts > 0 || fixedUsers > 0 {
+		slog.Warn("counters drift repaired",
+			slog.Int64("mitts", fixedMitts),
+			slog.Int64("users", fixedUsers),
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,31 @@
+package counter
+
+import (
+	"context"
+	"errors"
+)
+
+// Mock Counter repo
+type mockCounterRepo struct {
+	fail bool
+
+	mittsCalls int
+	usersCalls int
+}
+
+func (r *mockCounterRepo) ReconcileMittsCounters(ctx context.Context) (int64, error) {
+	_ = ctx
+
+	r.mittsCalls++
+	if r.fail {
+		return 0, errors.New("db is down")
+	}
+	return 2, nil
+}
+
+func (r *mockCounterRepo) ReconcileUsersCounters(ctx context.Context) (int64, error) {
+	_ = ctx
+
+	r.usersCalls++
+	return 0, nil
+}
@@ -0,0 +1,24 @@
+package counter
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// Tests
+func TestCounterService_Reconcile(t *testing.T) {
+	cr := &mockCounterRepo{}
+	service := NewService(cr)
+
+	assert.NoError(t, service.Reconcile(context.Background()))
+	assert.Equal(t, 1, cr.mittsCalls)
+	assert.Equal(t, 1, cr.usersCalls)
+
+	// Users are not reconciled if mitts failed
+	cr = &mockCounterRepo{fail: true}
+	service = NewService(cr)
+
+	assert.Error(t, service.Reconcile(context.Background()))
+	assert.Equal(t, 0, cr.usersCalls)
+}
@@ -0,0 +1,170 @@
+package export
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/misshanya/mitter/internal/models"
+)
+
+// Archive format, it's a public contract, so it doesn't reuse API DTOs
+
+type profileJSON struct {
+	ID             uuid.UUID `json:"id"`
+	Login          string    `json:"login"`
+	Name           string    `json:"name"`
+	IsPrivate      bool      `json:"is_private"`
+	FollowersCount int64     `json:"followers_count"`
+	FollowingCount int64     `json:"following_count"`
+	MittsCount     int64     `json:"mitts_count"`
+}
+
+type revisionJSON struct {
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type mittJSON struct {
+	ID        uuid.UUID      `json:"id"`
+	Content   string         `json:"content"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	Likes     int64          `json:"likes"`
+	DeletedAt *time.Time     `json:"deleted_at,omitempty"`
+	Revisions []revisionJSON `json:"revisions"`
+}
+
+type likeJSON struct {
+	MittID  uuid.UUID `json:"mitt_id"`
+	LikedAt time.Time `json:"liked_at"`
+}
+
+type userRefJSON struct {
+	ID uuid.UUID `json:"id"`
+	// Empty if account is deactivated
+	Login string `json:"login,omitempty"`
+	Name  string `json:"name,omitempty"`
+}
+
+func newProfileJSON(user *models.User) profileJSON {
+	return profileJSON{
+		ID:             user.ID,
+		Login:          user.Login,
+		Name:           user.Name,
+		IsPrivate:      user.IsPrivate,
+		FollowersCount: user.FollowersCount,
+		FollowingCount: user.FollowingCount,
+		MittsCount:     user.MittsCount,
+	}
+}
+
+func newMittJSON(mitt *models.Mitt, revisions []*models.MittRevision) mittJSON {
+	m := mittJSON{
+		ID:        mitt.ID,
+		Content:   mitt.Content,
+		CreatedAt: mitt.CreatedAt,
+		UpdatedAt: mitt.UpdatedAt,
+		Likes:     mitt.Likes,
+		DeletedAt: mitt.DeletedAt,
+		Revisions: make([]revisionJSON, len(revisions)),
+	}
+	for i, revision := range revisions {
+		m.Revisions[i] = revisionJSON{
+			Content:   revision.Content,
+			CreatedAt: revision.CreatedAt,
+		}
+	}
+	return m
+}
+
+func newLikeJSON(like *models.MittLike) likeJSON {
+	return likeJSON{
+		MittID:  like.MittID,
+		LikedAt: like.LikedAt,
+	}
+}
+
+// newUserRefsJSON keeps order of ids, users missing from the list are exported by id only
+func newUserRefsJSON(ids []uuid.UUID, users []*models.User) []userRefJSON {
+	byID := make(map[uuid.UUID]*models.User, len(users))
+	for _, user := range users {
+		byID[user.ID] = user
+	}
+
+	result := make([]userRefJSON, len(ids))
+	for i, id := range ids {
+		result[i] = userRefJSON{ID: id}
+		if user, ok := byID[id]; ok {
+			result[i].Login = user.Login
+			result[i].Name = user.Name
+		}
+	}
+	return result
+}
+
+// archiveWriter packs archive as ZIP with one JSON file per section.
+// Lists are written item by item, so sections are never held in memory as a whole
+type archiveWriter struct {
+	zw *zip.Writer
+}
+
+func newArchiveWriter(w io.Writer) *archiveWriter {
+	return &archiveWriter{zw: zip.NewWriter(w)}
+}
+
+func (a *archiveWriter) writeFile(name string, content any) error {
+	w, err := a.zw.Create(name)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(content)
+}
+
+// writeList writes JSON array, writeItems calls add for each item in order
+func (a *archiveWriter) writeList(name string, writeItems func(add func(item any) error) error) error {
+	w, err := a.zw.Create(name)
+	if err != nil {
+		return err
+	}
+
+	count := 0
+	add := func(item any) error {
+		// Same layout as json.Encoder with indent gives for the whole array
+		b, err := json.MarshalIndent(item, "  ", "  ")
+		if err != nil {
+			return err
+		}
+
+		sep := ",\n  "
+		if count == 0 {
+			sep = "[\n  "
+		}
+		count++
+
+		if _, err := io.WriteString(w, sep); err != nil {
+			return err
+		}
+		_, err = w.Write(b)
+		return err
+	}
+	if err := writeItems(add); err != nil {
+		return err
+	}
+
+	end := "\n]\n"
+	if count == 0 {
+		end = "[]\n"
+	}
+	_, err = io.WriteString(w, end)
+	return err
+}
+
+func (a *archiveWriter) close() error {
+	return a.zw.Close()
+}
@@ -0,0 +1,111 @@
+package export
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/misshanya/mitter/internal/models"
+)
+
+// Archive is stored in chunks of this size, so only one chunk is held in memory
+const chunkSize = 1 << 20
+
+// chunkWriter saves archive to db chunk by chunk as it is written
+type chunkWriter struct {
+	ctx context.Context
+	er  models.ExportRepository
+	id  uuid.UUID
+
+	buf  []byte
+	seq  int32
+	size int64
+}
+
+func newChunkWriter(ctx context.Context, er models.ExportRepository, id uuid.UUID, size int) *chunkWriter {
+	return &chunkWriter{
+		ctx: ctx,
+		er:  er,
+		id:  id,
+		buf: make([]byte, 0, size),
+	}
+}
+
+func (w *chunkWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		n := min(cap(w.buf)-len(w.buf), len(p))
+		w.buf = append(w.buf, p[:n]...)
+		p = p[n:]
+		written += n
+
+		if len(w.buf) == cap(w.buf) {
+			if err := w.flush(); err != nil {
+				return written, err
+			}
+		}
+	}
+	return written, nil
+}
+
+// Close saves the rest of archive
+func (w *chunkWriter) Close() error {
+	if len(w.buf) == 0 {
+		return nil
+	}
+	return w.flush()
+}
+
+func (w *chunkWriter) flush() error {
+	if err := w.er.SaveExportChunk(w.ctx, w.id, w.seq, w.buf); err != nil {
+		return err
+	}
+	w.seq++
+	w.size += int64(len(w.buf))
+	w.buf = make([]byte, 0, cap(w.buf))
+	return nil
+}
+
+// chunkReader reads archive from db chunk by chunk
+type chunkReader struct {
+	ctx context.Context
+	er  models.ExportRepository
+	id  uuid.UUID
+
+	chunk []byte
+	seq   int32
+	done  bool
+}
+
+func newChunkReader(ctx context.Context, er models.ExportRepository, id uuid.UUID) *chunkReader {
+	return &chunkReader{
+		ctx: ctx,
+		er:  er,
+		id:  id,
+	}
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	for len(r.chunk) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+
+		chunk, err := r.er.GetExportChunk(r.ctx, r.id, r.seq)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				r.done = true
+				continue
+			}
+			return 0, err
+		}
+		r.chunk = chunk
+		r.seq++
+	}
+
+	n := copy(p, r.chunk)
+	r.chunk = r.chunk[n:]
+	return n, nil
+}
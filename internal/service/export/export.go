@@ -0,0 +1,269 @@
+package export
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/misshanya/mitter/internal/models"
+	"github.com/misshanya/mitter/pkg/pgutil"
+)
+
+const (
+	pageSize = 100
+	// Running export is taken again if its worker didn't finish it in time (e.g. instance was restarted)
+	staleExportTimeout = time.Hour
+)
+
+type Service struct {
+	er models.ExportRepository
+	ur models.UserRepository
+	mr models.MittRepository
+
+	linkTTL time.Duration
+}
+
+func NewService(er models.ExportRepository, ur models.UserRepository, mr models.MittRepository, linkTTL time.Duration) *Service {
+	return &Service{
+		er:      er,
+		ur:      ur,
+		mr:      mr,
+		linkTTL: linkTTL,
+	}
+}
+
+// RequestExport queues export, archive is built by background worker
+func (s *Service) RequestExport(ctx context.Context, userID uuid.UUID) (*models.Export, *models.HTTPError) {
+	export, err := s.er.CreateExport(ctx, userID)
+	if err != nil {
+		if pgutil.IsUniqueViolation(err) {
+			return nil, &models.HTTPError{
+				Code:    http.StatusConflict,
+				Message: "Export is already in progress",
+			}
+		}
+		slog.Error("error creating export", slog.Any("err", err))
+		return nil, &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		}
+	}
+
+	return export, nil
+}
+
+func (s *Service) GetExport(ctx context.Context, userID uuid.UUID, id uuid.UUID) (*models.Export, *models.HTTPError) {
+	export, err := s.er.GetExport(ctx, userID, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, &models.HTTPError{
+				Code:    http.StatusNotFound,
+				Message: "Export not found",
+			}
+		}
+		slog.Error("error getting export", slog.Any("err", err))
+		return nil, &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		}
+	}
+
+	return export, nil
+}
+
+// GetArchive returns ZIP archive by download token, it's read from db as the reader is read
+func (s *Service) GetArchive(ctx context.Context, downloadToken uuid.UUID) (io.Reader, *models.HTTPError) {
+	export, err := s.er.GetExportByToken(ctx, downloadToken)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, &models.HTTPError{
+				Code:    http.StatusNotFound,
+				Message: "Download link is invalid or expired",
+			}
+		}
+		slog.Error("error getting export by token", slog.Any("err", err))
+		return nil, &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		}
+	}
+
+	return newChunkReader(ctx, s.er, export.ID), nil
+}
+
+// ProcessPending builds archives of all queued exports,
+// exports are claimed with row locks, so several instances can run it at once
+func (s *Service) ProcessPending(ctx context.Context) error {
+	for {
+		export, err := s.er.ClaimExport(ctx, staleExportTimeout)
+		if err != nil {
+			slog.Error("error claiming export", slog.Any("err", err))
+			return err
+		}
+		if export == nil {
+			return nil
+		}
+
+		// Archive is saved in chunks while it's built
+		archive := newChunkWriter(ctx, s.er, export.ID, chunkSize)
+		err = s.buildArchive(ctx, export.UserID, archive)
+		if err == nil {
+			err = archive.Close()
+		}
+		if err != nil {
+			slog.Error("error building export archive", slog.String("exportID", export.ID.String()), slog.Any("err", err))
+			if err := s.er.FailExport(ctx, export.ID, "Failed to build archive", s.linkTTL); err != nil {
+				slog.Error("error marking export as failed", slog.Any("err", err))
+				return err
+			}
+			continue
+		}
+
+		if err := s.er.CompleteExport(ctx, export.ID, s.linkTTL); err != nil {
+			slog.Error("error completing export", slog.Any("err", err))
+			return err
+		}
+		slog.Info("export is ready", slog.String("exportID", export.ID.String()), slog.Int64("size", archive.size))
+	}
+}
+
+// PurgeExpired deletes exports with expired download links
+func (s *Service) PurgeExpired(ctx context.Context) error {
+	deleted, err := s.er.DeleteExpiredExports(ctx)
+	if err != nil {
+		slog.Error("error deleting expired exports", slog.Any("err", err))
+		return err
+	}
+
+	if deleted > 0 {
+		slog.Info("deleted expired exports", slog.Int64("count", deleted))
+	}
+	return nil
+}
+
+func (s *Service) buildArchive(ctx context.Context, userID uuid.UUID, w io.Writer) error {
+	user, err := s.ur.GetUserByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("getting user: %w", err)
+	}
+
+	archive := newArchiveWriter(w)
+	if err := archive.writeFile("profile.json", newProfileJSON(user)); err != nil {
+		return err
+	}
+
+	err = archive.writeList("mitts.json", func(add func(item any) error) error {
+		// Published mitts and mitts in trash
+		for _, getMitts := range []func(ctx context.Context, userID uuid.UUID, limit, offset int32) ([]*models.Mitt, error){
+			s.mr.GetAllUserMitts,
+			s.mr.GetDeletedUserMitts,
+		} {
+			err := eachPage(ctx, func(ctx context.Context, limit, offset int32) (int, error) {
+				mitts, err := getMitts(ctx, userID, limit, offset)
+				if err != nil || len(mitts) == 0 {
+					return 0, err
+				}
+
+				ids := make([]uuid.UUID, len(mitts))
+				for i, mitt := range mitts {
+					ids[i] = mitt.ID
+				}
+				revisions, err := s.mr.GetMittsRevisions(ctx, ids)
+				if err != nil {
+					return 0, fmt.Errorf("getting revisions: %w", err)
+				}
+
+				for _, mitt := range mitts {
+					if err := add(newMittJSON(mitt, revisions[mitt.ID])); err != nil {
+						return 0, err
+					}
+				}
+				return len(mitts), nil
+			})
+			if err != nil {
+				return fmt.Errorf("getting mitts: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	err = archive.writeList("likes.json", func(add func(item any) error) error {
+		return eachPage(ctx, func(ctx context.Context, limit, offset int32) (int, error) {
+			likes, err := s.mr.GetUserLikes(ctx, userID, limit, offset)
+			if err != nil {
+				return 0, fmt.Errorf("getting likes: %w", err)
+			}
+
+			for _, like := range likes {
+				if err := add(newLikeJSON(like)); err != nil {
+					return 0, err
+				}
+			}
+			return len(likes), nil
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := s.writeUsers(ctx, archive, "follows.json", userID, s.ur.GetUserFollows); err != nil {
+		return fmt.Errorf("getting follows: %w", err)
+	}
+	if err := s.writeUsers(ctx, archive, "followers.json", userID, s.ur.GetUserFollowers); err != nil {
+		return fmt.Errorf("getting followers: %w", err)
+	}
+
+	return archive.close()
+}
+
+func (s *Service) writeUsers(
+	ctx context.Context,
+	archive *archiveWriter,
+	name string,
+	userID uuid.UUID,
+	getIDs func(ctx context.Context, userID uuid.UUID, limit, offset int32) ([]uuid.UUID, error),
+) error {
+	return archive.writeList(name, func(add func(item any) error) error {
+		return eachPage(ctx, func(ctx context.Context, limit, offset int32) (int, error) {
+			ids, err := getIDs(ctx, userID, limit, offset)
+			if err != nil || len(ids) == 0 {
+				return 0, err
+			}
+
+			users, err := s.ur.GetUsersByIDs(ctx, ids)
+			if err != nil {
+				return 0, err
+			}
+
+			for _, ref := range newUserRefsJSON(ids, users) {
+				if err := add(ref); err != nil {
+					return 0, err
+				}
+			}
+			return len(ids), nil
+		})
+	})
+}
+
+// eachPage calls fetch for every page until it returns a partial one, fetch returns page length
+func eachPage(ctx context.Context, fetch func(ctx context.Context, limit, offset int32) (int, error)) error {
+	for offset := int32(0); ; offset += pageSize {
+		n, err := fetch(ctx, pageSize, offset)
+		if err != nil {
+			return err
+		}
+
+		if n < pageSize {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,579 @@
+package export
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/misshanya/mitter/internal/models"
+)
+
+var (
+	testUser = models.User{
+		ID:    uuid.MustParse("b096376a-5fa9-4130-907a-709c67008a65"),
+		Login: "testuser",
+		Name:  "Test User",
+	}
+	testFollowee = models.User{
+		ID:    uuid.MustParse("38386ffe-54ac-48be-9244-a5144b41a014"),
+		Login: "followee",
+		Name:  "Followee",
+	}
+	testMitt = models.Mitt{
+		ID:        uuid.MustParse("5c0a1f5b-3a44-4bb5-9e55-0b0c1b1a4a11"),
+		AuthorID:  testUser.ID,
+		Content:   "edited version",
+		CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		UpdatedAt: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+		Revisions: 1,
+	}
+)
+
+// Mock Export repo (in-memory)
+type mockExportRepo struct {
+	exports []*models.Export
+	chunks  map[uuid.UUID][][]byte
+}
+
+func (r *mockExportRepo) CreateExport(ctx context.Context, userID uuid.UUID) (*models.Export, error) {
+	_ = ctx
+
+	for _, export := range r.exports {
+		if export.UserID == userID && (export.Status == models.ExportPending || export.Status == models.ExportRunning) {
+			return nil, &pgconn.PgError{Code: "23505"}
+		}
+	}
+
+	export := &models.Export{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Status:    models.ExportPending,
+		CreatedAt: time.Now(),
+	}
+	r.exports = append(r.exports, export)
+	return export, nil
+}
+
+func (r *mockExportRepo) GetExport(ctx context.Context, userID uuid.UUID, id uuid.UUID) (*models.Export, error) {
+	_ = ctx
+
+	for _, export := range r.exports {
+		if export.ID == id && export.UserID == userID {
+			return export, nil
+		}
+	}
+	return nil, pgx.ErrNoRows
+}
+
+func (r *mockExportRepo) ClaimExport(ctx context.Context, staleAfter time.Duration) (*models.Export, error) {
+	_ = ctx
+	_ = staleAfter
+
+	for _, export := range r.exports {
+		if export.Status == models.ExportPending {
+			export.Status = models.ExportRunning
+			return export, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *mockExportRepo) SaveExportChunk(ctx context.Context, id uuid.UUID, seq int32, data []byte) error {
+	_ = ctx
+
+	if r.chunks == nil {
+		r.chunks = make(map[uuid.UUID][][]byte)
+	}
+	if int(seq) != len(r.chunks[id]) {
+		return errors.New("chunk is out of order")
+	}
+	r.chunks[id] = append(r.chunks[id], data)
+	return nil
+}
+
+func (r *mockExportRepo) CompleteExport(ctx context.Context, id uuid.UUID, ttl time.Duration) error {
+	_ = ctx
+
+	for _, export := range r.exports {
+		if export.ID == id {
+			token := uuid.New()
+			expiresAt := time.Now().Add(ttl)
+			export.Status = models.ExportDone
+			export.DownloadToken = &token
+			export.ExpiresAt = &expiresAt
+		}
+	}
+	return nil
+}
+
+func (r *mockExportRepo) FailExport(ctx context.Context, id uuid.UUID, reason string, ttl time.Duration) error {
+	_ = ctx
+	_ = ttl
+
+	delete(r.chunks, id)
+	for _, export := range r.exports {
+		if export.ID == id {
+			export.Status = models.ExportFailed
+			export.Error = reason
+		}
+	}
+	return nil
+}
+
+func (r *mockExportRepo) GetExportByToken(ctx context.Context, downloadToken uuid.UUID) (*models.Export, error) {
+	_ = ctx
+
+	for _, export := range r.exports {
+		if export.DownloadToken != nil && *export.DownloadToken == downloadToken {
+			return export, nil
+		}
+	}
+	return nil, pgx.ErrNoRows
+}
+
+func (r *mockExportRepo) GetExportChunk(ctx context.Context, id uuid.UUID, seq int32) ([]byte, error) {
+	_ = ctx
+
+	if int(seq) >= len(r.chunks[id]) {
+		return nil, pgx.ErrNoRows
+	}
+	return r.chunks[id][seq], nil
+}
+
+func (r *mockExportRepo) DeleteExpiredExports(ctx context.Context) (int64, error) {
+	_ = ctx
+
+	return 0, nil
+}
+
+// Mock User repo
+type mockUserRepo struct{}
+
+func (r *mockUserRepo) CreateUser(ctx context.Context, user *models.UserCreate) (uuid.UUID, error) {
+	_ = ctx
+	_ = user
+
+	return uuid.Nil, nil
+}
+
+func (r *mockUserRepo) GetUserByLogin(ctx context.Context, login string) (*models.User, error) {
+	_ = ctx
+	_ = login
+
+	return nil, nil
+}
+
+func (r *mockUserRepo) GetUserByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	_ = ctx
+
+	if id != testUser.ID {
+		return nil, pgx.ErrNoRows
+	}
+	return &testUser, nil
+}
+
+func (r *mockUserRepo) GetUsersByIDs(ctx context.Context, ids []uuid.UUID) ([]*models.User, error) {
+	_ = ctx
+
+	users := make([]*models.User, 0, len(ids))
+	for _, id := range ids {
+		if id == testFollowee.ID {
+			users = append(users, &testFollowee)
+		}
+	}
+	return users, nil
+}
+
+func (r *mockUserRepo) DeactivateUser(ctx context.Context, id uuid.UUID) (bool, error) {
+	_ = ctx
+	_ = id
+
+	return false, nil
+}
+
+func (r *mockUserRepo) ReactivateUser(ctx context.Context, id uuid.UUID, gracePeriod time.Duration) (bool, error) {
+	_ = ctx
+	_ = id
+	_ = gracePeriod
+
+	return false, nil
+}
+
+func (r *mockUserRepo) GetUsersToPurge(ctx context.Context, gracePeriod time.Duration, limit int32) ([]uuid.UUID, error) {
+	_ = ctx
+	_ = gracePeriod
+	_ = limit
+
+	return nil, nil
+}
+
+func (r *mockUserRepo) DeleteUserLikes(ctx context.Context, id uuid.UUID, batchSize int32) (int64, error) {
+	_ = ctx
+	_ = id
+	_ = batchSize
+
+	return 0, nil
+}
+
+func (r *mockUserRepo) DeleteUserFollows(ctx context.Context, id uuid.UUID, batchSize int32) (int64, error) {
+	_ = ctx
+	_ = id
+	_ = batchSize
+
+	return 0, nil
+}
+
+func (r *mockUserRepo) DeleteUserMitts(ctx context.Context, id uuid.UUID, batchSize int32) (int64, error) {
+	_ = ctx
+	_ = id
+	_ = batchSize
+
+	return 0, nil
+}
+
+func (r *mockUserRepo) DeleteUser(ctx context.Context, id uuid.UUID) error {
+	_ = ctx
+	_ = id
+
+	return nil
+}
+
+func (r *mockUserRepo) UpdateUser(ctx context.Context, id uuid.UUID, user *models.UserUpdate) error {
+	_ = ctx
+	_ = id
+	_ = user
+
+	return nil
+}
+
+func (r *mockUserRepo) GetCurrentPasswordHash(ctx context.Context, id uuid.UUID) (string, error) {
+	_ = ctx
+	_ = id
+
+	return "", nil
+}
+
+func (r *mockUserRepo) ChangePassword(ctx context.Context, id uuid.UUID, newHashedPassword string) error {
+	_ = ctx
+	_ = id
+	_ = newHashedPassword
+
+	return nil
+}
+
+func (r *mockUserRepo) FollowUser(ctx context.Context, followerID uuid.UUID, followeeID uuid.UUID) error {
+	_ = ctx
+	_ = followerID
+	_ = followeeID
+
+	return nil
+}
+
+func (r *mockUserRepo) UnfollowUser(ctx context.Context, followerID uuid.UUID, followeeID uuid.UUID) error {
+	_ = ctx
+	_ = followerID
+	_ = followeeID
+
+	return nil
+}
+
+func (r *mockUserRepo) GetUserFollows(ctx context.Context, followerID uuid.UUID, limit, offset int32) ([]uuid.UUID, error) {
+	_ = ctx
+	_ = followerID
+	_ = limit
+
+	if offset > 0 {
+		return nil, nil
+	}
+	return []uuid.UUID{testFollowee.ID}, nil
+}
+
+func (r *mockUserRepo) GetUserFollowers(ctx context.Context, followeeID uuid.UUID, limit, offset int32) ([]uuid.UUID, error) {
+	_ = ctx
+	_ = followeeID
+	_ = limit
+	_ = offset
+
+	return nil, nil
+}
+
+func (r *mockUserRepo) GetUserFriends(ctx context.Context, userID uuid.UUID, limit, offset int32) ([]uuid.UUID, error) {
+	_ = ctx
+	_ = userID
+	_ = limit
+	_ = offset
+
+	return nil, nil
+}
+
+func (r *mockUserRepo) IsFollowing(ctx context.Context, followerID uuid.UUID, followeeID uuid.UUID) (bool, error) {
+	_ = ctx
+	_ = followerID
+	_ = followeeID
+
+	return false, nil
+}
+
+func (r *mockUserRepo) GetKnownFollowers(ctx context.Context, viewerID uuid.UUID, userID uuid.UUID, limit, offset int32) ([]uuid.UUID, error) {
+	_ = ctx
+	_ = viewerID
+	_ = userID
+	_ = limit
+	_ = offset
+
+	return nil, nil
+}
+
+func (r *mockUserRepo) GetRelationships(ctx context.Context, userID uuid.UUID, ids []uuid.UUID) ([]*models.Relationship, error) {
+	_ = ctx
+	_ = userID
+	_ = ids
+
+	return nil, nil
+}
+
+func (r *mockUserRepo) ListUserIDs(ctx context.Context, after uuid.UUID, limit int32) ([]uuid.UUID, error) {
+	_ = ctx
+	_ = after
+	_ = limit
+
+	return nil, nil
+}
+
+func (r *mockUserRepo) BlockUser(ctx context.Context, blockerID uuid.UUID, blockedID uuid.UUID) error {
+	_ = ctx
+	_ = blockerID
+	_ = blockedID
+
+	return nil
+}
+
+func (r *mockUserRepo) UnblockUser(ctx context.Context, blockerID uuid.UUID, blockedID uuid.UUID) error {
+	_ = ctx
+	_ = blockerID
+	_ = blockedID
+
+	return nil
+}
+
+func (r *mockUserRepo) IsBlockedBetween(ctx context.Context, firstID uuid.UUID, secondID uuid.UUID) (bool, error) {
+	_ = ctx
+	_ = firstID
+	_ = secondID
+
+	return false, nil
+}
+
+func (r *mockUserRepo) MuteUser(ctx context.Context, muterID uuid.UUID, mutedID uuid.UUID) error {
+	_ = ctx
+	_ = muterID
+	_ = mutedID
+
+	return nil
+}
+
+func (r *mockUserRepo) UnmuteUser(ctx context.Context, muterID uuid.UUID, mutedID uuid.UUID) error {
+	_ = ctx
+	_ = muterID
+	_ = mutedID
+
+	return nil
+}
+
+func (r *mockUserRepo) GetHiddenUserIDs(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	_ = ctx
+	_ = userID
+
+	return nil, nil
+}
+
+func (r *mockUserRepo) SuggestFollows(ctx context.Context, userID uuid.UUID, limit int32) ([]uuid.UUID, error) {
+	_ = ctx
+	_ = userID
+	_ = limit
+
+	return nil, nil
+}
+
+func (r *mockUserRepo) GetPopularUsers(ctx context.Context, userID uuid.UUID, limit int32) ([]uuid.UUID, error) {
+	_ = ctx
+	_ = userID
+	_ = limit
+
+	return nil, nil
+}
+
+func (r *mockUserRepo) FilterSuggestable(ctx context.Context, userID uuid.UUID, candidateIDs []uuid.UUID) ([]uuid.UUID, error) {
+	_ = ctx
+	_ = userID
+	_ = candidateIDs
+
+	return nil, nil
+}
+
+// Mock Mitt repo
+type mockMittRepo struct{}
+
+func (r *mockMittRepo) CreateMitt(ctx context.Context, userID uuid.UUID, mitt *models.MittCreate) (*models.Mitt, error) {
+	_ = ctx
+	_ = userID
+	_ = mitt
+
+	return nil, nil
+}
+
+func (r *mockMittRepo) GetMitt(ctx context.Context, id uuid.UUID) (*models.Mitt, error) {
+	_ = ctx
+	_ = id
+
+	return nil, nil
+}
+
+func (r *mockMittRepo) GetAllUserMitts(ctx context.Context, userID uuid.UUID, limit, offset int32) ([]*models.Mitt, error) {
+	_ = ctx
+	_ = userID
+	_ = limit
+
+	if offset > 0 {
+		return nil, nil
+	}
+	return []*models.Mitt{&testMitt}, nil
+}
+
+func (r *mockMittRepo) UpdateMitt(ctx context.Context, mittID uuid.UUID, mitt *models.MittUpdate) (*models.Mitt, error) {
+	_ = ctx
+	_ = mittID
+	_ = mitt
+
+	return nil, nil
+}
+
+func (r *mockMittRepo) DeleteMitt(ctx context.Context, mittID uuid.UUID) error {
+	_ = ctx
+	_ = mittID
+
+	return nil
+}
+
+func (r *mockMittRepo) RestoreMitt(ctx context.Context, userID uuid.UUID, mittID uuid.UUID, retention time.Duration) (bool, error) {
+	_ = ctx
+	_ = userID
+	_ = mittID
+	_ = retention
+
+	return false, nil
+}
+
+func (r *mockMittRepo) GetDeletedUserMitts(ctx context.Context, userID uuid.UUID, limit, offset int32) ([]*models.Mitt, error) {
+	_ = ctx
+	_ = userID
+	_ = limit
+	_ = offset
+
+	return nil, nil
+}
+
+func (r *mockMittRepo) PurgeDeletedMitts(ctx context.Context, retention time.Duration, batchSize int32) (int64, error) {
+	_ = ctx
+	_ = retention
+	_ = batchSize
+
+	return 0, nil
+}
+
+func (r *mockMittRepo) GetMittRevisions(ctx context.Context, mittID uuid.UUID, limit, offset int32) ([]*models.MittRevision, error) {
+	_ = ctx
+	_ = limit
+
+	if offset > 0 {
+		return nil, nil
+	}
+	return []*models.MittRevision{{MittID: mittID, Content: "first version", CreatedAt: testMitt.CreatedAt}}, nil
+}
+
+func (r *mockMittRepo) GetMittsRevisions(ctx context.Context, mittIDs []uuid.UUID) (map[uuid.UUID][]*models.MittRevision, error) {
+	_ = ctx
+
+	revisions := make(map[uuid.UUID][]*models.MittRevision, len(mittIDs))
+	for _, id := range mittIDs {
+		revisions[id] = []*models.MittRevision{{MittID: id, Content: "first version", CreatedAt: testMitt.CreatedAt}}
+	}
+	return revisions, nil
+}
+
+func (r *mockMittRepo) DeleteExpiredMitts(ctx context.Context, batchSize int32) ([]*models.ExpiredMitt, error) {
+	_ = ctx
+	_ = batchSize
+
+	return nil, nil
+}
+
+func (r *mockMittRepo) PinMitt(ctx context.Context, userID uuid.UUID, mittID uuid.UUID, max int) error {
+	_ = ctx
+	_ = userID
+	_ = mittID
+	_ = max
+
+	return nil
+}
+
+func (r *mockMittRepo) UnpinMitt(ctx context.Context, userID uuid.UUID, mittID uuid.UUID) (bool, error) {
+	_ = ctx
+	_ = userID
+	_ = mittID
+
+	return false, nil
+}
+
+func (r *mockMittRepo) LikeMitt(ctx context.Context, userID uuid.UUID, mittID uuid.UUID) (bool, error) {
+	_ = ctx
+	_ = userID
+	_ = mittID
+
+	return false, nil
+}
+
+func (r *mockMittRepo) IsMittLikedByUser(ctx context.Context, userID uuid.UUID, mittID uuid.UUID) (bool, error) {
+	_ = ctx
+	_ = userID
+	_ = mittID
+
+	return false, nil
+}
+
+func (r *mockMittRepo) DeleteMittLike(ctx context.Context, userID uuid.UUID, mittID uuid.UUID) (bool, error) {
+	_ = ctx
+	_ = userID
+	_ = mittID
+
+	return false, nil
+}
+
+func (r *mockMittRepo) GetUserLikes(ctx context.Context, userID uuid.UUID, limit, offset int32) ([]*models.MittLike, error) {
+	_ = ctx
+	_ = userID
+	_ = limit
+	_ = offset
+
+	return nil, nil
+}
+
+func (r *mockMittRepo) Feed(ctx context.Context, limit, offset int32) ([]*models.Mitt, error) {
+	_ = ctx
+	_ = limit
+	_ = offset
+
+	return nil, nil
+}
+
+func (r *mockMittRepo) GetMentionedMitts(ctx context.Context, userID uuid.UUID, limit, offset int32) ([]*models.Mitt, error) {
+	_ = ctx
+	_ = userID
+	_ = limit
+	_ = offset
+
+	return nil, nil
+}
@@ -0,0 +1,154 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/misshanya/mitter/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func readArchiveFile(t *testing.T, archive []byte, name string, v any) {
+	t.Helper()
+
+	zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := zr.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	content, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(content, v); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Tests
+func TestExportService_Export(t *testing.T) {
+	service := NewService(&mockExportRepo{}, &mockUserRepo{}, &mockMittRepo{}, time.Hour)
+	ctx := context.Background()
+
+	export, err := service.RequestExport(ctx, testUser.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, models.ExportPending, export.Status)
+
+	// Only one export at a time
+	_, err = service.RequestExport(ctx, testUser.ID)
+	if assert.NotNil(t, err) {
+		assert.Equal(t, http.StatusConflict, err.Code)
+	}
+
+	if err := service.ProcessPending(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	export, err = service.GetExport(ctx, testUser.ID, export.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, models.ExportDone, export.Status)
+	if !assert.NotNil(t, export.DownloadToken) {
+		t.FailNow()
+	}
+
+	archiveReader, err := service.GetArchive(ctx, *export.DownloadToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	archive, readErr := io.ReadAll(archiveReader)
+	if readErr != nil {
+		t.Fatal(readErr)
+	}
+
+	var profile profileJSON
+	readArchiveFile(t, archive, "profile.json", &profile)
+	assert.Equal(t, testUser.Login, profile.Login)
+
+	var mitts []mittJSON
+	readArchiveFile(t, archive, "mitts.json", &mitts)
+	if assert.Len(t, mitts, 1) {
+		assert.Equal(t, testMitt.Content, mitts[0].Content)
+		assert.Len(t, mitts[0].Revisions, 1)
+	}
+
+	var likes []likeJSON
+	readArchiveFile(t, archive, "likes.json", &likes)
+	assert.Empty(t, likes)
+
+	var follows []userRefJSON
+	readArchiveFile(t, archive, "follows.json", &follows)
+	assert.Equal(t, []userRefJSON{{ID: testFollowee.ID, Login: testFollowee.Login, Name: testFollowee.Name}}, follows)
+
+	var followers []userRefJSON
+	readArchiveFile(t, archive, "followers.json", &followers)
+	assert.Empty(t, followers)
+}
+
+func TestExportService_GetExport_NotFound(t *testing.T) {
+	service := NewService(&mockExportRepo{}, &mockUserRepo{}, &mockMittRepo{}, time.Hour)
+	ctx := context.Background()
+
+	_, err := service.GetExport(ctx, testUser.ID, uuid.New())
+	if assert.NotNil(t, err) {
+		assert.Equal(t, http.StatusNotFound, err.Code)
+	}
+
+	_, err = service.GetArchive(ctx, uuid.New())
+	if assert.NotNil(t, err) {
+		assert.Equal(t, http.StatusNotFound, err.Code)
+	}
+}
+
+func TestExportService_ProcessPending_Failed(t *testing.T) {
+	er := &mockExportRepo{}
+	service := NewService(er, &mockUserRepo{}, &mockMittRepo{}, time.Hour)
+	ctx := context.Background()
+
+	// User doesn't exist, so archive can't be built
+	export, err := service.RequestExport(ctx, uuid.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := service.ProcessPending(ctx); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, models.ExportFailed, export.Status)
+}
+
+func TestExportService_Chunks(t *testing.T) {
+	er := &mockExportRepo{}
+	ctx := context.Background()
+	id := uuid.New()
+
+	w := newChunkWriter(ctx, er, id, 4)
+	_, err := io.WriteString(w, "hello ")
+	assert.NoError(t, err)
+	_, err = io.WriteString(w, "world")
+	assert.NoError(t, err)
+	assert.NoError(t, w.Close())
+
+	assert.Len(t, er.chunks[id], 3)
+	assert.Equal(t, int64(11), w.size)
+
+	content, err := io.ReadAll(newChunkReader(ctx, er, id))
+	assert.NoError(t, err)
+	assert.Equal(t, "hello world", string(content))
+}
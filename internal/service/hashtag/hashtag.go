@@ -0,0 +1,68 @@
+package hashtag
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/misshanya/mitter/internal/models"
+	"github.com/misshanya/mitter/pkg/content"
+)
+
+type Service struct {
+	hr models.HashtagRepository
+	tr models.TrendingRepository
+}
+
+func NewService(hr models.HashtagRepository, tr models.TrendingRepository) *Service {
+	return &Service{
+		hr: hr,
+		tr: tr,
+	}
+}
+
+// GetHashtagMitts returns mitts with hashtag, newest first. Name may be given with '#' and in any case
+func (s *Service) GetHashtagMitts(ctx context.Context, name string, limit, offset int32) ([]*models.Mitt, *models.HTTPError) {
+	tag, ok := content.NormalizeHashtag(name)
+	if !ok {
+		return nil, &models.HTTPError{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid hashtag",
+		}
+	}
+
+	mitts, err := s.hr.GetHashtagMitts(ctx, tag, limit, offset)
+	if err != nil {
+		slog.Error("error getting hashtag mitts", slog.Any("err", err))
+		return nil, &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		}
+	}
+
+	return mitts, nil
+}
+
+// GetTrending returns hashtags which usage grows the most
+func (s *Service) GetTrending(ctx context.Context, limit int32) ([]*models.TrendingHashtag, *models.HTTPError) {
+	tags, err := s.tr.GetTrendingHashtags(ctx, limit)
+	if err != nil {
+		slog.Error("error getting trending hashtags", slog.Any("err", err))
+		return nil, &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		}
+	}
+
+	return tags, nil
+}
+
+// RefreshTrending recomputes trending hashtags from recent uses
+func (s *Service) RefreshTrending(ctx context.Context) error {
+	if err := s.tr.RefreshTrendingHashtags(ctx, time.Now()); err != nil {
+		slog.Error("error refreshing trending hashtags", slog.Any("err", err))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package hashtag
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/misshanya/mitter/internal/models"
+)
+
+var mockMittModel = &models.Mitt{
+	ID:        uuid.New(),
+	AuthorID:  uuid.New(),
+	Content:   "hello #Go",
+	CreatedAt: time.Now(),
+	UpdatedAt: time.Now(),
+}
+
+// Mock hashtag repo
+type mockHashtagRepo struct{}
+
+func (r *mockHashtagRepo) GetHashtagMitts(ctx context.Context, name string, limit, offset int32) ([]*models.Mitt, error) {
+	_ = ctx
+	_ = limit
+	_ = offset
+
+	if name != "go" {
+		return []*models.Mitt{}, nil
+	}
+	return []*models.Mitt{mockMittModel}, nil
+}
+
+// Mock trending repo
+type mockTrendingRepo struct {
+	refreshedAt time.Time
+}
+
+func (r *mockTrendingRepo) AddHashtagUses(ctx context.Context, tags []string, at time.Time, delta int64) error {
+	_ = ctx
+	_ = tags
+	_ = at
+	_ = delta
+
+	return nil
+}
+
+func (r *mockTrendingRepo) RefreshTrendingHashtags(ctx context.Context, now time.Time) error {
+	_ = ctx
+
+	r.refreshedAt = now
+	return nil
+}
+
+func (r *mockTrendingRepo) GetTrendingHashtags(ctx context.Context, limit int32) ([]*models.TrendingHashtag, error) {
+	_ = ctx
+
+	tags := []*models.TrendingHashtag{
+		{Name: "go", Score: 10},
+		{Name: "release", Score: 3},
+	}
+	return tags[:min(int(limit), len(tags))], nil
+}
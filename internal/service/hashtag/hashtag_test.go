@@ -0,0 +1,48 @@
+package hashtag
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Tests
+func TestHashtagService_GetHashtagMitts(t *testing.T) {
+	service := NewService(&mockHashtagRepo{}, &mockTrendingRepo{})
+	ctx := context.Background()
+
+	// Name is normalized
+	for _, name := range []string{"go", "#Go", "GO"} {
+		mitts, err := service.GetHashtagMitts(ctx, name, 30, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Len(t, mitts, 1, name)
+	}
+
+	_, err := service.GetHashtagMitts(ctx, "not a tag", 30, 0)
+	if assert.NotNil(t, err) {
+		assert.Equal(t, http.StatusBadRequest, err.Code)
+	}
+}
+
+func TestHashtagService_Trending(t *testing.T) {
+	tr := &mockTrendingRepo{}
+	service := NewService(&mockHashtagRepo{}, tr)
+	ctx := context.Background()
+
+	if err := service.RefreshTrending(ctx); err != nil {
+		t.Fatal(err)
+	}
+	assert.False(t, tr.refreshedAt.IsZero())
+
+	tags, err := service.GetTrending(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assert.Len(t, tags, 1) {
+		assert.Equal(t, "go", tags[0].Name)
+	}
+}
@@ -0,0 +1,72 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/misshanya/mitter/internal/models"
+)
+
+// itemsFile is read from ZIP archives, data export produces the same file
+const itemsFile = "mitts.json"
+
+// Archive can't be bigger than maxSize, so unpacked file is limited too
+const maxUnpackedRatio = 20
+
+var zipMagic = []byte("PK\x03\x04")
+
+var errNoItemsFile = fmt.Errorf("archive must contain %s", itemsFile)
+
+// parseArchive reads posts from JSON array, or from mitts.json in ZIP archive
+func parseArchive(data []byte) ([]*models.ImportItem, error) {
+	if bytes.HasPrefix(data, zipMagic) {
+		var err error
+		data, err = readItemsFile(data)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var items []*models.ImportItem
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	// null items would break processing
+	for i, item := range items {
+		if item == nil {
+			items[i] = &models.ImportItem{}
+		}
+	}
+
+	return items, nil
+}
+
+func readItemsFile(data []byte) ([]byte, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid ZIP: %w", err)
+	}
+
+	f, err := zr.Open(itemsFile)
+	if err != nil {
+		return nil, errNoItemsFile
+	}
+	defer f.Close()
+
+	// Protect from ZIP bombs
+	limit := int64(len(data)) * maxUnpackedRatio
+	content, err := io.ReadAll(io.LimitReader(f, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("invalid ZIP: %w", err)
+	}
+	if int64(len(content)) > limit {
+		return nil, errors.New("archive is too big when unpacked")
+	}
+
+	return content, nil
+}
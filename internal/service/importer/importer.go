@@ -0,0 +1,216 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/misshanya/mitter/internal/models"
+	"github.com/misshanya/mitter/pkg/content"
+)
+
+const (
+	batchSize = 100
+	// Running import is taken again if it has no progress for this long (e.g. instance was restarted)
+	staleImportTimeout = 10 * time.Minute
+)
+
+type Service struct {
+	ir models.ImportRepository
+
+	maxContentLength int
+}
+
+func NewService(ir models.ImportRepository, maxContentLength int) *Service {
+	return &Service{
+		ir:               ir,
+		maxContentLength: maxContentLength,
+	}
+}
+
+// RequestImport parses archive and queues import, mitts are imported by background worker
+func (s *Service) RequestImport(ctx context.Context, userID uuid.UUID, archive []byte) (*models.Import, *models.HTTPError) {
+	return s.createImport(ctx, userID, archive, false)
+}
+
+func (s *Service) createImport(ctx context.Context, userID uuid.UUID, archive []byte, running bool) (*models.Import, *models.HTTPError) {
+	items, err := parseArchive(archive)
+	if err != nil {
+		return nil, &models.HTTPError{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid archive: " + err.Error(),
+		}
+	}
+	if len(items) == 0 {
+		return nil, &models.HTTPError{
+			Code:    http.StatusBadRequest,
+			Message: "Archive has no mitts",
+		}
+	}
+
+	imp, err := s.ir.CreateImport(ctx, userID, items, running)
+	if err != nil {
+		slog.Error("error creating import", slog.Any("err", err))
+		return nil, &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		}
+	}
+
+	return imp, nil
+}
+
+func (s *Service) GetImport(ctx context.Context, userID uuid.UUID, id uuid.UUID) (*models.Import, *models.HTTPError) {
+	imp, err := s.ir.GetImport(ctx, userID, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, &models.HTTPError{
+				Code:    http.StatusNotFound,
+				Message: "Import not found",
+			}
+		}
+		slog.Error("error getting import", slog.Any("err", err))
+		return nil, &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		}
+	}
+
+	return imp, nil
+}
+
+func (s *Service) GetImportErrors(ctx context.Context, userID uuid.UUID, id uuid.UUID, limit, offset int32) ([]*models.ImportItemError, *models.HTTPError) {
+	// Check that import belongs to user
+	if _, httpErr := s.GetImport(ctx, userID, id); httpErr != nil {
+		return nil, httpErr
+	}
+
+	itemErrors, err := s.ir.GetImportErrors(ctx, id, limit, offset)
+	if err != nil {
+		slog.Error("error getting import errors", slog.Any("err", err))
+		return nil, &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		}
+	}
+
+	return itemErrors, nil
+}
+
+// Import imports archive right away, progress is called after every batch
+func (s *Service) Import(ctx context.Context, userID uuid.UUID, archive []byte, progress func(imp *models.Import)) (*models.Import, error) {
+	imp, httpErr := s.createImport(ctx, userID, archive, true)
+	if httpErr != nil {
+		return nil, errors.New(httpErr.Message)
+	}
+
+	return s.process(ctx, imp, progress)
+}
+
+// ProcessPending imports all queued imports,
+// imports are claimed with row locks, so several instances can run it at once
+func (s *Service) ProcessPending(ctx context.Context) error {
+	for {
+		imp, err := s.ir.ClaimImport(ctx, staleImportTimeout)
+		if err != nil {
+			slog.Error("error claiming import", slog.Any("err", err))
+			return err
+		}
+		if imp == nil {
+			return nil
+		}
+
+		if _, err := s.process(ctx, imp, nil); err != nil {
+			return err
+		}
+	}
+}
+
+// process imports items starting from already processed ones, so interrupted import is resumed
+func (s *Service) process(ctx context.Context, imp *models.Import, progress func(imp *models.Import)) (*models.Import, error) {
+	items, err := s.ir.GetImportItems(ctx, imp.ID)
+	if err != nil {
+		slog.Error("error getting import items", slog.String("importID", imp.ID.String()), slog.Any("err", err))
+		if err := s.ir.FailImport(ctx, imp.ID, "Failed to read archive"); err != nil {
+			slog.Error("error marking import as failed", slog.Any("err", err))
+			return nil, err
+		}
+		imp.Status = models.ImportFailed
+		return imp, nil
+	}
+
+	for imp.Processed < int32(len(items)) {
+		end := min(imp.Processed+batchSize, int32(len(items)))
+		batch := s.checkItems(items[imp.Processed:end], imp.Processed)
+
+		imp, err = s.ir.SaveImportBatch(ctx, imp, batch)
+		if err != nil {
+			slog.Error("error saving import batch", slog.Any("err", err))
+			return nil, err
+		}
+
+		if progress != nil {
+			progress(imp)
+		}
+	}
+
+	if err := s.ir.FinishImport(ctx, imp.ID); err != nil {
+		slog.Error("error finishing import", slog.Any("err", err))
+		return nil, err
+	}
+	imp.Status = models.ImportDone
+
+	slog.Info("import is finished",
+		slog.String("importID", imp.ID.String()),
+		slog.Int("imported", int(imp.Imported)),
+		slog.Int("skipped", int(imp.Skipped)),
+		slog.Int("failed", int(imp.Failed)),
+	)
+	return imp, nil
+}
+
+// checkItems normalizes items and splits them into valid ones and errors,
+// first is index of first item in archive
+func (s *Service) checkItems(items []*models.ImportItem, first int32) *models.ImportBatch {
+	batch := &models.ImportBatch{Processed: first + int32(len(items))}
+
+	now := time.Now()
+	for i, item := range items {
+		item.Content = content.Normalize(item.Content)
+		// Timestamps are stored without time zone
+		item.CreatedAt = item.CreatedAt.UTC()
+		item.Hashtags = content.Hashtags(item.Content)
+		item.Mentions = content.MentionedLogins(item.Content)
+
+		var reason string
+		switch {
+		case item.ExternalID == "":
+			reason = "id is required"
+		case item.CreatedAt.IsZero():
+			reason = "created_at is required"
+		case item.CreatedAt.After(now):
+			reason = "created_at must not be in the future"
+		default:
+			if err := content.Check(item.Content, s.maxContentLength); err != nil {
+				reason = fmt.Sprintf("content %s", err)
+			}
+		}
+
+		if reason != "" {
+			batch.Errors = append(batch.Errors, &models.ImportItemError{
+				Index:      first + int32(i),
+				ExternalID: item.ExternalID,
+				Error:      reason,
+			})
+			continue
+		}
+		batch.Items = append(batch.Items, item)
+	}
+
+	return batch
+}
@@ -0,0 +1,132 @@
+package importer
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/misshanya/mitter/internal/models"
+)
+
+var testUserID = uuid.MustParse("b096376a-5fa9-4130-907a-709c67008a65")
+
+// Mock Import repo (in-memory)
+type mockImportRepo struct {
+	imports []*models.Import
+	items   map[uuid.UUID][]*models.ImportItem
+	errors  map[uuid.UUID][]*models.ImportItemError
+	// Imported mitts by external ID
+	mitts map[string]*models.ImportItem
+}
+
+func newMockImportRepo() *mockImportRepo {
+	return &mockImportRepo{
+		items:  make(map[uuid.UUID][]*models.ImportItem),
+		errors: make(map[uuid.UUID][]*models.ImportItemError),
+		mitts:  make(map[string]*models.ImportItem),
+	}
+}
+
+func (r *mockImportRepo) CreateImport(ctx context.Context, userID uuid.UUID, items []*models.ImportItem, running bool) (*models.Import, error) {
+	_ = ctx
+
+	imp := &models.Import{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Status:    models.ImportPending,
+		Total:     int32(len(items)),
+		CreatedAt: time.Now(),
+	}
+	if running {
+		imp.Status = models.ImportRunning
+	}
+	r.imports = append(r.imports, imp)
+	r.items[imp.ID] = items
+
+	return imp, nil
+}
+
+func (r *mockImportRepo) GetImport(ctx context.Context, userID uuid.UUID, id uuid.UUID) (*models.Import, error) {
+	_ = ctx
+
+	for _, imp := range r.imports {
+		if imp.ID == id && imp.UserID == userID {
+			return imp, nil
+		}
+	}
+	return nil, pgx.ErrNoRows
+}
+
+func (r *mockImportRepo) GetImportErrors(ctx context.Context, importID uuid.UUID, limit, offset int32) ([]*models.ImportItemError, error) {
+	_ = ctx
+	_ = limit
+	_ = offset
+
+	return r.errors[importID], nil
+}
+
+func (r *mockImportRepo) ClaimImport(ctx context.Context, staleAfter time.Duration) (*models.Import, error) {
+	_ = ctx
+	_ = staleAfter
+
+	for _, imp := range r.imports {
+		if imp.Status == models.ImportPending {
+			imp.Status = models.ImportRunning
+			return imp, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *mockImportRepo) GetImportItems(ctx context.Context, importID uuid.UUID) ([]*models.ImportItem, error) {
+	_ = ctx
+
+	items, ok := r.items[importID]
+	if !ok {
+		return nil, pgx.ErrNoRows
+	}
+	return items, nil
+}
+
+func (r *mockImportRepo) SaveImportBatch(ctx context.Context, imp *models.Import, batch *models.ImportBatch) (*models.Import, error) {
+	_ = ctx
+
+	for _, item := range batch.Items {
+		if _, ok := r.mitts[item.ExternalID]; ok {
+			imp.Skipped++
+			continue
+		}
+		r.mitts[item.ExternalID] = item
+		imp.Imported++
+	}
+	r.errors[imp.ID] = append(r.errors[imp.ID], batch.Errors...)
+	imp.Failed += int32(len(batch.Errors))
+	imp.Processed = batch.Processed
+
+	return imp, nil
+}
+
+func (r *mockImportRepo) FinishImport(ctx context.Context, id uuid.UUID) error {
+	_ = ctx
+
+	for _, imp := range r.imports {
+		if imp.ID == id {
+			imp.Status = models.ImportDone
+		}
+	}
+	delete(r.items, id)
+	return nil
+}
+
+func (r *mockImportRepo) FailImport(ctx context.Context, id uuid.UUID, reason string) error {
+	_ = ctx
+
+	for _, imp := range r.imports {
+		if imp.ID == id {
+			imp.Status = models.ImportFailed
+			imp.Error = reason
+		}
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/misshanya/mitter/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+const testArchive = `[
+	{"id": "1", "content": "first post", "created_at": "2020-01-01T10:00:00+03:00"},
+	{"id": "2", "content": "   ", "created_at": "2020-01-02T10:00:00Z"},
+	{"id": "", "content": "no id", "created_at": "2020-01-03T10:00:00Z"},
+	{"id": "1", "content": "first post again", "created_at": "2020-01-01T10:00:00Z"},
+	{"id": "3", "content": "no date"}
+]`
+
+// Tests
+func TestImportService_Import(t *testing.T) {
+	ir := newMockImportRepo()
+	service := NewService(ir, 280)
+	ctx := context.Background()
+
+	imp, err := service.RequestImport(ctx, testUserID, []byte(testArchive))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, models.ImportPending, imp.Status)
+	assert.Equal(t, int32(5), imp.Total)
+
+	if err := service.ProcessPending(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	imp, err = service.GetImport(ctx, testUserID, imp.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, models.ImportDone, imp.Status)
+	assert.Equal(t, int32(5), imp.Processed)
+	assert.Equal(t, int32(1), imp.Imported)
+	assert.Equal(t, int32(1), imp.Skipped)
+	assert.Equal(t, int32(3), imp.Failed)
+
+	// Original time is kept
+	assert.Equal(t, time.Date(2020, 1, 1, 7, 0, 0, 0, time.UTC), ir.mitts["1"].CreatedAt)
+
+	itemErrors, err := service.GetImportErrors(ctx, testUserID, imp.ID, 30, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []*models.ImportItemError{
+		{Index: 1, ExternalID: "2", Error: "content must not be blank"},
+		{Index: 2, ExternalID: "", Error: "id is required"},
+		{Index: 4, ExternalID: "3", Error: "created_at is required"},
+	}, itemErrors)
+}
+
+func TestImportService_Import_Batches(t *testing.T) {
+	ir := newMockImportRepo()
+	service := NewService(ir, 280)
+	ctx := context.Background()
+
+	items := make([]string, batchSize+1)
+	for i := range items {
+		items[i] = fmt.Sprintf(`{"id": "%d", "content": "post %d", "created_at": "2020-01-01T00:00:00Z"}`, i, i)
+	}
+
+	var progress []int32
+	imp, err := service.Import(ctx, testUserID, []byte("["+strings.Join(items, ",")+"]"), func(imp *models.Import) {
+		progress = append(progress, imp.Processed)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, models.ImportDone, imp.Status)
+	assert.Equal(t, int32(batchSize+1), imp.Imported)
+	assert.Equal(t, []int32{batchSize, batchSize + 1}, progress)
+}
+
+func TestImportService_RequestImport_Zip(t *testing.T) {
+	service := NewService(newMockImportRepo(), 280)
+	ctx := context.Background()
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, err := zw.Create(itemsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte(testArchive)); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	imp, httpErr := service.RequestImport(ctx, testUserID, buf.Bytes())
+	if httpErr != nil {
+		t.Fatal(httpErr)
+	}
+	assert.Equal(t, int32(5), imp.Total)
+}
+
+func TestImportService_RequestImport_Invalid(t *testing.T) {
+	service := NewService(newMockImportRepo(), 280)
+	ctx := context.Background()
+
+	for _, archive := range []string{"", "{}", "[]", "PK\x03\x04broken"} {
+		_, err := service.RequestImport(ctx, testUserID, []byte(archive))
+		if assert.NotNil(t, err, archive) {
+			assert.Equal(t, http.StatusBadRequest, err.Code)
+		}
+	}
+}
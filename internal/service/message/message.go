@@ -0,0 +1,289 @@
+package message
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/misshanya/mitter/internal/dataloader"
+	"github.com/misshanya/mitter/internal/models"
+)
+
+type Service struct {
+	msr models.MessageRepository
+	ur  models.UserRepository
+
+	// Max members of conversation including its creator
+	maxMembers int
+}
+
+func NewService(msr models.MessageRepository, ur models.UserRepository, maxMembers int) *Service {
+	return &Service{
+		msr:        msr,
+		ur:         ur,
+		maxMembers: maxMembers,
+	}
+}
+
+// checkRecipients returns error if some of users don't exist or don't accept messages from sender
+func (s *Service) checkRecipients(ctx context.Context, senderID uuid.UUID, userIDs []uuid.UUID) *models.HTTPError {
+	recipients, err := s.msr.GetRecipients(ctx, senderID, userIDs)
+	if err != nil {
+		slog.Error("error getting message recipients", slog.Any("err", err))
+		return &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		}
+	}
+
+	if len(recipients) != len(userIDs) {
+		return &models.HTTPError{
+			Code:    http.StatusNotFound,
+			Message: "User not found",
+		}
+	}
+	for _, r := range recipients {
+		if !r.Allowed {
+			return &models.HTTPError{
+				Code:    http.StatusForbidden,
+				Message: "User doesn't accept messages from you",
+			}
+		}
+	}
+
+	return nil
+}
+
+// loadMembers fills members of conversations with one query
+func (s *Service) loadMembers(ctx context.Context, conversations ...*models.Conversation) error {
+	var ids []uuid.UUID
+	for _, c := range conversations {
+		ids = append(ids, c.MemberIDs...)
+	}
+	loader := dataloader.Users(ctx, s.ur)
+	if _, err := loader.LoadMany(ctx, ids); err != nil {
+		return err
+	}
+	for _, c := range conversations {
+		// Already loaded, no queries here
+		c.Members, _ = loader.LoadMany(ctx, c.MemberIDs)
+	}
+	return nil
+}
+
+// CreateConversation starts conversation of user with other users, it's one-to-one if there's only one of them.
+// If these two users already have one-to-one conversation it's returned instead, created reports whether it's new
+func (s *Service) CreateConversation(ctx context.Context, userID uuid.UUID, memberIDs []uuid.UUID) (*models.Conversation, bool, *models.HTTPError) {
+	others := make([]uuid.UUID, 0, len(memberIDs))
+	for _, id := range memberIDs {
+		if id != userID && !slices.Contains(others, id) {
+			others = append(others, id)
+		}
+	}
+
+	if len(others) == 0 {
+		return nil, false, &models.HTTPError{
+			Code:    http.StatusBadRequest,
+			Message: "At least one other member is required",
+		}
+	}
+	if len(others)+1 > s.maxMembers {
+		return nil, false, &models.HTTPError{
+			Code:    http.StatusBadRequest,
+			Message: "Too many members",
+		}
+	}
+
+	if httpErr := s.checkRecipients(ctx, userID, others); httpErr != nil {
+		return nil, false, httpErr
+	}
+
+	id, created, err := s.msr.CreateConversation(ctx, append([]uuid.UUID{userID}, others...), len(others) > 1)
+	if err != nil {
+		slog.Error("error creating conversation", slog.Any("err", err))
+		return nil, false, &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		}
+	}
+
+	conversation, httpErr := s.GetConversation(ctx, userID, id)
+	if httpErr != nil {
+		return nil, false, httpErr
+	}
+
+	return conversation, created, nil
+}
+
+func (s *Service) GetConversation(ctx context.Context, userID uuid.UUID, id uuid.UUID) (*models.Conversation, *models.HTTPError) {
+	conversation, err := s.msr.GetConversation(ctx, userID, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, &models.HTTPError{
+				Code:    http.StatusNotFound,
+				Message: "Conversation not found",
+			}
+		}
+		slog.Error("error getting conversation", slog.Any("err", err))
+		return nil, &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		}
+	}
+
+	if err := s.loadMembers(ctx, conversation); err != nil {
+		slog.Error("error getting conversation (getting members from db)", slog.Any("err", err))
+		return nil, &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		}
+	}
+
+	return conversation, nil
+}
+
+// GetConversations returns conversations of user with the latest message first
+func (s *Service) GetConversations(ctx context.Context, userID uuid.UUID, limit, offset int32) ([]*models.Conversation, *models.HTTPError) {
+	conversations, err := s.msr.GetUserConversations(ctx, userID, limit, offset)
+	if err != nil {
+		slog.Error("error getting conversations", slog.Any("err", err))
+		return nil, &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		}
+	}
+
+	if err := s.loadMembers(ctx, conversations...); err != nil {
+		slog.Error("error getting conversations (getting members from db)", slog.Any("err", err))
+		return nil, &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		}
+	}
+
+	return conversations, nil
+}
+
+// SendMessage sends message to conversation of user. In one-to-one conversation blocks and
+// "only people I follow" setting of the other member are checked on every message.
+// In group only blocks are checked on every message, settings of members are checked when group is created
+func (s *Service) SendMessage(ctx context.Context, userID uuid.UUID, conversationID uuid.UUID, content string) (*models.Message, *models.HTTPError) {
+	conversation, err := s.msr.GetConversation(ctx, userID, conversationID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, &models.HTTPError{
+				Code:    http.StatusNotFound,
+				Message: "Conversation not found",
+			}
+		}
+		slog.Error("error getting conversation", slog.Any("err", err))
+		return nil, &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		}
+	}
+
+	if !conversation.IsGroup {
+		others := slices.DeleteFunc(slices.Clone(conversation.MemberIDs), func(id uuid.UUID) bool {
+			return id == userID
+		})
+		if httpErr := s.checkRecipients(ctx, userID, others); httpErr != nil {
+			// Other member is deactivated
+			if httpErr.Code == http.StatusNotFound {
+				httpErr.Code = http.StatusForbidden
+				httpErr.Message = "User doesn't accept messages from you"
+			}
+			return nil, httpErr
+		}
+	} else {
+		blocked, err := s.msr.HasBlocks(ctx, userID, conversationID)
+		if err != nil {
+			slog.Error("error checking blocks in conversation", slog.Any("err", err))
+			return nil, &models.HTTPError{
+				Code:    http.StatusInternalServerError,
+				Message: "Internal server error",
+			}
+		}
+		if blocked {
+			return nil, &models.HTTPError{
+				Code:    http.StatusForbidden,
+				Message: "Conversation has members you blocked or who blocked you",
+			}
+		}
+	}
+
+	message, err := s.msr.CreateMessage(ctx, conversationID, userID, content)
+	if err != nil {
+		slog.Error("error creating message", slog.Any("err", err))
+		return nil, &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		}
+	}
+
+	return message, nil
+}
+
+// GetMessages returns messages of conversation older than message before (or the latest if it's nil), newest first
+func (s *Service) GetMessages(ctx context.Context, userID uuid.UUID, conversationID uuid.UUID, before *uuid.UUID, limit int32) ([]*models.Message, *models.HTTPError) {
+	if _, err := s.msr.GetConversation(ctx, userID, conversationID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, &models.HTTPError{
+				Code:    http.StatusNotFound,
+				Message: "Conversation not found",
+			}
+		}
+		slog.Error("error getting conversation", slog.Any("err", err))
+		return nil, &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		}
+	}
+
+	messages, err := s.msr.GetMessages(ctx, conversationID, before, limit)
+	if err != nil {
+		slog.Error("error getting messages", slog.Any("err", err))
+		return nil, &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		}
+	}
+
+	return messages, nil
+}
+
+// MarkRead marks all messages of conversation read
+func (s *Service) MarkRead(ctx context.Context, userID uuid.UUID, conversationID uuid.UUID) *models.HTTPError {
+	found, err := s.msr.MarkRead(ctx, userID, conversationID)
+	if err != nil {
+		slog.Error("error marking conversation read", slog.Any("err", err))
+		return &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		}
+	}
+	if !found {
+		return &models.HTTPError{
+			Code:    http.StatusNotFound,
+			Message: "Conversation not found",
+		}
+	}
+	return nil
+}
+
+// GetUnreadCount returns count of unread messages in all conversations of user
+func (s *Service) GetUnreadCount(ctx context.Context, userID uuid.UUID) (int64, *models.HTTPError) {
+	count, err := s.msr.GetUnreadCount(ctx, userID)
+	if err != nil {
+		slog.Error("error getting unread messages count", slog.Any("err", err))
+		return 0, &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		}
+	}
+	return count, nil
+}
@@ -0,0 +1,450 @@
+package message
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/misshanya/mitter/internal/models"
+)
+
+var (
+	testUserID    = uuid.MustParse("b096376a-5fa9-4130-907a-709c67008a65")
+	testUser2ID   = uuid.MustParse("38386ffe-54ac-48be-9244-a5144b41a014")
+	testUser3ID   = uuid.MustParse("7d3c4b2a-19e8-4f6a-b5c4-d3e2f1a0b9c8")
+	testBlockedID = uuid.MustParse("9e8d7c6b-5a49-4382-a1b0-c9d8e7f6a5b4")
+)
+
+// Mock User repo
+type mockUserRepo struct{}
+
+func (r *mockUserRepo) CreateUser(ctx context.Context, user *models.UserCreate) (uuid.UUID, error) {
+	_ = ctx
+	_ = user
+
+	return uuid.Nil, nil
+}
+
+func (r *mockUserRepo) GetUserByLogin(ctx context.Context, login string) (*models.User, error) {
+	_ = ctx
+	_ = login
+
+	return nil, nil
+}
+
+func (r *mockUserRepo) GetUserByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	_ = ctx
+	_ = id
+
+	return &models.User{ID: id, Login: "user", Name: "User"}, nil
+}
+
+func (r *mockUserRepo) GetUsersByIDs(ctx context.Context, ids []uuid.UUID) ([]*models.User, error) {
+	_ = ctx
+	_ = ids
+
+	users := make([]*models.User, len(ids))
+	for i, id := range ids {
+		users[i] = &models.User{ID: id, Login: "user", Name: "User"}
+	}
+	return users, nil
+}
+
+func (r *mockUserRepo) DeactivateUser(ctx context.Context, id uuid.UUID) (bool, error) {
+	_ = ctx
+	_ = id
+
+	return true, nil
+}
+
+func (r *mockUserRepo) ReactivateUser(ctx context.Context, id uuid.UUID, gracePeriod time.Duration) (bool, error) {
+	_ = ctx
+	_ = id
+	_ = gracePeriod
+
+	return true, nil
+}
+
+func (r *mockUserRepo) GetUsersToPurge(ctx context.Context, gracePeriod time.Duration, limit int32) ([]uuid.UUID, error) {
+	_ = ctx
+	_ = gracePeriod
+	_ = limit
+
+	return nil, nil
+}
+
+func (r *mockUserRepo) DeleteUserLikes(ctx context.Context, id uuid.UUID, batchSize int32) (int64, error) {
+	_ = ctx
+	_ = id
+	_ = batchSize
+
+	return 0, nil
+}
+
+func (r *mockUserRepo) DeleteUserFollows(ctx context.Context, id uuid.UUID, batchSize int32) (int64, error) {
+	_ = ctx
+	_ = id
+	_ = batchSize
+
+	return 0, nil
+}
+
+func (r *mockUserRepo) DeleteUserMitts(ctx context.Context, id uuid.UUID, batchSize int32) (int64, error) {
+	_ = ctx
+	_ = id
+	_ = batchSize
+
+	return 0, nil
+}
+
+func (r *mockUserRepo) DeleteUser(ctx context.Context, id uuid.UUID) error {
+	_ = ctx
+	_ = id
+
+	return nil
+}
+
+func (r *mockUserRepo) UpdateUser(ctx context.Context, id uuid.UUID, user *models.UserUpdate) error {
+	_ = ctx
+	_ = id
+	_ = user
+
+	return nil
+}
+
+func (r *mockUserRepo) GetCurrentPasswordHash(ctx context.Context, id uuid.UUID) (string, error) {
+	_ = ctx
+	_ = id
+
+	return "", nil
+}
+
+func (r *mockUserRepo) ChangePassword(ctx context.Context, id uuid.UUID, newHashedPassword string) error {
+	_ = ctx
+	_ = id
+	_ = newHashedPassword
+
+	return nil
+}
+
+func (r *mockUserRepo) FollowUser(ctx context.Context, followerID uuid.UUID, followeeID uuid.UUID) error {
+	_ = ctx
+	_ = followerID
+	_ = followeeID
+
+	return nil
+}
+
+func (r *mockUserRepo) UnfollowUser(ctx context.Context, followerID uuid.UUID, followeeID uuid.UUID) error {
+	_ = ctx
+	_ = followerID
+	_ = followeeID
+
+	return nil
+}
+
+func (r *mockUserRepo) GetUserFollows(ctx context.Context, followerID uuid.UUID, limit, offset int32) ([]uuid.UUID, error) {
+	_ = ctx
+	_ = followerID
+	_ = limit
+	_ = offset
+
+	return nil, nil
+}
+
+func (r *mockUserRepo) GetUserFollowers(ctx context.Context, followeeID uuid.UUID, limit, offset int32) ([]uuid.UUID, error) {
+	_ = ctx
+	_ = followeeID
+	_ = limit
+	_ = offset
+
+	return nil, nil
+}
+
+func (r *mockUserRepo) GetUserFriends(ctx context.Context, userID uuid.UUID, limit, offset int32) ([]uuid.UUID, error) {
+	_ = ctx
+	_ = userID
+	_ = limit
+	_ = offset
+
+	return nil, nil
+}
+
+func (r *mockUserRepo) ListUserIDs(ctx context.Context, after uuid.UUID, limit int32) ([]uuid.UUID, error) {
+	_ = ctx
+	_ = limit
+
+	_ = after
+
+	return nil, nil
+}
+
+func (r *mockUserRepo) BlockUser(ctx context.Context, blockerID uuid.UUID, blockedID uuid.UUID) error {
+	_ = ctx
+	_ = blockerID
+	_ = blockedID
+
+	return nil
+}
+
+func (r *mockUserRepo) UnblockUser(ctx context.Context, blockerID uuid.UUID, blockedID uuid.UUID) error {
+	_ = ctx
+	_ = blockerID
+	_ = blockedID
+
+	return nil
+}
+
+func (r *mockUserRepo) IsBlockedBetween(ctx context.Context, firstID uuid.UUID, secondID uuid.UUID) (bool, error) {
+	_ = ctx
+	_ = firstID
+	_ = secondID
+
+	return false, nil
+}
+
+func (r *mockUserRepo) MuteUser(ctx context.Context, muterID uuid.UUID, mutedID uuid.UUID) error {
+	_ = ctx
+	_ = muterID
+	_ = mutedID
+
+	return nil
+}
+
+func (r *mockUserRepo) UnmuteUser(ctx context.Context, muterID uuid.UUID, mutedID uuid.UUID) error {
+	_ = ctx
+	_ = muterID
+	_ = mutedID
+
+	return nil
+}
+
+func (r *mockUserRepo) GetHiddenUserIDs(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	_ = ctx
+	_ = userID
+
+	return nil, nil
+}
+
+func (r *mockUserRepo) SuggestFollows(ctx context.Context, userID uuid.UUID, limit int32) ([]uuid.UUID, error) {
+	_ = ctx
+	_ = userID
+	_ = limit
+
+	return nil, nil
+}
+
+func (r *mockUserRepo) GetPopularUsers(ctx context.Context, userID uuid.UUID, limit int32) ([]uuid.UUID, error) {
+	_ = ctx
+	_ = userID
+	_ = limit
+
+	return nil, nil
+}
+
+func (r *mockUserRepo) FilterSuggestable(ctx context.Context, userID uuid.UUID, candidateIDs []uuid.UUID) ([]uuid.UUID, error) {
+	_ = ctx
+	_ = userID
+	_ = candidateIDs
+
+	return candidateIDs, nil
+}
+
+func (r *mockUserRepo) IsFollowing(ctx context.Context, followerID uuid.UUID, followeeID uuid.UUID) (bool, error) {
+	_ = ctx
+	_ = followerID
+	_ = followeeID
+
+	return false, nil
+}
+
+func (r *mockUserRepo) GetKnownFollowers(ctx context.Context, viewerID uuid.UUID, userID uuid.UUID, limit, offset int32) ([]uuid.UUID, error) {
+	_ = ctx
+	_ = viewerID
+	_ = userID
+	_ = limit
+	_ = offset
+
+	return []uuid.UUID{}, nil
+}
+
+func (r *mockUserRepo) GetRelationships(ctx context.Context, userID uuid.UUID, ids []uuid.UUID) ([]*models.Relationship, error) {
+	_ = ctx
+	_ = userID
+	_ = ids
+
+	return []*models.Relationship{}, nil
+}
+
+// Mock message repo (in-memory)
+type mockMessageRepo struct {
+	// Users that exist, false if they don't accept messages from anyone
+	users map[uuid.UUID]bool
+	// Users who blocked or are blocked by anyone
+	blocked       map[uuid.UUID]bool
+	conversations map[uuid.UUID]*models.Conversation
+	messages      []*models.Message
+	readAt        map[uuid.UUID]map[uuid.UUID]time.Time
+}
+
+func newMockMessageRepo() *mockMessageRepo {
+	return &mockMessageRepo{
+		users: map[uuid.UUID]bool{
+			testUserID:    true,
+			testUser2ID:   true,
+			testUser3ID:   true,
+			testBlockedID: false,
+		},
+		blocked:       map[uuid.UUID]bool{},
+		conversations: map[uuid.UUID]*models.Conversation{},
+		readAt:        map[uuid.UUID]map[uuid.UUID]time.Time{},
+	}
+}
+
+func (r *mockMessageRepo) GetRecipients(ctx context.Context, senderID uuid.UUID, userIDs []uuid.UUID) ([]*models.MessageRecipient, error) {
+	_ = ctx
+	_ = senderID
+
+	var recipients []*models.MessageRecipient
+	for _, id := range userIDs {
+		if allowed, ok := r.users[id]; ok {
+			recipients = append(recipients, &models.MessageRecipient{ID: id, Allowed: allowed})
+		}
+	}
+	return recipients, nil
+}
+
+func (r *mockMessageRepo) CreateConversation(ctx context.Context, memberIDs []uuid.UUID, group bool) (uuid.UUID, bool, error) {
+	_ = ctx
+
+	if !group {
+		for _, c := range r.conversations {
+			if !c.IsGroup && len(c.MemberIDs) == 2 && slices.Contains(c.MemberIDs, memberIDs[0]) && slices.Contains(c.MemberIDs, memberIDs[1]) {
+				return c.ID, false, nil
+			}
+		}
+	}
+
+	c := &models.Conversation{
+		ID:        uuid.New(),
+		IsGroup:   group,
+		MemberIDs: memberIDs,
+		CreatedAt: time.Now(),
+	}
+	r.conversations[c.ID] = c
+	r.readAt[c.ID] = map[uuid.UUID]time.Time{}
+	for _, id := range memberIDs {
+		r.readAt[c.ID][id] = c.CreatedAt
+	}
+	return c.ID, true, nil
+}
+
+func (r *mockMessageRepo) GetConversation(ctx context.Context, userID uuid.UUID, id uuid.UUID) (*models.Conversation, error) {
+	_ = ctx
+
+	c, ok := r.conversations[id]
+	if !ok || !slices.Contains(c.MemberIDs, userID) {
+		return nil, pgx.ErrNoRows
+	}
+
+	conversation := *c
+	for _, m := range r.messages {
+		if m.ConversationID != id {
+			continue
+		}
+		conversation.LastMessage = m
+		if m.SenderID != userID && m.CreatedAt.After(r.readAt[id][userID]) {
+			conversation.UnreadCount++
+		}
+	}
+	return &conversation, nil
+}
+
+func (r *mockMessageRepo) GetUserConversations(ctx context.Context, userID uuid.UUID, limit, offset int32) ([]*models.Conversation, error) {
+	_ = limit
+	_ = offset
+
+	var conversations []*models.Conversation
+	for id := range r.conversations {
+		if c, err := r.GetConversation(ctx, userID, id); err == nil {
+			conversations = append(conversations, c)
+		}
+	}
+	return conversations, nil
+}
+
+func (r *mockMessageRepo) HasBlocks(ctx context.Context, userID uuid.UUID, conversationID uuid.UUID) (bool, error) {
+	_ = ctx
+
+	for _, id := range r.conversations[conversationID].MemberIDs {
+		if id != userID && r.blocked[id] {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *mockMessageRepo) CreateMessage(ctx context.Context, conversationID uuid.UUID, senderID uuid.UUID, content string) (*models.Message, error) {
+	_ = ctx
+
+	m := &models.Message{
+		ID:             uuid.New(),
+		ConversationID: conversationID,
+		SenderID:       senderID,
+		Content:        content,
+		// Strictly increasing, so order doesn't depend on clock resolution
+		CreatedAt: time.Now().Add(time.Duration(len(r.messages)+1) * time.Millisecond),
+	}
+	r.messages = append(r.messages, m)
+	r.readAt[conversationID][senderID] = m.CreatedAt
+	return m, nil
+}
+
+func (r *mockMessageRepo) GetMessages(ctx context.Context, conversationID uuid.UUID, before *uuid.UUID, limit int32) ([]*models.Message, error) {
+	_ = ctx
+
+	var messages []*models.Message
+	found := before == nil
+	for i := len(r.messages) - 1; i >= 0 && len(messages) < int(limit); i-- {
+		m := r.messages[i]
+		if m.ConversationID != conversationID {
+			continue
+		}
+		if found {
+			messages = append(messages, m)
+		} else if m.ID == *before {
+			found = true
+		}
+	}
+	return messages, nil
+}
+
+func (r *mockMessageRepo) MarkRead(ctx context.Context, userID uuid.UUID, conversationID uuid.UUID) (bool, error) {
+	_ = ctx
+
+	members, ok := r.readAt[conversationID]
+	if !ok {
+		return false, nil
+	}
+	if _, ok := members[userID]; !ok {
+		return false, nil
+	}
+	for _, m := range r.messages {
+		if m.ConversationID == conversationID && m.CreatedAt.After(members[userID]) {
+			members[userID] = m.CreatedAt
+		}
+	}
+	return true, nil
+}
+
+func (r *mockMessageRepo) GetUnreadCount(ctx context.Context, userID uuid.UUID) (int64, error) {
+	var count int64
+	for id := range r.conversations {
+		if c, err := r.GetConversation(ctx, userID, id); err == nil {
+			count += c.UnreadCount
+		}
+	}
+	return count, nil
+}
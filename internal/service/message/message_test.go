@@ -0,0 +1,193 @@
+package message
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestService_CreateConversation(t *testing.T) {
+	service := NewService(newMockMessageRepo(), &mockUserRepo{}, 3)
+	ctx := context.Background()
+
+	conversation, created, err := service.CreateConversation(ctx, testUserID, []uuid.UUID{testUser2ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, created)
+	assert.False(t, conversation.IsGroup)
+	assert.Len(t, conversation.Members, 2)
+
+	// The same pair of users shares one conversation, whoever starts it
+	again, created, err := service.CreateConversation(ctx, testUser2ID, []uuid.UUID{testUserID, testUserID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.False(t, created)
+	assert.Equal(t, conversation.ID, again.ID)
+
+	// Group
+	group, _, err := service.CreateConversation(ctx, testUserID, []uuid.UUID{testUser2ID, testUser3ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, group.IsGroup)
+
+	for _, tc := range []struct {
+		ids  []uuid.UUID
+		code int
+	}{
+		{[]uuid.UUID{testUserID}, http.StatusBadRequest},
+		{[]uuid.UUID{testUser2ID, testUser3ID, uuid.New()}, http.StatusBadRequest},
+		{[]uuid.UUID{uuid.New()}, http.StatusNotFound},
+		{[]uuid.UUID{testBlockedID}, http.StatusForbidden},
+		{[]uuid.UUID{testUser2ID, testBlockedID}, http.StatusForbidden},
+	} {
+		_, _, err := service.CreateConversation(ctx, testUserID, tc.ids)
+		if assert.NotNil(t, err, tc.ids) {
+			assert.Equal(t, tc.code, err.Code, tc.ids)
+		}
+	}
+}
+
+func TestService_SendMessage(t *testing.T) {
+	mr := newMockMessageRepo()
+	service := NewService(mr, &mockUserRepo{}, 3)
+	ctx := context.Background()
+
+	conversation, _, err := service.CreateConversation(ctx, testUserID, []uuid.UUID{testUser2ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := service.SendMessage(ctx, testUserID, conversation.ID, "hi"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only members can send
+	_, err = service.SendMessage(ctx, testUser3ID, conversation.ID, "hi")
+	if assert.NotNil(t, err) {
+		assert.Equal(t, http.StatusNotFound, err.Code)
+	}
+
+	// Recipient blocked sender after conversation was started
+	mr.users[testUser2ID] = false
+	_, err = service.SendMessage(ctx, testUserID, conversation.ID, "hi again")
+	if assert.NotNil(t, err) {
+		assert.Equal(t, http.StatusForbidden, err.Code)
+	}
+
+	// Recipient deactivated account
+	delete(mr.users, testUser2ID)
+	_, err = service.SendMessage(ctx, testUserID, conversation.ID, "hi again")
+	if assert.NotNil(t, err) {
+		assert.Equal(t, http.StatusForbidden, err.Code)
+	}
+}
+
+func TestService_SendGroupMessage(t *testing.T) {
+	mr := newMockMessageRepo()
+	service := NewService(mr, &mockUserRepo{}, 3)
+	ctx := context.Background()
+
+	group, _, err := service.CreateConversation(ctx, testUserID, []uuid.UUID{testUser2ID, testUser3ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := service.SendMessage(ctx, testUserID, group.ID, "hi"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Member blocked sender after group was created
+	mr.blocked[testUser3ID] = true
+	_, err = service.SendMessage(ctx, testUserID, group.ID, "hi again")
+	if assert.NotNil(t, err) {
+		assert.Equal(t, http.StatusForbidden, err.Code)
+	}
+}
+
+func TestService_GetMessages(t *testing.T) {
+	service := NewService(newMockMessageRepo(), &mockUserRepo{}, 3)
+	ctx := context.Background()
+
+	conversation, _, err := service.CreateConversation(ctx, testUserID, []uuid.UUID{testUser2ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, content := range []string{"1", "2", "3"} {
+		if _, err := service.SendMessage(ctx, testUserID, conversation.ID, content); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Newest first
+	page, err := service.GetMessages(ctx, testUser2ID, conversation.ID, nil, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assert.Len(t, page, 2) {
+		assert.Equal(t, "3", page[0].Content)
+		assert.Equal(t, "2", page[1].Content)
+	}
+
+	// Next page starts after the last message of previous one
+	page, err = service.GetMessages(ctx, testUser2ID, conversation.ID, &page[1].ID, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assert.Len(t, page, 1) {
+		assert.Equal(t, "1", page[0].Content)
+	}
+
+	_, err = service.GetMessages(ctx, testUser3ID, conversation.ID, nil, 2)
+	if assert.NotNil(t, err) {
+		assert.Equal(t, http.StatusNotFound, err.Code)
+	}
+}
+
+func TestService_MarkRead(t *testing.T) {
+	service := NewService(newMockMessageRepo(), &mockUserRepo{}, 3)
+	ctx := context.Background()
+
+	conversation, _, err := service.CreateConversation(ctx, testUserID, []uuid.UUID{testUser2ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, content := range []string{"1", "2"} {
+		if _, err := service.SendMessage(ctx, testUserID, conversation.ID, content); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Own messages aren't unread
+	count, err := service.GetUnreadCount(ctx, testUserID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, int64(0), count)
+
+	count, err = service.GetUnreadCount(ctx, testUser2ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, int64(2), count)
+
+	if err := service.MarkRead(ctx, testUser2ID, conversation.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	count, err = service.GetUnreadCount(ctx, testUser2ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, int64(0), count)
+
+	err = service.MarkRead(ctx, testUser3ID, conversation.ID)
+	if assert.NotNil(t, err) {
+		assert.Equal(t, http.StatusNotFound, err.Code)
+	}
+}
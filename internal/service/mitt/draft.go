@@ -0,0 +1,253 @@
+package mitt
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/misshanya/mitter/internal/models"
+	"github.com/misshanya/mitter/pkg/pgutil"
+)
+
+const (
+	// How many scheduled drafts are claimed per one db query
+	scheduleBatchSize = 50
+	// Claimed drafts are skipped by other instances for this long
+	scheduleLease = time.Minute
+)
+
+// checkPublishAt returns error if scheduled time has already passed
+func checkPublishAt(publishAt *time.Time) *models.HTTPError {
+	if publishAt != nil && !publishAt.After(time.Now()) {
+		return &models.HTTPError{
+			Code:    http.StatusBadRequest,
+			Message: "Publish time must be in the future",
+		}
+	}
+	return nil
+}
+
+// CreateDraft saves draft, it's published at PublishAt if it's set
+func (s *Service) CreateDraft(ctx context.Context, userID uuid.UUID, draft *models.DraftCreate) (*models.Draft, *models.HTTPError) {
+	if httpErr := checkPublishAt(draft.PublishAt); httpErr != nil {
+		return nil, httpErr
+	}
+
+	created, err := s.dr.CreateDraft(ctx, userID, draft)
+	if err != nil {
+		if pgutil.IsCheckViolation(err) {
+			return nil, &models.HTTPError{
+				Code:    http.StatusBadRequest,
+				Message: "Invalid mitt content",
+			}
+		}
+		slog.Error("error creating draft", slog.Any("err", err))
+		return nil, &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		}
+	}
+
+	return created, nil
+}
+
+func (s *Service) GetDraft(ctx context.Context, userID uuid.UUID, id uuid.UUID) (*models.Draft, *models.HTTPError) {
+	draft, err := s.dr.GetDraft(ctx, userID, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, &models.HTTPError{
+				Code:    http.StatusNotFound,
+				Message: "Draft not found",
+			}
+		}
+		slog.Error("error getting draft", slog.Any("err", err))
+		return nil, &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		}
+	}
+
+	return draft, nil
+}
+
+// GetDrafts returns drafts of user, only scheduled or not scheduled ones if scheduled is set
+func (s *Service) GetDrafts(ctx context.Context, userID uuid.UUID, scheduled *bool, limit, offset int32) ([]*models.Draft, *models.HTTPError) {
+	drafts, err := s.dr.GetUserDrafts(ctx, userID, scheduled, limit, offset)
+	if err != nil {
+		slog.Error("error getting drafts", slog.Any("err", err))
+		return nil, &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		}
+	}
+
+	return drafts, nil
+}
+
+// UpdateDraft replaces content and publish time of draft, nil publish time cancels scheduled publishing
+func (s *Service) UpdateDraft(ctx context.Context, userID uuid.UUID, id uuid.UUID, draft *models.DraftUpdate) (*models.Draft, *models.HTTPError) {
+	if httpErr := checkPublishAt(draft.PublishAt); httpErr != nil {
+		return nil, httpErr
+	}
+
+	existing, httpErr := s.GetDraft(ctx, userID, id)
+	if httpErr != nil {
+		return nil, httpErr
+	}
+
+	// Fail early, update checks version again in case of concurrent edit
+	if draft.ExpectedVersion != nil && *draft.ExpectedVersion != existing.Version {
+		return nil, &models.HTTPError{
+			Code:    http.StatusPreconditionFailed,
+			Message: "Draft was modified, fetch it again",
+		}
+	}
+
+	updated, err := s.dr.UpdateDraft(ctx, userID, id, draft)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, &models.HTTPError{
+				Code:    http.StatusNotFound,
+				Message: "Draft not found",
+			}
+		}
+		if errors.Is(err, models.ErrVersionConflict) {
+			return nil, &models.HTTPError{
+				Code:    http.StatusPreconditionFailed,
+				Message: "Draft was modified, fetch it again",
+			}
+		}
+		if pgutil.IsCheckViolation(err) {
+			return nil, &models.HTTPError{
+				Code:    http.StatusBadRequest,
+				Message: "Invalid mitt content",
+			}
+		}
+		slog.Error("error updating draft", slog.Any("err", err))
+		return nil, &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		}
+	}
+
+	return updated, nil
+}
+
+// CancelSchedule keeps scheduled draft as a plain draft, it won't be published
+func (s *Service) CancelSchedule(ctx context.Context, userID uuid.UUID, id uuid.UUID) (*models.Draft, *models.HTTPError) {
+	draft, httpErr := s.GetDraft(ctx, userID, id)
+	if httpErr != nil {
+		return nil, httpErr
+	}
+
+	if draft.PublishAt == nil {
+		return nil, &models.HTTPError{
+			Code:    http.StatusConflict,
+			Message: "Draft is not scheduled",
+		}
+	}
+
+	// Draft can be published meanwhile, then it's gone
+	return s.UpdateDraft(ctx, userID, id, &models.DraftUpdate{
+		Content:         draft.Content,
+		ExpectedVersion: &draft.Version,
+	})
+}
+
+func (s *Service) DeleteDraft(ctx context.Context, userID uuid.UUID, id uuid.UUID) *models.HTTPError {
+	deleted, err := s.dr.DeleteDraft(ctx, userID, id)
+	if err != nil {
+		slog.Error("error deleting draft", slog.Any("err", err))
+		return &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		}
+	}
+	if !deleted {
+		return &models.HTTPError{
+			Code:    http.StatusNotFound,
+			Message: "Draft not found",
+		}
+	}
+	return nil
+}
+
+// PublishDraft publishes draft right away, even if it's scheduled
+func (s *Service) PublishDraft(ctx context.Context, userID uuid.UUID, id uuid.UUID) (*models.Mitt, *models.HTTPError) {
+	draft, httpErr := s.GetDraft(ctx, userID, id)
+	if httpErr != nil {
+		return nil, httpErr
+	}
+
+	mitt, httpErr := s.publishDraft(ctx, draft)
+	if httpErr != nil {
+		return nil, httpErr
+	}
+	if mitt == nil {
+		return nil, &models.HTTPError{
+			Code:    http.StatusConflict,
+			Message: "Draft was modified or already published, fetch it again",
+		}
+	}
+	return mitt, nil
+}
+
+// publishDraft creates mitt from draft the same way as CreateMitt does.
+// Returns nil if draft was changed or published since it was read
+func (s *Service) publishDraft(ctx context.Context, draft *models.Draft) (*models.Mitt, *models.HTTPError) {
+	mitt := &models.MittCreate{Content: draft.Content}
+	if httpErr := s.prepareMitt(mitt); httpErr != nil {
+		return nil, httpErr
+	}
+
+	newMitt, err := s.dr.PublishDraft(ctx, draft, mitt)
+	if err != nil {
+		slog.Error("error publishing draft", slog.String("draftID", draft.ID.String()), slog.Any("err", err))
+		return nil, &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		}
+	}
+	if newMitt == nil {
+		return nil, nil
+	}
+
+	if httpErr := s.afterCreate(ctx, mitt, newMitt); httpErr != nil {
+		return nil, httpErr
+	}
+	return newMitt, nil
+}
+
+// PublishScheduled publishes drafts which are due. Every draft is published once
+// even if several instances run the job at the same time
+func (s *Service) PublishScheduled(ctx context.Context) error {
+	var total int
+	for {
+		drafts, err := s.dr.ClaimScheduled(ctx, scheduleLease, scheduleBatchSize)
+		if err != nil {
+			slog.Error("error claiming scheduled drafts", slog.Any("err", err))
+			return err
+		}
+
+		for _, draft := range drafts {
+			// Failed draft is retried once its lease expires
+			mitt, httpErr := s.publishDraft(ctx, draft)
+			if httpErr == nil && mitt != nil {
+				total++
+			}
+		}
+
+		if len(drafts) < scheduleBatchSize {
+			break
+		}
+	}
+
+	if total > 0 {
+		slog.Info("published scheduled drafts", slog.Int("count", total))
+	}
+	return nil
+}
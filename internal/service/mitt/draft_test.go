@@ -0,0 +1,146 @@
+package mitt
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/misshanya/mitter/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMittService_CreateDraft(t *testing.T) {
+	dr := newMockDraftRepo()
+	service := NewService(mockMittRepo{}, dr, &mockMittMetrics{}, &mockUserRepo{}, &mockTrendingRepo{}, &mockNotifier{}, &mockEventPublisher{}, Options{TrashRetention: time.Hour, MaxPollDuration: 24 * time.Hour, MaxPinned: 3})
+	ctx := context.Background()
+
+	draft, err := service.CreateDraft(ctx, mockUserID, &models.DraftCreate{Content: "release notes"})
+	require.Nil(t, err)
+	assert.Nil(t, draft.PublishAt)
+
+	past := time.Now().Add(-time.Minute)
+	_, err = service.CreateDraft(ctx, mockUserID, &models.DraftCreate{Content: "too late", PublishAt: &past})
+	if assert.NotNil(t, err) {
+		assert.Equal(t, http.StatusBadRequest, err.Code)
+	}
+	assert.Len(t, dr.drafts, 1)
+
+	// Drafts are private
+	_, err = service.GetDraft(ctx, uuid.New(), draft.ID)
+	if assert.NotNil(t, err) {
+		assert.Equal(t, http.StatusNotFound, err.Code)
+	}
+}
+
+func TestMittService_UpdateDraft(t *testing.T) {
+	service := NewService(mockMittRepo{}, newMockDraftRepo(), &mockMittMetrics{}, &mockUserRepo{}, &mockTrendingRepo{}, &mockNotifier{}, &mockEventPublisher{}, Options{TrashRetention: time.Hour, MaxPollDuration: 24 * time.Hour, MaxPinned: 3})
+	ctx := context.Background()
+
+	draft, err := service.CreateDraft(ctx, mockUserID, &models.DraftCreate{Content: "release notes"})
+	require.Nil(t, err)
+
+	publishAt := time.Now().Add(time.Hour)
+	updated, err := service.UpdateDraft(ctx, mockUserID, draft.ID, &models.DraftUpdate{
+		Content:         "release notes v2",
+		PublishAt:       &publishAt,
+		ExpectedVersion: &draft.Version,
+	})
+	require.Nil(t, err)
+	assert.Equal(t, "release notes v2", updated.Content)
+	assert.Equal(t, int32(2), updated.Version)
+	assert.NotNil(t, updated.PublishAt)
+
+	// Stale version
+	_, err = service.UpdateDraft(ctx, mockUserID, draft.ID, &models.DraftUpdate{
+		Content:         "release notes v3",
+		ExpectedVersion: &draft.Version,
+	})
+	if assert.NotNil(t, err) {
+		assert.Equal(t, http.StatusPreconditionFailed, err.Code)
+	}
+
+	// Cancel schedule
+	canceled, err := service.CancelSchedule(ctx, mockUserID, draft.ID)
+	require.Nil(t, err)
+	assert.Nil(t, canceled.PublishAt)
+	assert.Equal(t, "release notes v2", canceled.Content)
+
+	_, err = service.CancelSchedule(ctx, mockUserID, draft.ID)
+	if assert.NotNil(t, err) {
+		assert.Equal(t, http.StatusConflict, err.Code)
+	}
+}
+
+func TestMittService_PublishDraft(t *testing.T) {
+	ep := &mockEventPublisher{}
+	dr := newMockDraftRepo()
+	service := NewService(mockMittRepo{}, dr, &mockMittMetrics{}, &mockUserRepo{}, &mockTrendingRepo{}, &mockNotifier{}, ep, Options{TrashRetention: time.Hour, MaxPollDuration: 24 * time.Hour, MaxPinned: 3})
+	ctx := context.Background()
+
+	draft, err := service.CreateDraft(ctx, mockUserID, &models.DraftCreate{Content: "release notes"})
+	require.Nil(t, err)
+
+	mitt, err := service.PublishDraft(ctx, mockUserID, draft.ID)
+	require.Nil(t, err)
+	assert.Equal(t, "release notes", mitt.Content)
+	assert.Empty(t, dr.drafts)
+	// Published draft is streamed like a new mitt
+	assert.Len(t, ep.mitts, 1)
+
+	_, err = service.PublishDraft(ctx, mockUserID, draft.ID)
+	if assert.NotNil(t, err) {
+		assert.Equal(t, http.StatusNotFound, err.Code)
+	}
+}
+
+func TestMittService_PublishScheduled(t *testing.T) {
+	ep := &mockEventPublisher{}
+	dr := newMockDraftRepo()
+	service := NewService(mockMittRepo{}, dr, &mockMittMetrics{}, &mockUserRepo{}, &mockTrendingRepo{}, &mockNotifier{}, ep, Options{TrashRetention: time.Hour, MaxPollDuration: 24 * time.Hour, MaxPinned: 3})
+	ctx := context.Background()
+
+	publishAt := time.Now().Add(time.Hour)
+	later, err := service.CreateDraft(ctx, mockUserID, &models.DraftCreate{Content: "later", PublishAt: &publishAt})
+	require.Nil(t, err)
+	_, err = service.CreateDraft(ctx, mockUserID, &models.DraftCreate{Content: "draft"})
+	require.Nil(t, err)
+
+	// Make the first draft due
+	past := time.Now().Add(-time.Second)
+	dr.drafts[later.ID].PublishAt = &past
+
+	require.NoError(t, service.PublishScheduled(ctx))
+	require.NoError(t, service.PublishScheduled(ctx))
+
+	// Published once, the plain draft stays
+	if assert.Len(t, ep.mitts, 1) {
+		assert.Equal(t, "later", ep.mitts[0].Content)
+	}
+	assert.Len(t, dr.drafts, 1)
+	assert.NotContains(t, dr.drafts, later.ID)
+}
+
+func TestMittService_PublishScheduledEdited(t *testing.T) {
+	ep := &mockEventPublisher{}
+	dr := newMockDraftRepo()
+	service := NewService(mockMittRepo{}, dr, &mockMittMetrics{}, &mockUserRepo{}, &mockTrendingRepo{}, &mockNotifier{}, ep, Options{TrashRetention: time.Hour, MaxPollDuration: 24 * time.Hour, MaxPinned: 3})
+	ctx := context.Background()
+
+	publishAt := time.Now().Add(time.Hour)
+	draft, err := service.CreateDraft(ctx, mockUserID, &models.DraftCreate{Content: "v1", PublishAt: &publishAt})
+	require.Nil(t, err)
+
+	// Draft claimed by scheduler is edited before it's published, the old version isn't published
+	claimed := *dr.drafts[draft.ID]
+	_, err = service.UpdateDraft(ctx, mockUserID, draft.ID, &models.DraftUpdate{Content: "v2", PublishAt: &publishAt})
+	require.Nil(t, err)
+
+	mitt, err := service.publishDraft(ctx, &claimed)
+	assert.Nil(t, err)
+	assert.Nil(t, mitt)
+	assert.Empty(t, ep.mitts)
+	assert.Contains(t, dr.drafts, draft.ID)
+}
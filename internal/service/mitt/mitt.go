@@ -3,27 +3,83 @@ package mitt
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/misshanya/mitter/internal/dataloader"
 	"github.com/misshanya/mitter/internal/models"
+	"github.com/misshanya/mitter/pkg/content"
+	"github.com/misshanya/mitter/pkg/pgutil"
+)
+
+const (
+	// How many mitts are hard deleted per one db query while purging trash or expired mitts
+	purgeBatchSize = 100
+	// Expiring mitt must live at least this long after creation
+	minMittLifetime = 5 * time.Minute
 )
 
 type Service struct {
 	mr models.MittRepository
+	dr models.DraftRepository
 	mm models.MittMetrics
 	ur models.UserRepository
+	tr models.TrendingRepository
+	nt models.Notifier
+	ep models.EventPublisher
+
+	editWindow      time.Duration
+	trashRetention  time.Duration
+	maxPollDuration time.Duration
+	maxPinned       int
 }
 
-func NewService(mr models.MittRepository, mm models.MittMetrics, ur models.UserRepository) *Service {
-	return &Service{mr: mr, mm: mm, ur: ur}
+// Options are limits of mitts
+type Options struct {
+	// Mitts can be edited only for EditWindow after creation, zero means forever
+	EditWindow time.Duration
+	// Deleted mitts can be restored from trash during TrashRetention
+	TrashRetention time.Duration
+	// Polls can be open for up to MaxPollDuration
+	MaxPollDuration time.Duration
+	// Users can pin up to MaxPinned mitts to their profiles
+	MaxPinned int
+}
+
+// NewService creates mitt service with given limits
+func NewService(mr models.MittRepository, dr models.DraftRepository, mm models.MittMetrics, ur models.UserRepository, tr models.TrendingRepository, nt models.Notifier, ep models.EventPublisher, opts Options) *Service {
+	return &Service{
+		mr:              mr,
+		dr:              dr,
+		mm:              mm,
+		ur:              ur,
+		tr:              tr,
+		nt:              nt,
+		ep:              ep,
+		editWindow:      opts.EditWindow,
+		trashRetention:  opts.TrashRetention,
+		maxPollDuration: opts.MaxPollDuration,
+		maxPinned:       opts.MaxPinned,
+	}
 }
 
 func (s *Service) CreateMitt(ctx context.Context, userID uuid.UUID, mitt *models.MittCreate) (*models.Mitt, *models.HTTPError) {
+	if httpErr := s.prepareMitt(mitt); httpErr != nil {
+		return nil, httpErr
+	}
+
 	newMitt, err := s.mr.CreateMitt(ctx, userID, mitt)
 	if err != nil {
+		if pgutil.IsCheckViolation(err) {
+			return nil, &models.HTTPError{
+				Code:    http.StatusBadRequest,
+				Message: "Invalid mitt content",
+			}
+		}
 		slog.Error("error creating mitt", slog.Any("err", err))
 		return nil, &models.HTTPError{
 			Code:    http.StatusInternalServerError,
@@ -31,42 +87,144 @@ func (s *Service) CreateMitt(ctx context.Context, userID uuid.UUID, mitt *models
 		}
 	}
 
+	if httpErr := s.afterCreate(ctx, mitt, newMitt); httpErr != nil {
+		return nil, httpErr
+	}
+	return newMitt, nil
+}
+
+// prepareMitt extracts hashtags and mentions of new mitt and validates its poll.
+// Expiry, if set, must be at least minMittLifetime from now
+func (s *Service) prepareMitt(mitt *models.MittCreate) *models.HTTPError {
+	mitt.Hashtags = s.extractHashtags(mitt.Content)
+	mitt.Mentions = content.MentionedLogins(mitt.Content)
+
+	now := time.Now()
+	if mitt.ExpiresAt != nil && mitt.ExpiresAt.Before(now.Add(minMittLifetime)) {
+		return &models.HTTPError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Mitt must expire at least %s from now", minMittLifetime),
+		}
+	}
+
+	if mitt.Poll != nil {
+		return s.validatePoll(mitt.Poll, now)
+	}
+	return nil
+}
+
+// afterCreate does everything that follows creation of mitt: tracks hashtags, notifies mentioned users and streams it
+func (s *Service) afterCreate(ctx context.Context, mitt *models.MittCreate, newMitt *models.Mitt) *models.HTTPError {
 	if err := s.setAuthorName(ctx, newMitt); err != nil {
-		return nil, &models.HTTPError{
+		return &models.HTTPError{
 			Code:    http.StatusInternalServerError,
 			Message: "Internal server error",
 		}
 	}
 
+	s.trackHashtags(ctx, mitt.Hashtags, newMitt.CreatedAt, 1)
+	s.notifyMentions(ctx, newMitt)
+	s.ep.PublishMitt(ctx, newMitt)
+
 	// Update metrics
 	go s.mm.AddMitt()
 
-	return newMitt, nil
+	return nil
+}
+
+// notifyMentions notifies mentioned users, users mentioned before are notified only once
+func (s *Service) notifyMentions(ctx context.Context, mitt *models.Mitt) {
+	notified := make(map[uuid.UUID]struct{}, len(mitt.Mentions))
+	for _, mention := range mitt.Mentions {
+		if _, ok := notified[mention.UserID]; ok {
+			continue
+		}
+		notified[mention.UserID] = struct{}{}
+
+		s.nt.Notify(ctx, &models.NotificationEvent{
+			Type:    models.NotificationMention,
+			ActorID: mitt.AuthorID,
+			UserID:  &mention.UserID,
+			MittID:  &mitt.ID,
+		})
+	}
+}
+
+// notifyLike notifies author of mitt about new like
+func (s *Service) notifyLike(ctx context.Context, userID uuid.UUID, mittID uuid.UUID) {
+	s.nt.Notify(ctx, &models.NotificationEvent{
+		Type:    models.NotificationLike,
+		ActorID: userID,
+		MittID:  &mittID,
+	})
 }
 
-func (s *Service) setLikesCount(ctx context.Context, mitt *models.Mitt) error {
-	likesCount, err := s.mr.GetMittLikesCount(ctx, mitt.ID)
+// publishLikes streams current likes count of mitt
+func (s *Service) publishLikes(ctx context.Context, mittID uuid.UUID) {
+	mitt, err := s.mr.GetMitt(ctx, mittID)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil
+		slog.Error("error getting mitt likes count", slog.Any("err", err))
+		return
+	}
+	s.ep.PublishLikes(ctx, mittID, mitt.AuthorID, mitt.Likes)
+}
+
+// extractHashtags finds hashtags in normalized content
+func (s *Service) extractHashtags(text string) []string {
+	start := time.Now()
+	tags := content.Hashtags(text)
+
+	// Update metrics
+	go s.mm.ExtractHashtags(len(tags), time.Since(start))
+
+	return tags
+}
+
+// trackHashtags counts delta uses of tags in mitt created at given time for trends.
+// Trends are approximate, so failure doesn't fail the request
+func (s *Service) trackHashtags(ctx context.Context, tags []string, createdAt time.Time, delta int64) {
+	if len(tags) == 0 {
+		return
+	}
+
+	if err := s.tr.AddHashtagUses(ctx, tags, createdAt, delta); err != nil {
+		slog.Error("error tracking hashtag uses", slog.Any("err", err))
+	}
+}
+
+// diffHashtags returns tags which are only in new and only in old
+func diffHashtags(old, new []string) (added, removed []string) {
+	oldSet := make(map[string]struct{}, len(old))
+	for _, tag := range old {
+		oldSet[tag] = struct{}{}
+	}
+
+	for _, tag := range new {
+		if _, ok := oldSet[tag]; ok {
+			delete(oldSet, tag)
+			continue
 		}
-		slog.Error("error getting likes count", slog.Any("err", err))
-		return err
+		added = append(added, tag)
 	}
-	mitt.Likes = likesCount
-	return nil
+
+	for _, tag := range old {
+		if _, ok := oldSet[tag]; ok {
+			removed = append(removed, tag)
+		}
+	}
+
+	return added, removed
 }
 
 func (s *Service) setAuthorName(ctx context.Context, mitt *models.Mitt) error {
-	user, err := s.ur.GetUserByID(ctx, mitt.AuthorID)
+	user, err := dataloader.Users(ctx, s.ur).Load(ctx, mitt.AuthorID)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil
-		}
-		slog.Error("error getting user id", slog.Any("err", err))
+		slog.Error("error getting user", slog.Any("err", err))
 		return err
 	}
-	mitt.AuthorName = user.Name
+	if user != nil {
+		mitt.AuthorName = user.Name
+	}
 	return nil
 }
 
@@ -86,20 +244,6 @@ func (s *Service) GetMitt(ctx context.Context, id uuid.UUID) (*models.Mitt, *mod
 		}
 	}
 
-	if err := s.setLikesCount(ctx, mitt); err != nil {
-		return nil, &models.HTTPError{
-			Code:    http.StatusInternalServerError,
-			Message: "Internal server error",
-		}
-	}
-
-	if err := s.setAuthorName(ctx, mitt); err != nil {
-		return nil, &models.HTTPError{
-			Code:    http.StatusInternalServerError,
-			Message: "Internal server error",
-		}
-	}
-
 	return mitt, nil
 }
 
@@ -119,22 +263,6 @@ func (s *Service) GetAllUserMitts(ctx context.Context, userID uuid.UUID, limit,
 		}
 	}
 
-	for _, mitt := range mitts {
-		if err := s.setLikesCount(ctx, mitt); err != nil {
-			return nil, &models.HTTPError{
-				Code:    http.StatusInternalServerError,
-				Message: "Internal server error",
-			}
-		}
-
-		if err := s.setAuthorName(ctx, mitt); err != nil {
-			return nil, &models.HTTPError{
-				Code:    http.StatusInternalServerError,
-				Message: "Internal server error",
-			}
-		}
-	}
-
 	return mitts, nil
 }
 
@@ -152,6 +280,25 @@ func (s *Service) UpdateMitt(ctx context.Context, userID uuid.UUID, mittID uuid.
 		}
 	}
 
+	// Fail early, update checks version again in case of concurrent edit
+	if mitt.ExpectedVersion != nil && *mitt.ExpectedVersion != existingMitt.Version {
+		return nil, &models.HTTPError{
+			Code:    http.StatusPreconditionFailed,
+			Message: "Mitt was modified, fetch it again",
+		}
+	}
+
+	if s.editWindow > 0 && time.Since(existingMitt.CreatedAt) > s.editWindow {
+		return nil, &models.HTTPError{
+			Code:    http.StatusForbidden,
+			Message: "Mitt can't be edited anymore",
+		}
+	}
+
+	oldHashtags := content.Hashtags(existingMitt.Content)
+	mitt.Hashtags = s.extractHashtags(mitt.Content)
+	mitt.Mentions = content.MentionedLogins(mitt.Content)
+
 	newMitt, err := s.mr.UpdateMitt(ctx, mittID, mitt)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -160,6 +307,18 @@ func (s *Service) UpdateMitt(ctx context.Context, userID uuid.UUID, mittID uuid.
 				Message: "Mitt not found",
 			}
 		}
+		if errors.Is(err, models.ErrVersionConflict) {
+			return nil, &models.HTTPError{
+				Code:    http.StatusPreconditionFailed,
+				Message: "Mitt was modified, fetch it again",
+			}
+		}
+		if pgutil.IsCheckViolation(err) {
+			return nil, &models.HTTPError{
+				Code:    http.StatusBadRequest,
+				Message: "Invalid mitt content",
+			}
+		}
 		slog.Error("error updating mitt", slog.Any("err", err))
 		return nil, &models.HTTPError{
 			Code:    http.StatusInternalServerError,
@@ -167,21 +326,34 @@ func (s *Service) UpdateMitt(ctx context.Context, userID uuid.UUID, mittID uuid.
 		}
 	}
 
-	if err := s.setLikesCount(ctx, newMitt); err != nil {
-		return nil, &models.HTTPError{
-			Code:    http.StatusInternalServerError,
-			Message: "Internal server error",
-		}
+	// Editing doesn't change author
+	newMitt.AuthorName = existingMitt.AuthorName
+
+	added, removed := diffHashtags(oldHashtags, mitt.Hashtags)
+	s.trackHashtags(ctx, added, existingMitt.CreatedAt, 1)
+	s.trackHashtags(ctx, removed, existingMitt.CreatedAt, -1)
+	s.notifyMentions(ctx, newMitt)
+
+	return newMitt, nil
+}
+
+// GetMittHistory returns previous versions of mitt, newest first
+func (s *Service) GetMittHistory(ctx context.Context, mittID uuid.UUID, limit, offset int32) ([]*models.MittRevision, *models.HTTPError) {
+	// Check if mitt exists
+	if _, httpErr := s.GetMitt(ctx, mittID); httpErr != nil {
+		return nil, httpErr
 	}
 
-	if err := s.setAuthorName(ctx, newMitt); err != nil {
+	revisions, err := s.mr.GetMittRevisions(ctx, mittID, limit, offset)
+	if err != nil {
+		slog.Error("error getting mitt revisions", slog.Any("err", err))
 		return nil, &models.HTTPError{
 			Code:    http.StatusInternalServerError,
 			Message: "Internal server error",
 		}
 	}
 
-	return newMitt, nil
+	return revisions, nil
 }
 
 func (s *Service) DeleteMitt(ctx context.Context, userID uuid.UUID, mittID uuid.UUID) *models.HTTPError {
@@ -207,50 +379,266 @@ func (s *Service) DeleteMitt(ctx context.Context, userID uuid.UUID, mittID uuid.
 		}
 	}
 
+	// Hashtags stay linked to mitt in trash, but it doesn't count in trends
+	s.trackHashtags(ctx, content.Hashtags(existingMitt.Content), existingMitt.CreatedAt, -1)
+
 	// Update metrics
 	go s.mm.DeleteMitt()
 
 	return nil
 }
 
-// Likes
+// Pins
 
-func (s *Service) SwitchLike(ctx context.Context, userID uuid.UUID, mittID uuid.UUID) (bool, *models.HTTPError) {
-	isAlreadyLiked, err := s.mr.IsMittLikedByUser(ctx, userID, mittID)
+// PinMitt pins mitt of user to their profile, pinned mitts go first in GetAllUserMitts
+func (s *Service) PinMitt(ctx context.Context, userID uuid.UUID, mittID uuid.UUID) *models.HTTPError {
+	existingMitt, httpErr := s.GetMitt(ctx, mittID)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	// Only own mitts can be pinned
+	if existingMitt.AuthorID != userID {
+		return &models.HTTPError{
+			Code:    http.StatusForbidden,
+			Message: "You are not allowed to do this",
+		}
+	}
+
+	if err := s.mr.PinMitt(ctx, userID, mittID, s.maxPinned); err != nil {
+		if errors.Is(err, models.ErrPinLimit) {
+			return &models.HTTPError{
+				Code:    http.StatusConflict,
+				Message: fmt.Sprintf("You can pin up to %d mitts", s.maxPinned),
+			}
+		}
+		if errors.Is(err, models.ErrPrivateAccount) {
+			return &models.HTTPError{
+				Code:    http.StatusForbidden,
+				Message: "Private accounts can't pin mitts",
+			}
+		}
+		slog.Error("error pinning mitt", slog.Any("err", err))
+		return &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) UnpinMitt(ctx context.Context, userID uuid.UUID, mittID uuid.UUID) *models.HTTPError {
+	unpinned, err := s.mr.UnpinMitt(ctx, userID, mittID)
 	if err != nil {
-		slog.Error("error getting isAlreadyLiked", slog.Any("err", err))
-		return false, &models.HTTPError{
+		slog.Error("error unpinning mitt", slog.Any("err", err))
+		return &models.HTTPError{
 			Code:    http.StatusInternalServerError,
 			Message: "Internal server error",
 		}
 	}
 
-	if !isAlreadyLiked {
-		if err := s.mr.LikeMitt(ctx, userID, mittID); err != nil {
-			slog.Error("error liking mitt", slog.Any("err", err))
-			return false, &models.HTTPError{
-				Code:    http.StatusInternalServerError,
-				Message: "Internal server error",
+	if !unpinned {
+		return &models.HTTPError{
+			Code:    http.StatusNotFound,
+			Message: "Mitt is not pinned",
+		}
+	}
+	return nil
+}
+
+// Trash
+
+// RestoreMitt restores deleted mitt of user if it's still in trash
+func (s *Service) RestoreMitt(ctx context.Context, userID uuid.UUID, mittID uuid.UUID) *models.HTTPError {
+	restored, err := s.mr.RestoreMitt(ctx, userID, mittID, s.trashRetention)
+	if err != nil {
+		slog.Error("error restoring mitt", slog.Any("err", err))
+		return &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		}
+	}
+
+	if !restored {
+		return &models.HTTPError{
+			Code:    http.StatusNotFound,
+			Message: "Mitt not found in trash",
+		}
+	}
+
+	// Count hashtags in trends again
+	if restoredMitt, err := s.mr.GetMitt(ctx, mittID); err == nil {
+		s.trackHashtags(ctx, content.Hashtags(restoredMitt.Content), restoredMitt.CreatedAt, 1)
+	}
+
+	// Update metrics
+	go s.mm.AddMitt()
+
+	return nil
+}
+
+// GetTrash returns deleted mitts of user, recently deleted first
+func (s *Service) GetTrash(ctx context.Context, userID uuid.UUID, limit, offset int32) ([]*models.Mitt, *models.HTTPError) {
+	mitts, err := s.mr.GetDeletedUserMitts(ctx, userID, limit, offset)
+	if err != nil {
+		slog.Error("error getting deleted mitts", slog.Any("err", err))
+		return nil, &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		}
+	}
+
+	return mitts, nil
+}
+
+// PurgeTrash hard deletes mitts which are in trash longer than retention
+func (s *Service) PurgeTrash(ctx context.Context) error {
+	var total int64
+	for {
+		purged, err := s.mr.PurgeDeletedMitts(ctx, s.trashRetention, purgeBatchSize)
+		if err != nil {
+			slog.Error("error purging deleted mitts", slog.Any("err", err))
+			return err
+		}
+		total += purged
+
+		if purged < purgeBatchSize {
+			break
+		}
+	}
+
+	if total > 0 {
+		slog.Info("purged deleted mitts", slog.Int64("count", total))
+	}
+	return nil
+}
+
+// DeleteExpired deletes expired mitts, they are hidden since they expire.
+// Every mitt is deleted once even if several instances run the job at the same time
+func (s *Service) DeleteExpired(ctx context.Context) error {
+	var total int
+	for {
+		expired, err := s.mr.DeleteExpiredMitts(ctx, purgeBatchSize)
+		if err != nil {
+			slog.Error("error deleting expired mitts", slog.Any("err", err))
+			return err
+		}
+		total += len(expired)
+
+		for _, m := range expired {
+			// Mitts in trash are already uncounted
+			if !m.Active {
+				continue
 			}
+			s.trackHashtags(ctx, content.Hashtags(m.Content), m.CreatedAt, -1)
+			go s.mm.DeleteMitt()
 		}
 
-		// Add like in metrics
-		go s.mm.AddLike()
+		if len(expired) < purgeBatchSize {
+			break
+		}
+	}
 
-		return true, nil
+	if total > 0 {
+		slog.Info("deleted expired mitts", slog.Int("count", total))
+	}
+	return nil
+}
+
+// Likes
+
+// LikeMitt likes mitt, liking already liked mitt changes nothing
+func (s *Service) LikeMitt(ctx context.Context, userID uuid.UUID, mittID uuid.UUID) *models.HTTPError {
+	created, err := s.mr.LikeMitt(ctx, userID, mittID)
+	if err != nil {
+		if pgutil.IsForeignKeyViolation(err) {
+			return &models.HTTPError{
+				Code:    http.StatusNotFound,
+				Message: "Mitt not found",
+			}
+		}
+		slog.Error("error liking mitt", slog.Any("err", err))
+		return &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		}
+	}
+
+	if !created {
+		// Mitt is already liked or doesn't exist
+		_, httpErr := s.GetMitt(ctx, mittID)
+		return httpErr
 	}
 
-	if err := s.mr.DeleteMittLike(ctx, userID, mittID); err != nil {
+	s.notifyLike(ctx, userID, mittID)
+	s.publishLikes(ctx, mittID)
+
+	// Add like in metrics
+	go s.mm.AddLike()
+
+	return nil
+}
+
+// UnlikeMitt removes like from mitt, removing absent like changes nothing
+func (s *Service) UnlikeMitt(ctx context.Context, userID uuid.UUID, mittID uuid.UUID) *models.HTTPError {
+	removed, err := s.mr.DeleteMittLike(ctx, userID, mittID)
+	if err != nil {
 		slog.Error("error deleting mitt like", slog.Any("err", err))
-		return false, &models.HTTPError{
+		return &models.HTTPError{
 			Code:    http.StatusInternalServerError,
 			Message: "Internal server error",
 		}
 	}
 
+	if !removed {
+		// Nothing was liked, but mitt may not exist at all
+		_, httpErr := s.GetMitt(ctx, mittID)
+		return httpErr
+	}
+
+	s.publishLikes(ctx, mittID)
+
 	// Delete like in metrics
 	go s.mm.DeleteLike()
 
+	return nil
+}
+
+// SwitchLike likes mitt if it's not liked yet and removes like otherwise.
+// Returns new like state
+func (s *Service) SwitchLike(ctx context.Context, userID uuid.UUID, mittID uuid.UUID) (bool, *models.HTTPError) {
+	// Try to like first, so concurrent switches can't create duplicates
+	created, err := s.mr.LikeMitt(ctx, userID, mittID)
+	if err != nil {
+		if pgutil.IsForeignKeyViolation(err) {
+			return false, &models.HTTPError{
+				Code:    http.StatusNotFound,
+				Message: "Mitt not found",
+			}
+		}
+		slog.Error("error liking mitt", slog.Any("err", err))
+		return false, &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		}
+	}
+
+	if created {
+		s.notifyLike(ctx, userID, mittID)
+		s.publishLikes(ctx, mittID)
+
+		// Add like in metrics
+		go s.mm.AddLike()
+
+		return true, nil
+	}
+
+	// Mitt was already liked
+	if httpErr := s.UnlikeMitt(ctx, userID, mittID); httpErr != nil {
+		return false, httpErr
+	}
+
 	return false, nil
 }
 
@@ -264,23 +652,21 @@ func (s *Service) Feed(ctx context.Context, limit, offset int32) ([]*models.Mitt
 		}
 	}
 
-	for _, mitt := range mitts {
-		if err := s.setLikesCount(ctx, mitt); err != nil {
-			return nil, &models.HTTPError{
-				Code:    http.StatusInternalServerError,
-				Message: "Internal server error",
-			}
-		}
+	go s.mm.ViewInFeed(float64(len(mitts)))
 
-		if err := s.setAuthorName(ctx, mitt); err != nil {
-			return nil, &models.HTTPError{
-				Code:    http.StatusInternalServerError,
-				Message: "Internal server error",
-			}
+	return mitts, nil
+}
+
+// GetMentions returns mitts mentioning user, newest first
+func (s *Service) GetMentions(ctx context.Context, userID uuid.UUID, limit, offset int32) ([]*models.Mitt, *models.HTTPError) {
+	mitts, err := s.mr.GetMentionedMitts(ctx, userID, limit, offset)
+	if err != nil {
+		slog.Error("error getting mentions", slog.Any("err", err))
+		return nil, &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
 		}
 	}
 
-	go s.mm.ViewInFeed(float64(len(mitts)))
-
 	return mitts, nil
 }
@@ -5,6 +5,8 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/misshanya/mitter/internal/models"
 )
 
@@ -15,10 +17,18 @@ var mockMittModel = &models.Mitt{
 	CreatedAt: time.Now(),
 	UpdatedAt: time.Now(),
 	Likes:     0,
+	Version:   1,
 }
 
 var mockUserID = uuid.New()
 
+var mockPurgeCalls = 0
+
+var mockExpiredCalls = 0
+
+// Liking this mitt fails like it doesn't exist
+var mockMissingMittID = uuid.New()
+
 // Mock mitt repo
 type mockMittRepo struct{}
 
@@ -32,7 +42,10 @@ func (m mockMittRepo) CreateMitt(ctx context.Context, userID uuid.UUID, mitt *mo
 
 func (m mockMittRepo) GetMitt(ctx context.Context, id uuid.UUID) (*models.Mitt, error) {
 	_ = ctx
-	_ = id
+
+	if id == mockMissingMittID {
+		return nil, pgx.ErrNoRows
+	}
 
 	return mockMittModel, nil
 }
@@ -52,6 +65,8 @@ func (m mockMittRepo) UpdateMitt(ctx context.Context, mittID uuid.UUID, mitt *mo
 
 	// Update mock mitt content
 	mockMittModel.Content = mitt.Content
+	mockMittModel.Revisions++
+	mockMittModel.Version++
 
 	return mockMittModel, nil
 }
@@ -64,15 +79,114 @@ func (m mockMittRepo) DeleteMitt(ctx context.Context, mittID uuid.UUID) error {
 	return nil
 }
 
-func (m mockMittRepo) LikeMitt(ctx context.Context, userID uuid.UUID, mittID uuid.UUID) error {
+func (m mockMittRepo) RestoreMitt(ctx context.Context, userID uuid.UUID, mittID uuid.UUID, retention time.Duration) (bool, error) {
+	_ = ctx
+	_ = userID
+	_ = retention
+
+	// Only mock mitt is in trash
+	return mittID == mockMittModel.ID, nil
+}
+
+func (m mockMittRepo) GetDeletedUserMitts(ctx context.Context, userID uuid.UUID, limit, offset int32) ([]*models.Mitt, error) {
+	_ = ctx
+	_ = userID
+	_ = limit
+	_ = offset
+
+	deletedAt := time.Now()
+	return []*models.Mitt{{
+		ID:        uuid.New(),
+		AuthorID:  userID,
+		Content:   "deleted mitt",
+		DeletedAt: &deletedAt,
+	}}, nil
+}
+
+func (m mockMittRepo) PurgeDeletedMitts(ctx context.Context, retention time.Duration, batchSize int32) (int64, error) {
+	_ = ctx
+	_ = retention
+
+	// Two full batches and the last one
+	mockPurgeCalls++
+	if mockPurgeCalls < 3 {
+		return int64(batchSize), nil
+	}
+	return 1, nil
+}
+
+func (m mockMittRepo) DeleteExpiredMitts(ctx context.Context, batchSize int32) ([]*models.ExpiredMitt, error) {
+	_ = ctx
+
+	// One full batch with a mitt from trash and the last one
+	mockExpiredCalls++
+	if mockExpiredCalls > 1 {
+		return []*models.ExpiredMitt{{ID: uuid.New(), AuthorID: mockUserID, Content: "bye #status", Active: true}}, nil
+	}
+	expired := make([]*models.ExpiredMitt, batchSize)
+	for i := range expired {
+		expired[i] = &models.ExpiredMitt{ID: uuid.New(), AuthorID: mockUserID, Active: i > 0}
+	}
+	return expired, nil
+}
+
+func (m mockMittRepo) PinMitt(ctx context.Context, userID uuid.UUID, mittID uuid.UUID, max int) error {
 	_ = ctx
 	_ = userID
 	_ = mittID
 
+	// User has no pins yet
+	if max < 1 {
+		return models.ErrPinLimit
+	}
+	return nil
+}
+
+func (m mockMittRepo) UnpinMitt(ctx context.Context, userID uuid.UUID, mittID uuid.UUID) (bool, error) {
+	_ = ctx
+	_ = userID
+
+	// Only mock mitt is pinned
+	return mittID == mockMittModel.ID, nil
+}
+
+func (m mockMittRepo) GetMittRevisions(ctx context.Context, mittID uuid.UUID, limit, offset int32) ([]*models.MittRevision, error) {
+	_ = ctx
+	_ = limit
+	_ = offset
+
+	return []*models.MittRevision{{
+		ID:        uuid.New(),
+		MittID:    mittID,
+		Content:   "first version",
+		CreatedAt: mockMittModel.CreatedAt,
+	}}, nil
+}
+
+func (m mockMittRepo) GetMittsRevisions(ctx context.Context, mittIDs []uuid.UUID) (map[uuid.UUID][]*models.MittRevision, error) {
+	_ = ctx
+	_ = mittIDs
+
+	return nil, nil
+}
+
+func (m mockMittRepo) LikeMitt(ctx context.Context, userID uuid.UUID, mittID uuid.UUID) (bool, error) {
+	_ = ctx
+	_ = userID
+
+	if mittID == mockMissingMittID {
+		return false, &pgconn.PgError{Code: "23503"}
+	}
+
+	// Let's assume that if mock model has >=1 like, mitt is liked by user
+	if mockMittModel.Likes >= 1 {
+		return false, nil
+	}
+
 	// Add like to mock mitt
 	mockMittModel.Likes++
 
-	return nil
+	return true, nil
 }
 
 func (m mockMittRepo) IsMittLikedByUser(ctx context.Context, userID uuid.UUID, mittID uuid.UUID) (bool, error) {
@@ -84,22 +198,28 @@ func (m mockMittRepo) IsMittLikedByUser(ctx context.Context, userID uuid.UUID, m
 	return mockMittModel.Likes >= 1, nil
 }
 
-func (m mockMittRepo) DeleteMittLike(ctx context.Context, userID uuid.UUID, mittID uuid.UUID) error {
+func (m mockMittRepo) DeleteMittLike(ctx context.Context, userID uuid.UUID, mittID uuid.UUID) (bool, error) {
 	_ = ctx
 	_ = userID
 	_ = mittID
 
-	// Delete like (oops, it can be negative cause you can run tests in different order)
+	if mockMittModel.Likes < 1 {
+		return false, nil
+	}
+
+	// Delete like
 	mockMittModel.Likes--
 
-	return nil
+	return true, nil
 }
 
-func (m mockMittRepo) GetMittLikesCount(ctx context.Context, mittID uuid.UUID) (int64, error) {
+func (m mockMittRepo) GetUserLikes(ctx context.Context, userID uuid.UUID, limit, offset int32) ([]*models.MittLike, error) {
 	_ = ctx
-	_ = mittID
+	_ = userID
+	_ = limit
+	_ = offset
 
-	return mockMittModel.Likes, nil
+	return []*models.MittLike{}, nil
 }
 
 func (m mockMittRepo) Feed(ctx context.Context, limit, offset int32) ([]*models.Mitt, error) {
@@ -110,6 +230,19 @@ func (m mockMittRepo) Feed(ctx context.Context, limit, offset int32) ([]*models.
 	return []*models.Mitt{mockMittModel}, nil
 }
 
+func (m mockMittRepo) GetMentionedMitts(ctx context.Context, userID uuid.UUID, limit, offset int32) ([]*models.Mitt, error) {
+	_ = ctx
+	_ = limit
+	_ = offset
+
+	return []*models.Mitt{{
+		ID:       uuid.New(),
+		AuthorID: mockUserID,
+		Content:  "hi @mentioned",
+		Mentions: []*models.MittMention{{UserID: userID, Login: "mentioned", Start: 3, End: 13}},
+	}}, nil
+}
+
 // Mock User repo
 type mockUserRepo struct{}
 
@@ -134,6 +267,64 @@ func (r *mockUserRepo) GetUserByID(ctx context.Context, id uuid.UUID) (*models.U
 	return &models.User{ID: mockUserID}, nil
 }
 
+func (r *mockUserRepo) GetUsersByIDs(ctx context.Context, ids []uuid.UUID) ([]*models.User, error) {
+	_ = ctx
+	_ = ids
+
+	users := make([]*models.User, len(ids))
+	for i, id := range ids {
+		users[i] = &models.User{ID: id}
+	}
+	return users, nil
+}
+
+func (r *mockUserRepo) DeactivateUser(ctx context.Context, id uuid.UUID) (bool, error) {
+	_ = ctx
+	_ = id
+
+	return true, nil
+}
+
+func (r *mockUserRepo) ReactivateUser(ctx context.Context, id uuid.UUID, gracePeriod time.Duration) (bool, error) {
+	_ = ctx
+	_ = id
+	_ = gracePeriod
+
+	return true, nil
+}
+
+func (r *mockUserRepo) GetUsersToPurge(ctx context.Context, gracePeriod time.Duration, limit int32) ([]uuid.UUID, error) {
+	_ = ctx
+	_ = gracePeriod
+	_ = limit
+
+	return nil, nil
+}
+
+func (r *mockUserRepo) DeleteUserLikes(ctx context.Context, id uuid.UUID, batchSize int32) (int64, error) {
+	_ = ctx
+	_ = id
+	_ = batchSize
+
+	return 0, nil
+}
+
+func (r *mockUserRepo) DeleteUserFollows(ctx context.Context, id uuid.UUID, batchSize int32) (int64, error) {
+	_ = ctx
+	_ = id
+	_ = batchSize
+
+	return 0, nil
+}
+
+func (r *mockUserRepo) DeleteUserMitts(ctx context.Context, id uuid.UUID, batchSize int32) (int64, error) {
+	_ = ctx
+	_ = id
+	_ = batchSize
+
+	return 0, nil
+}
+
 func (r *mockUserRepo) DeleteUser(ctx context.Context, id uuid.UUID) error {
 	_ = ctx
 	_ = id
@@ -207,11 +398,117 @@ func (r *mockUserRepo) GetUserFriends(ctx context.Context, userID uuid.UUID, lim
 	return []uuid.UUID{mockUserID}, nil
 }
 
+func (r *mockUserRepo) ListUserIDs(ctx context.Context, after uuid.UUID, limit int32) ([]uuid.UUID, error) {
+	_ = ctx
+	_ = after
+	_ = limit
+
+	return []uuid.UUID{}, nil
+}
+
+func (r *mockUserRepo) BlockUser(ctx context.Context, blockerID uuid.UUID, blockedID uuid.UUID) error {
+	_ = ctx
+	_ = blockerID
+	_ = blockedID
+
+	return nil
+}
+
+func (r *mockUserRepo) UnblockUser(ctx context.Context, blockerID uuid.UUID, blockedID uuid.UUID) error {
+	_ = ctx
+	_ = blockerID
+	_ = blockedID
+
+	return nil
+}
+
+func (r *mockUserRepo) IsBlockedBetween(ctx context.Context, firstID uuid.UUID, secondID uuid.UUID) (bool, error) {
+	_ = ctx
+	_ = firstID
+	_ = secondID
+
+	return false, nil
+}
+
+func (r *mockUserRepo) MuteUser(ctx context.Context, muterID uuid.UUID, mutedID uuid.UUID) error {
+	_ = ctx
+	_ = muterID
+	_ = mutedID
+
+	return nil
+}
+
+func (r *mockUserRepo) UnmuteUser(ctx context.Context, muterID uuid.UUID, mutedID uuid.UUID) error {
+	_ = ctx
+	_ = muterID
+	_ = mutedID
+
+	return nil
+}
+
+func (r *mockUserRepo) GetHiddenUserIDs(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	_ = ctx
+	_ = userID
+
+	return nil, nil
+}
+
+func (r *mockUserRepo) SuggestFollows(ctx context.Context, userID uuid.UUID, limit int32) ([]uuid.UUID, error) {
+	_ = ctx
+	_ = userID
+	_ = limit
+
+	return []uuid.UUID{}, nil
+}
+
+func (r *mockUserRepo) GetPopularUsers(ctx context.Context, userID uuid.UUID, limit int32) ([]uuid.UUID, error) {
+	_ = ctx
+	_ = userID
+	_ = limit
+
+	return []uuid.UUID{}, nil
+}
+
+func (r *mockUserRepo) FilterSuggestable(ctx context.Context, userID uuid.UUID, candidateIDs []uuid.UUID) ([]uuid.UUID, error) {
+	_ = ctx
+	_ = userID
+	_ = candidateIDs
+
+	return candidateIDs, nil
+}
+
+func (r *mockUserRepo) IsFollowing(ctx context.Context, followerID uuid.UUID, followeeID uuid.UUID) (bool, error) {
+	_ = ctx
+	_ = followerID
+	_ = followeeID
+
+	return false, nil
+}
+
+func (r *mockUserRepo) GetKnownFollowers(ctx context.Context, viewerID uuid.UUID, userID uuid.UUID, limit, offset int32) ([]uuid.UUID, error) {
+	_ = ctx
+	_ = viewerID
+	_ = userID
+	_ = limit
+	_ = offset
+
+	return []uuid.UUID{}, nil
+}
+
+func (r *mockUserRepo) GetRelationships(ctx context.Context, userID uuid.UUID, ids []uuid.UUID) ([]*models.Relationship, error) {
+	_ = ctx
+	_ = userID
+	_ = ids
+
+	return []*models.Relationship{}, nil
+}
+
 // Mock metrics
 type mockMittMetrics struct {
 	FakeTotalMitts   int
 	FakeTotalLikes   int
 	FakeViewedInFeed float64
+	FakeHashtags     int
 }
 
 func (m *mockMittMetrics) AddMitt() {
@@ -233,3 +530,207 @@ func (m *mockMittMetrics) DeleteLike() {
 func (m *mockMittMetrics) ViewInFeed(count float64) {
 	m.FakeViewedInFeed += count
 }
+
+func (m *mockMittMetrics) ExtractHashtags(count int, took time.Duration) {
+	_ = took
+
+	m.FakeHashtags += count
+}
+
+// Mock trending repo
+type mockTrendingRepo struct {
+	uses map[string]int64
+}
+
+func (r *mockTrendingRepo) AddHashtagUses(ctx context.Context, tags []string, at time.Time, delta int64) error {
+	_ = ctx
+	_ = at
+
+	if r.uses == nil {
+		r.uses = make(map[string]int64)
+	}
+	for _, tag := range tags {
+		r.uses[tag] += delta
+	}
+	return nil
+}
+
+func (r *mockTrendingRepo) RefreshTrendingHashtags(ctx context.Context, now time.Time) error {
+	_ = ctx
+	_ = now
+
+	return nil
+}
+
+func (r *mockTrendingRepo) GetTrendingHashtags(ctx context.Context, limit int32) ([]*models.TrendingHashtag, error) {
+	_ = ctx
+	_ = limit
+
+	return []*models.TrendingHashtag{}, nil
+}
+
+// Mock notifier
+type mockNotifier struct {
+	events []*models.NotificationEvent
+}
+
+func (n *mockNotifier) Notify(ctx context.Context, event *models.NotificationEvent) {
+	_ = ctx
+
+	n.events = append(n.events, event)
+}
+
+// Mock event publisher
+type mockEventPublisher struct {
+	mitts     []*models.Mitt
+	likes     []int64
+	followers []*models.User
+}
+
+func (p *mockEventPublisher) PublishMitt(ctx context.Context, mitt *models.Mitt) {
+	_ = ctx
+
+	p.mitts = append(p.mitts, mitt)
+}
+
+func (p *mockEventPublisher) PublishLikes(ctx context.Context, mittID uuid.UUID, authorID uuid.UUID, likes int64) {
+	_ = ctx
+	_ = mittID
+	_ = authorID
+
+	p.likes = append(p.likes, likes)
+}
+
+func (p *mockEventPublisher) PublishFollower(ctx context.Context, followeeID uuid.UUID, follower *models.User) {
+	_ = ctx
+	_ = followeeID
+
+	p.followers = append(p.followers, follower)
+}
+
+// Mock draft repo (in-memory)
+type mockDraftRepo struct {
+	drafts map[uuid.UUID]*models.Draft
+	// Drafts held by scheduler
+	claimed map[uuid.UUID]bool
+}
+
+func newMockDraftRepo() *mockDraftRepo {
+	return &mockDraftRepo{
+		drafts:  map[uuid.UUID]*models.Draft{},
+		claimed: map[uuid.UUID]bool{},
+	}
+}
+
+func (r *mockDraftRepo) CreateDraft(ctx context.Context, userID uuid.UUID, draft *models.DraftCreate) (*models.Draft, error) {
+	_ = ctx
+
+	created := &models.Draft{
+		ID:        uuid.New(),
+		AuthorID:  userID,
+		Content:   draft.Content,
+		PublishAt: draft.PublishAt,
+		Version:   1,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	r.drafts[created.ID] = created
+	copied := *created
+	return &copied, nil
+}
+
+func (r *mockDraftRepo) GetDraft(ctx context.Context, userID uuid.UUID, id uuid.UUID) (*models.Draft, error) {
+	_ = ctx
+
+	draft, ok := r.drafts[id]
+	if !ok || draft.AuthorID != userID {
+		return nil, pgx.ErrNoRows
+	}
+	copied := *draft
+	return &copied, nil
+}
+
+func (r *mockDraftRepo) GetUserDrafts(ctx context.Context, userID uuid.UUID, scheduled *bool, limit, offset int32) ([]*models.Draft, error) {
+	_ = ctx
+	_ = limit
+	_ = offset
+
+	var drafts []*models.Draft
+	for _, d := range r.drafts {
+		if d.AuthorID != userID || (scheduled != nil && *scheduled != (d.PublishAt != nil)) {
+			continue
+		}
+		drafts = append(drafts, d)
+	}
+	return drafts, nil
+}
+
+func (r *mockDraftRepo) UpdateDraft(ctx context.Context, userID uuid.UUID, id uuid.UUID, draft *models.DraftUpdate) (*models.Draft, error) {
+	_ = ctx
+
+	existing, ok := r.drafts[id]
+	if !ok || existing.AuthorID != userID {
+		return nil, pgx.ErrNoRows
+	}
+	if draft.ExpectedVersion != nil && *draft.ExpectedVersion != existing.Version {
+		return nil, models.ErrVersionConflict
+	}
+
+	existing.Content = draft.Content
+	existing.PublishAt = draft.PublishAt
+	existing.Version++
+	existing.UpdatedAt = time.Now()
+	delete(r.claimed, id)
+
+	copied := *existing
+	return &copied, nil
+}
+
+func (r *mockDraftRepo) DeleteDraft(ctx context.Context, userID uuid.UUID, id uuid.UUID) (bool, error) {
+	_ = ctx
+
+	draft, ok := r.drafts[id]
+	if !ok || draft.AuthorID != userID {
+		return false, nil
+	}
+	delete(r.drafts, id)
+	return true, nil
+}
+
+func (r *mockDraftRepo) ClaimScheduled(ctx context.Context, lease time.Duration, batchSize int32) ([]*models.Draft, error) {
+	_ = ctx
+	_ = lease
+
+	var drafts []*models.Draft
+	for id, d := range r.drafts {
+		if int32(len(drafts)) == batchSize {
+			break
+		}
+		if d.PublishAt == nil || d.PublishAt.After(time.Now()) || r.claimed[id] {
+			continue
+		}
+		r.claimed[id] = true
+		copied := *d
+		drafts = append(drafts, &copied)
+	}
+	return drafts, nil
+}
+
+func (r *mockDraftRepo) PublishDraft(ctx context.Context, draft *models.Draft, mitt *models.MittCreate) (*models.Mitt, error) {
+	_ = ctx
+
+	existing, ok := r.drafts[draft.ID]
+	if !ok || existing.Version != draft.Version {
+		return nil, nil
+	}
+	delete(r.drafts, draft.ID)
+
+	return &models.Mitt{
+		ID:        uuid.New(),
+		AuthorID:  draft.AuthorID,
+		Content:   mitt.Content,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		Version:   1,
+	}, nil
+}
@@ -2,13 +2,18 @@ package mitt
 
 import (
 	"context"
+	"github.com/google/uuid"
 	"github.com/misshanya/mitter/internal/models"
+	"net/http"
+	"slices"
 	"testing"
+	"time"
 )
 
 // Tests
 func TestMittService_CreateMitt(t *testing.T) {
-	service := NewService(mockMittRepo{}, &mockMittMetrics{}, &mockUserRepo{})
+	ep := &mockEventPublisher{}
+	service := NewService(mockMittRepo{}, newMockDraftRepo(), &mockMittMetrics{}, &mockUserRepo{}, &mockTrendingRepo{}, &mockNotifier{}, ep, Options{TrashRetention: time.Hour, MaxPollDuration: 24 * time.Hour, MaxPinned: 3})
 	ctx := context.Background()
 
 	mitt, err := service.CreateMitt(ctx, mockUserID, &models.MittCreate{
@@ -21,10 +26,105 @@ func TestMittService_CreateMitt(t *testing.T) {
 	if mitt != mockMittModel {
 		t.Fatal("mitt does not match")
 	}
+
+	// New mitt is streamed
+	if len(ep.mitts) != 1 || ep.mitts[0] != mockMittModel {
+		t.Fatalf("expected new mitt to be published, got %v", ep.mitts)
+	}
+}
+
+func TestMittService_CreateMittExpiry(t *testing.T) {
+	service := NewService(mockMittRepo{}, newMockDraftRepo(), &mockMittMetrics{}, &mockUserRepo{}, &mockTrendingRepo{}, &mockNotifier{}, &mockEventPublisher{}, Options{TrashRetention: time.Hour, MaxPollDuration: 24 * time.Hour, MaxPinned: 3})
+	ctx := context.Background()
+
+	expiresAt := time.Now().Add(24 * time.Hour)
+	if _, err := service.CreateMitt(ctx, mockUserID, &models.MittCreate{Content: "today only", ExpiresAt: &expiresAt}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Too soon
+	expiresAt = time.Now().Add(time.Minute)
+	if _, err := service.CreateMitt(ctx, mockUserID, &models.MittCreate{Content: "today only", ExpiresAt: &expiresAt}); err == nil || err.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %v", err)
+	}
+}
+
+func TestMittService_CreateMittPoll(t *testing.T) {
+	service := NewService(mockMittRepo{}, newMockDraftRepo(), &mockMittMetrics{}, &mockUserRepo{}, &mockTrendingRepo{}, &mockNotifier{}, &mockEventPublisher{}, Options{TrashRetention: time.Hour, MaxPollDuration: 24 * time.Hour, MaxPinned: 3})
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		poll    *models.PollCreate
+		code    int
+		options []string
+	}{
+		{
+			name:    "valid",
+			poll:    &models.PollCreate{Options: []string{" yes ", "no"}, ExpiresAt: time.Now().Add(time.Hour)},
+			options: []string{"yes", "no"},
+		},
+		{
+			name: "one option",
+			poll: &models.PollCreate{Options: []string{"yes"}, ExpiresAt: time.Now().Add(time.Hour)},
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "too many options",
+			poll: &models.PollCreate{Options: []string{"a", "b", "c", "d", "e"}, ExpiresAt: time.Now().Add(time.Hour)},
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "blank option",
+			poll: &models.PollCreate{Options: []string{"yes", "  "}, ExpiresAt: time.Now().Add(time.Hour)},
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "too long option",
+			poll: &models.PollCreate{Options: []string{"yes", "this option is way too long"}, ExpiresAt: time.Now().Add(time.Hour)},
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "duplicate options",
+			poll: &models.PollCreate{Options: []string{"yes", "yes "}, ExpiresAt: time.Now().Add(time.Hour)},
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "expires too soon",
+			poll: &models.PollCreate{Options: []string{"yes", "no"}, ExpiresAt: time.Now().Add(time.Minute)},
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "expires too late",
+			poll: &models.PollCreate{Options: []string{"yes", "no"}, ExpiresAt: time.Now().Add(25 * time.Hour)},
+			code: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.CreateMitt(ctx, mockUserID, &models.MittCreate{
+				Content: mockMittModel.Content,
+				Poll:    tt.poll,
+			})
+			if tt.code == 0 {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !slices.Equal(tt.poll.Options, tt.options) {
+					t.Fatalf("expected normalized options %v, got %v", tt.options, tt.poll.Options)
+				}
+				return
+			}
+			if err == nil || err.Code != tt.code {
+				t.Fatalf("expected %d, got %v", tt.code, err)
+			}
+		})
+	}
 }
 
 func TestMittService_GetMitt(t *testing.T) {
-	service := NewService(mockMittRepo{}, &mockMittMetrics{}, &mockUserRepo{})
+	service := NewService(mockMittRepo{}, newMockDraftRepo(), &mockMittMetrics{}, &mockUserRepo{}, &mockTrendingRepo{}, &mockNotifier{}, &mockEventPublisher{}, Options{TrashRetention: time.Hour, MaxPollDuration: 24 * time.Hour, MaxPinned: 3})
 	ctx := context.Background()
 
 	mitt, err := service.GetMitt(ctx, mockMittModel.ID)
@@ -38,7 +138,7 @@ func TestMittService_GetMitt(t *testing.T) {
 }
 
 func TestMittService_GetAllUserMitts(t *testing.T) {
-	service := NewService(mockMittRepo{}, &mockMittMetrics{}, &mockUserRepo{})
+	service := NewService(mockMittRepo{}, newMockDraftRepo(), &mockMittMetrics{}, &mockUserRepo{}, &mockTrendingRepo{}, &mockNotifier{}, &mockEventPublisher{}, Options{TrashRetention: time.Hour, MaxPollDuration: 24 * time.Hour, MaxPinned: 3})
 	ctx := context.Background()
 
 	mitts, err := service.GetAllUserMitts(ctx, mockUserID, 1, 0)
@@ -53,7 +153,7 @@ func TestMittService_GetAllUserMitts(t *testing.T) {
 }
 
 func TestMittService_UpdateMitt(t *testing.T) {
-	service := NewService(mockMittRepo{}, &mockMittMetrics{}, &mockUserRepo{})
+	service := NewService(mockMittRepo{}, newMockDraftRepo(), &mockMittMetrics{}, &mockUserRepo{}, &mockTrendingRepo{}, &mockNotifier{}, &mockEventPublisher{}, Options{TrashRetention: time.Hour, MaxPollDuration: 24 * time.Hour, MaxPinned: 3})
 	ctx := context.Background()
 
 	mitt, err := service.UpdateMitt(ctx, mockUserID, mockMittModel.ID, &models.MittUpdate{
@@ -68,8 +168,65 @@ func TestMittService_UpdateMitt(t *testing.T) {
 	}
 }
 
+func TestMittService_UpdateMittVersion(t *testing.T) {
+	service := NewService(mockMittRepo{}, newMockDraftRepo(), &mockMittMetrics{}, &mockUserRepo{}, &mockTrendingRepo{}, &mockNotifier{}, &mockEventPublisher{}, Options{TrashRetention: time.Hour, MaxPollDuration: 24 * time.Hour, MaxPinned: 3})
+	ctx := context.Background()
+
+	version := mockMittModel.Version
+	mitt, err := service.UpdateMitt(ctx, mockUserID, mockMittModel.ID, &models.MittUpdate{
+		Content:         "updated with version",
+		ExpectedVersion: &version,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mitt.Version != version+1 {
+		t.Fatal("version should be incremented")
+	}
+
+	// Stale version
+	_, err = service.UpdateMitt(ctx, mockUserID, mockMittModel.ID, &models.MittUpdate{
+		Content:         "updated with stale version",
+		ExpectedVersion: &version,
+	})
+	if err == nil || err.Code != http.StatusPreconditionFailed {
+		t.Fatalf("expected 412, got %v", err)
+	}
+}
+
+func TestMittService_UpdateMittEditWindow(t *testing.T) {
+	// Mock mitt was created before the test started, so the window has already passed
+	service := NewService(mockMittRepo{}, newMockDraftRepo(), &mockMittMetrics{}, &mockUserRepo{}, &mockTrendingRepo{}, &mockNotifier{}, &mockEventPublisher{}, Options{EditWindow: time.Nanosecond, TrashRetention: time.Hour, MaxPollDuration: 24 * time.Hour, MaxPinned: 3})
+	ctx := context.Background()
+
+	_, err := service.UpdateMitt(ctx, mockUserID, mockMittModel.ID, &models.MittUpdate{
+		Content: "too late",
+	})
+	if err == nil || err.Code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %v", err)
+	}
+}
+
+func TestMittService_GetMittHistory(t *testing.T) {
+	service := NewService(mockMittRepo{}, newMockDraftRepo(), &mockMittMetrics{}, &mockUserRepo{}, &mockTrendingRepo{}, &mockNotifier{}, &mockEventPublisher{}, Options{TrashRetention: time.Hour, MaxPollDuration: 24 * time.Hour, MaxPinned: 3})
+	ctx := context.Background()
+
+	revisions, err := service.GetMittHistory(ctx, mockMittModel.ID, 30, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(revisions) != 1 || revisions[0].MittID != mockMittModel.ID {
+		t.Fatal("revisions do not match")
+	}
+
+	// Nonexistent mitt
+	if _, err := service.GetMittHistory(ctx, mockMissingMittID, 30, 0); err == nil || err.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %v", err)
+	}
+}
+
 func TestMittService_DeleteMitt(t *testing.T) {
-	service := NewService(mockMittRepo{}, &mockMittMetrics{}, &mockUserRepo{})
+	service := NewService(mockMittRepo{}, newMockDraftRepo(), &mockMittMetrics{}, &mockUserRepo{}, &mockTrendingRepo{}, &mockNotifier{}, &mockEventPublisher{}, Options{TrashRetention: time.Hour, MaxPollDuration: 24 * time.Hour, MaxPinned: 3})
 	ctx := context.Background()
 
 	err := service.DeleteMitt(ctx, mockUserID, mockMittModel.ID)
@@ -78,8 +235,110 @@ func TestMittService_DeleteMitt(t *testing.T) {
 	}
 }
 
+func TestMittService_DeleteExpired(t *testing.T) {
+	tr := &mockTrendingRepo{}
+	service := NewService(mockMittRepo{}, newMockDraftRepo(), &mockMittMetrics{}, &mockUserRepo{}, tr, &mockNotifier{}, &mockEventPublisher{}, Options{TrashRetention: time.Hour, MaxPollDuration: 24 * time.Hour, MaxPinned: 3})
+	ctx := context.Background()
+
+	mockExpiredCalls = 0
+	if err := service.DeleteExpired(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// Deleting goes on until a batch is not full
+	if mockExpiredCalls != 2 {
+		t.Fatalf("expected 2 batches, got %d", mockExpiredCalls)
+	}
+
+	// Hashtags of deleted mitts don't count in trends anymore
+	if tr.uses["status"] != -1 {
+		t.Fatalf("expected hashtag use to be removed, got %d", tr.uses["status"])
+	}
+}
+
+func TestMittService_PinMitt(t *testing.T) {
+	service := NewService(mockMittRepo{}, newMockDraftRepo(), &mockMittMetrics{}, &mockUserRepo{}, &mockTrendingRepo{}, &mockNotifier{}, &mockEventPublisher{}, Options{TrashRetention: time.Hour, MaxPollDuration: 24 * time.Hour, MaxPinned: 3})
+	ctx := context.Background()
+
+	if err := service.PinMitt(ctx, mockUserID, mockMittModel.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// Mitt of someone else
+	if err := service.PinMitt(ctx, uuid.New(), mockMittModel.ID); err == nil || err.Code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %v", err)
+	}
+
+	// Nonexistent mitt
+	if err := service.PinMitt(ctx, mockUserID, mockMissingMittID); err == nil || err.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %v", err)
+	}
+
+	// Limit is reached
+	service = NewService(mockMittRepo{}, newMockDraftRepo(), &mockMittMetrics{}, &mockUserRepo{}, &mockTrendingRepo{}, &mockNotifier{}, &mockEventPublisher{}, Options{TrashRetention: time.Hour, MaxPollDuration: 24 * time.Hour})
+	if err := service.PinMitt(ctx, mockUserID, mockMittModel.ID); err == nil || err.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %v", err)
+	}
+}
+
+func TestMittService_UnpinMitt(t *testing.T) {
+	service := NewService(mockMittRepo{}, newMockDraftRepo(), &mockMittMetrics{}, &mockUserRepo{}, &mockTrendingRepo{}, &mockNotifier{}, &mockEventPublisher{}, Options{TrashRetention: time.Hour, MaxPollDuration: 24 * time.Hour, MaxPinned: 3})
+	ctx := context.Background()
+
+	if err := service.UnpinMitt(ctx, mockUserID, mockMittModel.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// Not pinned
+	if err := service.UnpinMitt(ctx, mockUserID, uuid.New()); err == nil || err.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %v", err)
+	}
+}
+
+func TestMittService_RestoreMitt(t *testing.T) {
+	service := NewService(mockMittRepo{}, newMockDraftRepo(), &mockMittMetrics{}, &mockUserRepo{}, &mockTrendingRepo{}, &mockNotifier{}, &mockEventPublisher{}, Options{TrashRetention: time.Hour, MaxPollDuration: 24 * time.Hour, MaxPinned: 3})
+	ctx := context.Background()
+
+	if err := service.RestoreMitt(ctx, mockUserID, mockMittModel.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// Not in trash
+	if err := service.RestoreMitt(ctx, mockUserID, uuid.New()); err == nil || err.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %v", err)
+	}
+}
+
+func TestMittService_GetTrash(t *testing.T) {
+	service := NewService(mockMittRepo{}, newMockDraftRepo(), &mockMittMetrics{}, &mockUserRepo{}, &mockTrendingRepo{}, &mockNotifier{}, &mockEventPublisher{}, Options{TrashRetention: time.Hour, MaxPollDuration: 24 * time.Hour, MaxPinned: 3})
+	ctx := context.Background()
+
+	mitts, err := service.GetTrash(ctx, mockUserID, 30, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mitts) != 1 || mitts[0].DeletedAt == nil {
+		t.Fatal("trash should contain deleted mitt")
+	}
+}
+
+func TestMittService_PurgeTrash(t *testing.T) {
+	service := NewService(mockMittRepo{}, newMockDraftRepo(), &mockMittMetrics{}, &mockUserRepo{}, &mockTrendingRepo{}, &mockNotifier{}, &mockEventPublisher{}, Options{TrashRetention: time.Hour, MaxPollDuration: 24 * time.Hour, MaxPinned: 3})
+	ctx := context.Background()
+
+	mockPurgeCalls = 0
+	if err := service.PurgeTrash(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// Purging goes on until a batch is not full
+	if mockPurgeCalls != 3 {
+		t.Fatalf("expected 3 batches, got %d", mockPurgeCalls)
+	}
+}
+
 func TestMittService_SwitchLike(t *testing.T) {
-	service := NewService(mockMittRepo{}, &mockMittMetrics{}, &mockUserRepo{})
+	service := NewService(mockMittRepo{}, newMockDraftRepo(), &mockMittMetrics{}, &mockUserRepo{}, &mockTrendingRepo{}, &mockNotifier{}, &mockEventPublisher{}, Options{TrashRetention: time.Hour, MaxPollDuration: 24 * time.Hour, MaxPinned: 3})
 	ctx := context.Background()
 
 	// Like mitt
@@ -102,3 +361,102 @@ func TestMittService_SwitchLike(t *testing.T) {
 		t.Fatal("liked state should be false")
 	}
 }
+
+func TestMittService_LikeMitt(t *testing.T) {
+	nt := &mockNotifier{}
+	ep := &mockEventPublisher{}
+	service := NewService(mockMittRepo{}, newMockDraftRepo(), &mockMittMetrics{}, &mockUserRepo{}, &mockTrendingRepo{}, nt, ep, Options{TrashRetention: time.Hour, MaxPollDuration: 24 * time.Hour, MaxPinned: 3})
+	ctx := context.Background()
+
+	// Liking twice keeps one like
+	for range 2 {
+		if err := service.LikeMitt(ctx, mockUserID, mockMittModel.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if mockMittModel.Likes != 1 {
+		t.Fatalf("likes should be 1, got %d", mockMittModel.Likes)
+	}
+
+	// Author is notified once
+	if len(nt.events) != 1 || nt.events[0].Type != models.NotificationLike || *nt.events[0].MittID != mockMittModel.ID {
+		t.Fatalf("expected one like notification, got %v", nt.events)
+	}
+
+	// Unliking twice removes it once
+	for range 2 {
+		if err := service.UnlikeMitt(ctx, mockUserID, mockMittModel.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if mockMittModel.Likes != 0 {
+		t.Fatalf("likes should be 0, got %d", mockMittModel.Likes)
+	}
+
+	// Only changes of likes count are streamed
+	if len(ep.likes) != 2 || ep.likes[0] != 1 || ep.likes[1] != 0 {
+		t.Fatalf("expected likes counts 1 and 0 to be published, got %v", ep.likes)
+	}
+
+	// Nonexistent mitt
+	if err := service.LikeMitt(ctx, mockUserID, mockMissingMittID); err == nil || err.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %v", err)
+	}
+	if err := service.UnlikeMitt(ctx, mockUserID, mockMissingMittID); err == nil || err.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %v", err)
+	}
+}
+
+func TestMittService_HashtagTrends(t *testing.T) {
+	tr := &mockTrendingRepo{}
+	service := NewService(mockMittRepo{}, newMockDraftRepo(), &mockMittMetrics{}, &mockUserRepo{}, tr, &mockNotifier{}, &mockEventPublisher{}, Options{TrashRetention: time.Hour, MaxPollDuration: 24 * time.Hour, MaxPinned: 3})
+	ctx := context.Background()
+
+	create := &models.MittCreate{Content: "hello #Go"}
+	if _, err := service.CreateMitt(ctx, mockUserID, create); err != nil {
+		t.Fatal(err)
+	}
+	if len(create.Hashtags) != 1 || create.Hashtags[0] != "go" {
+		t.Fatalf("hashtags should be [go], got %v", create.Hashtags)
+	}
+
+	// Only changed hashtags are counted
+	mockMittModel.Content = "#go #old"
+	if _, err := service.UpdateMitt(ctx, mockUserID, mockMittModel.ID, &models.MittUpdate{Content: "#go #new"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Deleted mitt doesn't count anymore
+	if err := service.DeleteMitt(ctx, mockUserID, mockMittModel.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]int64{"go": 0, "old": -1, "new": 0}
+	for tag, uses := range expected {
+		if tr.uses[tag] != uses {
+			t.Fatalf("uses of %s should be %d, got %d", tag, uses, tr.uses[tag])
+		}
+	}
+}
+
+func TestMittService_Mentions(t *testing.T) {
+	service := NewService(mockMittRepo{}, newMockDraftRepo(), &mockMittMetrics{}, &mockUserRepo{}, &mockTrendingRepo{}, &mockNotifier{}, &mockEventPublisher{}, Options{TrashRetention: time.Hour, MaxPollDuration: 24 * time.Hour, MaxPinned: 3})
+	ctx := context.Background()
+
+	create := &models.MittCreate{Content: "hi @alice and @bob, @alice"}
+	if _, err := service.CreateMitt(ctx, mockUserID, create); err != nil {
+		t.Fatal(err)
+	}
+	if len(create.Mentions) != 2 || create.Mentions[0] != "alice" || create.Mentions[1] != "bob" {
+		t.Fatalf("mentions should be [alice bob], got %v", create.Mentions)
+	}
+
+	mentionedID := uuid.New()
+	mitts, err := service.GetMentions(ctx, mentionedID, 30, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mitts) != 1 || mitts[0].Mentions[0].UserID != mentionedID {
+		t.Fatal("mitt should mention user")
+	}
+}
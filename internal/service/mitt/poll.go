@@ -0,0 +1,62 @@
+package mitt
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/misshanya/mitter/internal/models"
+	"github.com/misshanya/mitter/pkg/content"
+)
+
+const (
+	minPollOptions      = 2
+	maxPollOptions      = 4
+	maxPollOptionLength = 25
+	// Poll must stay open at least this long after creation
+	minPollDuration = 5 * time.Minute
+)
+
+// validatePoll normalizes options of poll and checks them and its expiry
+func (s *Service) validatePoll(poll *models.PollCreate, now time.Time) *models.HTTPError {
+	if len(poll.Options) < minPollOptions || len(poll.Options) > maxPollOptions {
+		return &models.HTTPError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Poll must have from %d to %d options", minPollOptions, maxPollOptions),
+		}
+	}
+
+	seen := make(map[string]struct{}, len(poll.Options))
+	for i, option := range poll.Options {
+		option = content.Normalize(option)
+		if err := content.Check(option, maxPollOptionLength); err != nil {
+			return &models.HTTPError{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("Poll option %d %s", i+1, err),
+			}
+		}
+		if _, ok := seen[option]; ok {
+			return &models.HTTPError{
+				Code:    http.StatusBadRequest,
+				Message: "Poll options must be different",
+			}
+		}
+		seen[option] = struct{}{}
+		poll.Options[i] = option
+	}
+
+	if poll.ExpiresAt.Before(now.Add(minPollDuration)) {
+		return &models.HTTPError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Poll must be open at least %s", minPollDuration),
+		}
+	}
+	if poll.ExpiresAt.After(now.Add(s.maxPollDuration)) {
+		return &models.HTTPError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Poll can be open at most %s", s.maxPollDuration),
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,145 @@
+package notification
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/misshanya/mitter/internal/dataloader"
+	"github.com/misshanya/mitter/internal/models"
+)
+
+// How many latest actors are shown in a group, the rest are only counted
+const groupActorsLimit = 3
+
+type Service struct {
+	nr models.NotificationRepository
+	ur models.UserRepository
+}
+
+func NewService(nr models.NotificationRepository, ur models.UserRepository) *Service {
+	return &Service{
+		nr: nr,
+		ur: ur,
+	}
+}
+
+// Notify records notification, recipient's blocks, mutes and preferences are respected by repository
+func (s *Service) Notify(ctx context.Context, event *models.NotificationEvent) {
+	if _, err := s.nr.CreateNotification(ctx, event); err != nil {
+		slog.Error("error creating notification",
+			slog.String("type", string(event.Type)),
+			slog.Any("err", err),
+		)
+	}
+}
+
+// GetNotifications returns notifications grouped by type, mitt and day, latest first
+func (s *Service) GetNotifications(ctx context.Context, userID uuid.UUID, limit, offset int32) ([]*models.NotificationGroup, *models.HTTPError) {
+	groups, err := s.nr.GetNotificationGroups(ctx, userID, groupActorsLimit, limit, offset)
+	if err != nil {
+		slog.Error("error getting notifications", slog.Any("err", err))
+		return nil, &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		}
+	}
+
+	// Load actors of all groups with one query
+	ids := make([]uuid.UUID, 0, len(groups)*groupActorsLimit)
+	for _, g := range groups {
+		ids = append(ids, g.ActorIDs...)
+	}
+	loader := dataloader.Users(ctx, s.ur)
+	if _, err := loader.LoadMany(ctx, ids); err != nil {
+		slog.Error("error getting notifications (getting actors from db)", slog.Any("err", err))
+		return nil, &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		}
+	}
+	for _, g := range groups {
+		// Already loaded, no queries here
+		g.Actors, _ = loader.LoadMany(ctx, g.ActorIDs)
+	}
+
+	return groups, nil
+}
+
+func (s *Service) GetUnreadCount(ctx context.Context, userID uuid.UUID) (int64, *models.HTTPError) {
+	count, err := s.nr.GetUnreadCount(ctx, userID)
+	if err != nil {
+		slog.Error("error getting unread notifications count", slog.Any("err", err))
+		return 0, &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		}
+	}
+	return count, nil
+}
+
+// MarkRead marks notification read with the rest of its group
+func (s *Service) MarkRead(ctx context.Context, userID uuid.UUID, id uuid.UUID) *models.HTTPError {
+	found, err := s.nr.MarkGroupRead(ctx, userID, id)
+	if err != nil {
+		slog.Error("error marking notification read", slog.Any("err", err))
+		return &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		}
+	}
+	if !found {
+		return &models.HTTPError{
+			Code:    http.StatusNotFound,
+			Message: "Notification not found",
+		}
+	}
+	return nil
+}
+
+func (s *Service) MarkAllRead(ctx context.Context, userID uuid.UUID) *models.HTTPError {
+	if err := s.nr.MarkAllRead(ctx, userID); err != nil {
+		slog.Error("error marking all notifications read", slog.Any("err", err))
+		return &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		}
+	}
+	return nil
+}
+
+// GetPreferences returns preferences for every type, enabled unless user disabled it
+func (s *Service) GetPreferences(ctx context.Context, userID uuid.UUID) (models.NotificationPreferences, *models.HTTPError) {
+	saved, err := s.nr.GetPreferences(ctx, userID)
+	if err != nil {
+		slog.Error("error getting notification preferences", slog.Any("err", err))
+		return nil, &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		}
+	}
+
+	prefs := make(models.NotificationPreferences, len(models.NotificationTypes))
+	for _, t := range models.NotificationTypes {
+		enabled, ok := saved[t]
+		prefs[t] = !ok || enabled
+	}
+
+	return prefs, nil
+}
+
+// UpdatePreferences saves given types only and returns resulting preferences
+func (s *Service) UpdatePreferences(ctx context.Context, userID uuid.UUID, prefs models.NotificationPreferences) (models.NotificationPreferences, *models.HTTPError) {
+	if len(prefs) > 0 {
+		if err := s.nr.SetPreferences(ctx, userID, prefs); err != nil {
+			slog.Error("error updating notification preferences", slog.Any("err", err))
+			return nil, &models.HTTPError{
+				Code:    http.StatusInternalServerError,
+				Message: "Internal server error",
+			}
+		}
+	}
+
+	return s.GetPreferences(ctx, userID)
+}
@@ -0,0 +1,371 @@
+package notification
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/misshanya/mitter/internal/models"
+)
+
+var (
+	testUserID  = uuid.MustParse("b096376a-5fa9-4130-907a-709c67008a65")
+	testActorID = uuid.MustParse("38386ffe-54ac-48be-9244-a5144b41a014")
+	testMittID  = uuid.MustParse("5c0a1f5b-3a44-4bb5-9e55-0b0c1b1a4a11")
+)
+
+// Mock User repo
+type mockUserRepo struct{}
+
+func (r *mockUserRepo) CreateUser(ctx context.Context, user *models.UserCreate) (uuid.UUID, error) {
+	_ = ctx
+	_ = user
+
+	return uuid.Nil, nil
+}
+
+func (r *mockUserRepo) GetUserByLogin(ctx context.Context, login string) (*models.User, error) {
+	_ = ctx
+	_ = login
+
+	return nil, nil
+}
+
+func (r *mockUserRepo) GetUserByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	_ = ctx
+	_ = id
+
+	return &models.User{ID: id, Login: "actor", Name: "Actor"}, nil
+}
+
+func (r *mockUserRepo) GetUsersByIDs(ctx context.Context, ids []uuid.UUID) ([]*models.User, error) {
+	_ = ctx
+	_ = ids
+
+	users := make([]*models.User, len(ids))
+	for i, id := range ids {
+		users[i] = &models.User{ID: id, Login: "actor", Name: "Actor"}
+	}
+	return users, nil
+}
+
+func (r *mockUserRepo) DeactivateUser(ctx context.Context, id uuid.UUID) (bool, error) {
+	_ = ctx
+	_ = id
+
+	return true, nil
+}
+
+func (r *mockUserRepo) ReactivateUser(ctx context.Context, id uuid.UUID, gracePeriod time.Duration) (bool, error) {
+	_ = ctx
+	_ = id
+	_ = gracePeriod
+
+	return true, nil
+}
+
+func (r *mockUserRepo) GetUsersToPurge(ctx context.Context, gracePeriod time.Duration, limit int32) ([]uuid.UUID, error) {
+	_ = ctx
+	_ = gracePeriod
+	_ = limit
+
+	return nil, nil
+}
+
+func (r *mockUserRepo) DeleteUserLikes(ctx context.Context, id uuid.UUID, batchSize int32) (int64, error) {
+	_ = ctx
+	_ = id
+	_ = batchSize
+
+	return 0, nil
+}
+
+func (r *mockUserRepo) DeleteUserFollows(ctx context.Context, id uuid.UUID, batchSize int32) (int64, error) {
+	_ = ctx
+	_ = id
+	_ = batchSize
+
+	return 0, nil
+}
+
+func (r *mockUserRepo) DeleteUserMitts(ctx context.Context, id uuid.UUID, batchSize int32) (int64, error) {
+	_ = ctx
+	_ = id
+	_ = batchSize
+
+	return 0, nil
+}
+
+func (r *mockUserRepo) DeleteUser(ctx context.Context, id uuid.UUID) error {
+	_ = ctx
+	_ = id
+
+	return nil
+}
+
+func (r *mockUserRepo) UpdateUser(ctx context.Context, id uuid.UUID, user *models.UserUpdate) error {
+	_ = ctx
+	_ = id
+	_ = user
+
+	return nil
+}
+
+func (r *mockUserRepo) GetCurrentPasswordHash(ctx context.Context, id uuid.UUID) (string, error) {
+	_ = ctx
+	_ = id
+
+	return "", nil
+}
+
+func (r *mockUserRepo) ChangePassword(ctx context.Context, id uuid.UUID, newHashedPassword string) error {
+	_ = ctx
+	_ = id
+	_ = newHashedPassword
+
+	return nil
+}
+
+func (r *mockUserRepo) FollowUser(ctx context.Context, followerID uuid.UUID, followeeID uuid.UUID) error {
+	_ = ctx
+	_ = followerID
+	_ = followeeID
+
+	return nil
+}
+
+func (r *mockUserRepo) UnfollowUser(ctx context.Context, followerID uuid.UUID, followeeID uuid.UUID) error {
+	_ = ctx
+	_ = followerID
+	_ = followeeID
+
+	return nil
+}
+
+func (r *mockUserRepo) GetUserFollows(ctx context.Context, followerID uuid.UUID, limit, offset int32) ([]uuid.UUID, error) {
+	_ = ctx
+	_ = followerID
+	_ = limit
+	_ = offset
+
+	return nil, nil
+}
+
+func (r *mockUserRepo) GetUserFollowers(ctx context.Context, followeeID uuid.UUID, limit, offset int32) ([]uuid.UUID, error) {
+	_ = ctx
+	_ = followeeID
+	_ = limit
+	_ = offset
+
+	return nil, nil
+}
+
+func (r *mockUserRepo) GetUserFriends(ctx context.Context, userID uuid.UUID, limit, offset int32) ([]uuid.UUID, error) {
+	_ = ctx
+	_ = userID
+	_ = limit
+	_ = offset
+
+	return nil, nil
+}
+
+func (r *mockUserRepo) ListUserIDs(ctx context.Context, after uuid.UUID, limit int32) ([]uuid.UUID, error) {
+	_ = ctx
+	_ = limit
+
+	_ = after
+
+	return nil, nil
+}
+
+func (r *mockUserRepo) BlockUser(ctx context.Context, blockerID uuid.UUID, blockedID uuid.UUID) error {
+	_ = ctx
+	_ = blockerID
+	_ = blockedID
+
+	return nil
+}
+
+func (r *mockUserRepo) UnblockUser(ctx context.Context, blockerID uuid.UUID, blockedID uuid.UUID) error {
+	_ = ctx
+	_ = blockerID
+	_ = blockedID
+
+	return nil
+}
+
+func (r *mockUserRepo) IsBlockedBetween(ctx context.Context, firstID uuid.UUID, secondID uuid.UUID) (bool, error) {
+	_ = ctx
+	_ = firstID
+	_ = secondID
+
+	return false, nil
+}
+
+func (r *mockUserRepo) MuteUser(ctx context.Context, muterID uuid.UUID, mutedID uuid.UUID) error {
+	_ = ctx
+	_ = muterID
+	_ = mutedID
+
+	return nil
+}
+
+func (r *mockUserRepo) UnmuteUser(ctx context.Context, muterID uuid.UUID, mutedID uuid.UUID) error {
+	_ = ctx
+	_ = muterID
+	_ = mutedID
+
+	return nil
+}
+
+func (r *mockUserRepo) GetHiddenUserIDs(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	_ = ctx
+	_ = userID
+
+	return nil, nil
+}
+
+func (r *mockUserRepo) SuggestFollows(ctx context.Context, userID uuid.UUID, limit int32) ([]uuid.UUID, error) {
+	_ = ctx
+	_ = userID
+	_ = limit
+
+	return nil, nil
+}
+
+func (r *mockUserRepo) GetPopularUsers(ctx context.Context, userID uuid.UUID, limit int32) ([]uuid.UUID, error) {
+	_ = ctx
+	_ = userID
+	_ = limit
+
+	return nil, nil
+}
+
+func (r *mockUserRepo) FilterSuggestable(ctx context.Context, userID uuid.UUID, candidateIDs []uuid.UUID) ([]uuid.UUID, error) {
+	_ = ctx
+	_ = userID
+	_ = candidateIDs
+
+	return candidateIDs, nil
+}
+
+func (r *mockUserRepo) IsFollowing(ctx context.Context, followerID uuid.UUID, followeeID uuid.UUID) (bool, error) {
+	_ = ctx
+	_ = followerID
+	_ = followeeID
+
+	return false, nil
+}
+
+func (r *mockUserRepo) GetKnownFollowers(ctx context.Context, viewerID uuid.UUID, userID uuid.UUID, limit, offset int32) ([]uuid.UUID, error) {
+	_ = ctx
+	_ = viewerID
+	_ = userID
+	_ = limit
+	_ = offset
+
+	return []uuid.UUID{}, nil
+}
+
+func (r *mockUserRepo) GetRelationships(ctx context.Context, userID uuid.UUID, ids []uuid.UUID) ([]*models.Relationship, error) {
+	_ = ctx
+	_ = userID
+	_ = ids
+
+	return []*models.Relationship{}, nil
+}
+
+// Mock notification repo (in-memory, not grouped)
+type mockNotificationRepo struct {
+	notifications []*models.NotificationGroup
+	prefs         models.NotificationPreferences
+}
+
+func newMockNotificationRepo() *mockNotificationRepo {
+	return &mockNotificationRepo{prefs: models.NotificationPreferences{}}
+}
+
+func (r *mockNotificationRepo) CreateNotification(ctx context.Context, event *models.NotificationEvent) (bool, error) {
+	_ = ctx
+
+	if event.ActorID == testUserID {
+		return false, nil
+	}
+	if enabled, ok := r.prefs[event.Type]; ok && !enabled {
+		return false, nil
+	}
+
+	r.notifications = append(r.notifications, &models.NotificationGroup{
+		ID:          uuid.New(),
+		Type:        event.Type,
+		MittID:      event.MittID,
+		ActorIDs:    []uuid.UUID{event.ActorID},
+		ActorsCount: 1,
+		Unread:      true,
+		LatestAt:    time.Now(),
+	})
+	return true, nil
+}
+
+func (r *mockNotificationRepo) GetNotificationGroups(ctx context.Context, userID uuid.UUID, actorsLimit, limit, offset int32) ([]*models.NotificationGroup, error) {
+	_ = ctx
+	_ = userID
+	_ = actorsLimit
+
+	start := min(int(offset), len(r.notifications))
+	end := min(start+int(limit), len(r.notifications))
+	return r.notifications[start:end], nil
+}
+
+func (r *mockNotificationRepo) GetUnreadCount(ctx context.Context, userID uuid.UUID) (int64, error) {
+	_ = ctx
+	_ = userID
+
+	var count int64
+	for _, n := range r.notifications {
+		if n.Unread {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *mockNotificationRepo) MarkGroupRead(ctx context.Context, userID uuid.UUID, id uuid.UUID) (bool, error) {
+	_ = ctx
+	_ = userID
+
+	for _, n := range r.notifications {
+		if n.ID == id {
+			n.Unread = false
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *mockNotificationRepo) MarkAllRead(ctx context.Context, userID uuid.UUID) error {
+	_ = ctx
+	_ = userID
+
+	for _, n := range r.notifications {
+		n.Unread = false
+	}
+	return nil
+}
+
+func (r *mockNotificationRepo) GetPreferences(ctx context.Context, userID uuid.UUID) (models.NotificationPreferences, error) {
+	_ = ctx
+	_ = userID
+
+	return r.prefs, nil
+}
+
+func (r *mockNotificationRepo) SetPreferences(ctx context.Context, userID uuid.UUID, prefs models.NotificationPreferences) error {
+	_ = ctx
+	_ = userID
+
+	for t, enabled := range prefs {
+		r.prefs[t] = enabled
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package notification
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/misshanya/mitter/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// Tests
+func TestNotificationService_Notify(t *testing.T) {
+	nr := newMockNotificationRepo()
+	service := NewService(nr, &mockUserRepo{})
+	ctx := context.Background()
+
+	mittID := testMittID
+	service.Notify(ctx, &models.NotificationEvent{
+		Type:    models.NotificationLike,
+		ActorID: testActorID,
+		MittID:  &mittID,
+	})
+	// Own actions aren't notified
+	service.Notify(ctx, &models.NotificationEvent{
+		Type:    models.NotificationLike,
+		ActorID: testUserID,
+		MittID:  &mittID,
+	})
+
+	groups, err := service.GetNotifications(ctx, testUserID, 30, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assert.Len(t, groups, 1) {
+		assert.Equal(t, models.NotificationLike, groups[0].Type)
+		if assert.Len(t, groups[0].Actors, 1) {
+			assert.Equal(t, testActorID, groups[0].Actors[0].ID)
+		}
+	}
+}
+
+func TestNotificationService_MarkRead(t *testing.T) {
+	nr := newMockNotificationRepo()
+	service := NewService(nr, &mockUserRepo{})
+	ctx := context.Background()
+
+	userID := testUserID
+	for range 2 {
+		service.Notify(ctx, &models.NotificationEvent{
+			Type:    models.NotificationFollow,
+			ActorID: testActorID,
+			UserID:  &userID,
+		})
+	}
+
+	count, err := service.GetUnreadCount(ctx, testUserID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, int64(2), count)
+
+	if err := service.MarkRead(ctx, testUserID, nr.notifications[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	count, _ = service.GetUnreadCount(ctx, testUserID)
+	assert.Equal(t, int64(1), count)
+
+	err = service.MarkRead(ctx, testUserID, uuid.New())
+	if assert.NotNil(t, err) {
+		assert.Equal(t, http.StatusNotFound, err.Code)
+	}
+
+	if err := service.MarkAllRead(ctx, testUserID); err != nil {
+		t.Fatal(err)
+	}
+	count, _ = service.GetUnreadCount(ctx, testUserID)
+	assert.Equal(t, int64(0), count)
+}
+
+func TestNotificationService_Preferences(t *testing.T) {
+	nr := newMockNotificationRepo()
+	service := NewService(nr, &mockUserRepo{})
+	ctx := context.Background()
+
+	// Everything is enabled by default
+	prefs, err := service.GetPreferences(ctx, testUserID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, typ := range models.NotificationTypes {
+		assert.True(t, prefs[typ], typ)
+	}
+
+	prefs, err = service.UpdatePreferences(ctx, testUserID, models.NotificationPreferences{
+		models.NotificationMention: false,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.False(t, prefs[models.NotificationMention])
+	assert.True(t, prefs[models.NotificationLike])
+
+	// Disabled type isn't recorded
+	mittID := testMittID
+	service.Notify(ctx, &models.NotificationEvent{
+		Type:    models.NotificationMention,
+		ActorID: testActorID,
+		MittID:  &mittID,
+	})
+	assert.Empty(t, nr.notifications)
+}
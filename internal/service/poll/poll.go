@@ -0,0 +1,183 @@
+package poll
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/misshanya/mitter/internal/models"
+)
+
+// How many expired polls are closed per one db query
+const closeBatchSize = 100
+
+type Service struct {
+	pr models.PollRepository
+	mr models.MittRepository
+	nt models.Notifier
+}
+
+func NewService(pr models.PollRepository, mr models.MittRepository, nt models.Notifier) *Service {
+	return &Service{
+		pr: pr,
+		mr: mr,
+		nt: nt,
+	}
+}
+
+// getPoll returns mitt with poll which doesn't accept votes only if it's expired
+func (s *Service) getPoll(ctx context.Context, mittID uuid.UUID) (*models.Mitt, *models.HTTPError) {
+	mitt, err := s.mr.GetMitt(ctx, mittID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, &models.HTTPError{
+				Code:    http.StatusNotFound,
+				Message: "Poll not found",
+			}
+		}
+		slog.Error("error getting mitt", slog.Any("err", err))
+		return nil, &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		}
+	}
+
+	if mitt.Poll == nil {
+		return nil, &models.HTTPError{
+			Code:    http.StatusNotFound,
+			Message: "Poll not found",
+		}
+	}
+	return mitt, nil
+}
+
+// validChoices tells if choices are positions of different options and there is only one for single choice poll
+func validChoices(poll *models.Poll, choices []int) bool {
+	if len(choices) == 0 || (!poll.MultipleChoice && len(choices) > 1) {
+		return false
+	}
+
+	for i, c := range choices {
+		if c < 0 || c >= len(poll.Options) || slices.Contains(choices[:i], c) {
+			return false
+		}
+	}
+	return true
+}
+
+// Vote votes in poll of mitt, vote can't be changed. Returns mitt with updated results
+func (s *Service) Vote(ctx context.Context, userID uuid.UUID, mittID uuid.UUID, choices []int) (*models.Mitt, *models.HTTPError) {
+	mitt, httpErr := s.getPoll(ctx, mittID)
+	if httpErr != nil {
+		return nil, httpErr
+	}
+
+	if mitt.Poll.Expired(time.Now()) {
+		return nil, &models.HTTPError{
+			Code:    http.StatusConflict,
+			Message: "Poll is closed",
+		}
+	}
+
+	if !validChoices(mitt.Poll, choices) {
+		return nil, &models.HTTPError{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid poll choices",
+		}
+	}
+	slices.Sort(choices)
+
+	voted, err := s.pr.Vote(ctx, userID, mittID, choices)
+	if err != nil {
+		slog.Error("error voting in poll", slog.Any("err", err))
+		return nil, &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		}
+	}
+	if !voted {
+		// Poll could also expire or be deleted meanwhile, but it's unlikely
+		return nil, &models.HTTPError{
+			Code:    http.StatusConflict,
+			Message: "Already voted",
+		}
+	}
+
+	mitt, httpErr = s.getPoll(ctx, mittID)
+	if httpErr != nil {
+		return nil, httpErr
+	}
+	mitt.Poll.Voted = true
+	mitt.Poll.Choices = choices
+
+	return mitt, nil
+}
+
+// MarkMitts sets choices of user in polls of mitts
+func (s *Service) MarkMitts(ctx context.Context, userID uuid.UUID, mitts []*models.Mitt) *models.HTTPError {
+	ids := make([]uuid.UUID, 0, len(mitts))
+	for _, m := range mitts {
+		if m.Poll != nil {
+			ids = append(ids, m.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	choices, err := s.pr.GetChoices(ctx, userID, ids)
+	if err != nil {
+		slog.Error("error getting poll choices", slog.Any("err", err))
+		return &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		}
+	}
+
+	for _, m := range mitts {
+		if m.Poll == nil {
+			continue
+		}
+		m.Poll.Choices, m.Poll.Voted = choices[m.ID]
+	}
+	return nil
+}
+
+// CloseExpired closes expired polls and notifies their authors, polls of mitts in trash are closed silently.
+// Every poll is closed once even if several instances run the job at the same time
+func (s *Service) CloseExpired(ctx context.Context) error {
+	var total int
+	for {
+		closed, err := s.pr.ClosePolls(ctx, closeBatchSize)
+		if err != nil {
+			slog.Error("error closing expired polls", slog.Any("err", err))
+			return err
+		}
+		total += len(closed)
+
+		for _, p := range closed {
+			if !p.Active {
+				continue
+			}
+			s.nt.Notify(ctx, &models.NotificationEvent{
+				Type:    models.NotificationPollClosed,
+				ActorID: p.AuthorID,
+				MittID:  &p.MittID,
+			})
+		}
+
+		if len(closed) < closeBatchSize {
+			break
+		}
+	}
+
+	if total > 0 {
+		slog.Info("closed expired polls", slog.Int("count", total))
+	}
+	return nil
+}
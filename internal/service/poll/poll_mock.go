@@ -0,0 +1,285 @@
+package poll
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/misshanya/mitter/internal/models"
+)
+
+var (
+	testUserID    = uuid.MustParse("b096376a-5fa9-4130-907a-709c67008a65")
+	testAuthorID  = uuid.MustParse("38386ffe-54ac-48be-9244-a5144b41a014")
+	testMittID    = uuid.MustParse("5c0a1f5b-3a44-4bb5-9e55-0b0c1b1a4a11")
+	testNoPollID  = uuid.MustParse("e4a3f7a2-6f1d-4c1b-9a53-2d8f0e4b7c21")
+	testExpiredID = uuid.MustParse("9d2c6b1e-0c7a-4f5e-8b3d-71a6e2f4c9b8")
+)
+
+type mockPoll struct {
+	options        []string
+	multipleChoice bool
+	expiresAt      time.Time
+	closed         bool
+	// User ID -> choices
+	votes map[uuid.UUID][]int
+}
+
+// Mock poll repo (in-memory), shared with mock mitt repo
+type mockPollRepo struct {
+	polls map[uuid.UUID]*mockPoll
+}
+
+func newMockPollRepo() *mockPollRepo {
+	return &mockPollRepo{polls: map[uuid.UUID]*mockPoll{
+		testMittID: {
+			options:   []string{"yes", "no", "maybe"},
+			expiresAt: time.Now().Add(time.Hour),
+			votes:     map[uuid.UUID][]int{},
+		},
+		testExpiredID: {
+			options:   []string{"yes", "no"},
+			expiresAt: time.Now().Add(-time.Minute),
+			votes:     map[uuid.UUID][]int{},
+		},
+	}}
+}
+
+func (r *mockPollRepo) Vote(ctx context.Context, userID uuid.UUID, mittID uuid.UUID, choices []int) (bool, error) {
+	_ = ctx
+
+	p, ok := r.polls[mittID]
+	if !ok || p.closed || !time.Now().Before(p.expiresAt) {
+		return false, nil
+	}
+	if _, ok := p.votes[userID]; ok {
+		return false, nil
+	}
+	p.votes[userID] = slices.Clone(choices)
+	return true, nil
+}
+
+func (r *mockPollRepo) GetChoices(ctx context.Context, userID uuid.UUID, mittIDs []uuid.UUID) (map[uuid.UUID][]int, error) {
+	_ = ctx
+
+	choices := make(map[uuid.UUID][]int)
+	for _, id := range mittIDs {
+		if p, ok := r.polls[id]; ok {
+			if c, ok := p.votes[userID]; ok {
+				choices[id] = c
+			}
+		}
+	}
+	return choices, nil
+}
+
+func (r *mockPollRepo) ClosePolls(ctx context.Context, batchSize int32) ([]*models.PollClosed, error) {
+	_ = ctx
+
+	var closed []*models.PollClosed
+	for id, p := range r.polls {
+		if int32(len(closed)) == batchSize {
+			break
+		}
+		if p.closed || time.Now().Before(p.expiresAt) {
+			continue
+		}
+		p.closed = true
+		closed = append(closed, &models.PollClosed{MittID: id, AuthorID: testAuthorID, Active: true})
+	}
+	return closed, nil
+}
+
+// Mock notifier
+type mockNotifier struct {
+	events []*models.NotificationEvent
+}
+
+func (n *mockNotifier) Notify(ctx context.Context, event *models.NotificationEvent) {
+	_ = ctx
+
+	n.events = append(n.events, event)
+}
+
+// Mock Mitt repo, mitts have polls of mock poll repo
+type mockMittRepo struct {
+	pr *mockPollRepo
+}
+
+func (r *mockMittRepo) CreateMitt(ctx context.Context, userID uuid.UUID, mitt *models.MittCreate) (*models.Mitt, error) {
+	_ = ctx
+	_ = userID
+	_ = mitt
+
+	return nil, nil
+}
+
+func (r *mockMittRepo) GetMitt(ctx context.Context, id uuid.UUID) (*models.Mitt, error) {
+	_ = ctx
+
+	if id == testNoPollID {
+		return &models.Mitt{ID: id, AuthorID: testAuthorID}, nil
+	}
+	p, ok := r.pr.polls[id]
+	if !ok {
+		return nil, pgx.ErrNoRows
+	}
+
+	poll := &models.Poll{
+		Options:        make([]*models.PollOption, len(p.options)),
+		MultipleChoice: p.multipleChoice,
+		ExpiresAt:      p.expiresAt,
+		VotersCount:    int64(len(p.votes)),
+	}
+	for i, text := range p.options {
+		poll.Options[i] = &models.PollOption{Text: text}
+	}
+	for _, choices := range p.votes {
+		for _, c := range choices {
+			poll.Options[c].Votes++
+		}
+	}
+	return &models.Mitt{ID: id, AuthorID: testAuthorID, Poll: poll}, nil
+}
+
+func (r *mockMittRepo) GetAllUserMitts(ctx context.Context, userID uuid.UUID, limit, offset int32) ([]*models.Mitt, error) {
+	_ = ctx
+	_ = userID
+	_ = limit
+	_ = offset
+
+	return nil, nil
+}
+
+func (r *mockMittRepo) UpdateMitt(ctx context.Context, mittID uuid.UUID, mitt *models.MittUpdate) (*models.Mitt, error) {
+	_ = ctx
+	_ = mittID
+	_ = mitt
+
+	return nil, nil
+}
+
+func (r *mockMittRepo) DeleteMitt(ctx context.Context, mittID uuid.UUID) error {
+	_ = ctx
+	_ = mittID
+
+	return nil
+}
+
+func (r *mockMittRepo) RestoreMitt(ctx context.Context, userID uuid.UUID, mittID uuid.UUID, retention time.Duration) (bool, error) {
+	_ = ctx
+	_ = userID
+	_ = mittID
+	_ = retention
+
+	return false, nil
+}
+
+func (r *mockMittRepo) GetDeletedUserMitts(ctx context.Context, userID uuid.UUID, limit, offset int32) ([]*models.Mitt, error) {
+	_ = ctx
+	_ = userID
+	_ = limit
+	_ = offset
+
+	return nil, nil
+}
+
+func (r *mockMittRepo) PurgeDeletedMitts(ctx context.Context, retention time.Duration, batchSize int32) (int64, error) {
+	_ = ctx
+	_ = retention
+	_ = batchSize
+
+	return 0, nil
+}
+
+func (r *mockMittRepo) GetMittRevisions(ctx context.Context, mittID uuid.UUID, limit, offset int32) ([]*models.MittRevision, error) {
+	_ = ctx
+	_ = mittID
+	_ = limit
+	_ = offset
+
+	return nil, nil
+}
+
+func (r *mockMittRepo) GetMittsRevisions(ctx context.Context, mittIDs []uuid.UUID) (map[uuid.UUID][]*models.MittRevision, error) {
+	_ = ctx
+	_ = mittIDs
+
+	return nil, nil
+}
+
+func (r *mockMittRepo) DeleteExpiredMitts(ctx context.Context, batchSize int32) ([]*models.ExpiredMitt, error) {
+	_ = ctx
+	_ = batchSize
+
+	return nil, nil
+}
+
+func (r *mockMittRepo) PinMitt(ctx context.Context, userID uuid.UUID, mittID uuid.UUID, max int) error {
+	_ = ctx
+	_ = userID
+	_ = mittID
+	_ = max
+
+	return nil
+}
+
+func (r *mockMittRepo) UnpinMitt(ctx context.Context, userID uuid.UUID, mittID uuid.UUID) (bool, error) {
+	_ = ctx
+	_ = userID
+	_ = mittID
+
+	return false, nil
+}
+
+func (r *mockMittRepo) LikeMitt(ctx context.Context, userID uuid.UUID, mittID uuid.UUID) (bool, error) {
+	_ = ctx
+	_ = userID
+	_ = mittID
+
+	return false, nil
+}
+
+func (r *mockMittRepo) IsMittLikedByUser(ctx context.Context, userID uuid.UUID, mittID uuid.UUID) (bool, error) {
+	_ = ctx
+	_ = userID
+	_ = mittID
+
+	return false, nil
+}
+
+func (r *mockMittRepo) DeleteMittLike(ctx context.Context, userID uuid.UUID, mittID uuid.UUID) (bool, error) {
+	_ = ctx
+	_ = userID
+	_ = mittID
+
+	return false, nil
+}
+
+func (r *mockMittRepo) GetUserLikes(ctx context.Context, userID uuid.UUID, limit, offset int32) ([]*models.MittLike, error) {
+	_ = ctx
+	_ = userID
+	_ = limit
+	_ = offset
+
+	return nil, nil
+}
+
+func (r *mockMittRepo) Feed(ctx context.Context, limit, offset int32) ([]*models.Mitt, error) {
+	_ = ctx
+	_ = limit
+	_ = offset
+
+	return nil, nil
+}
+
+func (r *mockMittRepo) GetMentionedMitts(ctx context.Context, userID uuid.UUID, limit, offset int32) ([]*models.Mitt, error) {
+	_ = ctx
+	_ = userID
+	_ = limit
+	_ = offset
+
+	return nil, nil
+}
@@ -0,0 +1,132 @@
+package poll
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/misshanya/mitter/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_Vote(t *testing.T) {
+	pr := newMockPollRepo()
+	service := NewService(pr, &mockMittRepo{pr: pr}, &mockNotifier{})
+	ctx := context.Background()
+
+	mitt, err := service.Vote(ctx, testUserID, testMittID, []int{1})
+	require.Nil(t, err)
+	assert.True(t, mitt.Poll.Voted)
+	assert.Equal(t, []int{1}, mitt.Poll.Choices)
+	assert.Equal(t, int64(1), mitt.Poll.VotersCount)
+	assert.Equal(t, int64(1), mitt.Poll.Options[1].Votes)
+
+	// Vote can't be changed
+	_, err = service.Vote(ctx, testUserID, testMittID, []int{0})
+	if assert.NotNil(t, err) {
+		assert.Equal(t, http.StatusConflict, err.Code)
+		assert.Equal(t, "Already voted", err.Message)
+	}
+	assert.Equal(t, []int{1}, pr.polls[testMittID].votes[testUserID])
+}
+
+func TestService_VoteInvalid(t *testing.T) {
+	pr := newMockPollRepo()
+	service := NewService(pr, &mockMittRepo{pr: pr}, &mockNotifier{})
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		mittID  uuid.UUID
+		choices []int
+		code    int
+	}{
+		{"missing mitt", uuid.New(), []int{0}, http.StatusNotFound},
+		{"mitt without poll", testNoPollID, []int{0}, http.StatusNotFound},
+		{"expired poll", testExpiredID, []int{0}, http.StatusConflict},
+		{"no choices", testMittID, []int{}, http.StatusBadRequest},
+		{"several choices in single choice poll", testMittID, []int{0, 1}, http.StatusBadRequest},
+		{"unknown option", testMittID, []int{3}, http.StatusBadRequest},
+		{"negative option", testMittID, []int{-1}, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.Vote(ctx, testUserID, tt.mittID, tt.choices)
+			if assert.NotNil(t, err) {
+				assert.Equal(t, tt.code, err.Code)
+			}
+		})
+	}
+	assert.Empty(t, pr.polls[testMittID].votes)
+}
+
+func TestService_VoteMultipleChoice(t *testing.T) {
+	pr := newMockPollRepo()
+	pr.polls[testMittID].multipleChoice = true
+	service := NewService(pr, &mockMittRepo{pr: pr}, &mockNotifier{})
+	ctx := context.Background()
+
+	_, err := service.Vote(ctx, testUserID, testMittID, []int{2, 2})
+	if assert.NotNil(t, err) {
+		assert.Equal(t, http.StatusBadRequest, err.Code)
+	}
+
+	mitt, err := service.Vote(ctx, testUserID, testMittID, []int{2, 0})
+	require.Nil(t, err)
+	// Choices are sorted
+	assert.Equal(t, []int{0, 2}, mitt.Poll.Choices)
+	assert.Equal(t, int64(1), mitt.Poll.VotersCount)
+	assert.Equal(t, int64(1), mitt.Poll.Options[0].Votes)
+	assert.Equal(t, int64(0), mitt.Poll.Options[1].Votes)
+	assert.Equal(t, int64(1), mitt.Poll.Options[2].Votes)
+}
+
+func TestService_MarkMitts(t *testing.T) {
+	pr := newMockPollRepo()
+	mr := &mockMittRepo{pr: pr}
+	service := NewService(pr, mr, &mockNotifier{})
+	ctx := context.Background()
+
+	_, err := service.Vote(ctx, testUserID, testMittID, []int{0})
+	require.Nil(t, err)
+
+	voted, _ := mr.GetMitt(ctx, testMittID)
+	expired, _ := mr.GetMitt(ctx, testExpiredID)
+	noPoll, _ := mr.GetMitt(ctx, testNoPollID)
+	require.Nil(t, service.MarkMitts(ctx, testUserID, []*models.Mitt{voted, expired, noPoll}))
+
+	assert.True(t, voted.Poll.Voted)
+	assert.Equal(t, []int{0}, voted.Poll.Choices)
+	assert.False(t, expired.Poll.Voted)
+	assert.Nil(t, noPoll.Poll)
+
+	// Results are hidden until user votes or poll expires
+	other, _ := mr.GetMitt(ctx, testMittID)
+	require.Nil(t, service.MarkMitts(ctx, testAuthorID, []*models.Mitt{other}))
+	assert.False(t, other.Poll.ResultsVisible(time.Now()))
+	assert.True(t, voted.Poll.ResultsVisible(time.Now()))
+	assert.True(t, expired.Poll.ResultsVisible(time.Now()))
+}
+
+func TestService_CloseExpired(t *testing.T) {
+	pr := newMockPollRepo()
+	nt := &mockNotifier{}
+	service := NewService(pr, &mockMittRepo{pr: pr}, nt)
+	ctx := context.Background()
+
+	require.NoError(t, service.CloseExpired(ctx))
+	assert.True(t, pr.polls[testExpiredID].closed)
+	assert.False(t, pr.polls[testMittID].closed)
+
+	// Author is notified once
+	require.NoError(t, service.CloseExpired(ctx))
+	if assert.Len(t, nt.events, 1) {
+		assert.Equal(t, models.NotificationPollClosed, nt.events[0].Type)
+		assert.Equal(t, testAuthorID, nt.events[0].ActorID)
+		assert.Equal(t, testExpiredID, *nt.events[0].MittID)
+	}
+}
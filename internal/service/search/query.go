@@ -0,0 +1,116 @@
+package search
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/misshanya/mitter/internal/models"
+	"github.com/misshanya/mitter/pkg/content"
+)
+
+// Operators of mitts search query, the rest of it is searched as text
+const (
+	fromOperator  = "from:"
+	sinceOperator = "since:"
+	untilOperator = "until:"
+)
+
+const dateLayout = "2006-01-02"
+
+var (
+	ErrEmptyQuery   = errors.New("search query is empty")
+	ErrInvalidDate  = errors.New("since: and until: must be dates like 2006-01-02")
+	ErrInvalidRange = errors.New("until: must be after since:")
+)
+
+// splitQuery splits query by whitespace keeping "quoted phrases" (and -"excluded phrases") whole
+func splitQuery(q string) []string {
+	var tokens []string
+
+	runes := []rune(q)
+	for i := 0; i < len(runes); {
+		if unicode.IsSpace(runes[i]) {
+			i++
+			continue
+		}
+
+		start := i
+		if runes[i] == '"' || (runes[i] == '-' && i+1 < len(runes) && runes[i+1] == '"') {
+			// Up to closing quote or end of query
+			i = slices.Index(runes[start:], '"') + start + 1
+			if end := slices.Index(runes[i:], '"'); end >= 0 {
+				i += end + 1
+			} else {
+				i = len(runes)
+			}
+		} else {
+			for i < len(runes) && !unicode.IsSpace(runes[i]) {
+				i++
+			}
+		}
+		tokens = append(tokens, string(runes[start:i]))
+	}
+
+	return tokens
+}
+
+// parseMittQuery extracts from:login, #hashtag, since:date and until:date (inclusive) filters from query,
+// everything else is left for full-text search. Logins are lowercased, they are matched case-insensitively
+func parseMittQuery(q string) (*models.MittSearch, error) {
+	search := &models.MittSearch{}
+
+	var text []string
+	for _, token := range splitQuery(q) {
+		lower := strings.ToLower(token)
+		switch {
+		case strings.HasPrefix(lower, fromOperator) && len(token) > len(fromOperator):
+			login := strings.TrimPrefix(lower[len(fromOperator):], "@")
+			if !slices.Contains(search.From, login) {
+				search.From = append(search.From, login)
+			}
+
+		case strings.HasPrefix(lower, sinceOperator):
+			since, err := time.Parse(dateLayout, token[len(sinceOperator):])
+			if err != nil {
+				return nil, ErrInvalidDate
+			}
+			search.Since = &since
+
+		case strings.HasPrefix(lower, untilOperator):
+			until, err := time.Parse(dateLayout, token[len(untilOperator):])
+			if err != nil {
+				return nil, ErrInvalidDate
+			}
+			// The whole day is included
+			until = until.AddDate(0, 0, 1)
+			search.Until = &until
+
+		case strings.HasPrefix(token, "#"):
+			tag, ok := content.NormalizeHashtag(token)
+			if !ok {
+				// Not a hashtag, like "#1", search it as text
+				text = append(text, token)
+				continue
+			}
+			if !slices.Contains(search.Hashtags, tag) {
+				search.Hashtags = append(search.Hashtags, tag)
+			}
+
+		default:
+			text = append(text, token)
+		}
+	}
+	search.Text = strings.Join(text, " ")
+
+	if search.Text == "" && search.From == nil && search.Hashtags == nil && search.Since == nil && search.Until == nil {
+		return nil, ErrEmptyQuery
+	}
+	if search.Since != nil && search.Until != nil && !search.Until.After(*search.Since) {
+		return nil, ErrInvalidRange
+	}
+
+	return search, nil
+}
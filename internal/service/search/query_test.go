@@ -0,0 +1,65 @@
+package search
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitQuery(t *testing.T) {
+	for _, tc := range []struct {
+		query  string
+		tokens []string
+	}{
+		{"  go  lang ", []string{"go", "lang"}},
+		{`"hello world" from:bob`, []string{`"hello world"`, "from:bob"}},
+		{`cats -"big dogs" birds`, []string{"cats", `-"big dogs"`, "birds"}},
+		{`"unclosed phrase`, []string{`"unclosed phrase`}},
+		{"", nil},
+	} {
+		assert.Equal(t, tc.tokens, splitQuery(tc.query), tc.query)
+	}
+}
+
+func TestParseMittQuery(t *testing.T) {
+	search, err := parseMittQuery(`"hello world" from:@Bob from:bob #Go #go since:2024-01-01 until:2024-01-31 -spam #1`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, `"hello world" -spam #1`, search.Text)
+	assert.Equal(t, []string{"bob"}, search.From)
+	assert.Equal(t, []string{"go"}, search.Hashtags)
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), *search.Since)
+	// Until is inclusive
+	assert.Equal(t, time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), *search.Until)
+
+	// Operators inside phrases are text
+	search, err = parseMittQuery(`"from:bob #go"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, `"from:bob #go"`, search.Text)
+	assert.Nil(t, search.From)
+
+	// Filters alone are enough
+	search, err = parseMittQuery("from:bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Empty(t, search.Text)
+
+	for _, tc := range []struct {
+		query string
+		err   error
+	}{
+		{"", ErrEmptyQuery},
+		{"since:yesterday", ErrInvalidDate},
+		{"cats until:2024-13-01", ErrInvalidDate},
+		{"since:2024-02-01 until:2024-01-01", ErrInvalidRange},
+	} {
+		_, err := parseMittQuery(tc.query)
+		assert.ErrorIs(t, err, tc.err, tc.query)
+	}
+}
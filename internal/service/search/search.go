@@ -0,0 +1,220 @@
+package search
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/misshanya/mitter/internal/dataloader"
+	"github.com/misshanya/mitter/internal/models"
+	"github.com/misshanya/mitter/pkg/content"
+)
+
+// Max query length in characters
+const (
+	maxQueryLength     = 256
+	maxUserQueryLength = 50
+)
+
+// Messages of query parsing errors for clients
+var queryErrorMessages = map[error]string{
+	ErrEmptyQuery:   "Search query is required",
+	ErrInvalidDate:  "Dates in since: and until: must be like 2006-01-02",
+	ErrInvalidRange: "until: must be after since:",
+}
+
+type Service struct {
+	sr  models.SearchRepository
+	acr models.AutocompleteRepository
+	ur  models.UserRepository
+
+	autocompleteCount int32
+	autocompleteTTL   time.Duration
+}
+
+// NewService creates search service which keeps up to autocompleteCount users per login prefix cached for autocompleteTTL
+func NewService(sr models.SearchRepository, acr models.AutocompleteRepository, ur models.UserRepository, autocompleteCount int32, autocompleteTTL time.Duration) *Service {
+	return &Service{
+		sr:                sr,
+		acr:               acr,
+		ur:                ur,
+		autocompleteCount: autocompleteCount,
+		autocompleteTTL:   autocompleteTTL,
+	}
+}
+
+// SearchMitts returns mitts matching query which are visible to viewer: not deleted,
+// not by private accounts viewer doesn't follow and not by users viewer blocked, muted or was blocked by
+func (s *Service) SearchMitts(ctx context.Context, viewerID uuid.UUID, query string, order models.SearchOrder, limit, offset int32) ([]*models.Mitt, *models.HTTPError) {
+	if order != models.SearchByRelevance && order != models.SearchByRecency {
+		return nil, &models.HTTPError{
+			Code:    http.StatusBadRequest,
+			Message: "Unknown sort order",
+		}
+	}
+
+	query = content.Normalize(query)
+	if content.Length(query) > maxQueryLength {
+		return nil, &models.HTTPError{
+			Code:    http.StatusBadRequest,
+			Message: "Search query is too long",
+		}
+	}
+
+	search, err := parseMittQuery(query)
+	if err != nil {
+		return nil, &models.HTTPError{
+			Code:    http.StatusBadRequest,
+			Message: queryErrorMessages[err],
+		}
+	}
+	search.ViewerID = viewerID
+	search.Order = order
+
+	mitts, err := s.sr.SearchMitts(ctx, search, limit, offset)
+	if err != nil {
+		slog.Error("error searching mitts", slog.Any("err", err))
+		return nil, &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		}
+	}
+
+	return mitts, nil
+}
+
+// normalizeUserQuery brings user search query to the form of login, "@login" is searched as "login"
+func normalizeUserQuery(query string) (string, *models.HTTPError) {
+	query = strings.TrimPrefix(content.Normalize(query), "@")
+	if query == "" {
+		return "", &models.HTTPError{
+			Code:    http.StatusBadRequest,
+			Message: "Search query is required",
+		}
+	}
+	if content.Length(query) > maxUserQueryLength {
+		return "", &models.HTTPError{
+			Code:    http.StatusBadRequest,
+			Message: "Search query is too long",
+		}
+	}
+	return query, nil
+}
+
+// SearchUsers returns users with login or name similar to query. Accounts viewer follows
+// and accounts followed by viewer's friends are ranked higher
+func (s *Service) SearchUsers(ctx context.Context, viewerID uuid.UUID, query string, limit, offset int32) ([]*models.User, *models.HTTPError) {
+	query, httpErr := normalizeUserQuery(query)
+	if httpErr != nil {
+		return nil, httpErr
+	}
+
+	ids, err := s.sr.SearchUsers(ctx, viewerID, query, limit, offset)
+	if err != nil {
+		slog.Error("error searching users", slog.Any("err", err))
+		return nil, &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		}
+	}
+
+	users, err := dataloader.Users(ctx, s.ur).LoadMany(ctx, ids)
+	if err != nil {
+		slog.Error("error searching users (getting users from db)", slog.Any("err", err))
+		return nil, &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		}
+	}
+
+	return users, nil
+}
+
+// autocompleteCandidates returns the most followed users with login prefix, from cache if possible
+func (s *Service) autocompleteCandidates(ctx context.Context, prefix string) ([]uuid.UUID, error) {
+	ids, found, err := s.acr.GetAutocomplete(ctx, prefix)
+	if err != nil {
+		// Cache is an optimization, go to db
+		slog.Error("error getting cached autocomplete", slog.Any("err", err))
+	}
+	if found {
+		return ids, nil
+	}
+
+	ids, err = s.sr.AutocompleteLogins(ctx, prefix, s.autocompleteCount)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.acr.SaveAutocomplete(ctx, prefix, ids, s.autocompleteTTL); err != nil {
+		slog.Error("error caching autocomplete", slog.Any("err", err))
+	}
+
+	return ids, nil
+}
+
+// Autocomplete returns users whose login starts with prefix for @mentions. Candidates are shared by everyone
+// and cached, then users blocked either way are dropped and users viewer follows go first
+func (s *Service) Autocomplete(ctx context.Context, viewerID uuid.UUID, prefix string) ([]*models.User, *models.HTTPError) {
+	prefix, httpErr := normalizeUserQuery(prefix)
+	if httpErr != nil {
+		return nil, httpErr
+	}
+	prefix = strings.ToLower(prefix)
+
+	ids, err := s.autocompleteCandidates(ctx, prefix)
+	if err != nil {
+		slog.Error("error getting autocomplete", slog.Any("err", err))
+		return nil, &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		}
+	}
+	if len(ids) == 0 {
+		return []*models.User{}, nil
+	}
+
+	relationships, err := s.ur.GetRelationships(ctx, viewerID, ids)
+	if err != nil {
+		slog.Error("error getting autocomplete (getting relationships from db)", slog.Any("err", err))
+		return nil, &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		}
+	}
+	byID := make(map[uuid.UUID]*models.Relationship, len(relationships))
+	for _, r := range relationships {
+		byID[r.UserID] = r
+	}
+
+	ids = slices.DeleteFunc(ids, func(id uuid.UUID) bool {
+		r, ok := byID[id]
+		return ok && (r.Blocking || r.BlockedBy)
+	})
+	slices.SortStableFunc(ids, func(a, b uuid.UUID) int {
+		aFollowing := byID[a] != nil && byID[a].Following
+		bFollowing := byID[b] != nil && byID[b].Following
+		switch {
+		case aFollowing && !bFollowing:
+			return -1
+		case !aFollowing && bFollowing:
+			return 1
+		}
+		return 0
+	})
+
+	users, err := dataloader.Users(ctx, s.ur).LoadMany(ctx, ids)
+	if err != nil {
+		slog.Error("error getting autocomplete (getting users from db)", slog.Any("err", err))
+		return nil, &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		}
+	}
+
+	return users, nil
+}
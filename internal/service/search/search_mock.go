@@ -0,0 +1,358 @@
+package search
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/misshanya/mitter/internal/models"
+)
+
+// Mock User repo
+type mockUserRepo struct {
+	relationships map[uuid.UUID]*models.Relationship
+}
+
+func (r *mockUserRepo) CreateUser(ctx context.Context, user *models.UserCreate) (uuid.UUID, error) {
+	_ = ctx
+	_ = user
+
+	return uuid.Nil, nil
+}
+
+func (r *mockUserRepo) GetUserByLogin(ctx context.Context, login string) (*models.User, error) {
+	_ = ctx
+	_ = login
+
+	return nil, nil
+}
+
+func (r *mockUserRepo) GetUserByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	_ = ctx
+	_ = id
+
+	return &models.User{ID: id, Login: "user", Name: "User"}, nil
+}
+
+func (r *mockUserRepo) GetUsersByIDs(ctx context.Context, ids []uuid.UUID) ([]*models.User, error) {
+	_ = ctx
+	_ = ids
+
+	users := make([]*models.User, len(ids))
+	for i, id := range ids {
+		users[i] = &models.User{ID: id, Login: "user", Name: "User"}
+	}
+	return users, nil
+}
+
+func (r *mockUserRepo) DeactivateUser(ctx context.Context, id uuid.UUID) (bool, error) {
+	_ = ctx
+	_ = id
+
+	return true, nil
+}
+
+func (r *mockUserRepo) ReactivateUser(ctx context.Context, id uuid.UUID, gracePeriod time.Duration) (bool, error) {
+	_ = ctx
+	_ = id
+	_ = gracePeriod
+
+	return true, nil
+}
+
+func (r *mockUserRepo) GetUsersToPurge(ctx context.Context, gracePeriod time.Duration, limit int32) ([]uuid.UUID, error) {
+	_ = ctx
+	_ = gracePeriod
+	_ = limit
+
+	return nil, nil
+}
+
+func (r *mockUserRepo) DeleteUserLikes(ctx context.Context, id uuid.UUID, batchSize int32) (int64, error) {
+	_ = ctx
+	_ = id
+	_ = batchSize
+
+	return 0, nil
+}
+
+func (r *mockUserRepo) DeleteUserFollows(ctx context.Context, id uuid.UUID, batchSize int32) (int64, error) {
+	_ = ctx
+	_ = id
+	_ = batchSize
+
+	return 0, nil
+}
+
+func (r *mockUserRepo) DeleteUserMitts(ctx context.Context, id uuid.UUID, batchSize int32) (int64, error) {
+	_ = ctx
+	_ = id
+	_ = batchSize
+
+	return 0, nil
+}
+
+func (r *mockUserRepo) DeleteUser(ctx context.Context, id uuid.UUID) error {
+	_ = ctx
+	_ = id
+
+	return nil
+}
+
+func (r *mockUserRepo) UpdateUser(ctx context.Context, id uuid.UUID, user *models.UserUpdate) error {
+	_ = ctx
+	_ = id
+	_ = user
+
+	return nil
+}
+
+func (r *mockUserRepo) GetCurrentPasswordHash(ctx context.Context, id uuid.UUID) (string, error) {
+	_ = ctx
+	_ = id
+
+	return "", nil
+}
+
+func (r *mockUserRepo) ChangePassword(ctx context.Context, id uuid.UUID, newHashedPassword string) error {
+	_ = ctx
+	_ = id
+	_ = newHashedPassword
+
+	return nil
+}
+
+func (r *mockUserRepo) FollowUser(ctx context.Context, followerID uuid.UUID, followeeID uuid.UUID) error {
+	_ = ctx
+	_ = followerID
+	_ = followeeID
+
+	return nil
+}
+
+func (r *mockUserRepo) UnfollowUser(ctx context.Context, followerID uuid.UUID, followeeID uuid.UUID) error {
+	_ = ctx
+	_ = followerID
+	_ = followeeID
+
+	return nil
+}
+
+func (r *mockUserRepo) GetUserFollows(ctx context.Context, followerID uuid.UUID, limit, offset int32) ([]uuid.UUID, error) {
+	_ = ctx
+	_ = followerID
+	_ = limit
+	_ = offset
+
+	return nil, nil
+}
+
+func (r *mockUserRepo) GetUserFollowers(ctx context.Context, followeeID uuid.UUID, limit, offset int32) ([]uuid.UUID, error) {
+	_ = ctx
+	_ = followeeID
+	_ = limit
+	_ = offset
+
+	return nil, nil
+}
+
+func (r *mockUserRepo) GetUserFriends(ctx context.Context, userID uuid.UUID, limit, offset int32) ([]uuid.UUID, error) {
+	_ = ctx
+	_ = userID
+	_ = limit
+	_ = offset
+
+	return nil, nil
+}
+
+func (r *mockUserRepo) ListUserIDs(ctx context.Context, after uuid.UUID, limit int32) ([]uuid.UUID, error) {
+	_ = ctx
+	_ = limit
+
+	_ = after
+
+	return nil, nil
+}
+
+func (r *mockUserRepo) BlockUser(ctx context.Context, blockerID uuid.UUID, blockedID uuid.UUID) error {
+	_ = ctx
+	_ = blockerID
+	_ = blockedID
+
+	return nil
+}
+
+func (r *mockUserRepo) UnblockUser(ctx context.Context, blockerID uuid.UUID, blockedID uuid.UUID) error {
+	_ = ctx
+	_ = blockerID
+	_ = blockedID
+
+	return nil
+}
+
+func (r *mockUserRepo) IsBlockedBetween(ctx context.Context, firstID uuid.UUID, secondID uuid.UUID) (bool, error) {
+	_ = ctx
+	_ = firstID
+	_ = secondID
+
+	return false, nil
+}
+
+func (r *mockUserRepo) MuteUser(ctx context.Context, muterID uuid.UUID, mutedID uuid.UUID) error {
+	_ = ctx
+	_ = muterID
+	_ = mutedID
+
+	return nil
+}
+
+func (r *mockUserRepo) UnmuteUser(ctx context.Context, muterID uuid.UUID, mutedID uuid.UUID) error {
+	_ = ctx
+	_ = muterID
+	_ = mutedID
+
+	return nil
+}
+
+func (r *mockUserRepo) GetHiddenUserIDs(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	_ = ctx
+	_ = userID
+
+	return nil, nil
+}
+
+func (r *mockUserRepo) SuggestFollows(ctx context.Context, userID uuid.UUID, limit int32) ([]uuid.UUID, error) {
+	_ = ctx
+	_ = userID
+	_ = limit
+
+	return nil, nil
+}
+
+func (r *mockUserRepo) GetPopularUsers(ctx context.Context, userID uuid.UUID, limit int32) ([]uuid.UUID, error) {
+	_ = ctx
+	_ = userID
+	_ = limit
+
+	return nil, nil
+}
+
+func (r *mockUserRepo) FilterSuggestable(ctx context.Context, userID uuid.UUID, candidateIDs []uuid.UUID) ([]uuid.UUID, error) {
+	_ = ctx
+	_ = userID
+	_ = candidateIDs
+
+	return candidateIDs, nil
+}
+
+func (r *mockUserRepo) IsFollowing(ctx context.Context, followerID uuid.UUID, followeeID uuid.UUID) (bool, error) {
+	_ = ctx
+	_ = followerID
+	_ = followeeID
+
+	return false, nil
+}
+
+func (r *mockUserRepo) GetKnownFollowers(ctx context.Context, viewerID uuid.UUID, userID uuid.UUID, limit, offset int32) ([]uuid.UUID, error) {
+	_ = ctx
+	_ = viewerID
+	_ = userID
+	_ = limit
+	_ = offset
+
+	return []uuid.UUID{}, nil
+}
+func (r *mockUserRepo) GetRelationships(ctx context.Context, userID uuid.UUID, ids []uuid.UUID) ([]*models.Relationship, error) {
+	_ = ctx
+	_ = userID
+
+	relationships := make([]*models.Relationship, 0, len(ids))
+	for _, id := range ids {
+		if rel, ok := r.relationships[id]; ok {
+			relationships = append(relationships, rel)
+		} else {
+			relationships = append(relationships, &models.Relationship{UserID: id})
+		}
+	}
+	return relationships, nil
+}
+
+// Mock search repo
+type mockSearchRepo struct {
+	searches []*models.MittSearch
+	// Users by login
+	users        map[string]uuid.UUID
+	autocomplete int
+}
+
+func (r *mockSearchRepo) SearchMitts(ctx context.Context, search *models.MittSearch, limit, offset int32) ([]*models.Mitt, error) {
+	_ = ctx
+	_ = limit
+	_ = offset
+
+	r.searches = append(r.searches, search)
+	return []*models.Mitt{{Content: search.Text}}, nil
+}
+
+func (r *mockSearchRepo) SearchUsers(ctx context.Context, viewerID uuid.UUID, query string, limit, offset int32) ([]uuid.UUID, error) {
+	_ = ctx
+	_ = viewerID
+	_ = limit
+	_ = offset
+
+	var ids []uuid.UUID
+	for login, id := range r.users {
+		if strings.Contains(login, query) {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+func (r *mockSearchRepo) AutocompleteLogins(ctx context.Context, prefix string, limit int32) ([]uuid.UUID, error) {
+	_ = ctx
+
+	r.autocomplete++
+
+	// Sorted by login, so order is stable
+	var logins []string
+	for login := range r.users {
+		if strings.HasPrefix(login, prefix) {
+			logins = append(logins, login)
+		}
+	}
+	slices.Sort(logins)
+
+	var ids []uuid.UUID
+	for _, login := range logins {
+		if len(ids) < int(limit) {
+			ids = append(ids, r.users[login])
+		}
+	}
+	return ids, nil
+}
+
+// Mock autocomplete repo
+type mockAutocompleteRepo struct {
+	cache map[string][]uuid.UUID
+}
+
+func (r *mockAutocompleteRepo) SaveAutocomplete(ctx context.Context, prefix string, ids []uuid.UUID, ttl time.Duration) error {
+	_ = ctx
+	_ = ttl
+
+	if r.cache == nil {
+		r.cache = map[string][]uuid.UUID{}
+	}
+	r.cache[prefix] = slices.Clone(ids)
+	return nil
+}
+
+func (r *mockAutocompleteRepo) GetAutocomplete(ctx context.Context, prefix string) ([]uuid.UUID, bool, error) {
+	_ = ctx
+
+	ids, ok := r.cache[prefix]
+	return slices.Clone(ids), ok, nil
+}
@@ -0,0 +1,109 @@
+package search
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/misshanya/mitter/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	testUserID   = uuid.MustParse("b096376a-5fa9-4130-907a-709c67008a65")
+	testAliceID  = uuid.MustParse("38386ffe-54ac-48be-9244-a5144b41a014")
+	testAlbertID = uuid.MustParse("7d3c4b2a-19e8-4f6a-b5c4-d3e2f1a0b9c8")
+	testAlexID   = uuid.MustParse("9e8d7c6b-5a49-4382-a1b0-c9d8e7f6a5b4")
+)
+
+func TestService_SearchMitts(t *testing.T) {
+	sr := &mockSearchRepo{}
+	service := NewService(sr, &mockAutocompleteRepo{}, &mockUserRepo{}, 10, time.Minute)
+	ctx := context.Background()
+
+	mitts, err := service.SearchMitts(ctx, testUserID, "  cats from:bob ", models.SearchByRecency, 30, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, mitts, 1)
+
+	if assert.Len(t, sr.searches, 1) {
+		assert.Equal(t, testUserID, sr.searches[0].ViewerID)
+		assert.Equal(t, models.SearchByRecency, sr.searches[0].Order)
+		assert.Equal(t, "cats", sr.searches[0].Text)
+		assert.Equal(t, []string{"bob"}, sr.searches[0].From)
+	}
+
+	for _, tc := range []struct {
+		query string
+		order models.SearchOrder
+	}{
+		{"", models.SearchByRelevance},
+		{"   ", models.SearchByRelevance},
+		{"cats", "popular"},
+		{strings.Repeat("a", maxQueryLength+1), models.SearchByRelevance},
+		{"since:yesterday", models.SearchByRelevance},
+	} {
+		_, err := service.SearchMitts(ctx, testUserID, tc.query, tc.order, 30, 0)
+		if assert.NotNil(t, err, tc.query) {
+			assert.Equal(t, http.StatusBadRequest, err.Code, tc.query)
+		}
+	}
+}
+
+func TestService_SearchUsers(t *testing.T) {
+	sr := &mockSearchRepo{users: map[string]uuid.UUID{"alice": testAliceID}}
+	service := NewService(sr, &mockAutocompleteRepo{}, &mockUserRepo{}, 10, time.Minute)
+	ctx := context.Background()
+
+	// "@login" is searched as login
+	users, err := service.SearchUsers(ctx, testUserID, " @alice", 30, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assert.Len(t, users, 1) {
+		assert.Equal(t, testAliceID, users[0].ID)
+	}
+
+	for _, query := range []string{"", "@", strings.Repeat("a", maxUserQueryLength+1)} {
+		_, err := service.SearchUsers(ctx, testUserID, query, 30, 0)
+		if assert.NotNil(t, err, query) {
+			assert.Equal(t, http.StatusBadRequest, err.Code, query)
+		}
+	}
+}
+
+func TestService_Autocomplete(t *testing.T) {
+	sr := &mockSearchRepo{users: map[string]uuid.UUID{
+		"albert": testAlbertID,
+		"alex":   testAlexID,
+		"alice":  testAliceID,
+	}}
+	acr := &mockAutocompleteRepo{}
+	ur := &mockUserRepo{relationships: map[uuid.UUID]*models.Relationship{
+		testAliceID: {UserID: testAliceID, Following: true},
+		testAlexID:  {UserID: testAlexID, BlockedBy: true},
+	}}
+	service := NewService(sr, acr, ur, 10, time.Minute)
+	ctx := context.Background()
+
+	for range 2 {
+		users, err := service.Autocomplete(ctx, testUserID, "@AL")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Followed user goes first, user who blocked me is hidden
+		if assert.Len(t, users, 2) {
+			assert.Equal(t, testAliceID, users[0].ID)
+			assert.Equal(t, testAlbertID, users[1].ID)
+		}
+	}
+
+	// The second time result is taken from cache, where it's not personalized
+	assert.Equal(t, 1, sr.autocomplete)
+	assert.Equal(t, []uuid.UUID{testAlbertID, testAlexID, testAliceID}, acr.cache["al"])
+}
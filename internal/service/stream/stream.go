@@ -0,0 +1,292 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/misshanya/mitter/internal/models"
+)
+
+// New mitts are streamed to everyone, like the feed
+const feedChannel = "feed"
+
+// Separates IDs of channels in ID of event sent to client
+const eventIDSeparator = ","
+
+// How often connected client's blocks and mutes are reloaded, so changes apply without reconnect
+const hiddenRefreshInterval = time.Minute
+
+func userChannel(userID uuid.UUID) string {
+	return "user:" + userID.String()
+}
+
+type Service struct {
+	er models.EventRepository
+	ur models.UserRepository
+	sm models.StreamMetrics
+
+	replayLimit int64
+}
+
+// NewService creates stream service which replays up to replayLimit missed events per channel on reconnect
+func NewService(er models.EventRepository, ur models.UserRepository, sm models.StreamMetrics, replayLimit int64) *Service {
+	return &Service{
+		er:          er,
+		ur:          ur,
+		sm:          sm,
+		replayLimit: replayLimit,
+	}
+}
+
+func (s *Service) publish(ctx context.Context, channel string, eventType models.EventType, payload any) {
+	data, err := json.Marshal(payload)
+	if err == nil {
+		err = s.er.Publish(ctx, channel, eventType, data)
+	}
+	if err != nil {
+		slog.Error("error publishing event",
+			slog.String("type", string(eventType)),
+			slog.Any("err", err),
+		)
+	}
+}
+
+func (s *Service) PublishMitt(ctx context.Context, mitt *models.Mitt) {
+	s.publish(ctx, feedChannel, models.EventMitt, &models.MittEventData{
+		ID:         mitt.ID,
+		Author:     mitt.AuthorID,
+		AuthorName: mitt.AuthorName,
+		Content:    mitt.Content,
+		CreatedAt:  mitt.CreatedAt,
+	})
+}
+
+// PublishLikes streams likes count to everyone, as likes are visible in the feed
+func (s *Service) PublishLikes(ctx context.Context, mittID uuid.UUID, authorID uuid.UUID, likes int64) {
+	s.publish(ctx, feedChannel, models.EventLikes, &models.LikesEventData{
+		MittID: mittID,
+		Author: authorID,
+		Likes:  likes,
+	})
+}
+
+// getHidden returns set of users whose mitts aren't streamed to user
+func (s *Service) getHidden(ctx context.Context, userID uuid.UUID) (map[uuid.UUID]struct{}, error) {
+	ids, err := s.ur.GetHiddenUserIDs(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	hidden := make(map[uuid.UUID]struct{}, len(ids))
+	for _, id := range ids {
+		hidden[id] = struct{}{}
+	}
+	return hidden, nil
+}
+
+// eventAuthor returns author of mitt feed event is about
+func eventAuthor(event *models.Event) (uuid.UUID, bool) {
+	var data struct {
+		Author uuid.UUID `json:"author"`
+	}
+	if err := json.Unmarshal(event.Data, &data); err != nil {
+		return uuid.Nil, false
+	}
+	return data.Author, true
+}
+
+func (s *Service) PublishFollower(ctx context.Context, followeeID uuid.UUID, follower *models.User) {
+	s.publish(ctx, userChannel(followeeID), models.EventFollower, &models.FollowerEventData{
+		ID:    follower.ID,
+		Login: follower.Login,
+		Name:  follower.Name,
+	})
+}
+
+// parseStreamID parses Redis stream ID "<ms>-<seq>"
+func parseStreamID(id string) (ms, seq uint64, ok bool) {
+	msPart, seqPart, found := strings.Cut(id, "-")
+	if !found {
+		return 0, 0, false
+	}
+	ms, err := strconv.ParseUint(msPart, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	seq, err = strconv.ParseUint(seqPart, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return ms, seq, true
+}
+
+// streamIDAfter tells if stream ID a is after b
+func streamIDAfter(a, b string) bool {
+	aMs, aSeq, _ := parseStreamID(a)
+	bMs, bSeq, _ := parseStreamID(b)
+	return aMs > bMs || (aMs == bMs && aSeq > bSeq)
+}
+
+// parseLastEventID splits ID of the last event client received into IDs of the last events of each channel
+func parseLastEventID(lastEventID string, channels int) ([]string, bool) {
+	ids := strings.Split(lastEventID, eventIDSeparator)
+	if len(ids) != channels {
+		return nil, false
+	}
+	for _, id := range ids {
+		if _, _, ok := parseStreamID(id); !ok {
+			return nil, false
+		}
+	}
+	return ids, true
+}
+
+// Subscribe streams new mitts, likes counts and user's new followers until ctx is done.
+// Mitts of users blocked by, blocking or muted by user aren't streamed.
+// ID of every event includes positions in all channels, so client that sends it back as lastEventID
+// gets events it missed (up to replay limit per channel) and then continues with live ones
+func (s *Service) Subscribe(ctx context.Context, userID uuid.UUID, lastEventID string) (<-chan *models.Event, *models.HTTPError) {
+	channels := []string{feedChannel, userChannel(userID)}
+
+	var lastIDs []string
+	if lastEventID != "" {
+		var ok bool
+		lastIDs, ok = parseLastEventID(lastEventID, len(channels))
+		if !ok {
+			return nil, &models.HTTPError{
+				Code:    http.StatusBadRequest,
+				Message: "Invalid Last-Event-ID",
+			}
+		}
+	}
+
+	// Subscribe before looking for missed events, so nothing is lost in between
+	live, err := s.er.Subscribe(ctx, channels...)
+	if err != nil {
+		slog.Error("error subscribing to events", slog.Any("err", err))
+		return nil, &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		}
+	}
+
+	hidden, err := s.getHidden(ctx, userID)
+	if err != nil {
+		slog.Error("error getting hidden users", slog.Any("err", err))
+		return nil, &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		}
+	}
+	hiddenAt := time.Now()
+
+	var missed []*models.Event
+	if lastIDs == nil {
+		// New client starts from now
+		lastIDs = make([]string, len(channels))
+		for i, channel := range channels {
+			lastIDs[i], err = s.er.GetLastEventID(ctx, channel)
+			if err != nil {
+				break
+			}
+		}
+	} else {
+		for i, channel := range channels {
+			var events []*models.Event
+			events, err = s.er.GetEventsAfter(ctx, channel, lastIDs[i], s.replayLimit)
+			if err != nil {
+				break
+			}
+			missed = append(missed, events...)
+		}
+	}
+	if err != nil {
+		slog.Error("error getting missed events", slog.Any("err", err))
+		return nil, &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		}
+	}
+
+	channelIndex := make(map[string]int, len(channels))
+	for i, channel := range channels {
+		channelIndex[channel] = i
+	}
+
+	events := make(chan *models.Event)
+	go func() {
+		defer close(events)
+
+		s.sm.Connect()
+		defer s.sm.Disconnect()
+
+		// isHidden tells if feed event is about mitt of hidden user
+		isHidden := func(event *models.Event) bool {
+			if event.Channel != feedChannel {
+				return false
+			}
+
+			if time.Since(hiddenAt) >= hiddenRefreshInterval {
+				// Keep previous set if reload fails, it's retried with the next event
+				if reloaded, err := s.getHidden(ctx, userID); err != nil {
+					slog.Error("error getting hidden users", slog.Any("err", err))
+				} else {
+					hidden = reloaded
+					hiddenAt = time.Now()
+				}
+			}
+
+			author, ok := eventAuthor(event)
+			if !ok {
+				return false
+			}
+			_, ok = hidden[author]
+			return ok
+		}
+
+		// send returns false if client is gone
+		send := func(event *models.Event) bool {
+			i, ok := channelIndex[event.Channel]
+			// Live events may repeat missed ones
+			if !ok || !streamIDAfter(event.ID, lastIDs[i]) {
+				return true
+			}
+			// Hidden events are skipped, but count as received on resume
+			lastIDs[i] = event.ID
+			if isHidden(event) {
+				return true
+			}
+
+			select {
+			case events <- &models.Event{
+				ID:   strings.Join(lastIDs, eventIDSeparator),
+				Type: event.Type,
+				Data: event.Data,
+			}:
+				s.sm.SendEvent(event.Type)
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		for _, event := range missed {
+			if !send(event) {
+				return
+			}
+		}
+		for event := range live {
+			if !send(event) {
+				return
+			}
+		}
+	}()
+
+	return events, nil
+}
@@ -0,0 +1,101 @@
+package stream
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/misshanya/mitter/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// Tests
+func TestStreamService_Subscribe(t *testing.T) {
+	er := newMockEventRepo()
+	service := NewService(er, &mockUserRepo{}, &mockStreamMetrics{}, 100)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Published before connection, not sent to new client
+	service.PublishLikes(ctx, uuid.New(), uuid.New(), 1)
+	<-er.live
+
+	events, err := service.Subscribe(ctx, testUserID, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	service.PublishFollower(ctx, testUserID, &models.User{ID: uuid.New(), Login: "follower"})
+
+	event := <-events
+	assert.Equal(t, models.EventFollower, event.Type)
+	assert.Equal(t, "1-0,1-0", event.ID)
+	assert.Contains(t, string(event.Data), `"login":"follower"`)
+}
+
+func TestStreamService_Resume(t *testing.T) {
+	er := newMockEventRepo()
+	metrics := &mockStreamMetrics{}
+	service := NewService(er, &mockUserRepo{}, metrics, 100)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// Client received the first like and missed the second one
+	mittID, authorID := uuid.New(), uuid.New()
+	service.PublishLikes(ctx, mittID, authorID, 1)
+	service.PublishLikes(ctx, mittID, authorID, 2)
+
+	events, err := service.Subscribe(ctx, testUserID, "1-0,0-0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	event := <-events
+	assert.Equal(t, "2-0,0-0", event.ID)
+	assert.Contains(t, string(event.Data), `"likes":2`)
+
+	// Missed events come live too, they aren't sent twice
+	service.PublishMitt(ctx, &models.Mitt{ID: uuid.New(), Content: "hello"})
+	event = <-events
+	assert.Equal(t, models.EventMitt, event.Type)
+	assert.Equal(t, "3-0,0-0", event.ID)
+
+	// Stream ends when client is gone
+	cancel()
+	for range events {
+	}
+	assert.Equal(t, 2, metrics.sent)
+	assert.Equal(t, 0, metrics.connections)
+}
+
+func TestStreamService_Hidden(t *testing.T) {
+	er := newMockEventRepo()
+	hiddenID := uuid.New()
+	service := NewService(er, &mockUserRepo{hidden: []uuid.UUID{hiddenID}}, &mockStreamMetrics{}, 100)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := service.Subscribe(ctx, testUserID, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Mitt of blocked or muted user is skipped, but its position is kept
+	service.PublishMitt(ctx, &models.Mitt{ID: uuid.New(), AuthorID: hiddenID, Content: "hidden"})
+	service.PublishLikes(ctx, uuid.New(), uuid.New(), 1)
+
+	event := <-events
+	assert.Equal(t, models.EventLikes, event.Type)
+	assert.Equal(t, "2-0,0-0", event.ID)
+}
+
+func TestStreamService_InvalidLastEventID(t *testing.T) {
+	service := NewService(newMockEventRepo(), &mockUserRepo{}, &mockStreamMetrics{}, 100)
+
+	for _, id := range []string{"1-0", "a-0,1-0", "1-0,1-0,1-0"} {
+		_, err := service.Subscribe(context.Background(), testUserID, id)
+		if assert.NotNil(t, err, id) {
+			assert.Equal(t, http.StatusBadRequest, err.Code)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package suggestion
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/misshanya/mitter/internal/dataloader"
+	"github.com/misshanya/mitter/internal/models"
+)
+
+// How many users are processed per one db query while refreshing suggestions
+const refreshBatchSize = 100
+
+type Service struct {
+	ur models.UserRepository
+	sr models.SuggestionRepository
+
+	count int32
+	ttl   time.Duration
+}
+
+// NewService creates suggestion service which keeps up to count suggestions per user cached for ttl
+func NewService(ur models.UserRepository, sr models.SuggestionRepository, count int32, ttl time.Duration) *Service {
+	return &Service{
+		ur:    ur,
+		sr:    sr,
+		count: count,
+		ttl:   ttl,
+	}
+}
+
+// compute builds suggestions from the social graph and fills the rest with popular users
+func (s *Service) compute(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	ids, err := s.ur.SuggestFollows(ctx, userID, s.count)
+	if err != nil {
+		return nil, err
+	}
+
+	if int32(len(ids)) >= s.count {
+		return ids, nil
+	}
+
+	popular, err := s.ur.GetPopularUsers(ctx, userID, s.count)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[uuid.UUID]struct{}, len(ids))
+	for _, id := range ids {
+		seen[id] = struct{}{}
+	}
+	for _, id := range popular {
+		if int32(len(ids)) >= s.count {
+			break
+		}
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+func (s *Service) GetSuggestions(ctx context.Context, userID uuid.UUID, limit int32) ([]*models.User, *models.HTTPError) {
+	ids, found, err := s.sr.GetSuggestions(ctx, userID)
+	if err != nil {
+		slog.Error("error getting cached suggestions", slog.Any("err", err))
+		return nil, &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error",
+		}
+	}
+
+	if !found {
+		// Nothing precomputed yet (e.g. new user), compute it right now
+		ids, err = s.compute(ctx, userID)
+		if err != nil {
+			slog.Error("error computing suggestions", slog.Any("err", err))
+			return nil, &models.HTTPError{
+				Code:    http.StatusInternalServerError,
+				Message: "Internal Server Error",
+			}
+		}
+
+		if err := s.sr.SaveSuggestions(ctx, userID, ids, s.ttl); err != nil {
+			slog.Error("error saving suggestions", slog.Any("err", err))
+		}
+	} else if len(ids) > 0 {
+		// Cached suggestions can be stale, so drop users that were followed, blocked or muted since
+		ids, err = s.ur.FilterSuggestable(ctx, userID, ids)
+		if err != nil {
+			slog.Error("error filtering suggestions", slog.Any("err", err))
+			return nil, &models.HTTPError{
+				Code:    http.StatusInternalServerError,
+				Message: "Internal Server Error",
+			}
+		}
+	}
+
+	if int32(len(ids)) > limit {
+		ids = ids[:limit]
+	}
+
+	// Get users models from ids (with one query)
+	users, err := dataloader.Users(ctx, s.ur).LoadMany(ctx, ids)
+	if err != nil {
+		slog.Error("error getting suggestions (getting users from db)", slog.Any("err", err))
+		return nil, &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error",
+		}
+	}
+
+	return users, nil
+}
+
+// Refresh precomputes suggestions for every user and stores them in cache.
+// The lock is held for half of ttl (one refresh interval) and never released,
+// so only one instance refreshes suggestions per interval. Cached suggestions
+// live for two intervals, so they don't expire if a run is skipped
+func (s *Service) Refresh(ctx context.Context) error {
+	acquired, err := s.sr.AcquireRefreshLock(ctx, s.ttl/2)
+	if err != nil {
+		return err
+	}
+	if !acquired {
+		return nil
+	}
+
+	after := uuid.Nil
+	for {
+		userIDs, err := s.ur.ListUserIDs(ctx, after, refreshBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(userIDs) == 0 {
+			return nil
+		}
+
+		for _, userID := range userIDs {
+			ids, err := s.compute(ctx, userID)
+			if err != nil {
+				slog.Error("error computing suggestions", slog.String("userID", userID.String()), slog.Any("err", err))
+				continue
+			}
+
+			if err := s.sr.SaveSuggestions(ctx, userID, ids, s.ttl); err != nil {
+				slog.Error("error saving suggestions", slog.String("userID", userID.String()), slog.Any("err", err))
+			}
+		}
+
+		after = userIDs[len(userIDs)-1]
+	}
+}
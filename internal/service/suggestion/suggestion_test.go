@@ -0,0 +1,56 @@
+package suggestion
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// Tests
+func TestSuggestionService_GetSuggestions(t *testing.T) {
+	sr := &mockSuggestionRepo{}
+	service := NewService(&mockUserRepo{}, sr, 30, time.Hour)
+	ctx := context.Background()
+
+	users, err := service.GetSuggestions(ctx, testUserID, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Graph suggestions go first, popular users fill the rest without duplicates
+	if assert.Len(t, users, 2) {
+		assert.Equal(t, testFriendOfFriendID, users[0].ID)
+		assert.Equal(t, testPopularUserID, users[1].ID)
+	}
+
+	// Computed suggestions are cached
+	assert.Equal(t, []uuid.UUID{testFriendOfFriendID, testPopularUserID}, sr.cache[testUserID])
+
+	// Limit is applied
+	users, err = service.GetSuggestions(ctx, testUserID, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, users, 1)
+}
+
+func TestSuggestionService_Refresh(t *testing.T) {
+	sr := &mockSuggestionRepo{}
+	service := NewService(&mockUserRepo{}, sr, 30, time.Hour)
+	ctx := context.Background()
+
+	if err := service.Refresh(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, []uuid.UUID{testFriendOfFriendID, testPopularUserID}, sr.cache[testUserID])
+
+	// Another run within the same interval is skipped
+	delete(sr.cache, testUserID)
+	if err := service.Refresh(ctx); err != nil {
+		t.Fatal(err)
+	}
+	assert.NotContains(t, sr.cache, testUserID)
+}
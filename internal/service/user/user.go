@@ -3,23 +3,40 @@ package user
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/misshanya/mitter/internal/dataloader"
 	"github.com/misshanya/mitter/internal/models"
 	"github.com/misshanya/mitter/pkg/pgutil"
 	"log/slog"
 	"net/http"
+	"time"
 )
 
+// MaxRelationshipsBatch is how many users can be looked up in one relationships request
+const MaxRelationshipsBatch = 100
+
+const purgeBatchSize = 100
+
 type Service struct {
 	ur models.UserRepository
+	ar models.AuthRepository
 	um models.UserMetrics
+	nt models.Notifier
+	ep models.EventPublisher
+
+	deletionGracePeriod time.Duration
 }
 
-func NewUserService(repo models.UserRepository, metrics models.UserMetrics) *Service {
+func NewUserService(repo models.UserRepository, authRepo models.AuthRepository, metrics models.UserMetrics, notifier models.Notifier, publisher models.EventPublisher, deletionGracePeriod time.Duration) *Service {
 	return &Service{
-		ur: repo,
-		um: metrics,
+		ur:                  repo,
+		ar:                  authRepo,
+		um:                  metrics,
+		nt:                  notifier,
+		ep:                  publisher,
+		deletionGracePeriod: deletionGracePeriod,
 	}
 }
 
@@ -44,26 +61,113 @@ func (s *Service) GetUser(ctx context.Context, id uuid.UUID) (*models.User, *mod
 	return user, nil
 }
 
+// DeleteUser deactivates user and revokes all sessions,
+// account can be reactivated during grace period, after that its data is purged
 func (s *Service) DeleteUser(ctx context.Context, id uuid.UUID) *models.HTTPError {
-	// note: handle error if user not exists
-	err := s.ur.DeleteUser(ctx, id)
+	// Revoke first, so failed request can be retried
+	if err := s.ar.RevokeUserTokens(ctx, id); err != nil {
+		slog.Error("error revoking user tokens", slog.Any("err", err))
+		return &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error",
+		}
+	}
+
+	deactivated, err := s.ur.DeactivateUser(ctx, id)
 	if err != nil {
-		slog.Error("error deleting user", slog.Any("err", err))
+		slog.Error("error deactivating user", slog.Any("err", err))
 		return &models.HTTPError{
 			Code:    http.StatusInternalServerError,
 			Message: "Internal Server Error",
 		}
 	}
 
+	// Reject tokens which weren't revoked. Mark is set even if user was already deactivated, so failed request can be retried
+	if err := s.ar.SetUserDeactivated(ctx, id, true); err != nil {
+		slog.Error("error marking user as deactivated", slog.Any("err", err))
+		return &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error",
+		}
+	}
+
+	if !deactivated {
+		return &models.HTTPError{
+			Code:    http.StatusNotFound,
+			Message: "User not found",
+		}
+	}
+
 	// Update metrics
 	go s.um.DeleteUser()
 
 	return nil
 }
 
+// PurgeDeactivated deletes data of users deactivated longer than grace period
+func (s *Service) PurgeDeactivated(ctx context.Context) error {
+	var total int
+	for {
+		ids, err := s.ur.GetUsersToPurge(ctx, s.deletionGracePeriod, purgeBatchSize)
+		if err != nil {
+			slog.Error("error getting users to purge", slog.Any("err", err))
+			return err
+		}
+
+		for _, id := range ids {
+			if err := s.purgeUser(ctx, id); err != nil {
+				slog.Error("error purging user", slog.String("userID", id.String()), slog.Any("err", err))
+				return err
+			}
+		}
+		total += len(ids)
+
+		if len(ids) < purgeBatchSize {
+			break
+		}
+	}
+
+	if total > 0 {
+		slog.Info("purged deactivated users", slog.Int("count", total))
+	}
+	return nil
+}
+
+// purgeUser deletes user's data in small batches, so other queries aren't blocked for long
+func (s *Service) purgeUser(ctx context.Context, id uuid.UUID) error {
+	steps := []struct {
+		name   string
+		delete func(ctx context.Context, id uuid.UUID, batchSize int32) (int64, error)
+	}{
+		{"likes", s.ur.DeleteUserLikes},
+		{"follows", s.ur.DeleteUserFollows},
+		{"mitts", s.ur.DeleteUserMitts},
+	}
+
+	for _, step := range steps {
+		for {
+			deleted, err := step.delete(ctx, id, purgeBatchSize)
+			if err != nil {
+				return fmt.Errorf("deleting %s: %w", step.name, err)
+			}
+			if deleted < purgeBatchSize {
+				break
+			}
+		}
+	}
+
+	return s.ur.DeleteUser(ctx, id)
+}
+
 func (s *Service) UpdateUser(ctx context.Context, id uuid.UUID, user *models.UserUpdate) *models.HTTPError {
 	err := s.ur.UpdateUser(ctx, id, user)
 	if err != nil {
+		if errors.Is(err, models.ErrVersionConflict) {
+			return &models.HTTPError{
+				Code:    http.StatusPreconditionFailed,
+				Message: "User was modified, fetch it again",
+			}
+		}
 		slog.Error("error updating user", slog.Any("err", err))
 		return &models.HTTPError{
 			Code:    http.StatusInternalServerError,
@@ -92,6 +196,22 @@ func (s *Service) FollowUser(ctx context.Context, followerID uuid.UUID, followee
 		}
 	}
 
+	// Check if one of users blocked another
+	isBlocked, err := s.ur.IsBlockedBetween(ctx, followerID, followeeID)
+	if err != nil {
+		slog.Error("error checking block", slog.Any("err", err))
+		return &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error",
+		}
+	}
+	if isBlocked {
+		return &models.HTTPError{
+			Code:    http.StatusForbidden,
+			Message: "You are not allowed to do this",
+		}
+	}
+
 	err = s.ur.FollowUser(ctx, followerID, followeeID)
 	if err != nil {
 		if pgutil.IsUniqueViolation(err) {
@@ -107,6 +227,19 @@ func (s *Service) FollowUser(ctx context.Context, followerID uuid.UUID, followee
 			Message: "Internal Server Error",
 		}
 	}
+
+	s.nt.Notify(ctx, &models.NotificationEvent{
+		Type:    models.NotificationFollow,
+		ActorID: followerID,
+		UserID:  &followeeID,
+	})
+
+	if follower, err := dataloader.Users(ctx, s.ur).Load(ctx, followerID); err != nil {
+		slog.Error("error getting follower", slog.Any("err", err))
+	} else if follower != nil {
+		s.ep.PublishFollower(ctx, followeeID, follower)
+	}
+
 	return nil
 }
 
@@ -133,18 +266,14 @@ func (s *Service) GetUserFollows(ctx context.Context, followerID uuid.UUID, limi
 		}
 	}
 
-	// Get users models from ids
-	users := make([]*models.User, len(usersIDs))
-	for i, id := range usersIDs {
-		user, err := s.ur.GetUserByID(ctx, id)
-		if err != nil {
-			slog.Error("error getting user follows (getting user from db)", slog.Any("err", err))
-			return nil, &models.HTTPError{
-				Code:    http.StatusInternalServerError,
-				Message: "Internal Server Error",
-			}
+	// Get users models from ids (with one query)
+	users, err := dataloader.Users(ctx, s.ur).LoadMany(ctx, usersIDs)
+	if err != nil {
+		slog.Error("error getting user follows (getting users from db)", slog.Any("err", err))
+		return nil, &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error",
 		}
-		users[i] = user
 	}
 
 	return users, nil
@@ -161,18 +290,14 @@ func (s *Service) GetUserFollowers(ctx context.Context, followeeID uuid.UUID, li
 		}
 	}
 
-	// Get users models from ids
-	users := make([]*models.User, len(usersIDs))
-	for i, id := range usersIDs {
-		user, err := s.ur.GetUserByID(ctx, id)
-		if err != nil {
-			slog.Error("error getting user followers (getting user from db)", slog.Any("err", err))
-			return nil, &models.HTTPError{
-				Code:    http.StatusInternalServerError,
-				Message: "Internal Server Error",
-			}
+	// Get users models from ids (with one query)
+	users, err := dataloader.Users(ctx, s.ur).LoadMany(ctx, usersIDs)
+	if err != nil {
+		slog.Error("error getting user followers (getting users from db)", slog.Any("err", err))
+		return nil, &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error",
 		}
-		users[i] = user
 	}
 
 	return users, nil
@@ -189,19 +314,208 @@ func (s *Service) GetUserFriends(ctx context.Context, userID uuid.UUID, limit, o
 		}
 	}
 
-	// Get users models from ids
-	users := make([]*models.User, len(usersIDs))
-	for i, id := range usersIDs {
-		user, err := s.ur.GetUserByID(ctx, id)
-		if err != nil {
-			slog.Error("error getting user friends (getting user from db)", slog.Any("err", err))
-			return nil, &models.HTTPError{
-				Code:    http.StatusInternalServerError,
-				Message: "Internal Server Error",
-			}
+	// Get users models from ids (with one query)
+	users, err := dataloader.Users(ctx, s.ur).LoadMany(ctx, usersIDs)
+	if err != nil {
+		slog.Error("error getting user friends (getting users from db)", slog.Any("err", err))
+		return nil, &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error",
+		}
+	}
+
+	return users, nil
+}
+
+// Blocks and mutes
+
+func (s *Service) BlockUser(ctx context.Context, blockerID uuid.UUID, blockedID uuid.UUID) *models.HTTPError {
+	if blockerID == blockedID {
+		return &models.HTTPError{
+			Code:    http.StatusBadRequest,
+			Message: "You can't block yourself",
+		}
+	}
+
+	// Check if user exists
+	if _, httpErr := s.GetUser(ctx, blockedID); httpErr != nil {
+		return httpErr
+	}
+
+	if err := s.ur.BlockUser(ctx, blockerID, blockedID); err != nil {
+		slog.Error("error blocking user", slog.Any("err", err))
+		return &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error",
+		}
+	}
+	return nil
+}
+
+func (s *Service) UnblockUser(ctx context.Context, blockerID uuid.UUID, blockedID uuid.UUID) *models.HTTPError {
+	if err := s.ur.UnblockUser(ctx, blockerID, blockedID); err != nil {
+		slog.Error("error unblocking user", slog.Any("err", err))
+		return &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error",
+		}
+	}
+	return nil
+}
+
+func (s *Service) MuteUser(ctx context.Context, muterID uuid.UUID, mutedID uuid.UUID) *models.HTTPError {
+	if muterID == mutedID {
+		return &models.HTTPError{
+			Code:    http.StatusBadRequest,
+			Message: "You can't mute yourself",
+		}
+	}
+
+	// Check if user exists
+	if _, httpErr := s.GetUser(ctx, mutedID); httpErr != nil {
+		return httpErr
+	}
+
+	if err := s.ur.MuteUser(ctx, muterID, mutedID); err != nil {
+		slog.Error("error muting user", slog.Any("err", err))
+		return &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error",
+		}
+	}
+	return nil
+}
+
+func (s *Service) UnmuteUser(ctx context.Context, muterID uuid.UUID, mutedID uuid.UUID) *models.HTTPError {
+	if err := s.ur.UnmuteUser(ctx, muterID, mutedID); err != nil {
+		slog.Error("error unmuting user", slog.Any("err", err))
+		return &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error",
+		}
+	}
+	return nil
+}
+
+// Social graph of any user
+
+// checkGraphAccess checks if viewer can see follows, followers and friends of the user
+func (s *Service) checkGraphAccess(ctx context.Context, viewerID uuid.UUID, userID uuid.UUID) *models.HTTPError {
+	if viewerID == userID {
+		return nil
+	}
+
+	user, httpErr := s.GetUser(ctx, userID)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	isBlocked, err := s.ur.IsBlockedBetween(ctx, viewerID, userID)
+	if err != nil {
+		slog.Error("error checking block", slog.Any("err", err))
+		return &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error",
+		}
+	}
+	if isBlocked {
+		return &models.HTTPError{
+			Code:    http.StatusForbidden,
+			Message: "You are not allowed to do this",
+		}
+	}
+
+	if !user.IsPrivate {
+		return nil
+	}
+
+	// Private account's graph is visible only to its followers
+	isFollowing, err := s.ur.IsFollowing(ctx, viewerID, userID)
+	if err != nil {
+		slog.Error("error checking follow", slog.Any("err", err))
+		return &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error",
+		}
+	}
+	if !isFollowing {
+		return &models.HTTPError{
+			Code:    http.StatusForbidden,
+			Message: "This account is private",
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) GetFollowsOf(ctx context.Context, viewerID uuid.UUID, userID uuid.UUID, limit, offset int32) ([]*models.User, *models.HTTPError) {
+	if httpErr := s.checkGraphAccess(ctx, viewerID, userID); httpErr != nil {
+		return nil, httpErr
+	}
+
+	return s.GetUserFollows(ctx, userID, limit, offset)
+}
+
+func (s *Service) GetFollowersOf(ctx context.Context, viewerID uuid.UUID, userID uuid.UUID, limit, offset int32) ([]*models.User, *models.HTTPError) {
+	if httpErr := s.checkGraphAccess(ctx, viewerID, userID); httpErr != nil {
+		return nil, httpErr
+	}
+
+	return s.GetUserFollowers(ctx, userID, limit, offset)
+}
+
+func (s *Service) GetFriendsOf(ctx context.Context, viewerID uuid.UUID, userID uuid.UUID, limit, offset int32) ([]*models.User, *models.HTTPError) {
+	if httpErr := s.checkGraphAccess(ctx, viewerID, userID); httpErr != nil {
+		return nil, httpErr
+	}
+
+	return s.GetUserFriends(ctx, userID, limit, offset)
+}
+
+// GetKnownFollowers returns followers of the user that are followed by viewer
+func (s *Service) GetKnownFollowers(ctx context.Context, viewerID uuid.UUID, userID uuid.UUID, limit, offset int32) ([]*models.User, *models.HTTPError) {
+	if httpErr := s.checkGraphAccess(ctx, viewerID, userID); httpErr != nil {
+		return nil, httpErr
+	}
+
+	usersIDs, err := s.ur.GetKnownFollowers(ctx, viewerID, userID, limit, offset)
+	if err != nil {
+		slog.Error("error getting known followers", slog.Any("err", err))
+		return nil, &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error",
+		}
+	}
+
+	// Get users models from ids (with one query)
+	users, err := dataloader.Users(ctx, s.ur).LoadMany(ctx, usersIDs)
+	if err != nil {
+		slog.Error("error getting known followers (getting users from db)", slog.Any("err", err))
+		return nil, &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error",
 		}
-		users[i] = user
 	}
 
 	return users, nil
 }
+
+func (s *Service) GetRelationships(ctx context.Context, userID uuid.UUID, ids []uuid.UUID) ([]*models.Relationship, *models.HTTPError) {
+	if len(ids) > MaxRelationshipsBatch {
+		return nil, &models.HTTPError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Too many ids, max is %d", MaxRelationshipsBatch),
+		}
+	}
+
+	relationships, err := s.ur.GetRelationships(ctx, userID, ids)
+	if err != nil {
+		slog.Error("error getting relationships", slog.Any("err", err))
+		return nil, &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error",
+		}
+	}
+
+	return relationships, nil
+}
@@ -2,6 +2,7 @@ package user
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/misshanya/mitter/internal/models"
@@ -25,8 +26,55 @@ var (
 	testUser2ID = uuid.MustParse("38386ffe-54ac-48be-9244-a5144b41a014")
 )
 
+// Mock Auth repo
+type mockAuthRepo struct {
+	revoked     []uuid.UUID
+	deactivated map[uuid.UUID]bool
+}
+
+func (r *mockAuthRepo) SaveToken(ctx context.Context, token *models.Token) error {
+	_ = ctx
+	_ = token
+
+	return nil
+}
+
+func (r *mockAuthRepo) GetUserIDByToken(ctx context.Context, token string) (uuid.UUID, error) {
+	_ = ctx
+	_ = token
+
+	return testUserID, nil
+}
+
+func (r *mockAuthRepo) RevokeUserTokens(ctx context.Context, userID uuid.UUID) error {
+	_ = ctx
+
+	r.revoked = append(r.revoked, userID)
+	return nil
+}
+
+func (r *mockAuthRepo) DeleteToken(ctx context.Context, userID uuid.UUID, token string) error {
+	_ = ctx
+	_ = userID
+	_ = token
+
+	return nil
+}
+
+func (r *mockAuthRepo) SetUserDeactivated(ctx context.Context, userID uuid.UUID, deactivated bool) error {
+	_ = ctx
+
+	if r.deactivated == nil {
+		r.deactivated = make(map[uuid.UUID]bool)
+	}
+	r.deactivated[userID] = deactivated
+	return nil
+}
+
 // Mock User repo
-type mockUserRepo struct{}
+type mockUserRepo struct {
+	purged []uuid.UUID
+}
 
 func (r *mockUserRepo) CreateUser(ctx context.Context, user *models.UserCreate) (uuid.UUID, error) {
 	_ = ctx
@@ -49,10 +97,73 @@ func (r *mockUserRepo) GetUserByID(ctx context.Context, id uuid.UUID) (*models.U
 	return &testUser, nil
 }
 
-func (r *mockUserRepo) DeleteUser(ctx context.Context, id uuid.UUID) error {
+func (r *mockUserRepo) GetUsersByIDs(ctx context.Context, ids []uuid.UUID) ([]*models.User, error) {
+	_ = ctx
+	_ = ids
+
+	users := make([]*models.User, 0, len(ids))
+	for _, id := range ids {
+		switch id {
+		case testUserID:
+			users = append(users, &testUser)
+		case testUser2ID:
+			users = append(users, &testUser2)
+		}
+	}
+	return users, nil
+}
+
+func (r *mockUserRepo) DeactivateUser(ctx context.Context, id uuid.UUID) (bool, error) {
+	_ = ctx
+	_ = id
+
+	return true, nil
+}
+
+func (r *mockUserRepo) ReactivateUser(ctx context.Context, id uuid.UUID, gracePeriod time.Duration) (bool, error) {
 	_ = ctx
 	_ = id
+	_ = gracePeriod
 
+	return true, nil
+}
+
+func (r *mockUserRepo) GetUsersToPurge(ctx context.Context, gracePeriod time.Duration, limit int32) ([]uuid.UUID, error) {
+	_ = ctx
+	_ = gracePeriod
+	_ = limit
+
+	return []uuid.UUID{testUser2ID}, nil
+}
+
+func (r *mockUserRepo) DeleteUserLikes(ctx context.Context, id uuid.UUID, batchSize int32) (int64, error) {
+	_ = ctx
+	_ = id
+	_ = batchSize
+
+	return 0, nil
+}
+
+func (r *mockUserRepo) DeleteUserFollows(ctx context.Context, id uuid.UUID, batchSize int32) (int64, error) {
+	_ = ctx
+	_ = id
+	_ = batchSize
+
+	return 0, nil
+}
+
+func (r *mockUserRepo) DeleteUserMitts(ctx context.Context, id uuid.UUID, batchSize int32) (int64, error) {
+	_ = ctx
+	_ = id
+	_ = batchSize
+
+	return 0, nil
+}
+
+func (r *mockUserRepo) DeleteUser(ctx context.Context, id uuid.UUID) error {
+	_ = ctx
+
+	r.purged = append(r.purged, id)
 	return nil
 }
 
@@ -122,6 +233,115 @@ func (r *mockUserRepo) GetUserFriends(ctx context.Context, userID uuid.UUID, lim
 	return []uuid.UUID{testUserID}, nil
 }
 
+func (r *mockUserRepo) ListUserIDs(ctx context.Context, after uuid.UUID, limit int32) ([]uuid.UUID, error) {
+	_ = ctx
+	_ = after
+	_ = limit
+
+	return []uuid.UUID{}, nil
+}
+
+func (r *mockUserRepo) BlockUser(ctx context.Context, blockerID uuid.UUID, blockedID uuid.UUID) error {
+	_ = ctx
+	_ = blockerID
+	_ = blockedID
+
+	return nil
+}
+
+func (r *mockUserRepo) UnblockUser(ctx context.Context, blockerID uuid.UUID, blockedID uuid.UUID) error {
+	_ = ctx
+	_ = blockerID
+	_ = blockedID
+
+	return nil
+}
+
+func (r *mockUserRepo) IsBlockedBetween(ctx context.Context, firstID uuid.UUID, secondID uuid.UUID) (bool, error) {
+	_ = ctx
+	_ = firstID
+	_ = secondID
+
+	return false, nil
+}
+
+func (r *mockUserRepo) MuteUser(ctx context.Context, muterID uuid.UUID, mutedID uuid.UUID) error {
+	_ = ctx
+	_ = muterID
+	_ = mutedID
+
+	return nil
+}
+
+func (r *mockUserRepo) UnmuteUser(ctx context.Context, muterID uuid.UUID, mutedID uuid.UUID) error {
+	_ = ctx
+	_ = muterID
+	_ = mutedID
+
+	return nil
+}
+
+func (r *mockUserRepo) GetHiddenUserIDs(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	_ = ctx
+	_ = userID
+
+	return nil, nil
+}
+
+func (r *mockUserRepo) SuggestFollows(ctx context.Context, userID uuid.UUID, limit int32) ([]uuid.UUID, error) {
+	_ = ctx
+	_ = userID
+	_ = limit
+
+	return []uuid.UUID{}, nil
+}
+
+func (r *mockUserRepo) GetPopularUsers(ctx context.Context, userID uuid.UUID, limit int32) ([]uuid.UUID, error) {
+	_ = ctx
+	_ = userID
+	_ = limit
+
+	return []uuid.UUID{}, nil
+}
+
+func (r *mockUserRepo) FilterSuggestable(ctx context.Context, userID uuid.UUID, candidateIDs []uuid.UUID) ([]uuid.UUID, error) {
+	_ = ctx
+	_ = userID
+	_ = candidateIDs
+
+	return candidateIDs, nil
+}
+
+func (r *mockUserRepo) IsFollowing(ctx context.Context, followerID uuid.UUID, followeeID uuid.UUID) (bool, error) {
+	_ = ctx
+	_ = followerID
+	_ = followeeID
+
+	return false, nil
+}
+
+func (r *mockUserRepo) GetKnownFollowers(ctx context.Context, viewerID uuid.UUID, userID uuid.UUID, limit, offset int32) ([]uuid.UUID, error) {
+	_ = ctx
+	_ = viewerID
+	_ = userID
+	_ = limit
+	_ = offset
+
+	return []uuid.UUID{testUserID}, nil
+}
+
+func (r *mockUserRepo) GetRelationships(ctx context.Context, userID uuid.UUID, ids []uuid.UUID) ([]*models.Relationship, error) {
+	_ = ctx
+	_ = userID
+	_ = ids
+
+	relationships := make([]*models.Relationship, len(ids))
+	for i, id := range ids {
+		relationships[i] = &models.Relationship{UserID: id, Following: true}
+	}
+	return relationships, nil
+}
+
 // Mock user metrics
 type mockUserMetrics struct {
 	FakeUsersCount int
@@ -134,3 +354,42 @@ func (m *mockUserMetrics) AddUser() {
 func (m *mockUserMetrics) DeleteUser() {
 	m.FakeUsersCount--
 }
+
+// Mock notifier
+type mockNotifier struct {
+	events []*models.NotificationEvent
+}
+
+func (n *mockNotifier) Notify(ctx context.Context, event *models.NotificationEvent) {
+	_ = ctx
+
+	n.events = append(n.events, event)
+}
+
+// Mock event publisher
+type mockEventPublisher struct {
+	mitts     []*models.Mitt
+	likes     []int64
+	followers []*models.User
+}
+
+func (p *mockEventPublisher) PublishMitt(ctx context.Context, mitt *models.Mitt) {
+	_ = ctx
+
+	p.mitts = append(p.mitts, mitt)
+}
+
+func (p *mockEventPublisher) PublishLikes(ctx context.Context, mittID uuid.UUID, authorID uuid.UUID, likes int64) {
+	_ = ctx
+	_ = mittID
+	_ = authorID
+
+	p.likes = append(p.likes, likes)
+}
+
+func (p *mockEventPublisher) PublishFollower(ctx context.Context, followeeID uuid.UUID, follower *models.User) {
+	_ = ctx
+	_ = followeeID
+
+	p.followers = append(p.followers, follower)
+}
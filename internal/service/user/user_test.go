@@ -2,14 +2,17 @@ package user
 
 import (
 	"context"
+	"github.com/google/uuid"
 	"github.com/misshanya/mitter/internal/models"
 	"github.com/stretchr/testify/assert"
+	"net/http"
 	"testing"
+	"time"
 )
 
 // Tests
 func TestUserService_GetUser(t *testing.T) {
-	service := NewUserService(&mockUserRepo{}, &mockUserMetrics{})
+	service := NewUserService(&mockUserRepo{}, &mockAuthRepo{}, &mockUserMetrics{}, &mockNotifier{}, &mockEventPublisher{}, time.Hour)
 	ctx := context.Background()
 
 	user, err := service.GetUser(ctx, testUserID)
@@ -21,17 +24,35 @@ func TestUserService_GetUser(t *testing.T) {
 }
 
 func TestUserService_DeleteUser(t *testing.T) {
-	service := NewUserService(&mockUserRepo{}, &mockUserMetrics{})
+	ar := &mockAuthRepo{}
+	service := NewUserService(&mockUserRepo{}, ar, &mockUserMetrics{}, &mockNotifier{}, &mockEventPublisher{}, time.Hour)
 	ctx := context.Background()
 
 	err := service.DeleteUser(ctx, testUserID)
 	if err != nil {
 		t.Fatal(err)
 	}
+
+	// Sessions are revoked
+	assert.Equal(t, []uuid.UUID{testUserID}, ar.revoked)
+	// Tokens which weren't revoked are rejected too
+	assert.True(t, ar.deactivated[testUserID])
+}
+
+func TestUserService_PurgeDeactivated(t *testing.T) {
+	ur := &mockUserRepo{}
+	service := NewUserService(ur, &mockAuthRepo{}, &mockUserMetrics{}, &mockNotifier{}, &mockEventPublisher{}, time.Hour)
+	ctx := context.Background()
+
+	if err := service.PurgeDeactivated(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, []uuid.UUID{testUser2ID}, ur.purged)
 }
 
 func TestUserService_UpdateUser(t *testing.T) {
-	service := NewUserService(&mockUserRepo{}, &mockUserMetrics{})
+	service := NewUserService(&mockUserRepo{}, &mockAuthRepo{}, &mockUserMetrics{}, &mockNotifier{}, &mockEventPublisher{}, time.Hour)
 	ctx := context.Background()
 
 	newName := "new name"
@@ -45,17 +66,29 @@ func TestUserService_UpdateUser(t *testing.T) {
 }
 
 func TestUserService_FollowUser(t *testing.T) {
-	service := NewUserService(&mockUserRepo{}, &mockUserMetrics{})
+	nt := &mockNotifier{}
+	ep := &mockEventPublisher{}
+	service := NewUserService(&mockUserRepo{}, &mockAuthRepo{}, &mockUserMetrics{}, nt, ep, time.Hour)
 	ctx := context.Background()
 
 	err := service.FollowUser(ctx, testUserID, testUser2ID)
 	if err != nil {
 		t.Fatal(err)
 	}
+
+	// Followee is notified
+	if len(nt.events) != 1 || nt.events[0].Type != models.NotificationFollow || *nt.events[0].UserID != testUser2ID {
+		t.Fatalf("expected follow notification, got %v", nt.events)
+	}
+
+	// Followee gets follower in stream
+	if len(ep.followers) != 1 || ep.followers[0].ID != testUserID {
+		t.Fatalf("expected follower to be published, got %v", ep.followers)
+	}
 }
 
 func TestUserService_UnfollowUser(t *testing.T) {
-	service := NewUserService(&mockUserRepo{}, &mockUserMetrics{})
+	service := NewUserService(&mockUserRepo{}, &mockAuthRepo{}, &mockUserMetrics{}, &mockNotifier{}, &mockEventPublisher{}, time.Hour)
 	ctx := context.Background()
 
 	err := service.UnfollowUser(ctx, testUserID, testUser2ID)
@@ -65,7 +98,7 @@ func TestUserService_UnfollowUser(t *testing.T) {
 }
 
 func TestUserService_GetUserFollows(t *testing.T) {
-	service := NewUserService(&mockUserRepo{}, &mockUserMetrics{})
+	service := NewUserService(&mockUserRepo{}, &mockAuthRepo{}, &mockUserMetrics{}, &mockNotifier{}, &mockEventPublisher{}, time.Hour)
 	ctx := context.Background()
 
 	follows, err := service.GetUserFollows(ctx, testUserID, 30, 0)
@@ -77,7 +110,7 @@ func TestUserService_GetUserFollows(t *testing.T) {
 }
 
 func TestUserService_GetUserFollowers(t *testing.T) {
-	service := NewUserService(&mockUserRepo{}, &mockUserMetrics{})
+	service := NewUserService(&mockUserRepo{}, &mockAuthRepo{}, &mockUserMetrics{}, &mockNotifier{}, &mockEventPublisher{}, time.Hour)
 	ctx := context.Background()
 
 	followers, err := service.GetUserFollowers(ctx, testUserID, 30, 0)
@@ -87,3 +120,75 @@ func TestUserService_GetUserFollowers(t *testing.T) {
 
 	assert.Equal(t, []*models.User{&testUser}, followers)
 }
+
+func TestUserService_BlockUser(t *testing.T) {
+	service := NewUserService(&mockUserRepo{}, &mockAuthRepo{}, &mockUserMetrics{}, &mockNotifier{}, &mockEventPublisher{}, time.Hour)
+	ctx := context.Background()
+
+	err := service.BlockUser(ctx, testUserID, testUser2ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// User can't block himself
+	err = service.BlockUser(ctx, testUserID, testUserID)
+	if assert.NotNil(t, err) {
+		assert.Equal(t, http.StatusBadRequest, err.Code)
+	}
+}
+
+func TestUserService_MuteUser(t *testing.T) {
+	service := NewUserService(&mockUserRepo{}, &mockAuthRepo{}, &mockUserMetrics{}, &mockNotifier{}, &mockEventPublisher{}, time.Hour)
+	ctx := context.Background()
+
+	err := service.MuteUser(ctx, testUserID, testUser2ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUserService_GetFollowersOf(t *testing.T) {
+	service := NewUserService(&mockUserRepo{}, &mockAuthRepo{}, &mockUserMetrics{}, &mockNotifier{}, &mockEventPublisher{}, time.Hour)
+	ctx := context.Background()
+
+	followers, err := service.GetFollowersOf(ctx, testUser2ID, testUserID, 30, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, []*models.User{&testUser}, followers)
+}
+
+func TestUserService_GetKnownFollowers(t *testing.T) {
+	service := NewUserService(&mockUserRepo{}, &mockAuthRepo{}, &mockUserMetrics{}, &mockNotifier{}, &mockEventPublisher{}, time.Hour)
+	ctx := context.Background()
+
+	known, err := service.GetKnownFollowers(ctx, testUser2ID, testUserID, 30, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, []*models.User{&testUser}, known)
+}
+
+func TestUserService_GetRelationships(t *testing.T) {
+	service := NewUserService(&mockUserRepo{}, &mockAuthRepo{}, &mockUserMetrics{}, &mockNotifier{}, &mockEventPublisher{}, time.Hour)
+	ctx := context.Background()
+
+	relationships, err := service.GetRelationships(ctx, testUserID, []uuid.UUID{testUser2ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if assert.Len(t, relationships, 1) {
+		assert.Equal(t, testUser2ID, relationships[0].UserID)
+		assert.True(t, relationships[0].Following)
+	}
+
+	// Too many ids
+	ids := make([]uuid.UUID, MaxRelationshipsBatch+1)
+	_, err = service.GetRelationships(ctx, testUserID, ids)
+	if assert.NotNil(t, err) {
+		assert.Equal(t, http.StatusBadRequest, err.Code)
+	}
+}
@@ -0,0 +1,437 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/misshanya/mitter/internal/models"
+)
+
+const (
+	// How many deliveries are attempted at once
+	deliveryBatchSize = 20
+	maxURLLength      = 2048
+	// Only the beginning of response is read, the rest is dropped
+	maxResponseSize = 64 * 1024
+	maxErrorLength  = 500
+)
+
+// Headers of delivery request
+const (
+	HeaderEvent     = "X-Mitter-Event"
+	HeaderDelivery  = "X-Mitter-Delivery"
+	HeaderSignature = "X-Mitter-Signature"
+)
+
+var errPrivateAddress = errors.New("webhook address is not public")
+
+type Service struct {
+	wr     models.WebhookRepository
+	client *http.Client
+
+	timeout      time.Duration
+	maxAttempts  int32
+	backoff      time.Duration
+	maxBackoff   time.Duration
+	allowPrivate bool
+}
+
+// NewService creates webhook service which waits for response for timeout and makes up to maxAttempts attempts
+// of every delivery. Retries are delayed by backoff that doubles after every attempt up to maxBackoff.
+// Webhooks can reach loopback, private and link-local addresses only if allowPrivate is set, for local development
+func NewService(wr models.WebhookRepository, timeout time.Duration, maxAttempts int32, backoff, maxBackoff time.Duration, allowPrivate bool) *Service {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// Proxy would connect to any address on our behalf
+	transport.Proxy = nil
+	dialer := &net.Dialer{Timeout: timeout}
+	transport.DialContext = dialer.DialContext
+	if !allowPrivate {
+		transport.DialContext = dialPublic(dialer)
+	}
+
+	return &Service{
+		wr: wr,
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   timeout,
+			// Redirect could lead to any address, it's a response like any other
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		timeout:      timeout,
+		maxAttempts:  maxAttempts,
+		backoff:      backoff,
+		maxBackoff:   maxBackoff,
+		allowPrivate: allowPrivate,
+	}
+}
+
+// nonPublicPrefixes are special-purpose ranges webhooks must not reach: local networks, reserved,
+// documentation and benchmark ranges and multicast. IPv4-mapped IPv6 addresses are checked as IPv4
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("10.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("127.0.0.0/8"),
+	netip.MustParsePrefix("169.254.0.0/16"),
+	netip.MustParsePrefix("172.16.0.0/12"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("192.0.2.0/24"),
+	netip.MustParsePrefix("192.168.0.0/16"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("198.51.100.0/24"),
+	netip.MustParsePrefix("203.0.113.0/24"),
+	netip.MustParsePrefix("224.0.0.0/4"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("::/128"),
+	netip.MustParsePrefix("::1/128"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+	netip.MustParsePrefix("64:ff9b:1::/48"),
+	netip.MustParsePrefix("100::/64"),
+	netip.MustParsePrefix("2001:db8::/32"),
+	netip.MustParsePrefix("fc00::/7"),
+	netip.MustParsePrefix("fe80::/10"),
+	netip.MustParsePrefix("ff00::/8"),
+}
+
+// publicIP tells if ip isn't in any of nonPublicPrefixes
+func publicIP(ip netip.Addr) bool {
+	ip = ip.Unmap().WithZone("")
+	if !ip.IsValid() {
+		return false
+	}
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// dialPublic resolves host and connects only if all its addresses are public.
+// Checked addresses are dialed directly, so host can't be resolved to another one in between
+func dialPublic(dialer *net.Dialer) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+
+		ips, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+		if err != nil {
+			return nil, err
+		}
+		for _, ip := range ips {
+			if !publicIP(ip) {
+				return nil, errPrivateAddress
+			}
+		}
+
+		err = errPrivateAddress
+		for _, ip := range ips {
+			var conn net.Conn
+			conn, err = dialer.DialContext(ctx, network, net.JoinHostPort(ip.Unmap().String(), port))
+			if err == nil {
+				return conn, nil
+			}
+		}
+		return nil, err
+	}
+}
+
+// Sign returns signature of payload sent in X-Mitter-Signature header: "sha256=" and hex of HMAC-SHA256 with webhook secret
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func generateSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// validURL checks URL of webhook. Host names are resolved on every delivery,
+// only addresses and localhost are rejected here when private addresses aren't allowed
+func (s *Service) validURL(rawURL string) bool {
+	if len(rawURL) > maxURLLength {
+		return false
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return false
+	}
+	if s.allowPrivate {
+		return true
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return false
+	}
+	if ip, err := netip.ParseAddr(host); err == nil && !publicIP(ip) {
+		return false
+	}
+	return true
+}
+
+// truncate cuts s to at most n bytes without splitting runes
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+// CreateWebhook registers webhook, its secret is generated
+func (s *Service) CreateWebhook(ctx context.Context, userID uuid.UUID, webhook *models.WebhookCreate) (*models.Webhook, *models.HTTPError) {
+	if !s.validURL(webhook.URL) {
+		return nil, &models.HTTPError{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid webhook URL",
+		}
+	}
+
+	events := make([]models.NotificationType, 0, len(webhook.Events))
+	for _, t := range webhook.Events {
+		if !slices.Contains(models.NotificationTypes, t) {
+			return nil, &models.HTTPError{
+				Code:    http.StatusBadRequest,
+				Message: "Unknown event type",
+			}
+		}
+		if !slices.Contains(events, t) {
+			events = append(events, t)
+		}
+	}
+	if len(events) == 0 {
+		return nil, &models.HTTPError{
+			Code:    http.StatusBadRequest,
+			Message: "At least one event type is required",
+		}
+	}
+	webhook.Events = events
+
+	secret, err := generateSecret()
+	if err != nil {
+		slog.Error("error generating webhook secret", slog.Any("err", err))
+		return nil, &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		}
+	}
+
+	created, err := s.wr.CreateWebhook(ctx, userID, secret, webhook)
+	if err != nil {
+		slog.Error("error creating webhook", slog.Any("err", err))
+		return nil, &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		}
+	}
+
+	return created, nil
+}
+
+func (s *Service) GetWebhooks(ctx context.Context, userID uuid.UUID) ([]*models.Webhook, *models.HTTPError) {
+	webhooks, err := s.wr.GetUserWebhooks(ctx, userID)
+	if err != nil {
+		slog.Error("error getting webhooks", slog.Any("err", err))
+		return nil, &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		}
+	}
+	return webhooks, nil
+}
+
+func (s *Service) DeleteWebhook(ctx context.Context, userID uuid.UUID, id uuid.UUID) *models.HTTPError {
+	deleted, err := s.wr.DeleteWebhook(ctx, userID, id)
+	if err != nil {
+		slog.Error("error deleting webhook", slog.Any("err", err))
+		return &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		}
+	}
+	if !deleted {
+		return &models.HTTPError{
+			Code:    http.StatusNotFound,
+			Message: "Webhook not found",
+		}
+	}
+	return nil
+}
+
+// GetDeliveries returns delivery log of webhook, newest first
+func (s *Service) GetDeliveries(ctx context.Context, userID uuid.UUID, webhookID uuid.UUID, limit, offset int32) ([]*models.WebhookDelivery, *models.HTTPError) {
+	if _, err := s.wr.GetWebhook(ctx, userID, webhookID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, &models.HTTPError{
+				Code:    http.StatusNotFound,
+				Message: "Webhook not found",
+			}
+		}
+		slog.Error("error getting webhook", slog.Any("err", err))
+		return nil, &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		}
+	}
+
+	deliveries, err := s.wr.GetDeliveries(ctx, webhookID, limit, offset)
+	if err != nil {
+		slog.Error("error getting webhook deliveries", slog.Any("err", err))
+		return nil, &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		}
+	}
+
+	return deliveries, nil
+}
+
+// Redeliver queues delivery again with a fresh set of attempts
+func (s *Service) Redeliver(ctx context.Context, userID uuid.UUID, webhookID uuid.UUID, id uuid.UUID) *models.HTTPError {
+	status, err := s.wr.Redeliver(ctx, userID, webhookID, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return &models.HTTPError{
+				Code:    http.StatusNotFound,
+				Message: "Delivery not found",
+			}
+		}
+		slog.Error("error redelivering webhook delivery", slog.Any("err", err))
+		return &models.HTTPError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal server error",
+		}
+	}
+
+	if status == models.WebhookDeliveryPending {
+		return &models.HTTPError{
+			Code:    http.StatusConflict,
+			Message: "Delivery is already pending",
+		}
+	}
+	return nil
+}
+
+// Notify queues deliveries of event to webhooks of its recipient
+func (s *Service) Notify(ctx context.Context, event *models.NotificationEvent) {
+	if _, err := s.wr.CreateDeliveries(ctx, event); err != nil {
+		slog.Error("error creating webhook deliveries",
+			slog.String("type", string(event.Type)),
+			slog.Any("err", err),
+		)
+	}
+}
+
+// ProcessPending attempts deliveries that are due
+func (s *Service) ProcessPending(ctx context.Context) error {
+	// Deliveries of batch are attempted at once, they must not be claimed again meanwhile
+	lease := 2 * s.timeout
+
+	for {
+		deliveries, err := s.wr.ClaimDeliveries(ctx, lease, deliveryBatchSize)
+		if err != nil {
+			slog.Error("error claiming webhook deliveries", slog.Any("err", err))
+			return err
+		}
+
+		var wg sync.WaitGroup
+		for _, d := range deliveries {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				attempt := s.attempt(ctx, d)
+				if err := s.wr.RecordAttempt(ctx, d.ID, attempt); err != nil {
+					slog.Error("error recording webhook attempt", slog.String("deliveryID", d.ID.String()), slog.Any("err", err))
+				}
+			}()
+		}
+		wg.Wait()
+
+		if len(deliveries) < deliveryBatchSize {
+			return nil
+		}
+	}
+}
+
+// retryAfter returns delay before the next attempt after given number of attempts
+func (s *Service) retryAfter(attempts int32) time.Duration {
+	delay := s.backoff
+	for i := int32(1); i < attempts && delay < s.maxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, s.maxBackoff)
+}
+
+// attempt sends delivery, any 2xx response means it's delivered
+func (s *Service) attempt(ctx context.Context, d *models.ClaimedWebhookDelivery) *models.WebhookAttempt {
+	result := &models.WebhookAttempt{}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.URL, bytes.NewReader(d.Payload))
+	if err == nil {
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", "Mitter-Webhook")
+		req.Header.Set(HeaderEvent, string(d.Event))
+		req.Header.Set(HeaderDelivery, d.ID.String())
+		req.Header.Set(HeaderSignature, Sign(d.Secret, d.Payload))
+
+		var resp *http.Response
+		resp, err = s.client.Do(req)
+		if err == nil {
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseSize))
+			_ = resp.Body.Close()
+
+			code := int32(resp.StatusCode)
+			result.ResponseCode = &code
+			if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+				result.Status = models.WebhookDeliveryDelivered
+				return result
+			}
+		}
+	}
+	if err != nil {
+		result.Error = truncate(err.Error(), maxErrorLength)
+	}
+
+	attempts := d.Attempts + 1
+	if attempts >= s.maxAttempts {
+		result.Status = models.WebhookDeliveryFailed
+		return result
+	}
+	result.Status = models.WebhookDeliveryPending
+	result.RetryAfter = s.retryAfter(attempts)
+	return result
+}
@@ -0,0 +1,159 @@
+package webhook
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/misshanya/mitter/internal/models"
+)
+
+var testUserID = uuid.MustParse("b096376a-5fa9-4130-907a-709c67008a65")
+
+// Mock webhook repo (in-memory, every delivery is due right away)
+type mockWebhookRepo struct {
+	webhooks   []*models.Webhook
+	deliveries []*models.WebhookDelivery
+	attempts   []*models.WebhookAttempt
+}
+
+func (r *mockWebhookRepo) CreateWebhook(ctx context.Context, userID uuid.UUID, secret string, webhook *models.WebhookCreate) (*models.Webhook, error) {
+	_ = ctx
+
+	created := &models.Webhook{
+		ID:        uuid.New(),
+		UserID:    userID,
+		URL:       webhook.URL,
+		Secret:    secret,
+		Events:    webhook.Events,
+		CreatedAt: time.Now(),
+	}
+	r.webhooks = append(r.webhooks, created)
+	return created, nil
+}
+
+func (r *mockWebhookRepo) GetWebhook(ctx context.Context, userID uuid.UUID, id uuid.UUID) (*models.Webhook, error) {
+	_ = ctx
+
+	for _, w := range r.webhooks {
+		if w.ID == id && w.UserID == userID {
+			return w, nil
+		}
+	}
+	return nil, pgx.ErrNoRows
+}
+
+func (r *mockWebhookRepo) GetUserWebhooks(ctx context.Context, userID uuid.UUID) ([]*models.Webhook, error) {
+	_ = ctx
+
+	var webhooks []*models.Webhook
+	for _, w := range r.webhooks {
+		if w.UserID == userID {
+			webhooks = append(webhooks, w)
+		}
+	}
+	return webhooks, nil
+}
+
+func (r *mockWebhookRepo) DeleteWebhook(ctx context.Context, userID uuid.UUID, id uuid.UUID) (bool, error) {
+	_ = ctx
+
+	for i, w := range r.webhooks {
+		if w.ID == id && w.UserID == userID {
+			r.webhooks = append(r.webhooks[:i], r.webhooks[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *mockWebhookRepo) CreateDeliveries(ctx context.Context, event *models.NotificationEvent) (int64, error) {
+	_ = ctx
+
+	var created int64
+	for _, w := range r.webhooks {
+		if event.ActorID == w.UserID {
+			continue
+		}
+		r.deliveries = append(r.deliveries, &models.WebhookDelivery{
+			ID:        uuid.New(),
+			WebhookID: w.ID,
+			Event:     event.Type,
+			Payload:   []byte(`{"event":"` + string(event.Type) + `"}`),
+			Status:    models.WebhookDeliveryPending,
+			CreatedAt: time.Now(),
+		})
+		created++
+	}
+	return created, nil
+}
+
+func (r *mockWebhookRepo) GetDeliveries(ctx context.Context, webhookID uuid.UUID, limit, offset int32) ([]*models.WebhookDelivery, error) {
+	_ = ctx
+	_ = limit
+	_ = offset
+
+	var deliveries []*models.WebhookDelivery
+	for _, d := range r.deliveries {
+		if d.WebhookID == webhookID {
+			deliveries = append(deliveries, d)
+		}
+	}
+	return deliveries, nil
+}
+
+func (r *mockWebhookRepo) ClaimDeliveries(ctx context.Context, lease time.Duration, batchSize int32) ([]*models.ClaimedWebhookDelivery, error) {
+	_ = ctx
+	_ = lease
+
+	var claimed []*models.ClaimedWebhookDelivery
+	for _, d := range r.deliveries {
+		if d.Status != models.WebhookDeliveryPending || len(claimed) == int(batchSize) {
+			continue
+		}
+		w, _ := r.GetWebhook(ctx, testUserID, d.WebhookID)
+		claimed = append(claimed, &models.ClaimedWebhookDelivery{
+			ID:       d.ID,
+			Event:    d.Event,
+			Payload:  d.Payload,
+			Attempts: d.Attempts,
+			URL:      w.URL,
+			Secret:   w.Secret,
+		})
+	}
+	return claimed, nil
+}
+
+func (r *mockWebhookRepo) RecordAttempt(ctx context.Context, id uuid.UUID, attempt *models.WebhookAttempt) error {
+	_ = ctx
+
+	for _, d := range r.deliveries {
+		if d.ID == id {
+			d.Attempts++
+			d.Status = attempt.Status
+			d.ResponseCode = attempt.ResponseCode
+			d.Error = attempt.Error
+		}
+	}
+	r.attempts = append(r.attempts, attempt)
+	return nil
+}
+
+func (r *mockWebhookRepo) Redeliver(ctx context.Context, userID uuid.UUID, webhookID uuid.UUID, id uuid.UUID) (models.WebhookDeliveryStatus, error) {
+	if _, err := r.GetWebhook(ctx, userID, webhookID); err != nil {
+		return "", err
+	}
+
+	for _, d := range r.deliveries {
+		if d.ID == id && d.WebhookID == webhookID {
+			status := d.Status
+			if status != models.WebhookDeliveryPending {
+				d.Status = models.WebhookDeliveryPending
+				d.Attempts = 0
+			}
+			return status, nil
+		}
+	}
+	return "", pgx.ErrNoRows
+}
@@ -0,0 +1,241 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/misshanya/mitter/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// Tests
+func TestWebhookService_CreateWebhook(t *testing.T) {
+	service := NewService(&mockWebhookRepo{}, time.Second, 3, time.Second, time.Minute, false)
+	ctx := context.Background()
+
+	webhook, err := service.CreateWebhook(ctx, testUserID, &models.WebhookCreate{
+		URL:    "https://example.com/hook",
+		Events: []models.NotificationType{models.NotificationLike, models.NotificationLike, models.NotificationFollow},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, webhook.Secret, 64)
+	assert.Equal(t, []models.NotificationType{models.NotificationLike, models.NotificationFollow}, webhook.Events)
+
+	for _, tc := range []*models.WebhookCreate{
+		{URL: "ftp://example.com", Events: []models.NotificationType{models.NotificationLike}},
+		{URL: "not a url", Events: []models.NotificationType{models.NotificationLike}},
+		{URL: "https://example.com", Events: nil},
+		{URL: "https://example.com", Events: []models.NotificationType{"reply"}},
+		{URL: "http://localhost:9000/hook", Events: []models.NotificationType{models.NotificationLike}},
+		{URL: "http://127.0.0.1/hook", Events: []models.NotificationType{models.NotificationLike}},
+		{URL: "http://10.0.0.1/hook", Events: []models.NotificationType{models.NotificationLike}},
+		{URL: "http://169.254.169.254/latest", Events: []models.NotificationType{models.NotificationLike}},
+		{URL: "http://[::1]/hook", Events: []models.NotificationType{models.NotificationLike}},
+		{URL: "http://0.0.0.0/hook", Events: []models.NotificationType{models.NotificationLike}},
+	} {
+		_, err := service.CreateWebhook(ctx, testUserID, tc)
+		if assert.NotNil(t, err, tc.URL) {
+			assert.Equal(t, http.StatusBadRequest, err.Code)
+		}
+	}
+}
+
+func TestWebhookService_Deliver(t *testing.T) {
+	var (
+		gotBody      []byte
+		gotSignature string
+		gotEvent     string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotBody, _ = io.ReadAll(r.Body)
+		gotSignature = r.Header.Get(HeaderSignature)
+		gotEvent = r.Header.Get(HeaderEvent)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	wr := &mockWebhookRepo{}
+	service := NewService(wr, time.Second, 3, time.Second, time.Minute, true)
+	ctx := context.Background()
+
+	webhook, err := service.CreateWebhook(ctx, testUserID, &models.WebhookCreate{
+		URL:    server.URL,
+		Events: []models.NotificationType{models.NotificationFollow},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	service.Notify(ctx, &models.NotificationEvent{
+		Type:    models.NotificationFollow,
+		ActorID: uuid.New(),
+		UserID:  &testUserID,
+	})
+	if err := service.ProcessPending(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "follow", gotEvent)
+	assert.Equal(t, Sign(webhook.Secret, gotBody), gotSignature)
+
+	deliveries, httpErr := service.GetDeliveries(ctx, testUserID, webhook.ID, 30, 0)
+	if httpErr != nil {
+		t.Fatal(httpErr)
+	}
+	if assert.Len(t, deliveries, 1) {
+		assert.Equal(t, models.WebhookDeliveryDelivered, deliveries[0].Status)
+		if assert.NotNil(t, deliveries[0].ResponseCode) {
+			assert.Equal(t, int32(http.StatusNoContent), *deliveries[0].ResponseCode)
+		}
+	}
+}
+
+func TestWebhookService_Retry(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	wr := &mockWebhookRepo{}
+	service := NewService(wr, time.Second, 3, time.Second, 3*time.Second, true)
+	ctx := context.Background()
+
+	webhook, _ := service.CreateWebhook(ctx, testUserID, &models.WebhookCreate{
+		URL:    server.URL,
+		Events: []models.NotificationType{models.NotificationLike},
+	})
+	service.Notify(ctx, &models.NotificationEvent{Type: models.NotificationLike, ActorID: uuid.New()})
+
+	// Mock repo gives pending deliveries right away, without waiting for backoff
+	for range 3 {
+		if err := service.ProcessPending(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if assert.Len(t, wr.attempts, 3) {
+		assert.Equal(t, time.Second, wr.attempts[0].RetryAfter)
+		assert.Equal(t, 2*time.Second, wr.attempts[1].RetryAfter)
+		assert.Equal(t, models.WebhookDeliveryFailed, wr.attempts[2].Status)
+	}
+	delivery := wr.deliveries[0]
+	assert.Equal(t, models.WebhookDeliveryFailed, delivery.Status)
+	assert.Equal(t, int32(http.StatusInternalServerError), *delivery.ResponseCode)
+
+	// Manual redelivery starts over, but only once
+	if err := service.Redeliver(ctx, testUserID, webhook.ID, delivery.ID); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, models.WebhookDeliveryPending, delivery.Status)
+
+	err := service.Redeliver(ctx, testUserID, webhook.ID, delivery.ID)
+	if assert.NotNil(t, err) {
+		assert.Equal(t, http.StatusConflict, err.Code)
+	}
+
+	err = service.Redeliver(ctx, testUserID, webhook.ID, uuid.New())
+	if assert.NotNil(t, err) {
+		assert.Equal(t, http.StatusNotFound, err.Code)
+	}
+}
+
+func TestWebhookService_RetryAfter(t *testing.T) {
+	service := NewService(&mockWebhookRepo{}, time.Second, 10, time.Second, 10*time.Second, false)
+
+	assert.Equal(t, time.Second, service.retryAfter(1))
+	assert.Equal(t, 4*time.Second, service.retryAfter(3))
+	assert.Equal(t, 10*time.Second, service.retryAfter(9))
+}
+
+func TestWebhookService_PrivateAddress(t *testing.T) {
+	var called bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	// Webhook registered while private addresses were allowed
+	wr := &mockWebhookRepo{}
+	ctx := context.Background()
+	_, err := NewService(wr, time.Second, 3, time.Second, time.Minute, true).CreateWebhook(ctx, testUserID, &models.WebhookCreate{
+		URL:    server.URL,
+		Events: []models.NotificationType{models.NotificationLike},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	service := NewService(wr, time.Second, 3, time.Second, time.Minute, false)
+	service.Notify(ctx, &models.NotificationEvent{Type: models.NotificationLike, ActorID: uuid.New()})
+	if err := service.ProcessPending(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.False(t, called)
+	if assert.Len(t, wr.attempts, 1) {
+		assert.Equal(t, models.WebhookDeliveryPending, wr.attempts[0].Status)
+		assert.Contains(t, wr.attempts[0].Error, errPrivateAddress.Error())
+	}
+}
+
+func TestWebhookService_Redirect(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/internal" {
+			t.Error("redirect is followed")
+		}
+		http.Redirect(w, r, "/internal", http.StatusFound)
+	}))
+	defer server.Close()
+
+	wr := &mockWebhookRepo{}
+	service := NewService(wr, time.Second, 3, time.Second, time.Minute, true)
+	ctx := context.Background()
+
+	_, err := service.CreateWebhook(ctx, testUserID, &models.WebhookCreate{
+		URL:    server.URL + "/hook",
+		Events: []models.NotificationType{models.NotificationLike},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	service.Notify(ctx, &models.NotificationEvent{Type: models.NotificationLike, ActorID: uuid.New()})
+	if err := service.ProcessPending(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if assert.Len(t, wr.attempts, 1) && assert.NotNil(t, wr.attempts[0].ResponseCode) {
+		assert.Equal(t, int32(http.StatusFound), *wr.attempts[0].ResponseCode)
+		assert.Equal(t, models.WebhookDeliveryPending, wr.attempts[0].Status)
+	}
+}
+
+func TestPublicIP(t *testing.T) {
+	for _, ip := range []string{"93.184.216.34", "2606:2800:220:1:248:1893:25c8:1946"} {
+		assert.True(t, publicIP(netip.MustParseAddr(ip)), ip)
+	}
+	for _, ip := range []string{
+		"127.0.0.1", "10.1.2.3", "0.1.2.3", "100.64.0.1", "169.254.169.254", "192.0.0.8", "198.18.0.1", "240.0.0.1", "255.255.255.255",
+		"::1", "fd00::1", "fe80::1%eth0",
+		// IPv4-mapped IPv6 is checked as IPv4
+		"::ffff:127.0.0.1", "::ffff:100.64.0.1",
+	} {
+		assert.False(t, publicIP(netip.MustParseAddr(ip)), ip)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "abc", truncate("abc", 5))
+	assert.Equal(t, "ab", truncate("abc", 2))
+	// "й" takes 2 bytes and isn't split
+	assert.Equal(t, "a", truncate("aйb", 2))
+	assert.Equal(t, "aй", truncate("aйb", 3))
+}
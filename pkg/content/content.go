@@ -0,0 +1,50 @@
+package content
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/rivo/uniseg"
+	"golang.org/x/text/unicode/norm"
+)
+
+var (
+	ErrBlank        = errors.New("must not be blank")
+	ErrControlChars = errors.New("must not contain control characters")
+)
+
+// Normalize brings text to NFC form, unifies line breaks and trims surrounding whitespace.
+// Text should be normalized before it's checked and stored
+func Normalize(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = norm.NFC.String(s)
+	return strings.TrimFunc(s, unicode.IsSpace)
+}
+
+// Length returns number of user-perceived characters (grapheme clusters),
+// so emoji with modifiers or combined letters count as one
+func Length(s string) int {
+	return uniseg.GraphemeClusterCount(s)
+}
+
+// Check validates normalized text: it must be non-blank, contain no control characters
+// except line breaks and tabs, and be at most maxLength characters long
+func Check(s string, maxLength int) error {
+	if strings.TrimFunc(s, unicode.IsSpace) == "" {
+		return ErrBlank
+	}
+
+	for _, r := range s {
+		if unicode.IsControl(r) && r != '\n' && r != '\t' {
+			return ErrControlChars
+		}
+	}
+
+	if l := Length(s); l > maxLength {
+		return fmt.Errorf("must be at most %d characters, got %d", maxLength, l)
+	}
+
+	return nil
+}
@@ -0,0 +1,26 @@
+package content
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalize(t *testing.T) {
+	// "é" as "e" + combining acute accent becomes a single code point
+	assert.Equal(t, "caf\u00e9", Normalize("  cafe\u0301\r\n"))
+	assert.Equal(t, "a\nb", Normalize("a\r\nb"))
+}
+
+func TestCheck(t *testing.T) {
+	assert.NoError(t, Check("hello\n\tworld", 20))
+
+	assert.ErrorIs(t, Check("", 20), ErrBlank)
+	assert.ErrorIs(t, Check(" \n　", 20), ErrBlank)
+	assert.ErrorIs(t, Check("hi\x00", 20), ErrControlChars)
+	assert.ErrorIs(t, Check("bell\a", 20), ErrControlChars)
+
+	// Family emoji is 7 code points but one character
+	assert.NoError(t, Check("👨‍👩‍👧‍👦", 1))
+	assert.Error(t, Check("ab", 1))
+}
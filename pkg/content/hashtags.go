@@ -0,0 +1,107 @@
+package content
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Max hashtag length in characters without '#', longer ones are not hashtags
+const MaxHashtagLength = 100
+
+// token is a word starting with sigil ('#' or '@'). Start and End are byte offsets of token in text including sigil
+type token struct {
+	Text  string
+	Start int
+	End   int
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r) || r == '_'
+}
+
+// findTokens finds words which start with sigil that isn't glued to the previous word,
+// so "a#b", "&#39;" or "mail@example.com" don't count. Words consist of runes accepted by isTokenRune
+// and start and end with a word rune, so trailing punctuation like in "@bob." isn't a part of them
+func findTokens(s string, sigil rune, isTokenRune func(r rune) bool) []token {
+	var tokens []token
+
+	prev := rune(0)
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == sigil && !isWordRune(prev) && prev != '&' && prev != sigil {
+			start := i + size
+			end := start
+			for end < len(s) {
+				wr, wsize := utf8.DecodeRuneInString(s[end:])
+				if !isTokenRune(wr) || (end == start && !isWordRune(wr)) {
+					break
+				}
+				end += wsize
+			}
+			for end > start {
+				wr, wsize := utf8.DecodeLastRuneInString(s[start:end])
+				if isWordRune(wr) {
+					break
+				}
+				end -= wsize
+			}
+
+			if end > start {
+				tokens = append(tokens, token{Text: s[start:end], Start: i, End: end})
+				prev, _ = utf8.DecodeLastRuneInString(s[:end])
+				i = end
+				continue
+			}
+		}
+
+		prev = r
+		i += size
+	}
+
+	return tokens
+}
+
+// validHashtag checks word after '#', it must have at least one letter so "#1" isn't a hashtag
+func validHashtag(tag string) bool {
+	if utf8.RuneCountInString(tag) > MaxHashtagLength {
+		return false
+	}
+	return strings.IndexFunc(tag, unicode.IsLetter) >= 0
+}
+
+// Hashtags returns unique lowercase hashtags of normalized text without '#', in order of appearance
+func Hashtags(s string) []string {
+	var tags []string
+
+	seen := make(map[string]struct{})
+	for _, t := range findTokens(s, '#', isWordRune) {
+		if !validHashtag(t.Text) {
+			continue
+		}
+
+		tag := strings.ToLower(t.Text)
+		if _, ok := seen[tag]; ok {
+			continue
+		}
+		seen[tag] = struct{}{}
+		tags = append(tags, tag)
+	}
+
+	return tags
+}
+
+// NormalizeHashtag brings hashtag name given by user (with or without '#') to the stored form.
+// Returns false if it isn't a valid hashtag
+func NormalizeHashtag(name string) (string, bool) {
+	name = norm.NFC.String(strings.TrimPrefix(name, "#"))
+	if name == "" || strings.IndexFunc(name, func(r rune) bool { return !isWordRune(r) }) >= 0 {
+		return "", false
+	}
+	if !validHashtag(name) {
+		return "", false
+	}
+	return strings.ToLower(name), true
+}
@@ -0,0 +1,38 @@
+package content
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashtags(t *testing.T) {
+	assert.Equal(t, []string{"go", "release", "привет"}, Hashtags("#Go 1.25 is out! #release,#go #Привет"))
+	assert.Equal(t, []string{"snake_case", "v2"}, Hashtags("(#snake_case) #v2."))
+
+	// Not hashtags: glued to a word, numbers only, HTML entity, empty
+	assert.Empty(t, Hashtags("a#b #123 &#39; # ##"))
+
+	// Too long
+	long := make([]byte, MaxHashtagLength+1)
+	for i := range long {
+		long[i] = 'a'
+	}
+	assert.Empty(t, Hashtags("#"+string(long)))
+	assert.Equal(t, []string{string(long[1:])}, Hashtags("#"+string(long[1:])))
+}
+
+func TestNormalizeHashtag(t *testing.T) {
+	tag, ok := NormalizeHashtag("#GoLang")
+	assert.True(t, ok)
+	assert.Equal(t, "golang", tag)
+
+	tag, ok = NormalizeHashtag("café")
+	assert.True(t, ok)
+	assert.Equal(t, "café", tag)
+
+	for _, name := range []string{"", "#", "123", "two words", "a-b"} {
+		_, ok := NormalizeHashtag(name)
+		assert.False(t, ok, name)
+	}
+}
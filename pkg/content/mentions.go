@@ -0,0 +1,63 @@
+package content
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Logins may also have '.' and '-' inside, but start and end with a word rune
+func isLoginRune(r rune) bool {
+	return isWordRune(r) || r == '.' || r == '-'
+}
+
+// ValidLogin tells if login can be mentioned as "@login"
+func ValidLogin(login string) bool {
+	tokens := findTokens("@"+login, '@', isLoginRune)
+	return len(tokens) == 1 && tokens[0].Text == login
+}
+
+// Mention is "@login" in text. Start and End are offsets in code points including '@', End is exclusive
+type Mention struct {
+	Login string
+	Start int
+	End   int
+}
+
+// Mentions returns all mentions of normalized text in order of appearance
+func Mentions(s string) []Mention {
+	tokens := findTokens(s, '@', isLoginRune)
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	mentions := make([]Mention, len(tokens))
+	// Count code points incrementally, tokens are ordered
+	pos, offset := 0, 0
+	for i, t := range tokens {
+		offset += utf8.RuneCountInString(s[pos:t.Start])
+		start := offset
+		offset += utf8.RuneCountInString(s[t.Start:t.End])
+		pos = t.End
+
+		mentions[i] = Mention{Login: t.Text, Start: start, End: offset}
+	}
+
+	return mentions
+}
+
+// MentionedLogins returns unique lowercase logins mentioned in normalized text, logins are matched case-insensitively
+func MentionedLogins(s string) []string {
+	var logins []string
+
+	seen := make(map[string]struct{})
+	for _, m := range Mentions(s) {
+		login := strings.ToLower(m.Login)
+		if _, ok := seen[login]; ok {
+			continue
+		}
+		seen[login] = struct{}{}
+		logins = append(logins, login)
+	}
+
+	return logins
+}
@@ -0,0 +1,38 @@
+package content
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMentions(t *testing.T) {
+	assert.Equal(t, []Mention{
+		{Login: "bob", Start: 7, End: 11},
+		{Login: "alice", Start: 16, End: 22},
+		{Login: "bob", Start: 24, End: 28},
+	}, Mentions("привет @bob and @alice, @bob's"))
+
+	// Emails and lone '@' aren't mentions
+	assert.Empty(t, Mentions("mail me at bob@example.com @ or @@"))
+
+	// Dots and dashes inside login, but not at its end
+	assert.Equal(t, []Mention{
+		{Login: "john.doe", Start: 3, End: 12},
+		{Login: "mary-jane", Start: 17, End: 27},
+	}, Mentions("hi @john.doe and @mary-jane."))
+}
+
+func TestMentionedLogins(t *testing.T) {
+	assert.Equal(t, []string{"bob", "alice"}, MentionedLogins("@bob @alice @Bob"))
+	assert.Nil(t, MentionedLogins("nobody"))
+}
+
+func TestValidLogin(t *testing.T) {
+	for _, login := range []string{"bob", "john.doe", "mary-jane", "snake_case", "вася", "_x"} {
+		assert.True(t, ValidLogin(login), login)
+	}
+	for _, login := range []string{"", "bob.", "-bob", "two words", "bob@home", "a..b!"} {
+		assert.False(t, ValidLogin(login), login)
+	}
+}